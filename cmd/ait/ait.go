@@ -2,14 +2,21 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/yinxulai/ait/internal/i18n"
 	"github.com/yinxulai/ait/internal/mcp"
 	"github.com/yinxulai/ait/internal/server"
 	"github.com/yinxulai/ait/internal/server/config"
+	"github.com/yinxulai/ait/internal/server/update"
+	"github.com/yinxulai/ait/internal/server/upload"
 	"github.com/yinxulai/ait/internal/tui"
 	"github.com/yinxulai/ait/internal/web"
 )
@@ -22,57 +29,194 @@ var (
 )
 
 func main() {
-	// ── flags ────────────────────────────────────────────────────────────────
-	versionFlag := flag.Bool("version", false, "显示版本信息")
-	mcpFlag := flag.Bool("mcp", false, "启用 MCP 模式")
-	webFlag := flag.Bool("web", false, "启用 Web UI 模式")
-	langFlag := flag.String("lang", "", "界面语言：zh 或 en")
-	flag.Parse()
+	// ── 子命令 ────────────────────────────────────────────────────────────────
+	if len(os.Args) > 1 && os.Args[1] == "validate" {
+		os.Exit(runValidate(os.Args[2:], os.Stdin, os.Stdout, os.Stderr))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "report" {
+		os.Exit(runReport(os.Args[2:], os.Stdout, os.Stderr))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "doctor" {
+		os.Exit(runDoctor(os.Args[2:], os.LookupEnv, os.Stdout, os.Stderr))
+	}
+
+	// ── 参数 ──────────────────────────────────────────────────────────────────
+	opts, err := ParseOptions(os.Args[1:])
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			os.Exit(0)
+		}
+		if !errors.As(err, &usageError{}) {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+		}
+		os.Exit(2)
+	}
+	if err := opts.Apply(); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(2)
+	}
 
 	// ── 版本输出 ──────────────────────────────────────────────────────────────
-	if *versionFlag {
+	if opts.Version {
 		fmt.Printf("ait version %s\n", Version)
 		fmt.Printf("Git Commit: %s\n", GitCommit)
 		fmt.Printf("Build Time: %s\n", BuildTime)
 		os.Exit(0)
 	}
 
+	// ── 性能分析 ──────────────────────────────────────────────────────────────
+	prof, err := startProfiling(opts.CPUProfile, opts.MemProfile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+	// os.Exit 不执行 defer，之后的退出统一经过 exit，确保 profile 写完整
+	exit := func(code int) {
+		// 最后一个运行的 webhook 可能仍在推送或重试，限时等待其完成
+		if !server.WaitWebhooks(server.WebhookWaitTimeout()) {
+			fmt.Println("警告: 等待 webhook 推送超时")
+		}
+		if err := prof.stop(); err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+		}
+		os.Exit(code)
+	}
+	// 开启采集时，web / mcp 模式收到 Ctrl+C 后优雅退出，否则 profile 会丢失
+	ctx := context.Background()
+	if prof.enabled() {
+		var stopSignals context.CancelFunc
+		ctx, stopSignals = signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+		defer stopSignals()
+	}
+
 	// ── 创建 Server ───────────────────────────────────────────────────────────
 	srv, err := server.NewWithVersion(Version)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "初始化 Server 失败: %v\n", err)
-		os.Exit(1)
+		exit(1)
 	}
 
-	// ── 初始化界面语言（flag > 配置文件 > 默认 ZH）────────────────────────────
-	if *langFlag == "en" {
-		i18n.SetLang(i18n.EN)
-	} else if *langFlag == "zh" {
-		i18n.SetLang(i18n.ZH)
-	} else if cfg, err := config.Load(); err == nil && cfg.Lang == "en" {
-		i18n.SetLang(i18n.EN)
+	// ── 初始化界面语言（flag > 环境变量 > 配置文件 > 默认 ZH）──────────────────
+	opts.ApplyLang(func() string {
+		if cfg, err := config.Load(); err == nil {
+			return cfg.Lang
+		}
+		return ""
+	})
+
+	if opts.Verbose {
+		opts.PrintSources(os.Stderr)
+	}
+
+	// ── 启动提示：首次运行的遥测说明与新版本检查，均不阻塞启动 ───────────────
+	// --quiet 时非 TUI 场景不生成提示，遥测说明留到下次正常启动时再展示
+	var notices <-chan string
+	headless := opts.Plan != "" || opts.Route() != "tui"
+	if !opts.DryRun && !(opts.Quiet && headless) {
+		notices = startupNotices(upload.Enabled(), opts.NoUpdateCheck, update.DefaultReleaseURL, Version)
+		if headless {
+			go printNotices(os.Stderr, notices)
+		}
 	}
 
-	switch routeByFlags(*mcpFlag, *webFlag) {
+	if opts.DryRun {
+		exit(runDryRun(srv, opts.DryRunOutput, os.Stderr))
+	}
+
+	if opts.Plan != "" {
+		format := opts.ReportFormat
+		if format == "" {
+			format = string(server.ReportFormatJSON)
+		}
+		code := runPlan(srv, opts.Plan, planOptions{
+			Format:       format,
+			CostLimit:    opts.CostLimit,
+			RequestLimit: opts.RequestLimit,
+			Yes:          opts.Yes,
+			Interactive:  isTerminal(os.Stdin),
+			Quiet:        opts.Quiet,
+		}, os.Stdin, os.Stdout, os.Stderr)
+		if code == 0 {
+			code = runExitCode(srv, opts.FailOnSLA)
+		}
+		exit(code)
+	}
+
+	if opts.Replay != "" {
+		if _, err := createReplayTask(srv, opts.Replay, os.Stderr); err != nil {
+			fmt.Fprintf(os.Stderr, "--replay 失败: %v\n", err)
+			exit(1)
+		}
+	}
+	if opts.FromBundle != "" {
+		if _, err := createBundleTasks(srv, opts.FromBundle, os.LookupEnv, os.Stderr); err != nil {
+			fmt.Fprintf(os.Stderr, "--from-bundle 失败: %v\n", err)
+			exit(1)
+		}
+	}
+
+	switch opts.Route() {
 	case "mcp":
-		if err := mcp.New(srv).Run(context.Background()); err != nil {
+		if err := mcp.New(srv).Run(ctx); err != nil {
 			fmt.Fprintf(os.Stderr, "MCP 启动失败: %v\n", err)
-			os.Exit(1)
+			exit(1)
 		}
-		return
+		exit(runExitCode(srv, opts.FailOnSLA))
 	case "web":
-		if err := web.Run(context.Background()); err != nil {
+		if err := web.Run(ctx); err != nil {
 			fmt.Fprintf(os.Stderr, "Web UI 启动失败: %v\n", err)
-			os.Exit(1)
+			exit(1)
 		}
-		return
+		exit(0)
 	}
 
 	tui.SetVersion(Version)
+	tui.SetASCII(opts.ASCII)
+	tui.SetNotices(notices)
+	sessionStart := time.Now()
 	if err := tui.Run(srv); err != nil {
 		fmt.Fprintf(os.Stderr, "TUI 启动失败: %v\n", err)
-		os.Exit(1)
+		exit(1)
 	}
+	opts.writeSessionOutputs(srv, sessionStart)
+	exit(runExitCode(srv, opts.FailOnSLA))
+}
+
+// 运行结果不达标时的进程退出码，便于 CI 判定。
+const (
+	exitCodeRegression = 3 // 有运行相对基线出现回归
+	exitCodeSLA        = 4 // 有运行未达到 SLA（需开启 --fail-on-sla）
+	exitCodeAborted    = 5 // 有运行因错误率熔断提前终止
+)
+
+// runExitCode 本次进程内有运行因错误率熔断提前终止时返回 exitCodeAborted；有运行出现基线回归时返回 exitCodeRegression；
+// 开启 failOnSLA 且有运行未达到 SLA 时返回 exitCodeSLA；否则返回 0。
+func runExitCode(srv server.Server, failOnSLA bool) int {
+	if srv.AbortedEarly() {
+		fmt.Fprintln(os.Stderr, i18n.T(i18n.KCLIAbortedEarly))
+		return exitCodeAborted
+	}
+	if srv.Regressed() {
+		fmt.Fprintln(os.Stderr, i18n.T(i18n.KCLIRegressed))
+		return exitCodeRegression
+	}
+	if failOnSLA && srv.SLAFailed() {
+		fmt.Fprintln(os.Stderr, i18n.T(i18n.KCLISLAFailed))
+		return exitCodeSLA
+	}
+	return 0
+}
+
+// stringList 可重复指定的字符串参数，每次出现追加一项。
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, "; ")
+}
+
+func (l *stringList) Set(v string) error {
+	*l = append(*l, v)
+	return nil
 }
 
 func routeByFlags(mcpEnabled, webEnabled bool) string {
@@ -1,6 +1,18 @@
 package main
 
-import "testing"
+import (
+	"errors"
+	"flag"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yinxulai/ait/internal/i18n"
+	"github.com/yinxulai/ait/internal/server/logger"
+	"github.com/yinxulai/ait/internal/server/upload"
+)
 
 func TestFlagRouting(t *testing.T) {
 	tests := []struct {
@@ -24,3 +36,165 @@ func TestFlagRouting(t *testing.T) {
 		})
 	}
 }
+
+// parseWithEnv 以给定的环境变量解析参数，不输出用法
+func parseWithEnv(args []string, env map[string]string) (*Options, error) {
+	return parseOptions(args, func(name string) (string, bool) {
+		v, ok := env[name]
+		return v, ok
+	}, io.Discard)
+}
+
+func TestParseOptions_Defaults(t *testing.T) {
+	o, err := parseWithEnv(nil, nil)
+	if err != nil {
+		t.Fatalf("parseOptions: %v", err)
+	}
+	if o.Route() != "tui" || o.HTTPVersion != "auto" || o.ExportCurlOutput != "ait-curl.sh" || o.SelfMonitorOutput != "selfstats.jsonl" {
+		t.Errorf("defaults = %+v", o)
+	}
+	if o.LogMaxChunks != logger.DefaultMaxStreamChunks || o.UploadSampleRate != upload.DefaultSampleRate ||
+		o.RequestLimit != defaultPlanRequestLimit || o.SaveIOSampleRate != 1 {
+		t.Errorf("numeric defaults = %+v", o)
+	}
+	if o.Shard.Enabled() || len(o.SLA) != 0 || len(o.DNS.Resolve) != 0 || o.DNS.Server != "" {
+		t.Errorf("empty settings = shard %+v, sla %v, dns %+v", o.Shard, o.SLA, o.DNS)
+	}
+	if o.sources["lang"] != sourceDefault {
+		t.Errorf("lang source = %q, want default", o.sources["lang"])
+	}
+}
+
+func TestParseOptions_FlagsAndEnv(t *testing.T) {
+	o, err := parseWithEnv([]string{
+		"--mcp", "--lang", "en", "--http-version", "1.1", "--sla", "ttft<800ms,p=95", "--sla", "total<10s",
+		"--resolve", "api.example.com:443:10.0.0.5", "--dns-server", "8.8.8.8", "--telemetry-timeout", "5s",
+		"--shard", "2/4", "--table-format", "csv", "--explain", "--abort-on-error-rate", "50%",
+		"--accept-encoding", "BR", "--redact", "--progress-format", "json",
+	}, map[string]string{
+		"AIT_LANG":       "zh", // 命令行优先
+		"AIT_MAX_TOKENS": "512",
+		"AIT_SELF_STATS": "true",
+	})
+	if err != nil {
+		t.Fatalf("parseOptions: %v", err)
+	}
+	if o.Route() != "mcp" || o.Lang != "en" || o.HTTPVersion != "1.1" || o.MaxTokens != 512 || !o.SelfStats || o.ProgressFormat != "json" {
+		t.Errorf("options = %+v", o)
+	}
+	if len(o.SLA) != 2 || o.SLA[1] != "total<10s" {
+		t.Errorf("SLA = %v", o.SLA)
+	}
+	if len(o.DNS.Resolve) != 1 || o.DNS.Server != "8.8.8.8:53" {
+		t.Errorf("DNS = %+v", o.DNS)
+	}
+	if o.redactor == nil || o.redactor.Redact("13800138000") != "[REDACTED]" {
+		t.Errorf("redactor = %+v, want builtin rules", o.redactor)
+	}
+	if o.AcceptEncoding != "br" {
+		t.Errorf("AcceptEncoding = %q, want br", o.AcceptEncoding)
+	}
+	if o.AbortOnErrorRate != 50 || o.AbortMinSamples != 20 {
+		t.Errorf("abort = %g / %d, want 50 / 20", o.AbortOnErrorRate, o.AbortMinSamples)
+	}
+	if o.Telemetry.Timeout != 5*time.Second || o.Shard.String() != "2/4" || !o.Explain {
+		t.Errorf("telemetry / shard / explain = %+v / %v / %v", o.Telemetry, o.Shard, o.Explain)
+	}
+	if o.sources["lang"] != sourceFlag || o.sources["max-tokens"] != sourceEnv || o.sources["yes"] != sourceDefault {
+		t.Errorf("sources = %v", o.sources)
+	}
+}
+
+func TestParseOptions_ApplyLangFromConfig(t *testing.T) {
+	o, err := parseWithEnv(nil, nil)
+	if err != nil {
+		t.Fatalf("parseOptions: %v", err)
+	}
+	t.Cleanup(func() { i18n.SetLang(i18n.ZH) })
+	o.ApplyLang(func() string { return "en" })
+	if o.Lang != "en" || o.sources["lang"] != sourceConfig {
+		t.Errorf("lang = %q (%s), want en from config", o.Lang, o.sources["lang"])
+	}
+
+	o, _ = parseWithEnv([]string{"--lang", "zh"}, nil)
+	o.ApplyLang(func() string { t.Error("config should not be read when --lang is set"); return "en" })
+}
+
+func TestParseOptions_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		env  map[string]string
+		want string
+	}{
+		{"table format", []string{"--table-format", "xml"}, nil, "--table-format"},
+		{"report format", []string{"--report-format", "pdf"}, nil, "--report-format"},
+		{"explain without table", []string{"--explain"}, nil, "--explain"},
+		{"dry run output", []string{"--dry-run-output", "a.txt"}, nil, "--dry-run-output"},
+		{"negative limit", []string{"--request-limit", "-1"}, nil, "--request-limit"},
+		{"negative slowest", []string{"--show-slowest", "-1"}, nil, "--show-slowest"},
+		{"negative log chunks", []string{"--log-max-chunks", "-1"}, nil, "--log-max-chunks"},
+		{"negative max tokens", []string{"--max-tokens", "-1"}, nil, "--max-tokens"},
+		{"empty self monitor output", []string{"--self-monitor", "--self-monitor-output", ""}, nil, "--self-monitor-output"},
+		{"progress format", []string{"--progress-format", "text"}, nil, "--progress-format"},
+		{"progress format on tui", []string{"--progress-format", "json"}, nil, "--progress-format"},
+		{"save io rate", []string{"--save-io-dir", "io", "--save-io-sample-rate", "0"}, nil, "--save-io-sample-rate"},
+		{"upload rate", []string{"--upload-sample-rate", "2"}, nil, "--upload-sample-rate"},
+		{"export curl", []string{"--export-curl", "all"}, nil, "--export-curl"},
+		{"telemetry proxy", []string{"--telemetry-proxy", "proxy:8080"}, nil, "--telemetry-proxy"},
+		{"shard", []string{"--shard", "5/4"}, nil, "--shard"},
+		{"sla", []string{"--sla", "ttft<"}, nil, "--sla"},
+		{"http version", []string{"--http-version", "3"}, nil, "--http-version"},
+		{"accept encoding", []string{"--accept-encoding", "zstd"}, nil, "--accept-encoding"},
+		{"abort rate", []string{"--abort-on-error-rate", "150%"}, nil, "--abort-on-error-rate"},
+		{"abort rate never trips", []string{"--abort-on-error-rate", "100%"}, nil, "--abort-on-error-rate"},
+		{"abort rate NaN", []string{"--abort-on-error-rate", "NaN"}, nil, "--abort-on-error-rate"},
+		{"abort rate Inf", []string{"--abort-on-error-rate", "Inf%"}, nil, "--abort-on-error-rate"},
+		{"redact patterns without redact", []string{"--redact-patterns", "patterns.txt"}, nil, "--redact"},
+		{"redact patterns", []string{"--redact", "--redact-patterns", "missing-patterns.txt"}, nil, "--redact-patterns"},
+		{"response schema", []string{"--response-schema", "missing-schema.json"}, nil, "--response-schema"},
+		{"abort min samples", []string{"--abort-min-samples", "0"}, nil, "--abort-min-samples"},
+		{"resolve", []string{"--resolve", "api.example.com"}, nil, "--resolve"},
+		{"quiet with verbose", []string{"--quiet", "--verbose"}, nil, "--quiet"},
+		{"stdin lines with prompt command", []string{"--prompt-stdin-lines", "--prompt-command", "echo hi"}, nil, "--prompt-stdin-lines"},
+		{"stdin lines with mcp", []string{"--prompt-stdin-lines", "--mcp"}, nil, "--prompt-stdin-lines"},
+		{"dns server", []string{"--dns-server", "not a host"}, nil, "--dns-server"},
+		{"env value", nil, map[string]string{"AIT_MAX_TOKENS": "many"}, "AIT_MAX_TOKENS"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseWithEnv(tt.args, tt.env)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("err = %v, want mention of %s", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadStdinPrompts(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		io.WriteString(w, "first\r\n\nsecond\n")
+		w.Close()
+	}()
+	source, err := readStdinPrompts(r)
+	if err != nil {
+		t.Fatalf("readStdinPrompts: %v", err)
+	}
+	if got := source.Contents; len(got) != 2 || got[0] != "first" || got[1] != "second" {
+		t.Errorf("Contents = %q, want [first second]", got)
+	}
+}
+
+func TestParseOptions_UsageErrors(t *testing.T) {
+	if _, err := parseWithEnv([]string{"-h"}, nil); !errors.Is(err, flag.ErrHelp) {
+		t.Errorf("-h: err = %v, want flag.ErrHelp", err)
+	}
+	_, err := parseWithEnv([]string{"--no-such-flag"}, nil)
+	if !errors.As(err, &usageError{}) {
+		t.Errorf("unknown flag: err = %v, want usageError", err)
+	}
+}
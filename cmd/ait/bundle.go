@@ -0,0 +1,288 @@
+package main
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/yinxulai/ait/internal/server"
+	"github.com/yinxulai/ait/internal/server/client"
+	"github.com/yinxulai/ait/internal/server/config"
+	"github.com/yinxulai/ait/internal/server/types"
+)
+
+// bundleFormat bundle 的格式版本，结构不兼容地变化时递增
+const bundleFormat = 1
+
+// bundleManifest bundle 根目录下的 manifest.json：导出时的环境、命令行参数与各次运行的索引。
+//
+// 每次运行的文件位于 runs/<run_id>/ 下：task.json 为任务配置（已去除密钥与 webhook 地址），
+// result.json 为运行结果（ReportData 或 A/B 对比等模式结果），files/ 下为配置引用的外部文件
+// （prompt 文件、工具定义、重放文件），task.json 中对应的路径已改写为 files/ 下的相对路径。
+type bundleManifest struct {
+	Format      int                   `json:"format"`
+	CreatedAt   time.Time             `json:"created_at"`
+	Environment *types.RunEnvironment `json:"environment"`
+	Args        []string              `json:"args,omitempty"` // 导出时的命令行参数，进程级开关（如 --stream-both）不在任务配置中
+	Runs        []bundleRun           `json:"runs"`
+}
+
+// bundleRun manifest 中一次运行的索引。
+type bundleRun struct {
+	TaskName string `json:"task_name"`
+	TaskID   string `json:"task_id"`
+	RunID    string `json:"run_id"`
+	Dir      string `json:"dir"`
+}
+
+// bundleTask runs/<run_id>/task.json 的内容。
+type bundleTask struct {
+	Name  string      `json:"name"`
+	Input types.Input `json:"input"`
+}
+
+// writeSessionBundle 执行 --export-bundle：把本次会话标准模式运行的任务配置、prompt 数据、运行结果与环境信息
+// 打包为 zip 写入 out，返回打包的运行数。
+func writeSessionBundle(out string, args []string, srv server.Server, since time.Time) (int, error) {
+	runs := sessionRuns(srv, since)
+	if len(runs) == 0 {
+		return 0, fmt.Errorf("本次会话没有已完成的标准运行")
+	}
+	return writeBundle(out, args, srv, runs)
+}
+
+// writeBundle 把 runs 及其任务配置打包写入 out。
+func writeBundle(out string, args []string, srv server.Server, runs []*server.RunState) (int, error) {
+	f, err := os.Create(out)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	zw := zip.NewWriter(f)
+
+	hostname, _ := os.Hostname()
+	manifest := bundleManifest{
+		Format:    bundleFormat,
+		CreatedAt: time.Now(),
+		Environment: &types.RunEnvironment{
+			Hostname:   hostname,
+			OS:         runtime.GOOS,
+			Arch:       runtime.GOARCH,
+			AitVersion: Version,
+		},
+		Args: args,
+	}
+	for _, state := range runs {
+		def, err := srv.GetTask(state.TaskID)
+		if err != nil {
+			return 0, err
+		}
+		dir := path.Join("runs", string(state.RunID))
+		input, err := bundleInputFiles(zw, dir, def.Input)
+		if err != nil {
+			return 0, fmt.Errorf("%s: %w", def.Name, err)
+		}
+		if err := writeZipJSON(zw, path.Join(dir, "task.json"), bundleTask{Name: def.Name, Input: input}); err != nil {
+			return 0, err
+		}
+		if err := writeZipJSON(zw, path.Join(dir, "result.json"), state.ModeResult); err != nil {
+			return 0, err
+		}
+		manifest.Runs = append(manifest.Runs, bundleRun{TaskName: def.Name, TaskID: def.ID, RunID: string(state.RunID), Dir: dir})
+	}
+	if err := writeZipJSON(zw, "manifest.json", manifest); err != nil {
+		return 0, err
+	}
+	if err := zw.Close(); err != nil {
+		return 0, err
+	}
+	return len(manifest.Runs), nil
+}
+
+// bundleInputFiles 把 input 引用的外部文件写入 zip 的 <dir>/files/ 下，返回路径改写为 bundle 内相对路径、
+// 并去除密钥与 webhook 地址的配置。prompt 文件为 glob 时按匹配顺序加序号前缀，还原后的顺序与原来一致。
+func bundleInputFiles(zw *zip.Writer, dir string, input types.Input) (types.Input, error) {
+	input.ApiKey = ""
+	input.WebhookURL = ""
+	input.PromptSource = nil
+	if input.PromptMode == "file" && input.PromptFile != "" {
+		matches, err := filepath.Glob(input.PromptFile)
+		if err != nil {
+			return input, err
+		}
+		var files []string
+		for _, match := range matches {
+			if info, err := os.Stat(match); err == nil && !info.IsDir() {
+				files = append(files, match)
+			}
+		}
+		if len(files) == 0 {
+			return input, fmt.Errorf("prompt 文件不存在: %s", input.PromptFile)
+		}
+		for i, file := range files {
+			name := fmt.Sprintf("files/prompts/%04d-%s", i, filepath.Base(file))
+			if err := copyFileToZip(zw, path.Join(dir, name), file); err != nil {
+				return input, err
+			}
+		}
+		input.PromptFile = "files/prompts/*"
+	}
+	if input.ToolsFile != "" {
+		if err := copyFileToZip(zw, path.Join(dir, "files/tools.json"), input.ToolsFile); err != nil {
+			return input, err
+		}
+		input.ToolsFile = "files/tools.json"
+	}
+	if input.ResponseSchema != "" {
+		if err := copyFileToZip(zw, path.Join(dir, "files/response-schema.json"), input.ResponseSchema); err != nil {
+			return input, err
+		}
+		input.ResponseSchema = "files/response-schema.json"
+	}
+	if input.ReplayFile != "" {
+		if err := copyFileToZip(zw, path.Join(dir, "files/replay.jsonl"), input.ReplayFile); err != nil {
+			return input, err
+		}
+		input.ReplayFile = "files/replay.jsonl"
+	}
+	return input, nil
+}
+
+func writeZipJSON(zw *zip.Writer, name string, v any) error {
+	w, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func copyFileToZip(zw *zip.Writer, name, file string) error {
+	src, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	w, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(w, src)
+	return err
+}
+
+// createBundleTasks 执行 --from-bundle：把 bundle 中每次运行的任务配置还原为新任务，外部文件解压到
+// ~/.ait/bundles/<bundle 文件名>/<run_id>/ 下。bundle 不含密钥，从协议对应的环境变量（如 OPENAI_API_KEY）读取。
+func createBundleTasks(srv server.Server, bundlePath string, lookupEnv func(string) (string, bool), stderr io.Writer) ([]types.TaskDefinition, error) {
+	appDir, err := config.EnsureAppDir()
+	if err != nil {
+		return nil, err
+	}
+	name := strings.TrimSuffix(filepath.Base(bundlePath), filepath.Ext(bundlePath))
+	return restoreBundle(srv, bundlePath, filepath.Join(appDir, "bundles", name), lookupEnv, stderr)
+}
+
+// restoreBundle 读取 bundle，把外部文件解压到 destDir 并逐个创建任务。
+func restoreBundle(srv server.Server, bundlePath, destDir string, lookupEnv func(string) (string, bool), stderr io.Writer) ([]types.TaskDefinition, error) {
+	zr, err := zip.OpenReader(bundlePath)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+
+	var manifest bundleManifest
+	if err := readZipJSON(&zr.Reader, "manifest.json", &manifest); err != nil {
+		return nil, err
+	}
+	if manifest.Format != bundleFormat {
+		return nil, fmt.Errorf("不支持的 bundle 格式版本 %d", manifest.Format)
+	}
+
+	var defs []types.TaskDefinition
+	for _, run := range manifest.Runs {
+		var task bundleTask
+		if err := readZipJSON(&zr.Reader, path.Join(run.Dir, "task.json"), &task); err != nil {
+			return nil, err
+		}
+		runDir := filepath.Join(destDir, filepath.Base(run.Dir))
+		if err := extractZipDir(&zr.Reader, path.Join(run.Dir, "files"), filepath.Join(runDir, "files")); err != nil {
+			return nil, err
+		}
+		input := task.Input
+		for _, p := range []*string{&input.PromptFile, &input.ToolsFile, &input.ResponseSchema, &input.ReplayFile} {
+			if strings.HasPrefix(*p, "files/") {
+				*p = filepath.Join(runDir, filepath.FromSlash(*p))
+			}
+		}
+		if key, ok := lookupEnv(client.APIKeyEnv(input.NormalizedProtocol())); ok {
+			input.ApiKey = key
+		}
+		def, err := srv.CreateTask(server.TaskConfig{Name: task.Name + " (bundle)", Input: input})
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", task.Name, err)
+		}
+		defs = append(defs, def)
+	}
+	fmt.Fprintf(stderr, "已从 %s 创建 %d 个任务（导出于 %s，ait %s），可在任务列表中运行\n",
+		bundlePath, len(defs), manifest.CreatedAt.Format(time.DateTime), manifest.Environment.AitVersion)
+	if len(manifest.Args) > 0 {
+		fmt.Fprintf(stderr, "导出时的命令行参数: %s\n", strings.Join(manifest.Args, " "))
+	}
+	return defs, nil
+}
+
+func readZipJSON(zr *zip.Reader, name string, v any) error {
+	f, err := zr.Open(name)
+	if err != nil {
+		return fmt.Errorf("bundle 中缺少 %s: %w", name, err)
+	}
+	defer f.Close()
+	if err := json.NewDecoder(f).Decode(v); err != nil {
+		return fmt.Errorf("解析 %s 失败: %w", name, err)
+	}
+	return nil
+}
+
+// extractZipDir 把 zip 中 prefix 目录下的文件解压到 dest；条目路径不会越出 dest。
+func extractZipDir(zr *zip.Reader, prefix, dest string) error {
+	for _, f := range zr.File {
+		rel, ok := strings.CutPrefix(f.Name, prefix+"/")
+		if !ok || rel == "" || strings.HasSuffix(f.Name, "/") {
+			continue
+		}
+		target := filepath.Join(dest, filepath.FromSlash(rel))
+		if !strings.HasPrefix(target, filepath.Clean(dest)+string(filepath.Separator)) {
+			return fmt.Errorf("bundle 条目路径非法: %s", f.Name)
+		}
+		if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+			return err
+		}
+		if err := extractZipFile(f, target); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func extractZipFile(f *zip.File, target string) error {
+	src, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	dst, err := os.Create(target)
+	if err != nil {
+		return err
+	}
+	defer dst.Close()
+	_, err = io.Copy(dst, src)
+	return err
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"archive/zip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yinxulai/ait/internal/server"
+	"github.com/yinxulai/ait/internal/server/types"
+)
+
+func TestBundleRoundTrip(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	srv, err := server.New()
+	if err != nil {
+		t.Fatalf("server.New: %v", err)
+	}
+
+	dataDir := t.TempDir()
+	for name, content := range map[string]string{"a.txt": "第一题", "b.txt": "第二题"} {
+		if err := os.WriteFile(filepath.Join(dataDir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	def, err := srv.CreateTask(server.TaskConfig{Name: "bench", Input: types.Input{
+		Protocol: types.ProtocolOpenAICompletions, EndpointURL: "https://api.example.com/v1",
+		ApiKey: "sk-secret", Model: "gpt-4o", Count: 4, Concurrency: 2,
+		PromptMode: "file", PromptFile: filepath.Join(dataDir, "*.txt"),
+		WebhookURL: "https://hooks.example.com/secret",
+	}})
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+
+	out := filepath.Join(t.TempDir(), "run.zip")
+	state := &server.RunState{RunID: "run-1", TaskID: def.ID, ModeResult: &types.ReportData{TotalRequests: 4}}
+	n, err := writeBundle(out, []string{"--export-bundle", out}, srv, []*server.RunState{state})
+	if err != nil || n != 1 {
+		t.Fatalf("writeBundle = %d, %v", n, err)
+	}
+
+	// bundle 不含密钥与 webhook 地址
+	zr, err := zip.OpenReader(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer zr.Close()
+	var names []string
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+		rc, _ := f.Open()
+		data, _ := io.ReadAll(rc)
+		rc.Close()
+		if strings.Contains(string(data), "sk-secret") || strings.Contains(string(data), "hooks.example.com") {
+			t.Errorf("%s 泄露了密钥或 webhook 地址", f.Name)
+		}
+	}
+	for _, want := range []string{"manifest.json", "runs/run-1/task.json", "runs/run-1/result.json", "runs/run-1/files/prompts/0000-a.txt", "runs/run-1/files/prompts/0001-b.txt"} {
+		if !strings.Contains(strings.Join(names, "\n"), want) {
+			t.Errorf("bundle 缺少 %s，实际为 %v", want, names)
+		}
+	}
+
+	// 删除原 prompt 文件后仍能还原出可用的任务
+	os.RemoveAll(dataDir)
+	lookupEnv := func(key string) (string, bool) {
+		if key == "OPENAI_API_KEY" {
+			return "sk-env", true
+		}
+		return "", false
+	}
+	defs, err := restoreBundle(srv, out, t.TempDir(), lookupEnv, io.Discard)
+	if err != nil {
+		t.Fatalf("restoreBundle: %v", err)
+	}
+	if len(defs) != 1 {
+		t.Fatalf("restored %d tasks, want 1", len(defs))
+	}
+	got := defs[0]
+	if got.Name != "bench (bundle)" || got.Input.ApiKey != "sk-env" || got.Input.Model != "gpt-4o" || got.Input.Count != 4 || got.Input.WebhookURL != "" {
+		t.Fatalf("restored task = %+v", got)
+	}
+	files, _ := filepath.Glob(got.Input.PromptFile)
+	if len(files) != 2 {
+		t.Fatalf("restored prompt files = %v", files)
+	}
+	if data, _ := os.ReadFile(files[0]); string(data) != "第一题" {
+		t.Fatalf("first prompt = %q", data)
+	}
+}
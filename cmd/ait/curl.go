@@ -0,0 +1,177 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yinxulai/ait/internal/server"
+	"github.com/yinxulai/ait/internal/server/client"
+	"github.com/yinxulai/ait/internal/server/task"
+	"github.com/yinxulai/ait/internal/server/types"
+)
+
+// curlSelection --export-curl 选中的请求：failed 为所有失败请求，否则为指定的请求序号。
+type curlSelection struct {
+	failed  bool
+	indexes []int
+}
+
+// parseCurlSelection 解析 --export-curl 的取值：failed，或逗号分隔的请求序号如 "0,5,12"。
+func parseCurlSelection(s string) (curlSelection, error) {
+	s = strings.TrimSpace(s)
+	if s == "failed" {
+		return curlSelection{failed: true}, nil
+	}
+	var sel curlSelection
+	for _, part := range strings.Split(s, ",") {
+		index, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil || index < 0 {
+			return curlSelection{}, fmt.Errorf("%q 不是 failed 或非负的请求序号", part)
+		}
+		if !slices.Contains(sel.indexes, index) {
+			sel.indexes = append(sel.indexes, index)
+		}
+	}
+	slices.Sort(sel.indexes)
+	return sel, nil
+}
+
+// curlEntry 一条导出的 curl 命令；body 非空时需写入 bodyFile。
+type curlEntry struct {
+	comment  string
+	command  string
+	bodyFile string
+	body     []byte
+}
+
+// writeSessionCurl 执行 --export-curl：把本次会话中选中请求的等价 curl 命令写入 path，返回导出的命令数。
+// 请求体超过 client.CurlInlineBodyLimit 时写入 path 同目录下的 <文件名>-<run_id>-<序号>.json，命令中以 @file 引用。
+// 输入长度扫描的各轮 prompt 由长度生成、按 prompt 文件分场景运行的各轮只取一个文件，均无法按序号还原，跳过。
+func writeSessionCurl(path, selection string, srv server.Server, since time.Time) (int, error) {
+	sel, err := parseCurlSelection(selection)
+	if err != nil {
+		return 0, err
+	}
+	base := strings.TrimSuffix(path, filepath.Ext(path))
+	var entries []curlEntry
+	for _, state := range sessionRuns(srv, since) {
+		if _, ok := state.ModeResult.(*types.InputLengthSweepResult); ok {
+			continue
+		}
+		if _, ok := state.ModeResult.(*types.PromptFileRunsResult); ok {
+			continue
+		}
+		def, err := srv.GetTask(state.TaskID)
+		if err != nil {
+			return 0, err
+		}
+		runEntries, err := curlEntries(def, state, sel, func(index int) string {
+			return fmt.Sprintf("%s-%s-%d.json", base, state.RunID, index)
+		})
+		if err != nil {
+			return 0, fmt.Errorf("%s: %w", def.Name, err)
+		}
+		entries = append(entries, runEntries...)
+	}
+
+	var b strings.Builder
+	b.WriteString("#!/bin/sh\n# ait --export-curl 导出的等价请求，密钥通过环境变量引用\n")
+	for _, e := range entries {
+		if e.bodyFile != "" {
+			if err := os.WriteFile(e.bodyFile, e.body, 0644); err != nil {
+				return 0, err
+			}
+		}
+		fmt.Fprintf(&b, "\n%s\n%s\n", e.comment, e.command)
+	}
+	if err := os.WriteFile(path, []byte(b.String()), 0755); err != nil {
+		return 0, err
+	}
+	return len(entries), nil
+}
+
+// curlEntries 还原一次运行中选中请求的 curl 命令：请求体优先使用运行时记录的实际请求体，其次用记录的 prompt
+// 按运行时相同的方式构造；都没有记录（早期版本的运行记录或未执行的序号）时才按请求序号还原 prompt。
+// A/B 对比中序号在流式一轮内的请求按流式构造。bodyFile 返回该序号请求体落盘的路径。
+func curlEntries(def types.TaskDefinition, state *server.RunState, sel curlSelection, bodyFile func(index int) string) ([]curlEntry, error) {
+	failures := map[int]string{}
+	recorded := map[int]*types.RequestMetrics{}
+	indexes := sel.indexes
+	for _, r := range state.Requests {
+		if r == nil {
+			continue
+		}
+		recorded[r.Index] = r
+		if r.Success {
+			continue
+		}
+		failures[r.Index] = r.ErrorMessage
+		if sel.failed {
+			indexes = append(indexes, r.Index)
+		}
+	}
+	if len(indexes) == 0 {
+		return nil, nil
+	}
+	slices.Sort(indexes)
+
+	hydrated, err := task.HydrateInput(def.Input)
+	if err != nil {
+		return nil, err
+	}
+	c, err := client.NewClient(hydrated, nil)
+	if err != nil {
+		return nil, err
+	}
+	source := hydrated.PromptSource
+	streamCount, _ := hydrated.CompareStreamCounts()
+	apiKeyEnv := client.APIKeyEnv(hydrated.NormalizedProtocol())
+
+	entries := make([]curlEntry, 0, len(indexes))
+	for _, index := range indexes {
+		var req *http.Request
+		var body []byte
+		r := recorded[index]
+		if r != nil && r.RequestBody != "" {
+			// 记录的请求体包含实际发送的 prompt 与参数改写（如 max_completion_tokens），原样复现
+			req, body, err = client.BuildCurlRequest(context.Background(), c, "", "", r.RequestBody, false)
+		} else if userPrompt := recordedPrompt(r, source, index); hydrated.PromptMode == "raw" {
+			req, body, err = client.BuildCurlRequest(context.Background(), c, "", "", userPrompt, false)
+		} else {
+			stream := hydrated.Stream
+			if hydrated.CompareStream {
+				stream = index < streamCount
+			}
+			req, body, err = client.BuildCurlRequest(context.Background(), c, source.GetSystemContent(), userPrompt, "", stream)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		e := curlEntry{comment: fmt.Sprintf("# %s · %s · 请求 #%d", def.Name, state.RunID, index)}
+		if msg := failures[index]; msg != "" {
+			e.comment += " · " + strings.Join(strings.Fields(msg), " ")
+		}
+		if len(body) > client.CurlInlineBodyLimit {
+			e.bodyFile, e.body = bodyFile(index), body
+		}
+		e.command = client.CurlCommand(req, body, apiKeyEnv, e.bodyFile)
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+// recordedPrompt 返回请求记录中实际发送的 prompt；没有记录时按请求序号从 source 还原。
+func recordedPrompt(r *types.RequestMetrics, source types.PromptSource, index int) string {
+	if r != nil && r.Prompt != "" {
+		return r.Prompt
+	}
+	return source.GetContentByIndex(index)
+}
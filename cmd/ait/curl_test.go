@@ -0,0 +1,99 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/yinxulai/ait/internal/server"
+	"github.com/yinxulai/ait/internal/server/client"
+	"github.com/yinxulai/ait/internal/server/types"
+)
+
+func TestParseCurlSelection(t *testing.T) {
+	sel, err := parseCurlSelection("failed")
+	if err != nil || !sel.failed {
+		t.Fatalf("failed: got %+v, %v", sel, err)
+	}
+	sel, err = parseCurlSelection(" 5, 0,5 ")
+	if err != nil || sel.failed || len(sel.indexes) != 2 || sel.indexes[0] != 0 || sel.indexes[1] != 5 {
+		t.Fatalf("indexes: got %+v, %v", sel, err)
+	}
+	for _, bad := range []string{"", "all", "1,-2", "1,,2"} {
+		if _, err := parseCurlSelection(bad); err == nil {
+			t.Errorf("parseCurlSelection(%q): expected error", bad)
+		}
+	}
+}
+
+func TestCurlEntries(t *testing.T) {
+	def := types.TaskDefinition{Name: "bench", Input: types.Input{
+		Protocol: types.ProtocolOpenAICompletions, EndpointURL: "https://api.example.com", ApiKey: "sk-secret", Model: "gpt-4o",
+		PromptMode: "text", PromptText: "第 {{index}} 题", Stream: true, Count: 10,
+	}}
+	state := &server.RunState{
+		RunID: "run-1",
+		Requests: []*types.RequestMetrics{
+			{Index: 0, Success: true},
+			{Index: 3, ErrorMessage: "HTTP 502\nbad gateway"},
+		},
+	}
+	bodyFile := func(index int) string { return "body.json" }
+
+	entries, err := curlEntries(def, state, curlSelection{failed: true}, bodyFile)
+	if err != nil {
+		t.Fatalf("curlEntries: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("entries = %+v, want the failed request only", entries)
+	}
+	e := entries[0]
+	if e.comment != "# bench · run-1 · 请求 #3 · HTTP 502 bad gateway" {
+		t.Errorf("comment = %q", e.comment)
+	}
+	if !strings.Contains(e.command, "第 3 题") || !strings.Contains(e.command, `"stream":true`) || strings.Contains(e.command, "sk-secret") {
+		t.Errorf("command = %s", e.command)
+	}
+	if e.bodyFile != "" {
+		t.Errorf("short body should be inline, got file %q", e.bodyFile)
+	}
+
+	// 指定序号时导出任意请求，过长的请求体改为落盘引用
+	def.Input.PromptText = strings.Repeat("长", client.CurlInlineBodyLimit)
+	entries, err = curlEntries(def, state, curlSelection{indexes: []int{0}}, bodyFile)
+	if err != nil {
+		t.Fatalf("curlEntries: %v", err)
+	}
+	if len(entries) != 1 || entries[0].bodyFile != "body.json" || len(entries[0].body) <= client.CurlInlineBodyLimit ||
+		!strings.Contains(entries[0].command, "--data-binary '@body.json'") {
+		t.Fatalf("entries = %+v, want request 0 with body file", entries)
+	}
+}
+
+// TestCurlEntries_UsesRecordedRequest 有记录时导出实际发送的请求体或 prompt，而不是按序号重新生成。
+func TestCurlEntries_UsesRecordedRequest(t *testing.T) {
+	def := types.TaskDefinition{Name: "bench", Input: types.Input{
+		Protocol: types.ProtocolOpenAICompletions, EndpointURL: "https://api.example.com", ApiKey: "sk-secret", Model: "gpt-4o",
+		PromptMode: "text", PromptText: "第 {{index}} 题 {{uuid}}", Count: 10,
+	}}
+	sentBody := `{"model":"gpt-4o","messages":[{"role":"user","content":"第 1 题 7d1e"}],"max_completion_tokens":64}`
+	state := &server.RunState{
+		RunID: "run-1",
+		Requests: []*types.RequestMetrics{
+			{Index: 1, ErrorMessage: "HTTP 500", Prompt: "第 1 题 7d1e", RequestBody: sentBody},
+			{Index: 2, ErrorMessage: "prompt 命令执行超时", Prompt: "第 2 题 9a0b"},
+		},
+	}
+	entries, err := curlEntries(def, state, curlSelection{failed: true}, func(int) string { return "body.json" })
+	if err != nil {
+		t.Fatalf("curlEntries: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("entries = %+v, want 2", entries)
+	}
+	if !strings.Contains(entries[0].command, client.ShellQuote(sentBody)) {
+		t.Errorf("request 1 should reuse the recorded body, got %s", entries[0].command)
+	}
+	if !strings.Contains(entries[1].command, "第 2 题 9a0b") {
+		t.Errorf("request 2 should use the recorded prompt, got %s", entries[1].command)
+	}
+}
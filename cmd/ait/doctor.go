@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/yinxulai/ait/internal/server/client"
+	"github.com/yinxulai/ait/internal/server/doctor"
+	"github.com/yinxulai/ait/internal/server/types"
+)
+
+// doctorStatusSymbols 诊断结论对应的前缀符号
+var doctorStatusSymbols = map[doctor.Status]string{
+	doctor.StatusPass: "✅",
+	doctor.StatusWarn: "⚠️ ",
+	doctor.StatusFail: "❌",
+	doctor.StatusSkip: "⏭️ ",
+}
+
+// runDoctor 执行 ait doctor 子命令：按顺序诊断接口的 DNS 解析、TCP/TLS 连通、鉴权与最小请求，
+// 每步输出结论、原因与修复建议。有步骤失败时返回 1。
+func runDoctor(args []string, lookupEnv func(string) (string, bool), stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("ait doctor", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	protocol := fs.String("protocol", "openai", "接口协议：openai、openai-responses 或 anthropic")
+	var baseURL, apiKey string
+	for _, name := range []string{"baseUrl", "base-url"} {
+		fs.StringVar(&baseURL, name, "", "接口的 baseUrl，如 https://api.openai.com/v1；未指定时读取 OPENAI_BASE_URL / ANTHROPIC_BASE_URL")
+	}
+	for _, name := range []string{"apiKey", "api-key"} {
+		fs.StringVar(&apiKey, name, "", "API Key；未指定时读取 OPENAI_API_KEY / ANTHROPIC_API_KEY")
+	}
+	model := fs.String("model", "", "模型名称")
+	proxy := fs.String("proxy", "", "访问接口使用的代理，如 http://127.0.0.1:7890")
+	timeout := fs.Duration("timeout", 30*time.Second, "单个请求的超时")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	input := types.Input{
+		Protocol: types.NormalizeProtocol(*protocol),
+		BaseUrl:  baseURL,
+		ApiKey:   apiKey,
+		Model:    *model,
+		ProxyURL: *proxy,
+		Timeout:  *timeout,
+	}
+	if input.Protocol == types.ProtocolTritonGRPC {
+		fmt.Fprintln(stderr, "ait doctor 暂不支持 triton-grpc 协议")
+		return 2
+	}
+	envPrefix := strings.TrimSuffix(client.APIKeyEnv(input.Protocol), "_API_KEY")
+	if input.BaseUrl == "" {
+		input.BaseUrl, _ = lookupEnv(envPrefix + "_BASE_URL")
+	}
+	if input.ApiKey == "" {
+		input.ApiKey, _ = lookupEnv(client.APIKeyEnv(input.Protocol))
+	}
+	if input.Model == "" {
+		fmt.Fprintln(stderr, "用法: ait doctor --baseUrl https://api.openai.com/v1 --apiKey sk-... --model gpt-4o")
+		return 2
+	}
+
+	target := doctor.NewTarget(input)
+	fmt.Fprintf(stdout, "诊断 %s（%s，模型 %s）\n\n", input.ResolvedEndpointURL(), input.Protocol, input.Model)
+	reports := doctor.Run(context.Background(), target, doctor.DefaultSteps(), func(r doctor.Report) {
+		printDoctorReport(stdout, r)
+	})
+	if doctor.Failed(reports) {
+		fmt.Fprintln(stdout, "\n诊断未通过，请按上面的建议修复后重试")
+		return 1
+	}
+	fmt.Fprintln(stdout, "\n诊断通过，可以开始压测")
+	return 0
+}
+
+func printDoctorReport(w io.Writer, r doctor.Report) {
+	line := doctorStatusSymbols[r.Status] + " " + r.Name
+	switch {
+	case r.Detail != "":
+		line += ": " + r.Detail
+	case r.Status == doctor.StatusSkip:
+		line += ": 前置步骤失败，未执行"
+	}
+	fmt.Fprintln(w, line)
+	if r.Hint != "" {
+		fmt.Fprintf(w, "   建议: %s\n", r.Hint)
+	}
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRunDoctor(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer sk-good" {
+			http.Error(w, `{"error":{"message":"Incorrect API key provided"}}`, http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/models":
+			fmt.Fprint(w, `{"data":[{"id":"gpt-4o"}]}`)
+		case "/v1/chat/completions":
+			body, _ := io.ReadAll(r.Body)
+			if !strings.Contains(string(body), `"stream":true`) {
+				fmt.Fprint(w, `{"choices":[{"message":{"content":"Hi"}}],"usage":{"prompt_tokens":1,"completion_tokens":1}}`)
+				return
+			}
+			fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{\"content\":\"Hi\"}}]}\n\ndata: [DONE]\n\n")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	// baseUrl 与 key 从环境变量读取
+	env := envMap(map[string]string{"OPENAI_BASE_URL": srv.URL + "/v1", "OPENAI_API_KEY": "sk-good"})
+	var stdout, stderr bytes.Buffer
+	if code := runDoctor([]string{"--model", "gpt-4o"}, env, &stdout, &stderr); code != 0 {
+		t.Fatalf("exit code = %d\n%s%s", code, stdout.String(), stderr.String())
+	}
+	for _, want := range []string{"✅ DNS 解析", "✅ TCP / TLS 连通", "✅ 鉴权（GET /models）: 返回 1 个模型", "✅ 最小请求", "✅ 流式最小请求", "诊断通过"} {
+		if !strings.Contains(stdout.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, stdout.String())
+		}
+	}
+
+	stdout.Reset()
+	code := runDoctor([]string{"--baseUrl", srv.URL + "/v1", "--apiKey", "Bearer sk-good", "--model", "gpt-4o"}, envMap(nil), &stdout, &stderr)
+	if code != 1 || !strings.Contains(stdout.String(), "❌ 鉴权") || !strings.Contains(stdout.String(), "不要带 \"Bearer \" 前缀") {
+		t.Errorf("bearer prefix: code %d\n%s", code, stdout.String())
+	}
+
+	if code := runDoctor(nil, envMap(nil), &stdout, &stderr); code != 2 {
+		t.Errorf("missing model: code = %d, want 2", code)
+	}
+}
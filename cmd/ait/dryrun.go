@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/yinxulai/ait/internal/server"
+	"github.com/yinxulai/ait/internal/server/client"
+	"github.com/yinxulai/ait/internal/server/task"
+	"github.com/yinxulai/ait/internal/server/types"
+)
+
+// runDryRun 执行 --dry-run：为每个已保存的任务构造一次完整请求，写入 outputPath（为空时写 stdout）后返回退出码，
+// 不发起任何请求。有任务构造失败时返回 1。
+func runDryRun(srv server.Server, outputPath string, stderr io.Writer) int {
+	tasks, err := srv.ListTasks()
+	if err != nil {
+		fmt.Fprintf(stderr, "读取任务失败: %v\n", err)
+		return 1
+	}
+	if len(tasks) == 0 {
+		fmt.Fprintln(stderr, "没有已保存的任务，请先在 TUI / Web UI 中创建任务")
+		return 1
+	}
+
+	w := io.Writer(os.Stdout)
+	if outputPath != "" {
+		f, err := os.Create(outputPath)
+		if err != nil {
+			fmt.Fprintf(stderr, "创建 --dry-run-output 文件失败: %v\n", err)
+			return 1
+		}
+		defer f.Close()
+		w = f
+	}
+	if failed := writeDryRun(w, tasks); failed > 0 {
+		fmt.Fprintf(stderr, "%d 个任务无法构造请求\n", failed)
+		return 1
+	}
+	return 0
+}
+
+// writeDryRun 按任务顺序写出每个任务第一个请求的方法、URL、请求头（密钥打码）与美化后的请求体，返回构造失败的任务数。
+// integrity 任务的请求来自测试集，跳过。
+func writeDryRun(w io.Writer, tasks []types.TaskOverview) int {
+	failed := 0
+	for _, t := range tasks {
+		fmt.Fprintf(w, "=== %s (%s, %s) ===\n", t.Name, t.Input.Model, t.Input.NormalizedProtocol())
+		if t.Input.RunMode() == "integrity" {
+			fmt.Fprint(w, "integrity 任务的请求来自测试集，跳过\n\n")
+			continue
+		}
+		req, err := dryRunTask(t.Input)
+		if err != nil {
+			fmt.Fprintf(w, "构造请求失败: %v\n\n", err)
+			failed++
+			continue
+		}
+		fmt.Fprintf(w, "%s %s\n", req.Method, req.URL)
+		for _, h := range req.Headers {
+			fmt.Fprintf(w, "%s: %s\n", h[0], h[1])
+		}
+		fmt.Fprintf(w, "\n%s\n\n", req.Body)
+	}
+	return failed
+}
+
+// dryRunTask 以运行时相同的方式解析 prompt 并创建客户端，构造序号为 0 的请求但不发送
+func dryRunTask(input types.Input) (*client.DryRunRequest, error) {
+	hydrated, err := task.HydrateInput(input)
+	if err != nil {
+		return nil, err
+	}
+	c, err := client.NewClient(hydrated, nil)
+	if err != nil {
+		return nil, err
+	}
+	userPrompt := hydrated.PromptSource.GetContentByIndex(0)
+	if hydrated.PromptMode == "raw" {
+		return client.DryRun(context.Background(), c, "", "", userPrompt, false)
+	}
+	return client.DryRun(context.Background(), c, hydrated.PromptSource.GetSystemContent(), userPrompt, "", hydrated.Stream)
+}
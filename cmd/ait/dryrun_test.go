@@ -0,0 +1,53 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/yinxulai/ait/internal/server/types"
+)
+
+func TestWriteDryRun(t *testing.T) {
+	tasks := []types.TaskOverview{
+		{TaskDefinition: types.TaskDefinition{Name: "openai", Input: types.Input{
+			Protocol: types.ProtocolOpenAICompletions, BaseUrl: "https://api.example.com", ApiKey: "sk-1234567890abcdef",
+			Model: "gpt-4o", Stream: true, PromptMode: "text", PromptText: "hello",
+		}}},
+		{TaskDefinition: types.TaskDefinition{Name: "anthropic-raw", Input: types.Input{
+			Protocol: types.ProtocolAnthropicMessages, BaseUrl: "https://api.anthropic.com", ApiKey: "sk-ant-abcdefgh12345678",
+			Model: "claude", PromptMode: "raw", PromptText: `{"model":"claude","max_tokens":16,"messages":[{"role":"user","content":"hi"}]}`,
+		}}},
+		{TaskDefinition: types.TaskDefinition{Name: "suite", Input: types.Input{
+			Protocol: types.ProtocolOpenAICompletions, Model: "gpt-4o", Mode: "integrity",
+		}}},
+		{TaskDefinition: types.TaskDefinition{Name: "broken", Input: types.Input{
+			Protocol: types.ProtocolOpenAICompletions, Model: "gpt-4o", PromptMode: "file",
+		}}},
+	}
+
+	var buf bytes.Buffer
+	if failed := writeDryRun(&buf, tasks); failed != 1 {
+		t.Errorf("failed = %d, want 1", failed)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"=== openai (gpt-4o, openai-completions) ===",
+		"POST https://api.example.com/v1/chat/completions",
+		"Authorization: Bearer sk-1****cdef",
+		`"content": "hello"`,
+		"POST https://api.anthropic.com/v1/messages",
+		"X-Api-Key: sk-a****5678",
+		`"max_tokens": 16`,
+		"integrity 任务的请求来自测试集，跳过",
+		"=== broken",
+		"构造请求失败",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "sk-1234567890abcdef") || strings.Contains(out, "sk-ant-abcdefgh12345678") {
+		t.Errorf("API keys must be masked:\n%s", out)
+	}
+}
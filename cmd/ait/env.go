@@ -0,0 +1,87 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// envPrefix 环境变量前缀：flag "lang" 对应 AIT_LANG，"self-stats" 对应 AIT_SELF_STATS。
+const envPrefix = "AIT_"
+
+// 参数取值来源，优先级：命令行 > 环境变量 > 配置文件 > 默认值。
+const (
+	sourceFlag    = "flag"
+	sourceEnv     = "env"
+	sourceConfig  = "config"
+	sourceDefault = "default"
+)
+
+// envName 返回 flag 对应的环境变量名。
+func envName(flagName string) string {
+	return envPrefix + strings.ToUpper(strings.ReplaceAll(flagName, "-", "_"))
+}
+
+// applyEnvFlags 在 flag 解析后，用 AIT_ 前缀的环境变量填充未在命令行显式设置的 flag，
+// 返回每个 flag 的取值来源（flag / env / default）。
+func applyEnvFlags(fs *flag.FlagSet, lookupEnv func(string) (string, bool)) (map[string]string, error) {
+	sources := make(map[string]string)
+	fs.Visit(func(f *flag.Flag) {
+		sources[f.Name] = sourceFlag
+	})
+
+	var err error
+	fs.VisitAll(func(f *flag.Flag) {
+		if err != nil || sources[f.Name] == sourceFlag {
+			return
+		}
+		sources[f.Name] = sourceDefault
+		name := envName(f.Name)
+		value, ok := lookupEnv(name)
+		if !ok {
+			return
+		}
+		if setErr := fs.Set(f.Name, value); setErr != nil {
+			err = envFlagError(f, name, value)
+			return
+		}
+		sources[f.Name] = sourceEnv
+	})
+	return sources, err
+}
+
+// envFlagError 生成环境变量取值无效时的提示，说明期望的取值格式。
+func envFlagError(f *flag.Flag, env, value string) error {
+	if bf, ok := f.Value.(interface{ IsBoolFlag() bool }); ok && bf.IsBoolFlag() {
+		return fmt.Errorf("环境变量 %s=%q 无效：应为布尔值（true/false/1/0）", env, value)
+	}
+	if getter, ok := f.Value.(flag.Getter); ok {
+		switch getter.Get().(type) {
+		case int, int64, uint, uint64:
+			return fmt.Errorf("环境变量 %s=%q 无效：应为整数", env, value)
+		}
+	}
+	return fmt.Errorf("环境变量 %s=%q 无效", env, value)
+}
+
+// printFlagSources 按名称顺序输出每个 flag 的取值与来源，便于排查参数为何未生效。
+func printFlagSources(w io.Writer, fs *flag.FlagSet, sources map[string]string) {
+	names := make([]string, 0, len(sources))
+	for name := range sources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		f := fs.Lookup(name)
+		if f == nil {
+			continue
+		}
+		source := sources[name]
+		if source == sourceEnv {
+			source += " " + envName(name)
+		}
+		fmt.Fprintf(w, "  %-10s = %-8q (%s)\n", name, f.Value.String(), source)
+	}
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"io"
+	"strings"
+	"testing"
+)
+
+func newTestFlagSet() (*flag.FlagSet, *bool, *string, *int) {
+	fs := flag.NewFlagSet("ait", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	web := fs.Bool("web", false, "")
+	lang := fs.String("lang", "", "")
+	concurrency := fs.Int("concurrency", 1, "")
+	return fs, web, lang, concurrency
+}
+
+func envMap(m map[string]string) func(string) (string, bool) {
+	return func(key string) (string, bool) {
+		v, ok := m[key]
+		return v, ok
+	}
+}
+
+func TestApplyEnvFlags_Priority(t *testing.T) {
+	fs, web, lang, concurrency := newTestFlagSet()
+	if err := fs.Parse([]string{"-lang", "zh"}); err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	sources, err := applyEnvFlags(fs, envMap(map[string]string{
+		"AIT_LANG":        "en", // 命令行已设置，环境变量不生效
+		"AIT_WEB":         "true",
+		"AIT_CONCURRENCY": "10",
+	}))
+	if err != nil {
+		t.Fatalf("applyEnvFlags: %v", err)
+	}
+	if *lang != "zh" || sources["lang"] != sourceFlag {
+		t.Errorf("lang = %q (%s), want zh (flag)", *lang, sources["lang"])
+	}
+	if !*web || sources["web"] != sourceEnv {
+		t.Errorf("web = %v (%s), want true (env)", *web, sources["web"])
+	}
+	if *concurrency != 10 || sources["concurrency"] != sourceEnv {
+		t.Errorf("concurrency = %d (%s), want 10 (env)", *concurrency, sources["concurrency"])
+	}
+}
+
+func TestApplyEnvFlags_DefaultWhenUnset(t *testing.T) {
+	fs, web, _, concurrency := newTestFlagSet()
+	_ = fs.Parse(nil)
+
+	sources, err := applyEnvFlags(fs, envMap(nil))
+	if err != nil {
+		t.Fatalf("applyEnvFlags: %v", err)
+	}
+	if *web || *concurrency != 1 {
+		t.Errorf("defaults changed: web=%v concurrency=%d", *web, *concurrency)
+	}
+	for _, name := range []string{"web", "lang", "concurrency"} {
+		if sources[name] != sourceDefault {
+			t.Errorf("%s source = %q, want default", name, sources[name])
+		}
+	}
+}
+
+func TestApplyEnvFlags_InvalidValues(t *testing.T) {
+	tests := []struct {
+		env, value, want string
+	}{
+		{"AIT_WEB", "yes please", "布尔值"},
+		{"AIT_CONCURRENCY", "ten", "整数"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.env, func(t *testing.T) {
+			fs, _, _, _ := newTestFlagSet()
+			_ = fs.Parse(nil)
+			_, err := applyEnvFlags(fs, envMap(map[string]string{tt.env: tt.value}))
+			if err == nil || !strings.Contains(err.Error(), tt.env) || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("error = %v, want mention of %s and %s", err, tt.env, tt.want)
+			}
+		})
+	}
+}
+
+func TestEnvName(t *testing.T) {
+	if got := envName("self-stats"); got != "AIT_SELF_STATS" {
+		t.Errorf("envName = %q, want AIT_SELF_STATS", got)
+	}
+}
+
+func TestPrintFlagSources(t *testing.T) {
+	fs, _, _, _ := newTestFlagSet()
+	_ = fs.Parse([]string{"-web"})
+	sources, _ := applyEnvFlags(fs, envMap(map[string]string{"AIT_CONCURRENCY": "4"}))
+	sources["lang"] = sourceConfig
+
+	var buf bytes.Buffer
+	printFlagSources(&buf, fs, sources)
+	out := buf.String()
+	for _, want := range []string{"(flag)", "(env AIT_CONCURRENCY)", "(config)", "concurrency"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Index(out, "concurrency") > strings.Index(out, "web") {
+		t.Errorf("flags should be sorted by name:\n%s", out)
+	}
+}
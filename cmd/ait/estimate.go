@@ -0,0 +1,140 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/mattn/go-isatty"
+
+	"github.com/yinxulai/ait/internal/server"
+	"github.com/yinxulai/ait/internal/server/config"
+)
+
+// defaultPlanRequestLimit --request-limit 的默认值：预计请求数超过该值时需要确认
+const defaultPlanRequestLimit = 1000
+
+// estimateHistoryRuns 估算历史平均输出 token 时每个任务最多读取的最近运行数
+const estimateHistoryRuns = 5
+
+// printPlanEstimate 把测试计划的预估按场景逐行写入 w，最后一行为合计。
+func printPlanEstimate(w io.Writer, est config.PlanEstimate) {
+	fmt.Fprintln(w, "预估：")
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, s := range est.Scenarios {
+		requests := fmt.Sprintf("请求 %d", s.Requests)
+		if s.Unbounded {
+			requests = "请求 未知（turbo / integrity 模式）"
+		}
+		fmt.Fprintf(tw, "  %s · %s\t%s\t输入 ≈ %d tokens\t%s\t%s\n", s.Name, s.Model, requests, s.InputTokens,
+			scenarioOutputText(s), costText(s.Priced, s.CostMin, s.CostMax))
+	}
+	output := fmt.Sprintf("输出 %s tokens", tokenRangeText(est.OutputMin, est.OutputMax))
+	priced := false
+	for _, s := range est.Scenarios {
+		priced = priced || s.Priced
+	}
+	cost := costText(priced, est.CostMin, est.CostMax)
+	if priced && len(est.Unpriced) > 0 {
+		cost += fmt.Sprintf("（不含未配置价格的 %s）", strings.Join(est.Unpriced, ", "))
+	}
+	fmt.Fprintf(tw, "  合计\t请求 %d\t输入 ≈ %d tokens\t%s\t%s\n", est.Requests, est.InputTokens, output, cost)
+	tw.Flush()
+	if est.Partial {
+		fmt.Fprintln(w, "  部分场景的请求数或输出 token 无法预估，合计仅为下限")
+	}
+}
+
+func scenarioOutputText(s config.ScenarioEstimate) string {
+	switch s.OutputSource {
+	case config.OutputFromMaxTokens:
+		return fmt.Sprintf("输出 %s tokens（max_tokens）", tokenRangeText(s.OutputMin, s.OutputMax))
+	case config.OutputFromHistory:
+		return fmt.Sprintf("输出 ≈ %d tokens（历史均值）", s.OutputMax)
+	}
+	return "输出 未知（未设置 max_tokens 且无历史）"
+}
+
+// tokenRangeText 输出 token 区间：上下限相同时为 ≈ n，下限为 0 时为 ≤ n。
+func tokenRangeText(lo, hi int64) string {
+	switch {
+	case lo == hi:
+		return fmt.Sprintf("≈ %d", hi)
+	case lo == 0:
+		return fmt.Sprintf("≤ %d", hi)
+	}
+	return fmt.Sprintf("%d ~ %d", lo, hi)
+}
+
+func costText(priced bool, lo, hi float64) string {
+	if !priced {
+		return "费用 未配置价格"
+	}
+	if lo == hi {
+		return fmt.Sprintf("费用 ≈ %.4f", hi)
+	}
+	return fmt.Sprintf("费用 %.4f ~ %.4f", lo, hi)
+}
+
+// planConfirmReasons 返回需要用户确认的原因：预计费用上限超过 costLimit，或预计请求数超过 requestLimit；
+// 限制为 0 时不检查对应项。
+func planConfirmReasons(est config.PlanEstimate, costLimit float64, requestLimit int) []string {
+	var reasons []string
+	if costLimit > 0 && est.CostMax > costLimit {
+		reasons = append(reasons, fmt.Sprintf("预计费用上限 %.4f 超过 --cost-limit %.4f", est.CostMax, costLimit))
+	}
+	if requestLimit > 0 && est.Requests > requestLimit {
+		reasons = append(reasons, fmt.Sprintf("预计请求数 %d 超过 --request-limit %d", est.Requests, requestLimit))
+	}
+	return reasons
+}
+
+// confirmPlan 在 w 上提示并从 r 读取一行回答，y / yes（不区分大小写）视为确认。
+func confirmPlan(r io.Reader, w io.Writer, reasons []string) bool {
+	for _, reason := range reasons {
+		fmt.Fprintf(w, "  ! %s\n", reason)
+	}
+	fmt.Fprint(w, "确认开始执行？[y/N] ")
+	line, _ := bufio.NewReader(r).ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(line)) {
+	case "y", "yes":
+		return true
+	}
+	return false
+}
+
+// historicalOutputTokens 从已保存任务的最近几次已完成标准运行估算各模型的平均单请求输出 token 数（TPM / RPM）。
+func historicalOutputTokens(srv server.Server) map[string]float64 {
+	tasks, err := srv.ListTasks()
+	if err != nil {
+		return nil
+	}
+	sums := map[string]float64{}
+	counts := map[string]int{}
+	for _, t := range tasks {
+		history, err := srv.ListTaskRunHistory(t.ID, estimateHistoryRuns)
+		if err != nil {
+			continue
+		}
+		for _, run := range history {
+			if run.Mode != "standard" || run.Status != string(server.RunStatusCompleted) || run.RPM <= 0 || run.TPM <= 0 {
+				continue
+			}
+			sums[run.Model] += run.TPM / run.RPM
+			counts[run.Model]++
+		}
+	}
+	avg := make(map[string]float64, len(sums))
+	for model, sum := range sums {
+		avg[model] = sum / float64(counts[model])
+	}
+	return avg
+}
+
+// isTerminal 返回 f 是否连接到终端。
+func isTerminal(f *os.File) bool {
+	return isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd())
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/yinxulai/ait/internal/server/config"
+)
+
+func TestPlanConfirmReasons(t *testing.T) {
+	est := config.PlanEstimate{Requests: 5000, CostMax: 12.5}
+	if got := planConfirmReasons(est, 10, 1000); len(got) != 2 {
+		t.Errorf("reasons = %v, want cost and requests", got)
+	}
+	if got := planConfirmReasons(est, 20, 0); len(got) != 0 {
+		t.Errorf("reasons = %v, want none when within cost limit and request limit disabled", got)
+	}
+	if got := planConfirmReasons(est, 0, 1000); len(got) != 1 || !strings.Contains(got[0], "--request-limit") {
+		t.Errorf("reasons = %v, want request limit only", got)
+	}
+}
+
+func TestConfirmPlan(t *testing.T) {
+	for answer, want := range map[string]bool{"y\n": true, "YES\n": true, "n\n": false, "\n": false, "": false} {
+		var out bytes.Buffer
+		if got := confirmPlan(strings.NewReader(answer), &out, []string{"too many"}); got != want {
+			t.Errorf("answer %q: got %v, want %v", answer, got, want)
+		}
+		if !strings.Contains(out.String(), "too many") {
+			t.Errorf("prompt %q missing reason", out.String())
+		}
+	}
+}
+
+func TestPrintPlanEstimate(t *testing.T) {
+	var out bytes.Buffer
+	printPlanEstimate(&out, config.PlanEstimate{
+		Scenarios: []config.ScenarioEstimate{
+			{Name: "c1", Model: "a", Requests: 10, InputTokens: 20, OutputMax: 1000, OutputSource: config.OutputFromMaxTokens, Priced: true, CostMin: 0.001, CostMax: 0.002},
+			{Name: "c2", Model: "b", Unbounded: true},
+		},
+		Requests: 10, InputTokens: 20, OutputMax: 1000, CostMin: 0.001, CostMax: 0.002,
+		Partial: true, Unpriced: []string{"b"},
+	})
+	for _, want := range []string{"≤ 1000 tokens（max_tokens）", "0.0010 ~ 0.0020", "请求 未知", "不含未配置价格的 b", "合计仅为下限"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, out.String())
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/yinxulai/ait/internal/i18n"
+)
+
+// explainColumns 结果表（含"按目标 IP"小表）各列的说明文案，按 --explain 追加在结果表之后。
+// 表头保持英文机器可读，说明随 --lang 切换语言。
+var explainColumns = []struct {
+	column string
+	key    i18n.Key
+}{
+	{"model", i18n.KExplainModel},
+	{"stream_mode", i18n.KExplainStreamMode},
+	{"concurrency", i18n.KExplainConcurrency},
+	{"total_requests", i18n.KExplainTotalRequests},
+	{"success_rate_percent", i18n.KExplainSuccessRate},
+	{"avg_total_time_ms", i18n.KExplainAvgTotalTime},
+	{"avg_ttft_ms", i18n.KExplainAvgTTFT},
+	{"avg_tpot_ms", i18n.KExplainAvgTPOT},
+	{"avg_tps", i18n.KExplainAvgTPS},
+	{"avg_steady_tps", i18n.KExplainAvgSteadyTPS},
+	{"avg_total_throughput_tps", i18n.KExplainAvgThroughput},
+	{"rpm", i18n.KExplainRPM},
+	{"tpm", i18n.KExplainTPM},
+	{"avg_normalized_tps", i18n.KExplainNormalizedTPS},
+	{"target_ip", i18n.KExplainTargetIP},
+	{"requests", i18n.KExplainTargetIPRequests},
+}
+
+// printMetricExplain 以当前界面语言输出结果表各列的说明。
+func printMetricExplain(w io.Writer) {
+	fmt.Fprintf(w, "%s:\n", i18n.T(i18n.KExplainTitle))
+	for _, col := range explainColumns {
+		fmt.Fprintf(w, "  %-26s %s\n", col.column, i18n.T(col.key))
+	}
+}
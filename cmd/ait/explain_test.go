@@ -0,0 +1,60 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/yinxulai/ait/internal/i18n"
+	"github.com/yinxulai/ait/internal/server/report"
+)
+
+var updateGolden = flag.Bool("update", false, "update golden files")
+
+// TestExplainCoversTableHeaders 结果表新增列时必须补上说明，否则在这里失败。
+func TestExplainCoversTableHeaders(t *testing.T) {
+	explained := make(map[string]bool, len(explainColumns))
+	for _, col := range explainColumns {
+		explained[col.column] = true
+	}
+	headers := append(report.TableHeaders(), report.TargetIPTableHeaders...)
+	for _, h := range headers {
+		if !explained[h] {
+			t.Errorf("column %q has no explanation in explainColumns", h)
+		}
+	}
+}
+
+func TestPrintMetricExplain_Golden(t *testing.T) {
+	defer i18n.SetLang(i18n.Active())
+
+	for _, tt := range []struct {
+		lang   i18n.Lang
+		golden string
+	}{
+		{i18n.ZH, "explain_zh.golden"},
+		{i18n.EN, "explain_en.golden"},
+	} {
+		t.Run(tt.golden, func(t *testing.T) {
+			i18n.SetLang(tt.lang)
+			var buf bytes.Buffer
+			printMetricExplain(&buf)
+
+			golden := filepath.Join("testdata", tt.golden)
+			if *updateGolden {
+				if err := os.WriteFile(golden, buf.Bytes(), 0o644); err != nil {
+					t.Fatalf("update golden: %v", err)
+				}
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("read golden: %v", err)
+			}
+			if buf.String() != string(want) {
+				t.Errorf("output differs from %s (run with -update to refresh)\n--- got ---\n%s\n--- want ---\n%s", golden, buf.String(), want)
+			}
+		})
+	}
+}
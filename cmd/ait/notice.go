@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/yinxulai/ait/internal/i18n"
+	"github.com/yinxulai/ait/internal/server/config"
+	"github.com/yinxulai/ait/internal/server/update"
+)
+
+// startupNotices 在后台生成启动提示并逐条发出，全部发出后关闭 channel：
+//   - 遥测上报开启且从未提示过时，先发出一行遥测说明，并在 ~/.ait/state.json 中记为已提示；
+//   - 未关闭版本检查时，请求 releaseURL，发现比 current 新的版本则发出一行升级提示。
+//
+// 版本检查超时 1 秒、失败静默，不会阻塞启动；接收方在界面中展示或打印到 stderr。
+func startupNotices(telemetryEnabled, noUpdateCheck bool, releaseURL, current string) <-chan string {
+	ch := make(chan string, 2)
+	if telemetryEnabled {
+		if state, err := config.LoadState(); err == nil && !state.TelemetryNoticeShown {
+			state.TelemetryNoticeShown = true
+			if err := state.Save(); err == nil {
+				ch <- i18n.T(i18n.KCLITelemetryNotice)
+			}
+		}
+	}
+	if noUpdateCheck {
+		close(ch)
+		return ch
+	}
+	go func() {
+		defer close(ch)
+		if latest := update.Check(context.Background(), releaseURL, current); latest != "" {
+			ch <- fmt.Sprintf(i18n.T(i18n.KCLIUpdateAvailableFmt), latest, current)
+		}
+	}()
+	return ch
+}
+
+// printNotices 把 notices 中的提示逐行写入 w，直到 channel 关闭。
+func printNotices(w io.Writer, notices <-chan string) {
+	for notice := range notices {
+		fmt.Fprintln(w, notice)
+	}
+}
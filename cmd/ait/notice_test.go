@@ -0,0 +1,67 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func collectNotices(ch <-chan string) []string {
+	var out []string
+	for n := range ch {
+		out = append(out, n)
+	}
+	return out
+}
+
+func TestStartupNotices_UpdateCheck(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.Write([]byte(`{"tag_name":"v9.9.9"}`))
+	}))
+	defer srv.Close()
+
+	got := collectNotices(startupNotices(false, false, srv.URL, "v1.0.0"))
+	if len(got) != 1 || !strings.Contains(got[0], "v9.9.9") || !strings.Contains(got[0], "v1.0.0") {
+		t.Fatalf("notices = %q, want one update hint", got)
+	}
+
+	// --no-update-check / AIT_NO_UPDATE_CHECK=1 时不发请求
+	hits.Store(0)
+	if got := collectNotices(startupNotices(false, true, srv.URL, "v1.0.0")); len(got) != 0 || hits.Load() != 0 {
+		t.Fatalf("disabled: notices = %q, hits = %d", got, hits.Load())
+	}
+	o, err := parseWithEnv(nil, map[string]string{"AIT_NO_UPDATE_CHECK": "1"})
+	if err != nil || !o.NoUpdateCheck {
+		t.Fatalf("AIT_NO_UPDATE_CHECK=1: NoUpdateCheck = %v, %v", o != nil && o.NoUpdateCheck, err)
+	}
+}
+
+func TestParseOptions_NoUpload(t *testing.T) {
+	o, err := parseWithEnv([]string{"--no-upload", "--upload-sample-rate", "0.5"}, nil)
+	if err != nil {
+		t.Fatalf("parseOptions: %v", err)
+	}
+	if o.UploadSampleRate != 0 {
+		t.Errorf("UploadSampleRate = %g, want 0 with --no-upload", o.UploadSampleRate)
+	}
+}
+
+func TestStartupNotices_TelemetryOnce(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	if got := collectNotices(startupNotices(false, true, "", "v1.0.0")); len(got) != 0 {
+		t.Fatalf("telemetry disabled: notices = %q", got)
+	}
+	got := collectNotices(startupNotices(true, true, "", "v1.0.0"))
+	if len(got) != 1 || !strings.Contains(got[0], "--no-upload") {
+		t.Fatalf("first run: notices = %q, want telemetry notice", got)
+	}
+	if got := collectNotices(startupNotices(true, true, "", "v1.0.0")); len(got) != 0 {
+		t.Fatalf("second run: notices = %q, want none", got)
+	}
+}
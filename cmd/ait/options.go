@@ -0,0 +1,493 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"time"
+
+	"github.com/yinxulai/ait/internal/i18n"
+	"github.com/yinxulai/ait/internal/server"
+	"github.com/yinxulai/ait/internal/server/logger"
+	"github.com/yinxulai/ait/internal/server/network"
+	"github.com/yinxulai/ait/internal/server/prompt"
+	"github.com/yinxulai/ait/internal/server/redact"
+	"github.com/yinxulai/ait/internal/server/report"
+	"github.com/yinxulai/ait/internal/server/sla"
+	"github.com/yinxulai/ait/internal/server/stats"
+	"github.com/yinxulai/ait/internal/server/upload"
+)
+
+// Options 主命令的全部参数。ParseOptions 负责定义、解析（命令行 > AIT_ 环境变量 > 默认值）与校验，
+// Apply 把进程级设置下发到各包，main 只做装配。
+type Options struct {
+	Version        bool
+	MCP            bool
+	Web            bool
+	Lang           string
+	Verbose        bool
+	Quiet          bool
+	TableFormat    string
+	MarkdownOutput string
+	GHSummary      bool
+	ReportFormat   string
+	HistoryFile    string
+	Explain        bool
+	CPUProfile     string
+	MemProfile     string
+	ShowSlowest    int
+	ASCII          bool
+
+	// 出站辅助请求（遥测、webhook）的代理与超时，以及遥测上报比例
+	Telemetry        network.HTTPOptions
+	UploadSampleRate float64
+	NoUpload         bool
+	NoUpdateCheck    bool
+
+	// 多进程分片、额外 SLA 与 SLA 不达标时的退出码
+	Shard     server.Shard
+	SLA       []string
+	FailOnSLA bool
+
+	// --log 模式下单个流式响应的记录上限与进度输出格式
+	LogMaxChunks   int
+	LogMaxBytes    int
+	ProgressFormat string
+
+	// 请求预览、失败请求导出 / 重放与 curl 导出
+	DryRun           bool
+	DryRunOutput     string
+	FailedOutput     string
+	ExportCurl       string
+	ExportCurlOutput string
+	Replay           string
+
+	// 结果写入 SQLite 数据库
+	SQLite string
+
+	// 可复现 bundle 的导出与还原
+	ExportBundle string
+	FromBundle   string
+
+	// prompt / 响应落盘
+	SaveIODir        string
+	SaveIOSampleRate float64
+
+	// 作用于每次运行的进程级开关
+	StreamBoth         bool
+	ConsistencyCheck   bool
+	PromptFilePerRun   bool
+	HTTPVersion        string // 已规范化为 1.1 / 2 / auto
+	CompareHTTPVersion bool
+	AcceptEncoding     string // 已规范化为 identity / gzip / br，空表示不指定
+	MaxTokens          int
+	SelfMonitor        bool
+	SelfMonitorOutput  string
+	SelfStats          bool
+	TokenTrace         string
+	TokenTraceMax      int
+	AbortOnErrorRate   float64 // 百分比，0 表示不熔断
+	AbortMinSamples    int
+	ResponseSchema     string
+
+	// 每个请求前执行外部命令生成 prompt
+	PromptCommand        string
+	PromptCommandTimeout time.Duration
+	PromptCommandProcs   int
+
+	// 从 stdin 逐行读取 prompt 数据集
+	PromptStdinLines bool
+
+	// 日志、遥测上报与报告中的敏感信息脱敏
+	Redact         bool
+	RedactPatterns string
+	redactor       *redact.Redactor
+
+	// 被测请求的 --resolve / --dns-server 解析策略
+	DNS network.DNSConfig
+
+	// 测试计划
+	Plan         string
+	CostLimit    float64
+	RequestLimit int
+	Yes          bool
+
+	flags   *flag.FlagSet
+	sources map[string]string // 各参数的取值来源（flag / env / config / default）
+	args    []string          // 原始命令行参数，随 --export-bundle 写入 bundle
+}
+
+// usageError 命令行解析失败，FlagSet 已输出错误与用法，调用方无需重复输出。
+type usageError struct{ error }
+
+func (e usageError) Unwrap() error { return e.error }
+
+// ParseOptions 解析主命令参数 args（不含程序名）：未在命令行显式设置的参数由 AIT_ 前缀环境变量兜底，
+// 然后校验各参数的取值与组合。-h / --help 时返回 flag.ErrHelp。
+func ParseOptions(args []string) (*Options, error) {
+	return parseOptions(args, os.LookupEnv, os.Stderr)
+}
+
+func parseOptions(args []string, lookupEnv func(string) (string, bool), output io.Writer) (*Options, error) {
+	o := &Options{args: args}
+	fs := flag.NewFlagSet("ait", flag.ContinueOnError)
+	fs.SetOutput(output)
+	fs.BoolVar(&o.Version, "version", false, "显示版本信息")
+	fs.BoolVar(&o.MCP, "mcp", false, "启用 MCP 模式")
+	fs.BoolVar(&o.Web, "web", false, "启用 Web UI 模式")
+	fs.StringVar(&o.Lang, "lang", "", "界面语言：zh 或 en")
+	fs.BoolVar(&o.Verbose, "verbose", false, "启动时打印每个参数的取值来源")
+	fs.BoolVar(&o.Quiet, "quiet", false, "只输出最终结果与错误：不打印启动提示、--plan 的预估与逐场景进度，以及文件写入成功的确认信息")
+	fs.StringVar(&o.TableFormat, "table-format", "", "退出 TUI 后把本次运行的结果表输出到 stdout：tsv 或 csv")
+	fs.StringVar(&o.MarkdownOutput, "markdown-output", "", "退出 TUI 后把本次运行的结果以 Markdown 表格写入该文件")
+	fs.BoolVar(&o.GHSummary, "gh-summary", false, "退出 TUI 后把本次运行的结果以 Markdown 追加到 $GITHUB_STEP_SUMMARY 指向的文件")
+	fs.StringVar(&o.ReportFormat, "report-format", "", "退出 TUI 后把本次运行的结果写为该格式的报告文件：json、csv、md 或 k6（k6 summary JSON）")
+	fs.StringVar(&o.SQLite, "sqlite", "", "退出 TUI 后把本次每次运行的每模型结果（时间戳、任务配置、全部指标）写入该 SQLite 数据库的 results 表")
+	fs.StringVar(&o.HistoryFile, "history-file", "", "退出 TUI 后把本次运行的核心指标逐行追加到该 JSONL 文件，供 ait report 渲染趋势")
+	fs.BoolVar(&o.Explain, "explain", false, "在 --table-format 输出的结果表后追加各列指标说明（随 --lang 切换语言）")
+	fs.StringVar(&o.Telemetry.Proxy, "telemetry-proxy", "", "遥测上报、webhook 等出站辅助请求使用的代理，不影响被测请求")
+	fs.DurationVar(&o.Telemetry.Timeout, "telemetry-timeout", 0, "出站辅助请求的单次超时（如 5s），0 表示使用各自的默认值")
+	fs.Float64Var(&o.UploadSampleRate, "upload-sample-rate", upload.DefaultSampleRate, "成功请求的遥测上报比例 [0, 1]，0 表示不上报，1 表示全部上报")
+	fs.BoolVar(&o.NoUpload, "no-upload", false, "关闭匿名遥测上报，等同于 --upload-sample-rate 0")
+	fs.BoolVar(&o.Redact, "redact", false, "写日志（--log）、遥测上报与报告中的错误样例 / 响应片段前抹去手机号、邮箱、身份证号、银行卡号等敏感信息")
+	fs.StringVar(&o.RedactPatterns, "redact-patterns", "", "--redact 追加的自定义脱敏规则文件：每行一条正则，忽略空行与 # 注释")
+	fs.BoolVar(&o.NoUpdateCheck, "no-update-check", false, "启动时不检查 GitHub 上是否有新版本")
+	fs.StringVar(&o.CPUProfile, "cpuprofile", "", "把 ait 自身的 CPU profile 写入该文件（pprof 格式）")
+	fs.StringVar(&o.MemProfile, "memprofile", "", "退出时把 ait 自身的堆内存 profile 写入该文件（pprof 格式）")
+	fs.IntVar(&o.ShowSlowest, "show-slowest", 0, "退出 TUI 后为每次运行输出总耗时最长的 N 个请求，0 表示不输出")
+	shardFlag := fs.String("shard", "", "多进程分片压测：i/n 表示本进程只执行标准运行总请求数的第 i 片（共 n 片）")
+	var slaFlag stringList
+	fs.Var(&slaFlag, "sla", "标准运行额外评估的 SLA 表达式，如 \"ttft<800ms,total<10s,p=95\"，可重复指定")
+	fs.BoolVar(&o.FailOnSLA, "fail-on-sla", false, "有运行未达到 SLA 时以退出码 4 退出")
+	fs.IntVar(&o.LogMaxChunks, "log-max-chunks", logger.DefaultMaxStreamChunks, "--log 模式下单个流式响应最多记录的数据块数，超出部分不记录并标记截断，0 表示不限制")
+	fs.IntVar(&o.LogMaxBytes, "log-max-bytes", logger.DefaultMaxStreamBytes, "单个流式响应最多记录的字节数（--log 日志与请求结果中的原始响应体），超出部分不记录，0 表示不限制")
+	fs.StringVar(&o.ProgressFormat, "progress-format", "", "设为 json 时把运行进度以 JSON 行写到 stderr，供外部脚本监控")
+	fs.BoolVar(&o.DryRun, "dry-run", false, "为每个已保存的任务构造一次完整请求并打印（密钥打码），不发送请求，打印后退出")
+	fs.StringVar(&o.DryRunOutput, "dry-run-output", "", "--dry-run 的输出写入该文件而不是 stdout")
+	fs.StringVar(&o.FailedOutput, "failed-output", "", "退出 TUI 后把本次运行中失败请求的 prompt 导出为 JSONL，供 --replay 重跑")
+	fs.StringVar(&o.ExportCurl, "export-curl", "", "退出 TUI 后把本次运行中的请求导出为等价的 curl 命令：failed 为所有失败请求，或逗号分隔的请求序号如 0,5,12")
+	fs.StringVar(&o.ExportCurlOutput, "export-curl-output", "ait-curl.sh", "--export-curl 的输出文件，请求体过长时另存为同名前缀的 .json 文件并以 @file 引用")
+	fs.StringVar(&o.Replay, "replay", "", "以 --failed-output 导出的 JSONL 为 prompt 来源，复制原任务创建一个只重跑这些请求的重放任务")
+	fs.StringVar(&o.ExportBundle, "export-bundle", "", "退出 TUI 后把本次运行的任务配置、prompt 数据、结果与环境信息打包为该 zip 文件（不含密钥），供 --from-bundle 重跑")
+	fs.StringVar(&o.FromBundle, "from-bundle", "", "从 --export-bundle 导出的 zip 还原任务配置与 prompt 数据并创建任务，密钥从协议对应的环境变量读取")
+	fs.StringVar(&o.SaveIODir, "save-io-dir", "", "把成功请求的 prompt 与模型输出正文按运行追加到该目录下的 <run_id>.jsonl，供离线质量评估")
+	fs.Float64Var(&o.SaveIOSampleRate, "save-io-sample-rate", 1, "--save-io-dir 的采样比例 (0, 1]，大量请求时只保存其中一部分以控制磁盘占用")
+	fs.BoolVar(&o.StreamBoth, "stream-both", false, "每次标准运行依次以流式、非流式各跑一轮，并排对比两种模式的 TTFT / 总耗时 / TPS")
+	fs.BoolVar(&o.ConsistencyCheck, "consistency-check", false, "每次标准运行改为确定性验证：同一 prompt、非流式、temperature=0，统计完整输出的不同版本数与一致率")
+	fs.BoolVar(&o.PromptFilePerRun, "prompt-file-per-run", false, "prompt 文件匹配多个文件时，每个文件作为一个场景依次各跑 count 个请求并分别统计（场景名为文件名去扩展名），而不是混在一起随机抽取")
+	fs.StringVar(&o.HTTPVersion, "http-version", "auto", "被测请求使用的 HTTP 版本：1.1 禁用 HTTP/2，2 强制尝试 HTTP/2，auto 保持默认协商；任务设置了 http_version 时以任务为准")
+	fs.StringVar(&o.AcceptEncoding, "accept-encoding", "", "被测请求的 Accept-Encoding：identity、gzip 或 br，用于对比响应压缩对 TTFT / 带宽的影响；任务设置了 accept_encoding 时以任务为准")
+	fs.BoolVar(&o.CompareHTTPVersion, "compare-http-version", false, "每次标准运行依次以 HTTP/1.1、HTTP/2 各跑一轮，并排对比两种版本的总耗时 / TTFT / TPS")
+	fs.IntVar(&o.MaxTokens, "max-tokens", 0, "被测请求默认的输出 token 上限，0 表示不设置；任务设置了 max_tokens 时以任务为准，报告统计实际输出相对上限的分布与截断比例")
+	fs.BoolVar(&o.SelfMonitor, "self-monitor", false, "长稳测试自监控：开启 --self-stats，每分钟把 ait 自身的 goroutine / 堆内存 / GC 采样追加到 --self-monitor-output，结束时输出起止对比与持续增长告警")
+	fs.StringVar(&o.SelfMonitorOutput, "self-monitor-output", "selfstats.jsonl", "--self-monitor 采样追加写入的 JSONL 文件")
+	fs.StringVar(&o.TokenTrace, "token-trace", "", "流式请求记录每个增量 chunk 的到达时刻与长度，按请求写入该目录下的 <run_id>/<请求序号>.jsonl，用于研究推理引擎的解码调度；会增加内存与磁盘占用")
+	fs.IntVar(&o.TokenTraceMax, "token-trace-max-chunks", server.DefaultTokenTraceMaxChunks, "--token-trace 单个请求最多记录的 chunk 数，超出部分截断")
+	abortFlag := fs.String("abort-on-error-rate", "", "错误率熔断：已完成请求数达到 --abort-min-samples 后错误率超过该值（如 50%）即停止派发、取消在途请求并出报告，进程以非零退出码结束；任务设置了 abort_on_error_rate 时以任务为准")
+	fs.IntVar(&o.AbortMinSamples, "abort-min-samples", stats.DefaultAbortMinSamples, "--abort-on-error-rate 开始判断所需的最少完成请求数")
+	fs.StringVar(&o.ResponseSchema, "response-schema", "", "标准运行按该 JSON schema 文件逐条校验成功响应的正文，报告统计合法 JSON 占比与合规率；openai-completions 协议同时发送 response_format: json_object。任务设置了 response_schema 时以任务为准")
+	fs.StringVar(&o.PromptCommand, "prompt-command", "", "每个请求前经 shell 执行该命令，以其 stdout 作为 prompt（环境变量 AIT_PROMPT_INDEX 为请求序号）；命令失败或超时的请求记为失败，重放运行不受影响")
+	fs.DurationVar(&o.PromptCommandTimeout, "prompt-command-timeout", prompt.DefaultCommandTimeout, "--prompt-command 单次执行的超时")
+	fs.IntVar(&o.PromptCommandProcs, "prompt-command-procs", 0, "--prompt-command 同时执行的命令数上限，0 表示 CPU 核数；超出时请求排队等待")
+	fs.BoolVar(&o.PromptStdinLines, "prompt-stdin-lines", false, "从 stdin 逐行读取 prompt，每个非空行作为一个候选 prompt，代替任务的 prompt 配置（如 cat prompts.txt | ait --plan plan.json --prompt-stdin-lines）；重放运行不受影响")
+	fs.BoolVar(&o.SelfStats, "self-stats", false, "对每次运行开启自监控：报告 ait 自身的 goroutine / 内存 / GC 占用，以及运行结束释放连接后残留的 goroutine 数")
+	var resolveFlag stringList
+	fs.Var(&resolveFlag, "resolve", "把被测请求的 host:port 固定解析到指定 IP，语法同 curl，如 \"api.example.com:443:10.0.0.5\"，IPv6 地址加方括号，可重复指定")
+	dnsServerFlag := fs.String("dns-server", "", "被测请求使用的上游 DNS 服务器，如 8.8.8.8 或 [2001:4860:4860::8888]:53，默认使用系统解析")
+	fs.StringVar(&o.Plan, "plan", "", "依次执行测试计划文件（JSON）中的各个场景，汇总所有场景的结果输出一份总报告后退出，报告格式同 --report-format（默认 json）")
+	fs.Float64Var(&o.CostLimit, "cost-limit", 0, "--plan 预计费用上限超过该值时要求确认后再执行，0 表示不检查")
+	fs.IntVar(&o.RequestLimit, "request-limit", defaultPlanRequestLimit, "--plan 预计请求数超过该值时要求确认后再执行，0 表示不检查")
+	fs.BoolVar(&o.Yes, "yes", false, "跳过 --plan 的执行确认")
+	fs.BoolVar(&o.ASCII, "ascii", false, "TUI 只使用纯 ASCII 字符：状态符号替换为 [OK]/[ERR] 等，表格与面板使用 ASCII 边框")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil, err
+		}
+		return nil, usageError{err}
+	}
+	o.flags = fs
+
+	// 未在命令行显式设置的参数由 AIT_ 前缀环境变量兜底
+	sources, err := applyEnvFlags(fs, lookupEnv)
+	if err != nil {
+		return nil, err
+	}
+	o.sources = sources
+	o.SLA = slaFlag
+
+	if err := o.validate(); err != nil {
+		return nil, err
+	}
+	if o.Shard, err = server.ParseShard(*shardFlag); err != nil {
+		return nil, fmt.Errorf("--shard 无效: %w", err)
+	}
+	for _, expr := range o.SLA {
+		if _, err := sla.Parse(expr); err != nil {
+			return nil, fmt.Errorf("--sla 无效: %w", err)
+		}
+	}
+	if o.AbortOnErrorRate, err = server.ParseErrorRate(*abortFlag); err != nil {
+		return nil, fmt.Errorf("--abort-on-error-rate 无效: %w", err)
+	}
+	if o.HTTPVersion, err = server.ParseHTTPVersion(o.HTTPVersion); err != nil {
+		return nil, fmt.Errorf("--http-version 无效: %w", err)
+	}
+	if o.AcceptEncoding, err = server.ParseAcceptEncoding(o.AcceptEncoding); err != nil {
+		return nil, fmt.Errorf("--accept-encoding 无效: %w", err)
+	}
+	if o.ResponseSchema != "" {
+		if _, err := stats.LoadJSONSchema(o.ResponseSchema); err != nil {
+			return nil, fmt.Errorf("--response-schema 无效: %w", err)
+		}
+	}
+	if o.Redact {
+		var patterns []string
+		if o.RedactPatterns != "" {
+			if patterns, err = redact.LoadPatterns(o.RedactPatterns); err != nil {
+				return nil, fmt.Errorf("--redact-patterns 无效: %w", err)
+			}
+		}
+		if o.redactor, err = redact.New(patterns); err != nil {
+			return nil, fmt.Errorf("--redact-patterns 无效: %w", err)
+		}
+	}
+	if o.NoUpload {
+		o.UploadSampleRate = 0
+	}
+	for _, s := range resolveFlag {
+		rule, err := network.ParseResolveRule(s)
+		if err != nil {
+			return nil, fmt.Errorf("--resolve 无效: %w", err)
+		}
+		o.DNS.Resolve = append(o.DNS.Resolve, rule)
+	}
+	if *dnsServerFlag != "" {
+		if o.DNS.Server, err = network.ParseDNSServer(*dnsServerFlag); err != nil {
+			return nil, fmt.Errorf("--dns-server 无效: %w", err)
+		}
+	}
+	return o, nil
+}
+
+// validate 校验单个参数的取值范围与参数之间的依赖。
+func (o *Options) validate() error {
+	switch {
+	case o.TableFormat != "" && !report.IsTableFormat(o.TableFormat):
+		return fmt.Errorf("--table-format 仅支持 tsv 或 csv，当前为 %q", o.TableFormat)
+	case o.ReportFormat != "" && !isReportFormat(o.ReportFormat):
+		return fmt.Errorf("--report-format 仅支持 json、csv、md 或 k6，当前为 %q", o.ReportFormat)
+	case o.Explain && o.TableFormat == "":
+		return errors.New("--explain 需配合 --table-format 使用")
+	case o.DryRunOutput != "" && !o.DryRun:
+		return errors.New("--dry-run-output 需配合 --dry-run 使用")
+	case o.RedactPatterns != "" && !o.Redact:
+		return errors.New("--redact-patterns 需配合 --redact 使用")
+	case o.CostLimit < 0 || o.RequestLimit < 0:
+		return fmt.Errorf("--cost-limit / --request-limit 不能为负数，当前为 %g / %d", o.CostLimit, o.RequestLimit)
+	case o.Quiet && o.Verbose:
+		return errors.New("--quiet 与 --verbose 不能同时使用")
+	case o.ShowSlowest < 0:
+		return fmt.Errorf("--show-slowest 不能为负数，当前为 %d", o.ShowSlowest)
+	case o.LogMaxChunks < 0 || o.LogMaxBytes < 0:
+		return fmt.Errorf("--log-max-chunks / --log-max-bytes 不能为负数，当前为 %d / %d", o.LogMaxChunks, o.LogMaxBytes)
+	case o.MaxTokens < 0:
+		return fmt.Errorf("--max-tokens 无效: %d 不能为负数", o.MaxTokens)
+	case o.AbortMinSamples <= 0:
+		return fmt.Errorf("--abort-min-samples 无效: 必须大于 0，当前为 %d", o.AbortMinSamples)
+	case o.PromptCommandTimeout <= 0 || o.PromptCommandProcs < 0:
+		return fmt.Errorf("--prompt-command-timeout / --prompt-command-procs 无效: 超时必须大于 0、进程数不能为负数，当前为 %s / %d", o.PromptCommandTimeout, o.PromptCommandProcs)
+	case o.PromptStdinLines && o.PromptCommand != "":
+		return errors.New("--prompt-stdin-lines 与 --prompt-command 不能同时使用")
+	case o.PromptStdinLines && o.MCP:
+		return errors.New("--prompt-stdin-lines 不能用于 --mcp：MCP 模式通过 stdin 通信")
+	case o.TokenTrace != "" && o.TokenTraceMax <= 0:
+		return fmt.Errorf("--token-trace-max-chunks 无效: 必须大于 0，当前为 %d", o.TokenTraceMax)
+	case o.SelfMonitor && o.SelfMonitorOutput == "":
+		return errors.New("--self-monitor-output 不能为空")
+	case o.ProgressFormat != "" && o.ProgressFormat != "json":
+		return fmt.Errorf("--progress-format 仅支持 json，当前为 %q", o.ProgressFormat)
+	case o.ProgressFormat != "" && o.Plan == "" && !o.DryRun && o.Route() == "tui":
+		return errors.New("--progress-format 不能用于 TUI：TUI 占用终端，请配合 --plan / --web / --mcp 使用")
+	case o.SaveIODir != "" && (o.SaveIOSampleRate <= 0 || o.SaveIOSampleRate > 1):
+		return fmt.Errorf("--save-io-dir / --save-io-sample-rate 无效: 采样比例必须在 (0, 1] 内，当前为 %g", o.SaveIOSampleRate)
+	case math.IsNaN(o.UploadSampleRate) || o.UploadSampleRate < 0 || o.UploadSampleRate > 1:
+		return fmt.Errorf("--upload-sample-rate 无效: 采样比例必须在 [0, 1] 内，当前为 %g", o.UploadSampleRate)
+	}
+	if o.ExportCurl != "" {
+		if _, err := parseCurlSelection(o.ExportCurl); err != nil {
+			return fmt.Errorf("--export-curl 无效: %w", err)
+		}
+	}
+	if err := o.Telemetry.Validate(); err != nil {
+		return fmt.Errorf("--telemetry-proxy / --telemetry-timeout 无效: %w", err)
+	}
+	return nil
+}
+
+// Apply 把进程级参数下发到 server、logger、network、upload 等包；只有需要创建目录等副作用失败时返回错误。
+func (o *Options) Apply() error {
+	logger.SetStreamLimits(o.LogMaxChunks, o.LogMaxBytes)
+	redact.Enable(o.redactor)
+	server.SetShard(o.Shard)
+	server.SetSLA(o.SLA)
+	server.SetStreamBoth(o.StreamBoth)
+	server.SetConsistencyCheck(o.ConsistencyCheck)
+	server.SetPromptFilePerRun(o.PromptFilePerRun)
+	if err := server.SetHTTPVersion(o.HTTPVersion); err != nil {
+		return fmt.Errorf("--http-version 无效: %w", err)
+	}
+	server.SetCompareHTTPVersion(o.CompareHTTPVersion)
+	if err := server.SetAcceptEncoding(o.AcceptEncoding); err != nil {
+		return fmt.Errorf("--accept-encoding 无效: %w", err)
+	}
+	server.SetSelfStats(o.SelfStats)
+	if o.SelfMonitor {
+		server.SetSelfMonitor(o.SelfMonitorOutput)
+	}
+	server.SetMaxTokens(o.MaxTokens)
+	server.SetAbortOnErrorRate(o.AbortOnErrorRate, o.AbortMinSamples)
+	if err := server.SetResponseSchema(o.ResponseSchema); err != nil {
+		return fmt.Errorf("--response-schema 无效: %w", err)
+	}
+	server.SetPromptCommand(o.PromptCommand, o.PromptCommandTimeout, o.PromptCommandProcs)
+	if o.PromptStdinLines {
+		source, err := readStdinPrompts(os.Stdin)
+		if err != nil {
+			return fmt.Errorf("--prompt-stdin-lines 无效: %w", err)
+		}
+		server.SetStdinPrompts(source)
+	}
+	if o.ProgressFormat == "json" {
+		server.SetProgressWriter(os.Stderr)
+	}
+	if err := server.SetIOLog(o.SaveIODir, o.SaveIOSampleRate); err != nil {
+		return fmt.Errorf("--save-io-dir / --save-io-sample-rate 无效: %w", err)
+	}
+	if err := server.SetTokenTrace(o.TokenTrace, o.TokenTraceMax); err != nil {
+		return fmt.Errorf("--token-trace 无效: %w", err)
+	}
+	if o.TokenTrace != "" {
+		fmt.Fprintf(os.Stderr, i18n.T(i18n.KCLITokenTraceWarnFmt)+"\n", o.TokenTrace, o.TokenTraceMax)
+	}
+	network.SetTelemetryOptions(o.Telemetry)
+	if err := upload.SetSampleRate(o.UploadSampleRate); err != nil {
+		return fmt.Errorf("--upload-sample-rate 无效: %w", err)
+	}
+	network.SetDNSConfig(o.DNS)
+	return nil
+}
+
+// ApplyLang 初始化界面语言，优先级：命令行 > 环境变量 > 配置文件 > 默认中文；
+// 只有命令行与环境变量都未指定有效语言时才调用 configLang 读取配置文件中的语言。
+func (o *Options) ApplyLang(configLang func() string) {
+	if o.Lang != "en" && o.Lang != "zh" {
+		if lang := configLang(); lang != "" {
+			_ = o.flags.Set("lang", lang)
+			o.sources["lang"] = sourceConfig
+		}
+	}
+	if o.Lang == "en" {
+		i18n.SetLang(i18n.EN)
+	} else if o.Lang == "zh" {
+		i18n.SetLang(i18n.ZH)
+	}
+}
+
+// PrintSources 输出每个参数的取值与来源（--verbose）。
+func (o *Options) PrintSources(w io.Writer) {
+	fmt.Fprintln(w, "参数来源：")
+	printFlagSources(w, o.flags, o.sources)
+}
+
+// Route 返回运行界面：mcp、web 或默认的 tui。
+func (o *Options) Route() string {
+	return routeByFlags(o.MCP, o.Web)
+}
+
+// readStdinPrompts 从 stdin 逐行读取 prompt；stdin 是终端时报错，避免阻塞等待输入。
+func readStdinPrompts(stdin *os.File) (*prompt.PromptSource, error) {
+	if isTerminal(stdin) {
+		return nil, errors.New("stdin 是终端，请通过管道或重定向提供 prompt，如 cat prompts.txt | ait --prompt-stdin-lines")
+	}
+	return prompt.LoadPromptsFromLines(stdin)
+}
+
+// statusOutput 返回文件写入成功等确认信息的输出目标：--quiet 时丢弃，否则为 stderr；错误信息始终写到 stderr。
+func (o *Options) statusOutput() io.Writer {
+	if o.Quiet {
+		return io.Discard
+	}
+	return os.Stderr
+}
+
+// writeSessionOutputs 退出 TUI 后按参数输出本次会话的结果表、报告、历史、慢请求与导出文件。
+func (o *Options) writeSessionOutputs(srv server.Server, since time.Time) {
+	if o.TableFormat != "" {
+		if err := printSessionTable(os.Stdout, srv, since, o.TableFormat, o.Explain); err != nil {
+			fmt.Fprintf(os.Stderr, i18n.T(i18n.KCLITableFailedFmt)+"\n", err)
+		}
+	}
+	if o.MarkdownOutput != "" {
+		if err := writeSessionMarkdown(o.MarkdownOutput, false, srv, since); err != nil {
+			fmt.Fprintf(os.Stderr, i18n.T(i18n.KCLIMarkdownFailedFmt)+"\n", err)
+		}
+	}
+	if o.GHSummary {
+		if path := os.Getenv("GITHUB_STEP_SUMMARY"); path == "" {
+			fmt.Fprintln(os.Stderr, i18n.T(i18n.KCLIGHSummaryUnset))
+		} else if err := writeSessionMarkdown(path, true, srv, since); err != nil {
+			fmt.Fprintf(os.Stderr, i18n.T(i18n.KCLIMarkdownFailedFmt)+"\n", err)
+		}
+	}
+	if o.ReportFormat != "" {
+		if path, err := writeSessionReport(o.ReportFormat, srv, since); err != nil {
+			fmt.Fprintf(os.Stderr, i18n.T(i18n.KCLIReportFailedFmt)+"\n", err)
+		} else if path != "" {
+			fmt.Fprintf(o.statusOutput(), i18n.T(i18n.KCLIReportSavedFmt)+"\n", path)
+		}
+	}
+	if o.HistoryFile != "" {
+		if err := report.AppendHistory(o.HistoryFile, sessionReports(srv, since)); err != nil {
+			fmt.Fprintf(os.Stderr, i18n.T(i18n.KCLIHistoryFailedFmt)+"\n", err)
+		}
+	}
+	if o.SQLite != "" {
+		if n, err := writeSessionSQLite(o.SQLite, srv, since); err != nil {
+			fmt.Fprintf(os.Stderr, i18n.T(i18n.KCLISQLiteFailedFmt)+"\n", err)
+		} else if n > 0 {
+			fmt.Fprintf(o.statusOutput(), i18n.T(i18n.KCLISQLiteWrittenFmt)+"\n", n, o.SQLite)
+		}
+	}
+	if o.SelfMonitor {
+		printSessionSelfMonitor(os.Stderr, srv, since, isTerminal(os.Stderr))
+	}
+	if o.ShowSlowest > 0 {
+		if err := printSessionSlowest(os.Stdout, srv, since, o.ShowSlowest); err != nil {
+			fmt.Fprintf(os.Stderr, i18n.T(i18n.KCLISlowestFailedFmt)+"\n", err)
+		}
+	}
+	if o.FailedOutput != "" {
+		if n, err := writeSessionFailed(o.FailedOutput, srv, since); err != nil {
+			fmt.Fprintf(os.Stderr, i18n.T(i18n.KCLIFailedExportFmt)+"\n", err)
+		} else {
+			fmt.Fprintf(o.statusOutput(), i18n.T(i18n.KCLIFailedExportedFmt)+"\n", n, o.FailedOutput)
+		}
+	}
+	if o.ExportCurl != "" {
+		if n, err := writeSessionCurl(o.ExportCurlOutput, o.ExportCurl, srv, since); err != nil {
+			fmt.Fprintf(os.Stderr, i18n.T(i18n.KCLICurlExportFmt)+"\n", err)
+		} else {
+			fmt.Fprintf(o.statusOutput(), i18n.T(i18n.KCLICurlExportedFmt)+"\n", n, o.ExportCurlOutput)
+		}
+	}
+	if o.ExportBundle != "" {
+		if n, err := writeSessionBundle(o.ExportBundle, o.args, srv, since); err != nil {
+			fmt.Fprintf(os.Stderr, i18n.T(i18n.KCLIBundleExportFmt)+"\n", err)
+		} else {
+			fmt.Fprintf(o.statusOutput(), i18n.T(i18n.KCLIBundleExportedFmt)+"\n", n, o.ExportBundle)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/yinxulai/ait/internal/server"
+	"github.com/yinxulai/ait/internal/server/config"
+	"github.com/yinxulai/ait/internal/server/report"
+	"github.com/yinxulai/ait/internal/server/types"
+)
+
+// planPollInterval 等待场景运行结束时轮询运行状态的间隔，兜底订阅前运行已结束、收不到事件的情况
+var planPollInterval = time.Second
+
+// planOptions --plan 的运行选项
+type planOptions struct {
+	Format       string  // 总报告格式
+	CostLimit    float64 // 预计费用上限超过该值时需要确认，0 表示不检查
+	RequestLimit int     // 预计请求数超过该值时需要确认，0 表示不检查
+	Yes          bool    // 跳过确认
+	Interactive  bool    // stdin 是否为终端；非终端时不询问，视为已确认
+	Quiet        bool    // 不输出预估、逐场景进度与报告路径，只保留汇总表与错误
+}
+
+// runPlan 执行 --plan：先输出预估（请求数、token 消耗与费用），超过限制时要求确认；随后为测试计划中的每个场景
+// 创建任务并依次运行，全部结束后把各场景结果汇总成一张 Markdown 对比表写到 stdout，并按 opts.Format 写入总报告文件。
+// 有场景无法创建或运行失败、或用户取消时返回 1。
+func runPlan(srv server.Server, path string, opts planOptions, stdin io.Reader, stdout, stderr io.Writer) int {
+	plan, err := config.LoadPlan(path)
+	if err != nil {
+		fmt.Fprintf(stderr, "读取测试计划失败: %v\n", err)
+		return 1
+	}
+
+	// 进度类输出写到 progress，--quiet 时丢弃；错误信息始终写到 stderr
+	progress := stderr
+	if opts.Quiet {
+		progress = io.Discard
+	}
+
+	est := config.EstimatePlan(plan, historicalOutputTokens(srv))
+	reasons := planConfirmReasons(est, opts.CostLimit, opts.RequestLimit)
+	if len(reasons) > 0 && !opts.Yes && opts.Interactive {
+		// 需要用户确认时预估是判断依据，--quiet 也照常显示
+		printPlanEstimate(stderr, est)
+	} else {
+		printPlanEstimate(progress, est)
+	}
+	if len(reasons) > 0 && !opts.Yes {
+		if !opts.Interactive {
+			for _, reason := range reasons {
+				fmt.Fprintf(progress, "  ! %s\n", reason)
+			}
+			fmt.Fprintln(progress, "非交互环境，视为已确认")
+		} else if !confirmPlan(stdin, stderr, reasons) {
+			fmt.Fprintln(stderr, "已取消")
+			return 1
+		}
+	}
+
+	failed := 0
+	var data []types.ReportData
+	for i, scenario := range plan.Scenarios {
+		name := scenario.Name
+		if plan.Name != "" {
+			name = plan.Name + " / " + name
+		}
+		header := fmt.Sprintf("[%d/%d] %s", i+1, len(plan.Scenarios), name)
+		fmt.Fprintln(progress, header)
+		fail := func(format string, args ...any) {
+			if opts.Quiet {
+				// 场景标题没有输出，出错时补上以便定位
+				fmt.Fprintln(stderr, header)
+			}
+			fmt.Fprintf(stderr, format, args...)
+			failed++
+		}
+		def, err := srv.CreateTask(server.TaskConfig{Name: name, Input: scenario.Input})
+		if err != nil {
+			fail("  创建任务失败: %v\n", err)
+			continue
+		}
+		state, err := runScenario(srv, def.ID)
+		if err != nil {
+			fail("  运行失败: %v\n", err)
+			continue
+		}
+		reports := runReports(state)
+		if state.Status != server.RunStatusCompleted || len(reports) == 0 {
+			fail("  运行未完成: %s\n", state.Status)
+		}
+		for _, r := range reports {
+			fmt.Fprintf(progress, "  成功率 %.1f%% · 平均总耗时 %s · 平均 TPS %.1f\n", r.SuccessRate, r.AvgTotalTime.Round(time.Millisecond), r.AvgTPS)
+			// 汇总表按模型列区分各行，以场景名标注结果所属的场景
+			model := r.ModelDisplayName
+			if model == "" {
+				model = r.Model
+			}
+			r.ModelDisplayName = scenario.Name + " · " + model
+			data = append(data, r)
+		}
+	}
+
+	if len(data) > 0 {
+		if err := report.WriteMarkdown(stdout, data); err != nil {
+			fmt.Fprintf(stderr, "输出汇总结果失败: %v\n", err)
+			return 1
+		}
+		paths, err := report.NewReportManager().GenerateReports(data, []string{opts.Format})
+		if err != nil {
+			fmt.Fprintf(stderr, "生成总报告失败: %v\n", err)
+			return 1
+		}
+		fmt.Fprintf(progress, "总报告已保存到 %s\n", paths[0])
+	}
+	if failed > 0 {
+		fmt.Fprintf(stderr, "%d 个场景未能完成\n", failed)
+		return 1
+	}
+	return 0
+}
+
+// runScenario 启动任务的一次运行并阻塞到运行结束，返回最终状态。
+func runScenario(srv server.Server, taskID string) (*server.RunState, error) {
+	runID, err := srv.StartRun(taskID)
+	if err != nil {
+		return nil, err
+	}
+	events, cancel := srv.SubscribeRunEvents(runID)
+	defer cancel()
+	ticker := time.NewTicker(planPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case _, ok := <-events:
+			if ok {
+				continue
+			}
+		case <-ticker.C:
+		}
+		state, ok := srv.GetRunState(runID)
+		if !ok {
+			return nil, fmt.Errorf("run %s not found", runID)
+		}
+		if runFinished(state.Status) {
+			return state, nil
+		}
+	}
+}
+
+// runFinished 返回运行是否已结束（完成、失败或被停止）。
+func runFinished(status server.RunStatus) bool {
+	return status == server.RunStatusCompleted || status == server.RunStatusFailed || status == server.RunStatusStopped
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yinxulai/ait/internal/server"
+)
+
+// TestRunPlan_Quiet --quiet 时隐藏预估、逐场景标题与报告路径，出错的场景仍补上标题，汇总表照常写到 stdout。
+func TestRunPlan_Quiet(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Chdir(t.TempDir())
+	oldInterval := planPollInterval
+	planPollInterval = 10 * time.Millisecond
+	t.Cleanup(func() { planPollInterval = oldInterval })
+
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"choices":[{"index":0,"message":{"role":"assistant","content":"hi"}}],"usage":{"prompt_tokens":3,"completion_tokens":2}}`)
+	}))
+	t.Cleanup(api.Close)
+
+	// 第二个场景的 tools_file 不存在，校验计划时通过、创建任务时失败
+	plan := filepath.Join(t.TempDir(), "plan.json")
+	content := fmt.Sprintf(`{
+		"name": "nightly",
+		"defaults": {"protocol": "openai-completions", "endpoint_url": %q, "model": "m", "concurrency": 1, "count": 2, "prompt_text": "hi"},
+		"scenarios": [
+			{"name": "ok", "input": {}},
+			{"name": "broken", "input": {"tools_file": "missing-tools.json"}}
+		]
+	}`, api.URL)
+	if err := os.WriteFile(plan, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	srv, err := server.New()
+	if err != nil {
+		t.Fatalf("server.New: %v", err)
+	}
+	var stdout, stderr bytes.Buffer
+	code := runPlan(srv, plan, planOptions{Format: string(server.ReportFormatJSON), Quiet: true}, strings.NewReader(""), &stdout, &stderr)
+	if code != 1 {
+		t.Errorf("exit code = %d, want 1", code)
+	}
+
+	errText := stderr.String()
+	for _, hidden := range []string{"预估", "[1/2] nightly / ok", "总报告已保存"} {
+		if strings.Contains(errText, hidden) {
+			t.Errorf("stderr should not contain %q under --quiet:\n%s", hidden, errText)
+		}
+	}
+	for _, shown := range []string{"[2/2] nightly / broken", "创建任务失败", "1 个场景未能完成"} {
+		if !strings.Contains(errText, shown) {
+			t.Errorf("stderr missing %q:\n%s", shown, errText)
+		}
+	}
+	if out := stdout.String(); !strings.Contains(out, "ok · m") || !strings.Contains(out, "|") {
+		t.Errorf("stdout should still carry the summary table:\n%s", out)
+	}
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"runtime/pprof"
+	"sync"
+)
+
+// profiler 按 --cpuprofile / --memprofile 采集 ait 进程自身的 pprof 数据，
+// 用于定位工具本身在高并发下的性能瓶颈。
+type profiler struct {
+	cpuFile *os.File
+	memPath string
+
+	once sync.Once
+	err  error
+}
+
+// startProfiling 开始采集：cpuPath 非空时立即开始 CPU 采集，memPath 非空时在 stop 时写出堆快照。
+// 两者都为空时返回的 profiler 不做任何事。
+func startProfiling(cpuPath, memPath string) (*profiler, error) {
+	p := &profiler{memPath: memPath}
+	if cpuPath == "" {
+		return p, nil
+	}
+	f, err := os.Create(cpuPath)
+	if err != nil {
+		return nil, fmt.Errorf("创建 CPU profile 文件失败: %w", err)
+	}
+	if err := pprof.StartCPUProfile(f); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("启动 CPU profile 失败: %w", err)
+	}
+	p.cpuFile = f
+	return p, nil
+}
+
+// enabled 返回是否开启了任一采集。
+func (p *profiler) enabled() bool {
+	return p.cpuFile != nil || p.memPath != ""
+}
+
+// stop 结束 CPU 采集并写出堆快照；可重复调用，只生效一次。
+func (p *profiler) stop() error {
+	p.once.Do(func() {
+		if p.cpuFile != nil {
+			pprof.StopCPUProfile()
+			if err := p.cpuFile.Close(); err != nil {
+				p.err = fmt.Errorf("写入 CPU profile 失败: %w", err)
+			}
+		}
+		if p.memPath != "" {
+			if err := writeHeapProfile(p.memPath); err != nil && p.err == nil {
+				p.err = err
+			}
+		}
+	})
+	return p.err
+}
+
+func writeHeapProfile(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("创建内存 profile 文件失败: %w", err)
+	}
+	defer f.Close()
+	runtime.GC() // 先 GC，让堆快照反映最新的存活对象
+	if err := pprof.WriteHeapProfile(f); err != nil {
+		return fmt.Errorf("写入内存 profile 失败: %w", err)
+	}
+	return nil
+}
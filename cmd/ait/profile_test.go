@@ -0,0 +1,51 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestStartProfiling(t *testing.T) {
+	dir := t.TempDir()
+	cpuPath := filepath.Join(dir, "cpu.pprof")
+	memPath := filepath.Join(dir, "mem.pprof")
+
+	prof, err := startProfiling(cpuPath, memPath)
+	if err != nil {
+		t.Fatalf("startProfiling: %v", err)
+	}
+	if !prof.enabled() {
+		t.Fatal("profiler should be enabled")
+	}
+	if err := prof.stop(); err != nil {
+		t.Fatalf("stop: %v", err)
+	}
+	if err := prof.stop(); err != nil {
+		t.Fatalf("second stop: %v", err)
+	}
+	for _, path := range []string{cpuPath, memPath} {
+		if info, err := os.Stat(path); err != nil || info.Size() == 0 {
+			t.Errorf("%s not written: %v", filepath.Base(path), err)
+		}
+	}
+}
+
+func TestStartProfiling_Disabled(t *testing.T) {
+	prof, err := startProfiling("", "")
+	if err != nil {
+		t.Fatalf("startProfiling: %v", err)
+	}
+	if prof.enabled() {
+		t.Error("profiler without paths should be disabled")
+	}
+	if err := prof.stop(); err != nil {
+		t.Errorf("stop: %v", err)
+	}
+}
+
+func TestStartProfiling_BadPath(t *testing.T) {
+	if _, err := startProfiling(filepath.Join(t.TempDir(), "missing", "cpu.pprof"), ""); err == nil {
+		t.Error("expected error for unwritable CPU profile path")
+	}
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/yinxulai/ait/internal/server"
+	"github.com/yinxulai/ait/internal/server/prompt"
+	"github.com/yinxulai/ait/internal/server/task"
+	"github.com/yinxulai/ait/internal/server/types"
+)
+
+// writeSessionFailed 把本次会话标准模式运行中失败请求的 prompt 以 JSONL 写入 path，返回导出的条数。
+// 输入长度扫描与按 prompt 文件分场景运行的各轮 prompt 来源不同，无法按序号还原，跳过。
+func writeSessionFailed(path string, srv server.Server, since time.Time) (int, error) {
+	var entries []prompt.ReplayEntry
+	for _, state := range sessionRuns(srv, since) {
+		if _, ok := state.ModeResult.(*types.InputLengthSweepResult); ok {
+			continue
+		}
+		if _, ok := state.ModeResult.(*types.PromptFileRunsResult); ok {
+			continue
+		}
+		def, err := srv.GetTask(state.TaskID)
+		if err != nil {
+			return 0, err
+		}
+		runEntries, err := failedEntries(def.Input, state)
+		if err != nil {
+			return 0, fmt.Errorf("%s: %w", def.Name, err)
+		}
+		entries = append(entries, runEntries...)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	if err := prompt.WriteReplayEntries(f, entries); err != nil {
+		return 0, err
+	}
+	return len(entries), nil
+}
+
+// failedEntries 导出一次运行中失败请求实际发送的 prompt。重放运行再次失败的请求沿用原任务 ID 与原序号，
+// 导出的文件可以继续重放。没有记录 prompt 的请求（早期版本的运行记录，或 prompt 生成失败而未发送）按请求序号还原。
+func failedEntries(input types.Input, state *server.RunState) ([]prompt.ReplayEntry, error) {
+	var failed []*types.RequestMetrics
+	for _, r := range state.Requests {
+		if r != nil && !r.Success {
+			failed = append(failed, r)
+		}
+	}
+	if len(failed) == 0 {
+		return nil, nil
+	}
+
+	hydrated, err := task.HydrateInput(input)
+	if err != nil {
+		return nil, err
+	}
+	source := hydrated.PromptSource
+	replay, isReplay := source.(types.ReplaySource)
+
+	entries := make([]prompt.ReplayEntry, 0, len(failed))
+	for _, r := range failed {
+		e := prompt.ReplayEntry{
+			TaskID: state.TaskID,
+			RunID:  string(state.RunID),
+			Index:  r.Index,
+			System: source.GetSystemContent(),
+			Prompt: r.Prompt,
+			Error:  r.ErrorMessage,
+		}
+		if e.Prompt == "" {
+			e.Prompt = source.GetContentByIndex(r.Index)
+		}
+		if isReplay {
+			e.TaskID = replay.OriginTaskID()
+			e.Index = replay.OriginIndex(r.Index)
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+// createReplayTask 执行 --replay：复制重放文件对应的原任务配置，改以重放文件为 prompt 来源创建新任务。
+func createReplayTask(srv server.Server, path string, stderr io.Writer) (types.TaskDefinition, error) {
+	replay, err := prompt.LoadReplayFile(path)
+	if err != nil {
+		return types.TaskDefinition{}, err
+	}
+	origin, err := srv.GetTask(replay.OriginTaskID())
+	if err != nil {
+		return types.TaskDefinition{}, fmt.Errorf("找不到原任务 %s: %w", replay.OriginTaskID(), err)
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return types.TaskDefinition{}, err
+	}
+
+	input := origin.Input
+	input.ReplayFile = abs
+	def, err := srv.CreateTask(server.TaskConfig{Name: origin.Name + " (重放)", Input: input})
+	if err != nil {
+		return types.TaskDefinition{}, err
+	}
+	fmt.Fprintf(stderr, "已创建重放任务 %q（%d 个请求），可在任务列表中运行\n", def.Name, def.Input.Count)
+	return def, nil
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/yinxulai/ait/internal/server"
+	"github.com/yinxulai/ait/internal/server/prompt"
+	"github.com/yinxulai/ait/internal/server/types"
+)
+
+func TestFailedEntries(t *testing.T) {
+	input := types.Input{
+		Protocol: types.ProtocolOpenAICompletions, Model: "gpt-4o",
+		PromptMode: "text", PromptText: "第 {{index}} 题",
+	}
+	state := &server.RunState{
+		RunID:  "run-1",
+		TaskID: "task-1",
+		Requests: []*types.RequestMetrics{
+			{Index: 0, Success: true},
+			{Index: 3, ErrorMessage: "HTTP 502"},
+			// 记录了实际发送的 prompt 时以记录为准（如 {{uuid}} 展开值、--prompt-command 输出）
+			{Index: 4, ErrorMessage: "HTTP 500", Prompt: "第 4 题 · 5f0c"},
+		},
+	}
+	entries, err := failedEntries(input, state)
+	if err != nil {
+		t.Fatalf("failedEntries: %v", err)
+	}
+	want := prompt.ReplayEntry{TaskID: "task-1", RunID: "run-1", Index: 3, Prompt: "第 3 题", Error: "HTTP 502"}
+	recorded := prompt.ReplayEntry{TaskID: "task-1", RunID: "run-1", Index: 4, Prompt: "第 4 题 · 5f0c", Error: "HTTP 500"}
+	if len(entries) != 2 || entries[0] != want || entries[1] != recorded {
+		t.Fatalf("entries = %+v, want [%+v %+v]", entries, want, recorded)
+	}
+	entries = entries[:1]
+
+	// 重放运行再次失败的请求沿用原任务 ID 与原序号
+	path := filepath.Join(t.TempDir(), "failed.jsonl")
+	writeReplayFile(t, path, entries)
+	input.ReplayFile = path
+	state = &server.RunState{RunID: "run-2", TaskID: "task-2", Requests: []*types.RequestMetrics{{Index: 0, ErrorMessage: "timeout"}}}
+	entries, err = failedEntries(input, state)
+	if err != nil {
+		t.Fatalf("failedEntries: %v", err)
+	}
+	want = prompt.ReplayEntry{TaskID: "task-1", RunID: "run-2", Index: 3, Prompt: "第 3 题", Error: "timeout"}
+	if len(entries) != 1 || entries[0] != want {
+		t.Fatalf("replayed entries = %+v, want [%+v]", entries, want)
+	}
+}
+
+func writeReplayFile(t *testing.T, path string, entries []prompt.ReplayEntry) {
+	t.Helper()
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if err := prompt.WriteReplayEntries(f, entries); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/yinxulai/ait/internal/server/report"
+)
+
+// runReport 执行 ait report 子命令：读取 --history-file 累积的历史 JSONL，按模型分组、按时间排序，
+// 渲染为趋势页（html）或趋势表（md），写入 --output 或 stdout。
+func runReport(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("ait report", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	from := fs.String("from", "", "历史文件路径（--history-file 写入的 JSONL）")
+	format := fs.String("format", "html", "输出格式：html 或 md")
+	output := fs.String("output", "", "输出文件路径，留空时写到 stdout")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *from == "" {
+		fmt.Fprintln(stderr, "用法: ait report --from history.jsonl [--format html|md] [--output trend.html]")
+		return 2
+	}
+	var render func(io.Writer, []report.ModelTrend) error
+	switch *format {
+	case "html":
+		render = report.WriteTrendHTML
+	case "md":
+		render = report.WriteTrendMarkdown
+	default:
+		fmt.Fprintf(stderr, "不支持的格式 %q，可选 html / md\n", *format)
+		return 2
+	}
+
+	f, err := os.Open(*from)
+	if err != nil {
+		fmt.Fprintf(stderr, "读取历史文件失败: %v\n", err)
+		return 1
+	}
+	entries, err := report.ReadHistory(f)
+	f.Close()
+	if err != nil {
+		fmt.Fprintf(stderr, "解析历史文件失败: %v\n", err)
+		return 1
+	}
+	if len(entries) == 0 {
+		fmt.Fprintf(stderr, "历史文件 %s 中没有记录\n", *from)
+		return 1
+	}
+
+	w := stdout
+	if *output != "" {
+		out, err := os.Create(*output)
+		if err != nil {
+			fmt.Fprintf(stderr, "创建输出文件失败: %v\n", err)
+			return 1
+		}
+		defer out.Close()
+		w = out
+	}
+	if err := render(w, report.BuildTrends(entries)); err != nil {
+		fmt.Fprintf(stderr, "生成趋势报告失败: %v\n", err)
+		return 1
+	}
+	return 0
+}
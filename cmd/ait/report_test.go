@@ -0,0 +1,56 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunReport(t *testing.T) {
+	dir := t.TempDir()
+	history := filepath.Join(dir, "history.jsonl")
+	_ = os.WriteFile(history, []byte(`{"time":"2025-01-01T10:00:00Z","model":"gpt-4o","stream_mode":"stream","concurrency":4,"avg_tps":42}
+{"time":"2025-01-02T10:00:00Z","model":"gpt-4o","stream_mode":"stream","concurrency":4,"avg_tps":45}
+`), 0o644)
+	broken := filepath.Join(dir, "broken.jsonl")
+	_ = os.WriteFile(broken, []byte("oops\n"), 0o644)
+	emptyFile := filepath.Join(dir, "empty.jsonl")
+	_ = os.WriteFile(emptyFile, nil, 0o644)
+
+	tests := []struct {
+		name     string
+		args     []string
+		wantCode int
+		wantOut  string
+	}{
+		{"no from", nil, 2, ""},
+		{"bad format", []string{"--from", history, "--format", "pdf"}, 2, ""},
+		{"html", []string{"--from", history}, 0, "<polyline"},
+		{"markdown", []string{"--from", history, "--format", "md"}, 0, "### gpt-4o"},
+		{"missing file", []string{"--from", filepath.Join(dir, "none.jsonl")}, 1, ""},
+		{"broken file", []string{"--from", broken}, 1, ""},
+		{"empty file", []string{"--from", emptyFile}, 1, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			if code := runReport(tt.args, &stdout, &stderr); code != tt.wantCode {
+				t.Fatalf("exit code = %d, want %d\nstderr: %s", code, tt.wantCode, stderr.String())
+			}
+			if !strings.Contains(stdout.String(), tt.wantOut) {
+				t.Errorf("stdout missing %q:\n%s", tt.wantOut, stdout.String())
+			}
+		})
+	}
+
+	out := filepath.Join(dir, "trend.html")
+	var stdout, stderr bytes.Buffer
+	if code := runReport([]string{"--from", history, "--output", out}, &stdout, &stderr); code != 0 {
+		t.Fatalf("exit code = %d, stderr: %s", code, stderr.String())
+	}
+	if data, err := os.ReadFile(out); err != nil || !strings.Contains(string(data), "gpt-4o") || stdout.Len() != 0 {
+		t.Errorf("--output: file %q err %v, stdout %q", data, err, stdout.String())
+	}
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/yinxulai/ait/internal/i18n"
+	"github.com/yinxulai/ait/internal/server"
+	"github.com/yinxulai/ait/internal/tui/pages/shared"
+)
+
+// ansiYellow / ansiReset 终端下把自监控告警渲染为黄色
+const (
+	ansiYellow = "\033[33m"
+	ansiReset  = "\033[0m"
+)
+
+// printSessionSelfMonitor 执行 --self-monitor 的结束报告：为本次会话每次开启自监控的运行输出 goroutine 数与
+// 堆内存的起止对比，持续增长时追加告警（color 为 true 时以黄色显示）。
+func printSessionSelfMonitor(w io.Writer, srv server.Server, since time.Time, color bool) {
+	for _, state := range sessionRuns(srv, since) {
+		s := state.SelfStats
+		if s == nil || len(s.Timeline) == 0 {
+			continue
+		}
+		first, last := s.Timeline[0], s.Timeline[len(s.Timeline)-1]
+		fmt.Fprintf(w, i18n.T(i18n.KCLISelfMonitorFmt)+"\n", runLabel(state),
+			first.Goroutines, last.Goroutines, shared.FmtBytes(first.HeapAlloc), shared.FmtBytes(last.HeapAlloc), last.NumGC, len(s.Timeline))
+
+		var warnings []string
+		if s.GoroutineGrowing {
+			warnings = append(warnings, fmt.Sprintf(i18n.T(i18n.KCLISelfMonitorGoroutineGrowingFmt), first.Goroutines, last.Goroutines))
+		}
+		if s.HeapGrowing {
+			warnings = append(warnings, fmt.Sprintf(i18n.T(i18n.KCLISelfMonitorHeapGrowingFmt), shared.FmtBytes(first.HeapAlloc), shared.FmtBytes(last.HeapAlloc)))
+		}
+		for _, warning := range warnings {
+			if color {
+				warning = ansiYellow + warning + ansiReset
+			}
+			fmt.Fprintln(w, "  "+warning)
+		}
+	}
+}
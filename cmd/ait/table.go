@@ -0,0 +1,208 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/yinxulai/ait/internal/i18n"
+	"github.com/yinxulai/ait/internal/server"
+	"github.com/yinxulai/ait/internal/server/report"
+	"github.com/yinxulai/ait/internal/server/types"
+)
+
+// sessionRuns 收集 since 之后开始、且已结束的标准运行，按结束时间排序。
+func sessionRuns(srv server.Server, since time.Time) []*server.RunState {
+	tasks, err := srv.ListTasks()
+	if err != nil {
+		return nil
+	}
+
+	var runs []types.TaskRunSummary
+	for _, task := range tasks {
+		history, err := srv.ListTaskRunHistory(task.ID, 0)
+		if err != nil {
+			continue
+		}
+		for _, run := range history {
+			if run.Mode == "standard" && !run.StartedAt.Before(since) && !run.FinishedAt.IsZero() {
+				runs = append(runs, run)
+			}
+		}
+	}
+	sort.Slice(runs, func(i, j int) bool { return runs[i].FinishedAt.Before(runs[j].FinishedAt) })
+
+	var states []*server.RunState
+	for _, run := range runs {
+		if state, ok := srv.GetRunState(server.RunID(run.RunID)); ok {
+			states = append(states, state)
+		}
+	}
+	return states
+}
+
+// sessionReports 收集本次会话的标准运行结果，A/B 对比运行展开为流式、非流式两行，输入长度扫描按长度各占一行。
+func sessionReports(srv server.Server, since time.Time) []types.ReportData {
+	var data []types.ReportData
+	for _, state := range sessionRuns(srv, since) {
+		data = append(data, runReports(state)...)
+	}
+	return data
+}
+
+// writeSessionSQLite 执行 --sqlite：把本次会话每次运行的每份结果连同任务配置写入 SQLite 数据库 path，返回写入的行数。
+// 任务已被删除的运行只写结果，配置留空。
+func writeSessionSQLite(path string, srv server.Server, since time.Time) (int, error) {
+	var records []report.SQLiteRecord
+	for _, state := range sessionRuns(srv, since) {
+		def, _ := srv.GetTask(state.TaskID)
+		reports := runReports(state)
+		for i := range reports {
+			records = append(records, report.SQLiteRecord{
+				RunID:    string(state.RunID),
+				TaskID:   state.TaskID,
+				TaskName: def.Name,
+				Seq:      i,
+				Input:    def.Input,
+				Report:   &reports[i],
+			})
+		}
+	}
+	if len(records) == 0 {
+		return 0, nil
+	}
+	return len(records), report.WriteSQLite(path, records)
+}
+
+// runReports 返回一次标准运行的结果行，展开规则同 sessionReports；没有结果时返回 nil。
+func runReports(state *server.RunState) []types.ReportData {
+	switch result := state.ModeResult.(type) {
+	case *types.ReportData:
+		return []types.ReportData{*result}
+	case *types.StreamCompareResult:
+		return result.Reports()
+	case *types.InputLengthSweepResult:
+		return result.Reports()
+	case *types.HTTPVersionCompareResult:
+		return result.Reports()
+	case *types.PromptFileRunsResult:
+		return result.Reports()
+	}
+	return nil
+}
+
+// printSessionTable 把本次会话的结果表以 format（tsv / csv）写入 w；没有结果时不输出。
+// explain 为 true 时在表后追加各列的说明。
+func printSessionTable(w io.Writer, srv server.Server, since time.Time, format string, explain bool) error {
+	data := sessionReports(srv, since)
+	if len(data) == 0 {
+		return nil
+	}
+	if err := report.WriteTable(w, format, data); err != nil {
+		return err
+	}
+	// 有运行命中多个目标 IP 时，追加一张按 IP 聚合的小表
+	var ipTable bytes.Buffer
+	if err := report.WriteTargetIPTable(&ipTable, format, data); err != nil {
+		return err
+	}
+	if ipTable.Len() > 0 {
+		fmt.Fprintln(w)
+		if _, err := ipTable.WriteTo(w); err != nil {
+			return err
+		}
+	}
+	if explain {
+		fmt.Fprintln(w)
+		printMetricExplain(w)
+	}
+	return nil
+}
+
+// writeSessionMarkdown 把本次会话的结果以 Markdown 写入 path；appendMode 为 true 时追加写入
+// （GitHub Actions 的 $GITHUB_STEP_SUMMARY 由同一 job 的多个 step 共用）。没有结果时不写文件。
+func writeSessionMarkdown(path string, appendMode bool, srv server.Server, since time.Time) error {
+	data := sessionReports(srv, since)
+	if len(data) == 0 {
+		return nil
+	}
+	flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	if appendMode {
+		flags = os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	}
+	f, err := os.OpenFile(path, flags, 0o644)
+	if err != nil {
+		return err
+	}
+	if err := report.WriteMarkdown(f, data); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// isReportFormat 返回 format 是否为 --report-format 支持的报告格式
+func isReportFormat(format string) bool {
+	switch server.ReportFormat(format) {
+	case server.ReportFormatJSON, server.ReportFormatCSV, server.ReportFormatMarkdown, server.ReportFormatK6:
+		return true
+	}
+	return false
+}
+
+// writeSessionReport 把本次会话的结果以 format 格式写入当前目录的报告文件，返回文件路径；没有结果时不写文件。
+func writeSessionReport(format string, srv server.Server, since time.Time) (string, error) {
+	data := sessionReports(srv, since)
+	if len(data) == 0 {
+		return "", nil
+	}
+	paths, err := report.NewReportManager().GenerateReports(data, []string{format})
+	if err != nil {
+		return "", err
+	}
+	return paths[0], nil
+}
+
+// printSessionSlowest 为本次会话的每次运行输出总耗时最长的 n 个请求，便于定位长尾。
+func printSessionSlowest(w io.Writer, srv server.Server, since time.Time, n int) error {
+	for i, state := range sessionRuns(srv, since) {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		title := fmt.Sprintf(i18n.T(i18n.KCLISlowestTitleFmt), n, runLabel(state))
+		if err := report.WriteSlowestTable(w, title, report.SlowestRequests(state.Requests, n)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runLabel 用模型名标识一次运行，拿不到结果时退回运行 ID
+func runLabel(state *server.RunState) string {
+	switch result := state.ModeResult.(type) {
+	case *types.ReportData:
+		if result.Model != "" {
+			return result.Model
+		}
+	case *types.StreamCompareResult:
+		if reports := result.Reports(); len(reports) > 0 && reports[0].Model != "" {
+			return reports[0].Model
+		}
+	case *types.InputLengthSweepResult:
+		if reports := result.Reports(); len(reports) > 0 && reports[0].Model != "" {
+			return reports[0].Model
+		}
+	case *types.HTTPVersionCompareResult:
+		if reports := result.Reports(); len(reports) > 0 && reports[0].Model != "" {
+			return reports[0].Model
+		}
+	case *types.PromptFileRunsResult:
+		if reports := result.Reports(); len(reports) > 0 && reports[0].Model != "" {
+			return reports[0].Model
+		}
+	}
+	return string(state.RunID)
+}
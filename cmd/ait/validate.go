@@ -0,0 +1,101 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/yinxulai/ait/internal/server/config"
+	"github.com/yinxulai/ait/internal/server/prompt"
+)
+
+// validateIssue 校验汇总表中的一行
+type validateIssue struct {
+	source  string // prompt / config
+	target  string // 文件路径或字段名
+	problem string
+}
+
+// runValidate 执行 ait validate 子命令：离线校验 prompt 文件（或 stdin 逐行 prompt）与任务配置文件，
+// 问题汇总成表输出，有问题时返回非 0 退出码。
+func runValidate(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("ait validate", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	promptFile := fs.String("prompt-file", "", "prompt 文件路径，支持通配符（如 \"prompts/*.txt\"）")
+	configFile := fs.String("config", "", "任务配置文件路径（JSON）")
+	promptStdinLines := fs.Bool("prompt-stdin-lines", false, "从 stdin 逐行读取 prompt，每个非空行作为一个 prompt")
+	maxSize := fs.Int64("max-size", prompt.DefaultMaxFileSize, "单个 prompt 文件的大小上限（字节）")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *promptFile == "" && *configFile == "" && !*promptStdinLines {
+		fmt.Fprintln(stderr, "用法: ait validate --prompt-file \"prompts/*.txt\" [--config task.json]")
+		fmt.Fprintln(stderr, "      cat prompts.txt | ait validate --prompt-stdin-lines")
+		return 2
+	}
+	if *promptFile != "" && *promptStdinLines {
+		fmt.Fprintln(stderr, "--prompt-file 与 --prompt-stdin-lines 不能同时使用")
+		return 2
+	}
+
+	var issues []validateIssue
+	if *promptFile != "" {
+		result, err := prompt.ValidateFiles(*promptFile, *maxSize)
+		if err != nil {
+			issues = append(issues, validateIssue{"prompt", *promptFile, err.Error()})
+		} else {
+			fmt.Fprintf(stdout, "prompt 文件: %d 个，通过 %d 个\n", result.Stats.Files, result.Stats.Valid)
+			issues = append(issues, promptIssues(stdout, result)...)
+		}
+	}
+	if *promptStdinLines {
+		result, err := prompt.ValidateLines(stdin, "stdin", *maxSize)
+		if err != nil {
+			issues = append(issues, validateIssue{"prompt", "stdin", err.Error()})
+		} else {
+			fmt.Fprintf(stdout, "stdin prompt: %d 行，通过 %d 行\n", result.Stats.Files, result.Stats.Valid)
+			issues = append(issues, promptIssues(stdout, result)...)
+		}
+	}
+	if *configFile != "" {
+		result, err := config.ValidateTaskFile(*configFile)
+		if err != nil {
+			issues = append(issues, validateIssue{"config", *configFile, err.Error()})
+		}
+		for _, issue := range result {
+			target := *configFile
+			if issue.Field != "" {
+				target = issue.Field
+			}
+			issues = append(issues, validateIssue{"config", target, issue.Problem})
+		}
+	}
+
+	if len(issues) == 0 {
+		fmt.Fprintln(stdout, "校验通过")
+		return 0
+	}
+	fmt.Fprintf(stdout, "\n发现 %d 个问题:\n", len(issues))
+	tw := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "来源\t对象\t问题")
+	for _, issue := range issues {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", issue.source, issue.target, issue.problem)
+	}
+	tw.Flush()
+	return 1
+}
+
+// promptIssues 输出通过校验的 prompt 的长度分布，并把校验问题转换为汇总表的行。
+func promptIssues(stdout io.Writer, result *prompt.FileValidation) []validateIssue {
+	s := result.Stats
+	if s.Valid > 0 {
+		fmt.Fprintf(stdout, "字符数: 最短 %d / 最长 %d / 平均 %.1f\n", s.MinChars, s.MaxChars, s.AvgChars)
+		fmt.Fprintf(stdout, "估算 token: 最少 %d / 最多 %d / 平均 %.1f\n", s.MinTokens, s.MaxTokens, s.AvgTokens)
+	}
+	issues := make([]validateIssue, 0, len(result.Issues))
+	for _, issue := range result.Issues {
+		issues = append(issues, validateIssue{"prompt", issue.Path, issue.Problem})
+	}
+	return issues
+}
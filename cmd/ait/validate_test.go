@@ -0,0 +1,47 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunValidate(t *testing.T) {
+	dir := t.TempDir()
+	good := filepath.Join(dir, "good.txt")
+	empty := filepath.Join(dir, "empty.txt")
+	task := filepath.Join(dir, "task.json")
+	_ = os.WriteFile(good, []byte("hello"), 0o644)
+	_ = os.WriteFile(empty, nil, 0o644)
+	_ = os.WriteFile(task, []byte(`{"name":"x","input":{"protocol":"openai","model":"m","concurrency":1,"count":1,"prompt_text":"hi"}}`), 0o644)
+
+	tests := []struct {
+		name     string
+		args     []string
+		stdin    string
+		wantCode int
+		wantOut  string
+	}{
+		{"no flags", nil, "", 2, ""},
+		{"valid", []string{"--prompt-file", good, "--config", task}, "", 0, "校验通过"},
+		{"empty prompt", []string{"--prompt-file", filepath.Join(dir, "*.txt")}, "", 1, "空文件"},
+		{"missing config", []string{"--config", filepath.Join(dir, "none.json")}, "", 1, "none.json"},
+		{"stdin lines", []string{"--prompt-stdin-lines"}, "hello\n\nworld\r\n", 0, "stdin prompt: 2 行，通过 2 行"},
+		{"stdin invalid line", []string{"--prompt-stdin-lines"}, "ok\n\xff\n", 1, "stdin:2"},
+		{"stdin empty", []string{"--prompt-stdin-lines"}, "\n  \n", 1, "没有读取到非空的 prompt 行"},
+		{"stdin with prompt file", []string{"--prompt-stdin-lines", "--prompt-file", good}, "", 2, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			if code := runValidate(tt.args, strings.NewReader(tt.stdin), &stdout, &stderr); code != tt.wantCode {
+				t.Fatalf("exit code = %d, want %d\nstdout: %s\nstderr: %s", code, tt.wantCode, stdout.String(), stderr.String())
+			}
+			if !strings.Contains(stdout.String(), tt.wantOut) {
+				t.Errorf("stdout missing %q:\n%s", tt.wantOut, stdout.String())
+			}
+		})
+	}
+}
@@ -89,6 +89,12 @@ const (
 	KCacheHit
 	KRPM
 	KTPM
+	KInstantTPS // 滑动窗口实时 TPS
+	KThinkingTime
+	KSelfStats            // 工具自身资源占用
+	KSelfStatsResidualFmt // "残留 goroutine %d"
+	KSelfStatsLeak        // 残留 goroutine 超出阈值的警告
+	KSelfStatsGrowing     // goroutine / 堆内存持续增长的警告
 	KStatus
 	KTotalTime
 	KTTFT
@@ -100,6 +106,8 @@ const (
 	KTCPConnect
 	KTLSHandshake
 	KTargetIP
+	KTraceID   // 本地 trace id（X-Client-Request-Id）
+	KRequestID // 供应商返回的请求 ID
 
 	// ─── Status values ───────────────────────────────────────────────────────
 	KRunning
@@ -116,6 +124,8 @@ const (
 	KRamp
 	KPerLevel
 	KStopCondLabel
+	KKneeLabel    // 并发扫描的错误率 / 延迟拐点
+	KTurboKneeFmt // "安全 %d · 错误率 %s · P99 %s"
 	KTurboMode
 	KStandardMode
 	KTurboMonitor
@@ -365,6 +375,197 @@ const (
 	KHoursAgoFmt    // "%d 小时前"
 	KDaysAgoFmt     // "%d 天前"
 	KToggleLang     // "切换语言" / "Toggle Lang"
+
+	// ─── Baseline ────────────────────────────────────────────────────────────
+	KBaseline
+	KBaselineSaved
+	KBaselinePassed
+	KBaselineRegressedFmt // "%d 项指标回退"
+
+	// ─── Finish reason ───────────────────────────────────────────────────────
+	KFinishReason
+
+	// ─── Steady TPS ──────────────────────────────────────────────────────────
+	KSteadyTPS
+	KHelpTermSteadyTPS
+	KHelpDescSteadyTPS
+
+	// ─── Cached token ratio ──────────────────────────────────────────────────
+	KCachedTokenRatio
+	KHelpTermCachedTokenRatio
+	KHelpDescCachedTokenRatio
+
+	// ─── Content check ───────────────────────────────────────────────────────
+	KSuspiciousContent
+	KSuspiciousContentFmt // "%d 条疑似乱码/复读"
+
+	// ─── Shard ───────────────────────────────────────────────────────────────
+	KShardFmt // "分片 %s：本进程 %d"
+
+	// ─── SLA ─────────────────────────────────────────────────────────────────
+	KSLA
+
+	// ─── Prompt length distribution ──────────────────────────────────────────
+	KPromptLengthDistFmt // "长度分布 %s"
+
+	// ─── Target IP stats ─────────────────────────────────────────────────────
+	KTargetIPStatFmt   // "%s  %d 次 · 平均 %s · TTFT %s"
+	KTargetIPUnusedFmt // "%s  解析到但未连接"
+	KResolvedIPsFmt    // "%s（DNS 返回 %d 个 IP）"
+
+	// ─── Tool calls ──────────────────────────────────────────────────────────
+	KAvgToolCalls
+	KToolsFileFmt // "工具 %s"
+	KRedacted     // "已脱敏"
+
+	// ─── Connections ─────────────────────────────────────────────────────────
+	KPeakConnections
+	KPeakConnectionsFmt // "%d / 并发 %d"
+	KQueueWait
+	KQueueWaitFmt  // "平均 %s · 最大 %s"
+	KQueueWaitHint // "并发设置可能过高，本地排队明显"
+	KConnReuse
+	KConnReuseFmt // "%d/%d 个请求（%.1f%%）"
+
+	// ─── CLI result output ───────────────────────────────────────────────────
+	KExplainTitle
+	KExplainModel
+	KExplainStreamMode
+	KExplainConcurrency
+	KExplainTotalRequests
+	KExplainSuccessRate
+	KExplainAvgTotalTime
+	KExplainAvgTTFT
+	KExplainAvgTPOT
+	KExplainAvgTPS
+	KExplainAvgSteadyTPS
+	KExplainAvgThroughput
+	KExplainRPM
+	KExplainTPM
+	KExplainTargetIP
+	KExplainTargetIPRequests
+	KCLISlowestTitleFmt // "Top %d 最慢请求 · %s"
+	KCLITableFailedFmt  // "输出结果表失败: %v"
+	KCLISlowestFailedFmt
+	KCLISelfMonitorFmt                 // "自监控 %s: goroutine %d → %d · 堆内存 %s → %s · GC %d · 采样 %d"
+	KCLISelfMonitorGoroutineGrowingFmt // "⚠ goroutine 数持续增长 (%d → %d)，ait 自身可能存在泄漏"
+	KCLISelfMonitorHeapGrowingFmt      // "⚠ 堆内存持续增长 (%s → %s)，ait 自身可能存在泄漏"
+	KCLITokenTraceWarnFmt              // "⚠ 已开启 --token-trace：流式请求的 chunk 时序写入 %s，会增加内存与磁盘占用（单请求最多 %d 个 chunk）"
+	KCLIRegressed
+	KCLISLAFailed
+	KCLIAbortedEarly
+	KCLIMarkdownFailedFmt // "写入 Markdown 结果失败: %v"
+	KCLIGHSummaryUnset
+	KCLIHistoryFailedFmt // "追加历史记录失败: %v"
+	KCLISQLiteFailedFmt  // "写入 SQLite 失败: %v"
+	KCLISQLiteWrittenFmt
+	KCLITelemetryNotice
+	KCLIUpdateAvailableFmt
+	KCLIFailedExportFmt // "导出失败请求失败: %v"
+	KCLIFailedExportedFmt
+	KCLICurlExportFmt // "导出 curl 命令失败: %v"
+	KCLICurlExportedFmt
+	KCLIBundleExportFmt // "导出 bundle 失败: %v"
+	KCLIBundleExportedFmt
+	KCLIReportFailedFmt // "生成报告失败: %v"
+	KCLIReportSavedFmt
+
+	// ─── Request ID check ────────────────────────────────────────────────────
+	KRequestIDCheck
+	KRequestIDCheckFmt // "回传 %d · 不一致 %d"
+	KResponseEncoding
+	KResponseEncodingFmt // "%s ×%d · 压缩率 %.0f%% · TTFT %s"
+	KSchemaCheck
+	KSchemaCheckFmt // "合规 %.1f%% · 合法 JSON %.1f%% · 共 %d"
+
+	// ─── Normalized tokens ───────────────────────────────────────────────────
+	KNormalizedTPS
+	KExplainNormalizedTPS
+
+	// ─── Interactive concurrency ─────────────────────────────────────────────
+	KAdjustConcurrency
+	KConcurrencyAdjustedFmt
+	KHelpTermAdjustConcurrency
+	KHelpDescAdjustConcurrency
+
+	// ─── Pause / resume ──────────────────────────────────────────────────────
+	KPauseResume
+	KRunPaused
+	KRunResumed
+	KPausedTag
+	KHelpTermPauseResume
+	KHelpDescPauseResume
+
+	// ─── Burst ───────────────────────────────────────────────────────────────
+	KBurst
+	KBurstFmt
+
+	// ─── Fallback model ──────────────────────────────────────────────────────
+	KFallback
+	KFallbackFmt
+
+	// ─── Network probe ───────────────────────────────────────────────────────
+	KNetworkProbe
+	KNetworkProbeFmt
+	KProbeVerdict
+	KProbeVerdictServer
+	KProbeVerdictNetwork
+
+	// ─── Input length sweep ──────────────────────────────────────────────────
+	KLengthSweep
+	KLengthSweepFmt
+	KPromptFileScenario
+	KPromptFileScenarioFmt // "%s：成功率 %.1f%%，平均总耗时 %s，TPS %.1f"
+
+	// ─── Phase analysis ──────────────────────────────────────────────────────
+	KPhase
+	KPhaseWarmup
+	KPhaseSteady
+	KPhaseCooldown
+	KPhaseFmt
+	KPhaseSkewedHint
+
+	// ─── Replay ──────────────────────────────────────────────────────────────
+	KReplay
+	KReplayOfFmt
+	KReplayOriginFmt
+
+	// ─── Stream events ───────────────────────────────────────────────────────
+	KBlockTiming
+
+	// ─── Stream compare ──────────────────────────────────────────────────────
+	KStreamCompare
+	KNonStream
+	KHTTPVersionCompare
+	KHTTPProtocols
+
+	// ─── Network errors ──────────────────────────────────────────────────────
+	KNetErrors
+
+	// ─── Consistency check ───────────────────────────────────────────────────
+	KConsistency
+	KConsistencyFmt
+
+	// ─── DNS policy ──────────────────────────────────────────────────────────
+	KDNSPolicy
+
+	// ─── Empty content ───────────────────────────────────────────────────────
+	KEmptyContent
+	KEmptyContentFmt // "%d 条成功但无正文 (%.1f%%)，可能被安全策略过滤"
+	KMaxTokensUsage
+	KMaxTokensUsageFmt    // "%.0f%% 请求被 max_tokens 截断 · 平均用量 %.0f%%"
+	KMaxTokensExceededFmt // "%d 条超出上限，服务可能忽略了 max_tokens"
+	KAbortedEarly
+	KAbortedEarlyFmt // "错误率 %.1f%% 超过阈值 %.0f%%（%d/%d 失败），已提前终止"
+
+	// ─── First request ───────────────────────────────────────────────────────
+	KFirstRequest
+	KFirstRequestFmt // "#%d TTFT %s · 总耗时 %s"
+	KWarmRequestFmt  // "其余 %d 个 TTFT %s · 总耗时 %s"
+	KColdStartHint   // "首请求比其余请求慢 %.0f%%，冷启动明显"
+
+	// keyCount 键的总数，必须保持在最后；测试据此检查每个键在各语言下都有译文
+	keyCount
 )
 
 var translations = [2]map[Key]string{
@@ -420,23 +621,31 @@ var translations = [2]map[Key]string{
 		KHintNew:       "[a] 创建任务",
 
 		// Metric labels
-		KSuccessRate:  "成功率",
-		KAvgTPS:       "TPS均值",
-		KAvgTTFT:      "TTFT均值",
-		KCacheHit:     "缓存命中",
-		KRPM:          "RPM",
-		KTPM:          "TPM",
-		KStatus:       "状态",
-		KTotalTime:    "总耗时",
-		KTTFT:         "TTFT",
-		KOutputTPS:    "输出TPS",
-		KToken:        "Token",
-		KCache:        "缓存",
-		KError:        "错误",
-		KDNS:          "DNS",
-		KTCPConnect:   "TCP 连接",
-		KTLSHandshake: "TLS 握手",
-		KTargetIP:     "目标 IP",
+		KSuccessRate:          "成功率",
+		KAvgTPS:               "TPS均值",
+		KAvgTTFT:              "TTFT均值",
+		KCacheHit:             "缓存命中",
+		KRPM:                  "RPM",
+		KTPM:                  "TPM",
+		KInstantTPS:           "实时TPS",
+		KThinkingTime:         "思考",
+		KSelfStats:            "自监控",
+		KSelfStatsResidualFmt: "残留 goroutine %d",
+		KSelfStatsLeak:        "疑似泄漏",
+		KSelfStatsGrowing:     "持续增长",
+		KStatus:               "状态",
+		KTotalTime:            "总耗时",
+		KTTFT:                 "TTFT",
+		KOutputTPS:            "输出TPS",
+		KToken:                "Token",
+		KCache:                "缓存",
+		KError:                "错误",
+		KDNS:                  "DNS",
+		KTCPConnect:           "TCP 连接",
+		KTLSHandshake:         "TLS 握手",
+		KTargetIP:             "目标 IP",
+		KTraceID:              "Trace ID",
+		KRequestID:            "请求 ID",
 
 		// Status values
 		KRunning:       "运行中",
@@ -453,6 +662,8 @@ var translations = [2]map[Key]string{
 		KRamp:             "爬坡",
 		KPerLevel:         "每级",
 		KStopCondLabel:    "停止",
+		KKneeLabel:        "拐点",
+		KTurboKneeFmt:     "安全 %d · 错误率 %s · P99 %s",
 		KTurboMode:        "Turbo 模式",
 		KStandardMode:     "标准",
 		KTurboMonitor:     "Turbo 探测监控",
@@ -636,68 +847,68 @@ var translations = [2]map[Key]string{
 		KHelpDescCSVReport:  "表格形式的汇总数据，可直接在电子表格中打开。报告默认保存在当前工作目录。",
 
 		// Wizard fields
-		KWzTaskName:         "任务名称",
-		KWzProtocol:         "协议类型",
-		KWzEndpoint:         "接口地址",
-		KWzAPIKey:           "API 密钥",
-		KWzTestModel:        "测试模型",
-		KWzTestMode:         "测试模式",
-		KWzTurboMode:        "Turbo 模式",
-		KWzStandardMode:     "标准模式",
-		KWzIntegrityMode:    "Integrity 模式",
-		KWzIntegritySuite:  "测试套件",
-		KWzFailFast:       "遇错即停",
-		KWzConcurrency:      "并发数",
-		KWzTotalRequests:    "请求总数",
-		KWzTimeoutSecs:      "超时(秒)",
-		KWzInitConc:         "初始并发",
-		KWzMaxConc:          "最大并发",
-		KWzStepSize:         "步进值",
-		KWzLevelReqs:        "每级请求数",
-		KWzMinSuccessRate:   "最低成功率",
-		KWzStreamMode:       "流式模式",
-		KWzInputMode:        "输入方式",
-		KWzInputDirect:      "直接输入",
-		KWzInputFile:        "文件",
-		KWzInputGenerated:   "按长度生成",
-		KWzInputRaw:         "RAW 请求体",
-		KWzPromptConfig:     "Prompt 配置",
-		KWzSelectModeHint:   "选择压测模式，并补全并发与 Prompt 参数。",
-		KWzTurboModeLabel:   "Turbo 模式",
+		KWzTaskName:           "任务名称",
+		KWzProtocol:           "协议类型",
+		KWzEndpoint:           "接口地址",
+		KWzAPIKey:             "API 密钥",
+		KWzTestModel:          "测试模型",
+		KWzTestMode:           "测试模式",
+		KWzTurboMode:          "Turbo 模式",
+		KWzStandardMode:       "标准模式",
+		KWzIntegrityMode:      "Integrity 模式",
+		KWzIntegritySuite:     "测试套件",
+		KWzFailFast:           "遇错即停",
+		KWzConcurrency:        "并发数",
+		KWzTotalRequests:      "请求总数",
+		KWzTimeoutSecs:        "超时(秒)",
+		KWzInitConc:           "初始并发",
+		KWzMaxConc:            "最大并发",
+		KWzStepSize:           "步进值",
+		KWzLevelReqs:          "每级请求数",
+		KWzMinSuccessRate:     "最低成功率",
+		KWzStreamMode:         "流式模式",
+		KWzInputMode:          "输入方式",
+		KWzInputDirect:        "直接输入",
+		KWzInputFile:          "文件",
+		KWzInputGenerated:     "按长度生成",
+		KWzInputRaw:           "RAW 请求体",
+		KWzPromptConfig:       "Prompt 配置",
+		KWzSelectModeHint:     "选择压测模式，并补全并发与 Prompt 参数。",
+		KWzTurboModeLabel:     "Turbo 模式",
 		KWzIntegrityModeLabel: "Integrity 完整性验证模式",
-		KWzStepFmt:          "步骤 %d/3",
-		KWzStep1Label:       "1 基本信息",
-		KWzStep2Label:       "2 测试参数",
-		KWzStep3Label:       "3 确认保存",
-		KWzStep1Desc:        "配置任务名称、模型协议和连接信息。",
-		KWzStep2Desc:        "选择压测模式，并补全并发与 Prompt 参数。",
-		KWzStep3Desc:        "保存前快速检查关键配置。",
-		KWzUntitled:         "未命名任务",
-		KWzNotFilled:        "未填写",
-		KWzExecParams:       "执行参数",
-		KWzConcurrencyRamp:  "并发爬坡",
-		KWzStopCondition:    "停止条件",
-		KWzTimeoutLabel:     "超时",
-		KWzContentSummary:   "内容摘要",
-		KWzBodyBytes:        "Body 字节数",
-		KWzSaveLocation:     "保存位置",
-		KWzPromptSection:    "Prompt",
-		KWzHintDirect:       "直接粘贴或输入 Prompt 文本，所有请求共享同一段内容",
-		KWzHintFile:         "从文件读取 Prompt，支持通配符匹配多个文件（请求按文件轮换）",
-		KWzHintRaw:          "粘贴完整的 HTTP 请求 JSON Body，将跳过参数组装直接发送",
-		KWzHintCacheToken:   "提示：大多数服务需要 ≥ 1024 tokens 才能命中缓存",
-		KWzHintRawBody:      "提示：粘贴 API 请求的完整 JSON Body，将直接作为 HTTP 请求体发送",
-		KWzJSONBody:         "JSON Body",
-		KWzPromptLabelShort: "Prompt",
-		KWzRAWBody:          "RAW 请求体",
-		KWzFileSummary:      "文件",
-		KWzGeneratedFmt:     "生成 %d 字符",
-		KWzPromptContent:    "内容",
-		KWzNoConfirmItems:   "暂无确认项",
-		KWzConfirmRange:     "确认项 %d-%d/%d",
-		KWzConfirmTotal:     "共 %d 项待确认",
-		KWzNoFields:         "暂无配置项",
-		KWzFieldProgress:    "当前字段 %d/%d",
+		KWzStepFmt:            "步骤 %d/3",
+		KWzStep1Label:         "1 基本信息",
+		KWzStep2Label:         "2 测试参数",
+		KWzStep3Label:         "3 确认保存",
+		KWzStep1Desc:          "配置任务名称、模型协议和连接信息。",
+		KWzStep2Desc:          "选择压测模式，并补全并发与 Prompt 参数。",
+		KWzStep3Desc:          "保存前快速检查关键配置。",
+		KWzUntitled:           "未命名任务",
+		KWzNotFilled:          "未填写",
+		KWzExecParams:         "执行参数",
+		KWzConcurrencyRamp:    "并发爬坡",
+		KWzStopCondition:      "停止条件",
+		KWzTimeoutLabel:       "超时",
+		KWzContentSummary:     "内容摘要",
+		KWzBodyBytes:          "Body 字节数",
+		KWzSaveLocation:       "保存位置",
+		KWzPromptSection:      "Prompt",
+		KWzHintDirect:         "直接粘贴或输入 Prompt 文本，所有请求共享同一段内容",
+		KWzHintFile:           "从文件读取 Prompt，支持通配符匹配多个文件（请求按文件轮换）",
+		KWzHintRaw:            "粘贴完整的 HTTP 请求 JSON Body，将跳过参数组装直接发送",
+		KWzHintCacheToken:     "提示：大多数服务需要 ≥ 1024 tokens 才能命中缓存",
+		KWzHintRawBody:        "提示：粘贴 API 请求的完整 JSON Body，将直接作为 HTTP 请求体发送",
+		KWzJSONBody:           "JSON Body",
+		KWzPromptLabelShort:   "Prompt",
+		KWzRAWBody:            "RAW 请求体",
+		KWzFileSummary:        "文件",
+		KWzGeneratedFmt:       "生成 %d 字符",
+		KWzPromptContent:      "内容",
+		KWzNoConfirmItems:     "暂无确认项",
+		KWzConfirmRange:       "确认项 %d-%d/%d",
+		KWzConfirmTotal:       "共 %d 项待确认",
+		KWzNoFields:           "暂无配置项",
+		KWzFieldProgress:      "当前字段 %d/%d",
 
 		// Misc
 		KEnabled:        "开启",
@@ -709,6 +920,194 @@ var translations = [2]map[Key]string{
 		KHoursAgoFmt:    "%d 小时前",
 		KDaysAgoFmt:     "%d 天前",
 		KToggleLang:     "切换语言",
+
+		// Baseline
+		KBaseline:             "基线",
+		KBaselineSaved:        "已保存为基线",
+		KBaselinePassed:       "无回归",
+		KBaselineRegressedFmt: "%d 项指标回退",
+
+		// Finish reason
+		KFinishReason: "结束原因",
+
+		// Steady TPS
+		KSteadyTPS:         "稳态",
+		KHelpTermSteadyTPS: "稳态 TPS",
+		KHelpDescSteadyTPS: "输出 token 数 ÷（总耗时 − TTFT），排除排队与 prefill，只衡量生成阶段的吞吐；仅流式请求可用。",
+
+		// Cached token ratio
+		KCachedTokenRatio:         "按 token",
+		KHelpTermCachedTokenRatio: "缓存 token 占比",
+		KHelpDescCachedTokenRatio: "成功请求中缓存命中的输入 token 总数 ÷ 输入 token 总数，反映 Prompt Cache 实际节省的输入量；Anthropic 的缓存写入 token 计入输入但不算命中。",
+
+		// Content check
+		KSuspiciousContent:    "内容检测",
+		KSuspiciousContentFmt: "%d 条疑似乱码/复读",
+
+		// Shard
+		KShardFmt: "分片 %s：本进程 %d",
+
+		// SLA
+		KSLA: "SLA",
+
+		// Prompt length distribution
+		KPromptLengthDistFmt: "长度分布 %s",
+
+		// Target IP stats
+		KTargetIPStatFmt:   "%s  %d 次 · 平均 %s · TTFT %s",
+		KTargetIPUnusedFmt: "%s  解析到但未连接",
+		KResolvedIPsFmt:    "%s（DNS 返回 %d 个 IP）",
+
+		// Tool calls
+		KAvgToolCalls: "平均工具调用",
+		KToolsFileFmt: "工具 %s",
+		KRedacted:     "日志 / 上报已脱敏",
+
+		// Connections
+		KPeakConnections:    "连接峰值",
+		KPeakConnectionsFmt: "%d / 并发 %d",
+		KQueueWait:          "本地排队",
+		KQueueWaitFmt:       "平均 %s · 最大 %s",
+		KQueueWaitHint:      "并发设置可能过高，本地排队明显",
+		KConnReuse:          "连接复用",
+		KConnReuseFmt:       "%d/%d 个请求（%.1f%%）",
+
+		// CLI result output
+		KExplainTitle:                      "指标说明",
+		KExplainModel:                      "模型名称",
+		KExplainStreamMode:                 "请求模式：stream 为流式，non-stream 为非流式",
+		KExplainConcurrency:                "并发数",
+		KExplainTotalRequests:              "总请求数",
+		KExplainSuccessRate:                "成功率（%）",
+		KExplainAvgTotalTime:               "平均总耗时（毫秒）：从发出请求到收到完整响应",
+		KExplainAvgTTFT:                    "平均首 token 耗时（毫秒），仅流式请求有值",
+		KExplainAvgTPOT:                    "平均每个输出 token 的耗时（毫秒），仅流式请求有值",
+		KExplainAvgTPS:                     "平均输出速率（token/秒）",
+		KExplainAvgSteadyTPS:               "平均稳态输出速率（token/秒）：不含首 token 等待，仅流式请求有值",
+		KExplainAvgThroughput:              "平均总吞吐（token/秒）：输入与输出 token 合计",
+		KExplainRPM:                        "每分钟完成的请求数",
+		KExplainTPM:                        "每分钟处理的 token 数",
+		KExplainTargetIP:                   "请求实际连接的目标 IP",
+		KExplainTargetIPRequests:           "命中该目标 IP 的请求数",
+		KCLISlowestTitleFmt:                "Top %d 最慢请求 · %s",
+		KCLITableFailedFmt:                 "输出结果表失败: %v",
+		KCLISelfMonitorFmt:                 "自监控 %s: goroutine %d → %d · 堆内存 %s → %s · GC %d · 采样 %d",
+		KCLISelfMonitorGoroutineGrowingFmt: "⚠ goroutine 数持续增长 (%d → %d)，ait 自身可能存在泄漏",
+		KCLISelfMonitorHeapGrowingFmt:      "⚠ 堆内存持续增长 (%s → %s)，ait 自身可能存在泄漏",
+		KCLITokenTraceWarnFmt:              "⚠ 已开启 --token-trace：流式请求的 chunk 时序写入 %s，会增加内存与磁盘占用（单请求最多 %d 个 chunk）",
+		KCLISlowestFailedFmt:               "输出最慢请求失败: %v",
+		KCLIRegressed:                      "检测到相对基线的性能回归",
+		KCLISLAFailed:                      "有运行未达到 SLA",
+		KCLIAbortedEarly:                   "有运行因错误率超过阈值提前终止",
+		KCLIMarkdownFailedFmt:              "写入 Markdown 结果失败: %v",
+		KCLIGHSummaryUnset:                 "未设置 GITHUB_STEP_SUMMARY 环境变量，跳过 --gh-summary",
+		KCLIHistoryFailedFmt:               "追加历史记录失败: %v",
+		KCLISQLiteFailedFmt:                "写入 SQLite 失败: %v",
+		KCLISQLiteWrittenFmt:               "已写入 %d 条结果到 %s",
+		KCLITelemetryNotice:                "ait 会匿名上报部分成功请求的性能指标（耗时、token 数、接口地址，不含 prompt、响应内容与密钥），可用 --no-upload 关闭",
+		KCLIUpdateAvailableFmt:             "发现新版本 %s（当前 %s），升级: https://github.com/yinxulai/ait/releases/latest",
+		KCLIFailedExportFmt:                "导出失败请求失败: %v",
+		KCLIFailedExportedFmt:              "已导出 %d 个失败请求到 %s",
+		KCLICurlExportFmt:                  "导出 curl 命令失败: %v",
+		KCLICurlExportedFmt:                "已导出 %d 条 curl 命令到 %s",
+		KCLIBundleExportFmt:                "导出 bundle 失败: %v",
+		KCLIBundleExportedFmt:              "已把 %d 次运行打包到 %s",
+		KCLIReportFailedFmt:                "生成报告失败: %v",
+		KCLIReportSavedFmt:                 "报告已保存到 %s",
+
+		// Request ID check
+		KRequestIDCheck:      "请求 ID",
+		KRequestIDCheckFmt:   "回传 %d · 不一致 %d",
+		KResponseEncoding:    "响应压缩",
+		KResponseEncodingFmt: "%s ×%d · 压缩率 %.0f%% · TTFT %s",
+		KSchemaCheck:         "Schema",
+		KSchemaCheckFmt:      "合规 %.1f%% · 合法 JSON %.1f%% · 共 %d",
+
+		// Normalized tokens
+		KNormalizedTPS:        "归一化",
+		KExplainNormalizedTPS: "归一化输出速率（token/秒）：用统一的本地分词器重新计数，仅开启 normalize_tokens 时有值",
+
+		// Interactive concurrency
+		KAdjustConcurrency:         "调并发",
+		KConcurrencyAdjustedFmt:    "并发已调整为 %d",
+		KHelpTermAdjustConcurrency: "+ / -",
+		KHelpDescAdjustConcurrency: "运行中把并发加 / 减 1；调低时正在执行的请求照常完成。",
+
+		// Pause / resume
+		KPauseResume:         "暂停/恢复",
+		KRunPaused:           "已暂停派发新请求，按 p 恢复",
+		KRunResumed:          "已恢复派发请求",
+		KPausedTag:           "已暂停",
+		KHelpTermPauseResume: "p",
+		KHelpDescPauseResume: "暂停 / 恢复派发新请求；在途请求照常完成，暂停时间不计入吞吐统计。",
+
+		// Burst
+		KBurst:    "突发",
+		KBurstFmt: "%d 批，批内 TTFT 均值最高 %s，最大 %s",
+
+		// Fallback model
+		KFallback:    "备用模型",
+		KFallbackFmt: "触发 %d 次，其中成功 %d 次",
+
+		// Network probe
+		KNetworkProbe:        "网络探测",
+		KNetworkProbeFmt:     "%d 次（失败 %d），最小 %s / 平均 %s / 最大 %s，请求建连均值 %s",
+		KProbeVerdict:        "结论",
+		KProbeVerdictServer:  "疑似服务端原因：网络探测稳定而 TTFT 变差",
+		KProbeVerdictNetwork: "疑似网络原因：网络探测与 TTFT 同步变差",
+
+		// Input length sweep
+		KLengthSweep:           "长度扫描",
+		KLengthSweepFmt:        "%d tok：平均输入 %d，TTFT %s，prefill %.0f tok/s",
+		KPromptFileScenario:    "场景",
+		KPromptFileScenarioFmt: "%s：成功率 %.1f%%，平均总耗时 %s，TPS %.1f",
+
+		// Phase analysis
+		KPhase:           "分段",
+		KPhaseWarmup:     "预热段",
+		KPhaseSteady:     "稳态段",
+		KPhaseCooldown:   "收尾段",
+		KPhaseFmt:        "%s %d 个，TTFT %s，TPS %.1f",
+		KPhaseSkewedHint: "稳态段与整体相差 %.0f%%，建议先预热或延长测试时间",
+
+		// Replay
+		KReplay:          "重放",
+		KReplayOfFmt:     "重放任务 %s 的失败请求",
+		KReplayOriginFmt: "原序号 #%d",
+
+		// Stream events
+		KBlockTiming: "内容块",
+
+		// Stream compare
+		KStreamCompare:      "流式对比",
+		KNonStream:          "非流式",
+		KHTTPVersionCompare: "HTTP 版本对比",
+		KHTTPProtocols:      "协商版本",
+
+		// Network errors
+		KNetErrors: "网络错误",
+
+		// Consistency check
+		KConsistency:    "一致率",
+		KConsistencyFmt: "%.1f%% · %d 个输出版本",
+
+		// DNS policy
+		KDNSPolicy: "解析",
+
+		// Empty content
+		KEmptyContent:         "空内容",
+		KEmptyContentFmt:      "%d 条成功但无正文 (%.1f%%)，可能被安全策略过滤",
+		KMaxTokensUsage:       "输出上限",
+		KMaxTokensUsageFmt:    "%.0f%% 请求被 max_tokens 截断 · 平均用量 %.0f%%",
+		KMaxTokensExceededFmt: "%d 条超出上限，服务可能忽略了 max_tokens",
+		KAbortedEarly:         "熔断",
+		KAbortedEarlyFmt:      "错误率 %.1f%% 超过阈值 %.0f%%（%d/%d 失败），已提前终止",
+
+		// First request
+		KFirstRequest:    "首请求",
+		KFirstRequestFmt: "#%d TTFT %s · 总耗时 %s",
+		KWarmRequestFmt:  "其余 %d 个 TTFT %s · 总耗时 %s",
+		KColdStartHint:   "首请求比其余请求慢 %.0f%%，冷启动明显",
 	},
 	EN: {
 		// Hotkeys
@@ -762,23 +1161,31 @@ var translations = [2]map[Key]string{
 		KHintNew:       "[a] New Task",
 
 		// Metric labels
-		KSuccessRate:  "Success Rate",
-		KAvgTPS:       "Avg TPS",
-		KAvgTTFT:      "Avg TTFT",
-		KCacheHit:     "Cache Hit",
-		KRPM:          "RPM",
-		KTPM:          "TPM",
-		KStatus:       "Status",
-		KTotalTime:    "Total Time",
-		KTTFT:         "TTFT",
-		KOutputTPS:    "Output TPS",
-		KToken:        "Token",
-		KCache:        "Cache",
-		KError:        "Error",
-		KDNS:          "DNS",
-		KTCPConnect:   "TCP Connect",
-		KTLSHandshake: "TLS Handshake",
-		KTargetIP:     "Target IP",
+		KSuccessRate:          "Success Rate",
+		KAvgTPS:               "Avg TPS",
+		KAvgTTFT:              "Avg TTFT",
+		KCacheHit:             "Cache Hit",
+		KRPM:                  "RPM",
+		KTPM:                  "TPM",
+		KInstantTPS:           "Live TPS",
+		KThinkingTime:         "Thinking",
+		KSelfStats:            "Self Stats",
+		KSelfStatsResidualFmt: "residual goroutines %d",
+		KSelfStatsLeak:        "possible leak",
+		KSelfStatsGrowing:     "kept growing",
+		KStatus:               "Status",
+		KTotalTime:            "Total Time",
+		KTTFT:                 "TTFT",
+		KOutputTPS:            "Output TPS",
+		KToken:                "Token",
+		KCache:                "Cache",
+		KError:                "Error",
+		KDNS:                  "DNS",
+		KTCPConnect:           "TCP Connect",
+		KTLSHandshake:         "TLS Handshake",
+		KTargetIP:             "Target IP",
+		KTraceID:              "Trace ID",
+		KRequestID:            "Request ID",
 
 		// Status values
 		KRunning:       "Running",
@@ -795,11 +1202,13 @@ var translations = [2]map[Key]string{
 		KRamp:             "Ramp",
 		KPerLevel:         "Per Level",
 		KStopCondLabel:    "Stop",
+		KKneeLabel:        "Knee",
+		KTurboKneeFmt:     "safe %d · err %s · P99 %s",
 		KTurboMode:        "Turbo Mode",
 		KStandardMode:     "Standard",
 		KTurboMonitor:     "Turbo Probe Monitor",
 		KTurboModeMeta:    "Turbo Mode",
-		KIntegrityMode:   "Integrity Mode",
+		KIntegrityMode:    "Integrity Mode",
 		KSuccessRateFmt:   "Success %.1f%%",
 		KTurboCurLevelFmt: "Current Level Metrics [Concurrency = %d]",
 		KTurboDashSuffix:  "  %d/%d  Level %d  Progress %s",
@@ -978,68 +1387,68 @@ var translations = [2]map[Key]string{
 		KHelpDescCSVReport:  "Summary data in tabular form, openable directly in spreadsheets. Reports are saved in the current working directory by default.",
 
 		// Wizard fields
-		KWzTaskName:         "Task Name",
-		KWzProtocol:         "Protocol",
-		KWzEndpoint:         "Endpoint URL",
-		KWzAPIKey:           "API Key",
-		KWzTestModel:        "Model",
-		KWzTestMode:         "Test Mode",
-		KWzTurboMode:        "Turbo Mode",
-		KWzStandardMode:     "Standard Mode",
-		KWzIntegrityMode:    "Integrity Mode",
-		KWzIntegritySuite:   "Test Suite",
-		KWzFailFast:        "Fail Fast",
-		KWzConcurrency:      "Concurrency",
-		KWzTotalRequests:    "Total Requests",
-		KWzTimeoutSecs:      "Timeout (s)",
-		KWzInitConc:         "Init Concurrency",
-		KWzMaxConc:          "Max Concurrency",
-		KWzStepSize:         "Step Size",
-		KWzLevelReqs:        "Requests/Level",
-		KWzMinSuccessRate:   "Min Success Rate",
-		KWzStreamMode:       "Stream Mode",
-		KWzInputMode:        "Input Mode",
-		KWzInputDirect:      "Direct Input",
-		KWzInputFile:        "File",
-		KWzInputGenerated:   "Generated",
-		KWzInputRaw:         "RAW Body",
-		KWzPromptConfig:     "Prompt Config",
-		KWzSelectModeHint:   "Select load test mode, then fill in concurrency and Prompt parameters.",
-		KWzTurboModeLabel:   "Turbo Mode",
+		KWzTaskName:           "Task Name",
+		KWzProtocol:           "Protocol",
+		KWzEndpoint:           "Endpoint URL",
+		KWzAPIKey:             "API Key",
+		KWzTestModel:          "Model",
+		KWzTestMode:           "Test Mode",
+		KWzTurboMode:          "Turbo Mode",
+		KWzStandardMode:       "Standard Mode",
+		KWzIntegrityMode:      "Integrity Mode",
+		KWzIntegritySuite:     "Test Suite",
+		KWzFailFast:           "Fail Fast",
+		KWzConcurrency:        "Concurrency",
+		KWzTotalRequests:      "Total Requests",
+		KWzTimeoutSecs:        "Timeout (s)",
+		KWzInitConc:           "Init Concurrency",
+		KWzMaxConc:            "Max Concurrency",
+		KWzStepSize:           "Step Size",
+		KWzLevelReqs:          "Requests/Level",
+		KWzMinSuccessRate:     "Min Success Rate",
+		KWzStreamMode:         "Stream Mode",
+		KWzInputMode:          "Input Mode",
+		KWzInputDirect:        "Direct Input",
+		KWzInputFile:          "File",
+		KWzInputGenerated:     "Generated",
+		KWzInputRaw:           "RAW Body",
+		KWzPromptConfig:       "Prompt Config",
+		KWzSelectModeHint:     "Select load test mode, then fill in concurrency and Prompt parameters.",
+		KWzTurboModeLabel:     "Turbo Mode",
 		KWzIntegrityModeLabel: "Integrity Mode",
-		KWzStepFmt:          "Step %d/3",
-		KWzStep1Label:       "1 Basic Info",
-		KWzStep2Label:       "2 Parameters",
-		KWzStep3Label:       "3 Confirm",
-		KWzStep1Desc:        "Configure task name, protocol, and connection info.",
-		KWzStep2Desc:        "Choose test mode and fill in concurrency and prompt parameters.",
-		KWzStep3Desc:        "Quick review before saving.",
-		KWzUntitled:         "Untitled Task",
-		KWzNotFilled:        "(empty)",
-		KWzExecParams:       "Execution Parameters",
-		KWzConcurrencyRamp:  "Concurrency Ramp",
-		KWzStopCondition:    "Stop Condition",
-		KWzTimeoutLabel:     "Timeout",
-		KWzContentSummary:   "Content Summary",
-		KWzBodyBytes:        "Body Bytes",
-		KWzSaveLocation:     "Save Location",
-		KWzPromptSection:    "Prompt",
-		KWzHintDirect:       "Paste or type Prompt text directly. All requests share the same content.",
-		KWzHintFile:         "Read Prompt from file(s). Supports glob patterns; requests rotate through matching files.",
-		KWzHintRaw:          "Paste a complete HTTP request JSON body. Parameter assembly is skipped and the body is sent as-is.",
-		KWzHintCacheToken:   "Tip: most services require ≥ 1024 tokens to trigger cache hits.",
-		KWzHintRawBody:      "Tip: paste the full JSON body of an API request. It will be sent directly as the HTTP request body.",
-		KWzJSONBody:         "JSON Body",
-		KWzPromptLabelShort: "Prompt",
-		KWzRAWBody:          "RAW Body",
-		KWzFileSummary:      "File",
-		KWzGeneratedFmt:     "%d chars",
-		KWzPromptContent:    "Content",
-		KWzNoConfirmItems:   "No confirm items",
-		KWzConfirmRange:     "Items %d-%d/%d",
-		KWzConfirmTotal:     "%d items to confirm",
-		KWzNoFields:         "No fields",
-		KWzFieldProgress:    "Field %d/%d",
+		KWzStepFmt:            "Step %d/3",
+		KWzStep1Label:         "1 Basic Info",
+		KWzStep2Label:         "2 Parameters",
+		KWzStep3Label:         "3 Confirm",
+		KWzStep1Desc:          "Configure task name, protocol, and connection info.",
+		KWzStep2Desc:          "Choose test mode and fill in concurrency and prompt parameters.",
+		KWzStep3Desc:          "Quick review before saving.",
+		KWzUntitled:           "Untitled Task",
+		KWzNotFilled:          "(empty)",
+		KWzExecParams:         "Execution Parameters",
+		KWzConcurrencyRamp:    "Concurrency Ramp",
+		KWzStopCondition:      "Stop Condition",
+		KWzTimeoutLabel:       "Timeout",
+		KWzContentSummary:     "Content Summary",
+		KWzBodyBytes:          "Body Bytes",
+		KWzSaveLocation:       "Save Location",
+		KWzPromptSection:      "Prompt",
+		KWzHintDirect:         "Paste or type Prompt text directly. All requests share the same content.",
+		KWzHintFile:           "Read Prompt from file(s). Supports glob patterns; requests rotate through matching files.",
+		KWzHintRaw:            "Paste a complete HTTP request JSON body. Parameter assembly is skipped and the body is sent as-is.",
+		KWzHintCacheToken:     "Tip: most services require ≥ 1024 tokens to trigger cache hits.",
+		KWzHintRawBody:        "Tip: paste the full JSON body of an API request. It will be sent directly as the HTTP request body.",
+		KWzJSONBody:           "JSON Body",
+		KWzPromptLabelShort:   "Prompt",
+		KWzRAWBody:            "RAW Body",
+		KWzFileSummary:        "File",
+		KWzGeneratedFmt:       "%d chars",
+		KWzPromptContent:      "Content",
+		KWzNoConfirmItems:     "No confirm items",
+		KWzConfirmRange:       "Items %d-%d/%d",
+		KWzConfirmTotal:       "%d items to confirm",
+		KWzNoFields:           "No fields",
+		KWzFieldProgress:      "Field %d/%d",
 
 		// Misc
 		KEnabled:        "On",
@@ -1051,6 +1460,194 @@ var translations = [2]map[Key]string{
 		KHoursAgoFmt:    "%d hr ago",
 		KDaysAgoFmt:     "%d days ago",
 		KToggleLang:     "Toggle Lang",
+
+		// Baseline
+		KBaseline:             "Baseline",
+		KBaselineSaved:        "saved as baseline",
+		KBaselinePassed:       "no regression",
+		KBaselineRegressedFmt: "%d metric(s) regressed",
+
+		// Finish reason
+		KFinishReason: "Finish",
+
+		// Steady TPS
+		KSteadyTPS:         "steady",
+		KHelpTermSteadyTPS: "Steady TPS",
+		KHelpDescSteadyTPS: "Output tokens ÷ (total time − TTFT): generation-phase throughput excluding queueing and prefill. Streaming only.",
+
+		// Cached token ratio
+		KCachedTokenRatio:         "by token",
+		KHelpTermCachedTokenRatio: "Cached Token Ratio",
+		KHelpDescCachedTokenRatio: "Cached input tokens ÷ total input tokens across successful requests: how much input the prompt cache actually saved. Anthropic cache-write tokens count as input but not as hits.",
+
+		// Content check
+		KSuspiciousContent:    "Content",
+		KSuspiciousContentFmt: "%d garbled/looping",
+
+		// Shard
+		KShardFmt: "shard %s: %d here",
+
+		// SLA
+		KSLA: "SLA",
+
+		// Prompt length distribution
+		KPromptLengthDistFmt: "length dist %s",
+
+		// Target IP stats
+		KTargetIPStatFmt:   "%s  %d reqs · avg %s · TTFT %s",
+		KTargetIPUnusedFmt: "%s  resolved, never connected",
+		KResolvedIPsFmt:    "%s (DNS returned %d IPs)",
+
+		// Tool calls
+		KAvgToolCalls: "Avg Tool Calls",
+		KToolsFileFmt: "tools %s",
+		KRedacted:     "redacted in logs / uploads",
+
+		// Connections
+		KPeakConnections:    "Peak Connections",
+		KPeakConnectionsFmt: "%d / concurrency %d",
+		KQueueWait:          "Local Queue",
+		KQueueWaitFmt:       "avg %s · max %s",
+		KQueueWaitHint:      "Concurrency may be set too high; noticeable local queueing",
+		KConnReuse:          "Conn Reuse",
+		KConnReuseFmt:       "%d/%d requests (%.1f%%)",
+
+		// CLI result output
+		KExplainTitle:                      "Metric notes",
+		KExplainModel:                      "Model name",
+		KExplainStreamMode:                 "Request mode: stream or non-stream",
+		KExplainConcurrency:                "Concurrency",
+		KExplainTotalRequests:              "Total requests",
+		KExplainSuccessRate:                "Success rate (%)",
+		KExplainAvgTotalTime:               "Average total time (ms), from sending the request to receiving the full response",
+		KExplainAvgTTFT:                    "Average time to first token (ms), streaming requests only",
+		KExplainAvgTPOT:                    "Average time per output token (ms), streaming requests only",
+		KExplainAvgTPS:                     "Average output rate (tokens/s)",
+		KExplainAvgSteadyTPS:               "Average steady output rate (tokens/s) excluding the first-token wait, streaming requests only",
+		KExplainAvgThroughput:              "Average total throughput (tokens/s), input and output tokens combined",
+		KExplainRPM:                        "Requests completed per minute",
+		KExplainTPM:                        "Tokens processed per minute",
+		KExplainTargetIP:                   "Target IP the request actually connected to",
+		KExplainTargetIPRequests:           "Requests that hit this target IP",
+		KCLISlowestTitleFmt:                "Top %d slowest requests · %s",
+		KCLITableFailedFmt:                 "Failed to print result table: %v",
+		KCLISelfMonitorFmt:                 "Self monitor %s: goroutines %d → %d · heap %s → %s · GC %d · samples %d",
+		KCLISelfMonitorGoroutineGrowingFmt: "⚠ goroutines kept growing (%d → %d), ait itself may be leaking",
+		KCLISelfMonitorHeapGrowingFmt:      "⚠ heap kept growing (%s → %s), ait itself may be leaking",
+		KCLITokenTraceWarnFmt:              "⚠ --token-trace enabled: chunk timings of streaming requests are written to %s, increasing memory and disk usage (up to %d chunks per request)",
+		KCLISlowestFailedFmt:               "Failed to print slowest requests: %v",
+		KCLIRegressed:                      "Performance regression against baseline detected",
+		KCLISLAFailed:                      "Some runs did not meet the SLA",
+		KCLIAbortedEarly:                   "Some runs were aborted early because the error rate exceeded the threshold",
+		KCLIMarkdownFailedFmt:              "Failed to write Markdown results: %v",
+		KCLIGHSummaryUnset:                 "GITHUB_STEP_SUMMARY is not set, skipping --gh-summary",
+		KCLIHistoryFailedFmt:               "Failed to append run history: %v",
+		KCLISQLiteFailedFmt:                "Failed to write SQLite: %v",
+		KCLISQLiteWrittenFmt:               "Wrote %d results to %s",
+		KCLITelemetryNotice:                "ait anonymously uploads performance metrics of sampled successful requests (timings, token counts, endpoint; no prompts, responses or API keys). Disable with --no-upload",
+		KCLIUpdateAvailableFmt:             "New version %s available (current %s), upgrade: https://github.com/yinxulai/ait/releases/latest",
+		KCLIFailedExportFmt:                "Failed to export failed requests: %v",
+		KCLIFailedExportedFmt:              "Exported %d failed requests to %s",
+		KCLICurlExportFmt:                  "Failed to export curl commands: %v",
+		KCLICurlExportedFmt:                "Exported %d curl commands to %s",
+		KCLIBundleExportFmt:                "Failed to export bundle: %v",
+		KCLIBundleExportedFmt:              "Bundled %d runs into %s",
+		KCLIReportFailedFmt:                "Failed to generate report: %v",
+		KCLIReportSavedFmt:                 "Report saved to %s",
+
+		// Request ID check
+		KRequestIDCheck:      "Request ID",
+		KRequestIDCheckFmt:   "%d echoed · %d mismatched",
+		KResponseEncoding:    "Encoding",
+		KResponseEncodingFmt: "%s ×%d · ratio %.0f%% · TTFT %s",
+		KSchemaCheck:         "Schema",
+		KSchemaCheckFmt:      "%.1f%% compliant · %.1f%% valid JSON · %d checked",
+
+		// Normalized tokens
+		KNormalizedTPS:        "normalized",
+		KExplainNormalizedTPS: "Output rate (tokens/s) recounted with one local tokenizer, only with normalize_tokens",
+
+		// Interactive concurrency
+		KAdjustConcurrency:         "Concurrency",
+		KConcurrencyAdjustedFmt:    "Concurrency set to %d",
+		KHelpTermAdjustConcurrency: "+ / -",
+		KHelpDescAdjustConcurrency: "Raise / lower concurrency by 1 while running; in-flight requests finish normally.",
+
+		// Pause / resume
+		KPauseResume:         "Pause/Resume",
+		KRunPaused:           "Dispatch paused, press p to resume",
+		KRunResumed:          "Dispatch resumed",
+		KPausedTag:           "PAUSED",
+		KHelpTermPauseResume: "p",
+		KHelpDescPauseResume: "Pause / resume dispatching new requests; in-flight requests finish, paused time is excluded from throughput.",
+
+		// Burst
+		KBurst:    "Bursts",
+		KBurstFmt: "%d bursts, worst avg TTFT %s, max %s",
+
+		// Fallback model
+		KFallback:    "Fallback",
+		KFallbackFmt: "used %d times, %d succeeded",
+
+		// Network probe
+		KNetworkProbe:        "Net probe",
+		KNetworkProbeFmt:     "%d samples (%d failed), min %s / avg %s / max %s, request connect avg %s",
+		KProbeVerdict:        "Verdict",
+		KProbeVerdictServer:  "Likely server-side: network probes stable while TTFT degraded",
+		KProbeVerdictNetwork: "Likely network: network probes degraded along with TTFT",
+
+		// Input length sweep
+		KLengthSweep:           "Len sweep",
+		KLengthSweepFmt:        "%d tok: avg input %d, TTFT %s, prefill %.0f tok/s",
+		KPromptFileScenario:    "Scenario",
+		KPromptFileScenarioFmt: "%s: success %.1f%%, avg total %s, TPS %.1f",
+
+		// Phase analysis
+		KPhase:           "Phases",
+		KPhaseWarmup:     "Warmup",
+		KPhaseSteady:     "Steady",
+		KPhaseCooldown:   "Cooldown",
+		KPhaseFmt:        "%s %d req, TTFT %s, TPS %.1f",
+		KPhaseSkewedHint: "Steady phase differs from overall by %.0f%%; warm up first or run longer",
+
+		// Replay
+		KReplay:          "Replay",
+		KReplayOfFmt:     "Replaying failed requests of task %s",
+		KReplayOriginFmt: "original #%d",
+
+		// Stream events
+		KBlockTiming: "Blocks",
+
+		// Stream compare
+		KStreamCompare:      "Stream A/B",
+		KNonStream:          "Non-stream",
+		KHTTPVersionCompare: "HTTP Version A/B",
+		KHTTPProtocols:      "Negotiated",
+
+		// Network errors
+		KNetErrors: "Net errors",
+
+		// Consistency check
+		KConsistency:    "Consistency",
+		KConsistencyFmt: "%.1f%% · %d distinct outputs",
+
+		// DNS policy
+		KDNSPolicy: "DNS",
+
+		// Empty content
+		KEmptyContent:         "Empty",
+		KEmptyContentFmt:      "%d succeeded with no content (%.1f%%), possibly filtered",
+		KMaxTokensUsage:       "Max Tokens",
+		KMaxTokensUsageFmt:    "%.0f%% of requests truncated by max_tokens · avg usage %.0f%%",
+		KMaxTokensExceededFmt: "%d exceeded the limit, max_tokens may be ignored",
+		KAbortedEarly:         "Aborted",
+		KAbortedEarlyFmt:      "error rate %.1f%% exceeded threshold %.0f%% (%d/%d failed), aborted early",
+
+		// First request
+		KFirstRequest:    "First req",
+		KFirstRequestFmt: "#%d TTFT %s · total %s",
+		KWarmRequestFmt:  "other %d TTFT %s · total %s",
+		KColdStartHint:   "First request is %.0f%% slower than the rest; noticeable cold start",
 	},
 }
 
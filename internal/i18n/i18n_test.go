@@ -0,0 +1,28 @@
+package i18n
+
+import "testing"
+
+// TestTranslationsComplete 新增的每个键都必须在中英文下都有译文，漏翻时在这里暴露。
+func TestTranslationsComplete(t *testing.T) {
+	names := map[Lang]string{ZH: "ZH", EN: "EN"}
+	for lang, name := range names {
+		for k := Key(0); k < keyCount; k++ {
+			if _, ok := translations[lang][k]; !ok {
+				t.Errorf("%s translation missing for key %d", name, k)
+			}
+		}
+	}
+}
+
+func TestTFollowsActiveLang(t *testing.T) {
+	defer SetLang(Active())
+
+	SetLang(ZH)
+	if got := T(KExplainTitle); got != "指标说明" {
+		t.Errorf("ZH T(KExplainTitle) = %q", got)
+	}
+	SetLang(EN)
+	if got := T(KExplainTitle); got != "Metric notes" {
+		t.Errorf("EN T(KExplainTitle) = %q", got)
+	}
+}
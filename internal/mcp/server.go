@@ -10,6 +10,7 @@ import (
 
 	mcpsdk "github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/yinxulai/ait/internal/server"
+	"github.com/yinxulai/ait/internal/server/prompt"
 	"github.com/yinxulai/ait/internal/server/types"
 )
 
@@ -73,20 +74,81 @@ func (s *Server) registerTools() {
 type listTasksArgs struct{}
 
 type createTaskArgs struct {
-	Name         string `json:"name" jsonschema:"task name"`
-	Protocol     string `json:"protocol" jsonschema:"request protocol: openai-completions, openai-responses, or anthropic-messages"`
-	EndpointURL  string `json:"endpoint_url,omitempty" jsonschema:"full endpoint URL"`
-	BaseURL      string `json:"base_url,omitempty" jsonschema:"base URL"`
-	APIKey       string `json:"api_key" jsonschema:"API key"`
-	Model        string `json:"model" jsonschema:"model name"`
-	Stream       *bool  `json:"stream,omitempty" jsonschema:"enable streaming"`
-	Concurrency  int    `json:"concurrency,omitempty" jsonschema:"request concurrency, minimum 1"`
-	Count        int    `json:"count,omitempty" jsonschema:"request count, minimum 1"`
-	TimeoutSec   int    `json:"timeout_sec,omitempty" jsonschema:"timeout in seconds, minimum 1"`
-	PromptMode   string `json:"prompt_mode,omitempty" jsonschema:"prompt mode: text, file, generated, or raw"`
-	PromptText   string `json:"prompt_text,omitempty" jsonschema:"prompt text"`
-	PromptFile   string `json:"prompt_file,omitempty" jsonschema:"prompt file path"`
-	PromptLength int    `json:"prompt_length,omitempty" jsonschema:"generated prompt length, minimum 1"`
+	Name               string `json:"name" jsonschema:"task name"`
+	Protocol           string `json:"protocol" jsonschema:"request protocol: openai-completions, openai-responses, anthropic-messages, or triton-grpc"`
+	EndpointURL        string `json:"endpoint_url,omitempty" jsonschema:"full endpoint URL"`
+	BaseURL            string `json:"base_url,omitempty" jsonschema:"base URL"`
+	APIKey             string `json:"api_key" jsonschema:"API key"`
+	Model              string `json:"model" jsonschema:"model name"`
+	ModelAlias         string `json:"model_alias,omitempty" jsonschema:"display name used in dashboards and reports; requests still use model"`
+	Stream             *bool  `json:"stream,omitempty" jsonschema:"enable streaming"`
+	CompareStream      bool   `json:"compare_stream,omitempty" jsonschema:"run the task twice, with stream=true and stream=false, and compare the results"`
+	CompareStreamSplit bool   `json:"compare_stream_split,omitempty" jsonschema:"split count between the two compare_stream phases instead of running the full count in each"`
+	ThinkingBudget     int    `json:"thinking_budget,omitempty" jsonschema:"thinking budget in tokens; enables thinking mode when greater than 0"`
+	GRPCMethod         string `json:"grpc_method,omitempty" jsonschema:"full gRPC method for triton-grpc, defaults to /inference.GRPCInferenceService/ModelStreamInfer"`
+	SelfStats          bool   `json:"self_stats,omitempty" jsonschema:"sample ait's own goroutines, memory and GC during the run to detect client-side bottlenecks"`
+	CSVHeaderComment   bool   `json:"csv_header_comment,omitempty" jsonschema:"prepend a # metadata line (version, task id, generation time) to CSV reports"`
+	CSVLegacyFormat    bool   `json:"csv_legacy_format,omitempty" jsonschema:"write CSV reports in the legacy format with duration strings and unitless column names"`
+	Adaptive           bool   `json:"adaptive,omitempty" jsonschema:"adapt the send rate on 429 responses: honor Retry-After, back off exponentially, retry the request and recover the rate gradually"`
+	Concurrency        int    `json:"concurrency,omitempty" jsonschema:"request concurrency, minimum 1"`
+	Count              int    `json:"count,omitempty" jsonschema:"request count, minimum 1"`
+	TimeoutSec         int    `json:"timeout_sec,omitempty" jsonschema:"timeout in seconds, minimum 1"`
+	PromptMode         string `json:"prompt_mode,omitempty" jsonschema:"prompt mode: text, file, generated, or raw"`
+	PromptText         string `json:"prompt_text,omitempty" jsonschema:"prompt text; {{random}}, {{uuid}} and {{index}} placeholders are expanded per request to avoid prompt cache hits"`
+	PromptFile         string `json:"prompt_file,omitempty" jsonschema:"prompt file path"`
+	PromptLength       int    `json:"prompt_length,omitempty" jsonschema:"generated prompt length, minimum 1"`
+
+	BaselineDir         string  `json:"baseline_dir,omitempty" jsonschema:"directory of per-task baselines; the first run saves its result as the baseline, later runs are compared against it and flag regressions"`
+	RegressionThreshold float64 `json:"regression_threshold,omitempty" jsonschema:"regression threshold in percent for baseline comparison, defaults to 10"`
+
+	TokenBudget int64 `json:"token_budget,omitempty" jsonschema:"total token budget (input+output); once used up no new requests are sent and the report covers the completed ones, 0 means unlimited"`
+
+	WebhookURL    string `json:"webhook_url,omitempty" jsonschema:"webhook URL to POST a summary (success rate, avg TTFT, avg TPS, top errors) to when the run finishes"`
+	WebhookFormat string `json:"webhook_format,omitempty" jsonschema:"webhook message format: generic, feishu, slack or wecom, defaults to generic"`
+
+	Endpoints        []string `json:"endpoints,omitempty" jsonschema:"multiple full endpoint URLs to spread requests across; overrides endpoint_url and the report is grouped by endpoint"`
+	EndpointStrategy string   `json:"endpoint_strategy,omitempty" jsonschema:"how to pick an endpoint per request: round-robin (default) or random"`
+
+	MaxTokens int `json:"max_tokens,omitempty" jsonschema:"output token limit per request (max_tokens, or max_output_tokens for the Responses API); 0 leaves it unset, anthropic-messages then defaults to 1024"`
+
+	AnthropicVersion string `json:"anthropic_version,omitempty" jsonschema:"anthropic-version header for anthropic-messages, defaults to 2023-06-01"`
+	AnthropicBeta    string `json:"anthropic_beta,omitempty" jsonschema:"anthropic-beta header for anthropic-messages, comma-separated beta features such as prompt-caching-2024-07-31"`
+
+	ContentCheck bool `json:"content_check,omitempty" jsonschema:"check successful responses for garbled text (invalid UTF-8, replacement characters, excessive whitespace) and looping output; the report counts suspicious responses"`
+
+	SLA []string `json:"sla,omitempty" jsonschema:"SLA expressions such as ttft<800ms,total<10s,p=95: at least p percent of requests (default 100) must meet every condition; the report gives the compliance rate of each"`
+
+	PromptLengthDist string `json:"prompt_length_dist,omitempty" jsonschema:"per-request prompt length distribution for generated prompts, length:weight pairs such as 100:0.8,4000:0.2; each request samples a target length and the report adds an input token histogram"`
+	PromptSeed       int64  `json:"prompt_seed,omitempty" jsonschema:"random seed for prompt_length_dist sampling; the same seed yields the same length sequence"`
+
+	ToolsFile string `json:"tools_file,omitempty" jsonschema:"path to a JSON file with an OpenAI tools array (openai-completions protocol only); the tools are sent with every request and responses with tool_calls but no content count as successful"`
+
+	ResponseSchema string `json:"response_schema,omitempty" jsonschema:"standard mode: path to a JSON schema file (draft-07 or 2020-12); every successful response body must be exactly one JSON value matching it, the report gives the valid JSON rate and compliance rate; openai-completions also sends response_format json_object"`
+
+	CompressRequest bool `json:"compress_request,omitempty" jsonschema:"gzip the request body and send Content-Encoding: gzip (HTTP protocols only); saves upload bandwidth for long prompts, but some services reject it"`
+
+	VerifyRequestID bool `json:"verify_request_id,omitempty" jsonschema:"standard mode, HTTP protocols only: send a unique X-Request-Id per request and count responses that echo back a different id as suspicious, to catch proxies mixing up responses"`
+
+	NormalizeTokens bool `json:"normalize_tokens,omitempty" jsonschema:"standard mode: recount output tokens of every response with one local tokenizer and report avg_normalized_tps next to the service-reported TPS, so services with different tokenizers can be compared fairly"`
+
+	BurstSize        int `json:"burst_size,omitempty" jsonschema:"standard mode: send requests in bursts of this many at once, ignoring concurrency; requires burst_interval_sec, cannot be combined with adaptive"`
+	BurstIntervalSec int `json:"burst_interval_sec,omitempty" jsonschema:"seconds between the starts of two bursts; the next burst also waits until the previous one has completed"`
+
+	FallbackModel string `json:"fallback_model,omitempty" jsonschema:"standard mode: when a request to model fails, retry it once right away with this model (same protocol, endpoint and key); the request counts as successful if either succeeds and the report counts how often the fallback was used"`
+
+	ProbeIntervalSec int `json:"probe_interval_sec,omitempty" jsonschema:"standard mode: every this many seconds time a bare TCP connect + TLS handshake to the endpoint (no API request, no concurrency slot) and report the probe latency next to request TTFT, to tell network jitter from a slower service"`
+
+	InputLengthSweep string `json:"input_length_sweep,omitempty" jsonschema:"standard mode with generated prompts and stream: comma-separated input lengths in tokens such as 1k,4k,16k,64k (k = 1024); runs count requests per length and reports TTFT and prefill TPS for each length"`
+
+	PhaseRatio float64 `json:"phase_ratio,omitempty" jsonschema:"standard mode: share of successful requests (by completion time) treated as the warmup phase and as the cooldown phase when comparing them with the steady phase in between, defaults to 0.1, must be below 0.5"`
+
+	ReplayFile string `json:"replay_file,omitempty" jsonschema:"standard mode: path to a failed-prompts JSONL written by ait --failed-output; runs each prompt in it once instead of the prompt settings (count is set to the number of lines) and marks the report as a replay of the original task"`
+
+	ConsistencyCheck bool `json:"consistency_check,omitempty" jsonschema:"standard mode: determinism check; every request sends the same prompt, non-streaming, with temperature=0, and the report counts distinct full outputs and the share of the most common one (consistency_rate), to catch gateways that change parameters or route to different replicas; not available for raw prompts, triton-grpc or thinking"`
+
+	PromptFilePerRun bool `json:"prompt_file_per_run,omitempty" jsonschema:"standard mode with prompt_mode=file: when prompt_file matches several files, run each file as its own scenario (named after the file without extension) for count requests in turn instead of sampling from all files, and report each scenario separately"`
+
+	ConnectRetries int `json:"connect_retries,omitempty" jsonschema:"standard mode: retry a request up to this many times when the connection cannot be established (DNS failure, connection refused, connect timeout); the request was never sent, and failures that remain are counted by network error kind in the report"`
 }
 
 type runTaskArgs struct {
@@ -181,7 +243,7 @@ func buildTaskConfig(args createTaskArgs) (server.TaskConfig, error) {
 	if strings.TrimSpace(model) == "" {
 		return server.TaskConfig{}, fmt.Errorf("model is required")
 	}
-	if strings.TrimSpace(apiKey) == "" {
+	if strings.TrimSpace(apiKey) == "" && types.NormalizeProtocol(protocol) != types.ProtocolTritonGRPC {
 		return server.TaskConfig{}, fmt.Errorf("api_key is required")
 	}
 
@@ -189,21 +251,83 @@ func buildTaskConfig(args createTaskArgs) (server.TaskConfig, error) {
 	if args.Stream != nil {
 		stream = *args.Stream
 	}
+	var lengthSweep []int
+	if strings.TrimSpace(args.InputLengthSweep) != "" {
+		lengths, err := prompt.ParseLengthSweep(args.InputLengthSweep)
+		if err != nil {
+			return server.TaskConfig{}, fmt.Errorf("input_length_sweep: %w", err)
+		}
+		lengthSweep = lengths
+	}
 
 	in := types.Input{
-		Protocol:     protocol,
-		EndpointURL:  args.EndpointURL,
-		BaseUrl:      args.BaseURL,
-		ApiKey:       apiKey,
-		Model:        model,
-		Stream:       stream,
-		Concurrency:  intOrDefault(args.Concurrency, 10),
-		Count:        intOrDefault(args.Count, 100),
-		PromptMode:   stringOrDefault(args.PromptMode, "generated"),
-		PromptText:   args.PromptText,
-		PromptFile:   args.PromptFile,
-		PromptLength: intOrDefault(args.PromptLength, 4096),
-		Timeout:      time.Duration(intOrDefault(args.TimeoutSec, 30)) * time.Second,
+		Protocol:           protocol,
+		EndpointURL:        args.EndpointURL,
+		BaseUrl:            args.BaseURL,
+		ApiKey:             apiKey,
+		Model:              model,
+		ModelAlias:         strings.TrimSpace(args.ModelAlias),
+		Stream:             stream,
+		CompareStream:      args.CompareStream,
+		CompareStreamSplit: args.CompareStreamSplit,
+		GRPCMethod:         strings.TrimSpace(args.GRPCMethod),
+		ThinkingBudget:     args.ThinkingBudget,
+		SelfStats:          args.SelfStats,
+		CSVHeaderComment:   args.CSVHeaderComment,
+		CSVLegacyFormat:    args.CSVLegacyFormat,
+		Adaptive:           args.Adaptive,
+		Concurrency:        intOrDefault(args.Concurrency, 10),
+		Count:              intOrDefault(args.Count, 100),
+		PromptMode:         stringOrDefault(args.PromptMode, "generated"),
+		PromptText:         args.PromptText,
+		PromptFile:         args.PromptFile,
+		PromptLength:       intOrDefault(args.PromptLength, 4096),
+		Timeout:            time.Duration(intOrDefault(args.TimeoutSec, 30)) * time.Second,
+
+		BaselineDir:         strings.TrimSpace(args.BaselineDir),
+		RegressionThreshold: args.RegressionThreshold,
+
+		TokenBudget: args.TokenBudget,
+
+		WebhookURL:    strings.TrimSpace(args.WebhookURL),
+		WebhookFormat: args.WebhookFormat,
+
+		Endpoints:        args.Endpoints,
+		EndpointStrategy: args.EndpointStrategy,
+
+		MaxTokens: args.MaxTokens,
+
+		AnthropicVersion: strings.TrimSpace(args.AnthropicVersion),
+		AnthropicBeta:    strings.TrimSpace(args.AnthropicBeta),
+
+		ContentCheck: args.ContentCheck,
+
+		SLA: args.SLA,
+
+		PromptLengthDist: strings.TrimSpace(args.PromptLengthDist),
+		PromptSeed:       args.PromptSeed,
+
+		ToolsFile:      strings.TrimSpace(args.ToolsFile),
+		ResponseSchema: strings.TrimSpace(args.ResponseSchema),
+
+		CompressRequest: args.CompressRequest,
+		VerifyRequestID: args.VerifyRequestID,
+		NormalizeTokens: args.NormalizeTokens,
+
+		BurstSize:     args.BurstSize,
+		BurstInterval: time.Duration(args.BurstIntervalSec) * time.Second,
+
+		FallbackModel: args.FallbackModel,
+		ProbeInterval: time.Duration(args.ProbeIntervalSec) * time.Second,
+
+		InputLengthSweep: lengthSweep,
+		PhaseRatio:       args.PhaseRatio,
+		ReplayFile:       strings.TrimSpace(args.ReplayFile),
+
+		ConnectRetries: args.ConnectRetries,
+
+		ConsistencyCheck: args.ConsistencyCheck,
+		PromptFilePerRun: args.PromptFilePerRun,
 	}
 	if in.PromptMode == "text" && strings.TrimSpace(in.PromptText) == "" {
 		in.PromptText = "你好，介绍一下你自己。"
@@ -0,0 +1,58 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/yinxulai/ait/internal/server/types"
+)
+
+// ErrAbortedOnErrorRate 错误率熔断触发时取消运行 context 的原因
+var ErrAbortedOnErrorRate = errors.New("错误率超过阈值，提前终止运行")
+
+// abortConfig 进程级的错误率熔断配置
+type abortConfig struct {
+	rate       float64
+	minSamples int
+}
+
+var processAbort atomic.Pointer[abortConfig]
+
+// SetAbortOnErrorRate 设置本进程标准运行的错误率熔断，通常在启动时由 --abort-on-error-rate / --abort-min-samples 设置；
+// rate 为百分比，0 表示关闭。任务自身设置了 abort_on_error_rate 时以任务为准。
+func SetAbortOnErrorRate(rate float64, minSamples int) {
+	if rate <= 0 {
+		processAbort.Store(nil)
+		return
+	}
+	processAbort.Store(&abortConfig{rate: rate, minSamples: minSamples})
+}
+
+// ParseErrorRate 解析 --abort-on-error-rate 的取值，如 50% 或 50（百分比），需在 (0, 100) 内；空字符串返回 0（关闭）。
+// 熔断条件是错误率严格超过阈值，100% 永远不会触发，因此不接受。
+func ParseErrorRate(s string) (float64, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, nil
+	}
+	rate, err := strconv.ParseFloat(strings.TrimSuffix(s, "%"), 64)
+	if err != nil || math.IsNaN(rate) || math.IsInf(rate, 0) {
+		return 0, fmt.Errorf("无法解析错误率 %q，应为百分比如 50%%", s)
+	}
+	if rate <= 0 || rate >= 100 {
+		return 0, fmt.Errorf("错误率必须在 (0, 100) 内（错误率需超过阈值才熔断，100%% 永远不会触发），当前为 %g", rate)
+	}
+	return rate, nil
+}
+
+// applyProcessAbort 任务未设置 abort_on_error_rate 时使用 --abort-on-error-rate。
+func applyProcessAbort(input *types.Input) {
+	if c := processAbort.Load(); c != nil && input.AbortOnErrorRate == 0 {
+		input.AbortOnErrorRate = c.rate
+		input.AbortMinSamples = c.minSamples
+	}
+}
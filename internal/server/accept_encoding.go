@@ -0,0 +1,40 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+
+	"github.com/yinxulai/ait/internal/server/types"
+)
+
+var processAcceptEncoding atomic.Value // string
+
+// ParseAcceptEncoding 规范化 Accept-Encoding 取值：identity、gzip 或 br，留空表示不指定（默认 gzip）。
+func ParseAcceptEncoding(s string) (string, error) {
+	switch v := strings.ToLower(strings.TrimSpace(s)); v {
+	case "", types.AcceptEncodingIdentity, types.AcceptEncodingGzip, types.AcceptEncodingBrotli:
+		return v, nil
+	default:
+		return "", fmt.Errorf("invalid accept encoding %q: want identity, gzip or br", s)
+	}
+}
+
+// SetAcceptEncoding 设置本进程被测请求默认的 Accept-Encoding，通常在启动时由 --accept-encoding 设置；
+// 任务自身设置了 accept_encoding 时以任务为准。
+func SetAcceptEncoding(encoding string) error {
+	v, err := ParseAcceptEncoding(encoding)
+	if err != nil {
+		return err
+	}
+	processAcceptEncoding.Store(v)
+	return nil
+}
+
+// applyProcessAcceptEncoding 任务未指定 accept_encoding 时使用 --accept-encoding；triton-grpc 不走 HTTP 响应体，不受影响。
+func applyProcessAcceptEncoding(input *types.Input) {
+	if v, _ := processAcceptEncoding.Load().(string); v != "" && input.AcceptEncoding == "" &&
+		input.NormalizedProtocol() != types.ProtocolTritonGRPC {
+		input.AcceptEncoding = v
+	}
+}
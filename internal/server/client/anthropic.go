@@ -1,7 +1,6 @@
 package client
 
 import (
-	"bufio"
 	"bytes"
 	"context"
 	"crypto/tls"
@@ -24,8 +23,10 @@ type AnthropicResponse struct {
 	Type    string `json:"type"`
 	Role    string `json:"role"`
 	Content []struct {
-		Type string `json:"type"`
-		Text string `json:"text"`
+		Type  string          `json:"type"`
+		Text  string          `json:"text"`
+		Name  string          `json:"name,omitempty"`  // tool_use 块的工具名
+		Input json.RawMessage `json:"input,omitempty"` // tool_use 块的参数
 	} `json:"content"`
 	Model string `json:"model"`
 	Usage struct {
@@ -34,6 +35,35 @@ type AnthropicResponse struct {
 		CacheReadInputTokens     int `json:"cache_read_input_tokens"`
 		OutputTokens             int `json:"output_tokens"`
 	} `json:"usage"`
+	StopReason string `json:"stop_reason"`
+}
+
+// text 拼接所有 text 内容块的文本，tool_use 块渲染为 name(input) 逐行附在其后
+func (r *AnthropicResponse) text() string {
+	var b strings.Builder
+	for _, content := range r.Content {
+		switch content.Type {
+		case "text":
+			b.WriteString(content.Text)
+		case "tool_use":
+			if b.Len() > 0 {
+				b.WriteString("\n")
+			}
+			b.WriteString(formatToolCall(content.Name, anthropicToolInput(content.Input)))
+		}
+	}
+	return b.String()
+}
+
+// toolCallCount 返回 tool_use 内容块的个数
+func (r *AnthropicResponse) toolCallCount() int {
+	n := 0
+	for _, content := range r.Content {
+		if content.Type == "tool_use" {
+			n++
+		}
+	}
+	return n
 }
 
 // AnthropicErrorResponse Anthropic API 错误响应结构
@@ -47,8 +77,15 @@ type AnthropicErrorResponse struct {
 
 // AnthropicStreamChunk Anthropic 流式响应数据块
 type AnthropicStreamChunk struct {
-	Type    string `json:"type"`
-	Index   int    `json:"index,omitempty"`
+	Type         string `json:"type"`
+	Index        int    `json:"index,omitempty"`
+	ContentBlock *struct {
+		Type     string          `json:"type"`
+		Text     string          `json:"text,omitempty"`     // text 块自带的初始文本
+		Thinking string          `json:"thinking,omitempty"` // thinking 块自带的初始思考内容
+		Name     string          `json:"name,omitempty"`     // tool_use 块的工具名
+		Input    json.RawMessage `json:"input,omitempty"`    // tool_use 块自带的参数，通常为 {}，随后由 input_json_delta 补全
+	} `json:"content_block,omitempty"` // content_block_start 事件携带
 	Message *struct {
 		Usage *struct {
 			InputTokens              int `json:"input_tokens"`
@@ -62,6 +99,7 @@ type AnthropicStreamChunk struct {
 		Text        string  `json:"text"`
 		Thinking    *string `json:"thinking,omitempty"`
 		PartialJSON *string `json:"partial_json,omitempty"`
+		StopReason  string  `json:"stop_reason,omitempty"` // message_delta 事件携带
 	} `json:"delta,omitempty"`
 	Usage *struct {
 		InputTokens              int `json:"input_tokens"`
@@ -71,6 +109,15 @@ type AnthropicStreamChunk struct {
 	} `json:"usage,omitempty"`
 }
 
+// defaultAnthropicThinkingBudget 未指定思考预算时的默认 budget_tokens（Anthropic 要求不小于 1024）
+const defaultAnthropicThinkingBudget = 1024
+
+// defaultAnthropicVersion 未指定时发送的 anthropic-version 请求头
+const defaultAnthropicVersion = "2023-06-01"
+
+// defaultAnthropicMaxTokens 未指定输出上限时的默认 max_tokens（Anthropic /v1/messages 要求必填）
+const defaultAnthropicMaxTokens = 1024
+
 func anthropicTextBlock(text string) map[string]interface{} {
 	return map[string]interface{}{
 		"type": "text",
@@ -110,6 +157,19 @@ type AnthropicClient struct {
 	Thinking    bool
 	httpClient  *http.Client
 	logger      *logger.Logger
+
+	ThinkingBudget int // 思考预算 token 数，0 表示使用默认值
+	MaxTokens      int // 输出 token 上限，0 表示使用默认值
+
+	Temperature *float64 // 采样温度，nil 表示不设置
+
+	Version string // anthropic-version 请求头
+	Beta    string // anthropic-beta 请求头，为空时不发送
+
+	CompressRequest bool // 是否 gzip 压缩请求体
+	VerifyRequestID bool // 是否注入 X-Request-Id 并校验响应回传
+
+	TokenTraceMaxChunks int // 流式响应记录 chunk 时序的上限，0 表示不记录
 }
 
 // NewAnthropicClient 根据配置创建 Anthropic 客户端
@@ -119,7 +179,8 @@ type AnthropicClient struct {
 //     这对于准确的性能测量至关重要，因为连接复用会跳过 DNS 解析和 TCP 连接建立时间，
 //     导致测量结果不能反映真实的网络性能。在性能基准测试工具中，我们需要测量完整的
 //     网络栈性能，包括 DNS 解析、TCP 连接建立、TLS 握手等。
-//   - DisableCompression=false: 启用压缩以节省带宽
+//   - DisableCompression=false: 启用压缩以节省带宽；设置了 accept_encoding 时由 withResponseEncoding
+//     显式设置 Accept-Encoding 并自行解压，以记录压缩前后的字节数
 func NewAnthropicClient(config types.Input) *AnthropicClient {
 	transport := newMeasuredTransport(config)
 
@@ -128,20 +189,46 @@ func NewAnthropicClient(config types.Input) *AnthropicClient {
 		ApiKey:      config.ApiKey,
 		Model:       config.Model,
 		Provider:    config.NormalizedProtocol(),
-		Thinking:    config.Thinking,
+		Thinking:    config.ThinkingEnabled(),
 		httpClient: &http.Client{
-			Transport: transport,
+			Transport: withRequestCompression(withResponseEncoding(transport, config.AcceptEncoding), config.CompressRequest),
 			Timeout:   config.Timeout,
 		},
 		logger: nil,
+
+		ThinkingBudget: config.ThinkingBudget,
+		MaxTokens:      config.MaxTokens,
+
+		Temperature: requestTemperature(config),
+
+		Version: anthropicVersion(config.AnthropicVersion),
+		Beta:    strings.TrimSpace(config.AnthropicBeta),
+
+		CompressRequest: config.CompressRequest,
+		VerifyRequestID: config.VerifyRequestID,
+
+		TokenTraceMaxChunks: config.TokenTraceMaxChunks,
 	}
 }
 
+// anthropicVersion 返回要发送的 anthropic-version，未配置时使用默认版本
+func anthropicVersion(version string) string {
+	if version = strings.TrimSpace(version); version != "" {
+		return version
+	}
+	return defaultAnthropicVersion
+}
+
 // SetLogger 设置日志记录器
 func (c *AnthropicClient) SetLogger(l *logger.Logger) {
 	c.logger = l
 }
 
+// CloseIdleConnections 关闭连接池中的空闲连接
+func (c *AnthropicClient) CloseIdleConnections() {
+	c.httpClient.CloseIdleConnections()
+}
+
 // Request 发送 Anthropic 协议请求（支持流式和非流式）
 func (c *AnthropicClient) Request(ctx context.Context, systemPrompt, userPrompt string, stream bool) (*ResponseMetrics, error) {
 	// 记录请求开始日志
@@ -153,6 +240,72 @@ func (c *AnthropicClient) Request(ctx context.Context, systemPrompt, userPrompt
 		})
 	}
 
+	reqBodyBytes, err := c.buildRequestBody(systemPrompt, userPrompt, stream)
+	if err != nil {
+		// 记录错误日志
+		if c.logger != nil && c.logger.IsEnabled() {
+			c.logger.Error(c.Model, "JSON encoding failed", err)
+		}
+		return &ResponseMetrics{
+			TimeToFirstToken: 0,
+			TotalTime:        0,
+			DNSTime:          0,
+			ConnectTime:      0,
+			TLSHandshakeTime: 0,
+			TargetIP:         "",
+			CompletionTokens: 0,
+			ErrorMessage:     fmt.Sprintf("JSON encoding error: %s", err.Error()),
+		}, err
+	}
+
+	return c.doRequest(ctx, reqBodyBytes, stream)
+}
+
+// buildRequestBody 构造 Messages API 请求体
+func anthropicThinkingBudget(budget int) int {
+	if budget <= 0 {
+		return defaultAnthropicThinkingBudget
+	}
+	return budget
+}
+
+// anthropicMaxTokens 返回实际发送的 max_tokens。max_tokens 为必填字段；开启 thinking 时还必须大于 budget_tokens，
+// 未指定时在预算之上留出默认的正文额度。
+func anthropicMaxTokens(maxTokens int, thinking bool, budget int) int {
+	if maxTokens > 0 {
+		return maxTokens
+	}
+	if thinking {
+		return anthropicThinkingBudget(budget) + defaultAnthropicMaxTokens
+	}
+	return defaultAnthropicMaxTokens
+}
+
+// ThinkingBudgetLimit 返回显式设置的 max_tokens 必须超过的思考预算：设置了 thinking_budget 时即为该值，
+// Anthropic 协议开启 thinking 但未设置预算时为实际发送的默认预算，其余情况返回 0（不限制）。
+func ThinkingBudgetLimit(input types.Input) int {
+	if input.ThinkingBudget > 0 {
+		return input.ThinkingBudget
+	}
+	if input.ThinkingEnabled() && input.NormalizedProtocol() == types.ProtocolAnthropicMessages {
+		return defaultAnthropicThinkingBudget
+	}
+	return 0
+}
+
+// OutputTokenLimit 返回按 input 发出的单次请求的输出 token 上限：设置了 max_tokens 时即为该值，
+// Anthropic 协议未设置时为实际发送的默认值，其余协议未设置时返回 0（不限制）。
+func OutputTokenLimit(input types.Input) int {
+	if input.MaxTokens > 0 {
+		return input.MaxTokens
+	}
+	if input.NormalizedProtocol() == types.ProtocolAnthropicMessages {
+		return anthropicMaxTokens(0, input.ThinkingEnabled(), input.ThinkingBudget)
+	}
+	return 0
+}
+
+func (c *AnthropicClient) buildRequestBody(systemPrompt, userPrompt string, stream bool) ([]byte, error) {
 	// 构造请求体结构，使用正确的 JSON 编码
 	requestBody := map[string]interface{}{
 		"model": c.Model,
@@ -175,33 +328,18 @@ func (c *AnthropicClient) Request(ctx context.Context, systemPrompt, userPrompt
 		}
 	}
 
-	// 如果启用了 thinking 模式，添加 thinking 配置
 	if c.Thinking {
 		requestBody["thinking"] = map[string]interface{}{
 			"type":          "enabled",
-			"budget_tokens": 1024,
+			"budget_tokens": anthropicThinkingBudget(c.ThinkingBudget),
 		}
 	}
-
-	reqBodyBytes, err := json.Marshal(requestBody)
-	if err != nil {
-		// 记录错误日志
-		if c.logger != nil && c.logger.IsEnabled() {
-			c.logger.Error(c.Model, "JSON encoding failed", err)
-		}
-		return &ResponseMetrics{
-			TimeToFirstToken: 0,
-			TotalTime:        0,
-			DNSTime:          0,
-			ConnectTime:      0,
-			TLSHandshakeTime: 0,
-			TargetIP:         "",
-			CompletionTokens: 0,
-			ErrorMessage:     fmt.Sprintf("JSON encoding error: %s", err.Error()),
-		}, err
+	requestBody["max_tokens"] = anthropicMaxTokens(c.MaxTokens, c.Thinking, c.ThinkingBudget)
+	if c.Temperature != nil {
+		requestBody["temperature"] = *c.Temperature
 	}
 
-	return c.doRequest(ctx, reqBodyBytes, stream)
+	return json.Marshal(requestBody)
 }
 
 // RawRequest 使用原始 JSON 请求体发送请求，stream 从请求体中的 stream 字段自动检测。
@@ -213,12 +351,52 @@ func (c *AnthropicClient) RawRequest(ctx context.Context, rawBody string) (*Resp
 	return c.doRequest(ctx, []byte(rawBody), tmp.Stream)
 }
 
+// BuildRequest 构造与 Request 相同的 HTTP 请求但不发送，返回请求与请求体。
+func (c *AnthropicClient) BuildRequest(ctx context.Context, systemPrompt, userPrompt string, stream bool) (*http.Request, []byte, error) {
+	reqBodyBytes, err := c.buildRequestBody(systemPrompt, userPrompt, stream)
+	if err != nil {
+		return nil, nil, err
+	}
+	req, err := c.newHTTPRequest(ctx, reqBodyBytes, newRequestTrace(c.VerifyRequestID))
+	return req, reqBodyBytes, err
+}
+
+// BuildRawRequest 以原始 JSON 请求体构造 HTTP 请求但不发送。
+func (c *AnthropicClient) BuildRawRequest(ctx context.Context, rawBody string) (*http.Request, []byte, error) {
+	req, err := c.newHTTPRequest(ctx, []byte(rawBody), newRequestTrace(c.VerifyRequestID))
+	return req, []byte(rawBody), err
+}
+
+// newHTTPRequest 构造发往端点的 POST 请求并设置鉴权、版本与 trace 请求头
+func (c *AnthropicClient) newHTTPRequest(ctx context.Context, reqBodyBytes []byte, rt *requestTrace) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(withRequestTrace(ctx, rt), "POST", c.EndpointURL, bytes.NewBuffer(reqBodyBytes))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("x-api-key", c.ApiKey)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("anthropic-version", anthropicVersion(c.Version))
+	if c.Beta != "" {
+		req.Header.Set("anthropic-beta", c.Beta)
+	}
+	rt.setHeaders(req.Header)
+	return req, nil
+}
+
 // doRequest 执行 HTTP 请求并解析响应（支持流式和非流式）
 func (c *AnthropicClient) doRequest(ctx context.Context, reqBodyBytes []byte, stream bool) (*ResponseMetrics, error) {
+	rt := newRequestTrace(c.VerifyRequestID)
+	metrics, err := c.send(ctx, reqBodyBytes, stream, rt)
+	rt.apply(metrics, c.logger, c.Model)
+	return metrics, err
+}
+
+// send 发送一次请求并收集指标，rt 记录本次请求的 trace id。
+func (c *AnthropicClient) send(ctx context.Context, reqBodyBytes []byte, stream bool, rt *requestTrace) (*ResponseMetrics, error) {
 	if ctx == nil {
 		ctx = context.Background()
 	}
-	req, err := http.NewRequestWithContext(ctx, "POST", c.EndpointURL, bytes.NewBuffer(reqBodyBytes))
+	req, err := c.newHTTPRequest(ctx, reqBodyBytes, rt)
 	if err != nil {
 		// 记录错误日志
 		if c.logger != nil && c.logger.IsEnabled() {
@@ -237,9 +415,6 @@ func (c *AnthropicClient) doRequest(ctx context.Context, reqBodyBytes []byte, st
 			ErrorMessage:     fmt.Sprintf("Request creation error: %s", err.Error()),
 		}, err
 	}
-	req.Header.Set("x-api-key", c.ApiKey)
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("anthropic-version", "2023-06-01")
 
 	// 记录请求日志
 	if c.logger != nil && c.logger.IsEnabled() {
@@ -264,13 +439,19 @@ func (c *AnthropicClient) doRequest(ctx context.Context, reqBodyBytes []byte, st
 	var dnsStart, connectStart, tlsStart time.Time
 	var dnsTime, connectTime, tlsTime time.Duration
 	var targetIP string
+	var resolvedIPs []string
 
+	var t0 time.Time
 	trace := &httptrace.ClientTrace{
+		GotFirstResponseByte: func() {
+			rt.firstByte = time.Since(t0)
+		},
 		DNSStart: func(info httptrace.DNSStartInfo) {
 			dnsStart = time.Now()
 		},
 		DNSDone: func(info httptrace.DNSDoneInfo) {
 			dnsTime = time.Since(dnsStart)
+			resolvedIPs = ipAddrStrings(info.Addrs)
 		},
 		ConnectStart: func(network, addr string) {
 			connectStart = time.Now()
@@ -296,7 +477,7 @@ func (c *AnthropicClient) doRequest(ctx context.Context, reqBodyBytes []byte, st
 
 	req = req.WithContext(httptrace.WithClientTrace(req.Context(), trace))
 
-	t0 := time.Now()
+	t0 = time.Now()
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
 		errorMessage := EnhanceErrorMessage(fmt.Sprintf("Network error: %s", err.Error()))
@@ -312,12 +493,15 @@ func (c *AnthropicClient) doRequest(ctx context.Context, reqBodyBytes []byte, st
 			ConnectTime:      connectTime,
 			TLSHandshakeTime: tlsTime,
 			TargetIP:         targetIP,
+			ResolvedIPs:      resolvedIPs,
 			CompletionTokens: 0,
 			RequestBody:      string(reqBodyBytes),
 			ErrorMessage:     errorMessage,
+			NetErrorKind:     ClassifyNetError(err),
 		}, err
 	}
 	defer resp.Body.Close()
+	rt.captureResponse(resp)
 
 	// 检查 HTTP 状态码
 	if resp.StatusCode != http.StatusOK {
@@ -339,16 +523,20 @@ func (c *AnthropicClient) doRequest(ctx context.Context, reqBodyBytes []byte, st
 			})
 		}
 
-		// 尝试解析 Anthropic API 的错误响应
+		// 尝试解析 Anthropic API 的错误响应；保留状态码与完整的 message，
+		// 便于区分缺字段（400 invalid_request_error）与鉴权失败（401）
 		var errorResp AnthropicErrorResponse
 		errorMessage := fmt.Sprintf("HTTP %d", resp.StatusCode)
 
 		if err := json.Unmarshal(responseData, &errorResp); err == nil && errorResp.Error.Message != "" {
 			// 成功解析错误响应，使用业务返回的详细错误信息
-			errorMessage = fmt.Sprintf("[%s] %s",
-				errorResp.Error.Type, errorResp.Error.Message)
+			errorMessage = fmt.Sprintf("HTTP %d [%s] %s",
+				resp.StatusCode, errorResp.Error.Type, errorResp.Error.Message)
+		} else if body := strings.TrimSpace(responseBody); body != "" {
+			// 兼容网关的非标准错误体，原样附上
+			errorMessage = fmt.Sprintf("HTTP %d: %s", resp.StatusCode, body)
 		}
-		errorMessage = EnhanceErrorMessage(errorMessage)
+		errorMessage = withCompressionHint(EnhanceErrorMessage(errorMessage), c.CompressRequest, resp.StatusCode)
 
 		return &ResponseMetrics{
 			TimeToFirstToken: 0,
@@ -357,25 +545,31 @@ func (c *AnthropicClient) doRequest(ctx context.Context, reqBodyBytes []byte, st
 			ConnectTime:      connectTime,
 			TLSHandshakeTime: tlsTime,
 			TargetIP:         targetIP,
+			ResolvedIPs:      resolvedIPs,
 			CompletionTokens: 0,
 			RequestBody:      string(reqBodyBytes),
 			ResponseBody:     responseBody,
 			ErrorMessage:     errorMessage,
+			StatusCode:       resp.StatusCode,
+			RetryAfter:       ParseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
 		}, fmt.Errorf("%s", errorMessage)
 	}
 
 	if stream {
 		// 流式响应处理
-		scanner := bufio.NewScanner(resp.Body)
 		firstTokenTime := time.Duration(0)
 		gotFirst := false
-		var fullContent strings.Builder
+		blocks := newAnthropicStreamState()
 		var outputTokens int
 		var inputTokens int
 		var cacheCreationInputTokens int
 		var cachedInputTokens int
-		var streamChunks []string // 用于记录所有流式数据块
-		var rawResponseLines strings.Builder
+		var finishReason string
+		streamLog := c.logger.NewStreamRecorder()    // 用于记录流式数据块，超过上限后截断
+		rawResponseLines := logger.NewBodyRecorder() // 原始响应体，受 --log-max-bytes 上限约束
+		var thinking thinkingTimer
+		events := newStreamEventTimer(t0)
+		chunkLog := newChunkRecorder(t0, c.TokenTraceMaxChunks)
 
 		// 记录流式响应开始日志
 		if c.logger != nil && c.logger.IsEnabled() {
@@ -390,96 +584,87 @@ func (c *AnthropicClient) doRequest(ctx context.Context, reqBodyBytes []byte, st
 			})
 		}
 
-		for scanner.Scan() {
-			line := scanner.Text()
-			rawResponseLines.WriteString(line)
-			rawResponseLines.WriteByte('\n')
-			if strings.HasPrefix(line, "data: ") {
-				data := strings.TrimPrefix(line, "data: ")
-				if strings.TrimSpace(data) == "" {
-					continue
-				}
+		err = ParseSSE(io.TeeReader(resp.Body, rawResponseLines), func(event, data string) error {
+			if strings.TrimSpace(data) == "" {
+				return nil
+			}
 
-				// 记录流数据块
-				if c.logger != nil && c.logger.IsEnabled() {
-					streamChunks = append(streamChunks, data)
-				}
+			// 记录流数据块
+			streamLog.Add(data)
 
-				var chunk AnthropicStreamChunk
-				if err := json.Unmarshal([]byte(data), &chunk); err != nil {
-					continue // 跳过无法解析的行
-				}
+			var chunk AnthropicStreamChunk
+			if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+				return nil // 跳过无法解析的行
+			}
+			events.observe(event, &chunk)
 
-				if chunk.Message != nil && chunk.Message.Usage != nil {
-					if chunk.Message.Usage.InputTokens > 0 {
-						inputTokens = chunk.Message.Usage.InputTokens
-					}
-					if chunk.Message.Usage.CacheCreationInputTokens > 0 {
-						cacheCreationInputTokens = chunk.Message.Usage.CacheCreationInputTokens
-					}
-					if chunk.Message.Usage.CacheReadInputTokens > 0 {
-						cachedInputTokens = chunk.Message.Usage.CacheReadInputTokens
-					}
-					if chunk.Message.Usage.OutputTokens > 0 {
-						outputTokens = chunk.Message.Usage.OutputTokens
-					}
+			if chunk.Message != nil && chunk.Message.Usage != nil {
+				if chunk.Message.Usage.InputTokens > 0 {
+					inputTokens = chunk.Message.Usage.InputTokens
 				}
-
-				if chunk.Type == "content_block_delta" {
-					// 检查是否有任何形式的内容输出（包括 Text、Thinking 或 PartialJSON）
-					hasContent := false
-					if chunk.Delta.Text != "" {
-						fullContent.WriteString(chunk.Delta.Text)
-						hasContent = true
-					}
-					if chunk.Delta.Thinking != nil && *chunk.Delta.Thinking != "" {
-						hasContent = true
-					}
-					if chunk.Delta.PartialJSON != nil && *chunk.Delta.PartialJSON != "" {
-						hasContent = true
-					}
-
-					// 如果有任何内容输出且这是第一次，记录 TTFT 时间
-					if hasContent && !gotFirst {
-						firstTokenTime = time.Since(t0)
-						gotFirst = true
-					}
+				if chunk.Message.Usage.CacheCreationInputTokens > 0 {
+					cacheCreationInputTokens = chunk.Message.Usage.CacheCreationInputTokens
 				}
+				if chunk.Message.Usage.CacheReadInputTokens > 0 {
+					cachedInputTokens = chunk.Message.Usage.CacheReadInputTokens
+				}
+				if chunk.Message.Usage.OutputTokens > 0 {
+					outputTokens = chunk.Message.Usage.OutputTokens
+				}
+			}
+
+			if chunk.Type == "message_delta" && chunk.Delta.StopReason != "" {
+				finishReason = chunk.Delta.StopReason
+			}
 
-				// 获取 token 统计信息
-				if chunk.Usage != nil {
-					if chunk.Usage.InputTokens > 0 {
-						inputTokens = chunk.Usage.InputTokens
-					}
-					if chunk.Usage.CacheCreationInputTokens > 0 {
-						cacheCreationInputTokens = chunk.Usage.CacheCreationInputTokens
-					}
-					if chunk.Usage.CacheReadInputTokens > 0 {
-						cachedInputTokens = chunk.Usage.CacheReadInputTokens
-					}
-					if chunk.Usage.OutputTokens > 0 {
-						outputTokens = chunk.Usage.OutputTokens
-					}
+			// content_block_start 自带的初始内容与各块的 delta 都计入输出（包括 text、thinking 与 tool_use 的 input），
+			// TTFT 取所有块中最早出现内容的时刻
+			hasThinking, hasContent := blocks.observe(&chunk)
+			if hasThinking || hasContent {
+				chunkLog.observe(anthropicChunkText(&chunk))
+				thinking.observe(hasThinking, hasContent)
+				if !gotFirst {
+					firstTokenTime = time.Since(t0)
+					gotFirst = true
 				}
 			}
-		}
 
-		if err := scanner.Err(); err != nil {
+			// 获取 token 统计信息
+			if chunk.Usage != nil {
+				if chunk.Usage.InputTokens > 0 {
+					inputTokens = chunk.Usage.InputTokens
+				}
+				if chunk.Usage.CacheCreationInputTokens > 0 {
+					cacheCreationInputTokens = chunk.Usage.CacheCreationInputTokens
+				}
+				if chunk.Usage.CacheReadInputTokens > 0 {
+					cachedInputTokens = chunk.Usage.CacheReadInputTokens
+				}
+				if chunk.Usage.OutputTokens > 0 {
+					outputTokens = chunk.Usage.OutputTokens
+				}
+			}
+			return nil
+		})
+		if err != nil {
 			// 记录扫描错误日志
 			if c.logger != nil && c.logger.IsEnabled() {
 				c.logger.Error(c.Model, "Stream scanning failed", err)
 			}
 			return nil, err
 		}
+		// 部分网关把最终 usage 放在 HTTP trailer 中，以 trailer 中的非零值为准
+		if u, ok := readTrailerUsage(resp); ok {
+			final := tokenUsage{Prompt: inputTokens, Completion: outputTokens, CachedInput: cachedInputTokens}
+			final.merge(u)
+			inputTokens, outputTokens, cachedInputTokens = final.Prompt, final.Completion, final.CachedInput
+		}
 
 		totalTime := time.Since(t0)
 
 		// 记录流式响应完成日志
 		if c.logger != nil && c.logger.IsEnabled() {
-			c.logger.LogResponse(c.Model, logger.ResponseData{
-				StatusCode:   resp.StatusCode,
-				StreamChunks: streamChunks,
-			})
+			c.logger.LogResponse(c.Model, streamLog.Response(resp.StatusCode))
 
 			c.logger.LogTestEnd(c.Model, map[string]interface{}{
 				"total_time":                  totalTime.String(),
@@ -488,25 +673,37 @@ func (c *AnthropicClient) doRequest(ctx context.Context, reqBodyBytes []byte, st
 				"cache_creation_input_tokens": cacheCreationInputTokens,
 				"cached_input_tokens":         cachedInputTokens,
 				"output_tokens":               outputTokens,
-				"full_content":                fullContent.String(),
+				"thinking_time":               thinking.Duration().String(),
+				"full_content":                blocks.text(),
 			})
 		}
 		promptTokens := anthropicTotalInputTokens(inputTokens, cacheCreationInputTokens, cachedInputTokens)
 
-		return &ResponseMetrics{
+		metrics := &ResponseMetrics{
 			TimeToFirstToken:  firstTokenTime,
 			TotalTime:         totalTime,
+			ThinkingTime:      thinking.Duration(),
 			DNSTime:           dnsTime,
 			ConnectTime:       connectTime,
 			TLSHandshakeTime:  tlsTime,
 			TargetIP:          targetIP,
+			ResolvedIPs:       resolvedIPs,
 			PromptTokens:      promptTokens,
 			CachedInputTokens: cachedInputTokens,
 			CompletionTokens:  outputTokens,
+			FinishReason:      finishReason,
 			RequestBody:       string(reqBodyBytes),
 			ResponseBody:      rawResponseLines.String(),
+			ResponseText:      blocks.text(),
 			ErrorMessage:      "",
-		}, nil
+		}
+		metrics.CacheCreationInputTokens = cacheCreationInputTokens
+		metrics.ToolCallCount = blocks.toolCallCount()
+		metrics.ContentBlockCount = blocks.blockCount()
+		metrics.EmptyContent = isEmptyContent(metrics.ResponseText)
+		metrics.EventTimes, metrics.ContentBlocks = events.first, events.blocks
+		chunkLog.apply(metrics)
+		return metrics, nil
 	} else {
 		// 非流式响应处理
 		responseData, err := io.ReadAll(resp.Body)
@@ -522,6 +719,7 @@ func (c *AnthropicClient) doRequest(ctx context.Context, reqBodyBytes []byte, st
 				ConnectTime:      connectTime,
 				TLSHandshakeTime: tlsTime,
 				TargetIP:         targetIP,
+				ResolvedIPs:      resolvedIPs,
 				CompletionTokens: 0,
 				ErrorMessage:     fmt.Sprintf("Response body read error: %s", err.Error()),
 			}, err
@@ -556,6 +754,7 @@ func (c *AnthropicClient) doRequest(ctx context.Context, reqBodyBytes []byte, st
 				ConnectTime:      connectTime,
 				TLSHandshakeTime: tlsTime,
 				TargetIP:         targetIP,
+				ResolvedIPs:      resolvedIPs,
 				CompletionTokens: 0,
 				ErrorMessage:     "Empty response body",
 			}, fmt.Errorf("empty response body")
@@ -574,6 +773,7 @@ func (c *AnthropicClient) doRequest(ctx context.Context, reqBodyBytes []byte, st
 				ConnectTime:      connectTime,
 				TLSHandshakeTime: tlsTime,
 				TargetIP:         targetIP,
+				ResolvedIPs:      resolvedIPs,
 				CompletionTokens: 0,
 				ErrorMessage:     fmt.Sprintf("JSON parsing error: %s", err.Error()),
 			}, err
@@ -581,10 +781,8 @@ func (c *AnthropicClient) doRequest(ctx context.Context, reqBodyBytes []byte, st
 
 		// 记录测试完成日志
 		if c.logger != nil && c.logger.IsEnabled() {
-			var contentText string
-			if len(anthropicResp.Content) > 0 {
-				contentText = anthropicResp.Content[0].Text
-			}
+			// 内容长度包括全部 text 与 tool_use 块，只有工具调用的响应不会记为 0
+			contentText := anthropicResp.text()
 
 			c.logger.LogTestEnd(c.Model, map[string]interface{}{
 				"total_time":                  totalTime.String(),
@@ -602,20 +800,28 @@ func (c *AnthropicClient) doRequest(ctx context.Context, reqBodyBytes []byte, st
 			anthropicResp.Usage.CacheReadInputTokens,
 		)
 
-		return &ResponseMetrics{
+		metrics := &ResponseMetrics{
 			TimeToFirstToken:  totalTime, // 非流式模式下，所有token一次性返回，TTFT等于总时间
 			TotalTime:         totalTime,
 			DNSTime:           dnsTime,
 			ConnectTime:       connectTime,
 			TLSHandshakeTime:  tlsTime,
 			TargetIP:          targetIP,
+			ResolvedIPs:       resolvedIPs,
 			PromptTokens:      promptTokens,
 			CachedInputTokens: anthropicResp.Usage.CacheReadInputTokens,
 			CompletionTokens:  anthropicResp.Usage.OutputTokens,
+			FinishReason:      anthropicResp.StopReason,
 			RequestBody:       string(reqBodyBytes),
 			ResponseBody:      string(responseData),
+			ResponseText:      anthropicResp.text(),
 			ErrorMessage:      "",
-		}, nil
+		}
+		metrics.CacheCreationInputTokens = anthropicResp.Usage.CacheCreationInputTokens
+		metrics.ToolCallCount = anthropicResp.toolCallCount()
+		metrics.ContentBlockCount = len(anthropicResp.Content)
+		metrics.EmptyContent = isEmptyContent(metrics.ResponseText)
+		return metrics, nil
 	}
 }
 
@@ -628,3 +834,43 @@ func (c *AnthropicClient) GetProtocol() string {
 func (c *AnthropicClient) GetModel() string {
 	return c.Model
 }
+
+// streamEventTimer 记录 Anthropic 流式响应中各 SSE event 类型首次出现的时间，以及每个 content block 的类型与起止时间。
+type streamEventTimer struct {
+	start  time.Time
+	first  map[string]time.Duration
+	blocks []types.ContentBlockTiming
+}
+
+func newStreamEventTimer(start time.Time) *streamEventTimer {
+	return &streamEventTimer{start: start, first: map[string]time.Duration{}}
+}
+
+// observe 在每个数据块解析后调用。event 为 SSE 的 event: 字段，缺省时以数据块的 type 代替。
+func (t *streamEventTimer) observe(event string, chunk *AnthropicStreamChunk) {
+	if event == "" {
+		event = chunk.Type
+	}
+	if event == "" {
+		return
+	}
+	elapsed := time.Since(t.start)
+	if _, ok := t.first[event]; !ok {
+		t.first[event] = elapsed
+	}
+	switch chunk.Type {
+	case "content_block_start":
+		block := types.ContentBlockTiming{Index: chunk.Index, Start: elapsed}
+		if chunk.ContentBlock != nil {
+			block.Type = chunk.ContentBlock.Type
+		}
+		t.blocks = append(t.blocks, block)
+	case "content_block_stop":
+		for i := len(t.blocks) - 1; i >= 0; i-- {
+			if t.blocks[i].Index == chunk.Index && t.blocks[i].Stop == 0 {
+				t.blocks[i].Stop = elapsed
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"strings"
+)
+
+// anthropicBlock Anthropic 流式响应中一个 content block 的累计内容。
+// 内容按 delta 类型分别累计，缺少 content_block_start 的非标准流也不会把思考内容或参数混进正文。
+type anthropicBlock struct {
+	kind         string          // text / thinking / redacted_thinking / tool_use 等，取自 content_block_start
+	name         string          // tool_use 的工具名
+	text         strings.Builder // 正文
+	thinking     strings.Builder // 思考内容
+	input        strings.Builder // tool_use 的 input JSON 片段
+	initialInput string          // content_block_start 中 tool_use 自带的 input，没有 input_json_delta 时使用
+}
+
+// anthropicStreamState 按 content block 的 index 累计 Anthropic 流式响应的内容：
+// content_block_start 建块并记录块自带的初始内容，content_block_delta 按 index 追加到对应块，
+// content_block_stop 结束该块。多个块（thinking + text、text + tool_use 等）交错到达时内容也不会串块。
+type anthropicStreamState struct {
+	blocks map[int]*anthropicBlock
+}
+
+func newAnthropicStreamState() *anthropicStreamState {
+	return &anthropicStreamState{blocks: map[int]*anthropicBlock{}}
+}
+
+// observe 处理一个 content block 相关的数据块，返回该数据块是否带来了思考内容、正文内容
+// （text、tool_use 块的开始或其 input），供 TTFT 与思考耗时判定；其余事件返回 false, false。
+func (s *anthropicStreamState) observe(chunk *AnthropicStreamChunk) (thinking, content bool) {
+	switch chunk.Type {
+	case "content_block_start":
+		b := s.block(chunk.Index)
+		if cb := chunk.ContentBlock; cb != nil {
+			b.kind, b.name = cb.Type, cb.Name
+			// tool_use 块开始时工具名已经生成，与 OpenAI 首个 tool_calls 增量的口径一致计入输出；
+			// 随后的首个 input_json_delta 通常是空串，等参数片段会推迟 TTFT
+			if cb.Type == "tool_use" {
+				content = true
+			}
+			if cb.Text != "" {
+				b.text.WriteString(cb.Text)
+				content = true
+			}
+			if cb.Thinking != "" {
+				b.thinking.WriteString(cb.Thinking)
+				thinking = true
+			}
+			if input := strings.TrimSpace(string(cb.Input)); input != "" && input != "{}" && input != "null" {
+				b.initialInput = input
+				content = true
+			}
+		}
+	case "content_block_delta":
+		b := s.block(chunk.Index)
+		if chunk.Delta.Text != "" {
+			b.text.WriteString(chunk.Delta.Text)
+			content = true
+		}
+		if chunk.Delta.Thinking != nil && *chunk.Delta.Thinking != "" {
+			b.thinking.WriteString(*chunk.Delta.Thinking)
+			thinking = true
+		}
+		if chunk.Delta.PartialJSON != nil && *chunk.Delta.PartialJSON != "" {
+			b.input.WriteString(*chunk.Delta.PartialJSON)
+			content = true
+		}
+	case "content_block_stop":
+		// 块的内容已由 start / delta 累计完毕；只有 stop 的空块同样计入块数
+		s.block(chunk.Index)
+	}
+	return thinking, content
+}
+
+// anthropicChunkText 返回一个 content_block_start / content_block_delta 事件携带的增量内容
+// （正文、思考与 tool_use 的 input），用于记录 chunk 时序。
+func anthropicChunkText(chunk *AnthropicStreamChunk) string {
+	var b strings.Builder
+	if cb := chunk.ContentBlock; chunk.Type == "content_block_start" && cb != nil {
+		b.WriteString(cb.Text)
+		b.WriteString(cb.Thinking)
+		if input := strings.TrimSpace(string(cb.Input)); input != "{}" && input != "null" {
+			b.WriteString(input)
+		}
+		return b.String()
+	}
+	b.WriteString(chunk.Delta.Text)
+	if chunk.Delta.Thinking != nil {
+		b.WriteString(*chunk.Delta.Thinking)
+	}
+	if chunk.Delta.PartialJSON != nil {
+		b.WriteString(*chunk.Delta.PartialJSON)
+	}
+	return b.String()
+}
+
+// block 返回 index 对应的块，没有 content_block_start 的块按需创建。
+func (s *anthropicStreamState) block(index int) *anthropicBlock {
+	b, ok := s.blocks[index]
+	if !ok {
+		b = &anthropicBlock{}
+		s.blocks[index] = b
+	}
+	return b
+}
+
+// indexes 返回按 index 排序的块序号。
+func (s *anthropicStreamState) indexes() []int {
+	indexes := make([]int, 0, len(s.blocks))
+	for index := range s.blocks {
+		indexes = append(indexes, index)
+	}
+	sort.Ints(indexes)
+	return indexes
+}
+
+// text 按 index 顺序拼接各块的正文，tool_use 块渲染为 name(input) 另起一行，与 OpenAI 工具调用的口径一致。
+func (s *anthropicStreamState) text() string {
+	var b strings.Builder
+	for _, index := range s.indexes() {
+		block := s.blocks[index]
+		b.WriteString(block.text.String())
+		if block.isToolUse() {
+			if b.Len() > 0 {
+				b.WriteString("\n")
+			}
+			b.WriteString(formatToolCall(block.name, block.toolInput()))
+		}
+	}
+	return b.String()
+}
+
+// isToolUse 返回块是否为工具调用：content_block_start 声明为 tool_use，或收到了 input_json_delta。
+func (b *anthropicBlock) isToolUse() bool {
+	return b.kind == "tool_use" || b.input.Len() > 0
+}
+
+// toolInput 返回 tool_use 块的完整 input JSON。
+func (b *anthropicBlock) toolInput() string {
+	if b.input.Len() > 0 {
+		return b.input.String()
+	}
+	if b.initialInput != "" {
+		return b.initialInput
+	}
+	return "{}"
+}
+
+// toolCallCount 返回 tool_use 块的个数。
+func (s *anthropicStreamState) toolCallCount() int {
+	n := 0
+	for _, block := range s.blocks {
+		if block.isToolUse() {
+			n++
+		}
+	}
+	return n
+}
+
+// blockCount 返回响应中 content block 的个数。
+func (s *anthropicStreamState) blockCount() int {
+	return len(s.blocks)
+}
+
+// anthropicToolInput 把非流式响应中 tool_use 块的 input 压缩为一行 JSON。
+func anthropicToolInput(raw json.RawMessage) string {
+	if len(raw) == 0 {
+		return "{}"
+	}
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, raw); err != nil {
+		return string(raw)
+	}
+	return compact.String()
+}
@@ -11,6 +11,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/yinxulai/ait/internal/server/logger"
 	"github.com/yinxulai/ait/internal/server/types"
 )
 
@@ -238,6 +239,11 @@ func TestAnthropicClient_Request_Stream(t *testing.T) {
 		t.Errorf("TTFT %v should be <= total elapsed time %v", metrics.TimeToFirstToken, elapsed)
 	}
 
+	// 首字节先于首个内容 token 到达
+	if metrics.TimeToFirstByte <= 0 || metrics.TimeToFirstByte > metrics.TimeToFirstToken {
+		t.Errorf("TTFB %v should be in (0, TTFT %v]", metrics.TimeToFirstByte, metrics.TimeToFirstToken)
+	}
+
 	if metrics.CompletionTokens != 15 {
 		t.Errorf("Request() CompletionTokens = %v, want 15", metrics.CompletionTokens)
 	}
@@ -302,6 +308,9 @@ func TestAnthropicClient_Request_PromptTokensIncludeCachedAndCreatedInput(t *tes
 		if metrics.CachedInputTokens != 900 {
 			t.Fatalf("CachedInputTokens = %d, want %d", metrics.CachedInputTokens, 900)
 		}
+		if metrics.CacheCreationInputTokens != 100 {
+			t.Fatalf("CacheCreationInputTokens = %d, want %d", metrics.CacheCreationInputTokens, 100)
+		}
 	})
 
 	t.Run("stream", func(t *testing.T) {
@@ -333,6 +342,9 @@ func TestAnthropicClient_Request_PromptTokensIncludeCachedAndCreatedInput(t *tes
 		if metrics.CachedInputTokens != 800 {
 			t.Fatalf("CachedInputTokens = %d, want %d", metrics.CachedInputTokens, 800)
 		}
+		if metrics.CacheCreationInputTokens != 160 {
+			t.Fatalf("CacheCreationInputTokens = %d, want %d", metrics.CacheCreationInputTokens, 160)
+		}
 		if metrics.CompletionTokens != 12 {
 			t.Fatalf("CompletionTokens = %d, want %d", metrics.CompletionTokens, 12)
 		}
@@ -999,6 +1011,43 @@ func TestAnthropicClient_Request_StreamWithThinking(t *testing.T) {
 	if metrics.CompletionTokens != 10 {
 		t.Errorf("Request() CompletionTokens = %v, want 10", metrics.CompletionTokens)
 	}
+
+	if metrics.ThinkingTime <= 0 || metrics.ThinkingTime >= metrics.TotalTime {
+		t.Errorf("Request() ThinkingTime = %v, want between 0 and TotalTime %v", metrics.ThinkingTime, metrics.TotalTime)
+	}
+}
+
+func TestAnthropicClient_Request_ThinkingBudget(t *testing.T) {
+	tests := []struct {
+		name   string
+		budget int
+		want   float64
+	}{
+		{name: "default budget", budget: 0, want: 1024},
+		{name: "custom budget", budget: 4096, want: 4096},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body map[string]interface{}
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_ = json.NewDecoder(r.Body).Decode(&body)
+				w.Header().Set("Content-Type", "application/json")
+				fmt.Fprint(w, `{"id":"msg","type":"message","role":"assistant","content":[{"type":"text","text":"ok"}],"usage":{"input_tokens":1,"output_tokens":1}}`)
+			}))
+			defer server.Close()
+
+			config := createTestConfig(server.URL, "test-key", "claude-3-7-sonnet", 5*time.Second, tt.budget == 0)
+			config.ThinkingBudget = tt.budget
+			if _, err := NewAnthropicClient(config).Request(context.Background(), "", "hi", false); err != nil {
+				t.Fatalf("Request() error = %v", err)
+			}
+
+			thinking, _ := body["thinking"].(map[string]interface{})
+			if thinking == nil || thinking["budget_tokens"] != tt.want {
+				t.Errorf("thinking = %v, want budget_tokens %v", body["thinking"], tt.want)
+			}
+		})
+	}
 }
 
 // TestAnthropicClient_Request_StreamWithPartialJSON 测试包含 PartialJSON 输出的 TTFT 计算
@@ -1415,3 +1464,396 @@ func TestAnthropicClientWithConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestAnthropicClient_Request_StopReason(t *testing.T) {
+	tests := []struct {
+		name   string
+		stream bool
+		body   string
+	}{
+		{
+			name: "non-stream",
+			body: `{"type":"message","content":[{"type":"text","text":"hi"}],"stop_reason":"max_tokens","usage":{"input_tokens":3,"output_tokens":2}}`,
+		},
+		{
+			name:   "stream",
+			stream: true,
+			body: "data: {\"type\":\"message_start\",\"message\":{\"usage\":{\"input_tokens\":3,\"output_tokens\":1}}}\n\n" +
+				"data: {\"type\":\"content_block_delta\",\"index\":0,\"delta\":{\"type\":\"text_delta\",\"text\":\"hi\"}}\n\n" +
+				"data: {\"type\":\"message_delta\",\"delta\":{\"stop_reason\":\"max_tokens\"},\"usage\":{\"output_tokens\":2}}\n\n" +
+				"data: {\"type\":\"message_stop\"}\n\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, tt.body)
+			}))
+			defer server.Close()
+
+			client := NewAnthropicClient(createTestConfig(server.URL, "test-key", "claude-sonnet-4", 30*time.Second, false))
+			metrics, err := client.Request(context.Background(), "", "hello", tt.stream)
+			if err != nil {
+				t.Fatalf("Request() unexpected error: %v", err)
+			}
+			if metrics.FinishReason != "max_tokens" {
+				t.Errorf("FinishReason = %q, want max_tokens", metrics.FinishReason)
+			}
+		})
+	}
+}
+
+func TestAnthropicClient_Request_MaxTokens(t *testing.T) {
+	tests := []struct {
+		name      string
+		maxTokens int
+		thinking  bool
+		budget    int
+		want      float64
+	}{
+		{"default", 0, false, 0, 1024},
+		{"configured", 4096, false, 0, 4096},
+		{"thinking default leaves room above budget", 0, true, 2000, 3024},
+		{"thinking default budget", 0, true, 0, 2048},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body map[string]any
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+					t.Errorf("decode request body: %v", err)
+				}
+				w.Header().Set("Content-Type", "application/json")
+				fmt.Fprint(w, `{"type":"message","content":[{"type":"text","text":"hi"}],"usage":{"input_tokens":3,"output_tokens":2}}`)
+			}))
+			defer server.Close()
+
+			config := createTestConfig(server.URL, "test-key", "claude-sonnet-4", 30*time.Second, tt.thinking)
+			config.MaxTokens = tt.maxTokens
+			config.ThinkingBudget = tt.budget
+			if _, err := NewAnthropicClient(config).Request(context.Background(), "", "hello", false); err != nil {
+				t.Fatalf("Request: %v", err)
+			}
+			if body["max_tokens"] != tt.want {
+				t.Errorf("max_tokens = %v, want %v", body["max_tokens"], tt.want)
+			}
+		})
+	}
+}
+
+func TestAnthropicClient_Request_InvalidRequestMessage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"type":"error","error":{"type":"invalid_request_error","message":"max_tokens: Field required"}}`)
+	}))
+	defer server.Close()
+
+	client := NewAnthropicClient(createTestConfig(server.URL, "test-key", "claude-sonnet-4", 30*time.Second, false))
+	metrics, err := client.Request(context.Background(), "", "hello", false)
+	if err == nil {
+		t.Fatal("expected error for HTTP 400")
+	}
+	if !strings.HasPrefix(metrics.ErrorMessage, "HTTP 400 [invalid_request_error] max_tokens: Field required") {
+		t.Errorf("ErrorMessage = %q", metrics.ErrorMessage)
+	}
+
+	gateway := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"code":40001,"msg":"missing max_tokens"}`)
+	}))
+	defer gateway.Close()
+
+	metrics, _ = NewAnthropicClient(createTestConfig(gateway.URL, "test-key", "claude-sonnet-4", 30*time.Second, false)).Request(context.Background(), "", "hello", false)
+	if !strings.Contains(metrics.ErrorMessage, `HTTP 400: {"code":40001,"msg":"missing max_tokens"}`) {
+		t.Errorf("non-standard error body should be kept, got %q", metrics.ErrorMessage)
+	}
+}
+
+func TestAnthropicClient_Request_VersionAndBetaHeaders(t *testing.T) {
+	var header http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Clone()
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"type":"message","content":[{"type":"text","text":"hi"}],"usage":{"input_tokens":3,"output_tokens":2}}`)
+	}))
+	defer server.Close()
+
+	config := createTestConfig(server.URL, "test-key", "claude-sonnet-4", 30*time.Second, false)
+	if _, err := NewAnthropicClient(config).Request(context.Background(), "", "hello", false); err != nil {
+		t.Fatalf("Request: %v", err)
+	}
+	if got := header.Get("anthropic-version"); got != "2023-06-01" {
+		t.Errorf("default anthropic-version = %q", got)
+	}
+	if _, ok := header["Anthropic-Beta"]; ok {
+		t.Errorf("anthropic-beta should not be sent by default: %v", header)
+	}
+
+	config.AnthropicVersion = "2024-10-22"
+	config.AnthropicBeta = " prompt-caching-2024-07-31,context-1m-2025-08-07 "
+	if _, err := NewAnthropicClient(config).Request(context.Background(), "", "hello", false); err != nil {
+		t.Fatalf("Request: %v", err)
+	}
+	if got := header.Get("anthropic-version"); got != "2024-10-22" {
+		t.Errorf("anthropic-version = %q, want override", got)
+	}
+	if got := header.Get("anthropic-beta"); got != "prompt-caching-2024-07-31,context-1m-2025-08-07" {
+		t.Errorf("anthropic-beta = %q", got)
+	}
+}
+
+func TestAnthropicClient_Request_StreamLogTruncated(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	logger.SetStreamLimits(0, 200)
+	defer logger.SetStreamLimits(logger.DefaultMaxStreamChunks, logger.DefaultMaxStreamBytes)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "data: {\"type\":\"message_start\",\"message\":{\"usage\":{\"input_tokens\":3}}}\n\n")
+		for i := 0; i < 50; i++ {
+			fmt.Fprintf(w, "data: {\"type\":\"content_block_delta\",\"delta\":{\"type\":\"text_delta\",\"text\":\"t%d\"}}\n\n", i)
+		}
+		fmt.Fprint(w, "data: {\"type\":\"message_delta\",\"usage\":{\"output_tokens\":50}}\n\n")
+	}))
+	defer server.Close()
+
+	l := logger.New(true)
+	defer l.Close()
+	c := NewAnthropicClient(createTestConfig(server.URL, "k", "m", 5*time.Second, false))
+	c.SetLogger(l)
+	if _, err := c.Request(context.Background(), "", "hi", true); err != nil {
+		t.Fatalf("Request error: %v", err)
+	}
+	resp := streamResponseLog(t, dir)
+	size := 0
+	for _, chunk := range resp.StreamChunks {
+		size += len(chunk)
+	}
+	if size > 200 || len(resp.StreamChunks) == 0 || !resp.StreamTruncated || resp.StreamDroppedChunks == 0 {
+		t.Errorf("logged %d chunks (%d bytes), truncated=%v dropped=%d", len(resp.StreamChunks), size, resp.StreamTruncated, resp.StreamDroppedChunks)
+	}
+}
+
+// TestAnthropicClient_Request_StreamEventTimes 测试流式响应中事件首次出现时间与 content block 起止时间的记录
+func TestAnthropicClient_Request_StreamEventTimes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		flusher, _ := w.(http.Flusher)
+		send := func(event, data string) {
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data)
+			flusher.Flush()
+		}
+
+		send("message_start", `{"type": "message_start", "message": {"usage": {"input_tokens": 10}}}`)
+		send("content_block_start", `{"type": "content_block_start", "index": 0, "content_block": {"type": "thinking"}}`)
+		send("content_block_delta", `{"type": "content_block_delta", "index": 0, "delta": {"type": "thinking_delta", "thinking": "hmm"}}`)
+		time.Sleep(10 * time.Millisecond)
+		send("content_block_stop", `{"type": "content_block_stop", "index": 0}`)
+		send("content_block_start", `{"type": "content_block_start", "index": 1, "content_block": {"type": "text"}}`)
+		send("content_block_delta", `{"type": "content_block_delta", "index": 1, "delta": {"type": "text_delta", "text": "Hi"}}`)
+		send("content_block_stop", `{"type": "content_block_stop", "index": 1}`)
+		send("message_delta", `{"type": "message_delta", "delta": {"stop_reason": "end_turn"}, "usage": {"output_tokens": 5}}`)
+		send("message_stop", `{"type": "message_stop"}`)
+	}))
+	defer server.Close()
+
+	client := NewAnthropicClient(createTestConfig(server.URL, "test-key", "claude-3-sonnet", 30*time.Second, false))
+	metrics, err := client.Request(context.Background(), "", "test prompt", true)
+	if err != nil {
+		t.Fatalf("Request() error = %v", err)
+	}
+
+	for _, event := range []string{"message_start", "content_block_start", "content_block_delta", "content_block_stop", "message_delta", "message_stop"} {
+		if _, ok := metrics.EventTimes[event]; !ok {
+			t.Errorf("EventTimes missing %q: %v", event, metrics.EventTimes)
+		}
+	}
+	if metrics.EventTimes["message_start"] > metrics.EventTimes["message_stop"] {
+		t.Errorf("message_start %v should not be after message_stop %v", metrics.EventTimes["message_start"], metrics.EventTimes["message_stop"])
+	}
+
+	if len(metrics.ContentBlocks) != 2 {
+		t.Fatalf("ContentBlocks = %+v, want 2 blocks", metrics.ContentBlocks)
+	}
+	thinking, text := metrics.ContentBlocks[0], metrics.ContentBlocks[1]
+	if thinking.Type != "thinking" || thinking.Index != 0 || text.Type != "text" || text.Index != 1 {
+		t.Errorf("ContentBlocks = %+v, want thinking#0 then text#1", metrics.ContentBlocks)
+	}
+	if thinking.Stop < thinking.Start+10*time.Millisecond {
+		t.Errorf("thinking block stop %v should be at least 10ms after start %v", thinking.Stop, thinking.Start)
+	}
+	if text.Start < thinking.Stop || text.Stop < text.Start {
+		t.Errorf("text block %v-%v should start after thinking stops at %v", text.Start, text.Stop, thinking.Stop)
+	}
+}
+
+func TestAnthropicClient_Request_StreamMultipleBlocks(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		flusher, _ := w.(http.Flusher)
+		send := func(event, data string) {
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data)
+			flusher.Flush()
+		}
+
+		send("message_start", `{"type": "message_start", "message": {"usage": {"input_tokens": 10}}}`)
+		send("content_block_start", `{"type": "content_block_start", "index": 0, "content_block": {"type": "thinking", "thinking": ""}}`)
+		send("content_block_delta", `{"type": "content_block_delta", "index": 0, "delta": {"type": "thinking_delta", "thinking": "need the weather"}}`)
+		send("content_block_delta", `{"type": "content_block_delta", "index": 0, "delta": {"type": "signature_delta", "signature": "abc"}}`)
+		send("content_block_stop", `{"type": "content_block_stop", "index": 0}`)
+		send("content_block_start", `{"type": "content_block_start", "index": 1, "content_block": {"type": "text", "text": "Let me "}}`)
+		send("content_block_delta", `{"type": "content_block_delta", "index": 1, "delta": {"type": "text_delta", "text": "check."}}`)
+		send("content_block_stop", `{"type": "content_block_stop", "index": 1}`)
+		send("content_block_start", `{"type": "content_block_start", "index": 2, "content_block": {"type": "tool_use", "id": "toolu_1", "name": "get_weather", "input": {}}}`)
+		send("content_block_delta", `{"type": "content_block_delta", "index": 2, "delta": {"type": "input_json_delta", "partial_json": "{\"city\": "}}`)
+		send("content_block_delta", `{"type": "content_block_delta", "index": 2, "delta": {"type": "input_json_delta", "partial_json": "\"上海\"}"}}`)
+		send("content_block_stop", `{"type": "content_block_stop", "index": 2}`)
+		send("message_delta", `{"type": "message_delta", "delta": {"stop_reason": "tool_use"}, "usage": {"output_tokens": 30}}`)
+		send("message_stop", `{"type": "message_stop"}`)
+	}))
+	defer server.Close()
+
+	client := NewAnthropicClient(createTestConfig(server.URL, "test-key", "claude-3-sonnet", 30*time.Second, false))
+	metrics, err := client.Request(context.Background(), "", "test prompt", true)
+	if err != nil {
+		t.Fatalf("Request() error = %v", err)
+	}
+	if want := "Let me check.\nget_weather({\"city\": \"上海\"})"; metrics.ResponseText != want {
+		t.Errorf("ResponseText = %q, want %q", metrics.ResponseText, want)
+	}
+	if metrics.ContentBlockCount != 3 || metrics.ToolCallCount != 1 {
+		t.Errorf("ContentBlockCount = %d, ToolCallCount = %d, want 3 and 1", metrics.ContentBlockCount, metrics.ToolCallCount)
+	}
+	if metrics.FinishReason != "tool_use" || metrics.EmptyContent {
+		t.Errorf("FinishReason = %q, EmptyContent = %v", metrics.FinishReason, metrics.EmptyContent)
+	}
+}
+
+func TestAnthropicClient_Request_StreamToolUseOnly(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "event: content_block_start\ndata: "+`{"type": "content_block_start", "index": 0, "content_block": {"type": "tool_use", "name": "get_time", "input": {}}}`+"\n\n")
+		fmt.Fprint(w, "event: content_block_delta\ndata: "+`{"type": "content_block_delta", "index": 0, "delta": {"type": "input_json_delta", "partial_json": "{}"}}`+"\n\n")
+		fmt.Fprint(w, "event: content_block_stop\ndata: "+`{"type": "content_block_stop", "index": 0}`+"\n\n")
+		fmt.Fprint(w, "event: message_delta\ndata: "+`{"type": "message_delta", "delta": {"stop_reason": "tool_use"}}`+"\n\n")
+	}))
+	defer server.Close()
+
+	client := NewAnthropicClient(createTestConfig(server.URL, "test-key", "claude-3-sonnet", 30*time.Second, false))
+	metrics, err := client.Request(context.Background(), "", "test prompt", true)
+	if err != nil {
+		t.Fatalf("Request() error = %v", err)
+	}
+	// 没有 usage 时工具调用仍算有效输出
+	if !metrics.HasOutput() || metrics.TimeToFirstToken <= 0 || metrics.ResponseText != "get_time({})" {
+		t.Errorf("HasOutput = %v, TTFT = %v, ResponseText = %q", metrics.HasOutput(), metrics.TimeToFirstToken, metrics.ResponseText)
+	}
+}
+
+func TestAnthropicClient_Request_StreamTTFTFromBlockStart(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		flusher, _ := w.(http.Flusher)
+		send := func(event, data string) {
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data)
+			flusher.Flush()
+		}
+
+		send("content_block_start", `{"type": "content_block_start", "index": 0, "content_block": {"type": "text", "text": "Hel"}}`)
+		time.Sleep(50 * time.Millisecond)
+		send("content_block_delta", `{"type": "content_block_delta", "index": 0, "delta": {"type": "text_delta", "text": "lo"}}`)
+		send("content_block_stop", `{"type": "content_block_stop", "index": 0}`)
+		send("message_delta", `{"type": "message_delta", "delta": {"stop_reason": "end_turn"}, "usage": {"output_tokens": 2}}`)
+	}))
+	defer server.Close()
+
+	client := NewAnthropicClient(createTestConfig(server.URL, "test-key", "claude-3-sonnet", 30*time.Second, false))
+	metrics, err := client.Request(context.Background(), "", "test prompt", true)
+	if err != nil {
+		t.Fatalf("Request() error = %v", err)
+	}
+	if metrics.ResponseText != "Hello" {
+		t.Errorf("ResponseText = %q, want Hello", metrics.ResponseText)
+	}
+	// 初始文本随 content_block_start 到达，TTFT 不应等到 50ms 后的第一个 delta
+	if metrics.TimeToFirstToken <= 0 || metrics.TimeToFirstToken >= 50*time.Millisecond || metrics.TotalTime < 50*time.Millisecond {
+		t.Errorf("TTFT = %v, TotalTime = %v, want TTFT before the 50ms delta", metrics.TimeToFirstToken, metrics.TotalTime)
+	}
+}
+
+func TestAnthropicClient_Request_StreamTTFTFromToolUseStart(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		flusher, _ := w.(http.Flusher)
+		send := func(event, data string) {
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data)
+			flusher.Flush()
+		}
+
+		// 与 Anthropic 实际下发的一致：tool_use 块开始后先是一个空的 input_json_delta，参数片段随后才到
+		send("content_block_start", `{"type": "content_block_start", "index": 0, "content_block": {"type": "tool_use", "id": "toolu_1", "name": "get_weather", "input": {}}}`)
+		send("content_block_delta", `{"type": "content_block_delta", "index": 0, "delta": {"type": "input_json_delta", "partial_json": ""}}`)
+		time.Sleep(50 * time.Millisecond)
+		send("content_block_delta", `{"type": "content_block_delta", "index": 0, "delta": {"type": "input_json_delta", "partial_json": "{\"city\": \"上海\"}"}}`)
+		send("content_block_stop", `{"type": "content_block_stop", "index": 0}`)
+		send("message_delta", `{"type": "message_delta", "delta": {"stop_reason": "tool_use"}, "usage": {"output_tokens": 12}}`)
+	}))
+	defer server.Close()
+
+	client := NewAnthropicClient(createTestConfig(server.URL, "test-key", "claude-3-sonnet", 30*time.Second, false))
+	metrics, err := client.Request(context.Background(), "", "test prompt", true)
+	if err != nil {
+		t.Fatalf("Request() error = %v", err)
+	}
+	if metrics.ResponseText != `get_weather({"city": "上海"})` || metrics.ToolCallCount != 1 || metrics.CompletionTokens != 12 {
+		t.Errorf("ResponseText = %q, ToolCallCount = %d, CompletionTokens = %d", metrics.ResponseText, metrics.ToolCallCount, metrics.CompletionTokens)
+	}
+	// 工具名随 content_block_start 到达，TTFT 不应等到 50ms 后的参数片段
+	if metrics.TimeToFirstToken <= 0 || metrics.TimeToFirstToken >= 50*time.Millisecond || metrics.TotalTime < 50*time.Millisecond {
+		t.Errorf("TTFT = %v, TotalTime = %v, want TTFT before the 50ms delta", metrics.TimeToFirstToken, metrics.TotalTime)
+	}
+}
+
+func TestAnthropicClient_Request_NonStreamToolUse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"type": "message", "content": [{"type": "text", "text": "Checking."}, {"type": "tool_use", "id": "toolu_1", "name": "get_weather", "input": {"city": "上海"}}], "stop_reason": "tool_use", "usage": {"input_tokens": 10, "output_tokens": 20}}`)
+	}))
+	defer server.Close()
+
+	client := NewAnthropicClient(createTestConfig(server.URL, "test-key", "claude-3-sonnet", 30*time.Second, false))
+	metrics, err := client.Request(context.Background(), "", "test prompt", false)
+	if err != nil {
+		t.Fatalf("Request() error = %v", err)
+	}
+	if want := "Checking.\nget_weather({\"city\":\"上海\"})"; metrics.ResponseText != want {
+		t.Errorf("ResponseText = %q, want %q", metrics.ResponseText, want)
+	}
+	if metrics.ContentBlockCount != 2 || metrics.ToolCallCount != 1 {
+		t.Errorf("ContentBlockCount = %d, ToolCallCount = %d, want 2 and 1", metrics.ContentBlockCount, metrics.ToolCallCount)
+	}
+}
+
+func TestAnthropicClient_Request_StreamUsageFromTrailer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Trailer", "X-Input-Tokens, X-Output-Tokens")
+		fmt.Fprint(w, "event: message_start\ndata: "+`{"type": "message_start", "message": {"usage": {"input_tokens": 0, "output_tokens": 0}}}`+"\n\n")
+		fmt.Fprint(w, "event: content_block_delta\ndata: "+`{"type": "content_block_delta", "index": 0, "delta": {"type": "text_delta", "text": "Hi"}}`+"\n\n")
+		fmt.Fprint(w, "event: message_delta\ndata: "+`{"type": "message_delta", "delta": {"stop_reason": "end_turn"}}`+"\n\n")
+		w.Header().Set("X-Input-Tokens", "15")
+		w.Header().Set("X-Output-Tokens", "4")
+	}))
+	defer server.Close()
+
+	client := NewAnthropicClient(createTestConfig(server.URL, "test-key", "claude-3-sonnet", 30*time.Second, false))
+	metrics, err := client.Request(context.Background(), "", "test prompt", true)
+	if err != nil {
+		t.Fatalf("Request() error = %v", err)
+	}
+	if metrics.PromptTokens != 15 || metrics.CompletionTokens != 4 {
+		t.Errorf("tokens = %d/%d, want 15/4 from trailer", metrics.PromptTokens, metrics.CompletionTokens)
+	}
+}
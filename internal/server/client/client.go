@@ -3,6 +3,9 @@ package client
 import (
 	"context"
 	"fmt"
+	"net"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/yinxulai/ait/internal/server/logger"
@@ -13,13 +16,18 @@ import (
 type ResponseMetrics struct {
 	// 时间相关指标
 	TimeToFirstToken time.Duration // 首个 token 的响应时间 (TTFT)
+	TimeToFirstByte  time.Duration // 首字节到达时间 (TTFB)：收到响应首字节（状态行 / 首个 gRPC 消息）的时间，未收到响应时为 0
 	TotalTime        time.Duration // 总耗时 (从请求开始到完全结束)
+	ThinkingTime     time.Duration // 思考阶段耗时 (首个思考块到首个正文块，仅流式)
 
 	// 网络连接指标
 	DNSTime          time.Duration // DNS解析时间
 	ConnectTime      time.Duration // TCP连接建立时间
 	TLSHandshakeTime time.Duration // TLS握手时间
 	TargetIP         string        // 目标服务器IP地址
+	ResolvedIPs      []string      // 本次请求 DNS 解析返回的全部 IP（按返回顺序），复用连接或 --resolve 固定地址时为空
+	Endpoint         string        // 多端点轮询时实际请求的端点
+	HTTPProto        string        // 实际协商到的 HTTP 协议版本（resp.Proto，如 HTTP/1.1、HTTP/2.0），未收到响应时为空
 
 	// 内容指标
 	PromptTokens      int // 输入 token 数量
@@ -27,12 +35,110 @@ type ResponseMetrics struct {
 	ThinkingTokens    int // 思考/推理 token 数量
 	CompletionTokens  int // 输出 token 数量 (用于TPS计算)
 
+	// FinishReason 生成结束原因，原样记录供应商返回值（OpenAI finish_reason / Anthropic stop_reason，
+	// Responses API 为 incomplete_details.reason 或 status），如 stop、length、end_turn、max_tokens
+	FinishReason string
+
+	// CacheCreationInputTokens 本次写入 prompt 缓存的输入 token 数（Anthropic cache_creation_input_tokens），
+	// 已计入 PromptTokens
+	CacheCreationInputTokens int
+
+	// ToolCallCount 响应中的工具调用次数（OpenAI tool_calls / Anthropic tool_use 块），只有配置了 tools 时才可能非零
+	ToolCallCount int
+
+	// ContentBlockCount Anthropic 响应中 content block 的个数（thinking、text、tool_use 等各算一块）
+	ContentBlockCount int
+
 	// 错误信息
-	ErrorMessage string // 错误信息（如果有）
+	ErrorMessage string        // 错误信息（如果有）
+	StatusCode   int           // 非 200 响应的 HTTP 状态码（gRPC 限流映射为 429）
+	RetryAfter   time.Duration // 响应头 Retry-After 建议的等待时长
+
+	// 链路追踪
+	ClientRequestID string // 本地 trace id，通过 X-Client-Request-Id 请求头发送
+	ServerRequestID string // 供应商返回的请求 ID（x-request-id / request-id / cf-ray 等响应头）
+
+	// 请求 ID 回传校验（开启 verify_request_id 时）：响应是否回传了 X-Request-Id，以及回传值是否与发送的不一致
+	RequestIDEchoed   bool
+	RequestIDMismatch bool
+
+	// RateLimit 响应头中的请求配额（x-ratelimit-remaining 等），未返回时为 nil
+	RateLimit *RateLimitInfo
+
+	// NetErrorKind 网络错误（未收到响应）的类别，取值见 NetErr* 常量；收到响应的请求为空
+	NetErrorKind string
+
+	// EmptyContent 请求成功（HTTP 200、usage 正常）但正文与工具调用均为空，常见于内容被安全策略过滤
+	EmptyContent bool
+
+	// FallbackUsed 主模型请求失败后改由备用模型（fallback_model）完成，指标为备用请求的结果
+	FallbackUsed bool
+
+	// AdaptedRequest 首次请求因参数不兼容（如 o 系列模型要求 max_completion_tokens）返回 400 后，改写请求体重试了一次，
+	// 指标为重试请求的结果；AdaptedParams 为改动说明，如 "max_tokens→max_completion_tokens, -temperature"
+	AdaptedRequest bool
+	AdaptedParams  string
+
+	// Anthropic 流式响应的事件时序：各 SSE event 类型首次出现的时间（相对请求开始），
+	// 以及每个 content block 的类型与起止时间，用于区分 thinking 块与正式回复块
+	EventTimes    map[string]time.Duration
+	ContentBlocks []types.ContentBlockTiming
+
+	// TokenChunks 开启 token 级时序（TokenTraceMaxChunks > 0）时各增量 chunk 的到达时刻与长度，
+	// 超过上限的 chunk 不再记录并置 TokenChunksTruncated
+	TokenChunks          []types.TokenChunk
+	TokenChunksTruncated bool
+
+	// 响应压缩（仅设置 accept_encoding 时记录）：Encoding 为响应的 Content-Encoding（未压缩为 identity），
+	// CompressedBytes / UncompressedBytes 为客户端读取的响应体解压前（线上）与解压后的字节数。
+	// 流式 SSE 的局限：两者只统计读到流结束为止的数据，中途取消或出错的请求偏小；压缩流要求服务端逐块 flush，
+	// 每块都带压缩帧开销，压缩率通常明显不如同样内容的非流式响应；网关的压缩层也可能缓冲小块，
+	// 这部分延迟会体现在 TTFT 上而无法单独拆出
+	Encoding          string
+	CompressedBytes   int64
+	UncompressedBytes int64
+
+	// CompletedAt 请求完成的时间，由调用方（runner / 请求执行器）在请求返回后记录，用于按时间分段分析
+	CompletedAt time.Time
+
+	// Index 请求的全局序号，StartedAt 请求发出的时间，同样由调用方记录，用于单独统计第 1 个完成的请求
+	Index     int
+	StartedAt time.Time
+
+	// QueueWaitTime 请求派发给执行 goroutine 到真正开始执行的等待时间（goroutine 调度、自适应限流等待等本地开销），
+	// 同样由调用方记录，不计入 TotalTime
+	QueueWaitTime time.Duration
+
+	// Prompt 本次实际发送的用户 prompt（raw 模式为原始请求体），由调用方在生成 prompt 后记录；
+	// 占位符展开、--prompt-command 等每次生成的内容不同，请求完成后不能再按序号还原
+	Prompt string
 
 	// 原始数据（供请求详情页展示和复制）
 	RequestBody  string // 发送给 API 的原始 JSON 请求体
 	ResponseBody string // API 返回的原始数据（非流式为 JSON，流式为所有 SSE 行拼接）
+	ResponseText string // 模型输出的正文文本（不含思考内容），供内容检测使用
+}
+
+// HasOutput 返回响应是否产生了有效输出：输出 token 数大于 0，或返回了工具调用
+// （工具调用响应没有正文，部分兼容服务也不返回 usage）。
+func (m *ResponseMetrics) HasOutput() bool {
+	return m.CompletionTokens > 0 || m.ToolCallCount > 0
+}
+
+// isEmptyContent 返回成功响应的正文是否为空（只有空白字符）。工具调用已计入正文，不算空内容。
+func isEmptyContent(text string) bool {
+	return strings.TrimSpace(text) == ""
+}
+
+// ipAddrStrings 把 DNS 解析结果转为去重后的 IP 字符串列表，保持返回顺序；没有结果时返回 nil。
+func ipAddrStrings(addrs []net.IPAddr) []string {
+	var ips []string
+	for _, addr := range addrs {
+		if ip := addr.String(); !slices.Contains(ips, ip) {
+			ips = append(ips, ip)
+		}
+	}
+	return ips
 }
 
 // ModelClient 定义统一的模型客户端接口
@@ -46,18 +152,83 @@ type ModelClient interface {
 	SetLogger(logger *logger.Logger) // 设置日志记录器
 }
 
+// IdleCloser 由持有 HTTP 连接池的客户端实现，运行结束后关闭空闲连接，避免多场景连跑时连接与其读写 goroutine 累积。
+type IdleCloser interface {
+	CloseIdleConnections()
+}
+
+// CloseIdleConnections 关闭 c 持有的空闲连接；c 没有连接池（如每个请求单独建连的 gRPC 客户端）时什么也不做。
+func CloseIdleConnections(c ModelClient) {
+	if closer, ok := c.(IdleCloser); ok {
+		closer.CloseIdleConnections()
+	}
+}
+
 // NewClient 根据配置创建客户端
 func NewClient(config types.Input, logger *logger.Logger) (ModelClient, error) {
+	if config.FallbackModel != "" {
+		return NewFallbackClient(config, logger)
+	}
+	if len(config.Endpoints) > 0 {
+		return NewMultiEndpointClient(config, logger)
+	}
 	switch config.NormalizedProtocol() {
 	case types.ProtocolOpenAICompletions, types.ProtocolOpenAIResponses:
 		client := NewOpenAIClient(config)
+		if config.ToolsFile != "" {
+			tools, err := LoadTools(config.ToolsFile)
+			if err != nil {
+				return nil, err
+			}
+			client.Tools = tools
+		}
 		client.SetLogger(logger)
 		return client, nil
 	case types.ProtocolAnthropicMessages:
 		client := NewAnthropicClient(config)
 		client.SetLogger(logger)
 		return client, nil
+	case types.ProtocolTritonGRPC:
+		client := NewGRPCClient(config)
+		client.SetLogger(logger)
+		return client, nil
 	default:
 		return nil, fmt.Errorf("不支持的 protocol 类型: %s", config.Protocol)
 	}
 }
+
+// requestTemperature 返回请求中设置的采样温度：确定性验证时固定为 0，其余情况不设置（nil）。
+func requestTemperature(config types.Input) *float64 {
+	if !config.ConsistencyCheck {
+		return nil
+	}
+	zero := 0.0
+	return &zero
+}
+
+// thinkingTimer 记录流式响应中思考阶段的持续时间：
+// 从首个思考/推理块开始，到首个正文块结束；没有思考块时为 0。
+type thinkingTimer struct {
+	start    time.Time
+	duration time.Duration
+	done     bool
+}
+
+// observe 在每个数据块到达时调用，thinking / content 表示该块是否包含思考或正文内容。
+func (t *thinkingTimer) observe(thinking, content bool) {
+	if t.done {
+		return
+	}
+	if thinking && t.start.IsZero() {
+		t.start = time.Now()
+	}
+	if content && !t.start.IsZero() {
+		t.duration = time.Since(t.start)
+		t.done = true
+	}
+}
+
+// Duration 返回思考阶段耗时。思考块之后始终没有正文时返回 0。
+func (t *thinkingTimer) Duration() time.Duration {
+	return t.duration
+}
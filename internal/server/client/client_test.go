@@ -1,6 +1,9 @@
 package client
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
@@ -67,6 +70,18 @@ func TestNewClient(t *testing.T) {
 			expectedProtocol: types.ProtocolOpenAICompletions,
 			expectedEndpoint: "https://api.openai.com/v1/chat/completions",
 		},
+		{
+			name: "triton grpc client strips scheme",
+			config: types.Input{
+				Protocol:    "grpc",
+				EndpointURL: "grpc://localhost:8001",
+				Model:       "vllm_model",
+				Timeout:     30 * time.Second,
+			},
+			wantError:        false,
+			expectedProtocol: types.ProtocolTritonGRPC,
+			expectedEndpoint: "localhost:8001",
+		},
 		{
 			name: "invalid provider",
 			config: types.Input{
@@ -118,6 +133,10 @@ func TestNewClient(t *testing.T) {
 				if typed.EndpointURL != tt.expectedEndpoint {
 					t.Errorf("NewClient() endpointURL = %v, want %v", typed.EndpointURL, tt.expectedEndpoint)
 				}
+			case *GRPCClient:
+				if typed.Endpoint != tt.expectedEndpoint {
+					t.Errorf("NewClient() endpoint = %v, want %v", typed.Endpoint, tt.expectedEndpoint)
+				}
 			}
 		})
 	}
@@ -198,3 +217,70 @@ func TestNewClientWithTimeout(t *testing.T) {
 		})
 	}
 }
+
+func TestThinkingTimer(t *testing.T) {
+	var timer thinkingTimer
+	timer.observe(false, true)
+	if timer.Duration() != 0 {
+		t.Fatalf("content without thinking should not start timer, got %v", timer.Duration())
+	}
+
+	timer = thinkingTimer{}
+	timer.observe(true, false)
+	time.Sleep(10 * time.Millisecond)
+	timer.observe(true, false)
+	timer.observe(false, true)
+	got := timer.Duration()
+	if got < 10*time.Millisecond {
+		t.Fatalf("Duration = %v, want >= 10ms", got)
+	}
+
+	// 首个正文块之后不再更新
+	time.Sleep(5 * time.Millisecond)
+	timer.observe(true, true)
+	if timer.Duration() != got {
+		t.Fatalf("Duration changed after first content: %v -> %v", got, timer.Duration())
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{"", 0},
+		{"3", 3 * time.Second},
+		{"0.5", 500 * time.Millisecond},
+		{"-1", 0},
+		{now.Add(10 * time.Second).Format(http.TimeFormat), 10 * time.Second},
+		{now.Add(-10 * time.Second).Format(http.TimeFormat), 0},
+		{"soon", 0},
+	}
+	for _, tt := range tests {
+		if got := ParseRetryAfter(tt.value, now); got != tt.want {
+			t.Errorf("ParseRetryAfter(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestOpenAIClient_Request_RateLimited(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "2")
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte(`{"error":{"type":"rate_limit_error","message":"slow down"}}`))
+	}))
+	defer server.Close()
+
+	c := NewOpenAIClient(types.Input{Protocol: types.ProtocolOpenAICompletions, EndpointURL: server.URL, ApiKey: "k", Model: "m"})
+	metrics, err := c.Request(context.Background(), "", "hi", false)
+	if err == nil {
+		t.Fatal("expected error for 429 response")
+	}
+	if metrics.StatusCode != http.StatusTooManyRequests || metrics.RetryAfter != 2*time.Second {
+		t.Errorf("StatusCode/RetryAfter = %d/%v, want 429/2s", metrics.StatusCode, metrics.RetryAfter)
+	}
+	if !metrics.Throttled() {
+		t.Error("Throttled() = false, want true")
+	}
+}
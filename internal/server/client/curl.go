@@ -0,0 +1,89 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/yinxulai/ait/internal/server/types"
+)
+
+// CurlInlineBodyLimit 请求体超过该字节数时不内联进命令行，改为落盘后以 --data-binary @file 引用
+const CurlInlineBodyLimit = 4096
+
+// APIKeyEnv 返回协议对应的密钥环境变量名，导出的 curl 命令以 $ENV 引用密钥而不写出明文。
+func APIKeyEnv(protocol string) string {
+	if protocol == types.ProtocolAnthropicMessages {
+		return "ANTHROPIC_API_KEY"
+	}
+	return "OPENAI_API_KEY"
+}
+
+// BuildCurlRequest 用 c 构造与运行时相同的请求但不发送，供导出 curl 命令；rawBody 非空时按原始请求体构造。
+// c 不支持只构造请求时返回错误。
+func BuildCurlRequest(ctx context.Context, c ModelClient, systemPrompt, userPrompt, rawBody string, stream bool) (*http.Request, []byte, error) {
+	builder, ok := c.(RequestBuilder)
+	if !ok {
+		return nil, nil, fmt.Errorf("%s 协议不支持导出 curl", c.GetProtocol())
+	}
+	if rawBody != "" {
+		return builder.BuildRawRequest(ctx, rawBody)
+	}
+	return builder.BuildRequest(ctx, systemPrompt, userPrompt, stream)
+}
+
+// CurlCommand 把请求格式化为等价的 curl 命令：请求头按名称排序，携带密钥的请求头以 "$apiKeyEnv" 引用环境变量
+// （保留 "Bearer " 这类认证方案前缀），其余参数按 shell 单引号转义。bodyFile 非空时请求体以 --data-binary @bodyFile
+// 引用，由调用方负责把 body 写入该文件；否则请求体内联在命令中。
+func CurlCommand(req *http.Request, body []byte, apiKeyEnv, bodyFile string) string {
+	// 每个选项与其参数占一行，行尾以 \ 续行
+	first := "curl"
+	if req.Method != http.MethodPost || len(body) == 0 {
+		first += " -X " + req.Method
+	}
+	args := []string{first + " " + ShellQuote(req.URL.String())}
+
+	names := make([]string, 0, len(req.Header))
+	for name := range req.Header {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		for _, value := range req.Header[name] {
+			if apiKeyEnv != "" && slices.Contains(sensitiveHeaders, http.CanonicalHeaderKey(name)) {
+				args = append(args, "-H "+curlSecretHeader(name, value, apiKeyEnv))
+				continue
+			}
+			args = append(args, "-H "+ShellQuote(name+": "+value))
+		}
+	}
+
+	switch {
+	case bodyFile != "":
+		args = append(args, "--data-binary "+ShellQuote("@"+bodyFile))
+	case len(body) > 0:
+		args = append(args, "--data-binary "+ShellQuote(string(body)))
+	}
+	return strings.Join(args, " \\\n  ")
+}
+
+// curlSecretHeader 把密钥请求头改写为双引号包裹的环境变量引用，如 "Authorization: Bearer $OPENAI_API_KEY"。
+// 双引号内只有请求头名与认证方案前缀，二者不含需要转义的字符时才能安全展开环境变量，否则退回单引号形式。
+func curlSecretHeader(name, value, env string) string {
+	scheme := ""
+	if prefix, _, ok := strings.Cut(value, " "); ok {
+		scheme = prefix + " "
+	}
+	header := name + ": " + scheme
+	if strings.ContainsAny(header, "\"\\$`!") {
+		return ShellQuote(header) + `"$` + env + `"`
+	}
+	return `"` + header + "$" + env + `"`
+}
+
+// ShellQuote 把 s 转义为 POSIX shell 的单引号字符串：其中的单引号改写为"结束引号、\'、重新开始引号"。
+func ShellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,102 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yinxulai/ait/internal/server/types"
+)
+
+func TestShellQuote(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"", "''"},
+		{"hello world", "'hello world'"},
+		{"it's", `'it'\''s'`},
+		{"''", `''\'''\'''`},
+		{`$HOME "x" \n`, `'$HOME "x" \n'`},
+	}
+	for _, tt := range tests {
+		if got := ShellQuote(tt.in); got != tt.want {
+			t.Errorf("ShellQuote(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
+
+// 转义结果交给真实的 shell 解析，应原样还原
+func TestShellQuote_RoundTripThroughShell(t *testing.T) {
+	sh, err := exec.LookPath("sh")
+	if err != nil {
+		t.Skip("sh not available")
+	}
+	for _, s := range []string{"it's", "a'b'c", `{"text":"don't $HOME \"q\" \\ ` + "`cmd`" + `"}`, "line1\nline2", "!bang"} {
+		out, err := exec.Command(sh, "-c", "printf '%s' "+ShellQuote(s)).Output()
+		if err != nil {
+			t.Fatalf("sh: %v", err)
+		}
+		if string(out) != s {
+			t.Errorf("round trip: got %q, want %q", out, s)
+		}
+	}
+}
+
+func TestCurlCommand_OpenAI(t *testing.T) {
+	c := NewOpenAIClient(createOpenAITestConfig("https://api.example.com", "sk-secret", "gpt-4o", time.Second, false))
+	req, body, err := BuildCurlRequest(context.Background(), c, "", "don't stop", "", true)
+	if err != nil {
+		t.Fatalf("BuildCurlRequest: %v", err)
+	}
+	cmd := CurlCommand(req, body, APIKeyEnv(types.ProtocolOpenAICompletions), "")
+
+	if strings.Contains(cmd, "sk-secret") {
+		t.Errorf("command leaks the API key:\n%s", cmd)
+	}
+	for _, want := range []string{
+		"curl 'https://api.example.com/v1/chat/completions'",
+		`-H "Authorization: Bearer $OPENAI_API_KEY"`,
+		`-H 'Content-Type: application/json'`,
+		`don'\''t stop`,
+		`"stream":true`,
+	} {
+		if !strings.Contains(cmd, want) {
+			t.Errorf("command missing %q:\n%s", want, cmd)
+		}
+	}
+	if strings.Contains(cmd, "-X") {
+		t.Errorf("POST with body should not need -X:\n%s", cmd)
+	}
+}
+
+func TestCurlCommand_AnthropicBodyFile(t *testing.T) {
+	c := NewAnthropicClient(createTestConfig("https://api.anthropic.com", "sk-ant", "claude", time.Second, false))
+	req, body, err := BuildCurlRequest(context.Background(), c, "", "hello", "", false)
+	if err != nil {
+		t.Fatalf("BuildCurlRequest: %v", err)
+	}
+	cmd := CurlCommand(req, body, APIKeyEnv(types.ProtocolAnthropicMessages), "/tmp/it's body.json")
+
+	if !strings.Contains(cmd, `-H "X-Api-Key: $ANTHROPIC_API_KEY"`) {
+		t.Errorf("x-api-key should reference the env var:\n%s", cmd)
+	}
+	if !strings.Contains(cmd, `--data-binary '@/tmp/it'\''s body.json'`) || strings.Contains(cmd, "hello") {
+		t.Errorf("body should be referenced from the file:\n%s", cmd)
+	}
+}
+
+func TestCurlCommand_MethodAndUnsafeScheme(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodGet, "https://example.com/v1/models", nil)
+	req.Header.Set("Authorization", "Token$x abc")
+	cmd := CurlCommand(req, nil, "OPENAI_API_KEY", "")
+	if !strings.Contains(cmd, "-X GET") {
+		t.Errorf("GET without body should set -X:\n%s", cmd)
+	}
+	// 认证方案前缀含 $ 时不能放进双引号
+	if !strings.Contains(cmd, `-H 'Authorization: Token$x '"$OPENAI_API_KEY"`) {
+		t.Errorf("unsafe scheme should be single-quoted:\n%s", cmd)
+	}
+}
@@ -0,0 +1,80 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// RequestBuilder 由可以只构造请求而不发送的 HTTP 客户端实现（OpenAI、Anthropic 及其组合客户端），
+// 用于 dry-run 预览与直接断言请求内容的单测。gRPC 客户端不实现。
+type RequestBuilder interface {
+	BuildRequest(ctx context.Context, systemPrompt, userPrompt string, stream bool) (*http.Request, []byte, error)
+	BuildRawRequest(ctx context.Context, rawBody string) (*http.Request, []byte, error)
+}
+
+// sensitiveHeaders 携带密钥的请求头，dry-run 输出时打码
+var sensitiveHeaders = []string{"Authorization", "X-Api-Key", "Api-Key"}
+
+// DryRunRequest 一次未发送请求的可读快照：请求头已打码，请求体已美化。
+type DryRunRequest struct {
+	Model   string
+	Method  string
+	URL     string
+	Headers [][2]string // 按名称排序
+	Body    string
+}
+
+// DryRun 用 c 构造一次请求但不发送；rawBody 非空时按原始请求体构造，否则由 prompt 构造。
+// c 不支持只构造请求时返回错误。
+func DryRun(ctx context.Context, c ModelClient, systemPrompt, userPrompt, rawBody string, stream bool) (*DryRunRequest, error) {
+	builder, ok := c.(RequestBuilder)
+	if !ok {
+		return nil, fmt.Errorf("%s 协议不支持 dry-run", c.GetProtocol())
+	}
+	var req *http.Request
+	var body []byte
+	var err error
+	if rawBody != "" {
+		req, body, err = builder.BuildRawRequest(ctx, rawBody)
+	} else {
+		req, body, err = builder.BuildRequest(ctx, systemPrompt, userPrompt, stream)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	out := &DryRunRequest{Model: c.GetModel(), Method: req.Method, URL: req.URL.String(), Body: string(body)}
+	for name, values := range req.Header {
+		value := strings.Join(values, ", ")
+		if slices.Contains(sensitiveHeaders, http.CanonicalHeaderKey(name)) {
+			value = maskHeaderValue(value)
+		}
+		out.Headers = append(out.Headers, [2]string{name, value})
+	}
+	slices.SortFunc(out.Headers, func(a, b [2]string) int { return strings.Compare(a[0], b[0]) })
+	var pretty bytes.Buffer
+	if json.Indent(&pretty, body, "", "  ") == nil {
+		out.Body = pretty.String()
+	}
+	return out, nil
+}
+
+// maskHeaderValue 只保留密钥首尾各 4 个字符，保留 "Bearer " 这类认证方案前缀
+func maskHeaderValue(value string) string {
+	scheme, secret, ok := strings.Cut(value, " ")
+	if !ok {
+		scheme, secret = "", value
+	} else {
+		scheme += " "
+	}
+	r := []rune(secret)
+	if len(r) <= 8 {
+		return scheme + strings.Repeat("*", len(r))
+	}
+	return scheme + string(r[:4]) + "****" + string(r[len(r)-4:])
+}
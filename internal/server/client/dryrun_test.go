@@ -0,0 +1,118 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yinxulai/ait/internal/server/types"
+)
+
+func TestOpenAIClient_BuildRequest(t *testing.T) {
+	c := NewOpenAIClient(createOpenAITestConfig("https://api.example.com", "sk-test", "gpt-4o", time.Second, false))
+	req, body, err := c.BuildRequest(context.Background(), "be brief", "hello", true)
+	if err != nil {
+		t.Fatalf("BuildRequest: %v", err)
+	}
+	if req.Method != "POST" || req.URL.String() != "https://api.example.com/v1/chat/completions" {
+		t.Errorf("request = %s %s", req.Method, req.URL)
+	}
+	if got := req.Header.Get("Authorization"); got != "Bearer sk-test" {
+		t.Errorf("Authorization = %q", got)
+	}
+	if req.Header.Get(HeaderClientRequestID) == "" {
+		t.Error("trace header missing")
+	}
+
+	var decoded ChatCompletionRequest
+	if err := json.Unmarshal(body, &decoded); err != nil {
+		t.Fatalf("body: %v", err)
+	}
+	if decoded.Model != "gpt-4o" || !decoded.Stream || decoded.StreamOptions == nil || len(decoded.Messages) != 2 || decoded.Messages[0].Role != "system" {
+		t.Errorf("body = %s", body)
+	}
+}
+
+func TestAnthropicClient_BuildRequest(t *testing.T) {
+	cfg := createTestConfig("https://api.anthropic.com", "sk-ant", "claude", time.Second, true)
+	cfg.AnthropicBeta = "beta-1"
+	req, body, err := NewAnthropicClient(cfg).BuildRequest(context.Background(), "", "hello", false)
+	if err != nil {
+		t.Fatalf("BuildRequest: %v", err)
+	}
+	if req.Header.Get("x-api-key") != "sk-ant" || req.Header.Get("anthropic-version") == "" || req.Header.Get("anthropic-beta") != "beta-1" {
+		t.Errorf("headers = %v", req.Header)
+	}
+
+	var decoded map[string]any
+	if err := json.Unmarshal(body, &decoded); err != nil {
+		t.Fatalf("body: %v", err)
+	}
+	if decoded["stream"] != false || decoded["thinking"] == nil || decoded["system"] != nil {
+		t.Errorf("body = %s", body)
+	}
+	// 开启 thinking 时 max_tokens 须大于思考预算
+	if maxTokens, _ := decoded["max_tokens"].(float64); maxTokens <= defaultAnthropicThinkingBudget {
+		t.Errorf("max_tokens = %v", decoded["max_tokens"])
+	}
+}
+
+func TestDryRun(t *testing.T) {
+	c, err := NewClient(types.Input{
+		Protocol:      types.ProtocolOpenAICompletions,
+		EndpointURL:   "https://a.example.com/v1/chat/completions",
+		Endpoints:     []string{"https://a.example.com/v1/chat/completions", "https://b.example.com/v1/chat/completions"},
+		ApiKey:        "sk-1234567890abcdef",
+		Model:         "gpt-4o",
+		FallbackModel: "gpt-4o-mini",
+	}, nil)
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	got, err := DryRun(context.Background(), c, "", "hello", "", false)
+	if err != nil {
+		t.Fatalf("DryRun: %v", err)
+	}
+	if got.Model != "gpt-4o" || got.URL != "https://a.example.com/v1/chat/completions" {
+		t.Errorf("dry run = %+v", got)
+	}
+	headers := map[string]string{}
+	for _, h := range got.Headers {
+		headers[h[0]] = h[1]
+	}
+	if headers["Authorization"] != "Bearer sk-1****cdef" {
+		t.Errorf("Authorization = %q, want masked key", headers["Authorization"])
+	}
+	if !strings.Contains(got.Body, "\n  \"model\": \"gpt-4o\"") {
+		t.Errorf("body should be indented, got %s", got.Body)
+	}
+
+	raw, err := DryRun(context.Background(), c, "", "", `{"model":"x","stream":true}`, false)
+	if err != nil || !strings.Contains(raw.Body, `"model": "x"`) {
+		t.Errorf("raw dry run = %+v, err = %v", raw, err)
+	}
+
+	grpc, err := NewClient(types.Input{Protocol: types.ProtocolTritonGRPC, EndpointURL: "localhost:8001", Model: "m"}, nil)
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	if _, err := DryRun(context.Background(), grpc, "", "hello", "", false); err == nil {
+		t.Error("gRPC clients should not support dry-run")
+	}
+}
+
+func TestMaskHeaderValue(t *testing.T) {
+	for in, want := range map[string]string{
+		"Bearer sk-1234567890abcdef": "Bearer sk-1****cdef",
+		"sk-ant-api03-abcdefgh":      "sk-a****efgh",
+		"Bearer short":               "Bearer *****",
+		"":                           "",
+	} {
+		if got := maskHeaderValue(in); got != want {
+			t.Errorf("maskHeaderValue(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -1,7 +1,10 @@
 package client
 
 import (
+	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/yinxulai/ait/internal/i18n"
 )
@@ -21,6 +24,30 @@ const (
 	ErrServerError
 )
 
+// String returns the report name of the error type.
+func (t ErrorType) String() string {
+	switch t {
+	case ErrAuth:
+		return "auth"
+	case ErrQuota:
+		return "quota"
+	case ErrRateLimit:
+		return "rate_limit"
+	case ErrTimeout:
+		return "timeout"
+	case ErrNetwork:
+		return "network"
+	case ErrInvalidRequest:
+		return "invalid_request"
+	case ErrModelNotFound:
+		return "model_not_found"
+	case ErrServerError:
+		return "server_error"
+	default:
+		return "unknown"
+	}
+}
+
 // ClassifyError classifies an error message and returns its type
 func ClassifyError(errMsg string) ErrorType {
 	errLower := strings.ToLower(errMsg)
@@ -139,6 +166,30 @@ func EnhanceErrorMessage(errMsg string) string {
 	return errMsg + "\nHint: " + hint
 }
 
+// ParseRetryAfter parses a Retry-After header value, which is either delay-seconds
+// or an HTTP-date. It returns 0 when the header is absent or invalid.
+func ParseRetryAfter(value string, now time.Time) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	if secs, err := strconv.ParseFloat(value, 64); err == nil {
+		if secs <= 0 {
+			return 0
+		}
+		return time.Duration(secs * float64(time.Second))
+	}
+	if at, err := http.ParseTime(value); err == nil && at.After(now) {
+		return at.Sub(now)
+	}
+	return 0
+}
+
+// Throttled reports whether the response was rejected by the provider's rate limiter.
+func (m *ResponseMetrics) Throttled() bool {
+	return m != nil && (m.StatusCode == http.StatusTooManyRequests || m.RetryAfter > 0)
+}
+
 func containsAny(s string, substrs []string) bool {
 	for _, sub := range substrs {
 		if strings.Contains(s, sub) {
@@ -0,0 +1,141 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/yinxulai/ait/internal/server/logger"
+	"github.com/yinxulai/ait/internal/server/types"
+)
+
+// FallbackClient 主模型请求失败时立即改用备用模型重试一次：两个客户端除模型外配置相同，
+// 重试后的指标在 ResponseMetrics.FallbackUsed 中标记，耗时包含主模型失败前花费的时间。
+type FallbackClient struct {
+	primary       ModelClient
+	fallback      ModelClient
+	fallbackModel string
+}
+
+// NewFallbackClient 为 config.Model 与 config.FallbackModel 分别创建客户端（多端点配置对两者都生效）。
+func NewFallbackClient(config types.Input, logger *logger.Logger) (*FallbackClient, error) {
+	primaryConfig := config
+	primaryConfig.FallbackModel = ""
+	primary, err := NewClient(primaryConfig, logger)
+	if err != nil {
+		return nil, err
+	}
+	fallbackConfig := primaryConfig
+	fallbackConfig.Model = config.FallbackModel
+	fallbackConfig.ModelAlias = ""
+	fallback, err := NewClient(fallbackConfig, logger)
+	if err != nil {
+		return nil, err
+	}
+	return &FallbackClient{primary: primary, fallback: fallback, fallbackModel: config.FallbackModel}, nil
+}
+
+func (f *FallbackClient) Request(ctx context.Context, systemPrompt, userPrompt string, stream bool) (*ResponseMetrics, error) {
+	start := time.Now()
+	metrics, err := f.primary.Request(ctx, systemPrompt, userPrompt, stream)
+	if !needsFallback(ctx, metrics, err) {
+		return metrics, err
+	}
+	elapsed := time.Since(start)
+	metrics, err = f.fallback.Request(ctx, systemPrompt, userPrompt, stream)
+	return markFallback(metrics, elapsed), err
+}
+
+// RawRequest 备用请求把原始请求体中的 model 字段替换为备用模型；请求体不是 JSON 对象时不重试。
+func (f *FallbackClient) RawRequest(ctx context.Context, rawBody string) (*ResponseMetrics, error) {
+	start := time.Now()
+	metrics, err := f.primary.RawRequest(ctx, rawBody)
+	if !needsFallback(ctx, metrics, err) {
+		return metrics, err
+	}
+	fallbackBody, ok := replaceRawModel(rawBody, f.fallbackModel)
+	if !ok {
+		return metrics, err
+	}
+	elapsed := time.Since(start)
+	metrics, err = f.fallback.RawRequest(ctx, fallbackBody)
+	return markFallback(metrics, elapsed), err
+}
+
+// needsFallback 主模型请求失败且运行未被取消时需要改用备用模型
+func needsFallback(ctx context.Context, metrics *ResponseMetrics, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	return err != nil || metrics == nil || metrics.ErrorMessage != ""
+}
+
+// markFallback 标记备用请求，并把主模型失败前花费的时间计入总耗时与 TTFT，反映调用方实际等待的时长
+func markFallback(metrics *ResponseMetrics, elapsed time.Duration) *ResponseMetrics {
+	if metrics == nil {
+		return nil
+	}
+	metrics.FallbackUsed = true
+	metrics.TotalTime += elapsed
+	if metrics.TimeToFirstToken > 0 {
+		metrics.TimeToFirstToken += elapsed
+	}
+	return metrics
+}
+
+// replaceRawModel 把 JSON 请求体的 model 字段替换为 model
+func replaceRawModel(rawBody, model string) (string, bool) {
+	var body map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(rawBody), &body); err != nil {
+		return "", false
+	}
+	encoded, err := json.Marshal(model)
+	if err != nil {
+		return "", false
+	}
+	body["model"] = encoded
+	out, err := json.Marshal(body)
+	if err != nil {
+		return "", false
+	}
+	return string(out), true
+}
+
+func (f *FallbackClient) GetProtocol() string {
+	return f.primary.GetProtocol()
+}
+
+func (f *FallbackClient) GetModel() string {
+	return f.primary.GetModel()
+}
+
+func (f *FallbackClient) SetLogger(logger *logger.Logger) {
+	f.primary.SetLogger(logger)
+	f.fallback.SetLogger(logger)
+}
+
+// CloseIdleConnections 关闭主模型与备用模型客户端的空闲连接
+func (f *FallbackClient) CloseIdleConnections() {
+	CloseIdleConnections(f.primary)
+	CloseIdleConnections(f.fallback)
+}
+
+// BuildRequest 以主模型构造请求；主模型客户端不支持时返回错误。
+func (f *FallbackClient) BuildRequest(ctx context.Context, systemPrompt, userPrompt string, stream bool) (*http.Request, []byte, error) {
+	builder, ok := f.primary.(RequestBuilder)
+	if !ok {
+		return nil, nil, fmt.Errorf("%s 协议不支持只构造请求", f.GetProtocol())
+	}
+	return builder.BuildRequest(ctx, systemPrompt, userPrompt, stream)
+}
+
+// BuildRawRequest 以主模型按原始请求体构造请求。
+func (f *FallbackClient) BuildRawRequest(ctx context.Context, rawBody string) (*http.Request, []byte, error) {
+	builder, ok := f.primary.(RequestBuilder)
+	if !ok {
+		return nil, nil, fmt.Errorf("%s 协议不支持只构造请求", f.GetProtocol())
+	}
+	return builder.BuildRawRequest(ctx, rawBody)
+}
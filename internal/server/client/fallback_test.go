@@ -0,0 +1,132 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/yinxulai/ait/internal/server/types"
+)
+
+// newModelRoutingServer 按请求体中的 model 响应：failing 中的模型返回 503，其余正常返回，并按顺序记录收到的模型
+func newModelRoutingServer(t *testing.T, failing ...string) (*httptest.Server, func() []string) {
+	t.Helper()
+	var mu sync.Mutex
+	var models []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Model string `json:"model"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		mu.Lock()
+		models = append(models, body.Model)
+		mu.Unlock()
+		if slices.Contains(failing, body.Model) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			fmt.Fprint(w, `{"error":{"message":"overloaded","type":"server_error"}}`)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"choices":[{"index":0,"message":{"role":"assistant","content":"hi"},"finish_reason":"stop"}],"usage":{"prompt_tokens":3,"completion_tokens":2}}`)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return slices.Clone(models)
+	}
+}
+
+func newFallbackTestClient(t *testing.T, url string) ModelClient {
+	t.Helper()
+	c, err := NewClient(types.Input{
+		Protocol:      types.ProtocolOpenAICompletions,
+		EndpointURL:   url,
+		Model:         "primary",
+		FallbackModel: "backup",
+	}, nil)
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	if _, ok := c.(*FallbackClient); !ok {
+		t.Fatalf("NewClient with fallback_model returned %T", c)
+	}
+	return c
+}
+
+func TestFallbackClient_PrimaryFails(t *testing.T) {
+	srv, models := newModelRoutingServer(t, "primary")
+	c := newFallbackTestClient(t, srv.URL)
+
+	metrics, err := c.Request(context.Background(), "", "hello", false)
+	if err != nil || metrics.ErrorMessage != "" {
+		t.Fatalf("fallback request should succeed: err=%v metrics=%+v", err, metrics)
+	}
+	if !metrics.FallbackUsed || metrics.CompletionTokens != 2 {
+		t.Errorf("FallbackUsed=%v CompletionTokens=%d", metrics.FallbackUsed, metrics.CompletionTokens)
+	}
+	if got := models(); !slices.Equal(got, []string{"primary", "backup"}) {
+		t.Errorf("models requested = %v, want [primary backup]", got)
+	}
+	if c.GetModel() != "primary" {
+		t.Errorf("GetModel = %s, want primary", c.GetModel())
+	}
+}
+
+func TestFallbackClient_PrimarySucceeds(t *testing.T) {
+	srv, models := newModelRoutingServer(t)
+	c := newFallbackTestClient(t, srv.URL)
+
+	metrics, err := c.Request(context.Background(), "", "hello", false)
+	if err != nil || metrics.FallbackUsed {
+		t.Fatalf("err=%v FallbackUsed=%v", err, metrics.FallbackUsed)
+	}
+	if got := models(); !slices.Equal(got, []string{"primary"}) {
+		t.Errorf("models requested = %v, want [primary]", got)
+	}
+}
+
+func TestFallbackClient_BothFail(t *testing.T) {
+	srv, models := newModelRoutingServer(t, "primary", "backup")
+	c := newFallbackTestClient(t, srv.URL)
+
+	metrics, err := c.Request(context.Background(), "", "hello", false)
+	if err == nil || metrics == nil || metrics.ErrorMessage == "" || !metrics.FallbackUsed {
+		t.Fatalf("want failed fallback request, got err=%v metrics=%+v", err, metrics)
+	}
+	if len(models()) != 2 {
+		t.Errorf("models requested = %v, want one retry", models())
+	}
+}
+
+func TestFallbackClient_RawRequestReplacesModel(t *testing.T) {
+	srv, models := newModelRoutingServer(t, "primary")
+	c := newFallbackTestClient(t, srv.URL)
+
+	metrics, err := c.RawRequest(context.Background(), `{"model":"primary","messages":[{"role":"user","content":"hi"}]}`)
+	if err != nil || !metrics.FallbackUsed {
+		t.Fatalf("err=%v metrics=%+v", err, metrics)
+	}
+	if got := models(); !slices.Equal(got, []string{"primary", "backup"}) {
+		t.Errorf("models requested = %v, want [primary backup]", got)
+	}
+}
+
+func TestFallbackClient_CanceledContextSkipsFallback(t *testing.T) {
+	srv, models := newModelRoutingServer(t)
+	c := newFallbackTestClient(t, srv.URL)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := c.Request(ctx, "", "hello", false); err == nil {
+		t.Fatal("canceled request should fail")
+	}
+	if len(models()) != 0 {
+		t.Errorf("canceled run should not retry with the fallback model, got %v", models())
+	}
+}
@@ -0,0 +1,393 @@
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/mem"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/yinxulai/ait/internal/server/logger"
+	"github.com/yinxulai/ait/internal/server/network"
+	promptpkg "github.com/yinxulai/ait/internal/server/prompt"
+	"github.com/yinxulai/ait/internal/server/types"
+)
+
+// GRPCClient Triton Inference Server gRPC 客户端（KServe v2 ModelStreamInfer）
+//
+// 面向 vLLM backend 的 generate_stream：输入 text_input/stream，读取 text_output。
+// 与 HTTP 客户端一样，每个请求都会新建连接，以便测量 DNS / TCP / TLS 耗时。
+//
+// gRPC 推理接口不返回 token 用量：流式时以收到的响应条数作为输出 token 数
+// （vLLM 每个增量通常对应一个 token），非流式时用 prompt.EstimateTokens 按本地规则估算。
+type GRPCClient struct {
+	Endpoint string // host:port
+	Method   string // 完整方法名，如 /inference.GRPCInferenceService/ModelStreamInfer
+	Model    string
+	Provider string
+	UseTLS   bool
+	timeout  time.Duration
+	logger   *logger.Logger
+
+	TokenTraceMaxChunks int // 流式响应记录 chunk 时序的上限，0 表示不记录
+}
+
+// NewGRPCClient 根据配置创建 gRPC 客户端
+func NewGRPCClient(config types.Input) *GRPCClient {
+	endpoint, useTLS := parseGRPCEndpoint(config.ResolvedEndpointURL())
+	return &GRPCClient{
+		Endpoint: endpoint,
+		Method:   config.ResolvedGRPCMethod(),
+		Model:    config.Model,
+		Provider: config.NormalizedProtocol(),
+		UseTLS:   useTLS,
+		timeout:  config.Timeout,
+		logger:   nil,
+
+		TokenTraceMaxChunks: config.TokenTraceMaxChunks,
+	}
+}
+
+// parseGRPCEndpoint 去掉 grpc:// / grpcs:// 前缀，grpcs 表示启用 TLS。
+func parseGRPCEndpoint(raw string) (endpoint string, useTLS bool) {
+	endpoint = strings.TrimSpace(raw)
+	switch {
+	case strings.HasPrefix(endpoint, "grpcs://"):
+		endpoint, useTLS = strings.TrimPrefix(endpoint, "grpcs://"), true
+	case strings.HasPrefix(endpoint, "grpc://"):
+		endpoint = strings.TrimPrefix(endpoint, "grpc://")
+	}
+	return strings.TrimRight(endpoint, "/"), useTLS
+}
+
+// SetLogger 设置日志记录器
+func (c *GRPCClient) SetLogger(l *logger.Logger) {
+	c.logger = l
+}
+
+// Request 发送 Triton 推理请求。text_input 不区分角色，systemPrompt 直接拼接在用户输入之前。
+func (c *GRPCClient) Request(ctx context.Context, systemPrompt, userPrompt string, stream bool) (*ResponseMetrics, error) {
+	if c.logger != nil && c.logger.IsEnabled() {
+		c.logger.LogTestStart(c.Model, userPrompt, map[string]interface{}{
+			"stream":       stream,
+			"protocol":     c.Provider,
+			"endpoint_url": c.Endpoint,
+			"grpc_method":  c.Method,
+		})
+	}
+
+	prompt := userPrompt
+	if systemPrompt != "" {
+		prompt = systemPrompt + "\n\n" + userPrompt
+	}
+	return c.doRequest(ctx, prompt, stream)
+}
+
+// grpcRawRequest RawRequest 接受的 JSON 结构
+type grpcRawRequest struct {
+	TextInput string `json:"text_input"`
+	Stream    bool   `json:"stream"`
+}
+
+// RawRequest 使用 JSON 形式的原始请求：{"text_input": "...", "stream": true}
+func (c *GRPCClient) RawRequest(ctx context.Context, rawBody string) (*ResponseMetrics, error) {
+	var raw grpcRawRequest
+	if err := json.Unmarshal([]byte(rawBody), &raw); err != nil {
+		return &ResponseMetrics{
+			RequestBody:  rawBody,
+			ErrorMessage: fmt.Sprintf("JSON decoding error: %s", err.Error()),
+		}, err
+	}
+	return c.doRequest(ctx, raw.TextInput, raw.Stream)
+}
+
+// doRequest 建立连接并执行一次双向流调用，直到服务端关闭流。
+func (c *GRPCClient) doRequest(ctx context.Context, prompt string, stream bool) (*ResponseMetrics, error) {
+	rt := newRequestTrace(false)
+	metrics, err := c.send(ctx, prompt, stream, rt)
+	rt.apply(metrics, c.logger, c.Model)
+	return metrics, err
+}
+
+// send 执行一次调用并收集指标，trace id 通过 x-client-request-id 元数据发送。
+func (c *GRPCClient) send(ctx context.Context, prompt string, stream bool, rt *requestTrace) (*ResponseMetrics, error) {
+	requestBody, _ := json.Marshal(map[string]interface{}{
+		"model_name": c.Model,
+		"method":     c.Method,
+		"text_input": prompt,
+		"stream":     stream,
+	})
+
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	// 超时或快速失败返回后 gRPC 可能仍在后台拨号，建连计时经 dial 加锁读写
+	dial := &grpcDialStats{}
+
+	creds := insecure.NewCredentials()
+	if c.UseTLS {
+		creds = &timedCredentials{TransportCredentials: credentials.NewTLS(&tls.Config{}), dial: dial}
+	}
+
+	t0 := time.Now()
+	fail := func(stage string, err error) (*ResponseMetrics, error) {
+		if c.logger != nil && c.logger.IsEnabled() {
+			c.logger.Error(c.Model, stage, err)
+		}
+		var statusCode int
+		if status.Code(err) == codes.ResourceExhausted {
+			statusCode = http.StatusTooManyRequests
+		}
+		metrics := &ResponseMetrics{
+			TotalTime:    time.Since(t0),
+			RequestBody:  string(requestBody),
+			ErrorMessage: EnhanceErrorMessage(fmt.Sprintf("gRPC error: %s", err.Error())),
+			StatusCode:   statusCode,
+		}
+		dial.apply(metrics)
+		return metrics, err
+	}
+
+	// passthrough 跳过 gRPC 自带的解析器，由 dialer 自行解析 DNS 并计时
+	conn, err := grpc.NewClient("passthrough:///"+c.Endpoint,
+		grpc.WithTransportCredentials(creds),
+		grpc.WithContextDialer(func(ctx context.Context, addr string) (net.Conn, error) {
+			host, port, err := net.SplitHostPort(addr)
+			if err != nil {
+				return nil, err
+			}
+			// --resolve 固定解析的地址不做 DNS 解析，--dns-server 指定上游 DNS
+			dns := network.CurrentDNSConfig()
+			var targetIP string
+			if ip, ok := dns.Lookup(host, port); ok {
+				targetIP = ip
+			} else {
+				resolver := dns.Resolver()
+				if resolver == nil {
+					resolver = net.DefaultResolver
+				}
+				dnsStart := time.Now()
+				ips, err := resolver.LookupIPAddr(ctx, host)
+				dial.setDNS(time.Since(dnsStart), ips)
+				if err != nil {
+					return nil, err
+				}
+				if len(ips) == 0 {
+					return nil, fmt.Errorf("no address for host %s", host)
+				}
+				targetIP = ips[0].IP.String()
+			}
+
+			connectStart := time.Now()
+			var d net.Dialer
+			netConn, err := d.DialContext(ctx, "tcp", net.JoinHostPort(targetIP, port))
+			dial.setConnect(time.Since(connectStart), targetIP)
+			return netConn, err
+		}),
+	)
+	if err != nil {
+		return fail("gRPC client creation failed", err)
+	}
+	defer conn.Close()
+
+	ctx = metadata.AppendToOutgoingContext(ctx, strings.ToLower(HeaderClientRequestID), rt.clientID)
+	callStream, err := conn.NewStream(ctx, &grpc.StreamDesc{ServerStreams: true, ClientStreams: true}, c.Method, grpc.ForceCodecV2(rawCodec{}))
+	if err != nil {
+		return fail("gRPC stream creation failed", err)
+	}
+
+	payload := encodeTritonInferRequest(c.Model, prompt, stream)
+	if err := callStream.SendMsg(&payload); err != nil {
+		return fail("gRPC send failed", err)
+	}
+	if err := callStream.CloseSend(); err != nil {
+		return fail("gRPC close send failed", err)
+	}
+
+	var output strings.Builder
+	var firstTokenTime time.Duration
+	chunks := 0
+	chunkLog := newChunkRecorder(t0, c.TokenTraceMaxChunks)
+	for {
+		var msg []byte
+		err := callStream.RecvMsg(&msg)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			if md, mdErr := callStream.Header(); mdErr == nil {
+				rt.captureMetadata(md)
+			}
+			return fail("gRPC receive failed", err)
+		}
+		if rt.firstByte == 0 {
+			rt.firstByte = time.Since(t0)
+		}
+
+		text, err := decodeTritonStreamResponse(msg)
+		if err != nil {
+			return fail("Triton response failed", err)
+		}
+		if text == "" {
+			continue
+		}
+		if chunks == 0 {
+			firstTokenTime = time.Since(t0)
+		}
+		chunks++
+		output.WriteString(text)
+		if stream {
+			chunkLog.observe(text)
+		}
+	}
+	totalTime := time.Since(t0)
+	if md, err := callStream.Header(); err == nil {
+		rt.captureMetadata(md)
+	}
+
+	completionTokens := chunks
+	if !stream {
+		completionTokens = promptpkg.EstimateTokens(output.String())
+		firstTokenTime = totalTime
+	}
+
+	if c.logger != nil && c.logger.IsEnabled() {
+		c.logger.LogResponse(c.Model, logger.ResponseData{
+			Body: output.String(),
+		})
+	}
+
+	metrics := &ResponseMetrics{
+		TimeToFirstToken: firstTokenTime,
+		TotalTime:        totalTime,
+		CompletionTokens: completionTokens,
+		RequestBody:      string(requestBody),
+		ResponseBody:     output.String(),
+		ResponseText:     output.String(),
+		EmptyContent:     isEmptyContent(output.String()),
+	}
+	dial.apply(metrics)
+	chunkLog.apply(metrics)
+	return metrics, nil
+}
+
+// grpcDialStats 一次调用的建连计时，由 dialer 与 TLS 握手写入、构建指标时读取，可并发访问。
+type grpcDialStats struct {
+	mu          sync.Mutex
+	dnsTime     time.Duration
+	connectTime time.Duration
+	tlsTime     time.Duration
+	targetIP    string
+	resolvedIPs []string
+}
+
+func (s *grpcDialStats) setDNS(elapsed time.Duration, ips []net.IPAddr) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.dnsTime = elapsed
+	if len(ips) > 0 {
+		s.resolvedIPs = ipAddrStrings(ips)
+	}
+}
+
+func (s *grpcDialStats) setConnect(elapsed time.Duration, targetIP string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.connectTime = elapsed
+	s.targetIP = targetIP
+}
+
+func (s *grpcDialStats) setTLS(elapsed time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tlsTime = elapsed
+}
+
+// apply 把当前计时快照写入 metrics
+func (s *grpcDialStats) apply(metrics *ResponseMetrics) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	metrics.DNSTime = s.dnsTime
+	metrics.ConnectTime = s.connectTime
+	metrics.TLSHandshakeTime = s.tlsTime
+	metrics.TargetIP = s.targetIP
+	metrics.ResolvedIPs = slices.Clone(s.resolvedIPs)
+}
+
+// captureMetadata 从 gRPC 响应头元数据中记录供应商请求 ID。
+func (t *requestTrace) captureMetadata(md metadata.MD) {
+	header := make(http.Header, len(md))
+	for k, v := range md {
+		header[http.CanonicalHeaderKey(k)] = v
+	}
+	t.capture(header)
+}
+
+// GetProtocol 获取协议类型
+func (c *GRPCClient) GetProtocol() string {
+	return c.Provider
+}
+
+// GetModel 获取模型名称
+func (c *GRPCClient) GetModel() string {
+	return c.Model
+}
+
+// rawCodec 直接收发已编码的 protobuf 字节，配合 triton_proto.go 的手写编解码使用。
+type rawCodec struct{}
+
+func (rawCodec) Marshal(v any) (mem.BufferSlice, error) {
+	b, ok := v.(*[]byte)
+	if !ok {
+		return nil, fmt.Errorf("rawCodec: unexpected message type %T", v)
+	}
+	return mem.BufferSlice{mem.SliceBuffer(*b)}, nil
+}
+
+func (rawCodec) Unmarshal(data mem.BufferSlice, v any) error {
+	b, ok := v.(*[]byte)
+	if !ok {
+		return fmt.Errorf("rawCodec: unexpected message type %T", v)
+	}
+	*b = data.Materialize()
+	return nil
+}
+
+func (rawCodec) Name() string {
+	return "proto"
+}
+
+// timedCredentials 记录 TLS 握手耗时
+type timedCredentials struct {
+	credentials.TransportCredentials
+	dial *grpcDialStats
+}
+
+func (c *timedCredentials) ClientHandshake(ctx context.Context, authority string, rawConn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	start := time.Now()
+	conn, info, err := c.TransportCredentials.ClientHandshake(ctx, authority, rawConn)
+	c.dial.setTLS(time.Since(start))
+	return conn, info, err
+}
+
+func (c *timedCredentials) Clone() credentials.TransportCredentials {
+	return &timedCredentials{TransportCredentials: c.TransportCredentials.Clone(), dial: c.dial}
+}
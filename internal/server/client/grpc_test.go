@@ -0,0 +1,234 @@
+package client
+
+import (
+	"context"
+	"encoding/binary"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"github.com/yinxulai/ait/internal/server/types"
+)
+
+// encodeTestStreamResponse 构造 ModelStreamInferResponse，text_output 通过 raw_output_contents 返回。
+func encodeTestStreamResponse(text, errMsg string) []byte {
+	if errMsg != "" {
+		b := protowire.AppendTag(nil, 1, protowire.BytesType)
+		return protowire.AppendString(b, errMsg)
+	}
+
+	var output []byte
+	output = protowire.AppendTag(output, 1, protowire.BytesType)
+	output = protowire.AppendString(output, tritonTextOutput)
+
+	raw := binary.LittleEndian.AppendUint32(nil, uint32(len(text)))
+	raw = append(raw, text...)
+
+	var infer []byte
+	infer = protowire.AppendTag(infer, 5, protowire.BytesType)
+	infer = protowire.AppendBytes(infer, output)
+	infer = protowire.AppendTag(infer, 6, protowire.BytesType)
+	infer = protowire.AppendBytes(infer, raw)
+
+	b := protowire.AppendTag(nil, 2, protowire.BytesType)
+	return protowire.AppendBytes(b, infer)
+}
+
+// decodeTestInferRequest 从 ModelInferRequest 中取出 model_name 与 text_input。
+func decodeTestInferRequest(t *testing.T, data []byte) (model, prompt string) {
+	t.Helper()
+	err := rangeProtoFields(data, func(num protowire.Number, _ protowire.Type, v []byte) {
+		switch num {
+		case 1:
+			model = string(v)
+		case 5:
+			var name string
+			var contents []byte
+			_ = rangeProtoFields(v, func(n protowire.Number, _ protowire.Type, fv []byte) {
+				switch n {
+				case 1:
+					name = string(fv)
+				case 5:
+					_ = rangeProtoFields(fv, func(cn protowire.Number, _ protowire.Type, cv []byte) {
+						if cn == 8 {
+							contents = cv
+						}
+					})
+				}
+			})
+			if name == tritonTextInput {
+				prompt = string(contents)
+			}
+		}
+	})
+	if err != nil {
+		t.Fatalf("decode request: %v", err)
+	}
+	return model, prompt
+}
+
+// startTritonStub 启动进程内 gRPC 服务，对任意方法按 chunks 逐条回写响应。
+func startTritonStub(t *testing.T, chunks []string, errMsg string) (addr string, gotMethod *string, gotRequest *[]byte) {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+
+	var method string
+	var request []byte
+	srv := grpc.NewServer(
+		grpc.ForceServerCodecV2(rawCodec{}),
+		grpc.UnknownServiceHandler(func(_ any, stream grpc.ServerStream) error {
+			method, _ = grpc.MethodFromServerStream(stream)
+			if err := stream.RecvMsg(&request); err != nil {
+				return err
+			}
+			if errMsg != "" {
+				msg := encodeTestStreamResponse("", errMsg)
+				return stream.SendMsg(&msg)
+			}
+			for _, chunk := range chunks {
+				msg := encodeTestStreamResponse(chunk, "")
+				if err := stream.SendMsg(&msg); err != nil {
+					return err
+				}
+			}
+			return nil
+		}),
+	)
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+	return lis.Addr().String(), &method, &request
+}
+
+func TestGRPCClient_StreamRequest(t *testing.T) {
+	addr, gotMethod, gotRequest := startTritonStub(t, []string{"Hello", " ", "world"}, "")
+
+	c := NewGRPCClient(types.Input{
+		Protocol:    types.ProtocolTritonGRPC,
+		EndpointURL: "grpc://" + addr,
+		Model:       "vllm_model",
+		Timeout:     5 * time.Second,
+	})
+
+	metrics, err := c.Request(context.Background(), "be brief", "hi", true)
+	if err != nil {
+		t.Fatalf("Request: %v", err)
+	}
+	if *gotMethod != types.DefaultGRPCMethod {
+		t.Errorf("method = %q, want %q", *gotMethod, types.DefaultGRPCMethod)
+	}
+	model, prompt := decodeTestInferRequest(t, *gotRequest)
+	if model != "vllm_model" || prompt != "be brief\n\nhi" {
+		t.Errorf("request = (%q, %q)", model, prompt)
+	}
+	if metrics.ResponseBody != "Hello world" {
+		t.Errorf("ResponseBody = %q", metrics.ResponseBody)
+	}
+	if metrics.CompletionTokens != 3 {
+		t.Errorf("CompletionTokens = %d, want 3", metrics.CompletionTokens)
+	}
+	if metrics.TimeToFirstToken <= 0 || metrics.TimeToFirstToken > metrics.TotalTime {
+		t.Errorf("TTFT = %v, TotalTime = %v", metrics.TimeToFirstToken, metrics.TotalTime)
+	}
+	if metrics.TargetIP != "127.0.0.1" {
+		t.Errorf("TargetIP = %q", metrics.TargetIP)
+	}
+}
+
+func TestGRPCClient_CustomMethodNonStream(t *testing.T) {
+	addr, gotMethod, _ := startTritonStub(t, []string{"你好世界 ok"}, "")
+
+	c := NewGRPCClient(types.Input{
+		Protocol:    types.ProtocolTritonGRPC,
+		EndpointURL: addr,
+		GRPCMethod:  "/custom.Service/Generate",
+		Model:       "vllm_model",
+	})
+
+	metrics, err := c.Request(context.Background(), "", "hi", false)
+	if err != nil {
+		t.Fatalf("Request: %v", err)
+	}
+	if *gotMethod != "/custom.Service/Generate" {
+		t.Errorf("method = %q", *gotMethod)
+	}
+	if metrics.CompletionTokens != 5 {
+		t.Errorf("CompletionTokens = %d, want 5", metrics.CompletionTokens)
+	}
+	if metrics.TimeToFirstToken != metrics.TotalTime {
+		t.Errorf("non-stream TTFT = %v, want TotalTime %v", metrics.TimeToFirstToken, metrics.TotalTime)
+	}
+}
+
+func TestGRPCClient_ServerError(t *testing.T) {
+	addr, _, _ := startTritonStub(t, nil, "model not ready")
+
+	c := NewGRPCClient(types.Input{Protocol: types.ProtocolTritonGRPC, EndpointURL: addr, Model: "m"})
+	metrics, err := c.Request(context.Background(), "", "hi", true)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if metrics == nil || !strings.Contains(metrics.ErrorMessage, "model not ready") {
+		t.Errorf("ErrorMessage = %+v", metrics)
+	}
+}
+
+func TestGRPCClient_RawRequest(t *testing.T) {
+	addr, _, gotRequest := startTritonStub(t, []string{"ok"}, "")
+
+	c := NewGRPCClient(types.Input{Protocol: types.ProtocolTritonGRPC, EndpointURL: addr, Model: "m"})
+	if _, err := c.RawRequest(context.Background(), `{"text_input":"raw prompt","stream":true}`); err != nil {
+		t.Fatalf("RawRequest: %v", err)
+	}
+	if _, prompt := decodeTestInferRequest(t, *gotRequest); prompt != "raw prompt" {
+		t.Errorf("prompt = %q", prompt)
+	}
+
+	if _, err := c.RawRequest(context.Background(), "not json"); err == nil {
+		t.Error("expected error for invalid raw body")
+	}
+}
+
+func TestDecodeTritonInferResponse_BytesContents(t *testing.T) {
+	var contents []byte
+	contents = protowire.AppendTag(contents, 8, protowire.BytesType)
+	contents = protowire.AppendString(contents, "inline")
+
+	var output []byte
+	output = protowire.AppendTag(output, 1, protowire.BytesType)
+	output = protowire.AppendString(output, tritonTextOutput)
+	output = protowire.AppendTag(output, 5, protowire.BytesType)
+	output = protowire.AppendBytes(output, contents)
+
+	infer := protowire.AppendTag(nil, 5, protowire.BytesType)
+	infer = protowire.AppendBytes(infer, output)
+
+	got, err := decodeTritonInferResponse(infer)
+	if err != nil || got != "inline" {
+		t.Fatalf("decodeTritonInferResponse = (%q, %v), want inline", got, err)
+	}
+}
+
+func TestParseGRPCEndpoint(t *testing.T) {
+	tests := []struct {
+		raw     string
+		want    string
+		wantTLS bool
+	}{
+		{"localhost:8001", "localhost:8001", false},
+		{"grpc://triton:8001/", "triton:8001", false},
+		{"grpcs://triton.example.com:443", "triton.example.com:443", true},
+	}
+	for _, tt := range tests {
+		got, tlsOn := parseGRPCEndpoint(tt.raw)
+		if got != tt.want || tlsOn != tt.wantTLS {
+			t.Errorf("parseGRPCEndpoint(%q) = (%q, %v), want (%q, %v)", tt.raw, got, tlsOn, tt.want, tt.wantTLS)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/yinxulai/ait/internal/server/types"
+)
+
+// ModelList GET /v1/models 的结果。
+type ModelList struct {
+	StatusCode int      // HTTP 状态码
+	Models     []string // 返回的模型 ID，非 200 或无法解析时为空
+	Body       string   // 非 200 响应的原始响应体，供错误分类
+}
+
+// ModelsURL 返回与 config 请求地址同前缀的模型列表地址（如 https://api.openai.com/v1/models）；
+// gRPC 协议或地址不符合约定的路径时返回空字符串。
+func ModelsURL(config types.Input) string {
+	endpoint := config.ResolvedEndpointURL()
+	var suffix string
+	switch config.NormalizedProtocol() {
+	case types.ProtocolOpenAICompletions:
+		suffix = "/chat/completions"
+	case types.ProtocolOpenAIResponses:
+		suffix = "/responses"
+	case types.ProtocolAnthropicMessages:
+		suffix = "/messages"
+	default:
+		return ""
+	}
+	prefix, ok := strings.CutSuffix(endpoint, suffix)
+	if !ok {
+		return ""
+	}
+	return prefix + "/models"
+}
+
+// ListModels 以与被测请求相同的鉴权方式与传输配置（代理、--resolve、HTTP 版本）请求模型列表。
+// 只有网络错误（未收到响应）时返回 error。
+func ListModels(ctx context.Context, config types.Input) (*ModelList, error) {
+	modelsURL := ModelsURL(config)
+	if modelsURL == "" {
+		return nil, fmt.Errorf("%s 协议不支持查询模型列表", config.NormalizedProtocol())
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, modelsURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	if config.NormalizedProtocol() == types.ProtocolAnthropicMessages {
+		req.Header.Set("x-api-key", config.ApiKey)
+		req.Header.Set("anthropic-version", anthropicVersion(config.AnthropicVersion))
+	} else {
+		req.Header.Set("Authorization", "Bearer "+config.ApiKey)
+	}
+
+	httpClient := &http.Client{Transport: newMeasuredTransport(config), Timeout: config.Timeout}
+	defer httpClient.CloseIdleConnections()
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+
+	list := &ModelList{StatusCode: resp.StatusCode}
+	if resp.StatusCode != http.StatusOK {
+		list.Body = string(body)
+		return list, nil
+	}
+	var parsed struct {
+		Data []struct {
+			ID string `json:"id"`
+		} `json:"data"`
+	}
+	if json.Unmarshal(body, &parsed) == nil {
+		for _, m := range parsed.Data {
+			list.Models = append(list.Models, m.ID)
+		}
+	}
+	return list, nil
+}
@@ -0,0 +1,25 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/yinxulai/ait/internal/server/types"
+)
+
+func TestModelsURL(t *testing.T) {
+	cases := []struct {
+		input types.Input
+		want  string
+	}{
+		{types.Input{Protocol: types.ProtocolOpenAICompletions, BaseUrl: "https://api.example.com/v1"}, "https://api.example.com/v1/models"},
+		{types.Input{Protocol: types.ProtocolOpenAIResponses, BaseUrl: "https://api.example.com"}, "https://api.example.com/v1/models"},
+		{types.Input{Protocol: types.ProtocolAnthropicMessages}, "https://api.anthropic.com/v1/models"},
+		{types.Input{Protocol: types.ProtocolOpenAICompletions, EndpointURL: "https://gw.example.com/proxy"}, ""},
+		{types.Input{Protocol: types.ProtocolTritonGRPC, EndpointURL: "localhost:8001"}, ""},
+	}
+	for _, c := range cases {
+		if got := ModelsURL(c.input); got != c.want {
+			t.Errorf("ModelsURL(%+v) = %q, want %q", c.input, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/yinxulai/ait/internal/server/clock"
+	"github.com/yinxulai/ait/internal/server/logger"
+	"github.com/yinxulai/ait/internal/server/types"
+)
+
+// MultiEndpointClient 在多个端点之间分发请求：每个端点持有独立的客户端，
+// 每次请求按策略（轮询 / 随机）选择一个，并在 ResponseMetrics.Endpoint 中记录实际命中的端点。
+type MultiEndpointClient struct {
+	endpoints []string
+	clients   []ModelClient
+	random    bool
+
+	next atomic.Uint64
+	rng  clock.Rand
+}
+
+// NewMultiEndpointClient 为 config.Endpoints 中的每个端点创建客户端（覆盖 EndpointURL，其余配置相同）。
+func NewMultiEndpointClient(config types.Input, logger *logger.Logger) (*MultiEndpointClient, error) {
+	endpoints := config.Endpoints
+	m := &MultiEndpointClient{
+		endpoints: make([]string, 0, len(endpoints)),
+		clients:   make([]ModelClient, 0, len(endpoints)),
+		random:    config.EndpointStrategy == types.EndpointStrategyRandom,
+		rng:       clock.DefaultRand(),
+	}
+	for _, endpoint := range endpoints {
+		single := config
+		single.EndpointURL = endpoint
+		single.Endpoints = nil
+		c, err := NewClient(single, logger)
+		if err != nil {
+			return nil, err
+		}
+		m.endpoints = append(m.endpoints, endpoint)
+		m.clients = append(m.clients, c)
+	}
+	return m, nil
+}
+
+// SetRand 替换随机策略使用的随机源，测试注入固定种子以得到确定的端点序列。
+func (m *MultiEndpointClient) SetRand(r clock.Rand) {
+	m.rng = r
+}
+
+// pick 选择本次请求使用的端点下标
+func (m *MultiEndpointClient) pick() int {
+	if m.random {
+		return m.rng.Intn(len(m.clients))
+	}
+	return int((m.next.Add(1) - 1) % uint64(len(m.clients)))
+}
+
+func (m *MultiEndpointClient) Request(ctx context.Context, systemPrompt, userPrompt string, stream bool) (*ResponseMetrics, error) {
+	i := m.pick()
+	metrics, err := m.clients[i].Request(ctx, systemPrompt, userPrompt, stream)
+	return m.tag(metrics, i), err
+}
+
+func (m *MultiEndpointClient) RawRequest(ctx context.Context, rawBody string) (*ResponseMetrics, error) {
+	i := m.pick()
+	metrics, err := m.clients[i].RawRequest(ctx, rawBody)
+	return m.tag(metrics, i), err
+}
+
+// tag 在返回的指标上记录请求实际命中的端点
+func (m *MultiEndpointClient) tag(metrics *ResponseMetrics, i int) *ResponseMetrics {
+	if metrics != nil {
+		metrics.Endpoint = m.endpoints[i]
+	}
+	return metrics
+}
+
+func (m *MultiEndpointClient) GetProtocol() string {
+	return m.clients[0].GetProtocol()
+}
+
+func (m *MultiEndpointClient) GetModel() string {
+	return m.clients[0].GetModel()
+}
+
+func (m *MultiEndpointClient) SetLogger(logger *logger.Logger) {
+	for _, c := range m.clients {
+		c.SetLogger(logger)
+	}
+}
+
+// CloseIdleConnections 关闭各端点客户端的空闲连接
+func (m *MultiEndpointClient) CloseIdleConnections() {
+	for _, c := range m.clients {
+		CloseIdleConnections(c)
+	}
+}
+
+// BuildRequest 以第一个端点构造请求；端点客户端不支持时返回错误。
+func (m *MultiEndpointClient) BuildRequest(ctx context.Context, systemPrompt, userPrompt string, stream bool) (*http.Request, []byte, error) {
+	builder, ok := m.clients[0].(RequestBuilder)
+	if !ok {
+		return nil, nil, fmt.Errorf("%s 协议不支持只构造请求", m.GetProtocol())
+	}
+	return builder.BuildRequest(ctx, systemPrompt, userPrompt, stream)
+}
+
+// BuildRawRequest 以第一个端点按原始请求体构造请求。
+func (m *MultiEndpointClient) BuildRawRequest(ctx context.Context, rawBody string) (*http.Request, []byte, error) {
+	builder, ok := m.clients[0].(RequestBuilder)
+	if !ok {
+		return nil, nil, fmt.Errorf("%s 协议不支持只构造请求", m.GetProtocol())
+	}
+	return builder.BuildRawRequest(ctx, rawBody)
+}
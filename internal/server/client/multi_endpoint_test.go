@@ -0,0 +1,90 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync/atomic"
+	"testing"
+
+	"github.com/yinxulai/ait/internal/server/clock"
+	"github.com/yinxulai/ait/internal/server/types"
+)
+
+func newCountingOpenAIServer(t *testing.T, hits *atomic.Int32) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"choices":[{"index":0,"message":{"role":"assistant","content":"hi"},"finish_reason":"stop"}],"usage":{"prompt_tokens":3,"completion_tokens":2}}`)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestNewClient_MultiEndpointRoundRobin(t *testing.T) {
+	var hitsA, hitsB atomic.Int32
+	a := newCountingOpenAIServer(t, &hitsA)
+	b := newCountingOpenAIServer(t, &hitsB)
+
+	c, err := NewClient(types.Input{
+		Protocol:  types.ProtocolOpenAICompletions,
+		Model:     "test-model",
+		Endpoints: []string{a.URL, b.URL},
+	}, nil)
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	if _, ok := c.(*MultiEndpointClient); !ok {
+		t.Fatalf("NewClient with endpoints returned %T", c)
+	}
+
+	var got []string
+	for i := 0; i < 4; i++ {
+		metrics, err := c.Request(context.Background(), "", "hello", false)
+		if err != nil || metrics.ErrorMessage != "" {
+			t.Fatalf("request %d: err=%v metrics=%+v", i, err, metrics)
+		}
+		got = append(got, metrics.Endpoint)
+	}
+	want := []string{a.URL, b.URL, a.URL, b.URL}
+	if !slices.Equal(got, want) {
+		t.Errorf("endpoints = %v, want %v", got, want)
+	}
+	if hitsA.Load() != 2 || hitsB.Load() != 2 {
+		t.Errorf("hits = %d/%d, want 2/2", hitsA.Load(), hitsB.Load())
+	}
+	if c.GetModel() != "test-model" || c.GetProtocol() != types.ProtocolOpenAICompletions {
+		t.Errorf("model/protocol = %s/%s", c.GetModel(), c.GetProtocol())
+	}
+}
+
+func TestMultiEndpointClient_Random(t *testing.T) {
+	var hits atomic.Int32
+	a := newCountingOpenAIServer(t, &hits)
+	b := newCountingOpenAIServer(t, &hits)
+
+	c, err := NewMultiEndpointClient(types.Input{
+		Protocol:         types.ProtocolOpenAICompletions,
+		Model:            "test-model",
+		Endpoints:        []string{a.URL, b.URL},
+		EndpointStrategy: types.EndpointStrategyRandom,
+	}, nil)
+	if err != nil {
+		t.Fatalf("NewMultiEndpointClient: %v", err)
+	}
+	c.SetRand(clock.NewRand(7))
+	expected := clock.NewRand(7)
+	for i := 0; i < 5; i++ {
+		want := []string{a.URL, b.URL}[expected.Intn(2)]
+		metrics, _ := c.Request(context.Background(), "", "hello", false)
+		if metrics == nil || metrics.Endpoint != want {
+			t.Fatalf("request %d hit %+v, want endpoint %s", i, metrics, want)
+		}
+	}
+	if hits.Load() != 5 {
+		t.Errorf("hits = %d, want 5", hits.Load())
+	}
+}
@@ -0,0 +1,84 @@
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net"
+	"strings"
+	"syscall"
+)
+
+// 网络错误类别，记录在 ResponseMetrics.NetErrorKind，便于区分"连不上"的根因。
+const (
+	NetErrDNS               = "dns"                // 域名解析失败
+	NetErrConnectionRefused = "connection_refused" // 目标端口拒绝连接
+	NetErrConnectionReset   = "connection_reset"   // 连接被对端重置
+	NetErrConnectTimeout    = "connect_timeout"    // 建立 TCP 连接超时
+	NetErrTLS               = "tls"                // TLS 握手或证书校验失败
+	NetErrTimeout           = "timeout"            // 连接建立后的读写超时或请求整体超时
+	NetErrCanceled          = "canceled"           // 请求被取消（如停止运行）
+	NetErrOther             = "network"            // 其他网络错误
+)
+
+// ClassifyNetError 根据错误类型（net.DNSError、net.OpError、context 错误、TLS / 证书错误等）
+// 判断网络错误类别，err 为 nil 时返回空字符串。
+func ClassifyNetError(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return NetErrDNS
+	}
+	if isTLSError(err) {
+		return NetErrTLS
+	}
+	if errors.Is(err, syscall.ECONNREFUSED) {
+		return NetErrConnectionRefused
+	}
+	if errors.Is(err, syscall.ECONNRESET) {
+		return NetErrConnectionReset
+	}
+	if errors.Is(err, context.Canceled) {
+		return NetErrCanceled
+	}
+
+	var opErr *net.OpError
+	isDial := errors.As(err, &opErr) && opErr.Op == "dial"
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		if isDial {
+			return NetErrConnectTimeout
+		}
+		return NetErrTimeout
+	}
+	return NetErrOther
+}
+
+// ConnectFailed 返回该类别是否表示请求在连接建立阶段就失败、未到达服务端，这类失败可以安全重试。
+func ConnectFailed(kind string) bool {
+	switch kind {
+	case NetErrDNS, NetErrConnectionRefused, NetErrConnectTimeout:
+		return true
+	}
+	return false
+}
+
+// isTLSError 判断 err 是否为 TLS 握手或证书校验失败。
+func isTLSError(err error) bool {
+	var recordErr tls.RecordHeaderError
+	var certErr *tls.CertificateVerificationError
+	var authorityErr x509.UnknownAuthorityError
+	var hostnameErr x509.HostnameError
+	var invalidErr x509.CertificateInvalidError
+	if errors.As(err, &recordErr) || errors.As(err, &certErr) || errors.As(err, &authorityErr) ||
+		errors.As(err, &hostnameErr) || errors.As(err, &invalidErr) {
+		return true
+	}
+	// 对端发来的 TLS alert 没有导出的错误类型，只能按信息判断
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "remote error" || strings.Contains(err.Error(), "tls: ")
+}
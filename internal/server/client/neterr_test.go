@@ -0,0 +1,61 @@
+package client
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestClassifyNetError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"nil", nil, ""},
+		{"dns", &net.DNSError{Err: "no such host", Name: "nowhere.invalid", IsNotFound: true}, NetErrDNS},
+		{"connect timeout", &net.OpError{Op: "dial", Net: "tcp", Err: os.ErrDeadlineExceeded}, NetErrConnectTimeout},
+		{"read timeout", &net.OpError{Op: "read", Net: "tcp", Err: os.ErrDeadlineExceeded}, NetErrTimeout},
+		{"deadline", fmt.Errorf("request: %w", context.DeadlineExceeded), NetErrTimeout},
+		{"canceled", fmt.Errorf("request: %w", context.Canceled), NetErrCanceled},
+		{"tls", fmt.Errorf("request: %w", x509.UnknownAuthorityError{}), NetErrTLS},
+		{"other", errors.New("unexpected EOF"), NetErrOther},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ClassifyNetError(tt.err); got != tt.want {
+				t.Errorf("ClassifyNetError(%v) = %q, want %q", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClassifyNetError_ConnectionRefused(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+
+	_, err = (&http.Client{Timeout: 5 * time.Second}).Get("http://" + addr)
+	if err == nil {
+		t.Fatal("expected connection error")
+	}
+	kind := ClassifyNetError(err)
+	if kind != NetErrConnectionRefused {
+		t.Fatalf("ClassifyNetError(%v) = %q, want %q", err, kind, NetErrConnectionRefused)
+	}
+	if !ConnectFailed(kind) {
+		t.Errorf("ConnectFailed(%q) = false, want true", kind)
+	}
+	if ConnectFailed(NetErrTimeout) {
+		t.Errorf("ConnectFailed(%q) = true, want false", NetErrTimeout)
+	}
+}
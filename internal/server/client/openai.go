@@ -1,7 +1,6 @@
 package client
 
 import (
-	"bufio"
 	"bytes"
 	"context"
 	"crypto/tls"
@@ -11,6 +10,7 @@ import (
 	"net"
 	"net/http"
 	"net/http/httptrace"
+	"sort"
 	"strings"
 	"time"
 
@@ -31,7 +31,8 @@ type StreamOptions struct {
 
 // ThinkingOptions represents reasoning options for chat completion
 type ThinkingOptions struct {
-	Type string `json:"type"`
+	Type         string `json:"type"`
+	BudgetTokens int    `json:"budget_tokens,omitempty"`
 }
 
 type ResponsesReasoningOptions struct {
@@ -55,6 +56,25 @@ type ChatCompletionRequest struct {
 	Stream        bool                    `json:"stream,omitempty"`
 	StreamOptions *StreamOptions          `json:"stream_options,omitempty"`
 	Thinking      *ThinkingOptions        `json:"thinking,omitempty"`
+
+	// ReasoningEffort 仅在设置了思考预算时按预算映射写入
+	ReasoningEffort string `json:"reasoning_effort,omitempty"`
+
+	MaxTokens int `json:"max_tokens,omitempty"`
+
+	// Temperature 仅在确定性验证时设为 0，其余情况不发送
+	Temperature *float64 `json:"temperature,omitempty"`
+
+	// Tools 工具定义数组（OpenAI 格式），原样来自 tools_file
+	Tools json.RawMessage `json:"tools,omitempty"`
+
+	// ResponseFormat 仅在配置了 response_schema 时发送，要求模型输出 JSON 对象
+	ResponseFormat *ResponseFormat `json:"response_format,omitempty"`
+}
+
+// ResponseFormat Chat Completions 的输出格式约束，如 {"type": "json_object"}
+type ResponseFormat struct {
+	Type string `json:"type"`
 }
 
 type ResponsesAPIInputItem struct {
@@ -69,6 +89,10 @@ type ResponsesAPIRequest struct {
 	Store        bool                       `json:"store,omitempty"`
 	Stream       bool                       `json:"stream,omitempty"`
 	Reasoning    *ResponsesReasoningOptions `json:"reasoning,omitempty"`
+
+	MaxOutputTokens int `json:"max_output_tokens,omitempty"`
+
+	Temperature *float64 `json:"temperature,omitempty"`
 }
 
 // ChatCompletionResponse represents the response from chat completion
@@ -80,8 +104,9 @@ type ChatCompletionResponse struct {
 	Choices []struct {
 		Index   int `json:"index"`
 		Message struct {
-			Role    string `json:"role"`
-			Content string `json:"content"`
+			Role      string     `json:"role"`
+			Content   string     `json:"content"`
+			ToolCalls []ToolCall `json:"tool_calls,omitempty"`
 		} `json:"message"`
 		FinishReason string `json:"finish_reason"`
 	} `json:"choices"`
@@ -114,6 +139,11 @@ type ResponsesAPIResponse struct {
 		InputTokensDetails  *PromptTokensDetails     `json:"input_tokens_details,omitempty"`
 		OutputTokensDetails *CompletionTokensDetails `json:"output_tokens_details,omitempty"`
 	} `json:"usage"`
+
+	Status            string `json:"status"`
+	IncompleteDetails *struct {
+		Reason string `json:"reason"`
+	} `json:"incomplete_details,omitempty"`
 }
 
 // OpenAIErrorResponse represents OpenAI API error response
@@ -135,8 +165,9 @@ type StreamResponseChunk struct {
 	Choices []struct {
 		Index int `json:"index"`
 		Delta struct {
-			ThinkingContent *string `json:"reasoning_content,omitempty"`
-			Content         string  `json:"content"`
+			ThinkingContent *string    `json:"reasoning_content,omitempty"`
+			Content         string     `json:"content"`
+			ToolCalls       []ToolCall `json:"tool_calls,omitempty"`
 		} `json:"delta"`
 		FinishReason *string `json:"finish_reason"`
 	} `json:"choices"`
@@ -149,6 +180,112 @@ type StreamResponseChunk struct {
 	} `json:"usage,omitempty"`
 }
 
+// choiceContents 按 choices[].index 聚合流式正文与工具调用：n>1 时各 choice 的 chunk 交错到达，index 不一定为 0。
+type choiceContents struct {
+	choices map[int]*choiceContent
+}
+
+// choiceContent 单个 choice 累积的正文、工具调用（按 tool_calls[].index）与结束原因。
+type choiceContent struct {
+	text   strings.Builder
+	calls  map[int]*toolCallContent
+	reason string
+}
+
+type toolCallContent struct {
+	name      string
+	arguments strings.Builder
+}
+
+func (cc *choiceContents) choice(index int) *choiceContent {
+	if cc.choices == nil {
+		cc.choices = make(map[int]*choiceContent)
+	}
+	c, ok := cc.choices[index]
+	if !ok {
+		c = &choiceContent{}
+		cc.choices[index] = c
+	}
+	return c
+}
+
+func (cc *choiceContents) add(index int, content string) {
+	cc.choice(index).text.WriteString(content)
+}
+
+// addToolCall 累积一个工具调用增量：name 取首个非空值，arguments 逐片拼接。
+func (cc *choiceContents) addToolCall(index int, call ToolCall) {
+	c := cc.choice(index)
+	if c.calls == nil {
+		c.calls = make(map[int]*toolCallContent)
+	}
+	tc, ok := c.calls[call.Index]
+	if !ok {
+		tc = &toolCallContent{}
+		c.calls[call.Index] = tc
+	}
+	if tc.name == "" {
+		tc.name = call.Function.Name
+	}
+	tc.arguments.WriteString(call.Function.Arguments)
+}
+
+func (cc *choiceContents) setFinishReason(index int, reason string) {
+	cc.choice(index).reason = reason
+}
+
+// indexes 返回升序排列的 choice index。
+func (cc *choiceContents) indexes() []int {
+	indexes := make([]int, 0, len(cc.choices))
+	for i := range cc.choices {
+		indexes = append(indexes, i)
+	}
+	sort.Ints(indexes)
+	return indexes
+}
+
+// text 按 index 升序拼接各 choice 的正文，工具调用以 name(arguments) 逐行附在正文之后，
+// 多个 choice 之间以空行分隔。
+func (cc *choiceContents) text() string {
+	parts := make([]string, 0, len(cc.choices))
+	for _, i := range cc.indexes() {
+		c := cc.choices[i]
+		lines := []string{c.text.String()}
+		callIndexes := make([]int, 0, len(c.calls))
+		for j := range c.calls {
+			callIndexes = append(callIndexes, j)
+		}
+		sort.Ints(callIndexes)
+		for _, j := range callIndexes {
+			lines = append(lines, formatToolCall(c.calls[j].name, c.calls[j].arguments.String()))
+		}
+		if lines[0] == "" && len(lines) > 1 {
+			lines = lines[1:]
+		}
+		parts = append(parts, strings.Join(lines, "\n"))
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// toolCallCount 返回所有 choice 的工具调用总数。
+func (cc *choiceContents) toolCallCount() int {
+	n := 0
+	for _, c := range cc.choices {
+		n += len(c.calls)
+	}
+	return n
+}
+
+// finishReason 返回 index 最小且带结束原因的 choice 的结束原因。
+func (cc *choiceContents) finishReason() string {
+	for _, i := range cc.indexes() {
+		if reason := cc.choices[i].reason; reason != "" {
+			return reason
+		}
+	}
+	return ""
+}
+
 type ResponsesAPIStreamEvent struct {
 	Type     string                `json:"type"`
 	Delta    string                `json:"delta,omitempty"`
@@ -162,6 +299,32 @@ type ResponsesAPIStreamEvent struct {
 	} `json:"usage,omitempty"`
 }
 
+// finishReason 返回 Responses API 的结束原因：未完成时取 incomplete_details.reason（如 max_output_tokens），
+// 否则取终态 status；仍在进行中的中间事件返回空。
+func (r *ResponsesAPIResponse) finishReason() string {
+	if r.IncompleteDetails != nil && r.IncompleteDetails.Reason != "" {
+		return r.IncompleteDetails.Reason
+	}
+	switch r.Status {
+	case "", "queued", "in_progress":
+		return ""
+	}
+	return r.Status
+}
+
+// outputText 拼接 output 中所有 output_text 内容块的文本（不含推理内容）
+func (r *ResponsesAPIResponse) outputText() string {
+	var b strings.Builder
+	for _, item := range r.Output {
+		for _, content := range item.Content {
+			if content.Type == "output_text" {
+				b.WriteString(content.Text)
+			}
+		}
+	}
+	return b.String()
+}
+
 func extractThinkingTokens(details *CompletionTokensDetails) int {
 	if details == nil {
 		return 0
@@ -179,6 +342,21 @@ func extractCachedInputTokens(details *PromptTokensDetails) int {
 	return details.CachedTokens
 }
 
+// reasoningEffortForBudget 将思考预算（token 数）映射到 OpenAI 的 reasoning effort 档位。
+// 未设置预算时返回 medium，与开启 thinking 的默认行为保持一致。
+func reasoningEffortForBudget(budget int) string {
+	switch {
+	case budget <= 0:
+		return "medium"
+	case budget <= 2048:
+		return "low"
+	case budget <= 8192:
+		return "medium"
+	default:
+		return "high"
+	}
+}
+
 func (c *OpenAIClient) buildRequestBody(systemPrompt, userPrompt string, stream bool) ([]byte, error) {
 	if c.Provider == types.ProtocolOpenAIResponses {
 		reqBody := ResponsesAPIRequest{
@@ -189,9 +367,13 @@ func (c *OpenAIClient) buildRequestBody(systemPrompt, userPrompt string, stream
 			Instructions: systemPrompt,
 			Store:        true,
 			Stream:       stream,
+
+			MaxOutputTokens: c.MaxTokens,
+
+			Temperature: c.Temperature,
 		}
 		if c.Thinking {
-			reqBody.Reasoning = &ResponsesReasoningOptions{Effort: "medium"}
+			reqBody.Reasoning = &ResponsesReasoningOptions{Effort: reasoningEffortForBudget(c.ThinkingBudget)}
 		}
 		return json.Marshal(reqBody)
 	}
@@ -209,9 +391,16 @@ func (c *OpenAIClient) buildRequestBody(systemPrompt, userPrompt string, stream
 	})
 
 	reqBody := ChatCompletionRequest{
-		Model:    c.Model,
-		Messages: messages,
-		Stream:   stream,
+		Model:     c.Model,
+		Messages:  messages,
+		Stream:    stream,
+		MaxTokens: c.MaxTokens,
+		Tools:     c.Tools,
+
+		Temperature: c.Temperature,
+	}
+	if c.JSONResponse {
+		reqBody.ResponseFormat = &ResponseFormat{Type: "json_object"}
 	}
 
 	if stream {
@@ -222,7 +411,11 @@ func (c *OpenAIClient) buildRequestBody(systemPrompt, userPrompt string, stream
 
 	if c.Thinking {
 		reqBody.Thinking = &ThinkingOptions{
-			Type: "enabled",
+			Type:         "enabled",
+			BudgetTokens: c.ThinkingBudget,
+		}
+		if c.ThinkingBudget > 0 {
+			reqBody.ReasoningEffort = reasoningEffortForBudget(c.ThinkingBudget)
 		}
 	}
 
@@ -230,37 +423,34 @@ func (c *OpenAIClient) buildRequestBody(systemPrompt, userPrompt string, stream
 }
 
 func (c *OpenAIClient) parseResponsesStream(resp *http.Response, t0 time.Time, dnsTime, connectTime, tlsTime time.Duration, targetIP string, requestBody []byte) (*ResponseMetrics, error) {
-	scanner := bufio.NewScanner(resp.Body)
 	firstTokenTime := time.Duration(0)
 	gotFirst := false
-	var completionTokens int
-	var promptTokens int
-	var cachedInputTokens int
-	var thinkingTokens int
-	var streamChunks []string
-	var rawResponseBody strings.Builder
-
-	for scanner.Scan() {
-		line := scanner.Text()
-		rawResponseBody.WriteString(line)
-		rawResponseBody.WriteByte('\n')
-		if !strings.HasPrefix(line, "data: ") {
-			continue
-		}
-		data := strings.TrimPrefix(line, "data: ")
+	var usage tokenUsage
+	var finishReason string
+	streamLog := c.logger.NewStreamRecorder()
+	rawResponseBody := logger.NewBodyRecorder() // 原始响应体，受 --log-max-bytes 上限约束
+	var outputText strings.Builder
+	var thinking thinkingTimer
+	chunkLog := newChunkRecorder(t0, c.TokenTraceMaxChunks)
+
+	err := ParseSSE(io.TeeReader(resp.Body, rawResponseBody), func(_, data string) error {
 		if data == "[DONE]" {
-			break
-		}
-		if c.logger != nil && c.logger.IsEnabled() {
-			streamChunks = append(streamChunks, data)
+			return ErrSSEStop
 		}
+		streamLog.Add(data)
 
 		var event ResponsesAPIStreamEvent
 		if err := json.Unmarshal([]byte(data), &event); err != nil {
-			continue
+			return nil
 		}
 
 		if event.Delta != "" {
+			isReasoning := strings.Contains(event.Type, "reasoning")
+			thinking.observe(isReasoning, !isReasoning)
+			if event.Type == "response.output_text.delta" {
+				outputText.WriteString(event.Delta)
+			}
+			chunkLog.observe(event.Delta)
 			if !gotFirst {
 				firstTokenTime = time.Since(t0)
 				gotFirst = true
@@ -268,50 +458,63 @@ func (c *OpenAIClient) parseResponsesStream(resp *http.Response, t0 time.Time, d
 		}
 
 		if event.Usage != nil {
-			promptTokens = event.Usage.InputTokens
-			completionTokens = event.Usage.OutputTokens
-			cachedInputTokens = extractCachedInputTokens(event.Usage.InputTokensDetails)
-			thinkingTokens = extractThinkingTokens(event.Usage.OutputTokensDetails)
+			usage.merge(tokenUsage{
+				Prompt:      event.Usage.InputTokens,
+				Completion:  event.Usage.OutputTokens,
+				CachedInput: extractCachedInputTokens(event.Usage.InputTokensDetails),
+				Thinking:    extractThinkingTokens(event.Usage.OutputTokensDetails),
+			})
 		}
 
 		if event.Response != nil {
-			promptTokens = event.Response.Usage.InputTokens
-			completionTokens = event.Response.Usage.OutputTokens
-			cachedInputTokens = extractCachedInputTokens(event.Response.Usage.InputTokensDetails)
-			thinkingTokens = extractThinkingTokens(event.Response.Usage.OutputTokensDetails)
+			usage.merge(tokenUsage{
+				Prompt:      event.Response.Usage.InputTokens,
+				Completion:  event.Response.Usage.OutputTokens,
+				CachedInput: extractCachedInputTokens(event.Response.Usage.InputTokensDetails),
+				Thinking:    extractThinkingTokens(event.Response.Usage.OutputTokensDetails),
+			})
+			if reason := event.Response.finishReason(); reason != "" {
+				finishReason = reason
+			}
 		}
-	}
-
-	if err := scanner.Err(); err != nil {
+		return nil
+	})
+	if err != nil {
 		if c.logger != nil && c.logger.IsEnabled() {
 			c.logger.Error(c.Model, "Responses stream scanning failed", err)
 		}
 		return nil, err
 	}
+	if u, ok := readTrailerUsage(resp); ok {
+		usage.merge(u)
+	}
 
 	totalTime := time.Since(t0)
 	if c.logger != nil && c.logger.IsEnabled() {
-		c.logger.LogResponse(c.Model, logger.ResponseData{
-			StatusCode:   resp.StatusCode,
-			StreamChunks: streamChunks,
-		})
+		c.logger.LogResponse(c.Model, streamLog.Response(resp.StatusCode))
 	}
 
-	return &ResponseMetrics{
+	metrics := &ResponseMetrics{
 		TimeToFirstToken:  firstTokenTime,
 		TotalTime:         totalTime,
+		ThinkingTime:      thinking.Duration(),
 		DNSTime:           dnsTime,
 		ConnectTime:       connectTime,
 		TLSHandshakeTime:  tlsTime,
 		TargetIP:          targetIP,
-		PromptTokens:      promptTokens,
-		CachedInputTokens: cachedInputTokens,
-		CompletionTokens:  completionTokens,
-		ThinkingTokens:    thinkingTokens,
+		PromptTokens:      usage.Prompt,
+		CachedInputTokens: usage.CachedInput,
+		CompletionTokens:  usage.Completion,
+		ThinkingTokens:    usage.Thinking,
+		FinishReason:      finishReason,
 		RequestBody:       string(requestBody),
 		ResponseBody:      rawResponseBody.String(),
+		ResponseText:      outputText.String(),
+		EmptyContent:      isEmptyContent(outputText.String()),
 		ErrorMessage:      "",
-	}, nil
+	}
+	chunkLog.apply(metrics)
+	return metrics, nil
 }
 
 func (c *OpenAIClient) parseResponsesNonStream(responseData []byte, totalTime, dnsTime, connectTime, tlsTime time.Duration, targetIP string, requestBody []byte) (*ResponseMetrics, error) {
@@ -343,8 +546,11 @@ func (c *OpenAIClient) parseResponsesNonStream(responseData []byte, totalTime, d
 		CachedInputTokens: extractCachedInputTokens(apiResp.Usage.InputTokensDetails),
 		CompletionTokens:  apiResp.Usage.OutputTokens,
 		ThinkingTokens:    extractThinkingTokens(apiResp.Usage.OutputTokensDetails),
+		FinishReason:      apiResp.finishReason(),
 		RequestBody:       string(requestBody),
 		ResponseBody:      string(responseData),
+		ResponseText:      apiResp.outputText(),
+		EmptyContent:      isEmptyContent(apiResp.outputText()),
 		ErrorMessage:      "",
 	}, nil
 }
@@ -358,6 +564,22 @@ type OpenAIClient struct {
 	Provider    string
 	Thinking    bool // 是否开启 thinking 模式
 	logger      *logger.Logger
+
+	ThinkingBudget int // 思考预算 token 数，0 表示不限定
+	MaxTokens      int // 输出 token 上限，0 表示不设置
+
+	Temperature *float64 // 采样温度，nil 表示不设置
+
+	// Tools 工具定义数组（OpenAI 格式），非空时写入 Chat Completions 请求体的 tools 字段
+	Tools json.RawMessage
+
+	// JSONResponse 为 true 时 Chat Completions 请求体带 response_format: {"type": "json_object"}
+	JSONResponse bool
+
+	CompressRequest bool // 是否 gzip 压缩请求体
+	VerifyRequestID bool // 是否注入 X-Request-Id 并校验响应回传
+
+	TokenTraceMaxChunks int // 流式响应记录 chunk 时序的上限，0 表示不记录
 }
 
 // NewOpenAIClient 根据配置创建 OpenAI 客户端
@@ -367,22 +589,35 @@ type OpenAIClient struct {
 //     这对于准确的性能测量至关重要，因为连接复用会跳过 DNS 解析和 TCP 连接建立时间，
 //     导致测量结果不能反映真实的网络性能。在性能基准测试工具中，我们需要测量完整的
 //     网络栈性能，包括 DNS 解析、TCP 连接建立、TLS 握手等。
-//   - DisableCompression=false: 启用压缩以节省带宽
+//   - DisableCompression=false: 启用压缩以节省带宽；设置了 accept_encoding 时由 withResponseEncoding
+//     显式设置 Accept-Encoding 并自行解压，以记录压缩前后的字节数
 func NewOpenAIClient(config types.Input) *OpenAIClient {
 	endpointURL := config.ResolvedEndpointURL()
 	transport := newMeasuredTransport(config)
 
 	return &OpenAIClient{
 		httpClient: &http.Client{
-			Transport: transport,
+			Transport: withRequestCompression(withResponseEncoding(transport, config.AcceptEncoding), config.CompressRequest),
 			Timeout:   config.Timeout,
 		},
 		endpointURL: endpointURL,
 		apiKey:      config.ApiKey,
 		Model:       config.Model,
 		Provider:    config.NormalizedProtocol(),
-		Thinking:    config.Thinking,
+		Thinking:    config.ThinkingEnabled(),
 		logger:      nil,
+
+		ThinkingBudget: config.ThinkingBudget,
+		MaxTokens:      config.MaxTokens,
+
+		Temperature: requestTemperature(config),
+
+		JSONResponse: config.ResponseSchema != "",
+
+		CompressRequest: config.CompressRequest,
+		VerifyRequestID: config.VerifyRequestID,
+
+		TokenTraceMaxChunks: config.TokenTraceMaxChunks,
 	}
 }
 
@@ -391,6 +626,11 @@ func (c *OpenAIClient) SetLogger(l *logger.Logger) {
 	c.logger = l
 }
 
+// CloseIdleConnections 关闭连接池中的空闲连接
+func (c *OpenAIClient) CloseIdleConnections() {
+	c.httpClient.CloseIdleConnections()
+}
+
 // Request 发送 OpenAI 协议请求（支持流式和非流式）
 func (c *OpenAIClient) Request(ctx context.Context, systemPrompt, userPrompt string, stream bool) (*ResponseMetrics, error) {
 	// 记录请求开始日志
@@ -423,12 +663,63 @@ func (c *OpenAIClient) RawRequest(ctx context.Context, rawBody string) (*Respons
 	return c.doRequest(ctx, []byte(rawBody), tmp.Stream)
 }
 
+// BuildRequest 构造与 Request 相同的 HTTP 请求但不发送，返回请求与请求体。
+func (c *OpenAIClient) BuildRequest(ctx context.Context, systemPrompt, userPrompt string, stream bool) (*http.Request, []byte, error) {
+	jsonData, err := c.buildRequestBody(systemPrompt, userPrompt, stream)
+	if err != nil {
+		return nil, nil, err
+	}
+	req, err := c.newHTTPRequest(ctx, jsonData, newRequestTrace(c.VerifyRequestID))
+	return req, jsonData, err
+}
+
+// BuildRawRequest 以原始 JSON 请求体构造 HTTP 请求但不发送。
+func (c *OpenAIClient) BuildRawRequest(ctx context.Context, rawBody string) (*http.Request, []byte, error) {
+	req, err := c.newHTTPRequest(ctx, []byte(rawBody), newRequestTrace(c.VerifyRequestID))
+	return req, []byte(rawBody), err
+}
+
+// newHTTPRequest 构造发往端点的 POST 请求并设置鉴权、内容类型与 trace 请求头
+func (c *OpenAIClient) newHTTPRequest(ctx context.Context, jsonData []byte, rt *requestTrace) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(withRequestTrace(ctx, rt), "POST", c.endpointURL, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.apiKey))
+	rt.setHeaders(req.Header)
+	return req, nil
+}
+
 // doRequest 执行 HTTP 请求并解析响应（支持流式和非流式）
 func (c *OpenAIClient) doRequest(ctx context.Context, jsonData []byte, stream bool) (*ResponseMetrics, error) {
+	rt := newRequestTrace(c.VerifyRequestID)
+	metrics, err := c.send(ctx, jsonData, stream, rt)
+	if metrics != nil {
+		// o 系列等模型拒绝 max_tokens / temperature 时按其参数风格改写请求体，只重试一次
+		if adapted, changes, ok := adaptUnsupportedParams(jsonData, metrics.StatusCode, metrics.ResponseBody); ok {
+			change := strings.Join(changes, ", ")
+			if c.logger != nil && c.logger.IsEnabled() {
+				c.logger.Info(c.Model, "参数不兼容，改写请求体后重试: "+change)
+			}
+			rt = newRequestTrace(c.VerifyRequestID)
+			metrics, err = c.send(ctx, adapted, stream, rt)
+			if metrics != nil {
+				metrics.AdaptedRequest = true
+				metrics.AdaptedParams = change
+			}
+		}
+	}
+	rt.apply(metrics, c.logger, c.Model)
+	return metrics, err
+}
+
+// send 发送一次请求并收集指标，rt 记录本次请求的 trace id。
+func (c *OpenAIClient) send(ctx context.Context, jsonData []byte, stream bool, rt *requestTrace) (*ResponseMetrics, error) {
 	if ctx == nil {
 		ctx = context.Background()
 	}
-	req, err := http.NewRequestWithContext(ctx, "POST", c.endpointURL, bytes.NewBuffer(jsonData))
+	req, err := c.newHTTPRequest(ctx, jsonData, rt)
 	if err != nil {
 		// 记录错误日志
 		if c.logger != nil && c.logger.IsEnabled() {
@@ -448,9 +739,6 @@ func (c *OpenAIClient) doRequest(ctx context.Context, jsonData []byte, stream bo
 		}, err
 	}
 
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.apiKey))
-
 	// 记录请求日志
 	if c.logger != nil && c.logger.IsEnabled() {
 		headers := make(map[string]string)
@@ -474,13 +762,19 @@ func (c *OpenAIClient) doRequest(ctx context.Context, jsonData []byte, stream bo
 	var dnsStart, connectStart, tlsStart time.Time
 	var dnsTime, connectTime, tlsTime time.Duration
 	var targetIP string
+	var resolvedIPs []string
 
+	var t0 time.Time
 	trace := &httptrace.ClientTrace{
+		GotFirstResponseByte: func() {
+			rt.firstByte = time.Since(t0)
+		},
 		DNSStart: func(info httptrace.DNSStartInfo) {
 			dnsStart = time.Now()
 		},
 		DNSDone: func(info httptrace.DNSDoneInfo) {
 			dnsTime = time.Since(dnsStart)
+			resolvedIPs = ipAddrStrings(info.Addrs)
 		},
 		ConnectStart: func(network, addr string) {
 			connectStart = time.Now()
@@ -505,7 +799,7 @@ func (c *OpenAIClient) doRequest(ctx context.Context, jsonData []byte, stream bo
 	}
 
 	req = req.WithContext(httptrace.WithClientTrace(req.Context(), trace))
-	t0 := time.Now()
+	t0 = time.Now()
 
 	if stream {
 		// 流式请求
@@ -524,12 +818,15 @@ func (c *OpenAIClient) doRequest(ctx context.Context, jsonData []byte, stream bo
 				ConnectTime:      connectTime,
 				TLSHandshakeTime: tlsTime,
 				TargetIP:         targetIP,
+				ResolvedIPs:      resolvedIPs,
 				CompletionTokens: 0,
 				RequestBody:      string(jsonData),
 				ErrorMessage:     errorMessage,
+				NetErrorKind:     ClassifyNetError(err),
 			}, err
 		}
 		defer resp.Body.Close()
+		rt.captureResponse(resp)
 
 		if resp.StatusCode != http.StatusOK {
 			responseData, _ := io.ReadAll(resp.Body)
@@ -559,7 +856,7 @@ func (c *OpenAIClient) doRequest(ctx context.Context, jsonData []byte, stream bo
 				errorMessage = fmt.Sprintf("[%s] %s",
 					errorResp.Error.Type, errorResp.Error.Message)
 			}
-			errorMessage = EnhanceErrorMessage(errorMessage)
+			errorMessage = withCompressionHint(EnhanceErrorMessage(errorMessage), c.CompressRequest, resp.StatusCode)
 
 			return &ResponseMetrics{
 				TimeToFirstToken: 0,
@@ -568,27 +865,32 @@ func (c *OpenAIClient) doRequest(ctx context.Context, jsonData []byte, stream bo
 				ConnectTime:      connectTime,
 				TLSHandshakeTime: tlsTime,
 				TargetIP:         targetIP,
+				ResolvedIPs:      resolvedIPs,
 				CompletionTokens: 0,
 				RequestBody:      string(jsonData),
 				ResponseBody:     responseBody,
 				ErrorMessage:     errorMessage,
+				StatusCode:       resp.StatusCode,
+				RetryAfter:       ParseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
 			}, fmt.Errorf("%s", errorMessage)
 		}
 
 		if c.Provider == types.ProtocolOpenAIResponses {
-			return c.parseResponsesStream(resp, t0, dnsTime, connectTime, tlsTime, targetIP, jsonData)
+			metrics, err := c.parseResponsesStream(resp, t0, dnsTime, connectTime, tlsTime, targetIP, jsonData)
+			if metrics != nil {
+				metrics.ResolvedIPs = resolvedIPs
+			}
+			return metrics, err
 		}
 
-		scanner := bufio.NewScanner(resp.Body)
 		firstTokenTime := time.Duration(0)
 		gotFirst := false
-		var fullContent strings.Builder
-		var completionTokens int
-		var promptTokens int
-		var cachedInputTokens int
-		var thinkingTokens int
-		var streamChunks []string // 用于记录所有流式数据块
-		var rawResponseLines strings.Builder
+		var contents choiceContents
+		var usage tokenUsage
+		streamLog := c.logger.NewStreamRecorder()    // 用于记录流式数据块，超过上限后截断
+		rawResponseLines := logger.NewBodyRecorder() // 原始响应体，受 --log-max-bytes 上限约束
+		var thinking thinkingTimer
+		chunkLog := newChunkRecorder(t0, c.TokenTraceMaxChunks)
 
 		// 记录流式响应开始日志
 		if c.logger != nil && c.logger.IsEnabled() {
@@ -603,93 +905,114 @@ func (c *OpenAIClient) doRequest(ctx context.Context, jsonData []byte, stream bo
 			})
 		}
 
-		for scanner.Scan() {
-			line := scanner.Text()
-			rawResponseLines.WriteString(line)
-			rawResponseLines.WriteByte('\n')
-			if strings.HasPrefix(line, "data: ") {
-				data := strings.TrimPrefix(line, "data: ")
-				if data == "[DONE]" {
-					break
-				}
+		err = ParseSSE(io.TeeReader(resp.Body, rawResponseLines), func(_, data string) error {
+			if data == "[DONE]" {
+				return ErrSSEStop
+			}
 
-				// 记录流数据块
-				if c.logger != nil && c.logger.IsEnabled() {
-					streamChunks = append(streamChunks, data)
-				}
+			// 记录流数据块
+			streamLog.Add(data)
 
-				var chunk StreamResponseChunk
-				if err := json.Unmarshal([]byte(data), &chunk); err != nil {
-					continue // 跳过无法解析的行
-				}
+			var chunk StreamResponseChunk
+			if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+				return nil // 跳过无法解析的行
+			}
 
-				if !gotFirst && len(chunk.Choices) > 0 {
-					delta := chunk.Choices[0].Delta
-					// 检查是否有 ThinkingContent 或 Content，任一不为空都算作第一个 token
-					if delta.Content != "" || (delta.ThinkingContent != nil && *delta.ThinkingContent != "") {
-						firstTokenTime = time.Since(t0)
-						gotFirst = true
-					}
+			// 按 index 累积各 choice 的内容；任一 choice 的首个非空 ThinkingContent、Content 或 tool_calls 增量
+			// 都算作第一个 token
+			var chunkText strings.Builder
+			for _, choice := range chunk.Choices {
+				delta := choice.Delta
+				hasThinking := delta.ThinkingContent != nil && *delta.ThinkingContent != ""
+				hasOutput := delta.Content != "" || len(delta.ToolCalls) > 0
+				if !gotFirst && (hasOutput || hasThinking) {
+					firstTokenTime = time.Since(t0)
+					gotFirst = true
 				}
-
-				// 累积内容
-				if len(chunk.Choices) > 0 {
-					fullContent.WriteString(chunk.Choices[0].Delta.Content)
+				thinking.observe(hasThinking, hasOutput)
+				contents.add(choice.Index, delta.Content)
+				chunkText.WriteString(delta.Content)
+				if hasThinking {
+					chunkText.WriteString(*delta.ThinkingContent)
 				}
-
-				// 获取 token 统计信息（通常在最后一个chunk中）
-				if chunk.Usage != nil {
-					promptTokens = chunk.Usage.PromptTokens
-					completionTokens = chunk.Usage.CompletionTokens
-					cachedInputTokens = extractCachedInputTokens(chunk.Usage.PromptTokensDetails)
-					thinkingTokens = extractThinkingTokens(chunk.Usage.CompletionTokensDetails)
+				for _, call := range delta.ToolCalls {
+					contents.addToolCall(choice.Index, call)
+					chunkText.WriteString(call.Function.Arguments)
+				}
+				if reason := choice.FinishReason; reason != nil && *reason != "" {
+					contents.setFinishReason(choice.Index, *reason)
 				}
 			}
-		}
-
-		if err := scanner.Err(); err != nil {
+			chunkLog.observe(chunkText.String())
+
+			// 获取 token 统计信息（通常在最后一个chunk中）；部分服务在流末尾以单独的事件下发裸 usage 对象
+			if chunk.Usage != nil {
+				usage.merge(tokenUsage{
+					Prompt:      chunk.Usage.PromptTokens,
+					Completion:  chunk.Usage.CompletionTokens,
+					CachedInput: extractCachedInputTokens(chunk.Usage.PromptTokensDetails),
+					Thinking:    extractThinkingTokens(chunk.Usage.CompletionTokensDetails),
+				})
+			} else if len(chunk.Choices) == 0 {
+				if u, ok := parseUsage([]byte(data)); ok {
+					usage.merge(u)
+				}
+			}
+			return nil
+		})
+		if err != nil {
 			// 记录扫描错误日志
 			if c.logger != nil && c.logger.IsEnabled() {
 				c.logger.Error(c.Model, "Stream scanning failed", err)
 			}
 			return nil, err
 		}
+		// 部分服务把最终 usage 放在 HTTP trailer 中，以 trailer 为准
+		if u, ok := readTrailerUsage(resp); ok {
+			usage.merge(u)
+		}
 
 		totalTime := time.Since(t0)
 
 		// 记录流式响应完成日志
 		if c.logger != nil && c.logger.IsEnabled() {
-			c.logger.LogResponse(c.Model, logger.ResponseData{
-				StatusCode:   resp.StatusCode,
-				StreamChunks: streamChunks,
-			})
+			c.logger.LogResponse(c.Model, streamLog.Response(resp.StatusCode))
 
 			c.logger.LogTestEnd(c.Model, map[string]interface{}{
 				"total_time":          totalTime.String(),
 				"time_to_first_token": firstTokenTime.String(),
-				"prompt_tokens":       promptTokens,
-				"cached_input_tokens": cachedInputTokens,
-				"completion_tokens":   completionTokens,
-				"thinking_tokens":     thinkingTokens,
-				"full_content":        fullContent.String(),
+				"prompt_tokens":       usage.Prompt,
+				"cached_input_tokens": usage.CachedInput,
+				"completion_tokens":   usage.Completion,
+				"thinking_tokens":     usage.Thinking,
+				"thinking_time":       thinking.Duration().String(),
+				"full_content":        contents.text(),
 			})
 		}
 
-		return &ResponseMetrics{
+		metrics := &ResponseMetrics{
 			TimeToFirstToken:  firstTokenTime,
 			TotalTime:         totalTime,
+			ThinkingTime:      thinking.Duration(),
 			DNSTime:           dnsTime,
 			ConnectTime:       connectTime,
 			TLSHandshakeTime:  tlsTime,
 			TargetIP:          targetIP,
-			PromptTokens:      promptTokens,
-			CachedInputTokens: cachedInputTokens,
-			CompletionTokens:  completionTokens,
-			ThinkingTokens:    thinkingTokens,
+			ResolvedIPs:       resolvedIPs,
+			PromptTokens:      usage.Prompt,
+			CachedInputTokens: usage.CachedInput,
+			CompletionTokens:  usage.Completion,
+			ThinkingTokens:    usage.Thinking,
+			FinishReason:      contents.finishReason(),
+			ToolCallCount:     contents.toolCallCount(),
 			RequestBody:       string(jsonData),
 			ResponseBody:      rawResponseLines.String(),
+			ResponseText:      contents.text(),
+			EmptyContent:      isEmptyContent(contents.text()),
 			ErrorMessage:      "",
-		}, nil
+		}
+		chunkLog.apply(metrics)
+		return metrics, nil
 	} else {
 		// 非流式请求
 		resp, err := c.httpClient.Do(req)
@@ -703,12 +1026,15 @@ func (c *OpenAIClient) doRequest(ctx context.Context, jsonData []byte, stream bo
 				ConnectTime:      connectTime,
 				TLSHandshakeTime: tlsTime,
 				TargetIP:         targetIP,
+				ResolvedIPs:      resolvedIPs,
 				CompletionTokens: 0,
 				RequestBody:      string(jsonData),
 				ErrorMessage:     errorMessage,
+				NetErrorKind:     ClassifyNetError(err),
 			}, err
 		}
 		defer resp.Body.Close()
+		rt.captureResponse(resp)
 
 		if resp.StatusCode != http.StatusOK {
 			responseData, _ := io.ReadAll(resp.Body)
@@ -722,7 +1048,7 @@ func (c *OpenAIClient) doRequest(ctx context.Context, jsonData []byte, stream bo
 				errorMessage = fmt.Sprintf("[%s] %s",
 					errorResp.Error.Type, errorResp.Error.Message)
 			}
-			errorMessage = EnhanceErrorMessage(errorMessage)
+			errorMessage = withCompressionHint(EnhanceErrorMessage(errorMessage), c.CompressRequest, resp.StatusCode)
 
 			return &ResponseMetrics{
 				TimeToFirstToken: 0,
@@ -731,10 +1057,13 @@ func (c *OpenAIClient) doRequest(ctx context.Context, jsonData []byte, stream bo
 				ConnectTime:      connectTime,
 				TLSHandshakeTime: tlsTime,
 				TargetIP:         targetIP,
+				ResolvedIPs:      resolvedIPs,
 				CompletionTokens: 0,
 				RequestBody:      string(jsonData),
 				ResponseBody:     string(responseData),
 				ErrorMessage:     errorMessage,
+				StatusCode:       resp.StatusCode,
+				RetryAfter:       ParseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
 			}, fmt.Errorf("%s", errorMessage)
 		}
 
@@ -751,6 +1080,7 @@ func (c *OpenAIClient) doRequest(ctx context.Context, jsonData []byte, stream bo
 				ConnectTime:      connectTime,
 				TLSHandshakeTime: tlsTime,
 				TargetIP:         targetIP,
+				ResolvedIPs:      resolvedIPs,
 				CompletionTokens: 0,
 				RequestBody:      string(jsonData),
 				ErrorMessage:     fmt.Sprintf("Response body read error: %s", err.Error()),
@@ -771,13 +1101,18 @@ func (c *OpenAIClient) doRequest(ctx context.Context, jsonData []byte, stream bo
 				ConnectTime:      connectTime,
 				TLSHandshakeTime: tlsTime,
 				TargetIP:         targetIP,
+				ResolvedIPs:      resolvedIPs,
 				CompletionTokens: 0,
 				ErrorMessage:     "Empty response body",
 			}, fmt.Errorf("empty response body")
 		}
 
 		if c.Provider == types.ProtocolOpenAIResponses {
-			return c.parseResponsesNonStream(responseData, totalTime, dnsTime, connectTime, tlsTime, targetIP, jsonData)
+			metrics, err := c.parseResponsesNonStream(responseData, totalTime, dnsTime, connectTime, tlsTime, targetIP, jsonData)
+			if metrics != nil {
+				metrics.ResolvedIPs = resolvedIPs
+			}
+			return metrics, err
 		}
 
 		var chatResp ChatCompletionResponse
@@ -793,12 +1128,28 @@ func (c *OpenAIClient) doRequest(ctx context.Context, jsonData []byte, stream bo
 				ConnectTime:      connectTime,
 				TLSHandshakeTime: tlsTime,
 				TargetIP:         targetIP,
+				ResolvedIPs:      resolvedIPs,
 				CompletionTokens: 0,
 				ErrorMessage:     fmt.Sprintf("JSON parsing error: %s", err.Error()),
 			}, err
 		}
 
 		thinkingTokens := extractThinkingTokens(chatResp.Usage.CompletionTokensDetails)
+		var finishReason, responseText string
+		var toolCallCount int
+		if len(chatResp.Choices) > 0 {
+			message := chatResp.Choices[0].Message
+			finishReason = chatResp.Choices[0].FinishReason
+			lines := []string{message.Content}
+			if message.Content == "" {
+				lines = nil
+			}
+			for _, call := range message.ToolCalls {
+				lines = append(lines, formatToolCall(call.Function.Name, call.Function.Arguments))
+			}
+			responseText = strings.Join(lines, "\n")
+			toolCallCount = len(message.ToolCalls)
+		}
 
 		return &ResponseMetrics{
 			TimeToFirstToken:  totalTime, // 非流式模式下，所有token一次性返回，TTFT等于总时间
@@ -807,12 +1158,17 @@ func (c *OpenAIClient) doRequest(ctx context.Context, jsonData []byte, stream bo
 			ConnectTime:       connectTime,
 			TLSHandshakeTime:  tlsTime,
 			TargetIP:          targetIP,
+			ResolvedIPs:       resolvedIPs,
 			PromptTokens:      chatResp.Usage.PromptTokens,
 			CachedInputTokens: extractCachedInputTokens(chatResp.Usage.PromptTokensDetails),
 			CompletionTokens:  chatResp.Usage.CompletionTokens,
 			ThinkingTokens:    thinkingTokens,
+			FinishReason:      finishReason,
+			ToolCallCount:     toolCallCount,
 			RequestBody:       string(jsonData),
 			ResponseBody:      string(responseData),
+			ResponseText:      responseText,
+			EmptyContent:      isEmptyContent(responseText),
 			ErrorMessage:      "",
 		}, nil
 	}
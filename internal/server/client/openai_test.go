@@ -2,16 +2,22 @@ package client
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"reflect"
+	"runtime"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/yinxulai/ait/internal/server/logger"
 	"github.com/yinxulai/ait/internal/server/types"
 )
 
@@ -472,6 +478,9 @@ func TestOpenAIClient_Request_OpenAIResponses_NonStream(t *testing.T) {
 	if metrics.PromptTokens != 12 || metrics.CachedInputTokens != 3 || metrics.CompletionTokens != 7 || metrics.ThinkingTokens != 2 {
 		t.Fatalf("unexpected metrics: %+v", metrics)
 	}
+	if metrics.ResponseText != "hello" {
+		t.Fatalf("ResponseText = %q, want %q", metrics.ResponseText, "hello")
+	}
 }
 
 func TestOpenAIClient_Request_OpenAIResponses_Stream(t *testing.T) {
@@ -507,6 +516,9 @@ func TestOpenAIClient_Request_OpenAIResponses_Stream(t *testing.T) {
 	if metrics.PromptTokens != 10 || metrics.CachedInputTokens != 4 || metrics.CompletionTokens != 6 || metrics.ThinkingTokens != 1 {
 		t.Fatalf("unexpected stream metrics: %+v", metrics)
 	}
+	if metrics.ResponseText != "Hello world" {
+		t.Fatalf("ResponseText = %q, want %q", metrics.ResponseText, "Hello world")
+	}
 }
 
 func TestOpenAIClient_Request_BodyReadError(t *testing.T) {
@@ -920,6 +932,11 @@ func TestOpenAIClient_Request_TTFTAccuracy(t *testing.T) {
 			metrics.TimeToFirstToken, metrics.TotalTime)
 	}
 
+	// 思考阶段应该大约是 30ms（reasoning_content 到首个 content）
+	if metrics.ThinkingTime < 20*time.Millisecond || metrics.ThinkingTime > 60*time.Millisecond {
+		t.Errorf("ThinkingTime should be around 30ms, got %v", metrics.ThinkingTime)
+	}
+
 	t.Logf("Actual timing - TTFT: %v, Total: %v, External total: %v",
 		metrics.TimeToFirstToken, metrics.TotalTime, totalDuration)
 }
@@ -1049,3 +1066,606 @@ func TestOpenAIClient_Request_ErrorHandlingFixes(t *testing.T) {
 		}
 	})
 }
+
+func TestOpenAIClient_Request_ThinkingBudget(t *testing.T) {
+	var body map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		raw, _ := io.ReadAll(r.Body)
+		body = nil
+		_ = json.Unmarshal(raw, &body)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"choices":[{"index":0,"message":{"role":"assistant","content":"ok"}}],"usage":{"prompt_tokens":1,"completion_tokens":1}}`)
+	}))
+	defer server.Close()
+
+	config := createOpenAITestConfig(server.URL, "test-key", "test-model", 5*time.Second, false)
+	config.ThinkingBudget = 4096
+	if _, err := NewOpenAIClient(config).Request(context.Background(), "", "hi", false); err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	thinking, _ := body["thinking"].(map[string]interface{})
+	if thinking == nil || thinking["type"] != "enabled" || thinking["budget_tokens"] != float64(4096) {
+		t.Errorf("thinking = %v, want enabled with budget_tokens 4096", body["thinking"])
+	}
+	if body["reasoning_effort"] != "medium" {
+		t.Errorf("reasoning_effort = %v, want medium", body["reasoning_effort"])
+	}
+
+	// 仅开启 thinking 而不设预算时，不写入 reasoning_effort
+	if _, err := NewOpenAIClient(createOpenAITestConfig(server.URL, "test-key", "test-model", 5*time.Second, true)).Request(context.Background(), "", "hi", false); err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if _, ok := body["reasoning_effort"]; ok {
+		t.Errorf("reasoning_effort should be omitted without budget, got %v", body["reasoning_effort"])
+	}
+}
+
+func TestReasoningEffortForBudget(t *testing.T) {
+	tests := []struct {
+		budget int
+		want   string
+	}{
+		{0, "medium"},
+		{1024, "low"},
+		{2048, "low"},
+		{4096, "medium"},
+		{16384, "high"},
+	}
+	for _, tt := range tests {
+		if got := reasoningEffortForBudget(tt.budget); got != tt.want {
+			t.Errorf("reasoningEffortForBudget(%d) = %q, want %q", tt.budget, got, tt.want)
+		}
+	}
+}
+
+func TestOpenAIClient_Request_FinishReason(t *testing.T) {
+	tests := []struct {
+		name      string
+		responses bool
+		stream    bool
+		body      string
+		want      string
+	}{
+		{
+			name: "chat non-stream",
+			body: `{"choices":[{"index":0,"message":{"role":"assistant","content":"hi"},"finish_reason":"length"}],"usage":{"prompt_tokens":3,"completion_tokens":2}}`,
+			want: "length",
+		},
+		{
+			name:   "chat stream",
+			stream: true,
+			body: "data: {\"choices\":[{\"index\":0,\"delta\":{\"content\":\"hi\"},\"finish_reason\":null}]}\n\n" +
+				"data: {\"choices\":[{\"index\":0,\"delta\":{},\"finish_reason\":\"stop\"}]}\n\n" +
+				"data: [DONE]\n\n",
+			want: "stop",
+		},
+		{
+			name:      "responses incomplete",
+			responses: true,
+			body:      `{"status":"incomplete","incomplete_details":{"reason":"max_output_tokens"},"usage":{"input_tokens":3,"output_tokens":2}}`,
+			want:      "max_output_tokens",
+		},
+		{
+			name:      "responses stream completed",
+			responses: true,
+			stream:    true,
+			body: "data: {\"type\":\"response.created\",\"response\":{\"status\":\"in_progress\"}}\n\n" +
+				"data: {\"type\":\"response.output_text.delta\",\"delta\":\"hi\"}\n\n" +
+				"data: {\"type\":\"response.completed\",\"response\":{\"status\":\"completed\",\"usage\":{\"input_tokens\":3,\"output_tokens\":2}}}\n\n",
+			want: "completed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, tt.body)
+			}))
+			defer server.Close()
+
+			config := createOpenAITestConfig(server.URL, "test-key", "gpt-4o", 30*time.Second, false)
+			if tt.responses {
+				config = createOpenAIResponsesTestConfig(server.URL, "test-key", "gpt-4o", 30*time.Second, false)
+			}
+			metrics, err := NewOpenAIClient(config).Request(context.Background(), "", "hello", tt.stream)
+			if err != nil {
+				t.Fatalf("Request() unexpected error: %v", err)
+			}
+			if metrics.FinishReason != tt.want {
+				t.Errorf("FinishReason = %q, want %q", metrics.FinishReason, tt.want)
+			}
+		})
+	}
+}
+
+func TestOpenAIClient_BuildRequestBody_MaxTokens(t *testing.T) {
+	config := createOpenAITestConfig("http://localhost", "test-key", "gpt-4o", 30*time.Second, false)
+	body, err := NewOpenAIClient(config).buildRequestBody("", "hi", false)
+	if err != nil {
+		t.Fatalf("buildRequestBody: %v", err)
+	}
+	if strings.Contains(string(body), "max_tokens") {
+		t.Errorf("max_tokens should be omitted when not configured: %s", body)
+	}
+
+	config.MaxTokens = 256
+	body, _ = NewOpenAIClient(config).buildRequestBody("", "hi", false)
+	if !strings.Contains(string(body), `"max_tokens":256`) {
+		t.Errorf("chat completions body missing max_tokens: %s", body)
+	}
+
+	responses := createOpenAIResponsesTestConfig("http://localhost", "test-key", "gpt-4.1", 30*time.Second, false)
+	responses.MaxTokens = 256
+	body, _ = NewOpenAIClient(responses).buildRequestBody("", "hi", false)
+	if !strings.Contains(string(body), `"max_output_tokens":256`) {
+		t.Errorf("responses body missing max_output_tokens: %s", body)
+	}
+}
+
+func TestOpenAIClient_BuildRequestBody_Temperature(t *testing.T) {
+	config := createOpenAITestConfig("http://localhost", "test-key", "gpt-4o", 30*time.Second, false)
+	body, _ := NewOpenAIClient(config).buildRequestBody("", "hi", false)
+	if strings.Contains(string(body), "temperature") {
+		t.Errorf("temperature should be omitted unless consistency_check is set: %s", body)
+	}
+
+	config.ConsistencyCheck = true
+	body, _ = NewOpenAIClient(config).buildRequestBody("", "hi", false)
+	if !strings.Contains(string(body), `"temperature":0`) {
+		t.Errorf("chat completions body missing temperature: %s", body)
+	}
+
+	responses := createOpenAIResponsesTestConfig("http://localhost", "test-key", "gpt-4.1", 30*time.Second, false)
+	responses.ConsistencyCheck = true
+	body, _ = NewOpenAIClient(responses).buildRequestBody("", "hi", false)
+	if !strings.Contains(string(body), `"temperature":0`) {
+		t.Errorf("responses body missing temperature: %s", body)
+	}
+
+	anthropic := createTestConfig("http://localhost", "test-key", "claude-sonnet-4", 30*time.Second, false)
+	anthropic.ConsistencyCheck = true
+	body, _ = NewAnthropicClient(anthropic).buildRequestBody("", "hi", false)
+	if !strings.Contains(string(body), `"temperature":0`) {
+		t.Errorf("anthropic body missing temperature: %s", body)
+	}
+}
+
+func TestOpenAIClient_Request_StreamMultipleChoices(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+		flush := func() {
+			if flusher, ok := w.(http.Flusher); ok {
+				flusher.Flush()
+			}
+		}
+
+		// index 0 只有角色信息，首个内容来自 index 1
+		fmt.Fprint(w, "data: {\"choices\":[{\"index\":0,\"delta\":{\"role\":\"assistant\"}}]}\n\n")
+		flush()
+		time.Sleep(50 * time.Millisecond)
+		fmt.Fprint(w, "data: {\"choices\":[{\"index\":1,\"delta\":{\"content\":\"B1\"}}]}\n\n")
+		flush()
+
+		// 之后两个 choice 乱序交错，且同一 chunk 内携带多个 index
+		time.Sleep(100 * time.Millisecond)
+		fmt.Fprint(w, "data: {\"choices\":[{\"index\":0,\"delta\":{\"content\":\"A1\"}}]}\n\n")
+		fmt.Fprint(w, "data: {\"choices\":[{\"index\":1,\"delta\":{\"content\":\"B2\"},\"finish_reason\":\"length\"},{\"index\":0,\"delta\":{\"content\":\"A2\"},\"finish_reason\":\"stop\"}]}\n\n")
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	client := NewOpenAIClient(createOpenAITestConfig(server.URL, "test-key", "gpt-4o", 30*time.Second, false))
+	metrics, err := client.Request(context.Background(), "", "hello", true)
+	if err != nil {
+		t.Fatalf("Request() error = %v", err)
+	}
+
+	if metrics.ResponseText != "A1A2\n\nB1B2" {
+		t.Errorf("ResponseText = %q, want %q", metrics.ResponseText, "A1A2\n\nB1B2")
+	}
+	if metrics.FinishReason != "stop" {
+		t.Errorf("FinishReason = %q, want index 0 的 %q", metrics.FinishReason, "stop")
+	}
+	if metrics.TimeToFirstToken < 50*time.Millisecond || metrics.TimeToFirstToken >= 140*time.Millisecond {
+		t.Errorf("TimeToFirstToken = %v, want 取 index 1 首个内容的时间（约 50ms）", metrics.TimeToFirstToken)
+	}
+}
+
+func TestOpenAIClient_Request_StreamTokenChunks(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "data: {\"choices\":[{\"index\":0,\"delta\":{\"role\":\"assistant\"}}]}\n\n")
+		fmt.Fprint(w, "data: {\"choices\":[{\"index\":0,\"delta\":{\"content\":\"你好\"}}]}\n\n")
+		fmt.Fprint(w, "data: {\"choices\":[{\"index\":0,\"delta\":{\"content\":\"hello world\"}}]}\n\n")
+		fmt.Fprint(w, "data: {\"choices\":[{\"index\":0,\"delta\":{\"content\":\"!\"}}]}\n\n")
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	config := createOpenAITestConfig(server.URL, "test-key", "gpt-4o", 30*time.Second, false)
+	config.TokenTraceMaxChunks = 2
+	metrics, err := NewOpenAIClient(config).Request(context.Background(), "", "hello", true)
+	if err != nil {
+		t.Fatalf("Request() error = %v", err)
+	}
+
+	// 只有角色信息的 chunk 不记录；第 3 个内容 chunk 超出上限被截断
+	if len(metrics.TokenChunks) != 2 || !metrics.TokenChunksTruncated {
+		t.Fatalf("TokenChunks = %+v truncated=%v, want 2 chunks and truncated", metrics.TokenChunks, metrics.TokenChunksTruncated)
+	}
+	first, second := metrics.TokenChunks[0], metrics.TokenChunks[1]
+	if first.Chars != 2 || first.Tokens != 2 || second.Chars != 11 || second.Tokens != 3 {
+		t.Errorf("chunks = %+v, want 2 chars/2 tokens and 11 chars/3 tokens", metrics.TokenChunks)
+	}
+	if first.Offset <= 0 || second.Offset < first.Offset {
+		t.Errorf("chunk offsets = %v, %v, want increasing and > 0", first.Offset, second.Offset)
+	}
+
+	// 未开启时不记录
+	metrics, err = NewOpenAIClient(createOpenAITestConfig(server.URL, "test-key", "gpt-4o", 30*time.Second, false)).Request(context.Background(), "", "hello", true)
+	if err != nil {
+		t.Fatalf("Request() error = %v", err)
+	}
+	if metrics.TokenChunks != nil || metrics.TokenChunksTruncated {
+		t.Errorf("TokenChunks = %+v, want none when token trace is off", metrics.TokenChunks)
+	}
+}
+
+func TestOpenAIClient_Request_StreamToolCalls(t *testing.T) {
+	var gotTools json.RawMessage
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Tools json.RawMessage `json:"tools"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		gotTools = body.Tools
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+		// 响应只有 tool_calls 增量，没有 content
+		fmt.Fprint(w, "data: {\"choices\":[{\"index\":0,\"delta\":{\"role\":\"assistant\",\"content\":null,\"tool_calls\":[{\"index\":0,\"id\":\"call_1\",\"type\":\"function\",\"function\":{\"name\":\"get_weather\",\"arguments\":\"\"}}]}}]}\n\n")
+		fmt.Fprint(w, "data: {\"choices\":[{\"index\":0,\"delta\":{\"tool_calls\":[{\"index\":0,\"function\":{\"arguments\":\"{\\\"city\\\":\"}}]}}]}\n\n")
+		fmt.Fprint(w, "data: {\"choices\":[{\"index\":0,\"delta\":{\"tool_calls\":[{\"index\":0,\"function\":{\"arguments\":\"\\\"北京\\\"}\"}}]}}]}\n\n")
+		fmt.Fprint(w, "data: {\"choices\":[{\"index\":0,\"delta\":{\"tool_calls\":[{\"index\":1,\"id\":\"call_2\",\"type\":\"function\",\"function\":{\"name\":\"get_time\",\"arguments\":\"{}\"}}]},\"finish_reason\":\"tool_calls\"}]}\n\n")
+		fmt.Fprint(w, "data: {\"choices\":[],\"usage\":{\"prompt_tokens\":30,\"completion_tokens\":0}}\n\n")
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	client := NewOpenAIClient(createOpenAITestConfig(server.URL, "test-key", "gpt-4o", 30*time.Second, false))
+	client.Tools = json.RawMessage(`[{"type":"function","function":{"name":"get_weather"}}]`)
+	metrics, err := client.Request(context.Background(), "", "hello", true)
+	if err != nil {
+		t.Fatalf("Request() error = %v", err)
+	}
+
+	if string(gotTools) != `[{"type":"function","function":{"name":"get_weather"}}]` {
+		t.Errorf("request tools = %s", gotTools)
+	}
+	if metrics.ToolCallCount != 2 {
+		t.Errorf("ToolCallCount = %d, want 2", metrics.ToolCallCount)
+	}
+	if want := "get_weather({\"city\":\"北京\"})\nget_time({})"; metrics.ResponseText != want {
+		t.Errorf("ResponseText = %q, want %q", metrics.ResponseText, want)
+	}
+	if metrics.TimeToFirstToken <= 0 {
+		t.Error("tool_calls 增量应计为首 token")
+	}
+	if metrics.FinishReason != "tool_calls" {
+		t.Errorf("FinishReason = %q, want tool_calls", metrics.FinishReason)
+	}
+	if !metrics.HasOutput() {
+		t.Error("tool-call-only response should count as output")
+	}
+}
+
+func TestOpenAIClient_Request_NonStreamToolCalls(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"choices":[{"index":0,"message":{"role":"assistant","content":null,"tool_calls":[{"id":"call_1","type":"function","function":{"name":"get_weather","arguments":"{\"city\":\"上海\"}"}}]},"finish_reason":"tool_calls"}],"usage":{"prompt_tokens":30,"completion_tokens":12}}`)
+	}))
+	defer server.Close()
+
+	client := NewOpenAIClient(createOpenAITestConfig(server.URL, "test-key", "gpt-4o", 30*time.Second, false))
+	metrics, err := client.Request(context.Background(), "", "hello", false)
+	if err != nil {
+		t.Fatalf("Request() error = %v", err)
+	}
+	if metrics.ToolCallCount != 1 || metrics.ResponseText != `get_weather({"city":"上海"})` {
+		t.Errorf("ToolCallCount = %d, ResponseText = %q", metrics.ToolCallCount, metrics.ResponseText)
+	}
+}
+
+func TestOpenAIClient_Request_EmptyContent(t *testing.T) {
+	content := `""`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"choices":[{"index":0,"message":{"role":"assistant","content":%s},"finish_reason":"content_filter"}],"usage":{"prompt_tokens":30,"completion_tokens":5}}`, content)
+	}))
+	defer server.Close()
+
+	client := NewOpenAIClient(createOpenAITestConfig(server.URL, "test-key", "gpt-4o", 30*time.Second, false))
+	metrics, err := client.Request(context.Background(), "", "hello", false)
+	if err != nil {
+		t.Fatalf("Request() error = %v", err)
+	}
+	if !metrics.EmptyContent || metrics.ErrorMessage != "" || !metrics.HasOutput() {
+		t.Errorf("EmptyContent = %v, ErrorMessage = %q, HasOutput = %v", metrics.EmptyContent, metrics.ErrorMessage, metrics.HasOutput())
+	}
+
+	content = `"hi"`
+	metrics, err = client.Request(context.Background(), "", "hello", false)
+	if err != nil {
+		t.Fatalf("Request() error = %v", err)
+	}
+	if metrics.EmptyContent {
+		t.Error("non-empty response should not be marked EmptyContent")
+	}
+}
+
+func TestOpenAIClient_Request_ResponseFormat(t *testing.T) {
+	var gotFormat json.RawMessage
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			ResponseFormat json.RawMessage `json:"response_format"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		gotFormat = body.ResponseFormat
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"choices":[{"message":{"role":"assistant","content":"{\"ok\":true}"},"finish_reason":"stop"}],"usage":{"prompt_tokens":5,"completion_tokens":3}}`)
+	}))
+	defer server.Close()
+
+	config := createOpenAITestConfig(server.URL, "test-key", "gpt-4o", 30*time.Second, false)
+	for _, schema := range []string{"", "schema.json"} {
+		gotFormat = nil
+		config.ResponseSchema = schema
+		metrics, err := NewOpenAIClient(config).Request(context.Background(), "", "hello", false)
+		if err != nil {
+			t.Fatalf("Request() error = %v", err)
+		}
+		want := ""
+		if schema != "" {
+			want = `{"type":"json_object"}`
+		}
+		if string(gotFormat) != want {
+			t.Errorf("response_schema=%q: request response_format = %s, want %q", schema, gotFormat, want)
+		}
+		if metrics.ResponseText != `{"ok":true}` {
+			t.Errorf("ResponseText = %q", metrics.ResponseText)
+		}
+	}
+}
+
+func TestOpenAIClient_Request_AdaptsUnsupportedParams(t *testing.T) {
+	var bodies []map[string]any
+	alwaysReject := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		bodies = append(bodies, body)
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case body["max_tokens"] != nil || alwaysReject:
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, maxTokensUnsupportedBody)
+		case body["temperature"] != nil:
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, temperatureUnsupportedBody)
+		default:
+			fmt.Fprint(w, `{"choices":[{"message":{"role":"assistant","content":"ok"},"finish_reason":"stop"}],"usage":{"prompt_tokens":5,"completion_tokens":3}}`)
+		}
+	}))
+	defer server.Close()
+
+	config := createOpenAITestConfig(server.URL, "test-key", "o1", 30*time.Second, false)
+	config.MaxTokens = 128
+	config.ConsistencyCheck = true // 确定性验证会发送 temperature: 0
+	metrics, err := NewOpenAIClient(config).Request(context.Background(), "", "hello", false)
+	if err != nil {
+		t.Fatalf("Request() error = %v", err)
+	}
+	if len(bodies) != 2 || bodies[1]["max_completion_tokens"] != 128.0 {
+		t.Fatalf("requests = %v, want one adapted retry with max_completion_tokens", bodies)
+	}
+	if !metrics.AdaptedRequest || metrics.AdaptedParams != "max_tokens→max_completion_tokens, -temperature" || metrics.CompletionTokens != 3 {
+		t.Errorf("metrics = AdaptedRequest %v, AdaptedParams %q, CompletionTokens %d", metrics.AdaptedRequest, metrics.AdaptedParams, metrics.CompletionTokens)
+	}
+
+	// 改写后仍返回 400 时不再重试
+	bodies = nil
+	alwaysReject = true
+	metrics, err = NewOpenAIClient(config).Request(context.Background(), "", "hello", false)
+	if err == nil || metrics.StatusCode != http.StatusBadRequest {
+		t.Fatalf("Request() error = %v, StatusCode = %d, want 400", err, metrics.StatusCode)
+	}
+	if len(bodies) != 2 || !metrics.AdaptedRequest {
+		t.Errorf("requests = %d, AdaptedRequest = %v, want a single retry", len(bodies), metrics.AdaptedRequest)
+	}
+}
+
+func TestNewClient_ToolsFile(t *testing.T) {
+	dir := t.TempDir()
+	valid := filepath.Join(dir, "tools.json")
+	if err := os.WriteFile(valid, []byte("[\n  {\"type\": \"function\", \"function\": {\"name\": \"get_weather\"}}\n]\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	config := createOpenAITestConfig("http://localhost", "test-key", "gpt-4o", time.Second, false)
+	config.ToolsFile = valid
+	c, err := NewClient(config, nil)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	if got := string(c.(*OpenAIClient).Tools); got != `[{"type":"function","function":{"name":"get_weather"}}]` {
+		t.Errorf("Tools = %s", got)
+	}
+
+	for name, content := range map[string]string{
+		"object.json":  `{"type":"function"}`,
+		"empty.json":   `[]`,
+		"no-type.json": `[{"function":{"name":"f"}}]`,
+	} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadTools(path); err == nil {
+			t.Errorf("LoadTools(%s) should fail", name)
+		}
+	}
+	if _, err := LoadTools(filepath.Join(dir, "missing.json")); err == nil {
+		t.Error("LoadTools should fail for a missing file")
+	}
+}
+
+// streamResponseLog 读取 dir 下日志文件中的 HTTP Response 记录。
+func streamResponseLog(t *testing.T, dir string) logger.ResponseData {
+	t.Helper()
+	files, _ := filepath.Glob(filepath.Join(dir, "ait-*.log"))
+	if len(files) != 1 {
+		t.Fatalf("log files = %v", files)
+	}
+	data, err := os.ReadFile(files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		var entry struct {
+			Message string              `json:"message"`
+			Details logger.ResponseData `json:"details"`
+		}
+		if json.Unmarshal([]byte(line), &entry) == nil && entry.Message == "HTTP Response" {
+			return entry.Details
+		}
+	}
+	t.Fatalf("no HTTP Response entry in log:\n%s", data)
+	return logger.ResponseData{}
+}
+
+func TestOpenAIClient_Request_StreamLogTruncated(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	logger.SetStreamLimits(5, 0)
+	defer logger.SetStreamLimits(logger.DefaultMaxStreamChunks, logger.DefaultMaxStreamBytes)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for i := 0; i < 50; i++ {
+			fmt.Fprintf(w, "data: {\"choices\":[{\"index\":0,\"delta\":{\"content\":\"t%d\"}}]}\n\n", i)
+		}
+		fmt.Fprint(w, "data: {\"choices\":[],\"usage\":{\"prompt_tokens\":3,\"completion_tokens\":50}}\n\ndata: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	l := logger.New(true)
+	defer l.Close()
+	c := NewOpenAIClient(createOpenAITestConfig(server.URL, "k", "m", 5*time.Second, false))
+	c.SetLogger(l)
+	metrics, err := c.Request(context.Background(), "", "hi", true)
+	if err != nil {
+		t.Fatalf("Request error: %v", err)
+	}
+	if metrics.CompletionTokens != 50 {
+		t.Errorf("CompletionTokens = %d, truncating the log must not affect metrics", metrics.CompletionTokens)
+	}
+	resp := streamResponseLog(t, dir)
+	if len(resp.StreamChunks) != 5 || !resp.StreamTruncated || resp.StreamDroppedChunks != 46 {
+		t.Errorf("logged chunks=%d truncated=%v dropped=%d", len(resp.StreamChunks), resp.StreamTruncated, resp.StreamDroppedChunks)
+	}
+}
+
+// TestOpenAIClient_Request_StreamRawBodyMemoryBounded 超大流响应下原始响应体按 --log-max-bytes 截断，
+// 请求期间的堆内存峰值不随流大小增长。
+func TestOpenAIClient_Request_StreamRawBodyMemoryBounded(t *testing.T) {
+	const chunkCount = 20000 // 每个事件约 4 KiB，共约 80 MiB
+	logger.SetStreamLimits(logger.DefaultMaxStreamChunks, logger.DefaultMaxStreamBytes)
+	pad := strings.Repeat("x", 4<<10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for i := 0; i < chunkCount; i++ {
+			fmt.Fprintf(w, "data: {\"choices\":[{\"index\":0,\"delta\":{\"content\":\"t\"}}],\"pad\":\"%s\"}\n\n", pad)
+		}
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	var before runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	var peak atomic.Uint64
+	stop := make(chan struct{})
+	sampled := make(chan struct{})
+	go func() {
+		defer close(sampled)
+		var m runtime.MemStats
+		for {
+			runtime.ReadMemStats(&m)
+			if m.HeapAlloc > peak.Load() {
+				peak.Store(m.HeapAlloc)
+			}
+			select {
+			case <-stop:
+				return
+			case <-time.After(5 * time.Millisecond):
+			}
+		}
+	}()
+
+	c := NewOpenAIClient(createOpenAITestConfig(server.URL, "k", "m", 30*time.Second, false))
+	metrics, err := c.Request(context.Background(), "", "hi", true)
+	close(stop)
+	<-sampled
+	if err != nil {
+		t.Fatalf("Request error: %v", err)
+	}
+	if len(metrics.ResponseBody) > logger.DefaultMaxStreamBytes {
+		t.Errorf("ResponseBody = %d bytes, want at most %d", len(metrics.ResponseBody), logger.DefaultMaxStreamBytes)
+	}
+	if grown := int64(peak.Load()) - int64(before.HeapAlloc); grown > 32<<20 {
+		t.Errorf("peak heap grew by %d bytes while streaming ~80 MiB, want under 32 MiB", grown)
+	}
+}
+
+func TestOpenAIClient_Request_StreamUsageFromTrailer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Trailer", "X-Usage")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprint(w, "data: {\"choices\":[{\"index\":0,\"delta\":{\"content\":\"hello\"}}]}\n\n")
+		fmt.Fprint(w, "data: [DONE]\n\n")
+		w.Header().Set("X-Usage", `{"prompt_tokens":11,"completion_tokens":22,"prompt_tokens_details":{"cached_tokens":5}}`)
+	}))
+	defer server.Close()
+
+	client := NewOpenAIClient(createOpenAITestConfig(server.URL, "test-key", "test-model", 5*time.Second, false))
+	metrics, err := client.Request(context.Background(), "", "hi", true)
+	if err != nil {
+		t.Fatalf("Request() unexpected error: %v", err)
+	}
+	if metrics.PromptTokens != 11 || metrics.CompletionTokens != 22 || metrics.CachedInputTokens != 5 {
+		t.Errorf("tokens = %d/%d/%d, want 11/22/5 from trailer", metrics.PromptTokens, metrics.CompletionTokens, metrics.CachedInputTokens)
+	}
+}
+
+func TestOpenAIClient_Request_StreamUsageEvent(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprint(w, "data: {\"choices\":[{\"index\":0,\"delta\":{\"content\":\"hello\"}}]}\n\n")
+		// 末尾单独下发的裸 usage 事件，之后又有一个空 usage，不应把统计清零
+		fmt.Fprint(w, "event: usage\ndata: {\"prompt_tokens\":7,\"completion_tokens\":3}\n\n")
+		fmt.Fprint(w, "data: {\"choices\":[],\"usage\":{\"prompt_tokens\":0,\"completion_tokens\":0}}\n\n")
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	client := NewOpenAIClient(createOpenAITestConfig(server.URL, "test-key", "test-model", 5*time.Second, false))
+	metrics, err := client.Request(context.Background(), "", "hi", true)
+	if err != nil {
+		t.Fatalf("Request() unexpected error: %v", err)
+	}
+	if metrics.PromptTokens != 7 || metrics.CompletionTokens != 3 {
+		t.Errorf("tokens = %d/%d, want 7/3", metrics.PromptTokens, metrics.CompletionTokens)
+	}
+}
@@ -0,0 +1,76 @@
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// 参数不兼容错误的 error.code（OpenAI o 系列等推理模型拒绝 max_tokens、temperature 时返回）
+const (
+	errCodeUnsupportedParameter = "unsupported_parameter"
+	errCodeUnsupportedValue     = "unsupported_value"
+)
+
+// oSeriesDroppedParams o 系列模型只接受默认值、带上即报错的采样参数，适配时从请求体中去掉
+var oSeriesDroppedParams = []string{"temperature", "top_p", "presence_penalty", "frequency_penalty"}
+
+// unsupportedParamPattern 错误消息未带 param 字段时，从 "Unsupported parameter: 'max_tokens' ..." 中提取参数名
+var unsupportedParamPattern = regexp.MustCompile(`(?i)unsupported (?:parameter|value): '([a-z_]+)'`)
+
+// adaptUnsupportedParams 识别 400 响应中 max_tokens / 采样参数不被支持的错误，按 o 系列的参数风格改写请求体：
+// max_tokens 改名为 max_completion_tokens，去掉 oSeriesDroppedParams 中出现的参数。一次改写所有已知不兼容的字段，
+// 避免逐个报错逐个重试。返回改写后的请求体与改动说明；不是此类错误或请求体无需改动时 ok 为 false。
+func adaptUnsupportedParams(body []byte, statusCode int, responseBody string) (adapted []byte, changes []string, ok bool) {
+	if statusCode != http.StatusBadRequest {
+		return nil, nil, false
+	}
+	param := unsupportedParam(responseBody)
+	if param != "max_tokens" && !slices.Contains(oSeriesDroppedParams, param) {
+		return nil, nil, false
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return nil, nil, false
+	}
+	if value, exists := fields["max_tokens"]; exists {
+		if _, renamed := fields["max_completion_tokens"]; !renamed {
+			fields["max_completion_tokens"] = value
+		}
+		delete(fields, "max_tokens")
+		changes = append(changes, "max_tokens→max_completion_tokens")
+	}
+	for _, name := range oSeriesDroppedParams {
+		if _, exists := fields[name]; exists {
+			delete(fields, name)
+			changes = append(changes, "-"+name)
+		}
+	}
+	if len(changes) == 0 {
+		return nil, nil, false
+	}
+	adapted, err := json.Marshal(fields)
+	if err != nil {
+		return nil, nil, false
+	}
+	return adapted, changes, true
+}
+
+// unsupportedParam 从 OpenAI 错误响应中取出不被支持的参数名，不是参数不兼容错误时返回空字符串。
+func unsupportedParam(responseBody string) string {
+	var errorResp OpenAIErrorResponse
+	if err := json.Unmarshal([]byte(responseBody), &errorResp); err != nil {
+		return ""
+	}
+	e := errorResp.Error
+	if e.Param != "" && (e.Code == errCodeUnsupportedParameter || e.Code == errCodeUnsupportedValue) {
+		return e.Param
+	}
+	if m := unsupportedParamPattern.FindStringSubmatch(e.Message); m != nil {
+		return strings.ToLower(m[1])
+	}
+	return ""
+}
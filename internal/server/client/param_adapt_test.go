@@ -0,0 +1,56 @@
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+// OpenAI o 系列模型对 max_tokens / temperature 返回的 400 响应样例
+const (
+	maxTokensUnsupportedBody   = `{"error":{"message":"Unsupported parameter: 'max_tokens' is not supported with this model. Use 'max_completion_tokens' instead.","type":"invalid_request_error","param":"max_tokens","code":"unsupported_parameter"}}`
+	temperatureUnsupportedBody = `{"error":{"message":"Unsupported value: 'temperature' does not support 0 with this model. Only the default (1) value is supported.","type":"invalid_request_error","param":"temperature","code":"unsupported_value"}}`
+)
+
+func TestAdaptUnsupportedParams(t *testing.T) {
+	body := []byte(`{"model":"o1","messages":[],"max_tokens":256,"temperature":0}`)
+	tests := []struct {
+		name        string
+		status      int
+		response    string
+		wantOK      bool
+		wantChanges []string
+	}{
+		{"max_tokens", http.StatusBadRequest, maxTokensUnsupportedBody, true, []string{"max_tokens→max_completion_tokens", "-temperature"}},
+		{"temperature", http.StatusBadRequest, temperatureUnsupportedBody, true, []string{"max_tokens→max_completion_tokens", "-temperature"}},
+		{"message only", http.StatusBadRequest, `{"error":{"message":"Unsupported parameter: 'max_tokens' is not supported with this model.","type":"invalid_request_error"}}`, true, []string{"max_tokens→max_completion_tokens", "-temperature"}},
+		{"other param", http.StatusBadRequest, `{"error":{"message":"Unsupported parameter: 'tools'","param":"tools","code":"unsupported_parameter"}}`, false, nil},
+		{"other error", http.StatusBadRequest, `{"error":{"message":"context length exceeded","code":"context_length_exceeded"}}`, false, nil},
+		{"not 400", http.StatusInternalServerError, maxTokensUnsupportedBody, false, nil},
+		{"not json", http.StatusBadRequest, "Bad Request", false, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			adapted, changes, ok := adaptUnsupportedParams(body, tt.status, tt.response)
+			if ok != tt.wantOK || !reflect.DeepEqual(changes, tt.wantChanges) {
+				t.Fatalf("adaptUnsupportedParams = %v, %q, want %v, %q", ok, changes, tt.wantOK, tt.wantChanges)
+			}
+			if !ok {
+				return
+			}
+			var fields map[string]any
+			if err := json.Unmarshal(adapted, &fields); err != nil {
+				t.Fatalf("adapted body is not JSON: %v", err)
+			}
+			if fields["max_completion_tokens"] != 256.0 || fields["max_tokens"] != nil || fields["temperature"] != nil || fields["model"] != "o1" {
+				t.Errorf("adapted body = %s", adapted)
+			}
+		})
+	}
+
+	// 请求体里没有可改的字段时不重试
+	if _, _, ok := adaptUnsupportedParams([]byte(`{"model":"o1","max_completion_tokens":10}`), http.StatusBadRequest, maxTokensUnsupportedBody); ok {
+		t.Error("body without incompatible fields should not be adapted")
+	}
+}
@@ -0,0 +1,65 @@
+package client
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RateLimitInfo 响应头中供应商告知的请求配额：当前窗口剩余请求数、窗口总量与距窗口重置的时长。
+type RateLimitInfo struct {
+	Remaining int           // 剩余请求数
+	Limit     int           // 窗口内允许的请求数，响应头未给出时为 0
+	Reset     time.Duration // 距配额重置的时长，响应头未给出时为 0
+}
+
+// rateLimitHeaders 常见的请求配额响应头（剩余 / 总量 / 重置），按优先级排列
+var rateLimitHeaders = [][3]string{
+	{"X-Ratelimit-Remaining-Requests", "X-Ratelimit-Limit-Requests", "X-Ratelimit-Reset-Requests"},                         // OpenAI
+	{"Anthropic-Ratelimit-Requests-Remaining", "Anthropic-Ratelimit-Requests-Limit", "Anthropic-Ratelimit-Requests-Reset"}, // Anthropic
+	{"X-Ratelimit-Remaining", "X-Ratelimit-Limit", "X-Ratelimit-Reset"},                                                    // 通用网关
+}
+
+// ParseRateLimit 从响应头中解析请求配额，没有可识别的剩余请求数时返回 nil。
+func ParseRateLimit(header http.Header, now time.Time) *RateLimitInfo {
+	for _, names := range rateLimitHeaders {
+		remaining, err := strconv.Atoi(strings.TrimSpace(header.Get(names[0])))
+		if err != nil || remaining < 0 {
+			continue
+		}
+		info := &RateLimitInfo{Remaining: remaining}
+		if limit, err := strconv.Atoi(strings.TrimSpace(header.Get(names[1]))); err == nil && limit > 0 {
+			info.Limit = limit
+		}
+		info.Reset = parseRateLimitReset(header.Get(names[2]), now)
+		return info
+	}
+	return nil
+}
+
+// parseRateLimitReset 解析配额重置时间，支持 Go duration（OpenAI，如 "6m0s"、"20ms"）、
+// RFC 3339 时间点（Anthropic）、秒数与 Unix 时间戳（通用网关），无法解析或已过期时返回 0。
+func parseRateLimitReset(value string, now time.Time) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	if d, err := time.ParseDuration(value); err == nil && d > 0 {
+		return d
+	}
+	if at, err := time.Parse(time.RFC3339, value); err == nil && at.After(now) {
+		return at.Sub(now)
+	}
+	if secs, err := strconv.ParseFloat(value, 64); err == nil && secs > 0 {
+		// 大于 10 年的秒数视为 Unix 时间戳
+		if secs > 10*365*24*3600 {
+			if at := time.Unix(int64(secs), 0); at.After(now) {
+				return at.Sub(now)
+			}
+			return 0
+		}
+		return time.Duration(secs * float64(time.Second))
+	}
+	return 0
+}
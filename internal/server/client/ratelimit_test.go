@@ -0,0 +1,74 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/yinxulai/ait/internal/server/types"
+)
+
+func TestParseRateLimit(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		header map[string]string
+		want   *RateLimitInfo
+	}{
+		{"none", nil, nil},
+		{"openai", map[string]string{
+			"x-ratelimit-remaining-requests": "59",
+			"x-ratelimit-limit-requests":     "60",
+			"x-ratelimit-reset-requests":     "1s",
+		}, &RateLimitInfo{Remaining: 59, Limit: 60, Reset: time.Second}},
+		{"anthropic", map[string]string{
+			"anthropic-ratelimit-requests-remaining": "3",
+			"anthropic-ratelimit-requests-limit":     "50",
+			"anthropic-ratelimit-requests-reset":     "2026-01-01T00:00:30Z",
+		}, &RateLimitInfo{Remaining: 3, Limit: 50, Reset: 30 * time.Second}},
+		{"generic seconds", map[string]string{
+			"x-ratelimit-remaining": "0",
+			"x-ratelimit-reset":     "12",
+		}, &RateLimitInfo{Remaining: 0, Reset: 12 * time.Second}},
+		{"generic unix timestamp", map[string]string{
+			"x-ratelimit-remaining": "7",
+			"x-ratelimit-reset":     "1767225605",
+		}, &RateLimitInfo{Remaining: 7, Reset: 5 * time.Second}},
+		{"invalid remaining", map[string]string{"x-ratelimit-remaining": "many"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			for k, v := range tt.header {
+				header.Set(k, v)
+			}
+			got := ParseRateLimit(header, now)
+			if (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+				t.Errorf("ParseRateLimit() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOpenAIClient_Request_CapturesRateLimit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Ratelimit-Remaining-Requests", "4")
+		w.Header().Set("X-Ratelimit-Limit-Requests", "100")
+		w.Header().Set("X-Ratelimit-Reset-Requests", "20s")
+		w.Write([]byte(`{"choices":[{"index":0,"message":{"role":"assistant","content":"hi"}}],"usage":{"prompt_tokens":3,"completion_tokens":1}}`))
+	}))
+	defer server.Close()
+
+	c := NewOpenAIClient(types.Input{Protocol: types.ProtocolOpenAICompletions, EndpointURL: server.URL, ApiKey: "k", Model: "m"})
+	metrics, err := c.Request(context.Background(), "", "hi", false)
+	if err != nil {
+		t.Fatalf("Request() error = %v", err)
+	}
+	want := RateLimitInfo{Remaining: 4, Limit: 100, Reset: 20 * time.Second}
+	if metrics.RateLimit == nil || *metrics.RateLimit != want {
+		t.Errorf("RateLimit = %+v, want %+v", metrics.RateLimit, want)
+	}
+}
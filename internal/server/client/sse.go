@@ -0,0 +1,126 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// DefaultMaxSSEEventSize 单个 SSE 事件（单行或多行 data 拼接后）的默认上限
+const DefaultMaxSSEEventSize = 4 << 20 // 4 MiB
+
+// ErrSSEStop 由 onEvent 返回以提前结束解析（如收到 OpenAI 的 [DONE]），ParseSSE 此时返回 nil
+var ErrSSEStop = errors.New("sse: stop")
+
+// ErrSSEEventTooLarge 单行或单个事件超过 MaxEventSize
+var ErrSSEEventTooLarge = errors.New("sse: event exceeds maximum size")
+
+// SSEParser 按 text/event-stream 规范解析流式响应：
+//   - 行以 \n 或 \r\n 结尾；
+//   - 以 ":" 开头的注释行忽略；
+//   - "字段: 值" 中冒号后的第一个空格去掉，只处理 event 与 data 字段（id、retry 等忽略）；
+//   - 同一事件的多行 data 以 "\n" 拼接，遇到空行时分发事件。
+//
+// 流在最后一个事件的空行之前结束时，仍会分发已收到的 data，避免丢掉不规范服务端的最后一个事件。
+type SSEParser struct {
+	// MaxEventSize 单行与单个事件的最大字节数，0 表示使用 DefaultMaxSSEEventSize
+	MaxEventSize int
+}
+
+// ParseSSE 使用默认配置解析 r 中的 SSE 事件，每个事件调用一次 onEvent；event 为事件名，没有 event 字段时为空。
+func ParseSSE(r io.Reader, onEvent func(event, data string) error) error {
+	return SSEParser{}.Parse(r, onEvent)
+}
+
+// Parse 解析 r 中的 SSE 事件直到 EOF、读取出错或 onEvent 返回错误。没有 data 字段的事件不分发。
+func (p SSEParser) Parse(r io.Reader, onEvent func(event, data string) error) error {
+	maxSize := p.MaxEventSize
+	if maxSize <= 0 {
+		maxSize = DefaultMaxSSEEventSize
+	}
+	reader := bufio.NewReader(r)
+
+	var event string
+	var data strings.Builder
+	hasData := false
+	dispatch := func() error {
+		if !hasData {
+			event = ""
+			return nil
+		}
+		name, payload := event, data.String()
+		event, hasData = "", false
+		data.Reset()
+		return onEvent(name, payload)
+	}
+
+	for {
+		line, readErr := readSSELine(reader, maxSize)
+		if readErr != nil && readErr != io.EOF {
+			return readErr
+		}
+		if readErr == io.EOF && line == "" {
+			return finishSSE(dispatch())
+		}
+
+		var err error
+		switch {
+		case line == "":
+			err = dispatch()
+		case strings.HasPrefix(line, ":"):
+			// 注释行，常用作心跳
+		default:
+			field, value, _ := strings.Cut(line, ":")
+			value = strings.TrimPrefix(value, " ")
+			switch field {
+			case "event":
+				event = value
+			case "data":
+				if hasData {
+					data.WriteByte('\n')
+				}
+				data.WriteString(value)
+				hasData = true
+				if data.Len() > maxSize {
+					return fmt.Errorf("%w (%d bytes)", ErrSSEEventTooLarge, maxSize)
+				}
+			}
+		}
+		if err != nil {
+			return finishSSE(err)
+		}
+		if readErr == io.EOF {
+			return finishSSE(dispatch())
+		}
+	}
+}
+
+// finishSSE 把 onEvent 主动停止视为正常结束
+func finishSSE(err error) error {
+	if errors.Is(err, ErrSSEStop) {
+		return nil
+	}
+	return err
+}
+
+// readSSELine 读取一行并去掉行尾的 \n 或 \r\n；行长超过 maxSize 时返回 ErrSSEEventTooLarge。
+// 到达 EOF 时返回最后一段不完整的行与 io.EOF。
+func readSSELine(reader *bufio.Reader, maxSize int) (string, error) {
+	var buf []byte
+	for {
+		chunk, err := reader.ReadSlice('\n')
+		if len(buf)+len(chunk) > maxSize+2 { // 留出行尾 \r\n
+			return "", fmt.Errorf("%w (%d bytes)", ErrSSEEventTooLarge, maxSize)
+		}
+		buf = append(buf, chunk...)
+		if err == bufio.ErrBufferFull {
+			continue
+		}
+		buf = bytes.TrimSuffix(buf, []byte("\n"))
+		buf = bytes.TrimSuffix(buf, []byte("\r"))
+		return string(buf), err
+	}
+}
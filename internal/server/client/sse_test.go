@@ -0,0 +1,194 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type sseEvent struct {
+	event, data string
+}
+
+func collectSSE(t *testing.T, p SSEParser, input string) ([]sseEvent, error) {
+	t.Helper()
+	var events []sseEvent
+	err := p.Parse(strings.NewReader(input), func(event, data string) error {
+		events = append(events, sseEvent{event, data})
+		return nil
+	})
+	return events, err
+}
+
+func TestParseSSE(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []sseEvent
+	}{
+		{"single event", "data: hello\n\n", []sseEvent{{"", "hello"}}},
+		{"named event", "event: message_start\ndata: {}\n\n", []sseEvent{{"message_start", "{}"}}},
+		{"crlf line endings", "event: ping\r\ndata: a\r\n\r\ndata: b\r\n\r\n", []sseEvent{{"ping", "a"}, {"", "b"}}},
+		{"multi-line data", "data: line1\ndata: line2\ndata:line3\n\n", []sseEvent{{"", "line1\nline2\nline3"}}},
+		{"comment lines ignored", ": keep-alive\ndata: x\n: another\n\n", []sseEvent{{"", "x"}}},
+		{"only first space stripped", "data:  two spaces\n\n", []sseEvent{{"", " two spaces"}}},
+		{"colon in value", "data: {\"a\":\"b:c\"}\n\n", []sseEvent{{"", `{"a":"b:c"}`}}},
+		{"unknown fields ignored", "id: 1\nretry: 100\nfoo\ndata: x\n\n", []sseEvent{{"", "x"}}},
+		{"event without data skipped", "event: ping\n\ndata: x\n\n", []sseEvent{{"", "x"}}},
+		{"event name resets after dispatch", "event: a\ndata: 1\n\ndata: 2\n\n", []sseEvent{{"a", "1"}, {"", "2"}}},
+		{"extra blank lines", "\n\n\ndata: x\n\n\n\n", []sseEvent{{"", "x"}}},
+		{"empty data field", "data:\n\n", []sseEvent{{"", ""}}},
+		{"missing trailing blank line", "data: a\n\ndata: b\n", []sseEvent{{"", "a"}, {"", "b"}}},
+		{"missing trailing newline", "data: a\n\ndata: b", []sseEvent{{"", "a"}, {"", "b"}}},
+		{"empty input", "", nil},
+		{"comments only", ": ping\n: ping\n", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := collectSSE(t, SSEParser{}, tt.input)
+			if err != nil {
+				t.Fatalf("Parse error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("events = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseSSE_LongLine(t *testing.T) {
+	// 超过 bufio.Scanner 默认 64KB 缓冲的单行
+	payload := strings.Repeat("x", 200*1024)
+	got, err := collectSSE(t, SSEParser{}, "data: "+payload+"\n\n")
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	if len(got) != 1 || got[0].data != payload {
+		t.Fatalf("got %d events, want 1 event with the full payload", len(got))
+	}
+}
+
+func TestParseSSE_MaxEventSize(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"single line too long", "data: " + strings.Repeat("x", 100) + "\n\n"},
+		{"multi-line data too long", strings.Repeat("data: "+strings.Repeat("x", 30)+"\n", 4) + "\n"},
+		{"unterminated line too long", strings.Repeat("x", 100)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := collectSSE(t, SSEParser{MaxEventSize: 64}, tt.input)
+			if !errors.Is(err, ErrSSEEventTooLarge) {
+				t.Errorf("err = %v, want ErrSSEEventTooLarge", err)
+			}
+		})
+	}
+
+	if _, err := collectSSE(t, SSEParser{MaxEventSize: 64}, "data: "+strings.Repeat("x", 50)+"\r\n\r\n"); err != nil {
+		t.Errorf("event within the limit should parse, got %v", err)
+	}
+}
+
+func TestParseSSE_Stop(t *testing.T) {
+	var got []string
+	err := ParseSSE(strings.NewReader("data: a\n\ndata: [DONE]\n\ndata: b\n\n"), func(_, data string) error {
+		if data == "[DONE]" {
+			return ErrSSEStop
+		}
+		got = append(got, data)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ErrSSEStop should end parsing without error, got %v", err)
+	}
+	if !reflect.DeepEqual(got, []string{"a"}) {
+		t.Errorf("got %q, want [a]", got)
+	}
+}
+
+func TestParseSSE_CallbackAndReadErrors(t *testing.T) {
+	boom := errors.New("boom")
+	err := ParseSSE(strings.NewReader("data: a\n\n"), func(_, _ string) error { return boom })
+	if !errors.Is(err, boom) {
+		t.Errorf("callback error = %v, want boom", err)
+	}
+
+	r := io.MultiReader(strings.NewReader("data: a\n\n"), &failingReader{err: io.ErrUnexpectedEOF})
+	var got []string
+	err = ParseSSE(r, func(_, data string) error {
+		got = append(got, data)
+		return nil
+	})
+	if !errors.Is(err, io.ErrUnexpectedEOF) || len(got) != 1 {
+		t.Errorf("read error = %v after %d events, want ErrUnexpectedEOF after 1", err, len(got))
+	}
+}
+
+type failingReader struct{ err error }
+
+func (r *failingReader) Read([]byte) (int, error) { return 0, r.err }
+
+// TestAnthropicClient_Request_StreamTTFTWithHeartbeats 首 token 时间仍以首个内容增量为准，
+// 心跳注释与非内容事件不提前计时；同时覆盖 CRLF 行尾。
+func TestAnthropicClient_Request_StreamTTFTWithHeartbeats(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		flusher := w.(http.Flusher)
+		fmt.Fprint(w, "event: message_start\r\ndata: {\"type\":\"message_start\",\"message\":{\"usage\":{\"input_tokens\":3}}}\r\n\r\n")
+		fmt.Fprint(w, ": ping\r\n\r\n")
+		flusher.Flush()
+
+		time.Sleep(50 * time.Millisecond)
+		fmt.Fprint(w, "event: content_block_delta\r\ndata: {\"type\":\"content_block_delta\",\"delta\":{\"type\":\"text_delta\",\"text\":\"Hi\"}}\r\n\r\n")
+		flusher.Flush()
+
+		time.Sleep(30 * time.Millisecond)
+		fmt.Fprint(w, "event: message_delta\r\ndata: {\"type\":\"message_delta\",\"usage\":{\"output_tokens\":1}}\r\n\r\n")
+	}))
+	defer server.Close()
+
+	c := NewAnthropicClient(createTestConfig(server.URL, "k", "m", 5*time.Second, false))
+	metrics, err := c.Request(context.Background(), "", "hi", true)
+	if err != nil {
+		t.Fatalf("Request error: %v", err)
+	}
+	// 只断言先后顺序：服务端在首个内容增量前至少等待 50ms，心跳提前计时会让 TTFT 小于它；
+	// 内容增量之后还有 message_delta，TTFT 必须早于请求结束。不设上界，避免慢机器上偶发失败
+	if metrics.TimeToFirstToken < 50*time.Millisecond || metrics.TimeToFirstToken >= metrics.TotalTime {
+		t.Errorf("TTFT = %v, want in [50ms, total %v)", metrics.TimeToFirstToken, metrics.TotalTime)
+	}
+	if metrics.PromptTokens != 3 || metrics.CompletionTokens != 1 {
+		t.Errorf("tokens = %d/%d, want 3/1", metrics.PromptTokens, metrics.CompletionTokens)
+	}
+}
+
+// TestOpenAIClient_Request_StreamLongLine 超过 64KB 的单个数据块不再导致流解析失败
+func TestOpenAIClient_Request_StreamLongLine(t *testing.T) {
+	content := strings.Repeat("a", 128*1024)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprintf(w, "data: {\"choices\":[{\"delta\":{\"content\":%q}}]}\r\n\r\n", content)
+		fmt.Fprint(w, "data: {\"choices\":[],\"usage\":{\"prompt_tokens\":2,\"completion_tokens\":7}}\r\n\r\n")
+		fmt.Fprint(w, "data: [DONE]\r\n\r\n")
+	}))
+	defer server.Close()
+
+	c := NewOpenAIClient(createOpenAITestConfig(server.URL, "k", "m", 0, false))
+	metrics, err := c.Request(context.Background(), "", "hi", true)
+	if err != nil {
+		t.Fatalf("Request error: %v", err)
+	}
+	if metrics.CompletionTokens != 7 || metrics.TimeToFirstToken <= 0 {
+		t.Errorf("completion tokens = %d, TTFT = %v", metrics.CompletionTokens, metrics.TimeToFirstToken)
+	}
+}
@@ -0,0 +1,44 @@
+package client
+
+import (
+	"time"
+	"unicode/utf8"
+
+	"github.com/yinxulai/ait/internal/server/prompt"
+	"github.com/yinxulai/ait/internal/server/types"
+)
+
+// chunkRecorder 记录流式响应每个增量 chunk 的到达时刻与内容长度；limit 为 0 时什么也不做，
+// 记录满 limit 个之后只标记截断，避免超长输出占用过多内存。
+type chunkRecorder struct {
+	t0        time.Time
+	limit     int
+	chunks    []types.TokenChunk
+	truncated bool
+}
+
+func newChunkRecorder(t0 time.Time, limit int) *chunkRecorder {
+	return &chunkRecorder{t0: t0, limit: limit}
+}
+
+// observe 在一个 chunk 解析完成后调用，text 为该 chunk 的增量内容（正文、思考与工具调用参数），空内容不记录。
+func (r *chunkRecorder) observe(text string) {
+	if r.limit <= 0 || text == "" {
+		return
+	}
+	if len(r.chunks) >= r.limit {
+		r.truncated = true
+		return
+	}
+	r.chunks = append(r.chunks, types.TokenChunk{
+		Offset: time.Since(r.t0),
+		Chars:  utf8.RuneCountInString(text),
+		Tokens: prompt.EstimateTokens(text),
+	})
+}
+
+// apply 把记录的 chunk 写入指标。
+func (r *chunkRecorder) apply(m *ResponseMetrics) {
+	m.TokenChunks = r.chunks
+	m.TokenChunksTruncated = r.truncated
+}
@@ -0,0 +1,50 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// ToolCall OpenAI 响应中的一次工具调用。流式响应中为增量：同一调用的 id / name 只在首个分片出现，
+// arguments 按 Index 逐片拼接。
+type ToolCall struct {
+	Index    int    `json:"index"`
+	ID       string `json:"id,omitempty"`
+	Type     string `json:"type,omitempty"`
+	Function struct {
+		Name      string `json:"name,omitempty"`
+		Arguments string `json:"arguments,omitempty"`
+	} `json:"function"`
+}
+
+// LoadTools 读取 OpenAI 格式的工具定义文件（tools 数组），压缩后原样作为请求体的 tools 字段发送。
+func LoadTools(path string) (json.RawMessage, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取工具定义文件失败: %w", err)
+	}
+	var tools []map[string]json.RawMessage
+	if err := json.Unmarshal(data, &tools); err != nil {
+		return nil, fmt.Errorf("工具定义文件 %s 应为 OpenAI 格式的 tools 数组: %w", path, err)
+	}
+	if len(tools) == 0 {
+		return nil, fmt.Errorf("工具定义文件 %s 中没有工具", path)
+	}
+	for i, tool := range tools {
+		if _, ok := tool["type"]; !ok {
+			return nil, fmt.Errorf("工具定义文件 %s 第 %d 个工具缺少 type 字段", path, i+1)
+		}
+	}
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, data); err != nil {
+		return nil, fmt.Errorf("工具定义文件 %s 格式错误: %w", path, err)
+	}
+	return compact.Bytes(), nil
+}
+
+// formatToolCall 把一次工具调用渲染为 name(arguments)，计入 ResponseText 供内容检测使用。
+func formatToolCall(name, arguments string) string {
+	return name + "(" + arguments + ")"
+}
@@ -0,0 +1,159 @@
+package client
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/yinxulai/ait/internal/server/logger"
+)
+
+// HeaderClientRequestID 携带客户端本地 trace id 的请求头
+const HeaderClientRequestID = "X-Client-Request-Id"
+
+// HeaderRequestID 开启请求 ID 回传校验时注入的请求头，部分代理 / 网关会在响应中原样回传
+const HeaderRequestID = "X-Request-Id"
+
+// serverRequestIDHeaders 供应商返回请求 ID 的常见响应头，按优先级排列
+var serverRequestIDHeaders = []string{
+	"X-Request-Id",
+	"Request-Id",
+	"X-Amzn-Requestid",
+	"Apim-Request-Id",
+	"Cf-Ray",
+}
+
+// NewClientRequestID 生成一个本地 trace id（32 位十六进制）。
+func NewClientRequestID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// NewRequestUUID 生成一个随机 UUID v4，作为开启回传校验时的 trace id。
+func NewRequestUUID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+// ServerRequestID 从响应头中提取供应商的请求 ID，未找到时返回空字符串。
+func ServerRequestID(header http.Header) string {
+	for _, name := range serverRequestIDHeaders {
+		if v := strings.TrimSpace(header.Get(name)); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// TraceSuffix 返回附加在错误信息末尾的 trace 标注，两个 id 都为空时返回空字符串。
+func TraceSuffix(clientRequestID, serverRequestID string) string {
+	var parts []string
+	if clientRequestID != "" {
+		parts = append(parts, "client_request_id="+clientRequestID)
+	}
+	if serverRequestID != "" {
+		parts = append(parts, "request_id="+serverRequestID)
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" [%s]", strings.Join(parts, " "))
+}
+
+// requestTrace 单个请求的 trace 信息，由各客户端的 doRequest 创建并在请求结束时写入指标。
+type requestTrace struct {
+	clientID string
+	serverID string
+
+	// verify 开启请求 ID 回传校验：trace id 使用 UUID 并同时作为 X-Request-Id 发送，echoed 为响应回传的值
+	verify bool
+	echoed string
+
+	rateLimit *RateLimitInfo
+	proto     string        // 响应的 HTTP 协议版本
+	firstByte time.Duration // 从请求开始到收到响应首字节的耗时
+	body      *encodedBody  // 由客户端解压的响应体，记录编码与解压前后的字节数，由 encodingTransport 写入
+}
+
+// requestTraceKey 请求 context 中携带 *requestTrace 的键，供 Transport 层回写只有它能观察到的信息
+type requestTraceKey struct{}
+
+// withRequestTrace 返回携带 t 的 context。
+func withRequestTrace(ctx context.Context, t *requestTrace) context.Context {
+	return context.WithValue(ctx, requestTraceKey{}, t)
+}
+
+// requestTraceFrom 返回 ctx 携带的 trace，没有时返回 nil。
+func requestTraceFrom(ctx context.Context) *requestTrace {
+	t, _ := ctx.Value(requestTraceKey{}).(*requestTrace)
+	return t
+}
+
+// newRequestTrace 创建单个请求的 trace；verify 为 true 时开启请求 ID 回传校验。
+func newRequestTrace(verify bool) *requestTrace {
+	if verify {
+		return &requestTrace{clientID: NewRequestUUID(), verify: true}
+	}
+	return &requestTrace{clientID: NewClientRequestID()}
+}
+
+// setHeaders 把 trace id 写入请求头；开启回传校验时额外注入 X-Request-Id。
+func (t *requestTrace) setHeaders(header http.Header) {
+	header.Set(HeaderClientRequestID, t.clientID)
+	if t.verify {
+		header.Set(HeaderRequestID, t.clientID)
+	}
+}
+
+// capture 记录响应头中的供应商请求 ID 与请求配额，开启回传校验时同时记录回传的 X-Request-Id。
+func (t *requestTrace) capture(header http.Header) {
+	if id := ServerRequestID(header); id != "" {
+		t.serverID = id
+	}
+	if t.verify {
+		t.echoed = strings.TrimSpace(header.Get(HeaderRequestID))
+	}
+	t.rateLimit = ParseRateLimit(header, time.Now())
+}
+
+// captureResponse 记录响应的协议版本，并按 capture 记录响应头中的信息。
+func (t *requestTrace) captureResponse(resp *http.Response) {
+	t.proto = resp.Proto
+	t.capture(resp.Header)
+}
+
+// apply 将 trace id 写入指标，失败请求的错误信息附带两个 id；--log 模式下额外写一条可按 id 检索的日志。
+func (t *requestTrace) apply(m *ResponseMetrics, l *logger.Logger, model string) {
+	if m == nil {
+		return
+	}
+	m.ClientRequestID = t.clientID
+	m.ServerRequestID = t.serverID
+	// 没有回传不算异常（多数服务不回传），回传了不同的值才说明代理层可能乱序 / 串包
+	m.RequestIDEchoed = t.echoed != ""
+	m.RequestIDMismatch = t.echoed != "" && t.echoed != t.clientID
+	m.RateLimit = t.rateLimit
+	m.HTTPProto = t.proto
+	m.TimeToFirstByte = t.firstByte
+	if t.body != nil {
+		m.Encoding, m.CompressedBytes, m.UncompressedBytes = t.body.stats()
+	}
+	if m.ErrorMessage != "" {
+		m.ErrorMessage += TraceSuffix(t.clientID, t.serverID)
+	}
+	if l != nil && l.IsEnabled() {
+		l.Debug(model, "Request Trace", map[string]interface{}{
+			"client_request_id": t.clientID,
+			"request_id":        t.serverID,
+			"error":             m.ErrorMessage,
+		})
+	}
+}
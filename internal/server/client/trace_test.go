@@ -0,0 +1,166 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/yinxulai/ait/internal/server/types"
+)
+
+func TestServerRequestID(t *testing.T) {
+	tests := []struct {
+		name   string
+		header http.Header
+		want   string
+	}{
+		{"x-request-id", http.Header{"X-Request-Id": {"req_1"}}, "req_1"},
+		{"anthropic request-id", http.Header{"Request-Id": {"req_2"}}, "req_2"},
+		{"cf-ray fallback", http.Header{"Cf-Ray": {"8a1b-SJC"}}, "8a1b-SJC"},
+		{"priority", http.Header{"Cf-Ray": {"ray"}, "X-Request-Id": {"req_3"}}, "req_3"},
+		{"none", http.Header{}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ServerRequestID(tt.header); got != tt.want {
+				t.Errorf("ServerRequestID = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTraceSuffix(t *testing.T) {
+	if got := TraceSuffix("abc", "req_1"); got != " [client_request_id=abc request_id=req_1]" {
+		t.Errorf("TraceSuffix = %q", got)
+	}
+	if got := TraceSuffix("abc", ""); got != " [client_request_id=abc]" {
+		t.Errorf("TraceSuffix without server id = %q", got)
+	}
+	if got := TraceSuffix("", ""); got != "" {
+		t.Errorf("TraceSuffix empty = %q", got)
+	}
+}
+
+func TestOpenAIClient_Request_PropagatesTraceIDs(t *testing.T) {
+	var sent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sent = r.Header.Get(HeaderClientRequestID)
+		w.Header().Set("X-Request-Id", "req_upstream")
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(`{"error":{"type":"server_error","message":"boom"}}`))
+	}))
+	defer server.Close()
+
+	c := NewOpenAIClient(types.Input{Protocol: types.ProtocolOpenAICompletions, EndpointURL: server.URL, ApiKey: "k", Model: "m"})
+	metrics, err := c.Request(context.Background(), "", "hi", false)
+	if err == nil {
+		t.Fatal("expected error for 500 response")
+	}
+	if len(sent) != 32 || metrics.ClientRequestID != sent {
+		t.Errorf("client request id: sent %q, metrics %q", sent, metrics.ClientRequestID)
+	}
+	if metrics.ServerRequestID != "req_upstream" {
+		t.Errorf("ServerRequestID = %q, want req_upstream", metrics.ServerRequestID)
+	}
+	if !strings.HasSuffix(metrics.ErrorMessage, "[client_request_id="+sent+" request_id=req_upstream]") {
+		t.Errorf("ErrorMessage missing trace ids: %q", metrics.ErrorMessage)
+	}
+}
+
+func TestAnthropicClient_Request_CapturesRequestID(t *testing.T) {
+	var sent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sent = r.Header.Get(HeaderClientRequestID)
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Request-Id", "req_anthropic")
+		w.Write([]byte(`{"content":[{"type":"text","text":"hi"}],"usage":{"input_tokens":3,"output_tokens":1}}`))
+	}))
+	defer server.Close()
+
+	c := NewAnthropicClient(types.Input{Protocol: types.ProtocolAnthropicMessages, EndpointURL: server.URL, ApiKey: "k", Model: "m"})
+	metrics, err := c.Request(context.Background(), "", "hi", false)
+	if err != nil {
+		t.Fatalf("Request: %v", err)
+	}
+	if sent == "" || metrics.ClientRequestID != sent || metrics.ServerRequestID != "req_anthropic" {
+		t.Errorf("trace ids: sent %q, metrics %q / %q", sent, metrics.ClientRequestID, metrics.ServerRequestID)
+	}
+	if metrics.ErrorMessage != "" {
+		t.Errorf("successful request should not carry an error: %q", metrics.ErrorMessage)
+	}
+}
+
+func TestNewRequestUUID(t *testing.T) {
+	id := NewRequestUUID()
+	if len(id) != 36 || id[14] != '4' || strings.Count(id, "-") != 4 {
+		t.Errorf("NewRequestUUID = %q, want UUID v4", id)
+	}
+	if id == NewRequestUUID() {
+		t.Error("NewRequestUUID should be unique")
+	}
+}
+
+func TestOpenAIClient_Request_VerifyRequestID(t *testing.T) {
+	tests := []struct {
+		name         string
+		echo         func(sent string) string
+		wantEchoed   bool
+		wantMismatch bool
+	}{
+		{"echoed", func(sent string) string { return sent }, true, false},
+		{"mismatched", func(string) string { return "someone-else" }, true, true},
+		{"not echoed", func(string) string { return "" }, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sent, sentClient string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				sent = r.Header.Get(HeaderRequestID)
+				sentClient = r.Header.Get(HeaderClientRequestID)
+				if echo := tt.echo(sent); echo != "" {
+					w.Header().Set(HeaderRequestID, echo)
+				}
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(`{"choices":[{"message":{"content":"hi"}}],"usage":{"prompt_tokens":3,"completion_tokens":1}}`))
+			}))
+			defer server.Close()
+
+			c := NewOpenAIClient(types.Input{Protocol: types.ProtocolOpenAICompletions, EndpointURL: server.URL, ApiKey: "k", Model: "m", VerifyRequestID: true})
+			metrics, err := c.Request(context.Background(), "", "hi", false)
+			if err != nil {
+				t.Fatalf("Request error: %v", err)
+			}
+			if len(sent) != 36 || sent != sentClient || metrics.ClientRequestID != sent {
+				t.Errorf("request id: X-Request-Id %q, X-Client-Request-Id %q, metrics %q", sent, sentClient, metrics.ClientRequestID)
+			}
+			if metrics.RequestIDEchoed != tt.wantEchoed || metrics.RequestIDMismatch != tt.wantMismatch {
+				t.Errorf("echoed/mismatch = %v/%v, want %v/%v", metrics.RequestIDEchoed, metrics.RequestIDMismatch, tt.wantEchoed, tt.wantMismatch)
+			}
+		})
+	}
+}
+
+func TestOpenAIClient_Request_NoRequestIDByDefault(t *testing.T) {
+	var sent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sent = r.Header.Get(HeaderRequestID)
+		w.Header().Set(HeaderRequestID, "upstream")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"choices":[{"message":{"content":"hi"}}],"usage":{"prompt_tokens":3,"completion_tokens":1}}`))
+	}))
+	defer server.Close()
+
+	c := NewOpenAIClient(types.Input{Protocol: types.ProtocolOpenAICompletions, EndpointURL: server.URL, ApiKey: "k", Model: "m"})
+	metrics, err := c.Request(context.Background(), "", "hi", false)
+	if err != nil {
+		t.Fatalf("Request error: %v", err)
+	}
+	if sent != "" {
+		t.Errorf("X-Request-Id should not be sent by default, got %q", sent)
+	}
+	if metrics.RequestIDEchoed || metrics.RequestIDMismatch {
+		t.Errorf("request id check should be off by default, got %+v", metrics)
+	}
+}
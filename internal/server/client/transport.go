@@ -1,11 +1,20 @@
 package client
 
 import (
+	"bytes"
+	"compress/gzip"
+	"crypto/tls"
 	"fmt"
+	"io"
+	"net"
 	"net/http"
 	"net/url"
 	"strings"
 
+	"github.com/andybalholm/brotli"
+
+	"github.com/yinxulai/ait/internal/i18n"
+	"github.com/yinxulai/ait/internal/server/network"
 	"github.com/yinxulai/ait/internal/server/types"
 )
 
@@ -15,6 +24,19 @@ func newMeasuredTransport(config types.Input) *http.Transport {
 		DisableCompression: false,
 		Proxy:              http.ProxyFromEnvironment,
 	}
+	// --resolve / --dns-server：固定解析的地址直接连接指定 IP，不做 DNS 解析，DNSTime 因此为 0
+	if dns := network.CurrentDNSConfig(); dns.Enabled() {
+		transport.DialContext = dns.DialContext(&net.Dialer{})
+	}
+	switch config.HTTPVersion {
+	case types.HTTPVersion1:
+		// 非 nil 的空 TLSNextProto 禁止 ALPN 协商 h2
+		transport.ForceAttemptHTTP2 = false
+		transport.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+	case types.HTTPVersion2:
+		// 自定义了 DialContext 等字段的 Transport 默认不启用 HTTP/2，需显式开启
+		transport.ForceAttemptHTTP2 = true
+	}
 
 	proxyURL := strings.TrimSpace(config.ProxyURL)
 	if proxyURL == "" {
@@ -32,3 +54,185 @@ func newMeasuredTransport(config types.Input) *http.Transport {
 	}
 	return transport
 }
+
+// withRequestCompression 在 enabled 时包装 base，用 gzip 压缩请求体并设置 Content-Encoding: gzip；
+// 否则原样返回 base。压缩发生在请求计时之内，耗时计入总耗时。
+func withRequestCompression(base http.RoundTripper, enabled bool) http.RoundTripper {
+	if !enabled {
+		return base
+	}
+	return &gzipRequestTransport{base: base}
+}
+
+// gzipRequestTransport 压缩请求体后交给 base 发送，已带 Content-Encoding 的请求不再处理。
+type gzipRequestTransport struct {
+	base http.RoundTripper
+}
+
+// CloseIdleConnections 转发给 base，使 http.Client.CloseIdleConnections 能穿过压缩层关闭底层连接。
+func (t *gzipRequestTransport) CloseIdleConnections() {
+	if closer, ok := t.base.(interface{ CloseIdleConnections() }); ok {
+		closer.CloseIdleConnections()
+	}
+}
+
+func (t *gzipRequestTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body == nil || req.Body == http.NoBody || req.Header.Get("Content-Encoding") != "" {
+		return t.base.RoundTrip(req)
+	}
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(body); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	compressed := buf.Bytes()
+
+	out := req.Clone(req.Context())
+	out.Header.Set("Content-Encoding", "gzip")
+	out.ContentLength = int64(len(compressed))
+	out.Body = io.NopCloser(bytes.NewReader(compressed))
+	out.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(compressed)), nil
+	}
+	return t.base.RoundTrip(out)
+}
+
+// withResponseEncoding 在 acceptEncoding 非空时包装 base，设置请求头 Accept-Encoding 并由客户端自行解压响应体，
+// 以便记录解压前后的字节数；为空时原样返回 base，保持 Go 默认的透明 gzip 解压，不记录压缩统计。
+// 请求已带 Accept-Encoding 时不覆盖。
+//
+// 显式设置 Accept-Encoding 后 http.Transport 不再透明解压，响应体替换为 *encodedBody，
+// 与 Go 透明解压时一样去掉 Content-Encoding / Content-Length 并置 Uncompressed。
+func withResponseEncoding(base http.RoundTripper, acceptEncoding string) http.RoundTripper {
+	if acceptEncoding == "" {
+		return base
+	}
+	return &encodingTransport{base: base, acceptEncoding: acceptEncoding}
+}
+
+// encodingTransport 设置 Accept-Encoding 并解压响应体的 RoundTripper。
+type encodingTransport struct {
+	base           http.RoundTripper
+	acceptEncoding string
+}
+
+// CloseIdleConnections 转发给 base，使 http.Client.CloseIdleConnections 能穿过解压层关闭底层连接。
+func (t *encodingTransport) CloseIdleConnections() {
+	if closer, ok := t.base.(interface{ CloseIdleConnections() }); ok {
+		closer.CloseIdleConnections()
+	}
+}
+
+func (t *encodingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Header.Get("Accept-Encoding") == "" {
+		req = req.Clone(req.Context())
+		req.Header.Set("Accept-Encoding", t.acceptEncoding)
+	}
+	resp, err := t.base.RoundTrip(req)
+	if err != nil || resp.Uncompressed {
+		return resp, err
+	}
+	encoding := strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Encoding")))
+	if encoding == "" {
+		encoding = types.AcceptEncodingIdentity
+	}
+	body := &encodedBody{raw: resp.Body, encoding: encoding}
+	body.wire = &countingReader{r: resp.Body}
+	resp.Body = body
+	// http.Client 设置了 Timeout 时会再包一层响应体，统计通过请求 context 中的 trace 回传
+	if rt := requestTraceFrom(req.Context()); rt != nil {
+		rt.body = body
+	}
+	if encoding != types.AcceptEncodingIdentity && body.supported() {
+		resp.Header.Del("Content-Encoding")
+		resp.Header.Del("Content-Length")
+		resp.ContentLength = -1
+		resp.Uncompressed = true
+	}
+	return resp, nil
+}
+
+// countingReader 统计读取的字节数。
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// encodedBody 按 Content-Encoding 解压的响应体，同时统计解压前后的字节数。
+// 解压器在首次 Read 时才创建：gzip 需要先读出头部，提前创建会在流式响应的首块到达前阻塞。
+// 不支持的编码原样返回线上数据。
+type encodedBody struct {
+	raw      io.ReadCloser
+	wire     *countingReader // 线上（解压前）数据
+	encoding string
+	decoder  io.Reader
+	closer   io.Closer // gzip.Reader 需要关闭以校验尾部
+	out      int64     // 解压后已读取的字节数
+}
+
+// supported 返回 encoding 是否可以由客户端解压。
+func (b *encodedBody) supported() bool {
+	switch b.encoding {
+	case types.AcceptEncodingIdentity, types.AcceptEncodingGzip, "x-gzip", types.AcceptEncodingBrotli:
+		return true
+	}
+	return false
+}
+
+func (b *encodedBody) Read(p []byte) (int, error) {
+	if b.decoder == nil {
+		switch b.encoding {
+		case types.AcceptEncodingGzip, "x-gzip":
+			zr, err := gzip.NewReader(b.wire)
+			if err != nil {
+				return 0, err
+			}
+			b.decoder, b.closer = zr, zr
+		case types.AcceptEncodingBrotli:
+			b.decoder = brotli.NewReader(b.wire)
+		default:
+			b.decoder = b.wire
+		}
+	}
+	n, err := b.decoder.Read(p)
+	b.out += int64(n)
+	return n, err
+}
+
+func (b *encodedBody) Close() error {
+	if b.closer != nil {
+		b.closer.Close()
+	}
+	return b.raw.Close()
+}
+
+// stats 返回响应的编码与解压前后已读取的字节数。
+func (b *encodedBody) stats() (encoding string, compressed, uncompressed int64) {
+	return b.encoding, b.wire.n, b.out
+}
+
+// withCompressionHint 开启请求体压缩且服务端返回 400 / 415 时，在错误信息后追加提示：
+// 不少服务不接受 gzip 请求体，这类失败往往与压缩有关。
+func withCompressionHint(errorMessage string, compressed bool, statusCode int) string {
+	if !compressed || (statusCode != http.StatusBadRequest && statusCode != http.StatusUnsupportedMediaType) {
+		return errorMessage
+	}
+	if i18n.Active() == i18n.EN {
+		return errorMessage + "\nHint: the request body was gzip-compressed (compress_request); the service may not accept Content-Encoding: gzip, try disabling it."
+	}
+	return errorMessage + "\n提示: 请求体已按 compress_request 做 gzip 压缩，该服务可能不支持 Content-Encoding: gzip，可关闭后重试。"
+}
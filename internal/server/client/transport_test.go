@@ -1,10 +1,22 @@
 package client
 
 import (
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/andybalholm/brotli"
+
+	"github.com/yinxulai/ait/internal/i18n"
+	"github.com/yinxulai/ait/internal/server/network"
 	"github.com/yinxulai/ait/internal/server/types"
 )
 
@@ -34,6 +46,39 @@ func TestNewMeasuredTransport_InvalidProxy(t *testing.T) {
 	}
 }
 
+func TestNewMeasuredTransport_HTTPVersionNegotiation(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"choices":[{"message":{"content":"ok"},"finish_reason":"stop"}],"usage":{"prompt_tokens":1,"completion_tokens":1}}`))
+	}))
+	server.EnableHTTP2 = true
+	server.StartTLS()
+	defer server.Close()
+	trusted := server.Client().Transport.(*http.Transport).TLSClientConfig
+
+	tests := []struct {
+		version string
+		want    string
+	}{
+		{types.HTTPVersion1, "HTTP/1.1"},
+		{types.HTTPVersion2, "HTTP/2.0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			c := NewOpenAIClient(types.Input{Protocol: types.ProtocolOpenAICompletions, EndpointURL: server.URL, ApiKey: "k", Model: "m", HTTPVersion: tt.version})
+			c.httpClient.Transport.(*http.Transport).TLSClientConfig = trusted.Clone()
+
+			metrics, err := c.Request(context.Background(), "", "hi", false)
+			if err != nil {
+				t.Fatalf("Request: %v", err)
+			}
+			if metrics.HTTPProto != tt.want {
+				t.Errorf("HTTPProto = %q, want %q", metrics.HTTPProto, tt.want)
+			}
+		})
+	}
+}
+
 func TestNewClients_UseConfiguredProxy(t *testing.T) {
 	constructors := []struct {
 		name      string
@@ -79,3 +124,180 @@ func TestNewClients_UseConfiguredProxy(t *testing.T) {
 		})
 	}
 }
+
+func TestNewMeasuredTransport_Resolve(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"choices":[{"index":0,"message":{"role":"assistant","content":"hi"}}],"usage":{"prompt_tokens":3,"completion_tokens":2}}`)
+	}))
+	defer server.Close()
+	_, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+	rule, err := network.ParseResolveRule("ait-resolve.invalid:" + port + ":127.0.0.1")
+	if err != nil {
+		t.Fatalf("ParseResolveRule: %v", err)
+	}
+	network.SetDNSConfig(network.DNSConfig{Resolve: []network.ResolveRule{rule}})
+	t.Cleanup(func() { network.SetDNSConfig(network.DNSConfig{}) })
+
+	config := createOpenAITestConfig("http://ait-resolve.invalid:"+port, "test-key", "gpt-4o", 5*time.Second, false)
+	metrics, err := NewOpenAIClient(config).Request(context.Background(), "", "hello", false)
+	if err != nil {
+		t.Fatalf("Request to overridden host: %v", err)
+	}
+	if metrics.DNSTime != 0 || metrics.TargetIP != "127.0.0.1" {
+		t.Errorf("DNSTime = %v, TargetIP = %q, want 0 and 127.0.0.1", metrics.DNSTime, metrics.TargetIP)
+	}
+	if metrics.ResolvedIPs != nil {
+		t.Errorf("ResolvedIPs = %q, want nil for a fixed address", metrics.ResolvedIPs)
+	}
+}
+
+func TestOpenAIClient_Request_ResolvedIPs(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"choices":[{"index":0,"message":{"role":"assistant","content":"hi"}}],"usage":{"prompt_tokens":3,"completion_tokens":2}}`)
+	}))
+	defer server.Close()
+	_, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+
+	config := createOpenAITestConfig("http://localhost:"+port, "test-key", "gpt-4o", 5*time.Second, false)
+	metrics, err := NewOpenAIClient(config).Request(context.Background(), "", "hello", false)
+	if err != nil {
+		t.Fatalf("Request to localhost: %v", err)
+	}
+	if !slices.Contains(metrics.ResolvedIPs, "127.0.0.1") || !slices.Contains(metrics.ResolvedIPs, metrics.TargetIP) {
+		t.Errorf("ResolvedIPs = %q, TargetIP = %q, want both to include 127.0.0.1", metrics.ResolvedIPs, metrics.TargetIP)
+	}
+}
+
+func TestCompressRequest_GzipBody(t *testing.T) {
+	var gotEncoding, gotBody string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotEncoding = r.Header.Get("Content-Encoding")
+		zr, err := gzip.NewReader(r.Body)
+		if err != nil {
+			t.Errorf("request body is not gzip: %v", err)
+			return
+		}
+		body, _ := io.ReadAll(zr)
+		gotBody = string(body)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"choices":[{"message":{"content":"ok"},"finish_reason":"stop"}],"usage":{"prompt_tokens":1,"completion_tokens":1}}`)
+	}))
+	defer server.Close()
+
+	config := createOpenAITestConfig(server.URL, "test-key", "gpt-4o", 30*time.Second, false)
+	config.CompressRequest = true
+	metrics, err := NewOpenAIClient(config).Request(context.Background(), "", strings.Repeat("长上下文", 100), false)
+	if err != nil {
+		t.Fatalf("Request() error = %v", err)
+	}
+	if gotEncoding != "gzip" {
+		t.Errorf("Content-Encoding = %q, want gzip", gotEncoding)
+	}
+	if gotBody != metrics.RequestBody || !strings.Contains(gotBody, "长上下文") {
+		t.Errorf("decompressed body = %q, want the original request body", gotBody)
+	}
+}
+
+func TestCompressRequest_HintOnRejection(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnsupportedMediaType)
+	}))
+	defer server.Close()
+
+	config := createOpenAITestConfig(server.URL, "test-key", "gpt-4o", 30*time.Second, false)
+	config.CompressRequest = true
+	metrics, _ := NewOpenAIClient(config).Request(context.Background(), "", "hello", false)
+	if metrics == nil || !strings.Contains(metrics.ErrorMessage, "compress_request") {
+		t.Errorf("415 with compression should hint at compress_request, got %+v", metrics)
+	}
+	if metrics != nil && i18n.Active() != i18n.EN && !strings.Contains(metrics.ErrorMessage, "\n提示: ") {
+		t.Errorf("zh hint should start with 提示:, got %q", metrics.ErrorMessage)
+	}
+
+	config.CompressRequest = false
+	metrics, _ = NewOpenAIClient(config).Request(context.Background(), "", "hello", false)
+	if metrics == nil || strings.Contains(metrics.ErrorMessage, "compress_request") {
+		t.Errorf("no compression hint expected without compress_request, got %+v", metrics)
+	}
+}
+
+func TestAcceptEncoding_DecodesAndCountsBytes(t *testing.T) {
+	body := `{"choices":[{"message":{"content":"` + strings.Repeat("压缩测试", 200) + `"},"finish_reason":"stop"}],"usage":{"prompt_tokens":1,"completion_tokens":5}}`
+	var gotAccept string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAccept = r.Header.Get("Accept-Encoding")
+		w.Header().Set("Content-Type", "application/json")
+		var zw io.WriteCloser
+		switch gotAccept {
+		case "gzip":
+			zw = gzip.NewWriter(w)
+		case "br":
+			zw = brotli.NewWriter(w)
+		default:
+			fmt.Fprint(w, body)
+			return
+		}
+		w.Header().Set("Content-Encoding", gotAccept)
+		fmt.Fprint(zw, body)
+		zw.Close()
+	}))
+	defer server.Close()
+
+	for _, tc := range []struct{ accept, wantHeader, wantEncoding string }{
+		{"gzip", "gzip", "gzip"},
+		{"br", "br", "br"},
+		{"identity", "identity", "identity"},
+	} {
+		config := createOpenAITestConfig(server.URL, "test-key", "gpt-4o", 30*time.Second, false)
+		config.AcceptEncoding = tc.accept
+		metrics, err := NewOpenAIClient(config).Request(context.Background(), "", "hello", false)
+		if err != nil {
+			t.Fatalf("%q: Request() error = %v", tc.accept, err)
+		}
+		if gotAccept != tc.wantHeader || metrics.Encoding != tc.wantEncoding {
+			t.Errorf("%q: Accept-Encoding = %q, Encoding = %q", tc.accept, gotAccept, metrics.Encoding)
+		}
+		if metrics.UncompressedBytes != int64(len(body)) || metrics.CompletionTokens != 5 {
+			t.Errorf("%q: UncompressedBytes = %d, want %d (CompletionTokens = %d)", tc.accept, metrics.UncompressedBytes, len(body), metrics.CompletionTokens)
+		}
+		compressed := tc.wantEncoding != "identity"
+		if compressed != (metrics.CompressedBytes < metrics.UncompressedBytes) || metrics.CompressedBytes <= 0 {
+			t.Errorf("%q: CompressedBytes = %d, UncompressedBytes = %d", tc.accept, metrics.CompressedBytes, metrics.UncompressedBytes)
+		}
+	}
+}
+
+func TestAcceptEncoding_StreamGzip(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Content-Encoding", "gzip")
+		zw := gzip.NewWriter(w)
+		for _, text := range []string{"Hel", "lo"} {
+			fmt.Fprintf(zw, "data: {\"choices\":[{\"delta\":{\"content\":%q}}]}\n\n", text)
+			zw.Flush()
+			w.(http.Flusher).Flush()
+		}
+		fmt.Fprint(zw, "data: {\"choices\":[{\"delta\":{},\"finish_reason\":\"stop\"}],\"usage\":{\"prompt_tokens\":1,\"completion_tokens\":2}}\n\ndata: [DONE]\n\n")
+		zw.Close()
+	}))
+	defer server.Close()
+
+	config := createOpenAITestConfig(server.URL, "test-key", "gpt-4o", 30*time.Second, true)
+	config.AcceptEncoding = types.AcceptEncodingGzip
+	metrics, err := NewOpenAIClient(config).Request(context.Background(), "", "hello", true)
+	if err != nil {
+		t.Fatalf("Request() error = %v", err)
+	}
+	if metrics.ResponseText != "Hello" || metrics.Encoding != "gzip" || metrics.CompressedBytes <= 0 || metrics.UncompressedBytes <= 0 {
+		t.Errorf("ResponseText = %q, Encoding = %q, bytes = %d / %d", metrics.ResponseText, metrics.Encoding, metrics.CompressedBytes, metrics.UncompressedBytes)
+	}
+
+	// 未设置时保持 Go 默认的透明解压，不记录压缩统计
+	config.AcceptEncoding = ""
+	metrics, err = NewOpenAIClient(config).Request(context.Background(), "", "hello", true)
+	if err != nil || metrics.ResponseText != "Hello" || metrics.Encoding != "" {
+		t.Errorf("default: err = %v, ResponseText = %q, Encoding = %q", err, metrics.ResponseText, metrics.Encoding)
+	}
+}
@@ -0,0 +1,181 @@
+package client
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Triton KServe v2 gRPC 协议（grpc_service.proto）的最小手写编解码。
+//
+// 只覆盖 vLLM backend 的 generate/generate_stream 所需字段，避免引入整套生成代码：
+//
+//	ModelInferRequest      { 1 model_name, 5 inputs, 6 outputs }
+//	InferInputTensor       { 1 name, 2 datatype, 3 shape, 5 contents }
+//	InferTensorContents    { 1 bool_contents, 8 bytes_contents }
+//	ModelStreamInferResponse { 1 error_message, 2 infer_response }
+//	ModelInferResponse     { 5 outputs, 6 raw_output_contents }
+//	InferOutputTensor      { 1 name, 5 contents }
+
+const (
+	tritonTextInput  = "text_input"
+	tritonStream     = "stream"
+	tritonExclude    = "exclude_input_in_output"
+	tritonTextOutput = "text_output"
+)
+
+// encodeTritonInferRequest 构造 vLLM backend 的 ModelInferRequest。
+func encodeTritonInferRequest(model, prompt string, stream bool) []byte {
+	var b []byte
+	b = protowire.AppendTag(b, 1, protowire.BytesType)
+	b = protowire.AppendString(b, model)
+
+	b = appendTritonInput(b, tritonTextInput, "BYTES", encodeTensorBytes([]byte(prompt)))
+	b = appendTritonInput(b, tritonStream, "BOOL", encodeTensorBool(stream))
+	b = appendTritonInput(b, tritonExclude, "BOOL", encodeTensorBool(true))
+
+	var output []byte
+	output = protowire.AppendTag(output, 1, protowire.BytesType)
+	output = protowire.AppendString(output, tritonTextOutput)
+	b = protowire.AppendTag(b, 6, protowire.BytesType)
+	b = protowire.AppendBytes(b, output)
+	return b
+}
+
+func appendTritonInput(b []byte, name, datatype string, contents []byte) []byte {
+	var tensor []byte
+	tensor = protowire.AppendTag(tensor, 1, protowire.BytesType)
+	tensor = protowire.AppendString(tensor, name)
+	tensor = protowire.AppendTag(tensor, 2, protowire.BytesType)
+	tensor = protowire.AppendString(tensor, datatype)
+	// shape: [1]，packed repeated int64
+	tensor = protowire.AppendTag(tensor, 3, protowire.BytesType)
+	tensor = protowire.AppendBytes(tensor, protowire.AppendVarint(nil, 1))
+	tensor = protowire.AppendTag(tensor, 5, protowire.BytesType)
+	tensor = protowire.AppendBytes(tensor, contents)
+
+	b = protowire.AppendTag(b, 5, protowire.BytesType)
+	return protowire.AppendBytes(b, tensor)
+}
+
+func encodeTensorBytes(v []byte) []byte {
+	b := protowire.AppendTag(nil, 8, protowire.BytesType)
+	return protowire.AppendBytes(b, v)
+}
+
+func encodeTensorBool(v bool) []byte {
+	b := protowire.AppendTag(nil, 1, protowire.BytesType)
+	return protowire.AppendBytes(b, protowire.AppendVarint(nil, protowire.EncodeBool(v)))
+}
+
+// decodeTritonStreamResponse 解析 ModelStreamInferResponse，返回 text_output 文本。
+// error_message 非空时返回错误。
+func decodeTritonStreamResponse(data []byte) (string, error) {
+	var errMsg string
+	var inferResponse []byte
+	err := rangeProtoFields(data, func(num protowire.Number, typ protowire.Type, v []byte) {
+		switch num {
+		case 1:
+			errMsg = string(v)
+		case 2:
+			inferResponse = v
+		}
+	})
+	if err != nil {
+		return "", err
+	}
+	if errMsg != "" {
+		return "", fmt.Errorf("triton error: %s", errMsg)
+	}
+	return decodeTritonInferResponse(inferResponse)
+}
+
+// decodeTritonInferResponse 解析 ModelInferResponse 中名为 text_output 的输出张量。
+// Triton 默认通过 raw_output_contents 返回数据，与 outputs 按下标一一对应；
+// 部分实现会直接填充 contents.bytes_contents，两种方式都兼容。
+func decodeTritonInferResponse(data []byte) (string, error) {
+	var outputNames []string
+	var outputContents [][]byte
+	var raw [][]byte
+	err := rangeProtoFields(data, func(num protowire.Number, typ protowire.Type, v []byte) {
+		switch num {
+		case 5:
+			var name string
+			var contents []byte
+			_ = rangeProtoFields(v, func(n protowire.Number, _ protowire.Type, fv []byte) {
+				switch n {
+				case 1:
+					name = string(fv)
+				case 5:
+					_ = rangeProtoFields(fv, func(cn protowire.Number, _ protowire.Type, cv []byte) {
+						if cn == 8 {
+							contents = append(contents, cv...)
+						}
+					})
+				}
+			})
+			outputNames = append(outputNames, name)
+			outputContents = append(outputContents, contents)
+		case 6:
+			raw = append(raw, v)
+		}
+	})
+	if err != nil {
+		return "", err
+	}
+
+	for i, name := range outputNames {
+		if name != tritonTextOutput {
+			continue
+		}
+		if len(outputContents[i]) > 0 {
+			return string(outputContents[i]), nil
+		}
+		if i < len(raw) {
+			return decodeTritonRawBytes(raw[i]), nil
+		}
+	}
+	return "", nil
+}
+
+// decodeTritonRawBytes 解析 BYTES 张量的原始编码：每个元素为 4 字节小端长度前缀 + 内容。
+func decodeTritonRawBytes(raw []byte) string {
+	var out []byte
+	for len(raw) >= 4 {
+		n := int(binary.LittleEndian.Uint32(raw[:4]))
+		raw = raw[4:]
+		if n > len(raw) {
+			n = len(raw)
+		}
+		out = append(out, raw[:n]...)
+		raw = raw[n:]
+	}
+	return string(out)
+}
+
+// rangeProtoFields 遍历消息中的长度分隔字段（其余字段类型直接跳过）。
+func rangeProtoFields(data []byte, fn func(num protowire.Number, typ protowire.Type, v []byte)) error {
+	for len(data) > 0 {
+		num, typ, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		data = data[n:]
+		if typ == protowire.BytesType {
+			v, m := protowire.ConsumeBytes(data)
+			if m < 0 {
+				return protowire.ParseError(m)
+			}
+			fn(num, typ, v)
+			data = data[m:]
+			continue
+		}
+		m := protowire.ConsumeFieldValue(num, typ, data)
+		if m < 0 {
+			return protowire.ParseError(m)
+		}
+		data = data[m:]
+	}
+	return nil
+}
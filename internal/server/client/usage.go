@@ -0,0 +1,128 @@
+package client
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// tokenUsage 一次响应的 token 统计
+type tokenUsage struct {
+	Prompt      int // 输入 token 数
+	Completion  int // 输出 token 数
+	CachedInput int // 命中缓存的输入 token 数
+	Thinking    int // 思考 token 数
+}
+
+// merge 用 v 中非零的字段覆盖 u。usage 可能分散在多个事件中下发，也可能在流末尾重复下发一个空 usage，
+// 只取非零值可以避免已读到的统计被清零。
+func (u *tokenUsage) merge(v tokenUsage) {
+	if v.Prompt > 0 {
+		u.Prompt = v.Prompt
+	}
+	if v.Completion > 0 {
+		u.Completion = v.Completion
+	}
+	if v.CachedInput > 0 {
+		u.CachedInput = v.CachedInput
+	}
+	if v.Thinking > 0 {
+		u.Thinking = v.Thinking
+	}
+}
+
+func (u tokenUsage) empty() bool {
+	return u == tokenUsage{}
+}
+
+// usagePayload 兼容 OpenAI Chat（prompt / completion）与 Responses、Anthropic（input / output）两种命名的 usage 对象
+type usagePayload struct {
+	PromptTokens            int                      `json:"prompt_tokens"`
+	CompletionTokens        int                      `json:"completion_tokens"`
+	InputTokens             int                      `json:"input_tokens"`
+	OutputTokens            int                      `json:"output_tokens"`
+	CacheReadInputTokens    int                      `json:"cache_read_input_tokens"`
+	PromptTokensDetails     *PromptTokensDetails     `json:"prompt_tokens_details,omitempty"`
+	InputTokensDetails      *PromptTokensDetails     `json:"input_tokens_details,omitempty"`
+	CompletionTokensDetails *CompletionTokensDetails `json:"completion_tokens_details,omitempty"`
+	OutputTokensDetails     *CompletionTokensDetails `json:"output_tokens_details,omitempty"`
+}
+
+func (p usagePayload) tokens() tokenUsage {
+	u := tokenUsage{
+		Prompt:      max(p.PromptTokens, p.InputTokens),
+		Completion:  max(p.CompletionTokens, p.OutputTokens),
+		CachedInput: max(extractCachedInputTokens(p.PromptTokensDetails), extractCachedInputTokens(p.InputTokensDetails)),
+		Thinking:    max(extractThinkingTokens(p.CompletionTokensDetails), extractThinkingTokens(p.OutputTokensDetails)),
+	}
+	u.CachedInput = max(u.CachedInput, p.CacheReadInputTokens)
+	return u
+}
+
+// parseUsage 解析单独下发的 usage：既可以是 {"usage": {...}}，也可以是裸的 usage 对象。
+// 没有任何 token 统计时 ok 为 false。
+func parseUsage(data []byte) (tokenUsage, bool) {
+	var wrapped struct {
+		Usage *usagePayload `json:"usage"`
+	}
+	if err := json.Unmarshal(data, &wrapped); err != nil {
+		return tokenUsage{}, false
+	}
+	if wrapped.Usage != nil {
+		u := wrapped.Usage.tokens()
+		return u, !u.empty()
+	}
+	var bare usagePayload
+	if err := json.Unmarshal(data, &bare); err != nil {
+		return tokenUsage{}, false
+	}
+	u := bare.tokens()
+	return u, !u.empty()
+}
+
+// usageTrailers 以 JSON 下发完整 usage 对象的 trailer 名
+var usageTrailers = []string{"Usage", "X-Usage", "X-Token-Usage"}
+
+// countTrailers 以整数下发单项 token 数的 trailer 名
+var countTrailers = map[string]func(*tokenUsage, int){
+	"X-Prompt-Tokens":     func(u *tokenUsage, n int) { u.Prompt = n },
+	"X-Input-Tokens":      func(u *tokenUsage, n int) { u.Prompt = n },
+	"X-Completion-Tokens": func(u *tokenUsage, n int) { u.Completion = n },
+	"X-Output-Tokens":     func(u *tokenUsage, n int) { u.Completion = n },
+	"X-Cached-Tokens":     func(u *tokenUsage, n int) { u.CachedInput = n },
+}
+
+// trailerUsage 从响应 trailer 中读取 token 统计：Usage / X-Usage / X-Token-Usage 为 JSON usage 对象，
+// X-Prompt-Tokens、X-Completion-Tokens 等为单项计数。没有可用统计时 ok 为 false。
+func trailerUsage(trailer http.Header) (tokenUsage, bool) {
+	var u tokenUsage
+	for _, name := range usageTrailers {
+		if v := strings.TrimSpace(trailer.Get(name)); v != "" {
+			if parsed, ok := parseUsage([]byte(v)); ok {
+				u.merge(parsed)
+			}
+		}
+	}
+	for name, set := range countTrailers {
+		if n, err := strconv.Atoi(strings.TrimSpace(trailer.Get(name))); err == nil && n > 0 {
+			set(&u, n)
+		}
+	}
+	return u, !u.empty()
+}
+
+// maxTrailerDrain 读取 trailer 前最多丢弃的剩余响应体字节数
+const maxTrailerDrain = 1 << 20
+
+// readTrailerUsage 在流式响应解析结束后读取 trailer 中的 token 统计。trailer 要等响应体读到 EOF 才可用，
+// 而解析可能在 [DONE] 处提前返回，因此只在服务端声明了 Trailer 时丢弃剩余响应体再读取，
+// 避免对未声明 trailer、且 [DONE] 后不关闭连接的服务端阻塞。
+func readTrailerUsage(resp *http.Response) (tokenUsage, bool) {
+	if len(resp.Trailer) == 0 {
+		return tokenUsage{}, false
+	}
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxTrailerDrain))
+	return trailerUsage(resp.Trailer)
+}
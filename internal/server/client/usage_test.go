@@ -0,0 +1,59 @@
+package client
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestParseUsage(t *testing.T) {
+	cases := map[string]struct {
+		data string
+		want tokenUsage
+		ok   bool
+	}{
+		"wrapped chat":  {`{"usage":{"prompt_tokens":10,"completion_tokens":5,"prompt_tokens_details":{"cached_tokens":4}}}`, tokenUsage{Prompt: 10, Completion: 5, CachedInput: 4}, true},
+		"bare chat":     {`{"prompt_tokens":10,"completion_tokens":5,"completion_tokens_details":{"reasoning_tokens":2}}`, tokenUsage{Prompt: 10, Completion: 5, Thinking: 2}, true},
+		"input/output":  {`{"input_tokens":8,"output_tokens":3,"cache_read_input_tokens":6}`, tokenUsage{Prompt: 8, Completion: 3, CachedInput: 6}, true},
+		"null usage":    {`{"choices":[],"usage":null}`, tokenUsage{}, false},
+		"zero usage":    {`{"usage":{"prompt_tokens":0,"completion_tokens":0}}`, tokenUsage{}, false},
+		"not json":      {`oops`, tokenUsage{}, false},
+		"unrelated obj": {`{"id":"x"}`, tokenUsage{}, false},
+	}
+	for name, c := range cases {
+		got, ok := parseUsage([]byte(c.data))
+		if got != c.want || ok != c.ok {
+			t.Errorf("%s: got %+v, %v; want %+v, %v", name, got, ok, c.want, c.ok)
+		}
+	}
+}
+
+func TestTokenUsageMerge_KeepsNonZero(t *testing.T) {
+	u := tokenUsage{Prompt: 10, Completion: 5, CachedInput: 2}
+	u.merge(tokenUsage{Completion: 7})
+	if want := (tokenUsage{Prompt: 10, Completion: 7, CachedInput: 2}); u != want {
+		t.Errorf("merge = %+v, want %+v", u, want)
+	}
+}
+
+func TestTrailerUsage(t *testing.T) {
+	h := http.Header{}
+	h.Set("X-Usage", `{"prompt_tokens":12,"completion_tokens":30}`)
+	h.Set("X-Cached-Tokens", "4")
+	if got, ok := trailerUsage(h); !ok || got != (tokenUsage{Prompt: 12, Completion: 30, CachedInput: 4}) {
+		t.Errorf("json trailer = %+v, %v", got, ok)
+	}
+
+	h = http.Header{}
+	h.Set("X-Prompt-Tokens", "9")
+	h.Set("X-Completion-Tokens", "21")
+	if got, ok := trailerUsage(h); !ok || got != (tokenUsage{Prompt: 9, Completion: 21}) {
+		t.Errorf("count trailers = %+v, %v", got, ok)
+	}
+
+	h = http.Header{}
+	h.Set("X-Usage", "garbage")
+	h.Set("X-Output-Tokens", "abc")
+	if got, ok := trailerUsage(h); ok {
+		t.Errorf("invalid trailers = %+v, want none", got)
+	}
+}
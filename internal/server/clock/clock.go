@@ -0,0 +1,38 @@
+// Package clock 提供可注入的时间与随机源：生产代码使用真实时钟，测试注入 Fake 瞬时推进时间，
+// 使依赖时间的断言（耗时、进度刷新、退避重试）变为确定性的。
+package clock
+
+import "time"
+
+// Clock 时间源。Runner、进度 ticker、退避重试等通过它获取时间与定时器。
+type Clock interface {
+	Now() time.Time
+	Since(t time.Time) time.Duration
+	After(d time.Duration) <-chan time.Time
+	NewTicker(d time.Duration) Ticker
+}
+
+// Ticker 周期定时器，对应 time.Ticker。
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+}
+
+// Real 返回基于 time 包的真实时钟。
+func Real() Clock {
+	return realClock{}
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) Since(t time.Time) time.Duration        { return time.Since(t) }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+func (realClock) NewTicker(d time.Duration) Ticker       { return realTicker{time.NewTicker(d)} }
+
+type realTicker struct {
+	t *time.Ticker
+}
+
+func (t realTicker) C() <-chan time.Time { return t.t.C }
+func (t realTicker) Stop()               { t.t.Stop() }
@@ -0,0 +1,122 @@
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+var epoch = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func TestFake_AfterFiresOnAdvance(t *testing.T) {
+	f := NewFake(epoch)
+	ch := f.After(100 * time.Millisecond)
+
+	f.Advance(99 * time.Millisecond)
+	select {
+	case <-ch:
+		t.Fatal("After fired before its deadline")
+	default:
+	}
+
+	f.Advance(time.Millisecond)
+	select {
+	case at := <-ch:
+		if want := epoch.Add(100 * time.Millisecond); !at.Equal(want) {
+			t.Errorf("fired at %v, want %v", at, want)
+		}
+	default:
+		t.Fatal("After did not fire at its deadline")
+	}
+	if f.Waiters() != 0 {
+		t.Errorf("Waiters = %d, want 0 after firing", f.Waiters())
+	}
+	if got := f.Since(epoch); got != 100*time.Millisecond {
+		t.Errorf("Since = %v, want 100ms", got)
+	}
+}
+
+func TestFake_AfterNonPositiveIsReady(t *testing.T) {
+	f := NewFake(epoch)
+	select {
+	case <-f.After(0):
+	default:
+		t.Fatal("After(0) should be ready immediately")
+	}
+}
+
+func TestFake_TickerReschedulesAndStops(t *testing.T) {
+	f := NewFake(epoch)
+	ticker := f.NewTicker(time.Second)
+
+	var ticks []time.Duration
+	for i := 0; i < 3; i++ {
+		f.Advance(time.Second)
+		ticks = append(ticks, (<-ticker.C()).Sub(epoch))
+	}
+	if want := []time.Duration{time.Second, 2 * time.Second, 3 * time.Second}; len(ticks) != 3 || ticks[0] != want[0] || ticks[2] != want[2] {
+		t.Errorf("ticks = %v, want %v", ticks, want)
+	}
+
+	// 接收方未读取时多余的触发被丢弃
+	f.Advance(5 * time.Second)
+	<-ticker.C()
+	select {
+	case <-ticker.C():
+		t.Error("ticker buffered more than one tick")
+	default:
+	}
+
+	ticker.Stop()
+	f.Advance(time.Second)
+	select {
+	case <-ticker.C():
+		t.Error("stopped ticker fired")
+	default:
+	}
+}
+
+func TestFake_AdvanceFiresInDeadlineOrder(t *testing.T) {
+	f := NewFake(epoch)
+	late := f.After(300 * time.Millisecond)
+	early := f.After(100 * time.Millisecond)
+
+	f.Advance(time.Second)
+	if at := <-early; at.Sub(epoch) != 100*time.Millisecond {
+		t.Errorf("early fired at +%v, want +100ms", at.Sub(epoch))
+	}
+	if at := <-late; at.Sub(epoch) != 300*time.Millisecond {
+		t.Errorf("late fired at +%v, want +300ms", at.Sub(epoch))
+	}
+	if got := f.Now().Sub(epoch); got != time.Second {
+		t.Errorf("Now = +%v, want +1s", got)
+	}
+}
+
+func TestFake_BlockUntil(t *testing.T) {
+	f := NewFake(epoch)
+	done := make(chan struct{})
+	go func() {
+		<-f.After(time.Minute)
+		close(done)
+	}()
+
+	f.BlockUntil(1)
+	f.Advance(time.Minute)
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("goroutine waiting on After was not released")
+	}
+}
+
+func TestNewRand_Deterministic(t *testing.T) {
+	a, b := NewRand(42), NewRand(42)
+	for i := 0; i < 10; i++ {
+		if x, y := a.Intn(1000), b.Intn(1000); x != y {
+			t.Fatalf("draw %d: %d != %d with the same seed", i, x, y)
+		}
+	}
+	if v := a.Float64(); v < 0 || v >= 1 {
+		t.Errorf("Float64 = %v, want [0, 1)", v)
+	}
+}
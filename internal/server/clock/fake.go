@@ -0,0 +1,137 @@
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+// Fake 测试用的假时钟：时间只在调用 Advance 时推进，到期的 After / Ticker 按到期时间先后触发。
+// 被测 goroutine 注册等待后，测试用 BlockUntil 同步再 Advance，即可瞬时走完任意长的等待。
+type Fake struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	now     time.Time
+	waiters []*fakeWaiter
+}
+
+// fakeWaiter 一个等待中的 After 或 Ticker；period 大于 0 时为 Ticker，触发后按周期重新排期。
+type fakeWaiter struct {
+	at     time.Time
+	period time.Duration
+	ch     chan time.Time
+}
+
+// NewFake 创建起始时间为 start 的假时钟。
+func NewFake(start time.Time) *Fake {
+	f := &Fake{now: start}
+	f.cond = sync.NewCond(&f.mu)
+	return f
+}
+
+func (f *Fake) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+func (f *Fake) Since(t time.Time) time.Duration {
+	return f.Now().Sub(t)
+}
+
+// After 返回在假时间推进 d 后收到时间的 channel；d <= 0 时立即可读。
+func (f *Fake) After(d time.Duration) <-chan time.Time {
+	ch := make(chan time.Time, 1)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if d <= 0 {
+		ch <- f.now
+		return ch
+	}
+	f.add(&fakeWaiter{at: f.now.Add(d), ch: ch})
+	return ch
+}
+
+// NewTicker 返回按假时间周期触发的 Ticker；与 time.Ticker 一样，接收方来不及读取时丢弃多余的触发。
+func (f *Fake) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("clock: non-positive interval for NewTicker")
+	}
+	w := &fakeWaiter{period: d, ch: make(chan time.Time, 1)}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	w.at = f.now.Add(d)
+	f.add(w)
+	return &fakeTicker{clock: f, waiter: w}
+}
+
+func (f *Fake) add(w *fakeWaiter) {
+	f.waiters = append(f.waiters, w)
+	f.cond.Broadcast()
+}
+
+func (f *Fake) remove(w *fakeWaiter) {
+	for i, v := range f.waiters {
+		if v == w {
+			f.waiters = append(f.waiters[:i], f.waiters[i+1:]...)
+			return
+		}
+	}
+}
+
+// Advance 把假时间推进 d，期间到期的等待按到期时间先后触发。
+func (f *Fake) Advance(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	target := f.now.Add(d)
+	for {
+		var next *fakeWaiter
+		for _, w := range f.waiters {
+			if !w.at.After(target) && (next == nil || w.at.Before(next.at)) {
+				next = w
+			}
+		}
+		if next == nil {
+			break
+		}
+		f.now = next.at
+		select {
+		case next.ch <- f.now:
+		default:
+		}
+		if next.period > 0 {
+			next.at = next.at.Add(next.period)
+		} else {
+			f.remove(next)
+		}
+	}
+	f.now = target
+}
+
+// Waiters 返回当前等待中的 After 与 Ticker 个数。
+func (f *Fake) Waiters() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.waiters)
+}
+
+// BlockUntil 阻塞直到至少有 n 个等待中的 After 或 Ticker，用于确认被测 goroutine 已开始等待。
+func (f *Fake) BlockUntil(n int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for len(f.waiters) < n {
+		f.cond.Wait()
+	}
+}
+
+type fakeTicker struct {
+	clock  *Fake
+	waiter *fakeWaiter
+}
+
+func (t *fakeTicker) C() <-chan time.Time { return t.waiter.ch }
+
+func (t *fakeTicker) Stop() {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	t.clock.remove(t.waiter)
+}
@@ -0,0 +1,44 @@
+package clock
+
+import (
+	"math/rand"
+	"sync"
+)
+
+// Rand 随机源，采样、随机选端点等通过它取随机数，测试可注入固定种子的实现。
+type Rand interface {
+	Float64() float64
+	Intn(n int) int
+}
+
+// NewRand 返回以 seed 为种子、可并发使用的随机源。
+func NewRand(seed int64) Rand {
+	return &lockedRand{r: rand.New(rand.NewSource(seed))}
+}
+
+// DefaultRand 返回使用 math/rand 全局随机数的随机源。
+func DefaultRand() Rand {
+	return globalRand{}
+}
+
+type lockedRand struct {
+	mu sync.Mutex
+	r  *rand.Rand
+}
+
+func (l *lockedRand) Float64() float64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.r.Float64()
+}
+
+func (l *lockedRand) Intn(n int) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.r.Intn(n)
+}
+
+type globalRand struct{}
+
+func (globalRand) Float64() float64 { return rand.Float64() }
+func (globalRand) Intn(n int) int   { return rand.Intn(n) }
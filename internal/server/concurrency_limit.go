@@ -0,0 +1,152 @@
+package server
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/yinxulai/ait/internal/server/types"
+)
+
+// ConcurrencyLimit 运行期间可调整的并发上限：同时执行的请求数不超过当前上限。
+// 调低上限时正在执行的请求不受影响，只是在活跃数降到新上限以下之前不再派发新请求。
+// 暂停时同样只是不再派发新请求，恢复后继续。
+type ConcurrencyLimit struct {
+	mu      sync.Mutex
+	limit   int
+	active  int
+	wake    chan struct{} // 上限变化、暂停状态变化或有请求结束时关闭并替换，唤醒等待派发的调度循环
+	changes []concurrencyChange
+
+	paused bool
+	pauses []pauseSpan
+}
+
+// pauseSpan 一次暂停的起止时间，end 为零值表示仍在暂停。
+type pauseSpan struct {
+	start, end time.Time
+}
+
+type concurrencyChange struct {
+	at          time.Time
+	concurrency int
+}
+
+// NewConcurrencyLimit 以初始上限 n 创建 ConcurrencyLimit，n 小于 1 时按 1 处理。
+func NewConcurrencyLimit(n int) *ConcurrencyLimit {
+	return &ConcurrencyLimit{limit: max(n, 1), wake: make(chan struct{})}
+}
+
+// Limit 返回当前并发上限。
+func (l *ConcurrencyLimit) Limit() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.limit
+}
+
+// Adjust 把并发上限增减 delta（最小为 1），返回调整后的上限。
+func (l *ConcurrencyLimit) Adjust(delta int) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	next := max(l.limit+delta, 1)
+	if next == l.limit {
+		return next
+	}
+	l.limit = next
+	l.changes = append(l.changes, concurrencyChange{at: time.Now(), concurrency: next})
+	l.notifyLocked()
+	return next
+}
+
+// SetPaused 暂停或恢复派发新请求，状态有变化时返回 true。
+func (l *ConcurrencyLimit) SetPaused(paused bool) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.paused == paused {
+		return false
+	}
+	l.paused = paused
+	now := time.Now()
+	if paused {
+		l.pauses = append(l.pauses, pauseSpan{start: now})
+	} else {
+		l.pauses[len(l.pauses)-1].end = now
+		l.notifyLocked()
+	}
+	return true
+}
+
+// Paused 返回当前是否处于暂停状态。
+func (l *ConcurrencyLimit) Paused() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.paused
+}
+
+// PausedTime 返回 since 之后累计暂停的时长，仍在暂停时计到当前时刻。
+func (l *ConcurrencyLimit) PausedTime(since time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+	var total time.Duration
+	for _, p := range l.pauses {
+		end := p.end
+		if end.IsZero() {
+			end = now
+		}
+		start := p.start
+		if start.Before(since) {
+			start = since
+		}
+		if end.After(start) {
+			total += end.Sub(start)
+		}
+	}
+	return total
+}
+
+// Changes 返回 since 之后的上限调整记录，At 为相对 since 的偏移。
+func (l *ConcurrencyLimit) Changes(since time.Time) []types.ConcurrencyChange {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var out []types.ConcurrencyChange
+	for _, c := range l.changes {
+		if c.at.Before(since) {
+			continue
+		}
+		out = append(out, types.ConcurrencyChange{At: c.at.Sub(since), Concurrency: c.concurrency})
+	}
+	return out
+}
+
+// acquire 等待未暂停且活跃请求数低于上限后占用一个名额；ctx 取消时返回 false。
+func (l *ConcurrencyLimit) acquire(ctx context.Context) bool {
+	for {
+		l.mu.Lock()
+		if !l.paused && l.active < l.limit {
+			l.active++
+			l.mu.Unlock()
+			return true
+		}
+		wake := l.wake
+		l.mu.Unlock()
+		select {
+		case <-ctx.Done():
+			return false
+		case <-wake:
+		}
+	}
+}
+
+// release 归还 acquire 占用的名额。
+func (l *ConcurrencyLimit) release() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.active--
+	l.notifyLocked()
+}
+
+func (l *ConcurrencyLimit) notifyLocked() {
+	close(l.wake)
+	l.wake = make(chan struct{})
+}
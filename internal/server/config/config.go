@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"strings"
 
 	storepkg "github.com/yinxulai/ait/internal/server/store"
 )
@@ -10,6 +11,7 @@ import (
 const (
 	appDirName    = ".ait"
 	configJSON    = "config.json"
+	stateJSON     = "state.json"
 	tasksDirName  = "tasks"
 	runsDirName   = "runs"
 	runMetaJSON   = "run.json"
@@ -23,6 +25,17 @@ type Config struct {
 	DefaultProtocol    string `json:"default_protocol,omitempty"`
 	ProxyURL           string `json:"proxy_url,omitempty"`
 	Lang               string `json:"lang,omitempty"` // "zh" or "en", empty = zh
+	// ModelAliases 全局模型别名映射：真实模型 ID → 显示名。
+	// 任务未单独设置 model_alias 时按此映射展示。
+	ModelAliases map[string]string `json:"model_aliases,omitempty"`
+}
+
+// ModelAlias 返回模型 ID 对应的全局别名，未配置时返回空字符串。
+func (c *Config) ModelAlias(model string) string {
+	if c == nil || len(c.ModelAliases) == 0 {
+		return ""
+	}
+	return strings.TrimSpace(c.ModelAliases[strings.TrimSpace(model)])
 }
 
 func Load() (*Config, error) {
@@ -128,3 +141,38 @@ func RunRequestsPath(taskID, runID string) (string, error) {
 	}
 	return filepath.Join(dir, runReqsJSONL), nil
 }
+
+// State 程序自身记录的运行状态（区别于用户可编辑的 Config），保存在 ~/.ait/state.json。
+type State struct {
+	// TelemetryNoticeShown 是否已打印过遥测上报说明，首次运行时打印一次
+	TelemetryNoticeShown bool `json:"telemetry_notice_shown,omitempty"`
+}
+
+func StatePath() (string, error) {
+	dir, err := AppDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, stateJSON), nil
+}
+
+// LoadState 读取 state.json，文件不存在时返回零值。
+func LoadState() (*State, error) {
+	path, err := StatePath()
+	if err != nil {
+		return nil, err
+	}
+	loaded, err := storepkg.NewJSONStore[State](path).Load()
+	if err != nil {
+		return nil, err
+	}
+	return &loaded, nil
+}
+
+func (s *State) Save() error {
+	path, err := StatePath()
+	if err != nil {
+		return err
+	}
+	return storepkg.NewJSONStore[State](path).Save(*s)
+}
@@ -50,6 +50,22 @@ func TestConfigSaveAndLoadRoundTrip(t *testing.T) {
 	}
 }
 
+func TestModelAliasLookup(t *testing.T) {
+	cfg := &Config{ModelAliases: map[string]string{
+		"accounts/fireworks/models/llama-v3p1-70b-instruct": " Llama70B ",
+	}}
+	if got := cfg.ModelAlias("accounts/fireworks/models/llama-v3p1-70b-instruct"); got != "Llama70B" {
+		t.Fatalf("ModelAlias() = %q, want Llama70B", got)
+	}
+	if got := cfg.ModelAlias("gpt-4o"); got != "" {
+		t.Fatalf("ModelAlias() for unmapped model = %q, want empty", got)
+	}
+	var nilCfg *Config
+	if got := nilCfg.ModelAlias("gpt-4o"); got != "" {
+		t.Fatalf("nil ModelAlias() = %q, want empty", got)
+	}
+}
+
 func TestStoragePaths(t *testing.T) {
 	homeDir := t.TempDir()
 	t.Setenv("HOME", homeDir)
@@ -0,0 +1,174 @@
+package config
+
+import (
+	"math"
+
+	"github.com/yinxulai/ait/internal/server/client"
+	"github.com/yinxulai/ait/internal/server/prompt"
+	"github.com/yinxulai/ait/internal/server/task"
+	"github.com/yinxulai/ait/internal/server/types"
+)
+
+// ModelPrice 模型单价：每百万输入 / 输出 token 的价格，货币单位由计划文件自行约定
+type ModelPrice struct {
+	Input  float64 `json:"input"`
+	Output float64 `json:"output"`
+}
+
+// 预计输出 token 的来源
+const (
+	OutputFromMaxTokens = "max_tokens" // 任务设置的 max_tokens（或 Anthropic 协议的默认值），为上限
+	OutputFromHistory   = "history"    // 同模型历史运行的平均输出 token 数
+)
+
+// estimateSamplePrompts 估算平均输入 token 时最多采样的 prompt 条数
+const estimateSamplePrompts = 100
+
+// ScenarioEstimate 测试计划中单个场景的预估。
+type ScenarioEstimate struct {
+	Name  string
+	Model string
+
+	// Requests 预计请求数；Unbounded 为 true 时（turbo / integrity 模式）请求数由运行过程决定，无法预估
+	Requests  int
+	Unbounded bool
+
+	InputTokens int64 // 预计总输入 token（按 prompt 粗略估算）
+
+	// 预计总输出 token：OutputSource 为 max_tokens 时 OutputMax 为上限、OutputMin 取历史均值（没有历史时为 0），
+	// 为 history 时两者都取历史均值；为空表示既没有上限也没有历史，无法估算
+	OutputMin, OutputMax int64
+	OutputSource         string
+
+	// 预计费用区间，仅 Priced 为 true（计划文件为该模型配置了价格）时有效
+	Priced           bool
+	CostMin, CostMax float64
+}
+
+// PlanEstimate 测试计划的整体预估，各项为所有场景之和。
+type PlanEstimate struct {
+	Scenarios []ScenarioEstimate
+
+	Requests             int
+	InputTokens          int64
+	OutputMin, OutputMax int64
+	CostMin, CostMax     float64
+
+	// Partial 有场景的请求数或输出 token 无法预估，合计值只是下限
+	Partial bool
+	// Unpriced 没有配置价格的模型，合计费用不含这些模型
+	Unpriced []string
+}
+
+// EstimatePlan 预估测试计划的请求数、token 消耗与费用。avgOutput 为各模型历史运行的平均单请求输出 token 数，
+// 没有设置 max_tokens 的场景用它估算输出；价格取自计划文件的 prices。
+func EstimatePlan(plan Plan, avgOutput map[string]float64) PlanEstimate {
+	var est PlanEstimate
+	for _, scenario := range plan.Scenarios {
+		s := estimateScenario(scenario, avgOutput[scenario.Input.Model])
+		if price, ok := plan.Prices[scenario.Input.Model]; ok {
+			s.Priced = true
+			input := float64(s.InputTokens) * price.Input / 1e6
+			s.CostMin = input + float64(s.OutputMin)*price.Output/1e6
+			s.CostMax = input + float64(s.OutputMax)*price.Output/1e6
+		}
+
+		est.Requests += s.Requests
+		est.InputTokens += s.InputTokens
+		est.OutputMin += s.OutputMin
+		est.OutputMax += s.OutputMax
+		if s.Unbounded || s.OutputSource == "" {
+			est.Partial = true
+		}
+		if s.Priced {
+			est.CostMin += s.CostMin
+			est.CostMax += s.CostMax
+		} else {
+			est.Unpriced = appendModel(est.Unpriced, s.Model)
+		}
+		est.Scenarios = append(est.Scenarios, s)
+	}
+	return est
+}
+
+func estimateScenario(scenario PlanScenario, avgOutput float64) ScenarioEstimate {
+	input := scenario.Input
+	s := ScenarioEstimate{Name: scenario.Name, Model: input.Model}
+
+	var perRequestInput float64
+	switch {
+	case input.RunMode() != "standard":
+		s.Unbounded = true
+	case len(input.InputLengthSweep) > 0:
+		// 扫描的各档长度即为 token 数，每档各跑 count 个请求
+		s.Requests = input.Count * len(input.InputLengthSweep)
+		var sum int
+		for _, length := range input.InputLengthSweep {
+			sum += length
+		}
+		perRequestInput = float64(sum) / float64(len(input.InputLengthSweep))
+	default:
+		s.Requests = input.Count
+		if input.CompareStream {
+			streamCount, nonStreamCount := input.CompareStreamCounts()
+			s.Requests = streamCount + nonStreamCount
+		} else if n := promptFileScenarioCount(input); n > 1 {
+			// 按 prompt 文件分场景运行时每个文件各跑 count 个请求
+			s.Requests = input.Count * n
+		}
+		perRequestInput = averagePromptTokens(input)
+	}
+	s.InputTokens = int64(math.Round(perRequestInput * float64(s.Requests)))
+
+	switch limit := client.OutputTokenLimit(input); {
+	case limit > 0:
+		s.OutputSource = OutputFromMaxTokens
+		s.OutputMax = int64(limit) * int64(s.Requests)
+		s.OutputMin = min(int64(math.Round(avgOutput*float64(s.Requests))), s.OutputMax)
+	case avgOutput > 0:
+		s.OutputSource = OutputFromHistory
+		s.OutputMax = int64(math.Round(avgOutput * float64(s.Requests)))
+		s.OutputMin = s.OutputMax
+	}
+	return s
+}
+
+// promptFileScenarioCount 返回按 prompt 文件分场景运行的场景数（匹配到的文件数），未开启或不是文件 prompt 时返回 0。
+func promptFileScenarioCount(input types.Input) int {
+	if !input.PromptFilePerRun || input.PromptMode != "file" {
+		return 0
+	}
+	source, err := prompt.LoadPromptsFromFile(input.PromptFile)
+	if err != nil {
+		return 0
+	}
+	return len(source.FileScenarios())
+}
+
+// averagePromptTokens 按任务的 prompt 配置估算单个请求的平均输入 token 数（system 内容 + 采样的各条 prompt 均值）；
+// prompt 无法加载时返回 0。
+func averagePromptTokens(input types.Input) float64 {
+	hydrated, err := task.HydrateInput(input)
+	if err != nil || hydrated.PromptSource == nil {
+		return 0
+	}
+	source := hydrated.PromptSource
+	n := min(source.Count(), estimateSamplePrompts)
+	if n <= 0 {
+		return 0
+	}
+	var sum int
+	for i := range n {
+		sum += prompt.EstimateTokens(source.GetContentByIndex(i))
+	}
+	return float64(prompt.EstimateTokens(source.GetSystemContent())) + float64(sum)/float64(n)
+}
+
+func appendModel(models []string, model string) []string {
+	for _, m := range models {
+		if m == model {
+			return models
+		}
+	}
+	return append(models, model)
+}
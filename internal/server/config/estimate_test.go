@@ -0,0 +1,63 @@
+package config
+
+import (
+	"math"
+	"testing"
+
+	"github.com/yinxulai/ait/internal/server/types"
+)
+
+func TestEstimatePlan(t *testing.T) {
+	plan := Plan{
+		Scenarios: []PlanScenario{
+			// 8 个字符 → 2 tokens；max_tokens 为上限，历史均值 50 为下限
+			{Name: "capped", Input: types.Input{Protocol: "openai", Model: "a", Count: 10, PromptText: "abcdefgh", MaxTokens: 100}},
+			// 无 max_tokens，按历史均值估算；A/B 对比请求数翻倍
+			{Name: "history", Input: types.Input{Protocol: "openai", Model: "a", Count: 5, PromptText: "你好", CompareStream: true}},
+			// 长度扫描：每档各 2 个请求，输入取各档长度
+			{Name: "sweep", Input: types.Input{Protocol: "openai", Model: "b", Count: 2, PromptMode: "generated", InputLengthSweep: []int{100, 300}}},
+		},
+		Prices: map[string]ModelPrice{"a": {Input: 1, Output: 2}},
+	}
+	est := EstimatePlan(plan, map[string]float64{"a": 50})
+
+	capped, history, sweep := est.Scenarios[0], est.Scenarios[1], est.Scenarios[2]
+	if capped.Requests != 10 || capped.InputTokens != 20 || capped.OutputSource != OutputFromMaxTokens ||
+		capped.OutputMin != 500 || capped.OutputMax != 1000 {
+		t.Errorf("capped = %+v", capped)
+	}
+	if !capped.Priced || math.Abs(capped.CostMin-0.00102) > 1e-12 || math.Abs(capped.CostMax-0.00202) > 1e-12 {
+		t.Errorf("capped cost = %v ~ %v, want 0.00102 ~ 0.00202", capped.CostMin, capped.CostMax)
+	}
+	if history.Requests != 10 || history.InputTokens != 20 || history.OutputSource != OutputFromHistory ||
+		history.OutputMin != 500 || history.OutputMax != 500 {
+		t.Errorf("history = %+v", history)
+	}
+	if sweep.Requests != 4 || sweep.InputTokens != 800 || sweep.OutputSource != "" || sweep.Priced {
+		t.Errorf("sweep = %+v", sweep)
+	}
+
+	if est.Requests != 24 || est.InputTokens != 840 || est.OutputMin != 1000 || est.OutputMax != 1500 {
+		t.Errorf("totals = %+v", est)
+	}
+	if !est.Partial || len(est.Unpriced) != 1 || est.Unpriced[0] != "b" {
+		t.Errorf("Partial = %v, Unpriced = %v", est.Partial, est.Unpriced)
+	}
+}
+
+func TestEstimatePlan_AnthropicDefaultAndTurbo(t *testing.T) {
+	est := EstimatePlan(Plan{Scenarios: []PlanScenario{
+		{Name: "claude", Input: types.Input{Protocol: types.ProtocolAnthropicMessages, Model: "c", Count: 2, PromptText: "hi"}},
+		{Name: "turbo", Input: types.Input{Protocol: "openai", Model: "c", Mode: "turbo", PromptText: "hi"}},
+	}}, nil)
+
+	if s := est.Scenarios[0]; s.OutputSource != OutputFromMaxTokens || s.OutputMax != 2048 || s.OutputMin != 0 {
+		t.Errorf("anthropic default = %+v, want max_tokens 1024 per request", s)
+	}
+	if s := est.Scenarios[1]; !s.Unbounded || s.Requests != 0 {
+		t.Errorf("turbo = %+v, want unbounded", s)
+	}
+	if !est.Partial {
+		t.Error("turbo scenario should make the estimate partial")
+	}
+}
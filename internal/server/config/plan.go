@@ -0,0 +1,116 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/yinxulai/ait/internal/server/types"
+)
+
+// Plan 测试计划：一次依次执行的多个场景，每个场景是一份完整的任务配置。
+type Plan struct {
+	Name      string
+	Scenarios []PlanScenario
+	Prices    map[string]ModelPrice // 按模型名配置的单价，用于预估费用
+}
+
+// PlanScenario 测试计划中的一个场景。
+type PlanScenario struct {
+	Name  string
+	Input types.Input
+}
+
+// planFile 测试计划文件的 JSON 结构。defaults 为各场景共用的 input 字段，
+// 场景 input 中的同名字段覆盖 defaults；prices 为按模型名配置的单价（每百万 token）。
+type planFile struct {
+	Name      string                `json:"name"`
+	Defaults  json.RawMessage       `json:"defaults"`
+	Prices    map[string]ModelPrice `json:"prices"`
+	Scenarios []struct {
+		Name  string          `json:"name"`
+		Input json.RawMessage `json:"input"`
+	} `json:"scenarios"`
+}
+
+// LoadPlan 读取并校验测试计划文件（JSON）。每个场景按 defaults 合并后，
+// 使用与 ait validate --config 相同的规则校验；存在问题时返回汇总了全部问题的错误。
+func LoadPlan(path string) (Plan, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return Plan{}, fmt.Errorf("仅支持 JSON 格式的测试计划")
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Plan{}, err
+	}
+	return ParsePlan(data)
+}
+
+// ParsePlan 解析并校验 JSON 形式的测试计划，见 LoadPlan。
+func ParsePlan(data []byte) (Plan, error) {
+	var file planFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return Plan{}, fmt.Errorf("JSON 解析失败: %v", err)
+	}
+	if len(file.Scenarios) == 0 {
+		return Plan{}, fmt.Errorf("scenarios: 至少需要一个场景")
+	}
+	defaults := map[string]json.RawMessage{}
+	if len(file.Defaults) > 0 {
+		if err := json.Unmarshal(file.Defaults, &defaults); err != nil {
+			return Plan{}, fmt.Errorf("defaults: 类型错误，应为 object")
+		}
+	}
+
+	plan := Plan{Name: strings.TrimSpace(file.Name), Prices: file.Prices}
+	var problems []string
+	for _, model := range slices.Sorted(maps.Keys(file.Prices)) {
+		if price := file.Prices[model]; price.Input < 0 || price.Output < 0 {
+			problems = append(problems, fmt.Sprintf("prices.%s: 价格不能为负数", model))
+		}
+	}
+	for i, s := range file.Scenarios {
+		prefix := fmt.Sprintf("scenarios[%d]", i)
+		input, err := mergeInput(defaults, s.Input)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s.input: %v", prefix, err))
+			continue
+		}
+		task, _ := json.Marshal(map[string]any{"name": s.Name, "input": input})
+		for _, issue := range ValidateTask(task) {
+			problems = append(problems, fmt.Sprintf("%s.%s: %s", prefix, issue.Field, issue.Problem))
+		}
+		scenario := PlanScenario{Name: strings.TrimSpace(s.Name)}
+		if err := json.Unmarshal(input, &scenario.Input); err != nil {
+			continue // 类型错误已由 ValidateTask 报告
+		}
+		plan.Scenarios = append(plan.Scenarios, scenario)
+	}
+	if len(problems) > 0 {
+		return Plan{}, fmt.Errorf("测试计划校验失败:\n  %s", strings.Join(problems, "\n  "))
+	}
+	return plan, nil
+}
+
+// mergeInput 把场景 input 合并到 defaults 之上（浅合并，场景字段整体覆盖同名默认字段）。
+func mergeInput(defaults map[string]json.RawMessage, input json.RawMessage) (json.RawMessage, error) {
+	merged := make(map[string]json.RawMessage, len(defaults))
+	for k, v := range defaults {
+		merged[k] = v
+	}
+	if len(input) > 0 {
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(input, &fields); err != nil {
+			return nil, fmt.Errorf("类型错误，应为 object")
+		}
+		for k, v := range fields {
+			merged[k] = v
+		}
+	}
+	return json.Marshal(merged)
+}
@@ -0,0 +1,84 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParsePlan_MergesDefaults(t *testing.T) {
+	plan, err := ParsePlan([]byte(`{
+		"name": "nightly",
+		"defaults": {"protocol":"openai","model":"gpt-4o","count":10,"prompt_text":"hi"},
+		"scenarios": [
+			{"name": "c1", "input": {"concurrency": 1}},
+			{"name": "c8", "input": {"concurrency": 8, "model": "gpt-4o-mini", "stream": true}}
+		]
+	}`))
+	if err != nil {
+		t.Fatalf("ParsePlan: %v", err)
+	}
+	if plan.Name != "nightly" || len(plan.Scenarios) != 2 {
+		t.Fatalf("plan = %+v", plan)
+	}
+	c1, c8 := plan.Scenarios[0], plan.Scenarios[1]
+	if c1.Name != "c1" || c1.Input.Concurrency != 1 || c1.Input.Model != "gpt-4o" || c1.Input.Count != 10 || c1.Input.Stream {
+		t.Errorf("c1 = %+v", c1)
+	}
+	if c8.Input.Concurrency != 8 || c8.Input.Model != "gpt-4o-mini" || !c8.Input.Stream || c8.Input.PromptText != "hi" {
+		t.Errorf("c8 = %+v", c8)
+	}
+}
+
+func TestParsePlan_ReportsScenarioProblems(t *testing.T) {
+	_, err := ParsePlan([]byte(`{
+		"defaults": {"protocol":"openai","model":"gpt-4o","prompt_text":"hi"},
+		"scenarios": [
+			{"name": "ok", "input": {"concurrency": 1, "count": 1}},
+			{"input": {"concurrency": 0, "count": 1}},
+			{"name": "bad", "input": "x"}
+		]
+	}`))
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	for _, want := range []string{"scenarios[1].name: 必填", "scenarios[1].input.concurrency: 必须大于 0", "scenarios[2].input: 类型错误"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q missing %q", err, want)
+		}
+	}
+	if strings.Contains(err.Error(), "scenarios[0]") {
+		t.Errorf("valid scenario reported: %v", err)
+	}
+}
+
+func TestParsePlan_Invalid(t *testing.T) {
+	cases := map[string]string{
+		"not json":     `{`,
+		"no scenarios": `{"name":"x","scenarios":[]}`,
+		"bad defaults": `{"defaults":[1],"scenarios":[{"name":"a"}]}`,
+	}
+	for name, data := range cases {
+		if _, err := ParsePlan([]byte(data)); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestLoadPlan(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := LoadPlan(filepath.Join(dir, "plan.yaml")); err == nil || !strings.Contains(err.Error(), "JSON") {
+		t.Errorf("yaml plan: got %v, want JSON-only error", err)
+	}
+
+	path := filepath.Join(dir, "plan.json")
+	data := `{"scenarios":[{"name":"a","input":{"protocol":"openai","model":"m","concurrency":1,"count":1,"prompt_text":"hi"}}]}`
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	plan, err := LoadPlan(path)
+	if err != nil || len(plan.Scenarios) != 1 {
+		t.Fatalf("LoadPlan = %+v, %v", plan, err)
+	}
+}
@@ -0,0 +1,406 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/yinxulai/ait/internal/server/client"
+	"github.com/yinxulai/ait/internal/server/network"
+	"github.com/yinxulai/ait/internal/server/prompt"
+	"github.com/yinxulai/ait/internal/server/report"
+	"github.com/yinxulai/ait/internal/server/sla"
+	"github.com/yinxulai/ait/internal/server/stats"
+	"github.com/yinxulai/ait/internal/server/types"
+)
+
+// Issue 任务配置文件的校验问题
+type Issue struct {
+	Field   string // 出问题的字段路径，如 input.concurrency；文件级问题为空
+	Problem string
+}
+
+// Error 以 "字段: 问题" 的形式返回，创建任务时把第一个问题作为错误返回。
+func (i Issue) Error() string {
+	if i.Field == "" {
+		return i.Problem
+	}
+	return i.Field + ": " + i.Problem
+}
+
+// supportedProtocols 任务配置允许的协议（规范化后）
+var supportedProtocols = []string{
+	types.ProtocolOpenAICompletions,
+	types.ProtocolOpenAIResponses,
+	types.ProtocolAnthropicMessages,
+	types.ProtocolTritonGRPC,
+}
+
+// ValidateTaskFile 离线校验任务配置文件（与 ~/.ait/tasks/*.json 相同的 JSON 格式：name + input）。
+// 检查字段类型、必填项与取值范围，返回所有发现的问题；文件无法读取时返回错误。
+func ValidateTaskFile(path string) ([]Issue, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return []Issue{{Problem: "仅支持 JSON 格式的任务配置"}}, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ValidateTask(data), nil
+}
+
+// ValidateTask 校验 JSON 形式的任务配置，规则与创建任务时的服务端校验保持一致。
+func ValidateTask(data []byte) []Issue {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return []Issue{{Problem: fmt.Sprintf("JSON 解析失败: %v", err)}}
+	}
+
+	var issues []Issue
+	var name string
+	if v, ok := raw["name"]; ok {
+		if err := json.Unmarshal(v, &name); err != nil {
+			issues = append(issues, Issue{Field: "name", Problem: "类型错误，应为 string"})
+		}
+	}
+	if strings.TrimSpace(name) == "" {
+		issues = append(issues, Issue{Field: "name", Problem: "必填"})
+	}
+
+	rawInput, ok := raw["input"]
+	if !ok {
+		return append(issues, Issue{Field: "input", Problem: "必填"})
+	}
+	input, typeIssues := decodeInput(rawInput)
+	issues = append(issues, typeIssues...)
+	if input == nil {
+		return issues
+	}
+	return append(issues, CheckInput(*input)...)
+}
+
+// decodeInput 逐字段解码 input，使每个类型错误都能单独报告；未知字段同样视为问题。
+func decodeInput(data json.RawMessage) (*types.Input, []Issue) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, []Issue{{Field: "input", Problem: "类型错误，应为 object"}}
+	}
+
+	input := &types.Input{}
+	value := reflect.ValueOf(input).Elem()
+	index := inputFieldIndex()
+
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var issues []Issue
+	for _, key := range keys {
+		i, ok := index[key]
+		if !ok {
+			issues = append(issues, Issue{Field: "input." + key, Problem: "未知字段"})
+			continue
+		}
+		field := value.Field(i)
+		target := reflect.New(field.Type())
+		if err := json.Unmarshal(fields[key], target.Interface()); err != nil {
+			issues = append(issues, Issue{Field: "input." + key, Problem: fmt.Sprintf("类型错误，应为 %s", jsonTypeName(field.Type()))})
+			continue
+		}
+		field.Set(target.Elem())
+	}
+	return input, issues
+}
+
+// inputFieldIndex 返回 types.Input 的 JSON 字段名到结构体字段下标的映射。
+func inputFieldIndex() map[string]int {
+	t := reflect.TypeOf(types.Input{})
+	index := make(map[string]int, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		index[name] = i
+	}
+	return index
+}
+
+func jsonTypeName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int64, reflect.Int32:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.String:
+		return "string"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	default:
+		return "object"
+	}
+}
+
+// CheckInput 检查 input 的必填项、取值范围与字段组合，返回所有问题。
+// 离线校验（ait validate、--plan）与创建任务时的服务端校验共用这一套规则与提示；
+// 需要读取规则库或完整加载文件的检查（如 tools_file、replay_file、integrity 套件）由服务端另行完成。
+func CheckInput(input types.Input) []Issue {
+	var issues []Issue
+	add := func(field, problem string) {
+		issues = append(issues, Issue{Field: "input." + field, Problem: problem})
+	}
+
+	protocol := types.NormalizeProtocol(input.Protocol)
+	if !containsString(supportedProtocols, protocol) {
+		add("protocol", fmt.Sprintf("不支持的协议 %q，可选 %s", input.Protocol, strings.Join(supportedProtocols, " / ")))
+	}
+	if strings.TrimSpace(input.Model) == "" {
+		add("model", "必填")
+	}
+	if input.ThinkingBudget < 0 {
+		add("thinking_budget", "不能为负数")
+	}
+	if input.MaxTokens < 0 {
+		add("max_tokens", "不能为负数")
+	}
+	if budget := client.ThinkingBudgetLimit(input); budget > 0 && input.MaxTokens > 0 && input.MaxTokens <= budget {
+		if input.ThinkingBudget > 0 {
+			add("thinking_budget", fmt.Sprintf("必须小于 max_tokens（%d）", input.MaxTokens))
+		} else {
+			add("max_tokens", fmt.Sprintf("开启 thinking 时必须大于默认思考预算 %d", budget))
+		}
+	}
+	if input.Timeout < 0 {
+		add("timeout", "不能为负数")
+	}
+	if input.RegressionThreshold < 0 {
+		add("regression_threshold", "不能为负数")
+	}
+	if input.TokenBudget < 0 {
+		add("token_budget", "不能为负数")
+	}
+	if input.AbortOnErrorRate < 0 || input.AbortOnErrorRate >= 100 {
+		add("abort_on_error_rate", "必须在 [0, 100) 内：错误率需超过阈值才熔断，100 永远不会触发")
+	}
+	if input.AbortMinSamples < 0 {
+		add("abort_min_samples", "不能为负数")
+	}
+	if input.PromptLength < 0 {
+		add("prompt_length", "不能为负数")
+	}
+	switch strings.ToLower(strings.TrimSpace(input.EndpointStrategy)) {
+	case "", types.EndpointStrategyRoundRobin, types.EndpointStrategyRandom:
+	default:
+		add("endpoint_strategy", fmt.Sprintf("不支持的策略 %q，可选 round-robin / random", input.EndpointStrategy))
+	}
+	switch httpVersion := strings.ToLower(strings.TrimSpace(input.HTTPVersion)); httpVersion {
+	case "", types.HTTPVersionAuto, types.HTTPVersion2:
+	case types.HTTPVersion1:
+		if protocol == types.ProtocolTritonGRPC {
+			add("http_version", "triton-grpc 协议只能使用 HTTP/2")
+		}
+	default:
+		add("http_version", fmt.Sprintf("不支持的版本 %q，可选 1.1 / 2 / auto", input.HTTPVersion))
+	}
+	switch strings.ToLower(strings.TrimSpace(input.AcceptEncoding)) {
+	case "", types.AcceptEncodingIdentity, types.AcceptEncodingGzip, types.AcceptEncodingBrotli:
+	default:
+		add("accept_encoding", fmt.Sprintf("不支持的编码 %q，可选 identity / gzip / br", input.AcceptEncoding))
+	}
+	if !report.IsWebhookFormat(strings.ToLower(strings.TrimSpace(input.WebhookFormat))) {
+		add("webhook_format", fmt.Sprintf("不支持的格式 %q，可选 generic / feishu / slack / wecom", input.WebhookFormat))
+	}
+	if webhook := strings.TrimSpace(input.WebhookURL); webhook != "" {
+		if u, err := url.Parse(webhook); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			add("webhook_url", "必须是 http(s) 地址")
+		}
+	}
+	if strings.TrimSpace(input.PromptLengthDist) != "" {
+		if input.PromptMode != "generated" {
+			add("prompt_length_dist", "仅 prompt_mode 为 generated 时可用")
+		} else if _, err := prompt.ParseLengthDist(input.PromptLengthDist); err != nil {
+			add("prompt_length_dist", err.Error())
+		}
+	}
+	if strings.TrimSpace(input.ToolsFile) != "" && input.RunMode() != "integrity" {
+		if protocol != types.ProtocolOpenAICompletions {
+			add("tools_file", "仅 openai-completions 协议可用")
+		} else if input.PromptMode == "raw" {
+			add("tools_file", "raw 模式请直接在请求体中写 tools")
+		}
+	}
+	if path := strings.TrimSpace(input.ResponseSchema); path != "" && input.RunMode() == "standard" {
+		if _, err := stats.LoadJSONSchema(path); err != nil {
+			add("response_schema", err.Error())
+		}
+	}
+	if input.CompressRequest && protocol == types.ProtocolTritonGRPC {
+		add("compress_request", "triton-grpc 协议不支持")
+	}
+	if input.VerifyRequestID && protocol == types.ProtocolTritonGRPC {
+		add("verify_request_id", "triton-grpc 协议不支持")
+	}
+	for i, expr := range input.SLA {
+		if strings.TrimSpace(expr) == "" {
+			continue
+		}
+		if _, err := sla.Parse(expr); err != nil {
+			add(fmt.Sprintf("sla[%d]", i), fmt.Sprintf("表达式不合法：%v", err))
+		}
+	}
+
+	switch mode := input.RunMode(); mode {
+	case "standard", "turbo":
+		if strings.TrimSpace(input.PromptText) == "" && strings.TrimSpace(input.PromptFile) == "" && input.PromptLength <= 0 && strings.TrimSpace(input.PromptLengthDist) == "" &&
+			(mode != "standard" || len(input.InputLengthSweep) == 0) {
+			add("prompt_text", "需要 prompt_text、prompt_file 或 prompt_length 之一")
+		}
+		if mode == "standard" {
+			if input.Concurrency <= 0 {
+				add("concurrency", "必须大于 0")
+			}
+			// 重放任务的 count 由重放文件的条数决定
+			if input.Count <= 0 && strings.TrimSpace(input.ReplayFile) == "" {
+				add("count", "必须大于 0")
+			}
+			if input.CompareStream && input.PromptMode == "raw" {
+				add("compare_stream", "raw 模式的请求体无法切换流式")
+			}
+			if input.CompareStream && input.CompareStreamSplit && input.Count < 2 {
+				add("count", "开启 compare_stream_split 时至少为 2")
+			}
+			switch {
+			case input.BurstSize < 0:
+				add("burst_size", "不能为负数")
+			case input.BurstInterval < 0:
+				add("burst_interval", "不能为负数")
+			case input.BurstSize == 0 && input.BurstInterval > 0:
+				add("burst_size", "设置 burst_interval 时必填")
+			case input.BurstSize > 0 && input.BurstInterval == 0:
+				add("burst_interval", "设置 burst_size 时必须大于 0")
+			case input.BurstSize > 0 && input.Count > 0 && input.BurstSize > input.Count:
+				add("burst_size", "不能超过 count")
+			case input.BurstSize > 0 && input.Adaptive:
+				add("burst_size", "不能与 adaptive 同时开启")
+			}
+			if fallback := strings.TrimSpace(input.FallbackModel); fallback != "" && fallback == input.Model {
+				add("fallback_model", "不能与 model 相同")
+			}
+			if input.ProbeInterval < 0 {
+				add("probe_interval", "不能为负数")
+			} else if input.ProbeInterval > 0 {
+				if _, err := network.ParseProbeTarget(input.ProbeEndpoint()); err != nil {
+					add("probe_interval", fmt.Sprintf("无法探测端点：%v", err))
+				}
+			}
+			if input.PhaseRatio < 0 || input.PhaseRatio >= 0.5 {
+				add("phase_ratio", "需在 0 到 0.5 之间（不含 0.5）")
+			}
+			if input.ConnectRetries < 0 {
+				add("connect_retries", "不能为负数")
+			}
+			if input.ConsistencyCheck {
+				switch {
+				case input.PromptMode == "raw":
+					add("consistency_check", "raw 模式的请求体无法写入 temperature")
+				case input.NormalizedProtocol() == types.ProtocolTritonGRPC:
+					add("consistency_check", "triton-grpc 协议不支持")
+				case input.ThinkingEnabled():
+					add("consistency_check", "不能与 thinking 同时开启")
+				case input.CompareStream:
+					add("consistency_check", "不能与 compare_stream 同时开启")
+				case len(input.InputLengthSweep) > 0:
+					add("consistency_check", "不能与 input_length_sweep 同时使用")
+				}
+			}
+			if input.CompareHTTPVersion {
+				switch httpVersion := strings.ToLower(strings.TrimSpace(input.HTTPVersion)); {
+				case protocol == types.ProtocolTritonGRPC:
+					add("compare_http_version", "triton-grpc 协议不支持")
+				case httpVersion != "" && httpVersion != types.HTTPVersionAuto:
+					add("compare_http_version", "不能与 http_version 同时设置")
+				case input.CompareStream:
+					add("compare_http_version", "不能与 compare_stream 同时开启")
+				case len(input.InputLengthSweep) > 0:
+					add("compare_http_version", "不能与 input_length_sweep 同时使用")
+				case strings.TrimSpace(input.ReplayFile) != "":
+					add("compare_http_version", "不能与 replay_file 同时使用")
+				}
+			}
+			if input.PromptFilePerRun {
+				switch {
+				case input.PromptMode != "file":
+					add("prompt_file_per_run", "仅 prompt_mode 为 file 时可用")
+				case input.CompareStream:
+					add("prompt_file_per_run", "不能与 compare_stream 同时开启")
+				case input.CompareHTTPVersion:
+					add("prompt_file_per_run", "不能与 compare_http_version 同时开启")
+				case len(input.InputLengthSweep) > 0:
+					add("prompt_file_per_run", "不能与 input_length_sweep 同时使用")
+				case strings.TrimSpace(input.ReplayFile) != "":
+					add("prompt_file_per_run", "不能与 replay_file 同时使用")
+				case input.ConsistencyCheck:
+					add("prompt_file_per_run", "不能与 consistency_check 同时开启")
+				}
+			}
+			if strings.TrimSpace(input.ReplayFile) != "" && len(input.InputLengthSweep) > 0 {
+				add("replay_file", "不能与 input_length_sweep 同时使用")
+			}
+			if len(input.InputLengthSweep) > 0 {
+				switch {
+				case input.PromptMode != "generated":
+					add("input_length_sweep", "仅 prompt_mode 为 generated 时可用")
+				case !input.Stream:
+					add("input_length_sweep", "需要开启 stream 才能测量 TTFT")
+				case strings.TrimSpace(input.PromptLengthDist) != "":
+					add("input_length_sweep", "不能与 prompt_length_dist 同时使用")
+				case input.CompareStream:
+					add("input_length_sweep", "不能与 compare_stream 同时开启")
+				}
+				seen := map[int]bool{}
+				for i, length := range input.InputLengthSweep {
+					if length <= 0 {
+						add(fmt.Sprintf("input_length_sweep[%d]", i), "必须大于 0")
+					} else if seen[length] {
+						add(fmt.Sprintf("input_length_sweep[%d]", i), fmt.Sprintf("长度 %d 重复", length))
+					}
+					seen[length] = true
+				}
+			}
+		}
+		if mode == "turbo" {
+			if tc := input.TurboConfig; tc.MaxConcurrency > 0 && tc.InitConcurrency > tc.MaxConcurrency {
+				add("turbo_config.max_concurrency", fmt.Sprintf("不能小于 init_concurrency（%d）", tc.InitConcurrency))
+			}
+		}
+	case "integrity":
+		if protocol == types.ProtocolTritonGRPC {
+			add("mode", "triton-grpc 协议不支持 integrity 模式")
+		}
+		if strings.TrimSpace(input.Integrity.Suite) == "" {
+			add("integrity.suite", "必填")
+		}
+	default:
+		add("mode", fmt.Sprintf("不支持的模式 %q，可选 standard / turbo / integrity", mode))
+	}
+	return issues
+}
+
+func containsString(values []string, target string) bool {
+	for _, v := range values {
+		if v == target {
+			return true
+		}
+	}
+	return false
+}
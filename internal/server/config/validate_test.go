@@ -0,0 +1,190 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func issueMap(issues []Issue) map[string]string {
+	m := make(map[string]string, len(issues))
+	for _, issue := range issues {
+		if prev, ok := m[issue.Field]; ok {
+			m[issue.Field] = prev + "; " + issue.Problem
+			continue
+		}
+		m[issue.Field] = issue.Problem
+	}
+	return m
+}
+
+func TestValidateTask_Valid(t *testing.T) {
+	issues := ValidateTask([]byte(`{"id":"t1","name":"bench","input":{"protocol":"openai","model":"gpt-4o","concurrency":4,"count":10,"prompt_text":"hi"}}`))
+	if len(issues) != 0 {
+		t.Fatalf("unexpected issues: %+v", issues)
+	}
+}
+
+func TestValidateTask_ReportsAllProblems(t *testing.T) {
+	issues := issueMap(ValidateTask([]byte(`{
+		"input": {
+			"protocol": "grpc-web",
+			"concurrency": "4",
+			"count": 0,
+			"stream": "yes",
+			"thinking_budget": -1,
+			"colour": "red"
+		}
+	}`)))
+
+	want := map[string]string{
+		"name":                  "必填",
+		"input.protocol":        "不支持的协议",
+		"input.model":           "必填",
+		"input.concurrency":     "类型错误，应为 integer",
+		"input.stream":          "类型错误，应为 boolean",
+		"input.count":           "必须大于 0",
+		"input.thinking_budget": "不能为负数",
+		"input.colour":          "未知字段",
+		"input.prompt_text":     "需要 prompt_text",
+	}
+	for field, problem := range want {
+		got, ok := issues[field]
+		if !ok || len(got) < len(problem) || got[:len(problem)] != problem {
+			t.Errorf("%s: got %q, want prefix %q", field, got, problem)
+		}
+	}
+	// concurrency 类型错误后按零值参与范围检查，同一字段会追加“必须大于 0”
+	if !strings.HasSuffix(issues["input.concurrency"], "必须大于 0") {
+		t.Errorf("input.concurrency: got %q, want range problem too", issues["input.concurrency"])
+	}
+	if len(issues) != len(want) {
+		t.Errorf("got %d issues, want %d: %+v", len(issues), len(want), issues)
+	}
+}
+
+func TestValidateTask_Burst(t *testing.T) {
+	cases := map[string]string{
+		`"burst_size":2`:                                    "input.burst_interval",
+		`"burst_interval":1000000000`:                       "input.burst_size",
+		`"burst_size":20,"burst_interval":1000000000`:       "input.burst_size",
+		`"burst_size":2,"burst_interval":1,"adaptive":true`: "input.burst_size",
+		`"burst_size":-1`:                                   "input.burst_size",
+	}
+	for fields, field := range cases {
+		issues := issueMap(ValidateTask([]byte(`{"name":"x","input":{"protocol":"openai","model":"m","concurrency":1,"count":10,"prompt_text":"hi",` + fields + `}}`)))
+		if issues[field] == "" || len(issues) != 1 {
+			t.Errorf("%s: want one issue on %s, got %+v", fields, field, issues)
+		}
+	}
+	ok := ValidateTask([]byte(`{"name":"x","input":{"protocol":"openai","model":"m","concurrency":1,"count":10,"prompt_text":"hi","burst_size":5,"burst_interval":1000000000}}`))
+	if len(ok) != 0 {
+		t.Errorf("valid burst config: unexpected issues %+v", ok)
+	}
+}
+
+func TestValidateTask_ThinkingBudgetBelowMaxTokens(t *testing.T) {
+	issues := issueMap(ValidateTask([]byte(`{"name":"x","input":{"protocol":"openai","model":"m","concurrency":1,"count":1,"prompt_text":"hi","thinking_budget":2048,"max_tokens":2048}}`)))
+	if issues["input.thinking_budget"] == "" || len(issues) != 1 {
+		t.Errorf("want one issue on input.thinking_budget, got %+v", issues)
+	}
+	issues = issueMap(ValidateTask([]byte(`{"name":"x","input":{"protocol":"anthropic","model":"m","concurrency":1,"count":1,"prompt_text":"hi","thinking":true,"max_tokens":1024}}`)))
+	if issues["input.max_tokens"] == "" || len(issues) != 1 {
+		t.Errorf("want one issue on input.max_tokens, got %+v", issues)
+	}
+	ok := ValidateTask([]byte(`{"name":"x","input":{"protocol":"openai","model":"m","concurrency":1,"count":1,"prompt_text":"hi","thinking_budget":2048,"max_tokens":4096}}`))
+	if len(ok) != 0 {
+		t.Errorf("valid thinking config: unexpected issues %+v", ok)
+	}
+}
+
+func TestValidateTask_AbortOnErrorRate(t *testing.T) {
+	for _, rate := range []string{"-1", "100", "150"} {
+		issues := issueMap(ValidateTask([]byte(`{"name":"x","input":{"protocol":"openai","model":"m","concurrency":1,"count":1,"prompt_text":"hi","abort_on_error_rate":` + rate + `}}`)))
+		if issues["input.abort_on_error_rate"] == "" || len(issues) != 1 {
+			t.Errorf("rate %s: want one issue on input.abort_on_error_rate, got %+v", rate, issues)
+		}
+	}
+	ok := ValidateTask([]byte(`{"name":"x","input":{"protocol":"openai","model":"m","concurrency":1,"count":1,"prompt_text":"hi","abort_on_error_rate":99.5}}`))
+	if len(ok) != 0 {
+		t.Errorf("valid abort rate: unexpected issues %+v", ok)
+	}
+}
+
+func TestValidateTask_FallbackModel(t *testing.T) {
+	issues := issueMap(ValidateTask([]byte(`{"name":"x","input":{"protocol":"openai","model":"m","concurrency":1,"count":1,"prompt_text":"hi","fallback_model":"m"}}`)))
+	if issues["input.fallback_model"] == "" || len(issues) != 1 {
+		t.Errorf("want one issue on input.fallback_model, got %+v", issues)
+	}
+	ok := ValidateTask([]byte(`{"name":"x","input":{"protocol":"openai","model":"m","concurrency":1,"count":1,"prompt_text":"hi","fallback_model":"m-mini"}}`))
+	if len(ok) != 0 {
+		t.Errorf("valid fallback config: unexpected issues %+v", ok)
+	}
+}
+
+func TestValidateTask_InputLengthSweep(t *testing.T) {
+	ok := ValidateTask([]byte(`{"name":"x","input":{"protocol":"openai","model":"m","concurrency":1,"count":1,"stream":true,"prompt_mode":"generated","input_length_sweep":[1024,4096]}}`))
+	if len(ok) != 0 {
+		t.Errorf("valid sweep config: unexpected issues %+v", ok)
+	}
+	issues := issueMap(ValidateTask([]byte(`{"name":"x","input":{"protocol":"openai","model":"m","concurrency":1,"count":1,"prompt_mode":"generated","input_length_sweep":[1024,1024]}}`)))
+	if issues["input.input_length_sweep"] == "" || issues["input.input_length_sweep[1]"] == "" || len(issues) != 2 {
+		t.Errorf("want issues on stream and the duplicate length, got %+v", issues)
+	}
+}
+
+func TestValidateTask_HTTPVersion(t *testing.T) {
+	ok := ValidateTask([]byte(`{"name":"x","input":{"protocol":"openai","model":"m","concurrency":1,"count":1,"prompt_text":"hi","http_version":"1.1"}}`))
+	if len(ok) != 0 {
+		t.Errorf("valid http_version: unexpected issues %+v", ok)
+	}
+	issues := issueMap(ValidateTask([]byte(`{"name":"x","input":{"protocol":"openai","model":"m","concurrency":1,"count":1,"prompt_text":"hi","http_version":"3","compare_http_version":true,"compare_stream":true}}`)))
+	if issues["input.http_version"] == "" || issues["input.compare_http_version"] == "" || len(issues) != 2 {
+		t.Errorf("want issues on http_version and compare_http_version, got %+v", issues)
+	}
+}
+
+// TestValidateTask_RulesSharedWithServer 创建任务时的服务端校验同样走 CheckInput，这些规则曾只在服务端存在。
+func TestValidateTask_RulesSharedWithServer(t *testing.T) {
+	cases := map[string]string{
+		`"count":10,"compare_stream":true,"prompt_mode":"raw"`:                                "input.compare_stream",
+		`"count":1,"compare_stream":true,"compare_stream_split":true`:                         "input.count",
+		`"count":10,"webhook_url":"ftp://hooks.example.com"`:                                  "input.webhook_url",
+		`"count":10,"probe_interval":1000000000,"endpoint_url":"ftp://api.example.com/v1"`:    "input.probe_interval",
+		`"count":10,"prompt_file_per_run":true`:                                               "input.prompt_file_per_run",
+		`"count":10,"mode":"turbo","turbo_config":{"init_concurrency":8,"max_concurrency":4}`: "input.turbo_config.max_concurrency",
+	}
+	for fields, field := range cases {
+		issues := issueMap(ValidateTask([]byte(`{"name":"x","input":{"protocol":"openai","model":"m","concurrency":1,"prompt_text":"hi",` + fields + `}}`)))
+		if issues[field] == "" || len(issues) != 1 {
+			t.Errorf("%s: want one issue on %s, got %+v", fields, field, issues)
+		}
+	}
+	ok := ValidateTask([]byte(`{"name":"x","input":{"protocol":"openai","model":"m","concurrency":1,"count":10,"prompt_text":"hi","webhook_url":"https://hooks.example.com/x","probe_interval":1000000000}}`))
+	if len(ok) != 0 {
+		t.Errorf("valid webhook/probe config: unexpected issues %+v", ok)
+	}
+}
+
+func TestValidateTask_Integrity(t *testing.T) {
+	issues := issueMap(ValidateTask([]byte(`{"name":"x","input":{"mode":"integrity","protocol":"triton-grpc","model":"m"}}`)))
+	if issues["input.mode"] == "" || issues["input.integrity.suite"] == "" {
+		t.Errorf("integrity problems not reported: %+v", issues)
+	}
+}
+
+func TestValidateTaskFile(t *testing.T) {
+	dir := t.TempDir()
+	if issues, err := ValidateTaskFile(filepath.Join(dir, "bench.yaml")); err != nil || len(issues) != 1 {
+		t.Errorf("yaml: issues=%+v err=%v", issues, err)
+	}
+	if _, err := ValidateTaskFile(filepath.Join(dir, "missing.json")); err == nil {
+		t.Error("expected error for missing file")
+	}
+	path := filepath.Join(dir, "broken.json")
+	_ = os.WriteFile(path, []byte("{"), 0o644)
+	if issues, err := ValidateTaskFile(path); err != nil || len(issues) != 1 || issues[0].Field != "" {
+		t.Errorf("broken json: issues=%+v err=%v", issues, err)
+	}
+}
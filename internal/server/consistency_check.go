@@ -0,0 +1,23 @@
+package server
+
+import (
+	"sync/atomic"
+
+	"github.com/yinxulai/ait/internal/server/types"
+)
+
+var processConsistencyCheck atomic.Bool
+
+// SetConsistencyCheck 设置本进程是否对每次标准运行做确定性验证，通常在启动时由 --consistency-check 设置；
+// 开启后等同于为任务打开 consistency_check，运行改为非流式。
+func SetConsistencyCheck(enabled bool) {
+	processConsistencyCheck.Store(enabled)
+}
+
+// consistencyCheckApplies 返回进程级 --consistency-check 是否作用于该运行：只对标准模式生效，
+// raw prompt 无法写入 temperature、输入长度扫描每轮 prompt 不同、gRPC 协议不支持 temperature，
+// 开启思考的请求部分服务不接受 temperature=0，均保持原样。
+func consistencyCheckApplies(input types.Input) bool {
+	return processConsistencyCheck.Load() && input.RunMode() == "standard" && input.PromptMode != "raw" &&
+		len(input.InputLengthSweep) == 0 && input.NormalizedProtocol() != types.ProtocolTritonGRPC && !input.ThinkingEnabled()
+}
@@ -0,0 +1,108 @@
+// Package doctor 按顺序诊断模型接口的连通性（DNS、TCP/TLS、鉴权、最小请求），
+// 每步给出结论与针对常见错误的修复建议。诊断直接使用 client 包发请求，不经过 Runner。
+package doctor
+
+import (
+	"context"
+	"net/url"
+	"strings"
+
+	"github.com/yinxulai/ait/internal/server/types"
+)
+
+// Status 单个诊断步骤的结论。
+type Status int
+
+const (
+	StatusPass Status = iota
+	StatusWarn        // 不影响压测，但值得注意（如接口不提供模型列表）
+	StatusFail
+	StatusSkip // 前置步骤失败，未执行
+)
+
+// Result 单个诊断步骤的结果。
+type Result struct {
+	Status Status
+	Detail string // 执行结果或失败原因
+	Hint   string // 修复建议，通过时通常为空
+	// Stop 为 true 时后续步骤不再执行（如 DNS 解析失败后无需再发请求）
+	Stop bool
+}
+
+// Step 一个诊断步骤。新增检查只需实现该接口并加入 DefaultSteps。
+type Step interface {
+	Name() string
+	Run(ctx context.Context, target *Target) Result
+}
+
+// Target 被诊断的接口，步骤间共享解析结果。
+type Target struct {
+	Input    types.Input
+	Endpoint *url.URL // 请求地址，由 NewTarget 从 Input 解析
+	Addrs    []string // DNS 解析得到的地址，由 DNS 步骤填写
+}
+
+// NewTarget 解析 input 的请求地址，地址不是 http / https URL 时返回的 Target.Endpoint 为 nil。
+func NewTarget(input types.Input) *Target {
+	target := &Target{Input: input}
+	if u, err := url.Parse(input.ResolvedEndpointURL()); err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Hostname() != "" {
+		target.Endpoint = u
+	}
+	return target
+}
+
+// Port 返回请求地址的端口，未写端口时按 scheme 取 443 / 80。
+func (t *Target) Port() string {
+	if p := t.Endpoint.Port(); p != "" {
+		return p
+	}
+	if strings.EqualFold(t.Endpoint.Scheme, "https") {
+		return "443"
+	}
+	return "80"
+}
+
+// DefaultSteps 默认的诊断步骤：DNS 解析、TCP/TLS 连通、模型列表鉴权、非流式与流式最小请求。
+func DefaultSteps() []Step {
+	return []Step{
+		DNSStep{},
+		ConnectStep{},
+		ModelsStep{},
+		RequestStep{},
+		RequestStep{Stream: true},
+	}
+}
+
+// Report 一个步骤的名称与结果。
+type Report struct {
+	Name string
+	Result
+}
+
+// Run 依次执行 steps，每完成一步调用 onReport（可为 nil）；某步要求停止后，其余步骤记为 StatusSkip。
+func Run(ctx context.Context, target *Target, steps []Step, onReport func(Report)) []Report {
+	reports := make([]Report, 0, len(steps))
+	stopped := false
+	for _, step := range steps {
+		report := Report{Name: step.Name(), Result: Result{Status: StatusSkip}}
+		if !stopped {
+			report.Result = step.Run(ctx, target)
+			stopped = report.Stop
+		}
+		reports = append(reports, report)
+		if onReport != nil {
+			onReport(report)
+		}
+	}
+	return reports
+}
+
+// Failed 返回 reports 中是否有失败的步骤。
+func Failed(reports []Report) bool {
+	for _, r := range reports {
+		if r.Status == StatusFail {
+			return true
+		}
+	}
+	return false
+}
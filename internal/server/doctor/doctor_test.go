@@ -0,0 +1,174 @@
+package doctor
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yinxulai/ait/internal/server/types"
+)
+
+func testTarget(baseURL, apiKey string) *Target {
+	return NewTarget(types.Input{
+		Protocol: types.ProtocolOpenAICompletions,
+		BaseUrl:  baseURL,
+		ApiKey:   apiKey,
+		Model:    "gpt-4o",
+		Timeout:  5 * time.Second,
+	})
+}
+
+type fakeStep struct {
+	name   string
+	result Result
+	ran    *int
+}
+
+func (s fakeStep) Name() string { return s.name }
+
+func (s fakeStep) Run(context.Context, *Target) Result {
+	*s.ran++
+	return s.result
+}
+
+func TestRun_StopsAfterFatalStep(t *testing.T) {
+	var ran int
+	steps := []Step{
+		fakeStep{"a", Result{Status: StatusPass}, &ran},
+		fakeStep{"b", Result{Status: StatusFail, Stop: true}, &ran},
+		fakeStep{"c", Result{Status: StatusPass}, &ran},
+	}
+	var seen []string
+	reports := Run(context.Background(), testTarget("http://127.0.0.1:1/v1", "sk"), steps, func(r Report) { seen = append(seen, r.Name) })
+	if ran != 2 || len(reports) != 3 || reports[2].Status != StatusSkip {
+		t.Fatalf("ran = %d, reports = %+v", ran, reports)
+	}
+	if strings.Join(seen, ",") != "a,b,c" {
+		t.Errorf("onReport names = %v", seen)
+	}
+	if !Failed(reports) {
+		t.Error("Failed() = false, want true")
+	}
+}
+
+func TestDNSStep(t *testing.T) {
+	r := DNSStep{}.Run(context.Background(), testTarget("api.example.com/v1", "sk"))
+	if r.Status != StatusFail || !r.Stop || !strings.Contains(r.Hint, "http://") {
+		t.Errorf("invalid url: %+v", r)
+	}
+
+	target := testTarget("http://127.0.0.1:8080/v1", "sk")
+	if r := (DNSStep{}).Run(context.Background(), target); r.Status != StatusPass || len(target.Addrs) != 1 {
+		t.Errorf("ip host: %+v, addrs %v", r, target.Addrs)
+	}
+
+	r = DNSStep{}.Run(context.Background(), testTarget("http://ait-doctor-test.invalid/v1", "sk"))
+	if r.Status != StatusFail || !r.Stop || !strings.Contains(r.Hint, "DNS") {
+		t.Errorf("unknown host: %+v", r)
+	}
+}
+
+func TestConnectStep(t *testing.T) {
+	plain := httptest.NewServer(http.NotFoundHandler())
+	defer plain.Close()
+	if r := (ConnectStep{}).Run(context.Background(), testTarget(plain.URL, "sk")); r.Status != StatusPass {
+		t.Errorf("plain: %+v", r)
+	}
+
+	// httptest 的 TLS 证书是自签的，校验失败时建议配置 CA
+	secure := httptest.NewTLSServer(http.NotFoundHandler())
+	defer secure.Close()
+	r := ConnectStep{}.Run(context.Background(), testTarget(secure.URL, "sk"))
+	if r.Status != StatusFail || !r.Stop || !strings.Contains(r.Hint, "SSL_CERT_FILE") {
+		t.Errorf("self-signed: %+v", r)
+	}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+	r = ConnectStep{}.Run(context.Background(), testTarget("http://"+addr, "sk"))
+	if r.Status != StatusFail || !strings.Contains(r.Hint, "端口") {
+		t.Errorf("refused: %+v", r)
+	}
+}
+
+func TestModelsStep(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/models" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer sk-good" {
+			http.Error(w, `{"error":{"message":"Incorrect API key provided"}}`, http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprint(w, `{"data":[{"id":"gpt-4o"},{"id":"gpt-4o-mini"}]}`)
+	}))
+	defer srv.Close()
+
+	if r := (ModelsStep{}).Run(context.Background(), testTarget(srv.URL+"/v1", "sk-good")); r.Status != StatusPass {
+		t.Errorf("ok: %+v", r)
+	}
+	r := ModelsStep{}.Run(context.Background(), testTarget(srv.URL+"/v1", "Bearer sk-good"))
+	if r.Status != StatusFail || !strings.Contains(r.Hint, "Bearer") {
+		t.Errorf("bearer prefix: %+v", r)
+	}
+
+	target := testTarget(srv.URL+"/v1", "sk-good")
+	target.Input.Model = "gpt-5"
+	if r := (ModelsStep{}).Run(context.Background(), target); r.Status != StatusWarn || !strings.Contains(r.Detail, "gpt-5") {
+		t.Errorf("missing model: %+v", r)
+	}
+
+	// baseUrl 重复了 /v1：模型列表 404 只告警，提示以最小请求为准
+	if r := (ModelsStep{}).Run(context.Background(), testTarget(srv.URL+"/v1/v1", "sk-good")); r.Status != StatusWarn || !strings.Contains(r.Hint, "/v1") {
+		t.Errorf("not found: %+v", r)
+	}
+}
+
+func TestRequestStep(t *testing.T) {
+	var maxTokens []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" {
+			http.NotFound(w, r)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		if strings.Contains(string(body), `"max_tokens":1`) {
+			maxTokens = append(maxTokens, "1")
+		}
+		if strings.Contains(string(body), `"stream":true`) {
+			w.Header().Set("Content-Type", "text/event-stream")
+			fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{\"content\":\"Hi\"}}]}\n\n")
+			fmt.Fprint(w, "data: {\"choices\":[],\"usage\":{\"prompt_tokens\":1,\"completion_tokens\":1}}\n\n")
+			fmt.Fprint(w, "data: [DONE]\n\n")
+			return
+		}
+		fmt.Fprint(w, `{"choices":[{"message":{"content":"Hi"},"finish_reason":"length"}],"usage":{"prompt_tokens":1,"completion_tokens":1}}`)
+	}))
+	defer srv.Close()
+
+	for _, step := range []RequestStep{{}, {Stream: true}} {
+		if r := step.Run(context.Background(), testTarget(srv.URL+"/v1", "sk")); r.Status != StatusPass {
+			t.Errorf("%s: %+v", step.Name(), r)
+		}
+	}
+	if len(maxTokens) != 2 {
+		t.Errorf("requests with max_tokens=1 = %d, want 2", len(maxTokens))
+	}
+
+	// baseUrl 多写了一层 /api：404 时提示检查 /v1 路径
+	r := RequestStep{}.Run(context.Background(), testTarget(srv.URL+"/api", "sk"))
+	if r.Status != StatusFail || !strings.Contains(r.Hint, "/v1") || !strings.Contains(r.Hint, srv.URL+"/api/v1/chat/completions") {
+		t.Errorf("not found: %+v", r)
+	}
+}
@@ -0,0 +1,249 @@
+package doctor
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/yinxulai/ait/internal/server/client"
+	"github.com/yinxulai/ait/internal/server/types"
+)
+
+// netStepTimeout DNS 解析、TCP/TLS 连通检查各自的超时
+const netStepTimeout = 10 * time.Second
+
+// DNSStep 解析请求地址的主机名。
+type DNSStep struct{}
+
+func (DNSStep) Name() string { return "DNS 解析" }
+
+func (DNSStep) Run(ctx context.Context, target *Target) Result {
+	if target.Endpoint == nil {
+		return Result{
+			Status: StatusFail,
+			Detail: fmt.Sprintf("请求地址无效: %s", target.Input.ResolvedEndpointURL()),
+			Hint:   "baseUrl 需以 http:// 或 https:// 开头，如 https://api.openai.com/v1",
+			Stop:   true,
+		}
+	}
+	if proxy := proxyFor(target); proxy != "" {
+		return Result{Status: StatusSkip, Detail: fmt.Sprintf("经代理 %s 访问，由代理解析", proxy)}
+	}
+	host := target.Endpoint.Hostname()
+	if ip := net.ParseIP(host); ip != nil {
+		target.Addrs = []string{host}
+		return Result{Status: StatusPass, Detail: host}
+	}
+	ctx, cancel := context.WithTimeout(ctx, netStepTimeout)
+	defer cancel()
+	addrs, err := net.DefaultResolver.LookupHost(ctx, host)
+	if err != nil {
+		return Result{Status: StatusFail, Detail: err.Error(), Hint: netHint(err), Stop: true}
+	}
+	target.Addrs = addrs
+	return Result{Status: StatusPass, Detail: fmt.Sprintf("%s → %s", host, strings.Join(addrs, ", "))}
+}
+
+// ConnectStep 建立 TCP 连接，https 地址再做一次校验证书的 TLS 握手。
+type ConnectStep struct{}
+
+func (ConnectStep) Name() string { return "TCP / TLS 连通" }
+
+func (ConnectStep) Run(ctx context.Context, target *Target) Result {
+	if proxy := proxyFor(target); proxy != "" {
+		return Result{Status: StatusSkip, Detail: fmt.Sprintf("经代理 %s 访问，跳过直连检查", proxy)}
+	}
+	host := target.Endpoint.Hostname()
+	if len(target.Addrs) > 0 {
+		host = target.Addrs[0]
+	}
+	ctx, cancel := context.WithTimeout(ctx, netStepTimeout)
+	defer cancel()
+
+	start := time.Now()
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(host, target.Port()))
+	if err != nil {
+		return Result{Status: StatusFail, Detail: err.Error(), Hint: netHint(err), Stop: true}
+	}
+	defer conn.Close()
+	detail := fmt.Sprintf("TCP %s", time.Since(start).Round(time.Millisecond))
+	if !strings.EqualFold(target.Endpoint.Scheme, "https") {
+		return Result{Status: StatusPass, Detail: detail}
+	}
+
+	tlsStart := time.Now()
+	tlsConn := tls.Client(conn, &tls.Config{ServerName: target.Endpoint.Hostname()})
+	if err := tlsConn.HandshakeContext(ctx); err != nil {
+		return Result{Status: StatusFail, Detail: err.Error(), Hint: netHint(err), Stop: true}
+	}
+	state := tlsConn.ConnectionState()
+	return Result{Status: StatusPass, Detail: fmt.Sprintf("%s，TLS %s（%s）",
+		detail, time.Since(tlsStart).Round(time.Millisecond), tls.VersionName(state.Version))}
+}
+
+// ModelsStep 用 API Key 请求 GET /v1/models，检查鉴权与模型是否存在。
+type ModelsStep struct{}
+
+func (ModelsStep) Name() string { return "鉴权（GET /models）" }
+
+func (ModelsStep) Run(ctx context.Context, target *Target) Result {
+	list, err := client.ListModels(ctx, target.Input)
+	if err != nil {
+		return Result{Status: StatusFail, Detail: err.Error(), Hint: netHint(err)}
+	}
+	switch {
+	case list.StatusCode == http.StatusOK:
+		model := target.Input.Model
+		if len(list.Models) > 0 && model != "" && !slices.Contains(list.Models, model) {
+			return Result{
+				Status: StatusWarn,
+				Detail: fmt.Sprintf("返回 %d 个模型，其中没有 %s", len(list.Models), model),
+				Hint:   "检查 --model 拼写；部分服务的模型列表不完整，最小请求成功即可忽略",
+			}
+		}
+		return Result{Status: StatusPass, Detail: fmt.Sprintf("返回 %d 个模型", len(list.Models))}
+	case list.StatusCode == http.StatusUnauthorized || list.StatusCode == http.StatusForbidden:
+		return Result{Status: StatusFail, Detail: statusDetail(list.StatusCode, list.Body), Hint: httpHint(target, list.StatusCode, list.Body)}
+	case list.StatusCode == http.StatusNotFound || list.StatusCode == http.StatusMethodNotAllowed:
+		// 不少兼容服务不提供模型列表，是否是地址错误以最小请求为准
+		return Result{
+			Status: StatusWarn,
+			Detail: fmt.Sprintf("%s 返回 %d，接口可能不提供模型列表", client.ModelsURL(target.Input), list.StatusCode),
+			Hint:   "若下面的最小请求也返回 404，" + pathHint(target),
+		}
+	default:
+		return Result{Status: StatusWarn, Detail: statusDetail(list.StatusCode, list.Body), Hint: httpHint(target, list.StatusCode, list.Body)}
+	}
+}
+
+// RequestStep 发一条 max_tokens=1 的最小请求，Stream 为 true 时以流式发送。
+type RequestStep struct {
+	Stream bool
+}
+
+func (s RequestStep) Name() string {
+	if s.Stream {
+		return "流式最小请求"
+	}
+	return "最小请求（max_tokens=1）"
+}
+
+func (s RequestStep) Run(ctx context.Context, target *Target) Result {
+	input := target.Input
+	input.MaxTokens = 1
+	input.Stream = s.Stream
+	c, err := client.NewClient(input, nil)
+	if err != nil {
+		return Result{Status: StatusFail, Detail: err.Error()}
+	}
+	defer client.CloseIdleConnections(c)
+
+	metrics, err := c.Request(ctx, "", "hi", s.Stream)
+	if metrics != nil && metrics.StatusCode != 0 {
+		return Result{Status: StatusFail, Detail: statusDetail(metrics.StatusCode, metrics.ErrorMessage), Hint: httpHint(target, metrics.StatusCode, metrics.ErrorMessage)}
+	}
+	if err != nil || (metrics != nil && metrics.ErrorMessage != "") {
+		msg := ""
+		if metrics != nil {
+			msg = metrics.ErrorMessage
+		}
+		if err != nil {
+			msg = err.Error()
+		}
+		hint := netHint(err)
+		if err == nil {
+			hint = client.UserErrorHint(msg)
+		}
+		return Result{Status: StatusFail, Detail: msg, Hint: hint}
+	}
+
+	detail := fmt.Sprintf("总耗时 %s，输出 %d token", metrics.TotalTime.Round(time.Millisecond), metrics.CompletionTokens)
+	if s.Stream {
+		detail = fmt.Sprintf("TTFT %s，%s", metrics.TimeToFirstToken.Round(time.Millisecond), detail)
+		if metrics.TimeToFirstToken == 0 {
+			return Result{Status: StatusWarn, Detail: detail, Hint: "未收到流式输出：检查服务是否支持 stream，或代理是否缓冲了 SSE 响应"}
+		}
+	}
+	return Result{Status: StatusPass, Detail: detail}
+}
+
+// proxyFor 返回访问请求地址使用的代理（任务的 proxy_url 优先，其次 HTTPS_PROXY 等环境变量），不使用代理时为空。
+func proxyFor(target *Target) string {
+	if p := strings.TrimSpace(target.Input.ProxyURL); p != "" {
+		return p
+	}
+	u, err := http.ProxyFromEnvironment(&http.Request{URL: target.Endpoint})
+	if err != nil || u == nil {
+		return ""
+	}
+	return u.Redacted()
+}
+
+func statusDetail(status int, body string) string {
+	body = strings.TrimSpace(body)
+	if r := []rune(body); len(r) > 200 {
+		body = string(r[:200]) + "…"
+	}
+	if body == "" {
+		return fmt.Sprintf("HTTP %d", status)
+	}
+	return fmt.Sprintf("HTTP %d: %s", status, body)
+}
+
+// httpHint 按状态码与响应内容给出修复建议。
+func httpHint(target *Target, status int, body string) string {
+	key := target.Input.ApiKey
+	switch {
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		switch {
+		case strings.TrimSpace(key) == "":
+			return fmt.Sprintf("未提供 API Key：使用 --apiKey 或环境变量 %s", client.APIKeyEnv(target.Input.NormalizedProtocol()))
+		case strings.HasPrefix(strings.ToLower(key), "bearer "):
+			return "API Key 不要带 \"Bearer \" 前缀，ait 会自动添加"
+		case target.Input.NormalizedProtocol() == types.ProtocolAnthropicMessages:
+			return "检查 API Key 是否正确、未过期；Anthropic 协议通过 x-api-key 头鉴权，OpenAI 兼容服务请使用 --protocol openai"
+		}
+		return "检查 API Key 是否正确、未过期、有该模型的权限；Key 不要带 \"Bearer \" 前缀"
+	case status == http.StatusNotFound:
+		if client.ClassifyError(body) == client.ErrModelNotFound && strings.Contains(strings.ToLower(body), "model") {
+			return "模型不存在：检查 --model 拼写，或用 GET /models 查看可用模型"
+		}
+		return pathHint(target)
+	case status == http.StatusTooManyRequests || status == http.StatusPaymentRequired:
+		return client.UserErrorHint(fmt.Sprintf("%d %s", status, body))
+	case status >= 500:
+		return "服务端错误：稍后重试，或检查服务状态与网关日志"
+	}
+	return client.UserErrorHint(body)
+}
+
+// pathHint 请求地址路径错误（404）时的建议。
+func pathHint(target *Target) string {
+	return fmt.Sprintf("检查 baseUrl 路径：当前请求地址为 %s；OpenAI 兼容接口的 baseUrl 通常以 /v1 结尾（ait 自动补全 /chat/completions），不要重复或遗漏 /v1",
+		target.Input.ResolvedEndpointURL())
+}
+
+// netHint 按网络错误类别给出修复建议。
+func netHint(err error) string {
+	switch client.ClassifyNetError(err) {
+	case client.NetErrDNS:
+		return "检查 baseUrl 主机名拼写与本机 DNS 配置；内网域名需在对应网络环境下访问"
+	case client.NetErrConnectionRefused:
+		return "端口未监听：检查 baseUrl 的端口，以及服务是 http 还是 https"
+	case client.NetErrConnectTimeout:
+		return "连接超时：检查网络与防火墙；需要代理时设置 HTTPS_PROXY 或 --proxy"
+	case client.NetErrTLS:
+		return "TLS 握手或证书校验失败：自签或企业内网证书需把 CA 加入系统信任库，或用环境变量 SSL_CERT_FILE 指定 CA 文件；http 服务不要写成 https"
+	case client.NetErrConnectionReset:
+		return "连接被重置：检查代理 / 网关配置，或服务是否只接受 HTTP/1.1 / HTTP/2"
+	case client.NetErrTimeout:
+		return "请求超时：检查网络与代理，或用 --timeout 增大超时"
+	}
+	return "检查网络连接与代理配置"
+}
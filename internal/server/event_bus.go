@@ -37,8 +37,10 @@ func (b *eventBus) subscribeRunEvents(runID RunID) (<-chan Event, CancelFunc) {
 	return sub.ch, cancel
 }
 
-// publishRunEvent 向该 RunID 的所有订阅者非阻塞地投递事件。
+// publishRunEvent 向该 RunID 的所有订阅者非阻塞地投递事件，并在开启机器可读进度流时写出进度行。
 func (b *eventBus) publishRunEvent(event Event) {
+	writeProgress(event)
+
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
@@ -0,0 +1,57 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+
+	"github.com/yinxulai/ait/internal/server/types"
+)
+
+var (
+	processHTTPVersion        atomic.Value // string
+	processCompareHTTPVersion atomic.Bool
+)
+
+// ParseHTTPVersion 规范化 HTTP 版本取值：1.1、2 或 auto（留空视为 auto）。
+func ParseHTTPVersion(s string) (string, error) {
+	switch v := strings.ToLower(strings.TrimSpace(s)); v {
+	case "", types.HTTPVersionAuto:
+		return types.HTTPVersionAuto, nil
+	case types.HTTPVersion1, types.HTTPVersion2:
+		return v, nil
+	default:
+		return "", fmt.Errorf("invalid http version %q: want 1.1, 2 or auto", s)
+	}
+}
+
+// SetHTTPVersion 设置本进程被测请求默认使用的 HTTP 版本，通常在启动时由 --http-version 设置；
+// 任务自身设置了 http_version 时以任务为准。
+func SetHTTPVersion(version string) error {
+	v, err := ParseHTTPVersion(version)
+	if err != nil {
+		return err
+	}
+	processHTTPVersion.Store(v)
+	return nil
+}
+
+// SetCompareHTTPVersion 设置本进程是否对每次标准运行做 HTTP/1.1 与 HTTP/2 的 A/B 对比，
+// 通常在启动时由 --compare-http-version 设置；开启后等同于为任务打开 compare_http_version。
+func SetCompareHTTPVersion(enabled bool) {
+	processCompareHTTPVersion.Store(enabled)
+}
+
+// applyProcessHTTPVersion 把进程级 HTTP 版本设置合并到运行输入：任务未指定版本时使用 --http-version；
+// --compare-http-version 只作用于可以对比的标准运行（未固定版本、非 triton-grpc、没有其他按轮次的对比或扫描）。
+func applyProcessHTTPVersion(input *types.Input) {
+	if v, _ := processHTTPVersion.Load().(string); v != "" && v != types.HTTPVersionAuto && !input.CompareHTTPVersion &&
+		(input.HTTPVersion == "" || input.HTTPVersion == types.HTTPVersionAuto) && input.NormalizedProtocol() != types.ProtocolTritonGRPC {
+		input.HTTPVersion = v
+	}
+	if processCompareHTTPVersion.Load() && input.RunMode() == "standard" && input.NormalizedProtocol() != types.ProtocolTritonGRPC &&
+		(input.HTTPVersion == "" || input.HTTPVersion == types.HTTPVersionAuto) &&
+		!input.CompareStream && len(input.InputLengthSweep) == 0 && input.ReplayFile == "" {
+		input.CompareHTTPVersion = true
+	}
+}
@@ -0,0 +1,101 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/yinxulai/ait/internal/server/clock"
+	"github.com/yinxulai/ait/internal/server/types"
+)
+
+// IORecord 质量评估落盘文件（JSONL）中的一行：一个成功请求的 prompt 与模型输出正文。
+type IORecord struct {
+	Time             time.Time `json:"time"`
+	TaskID           string    `json:"task_id"`
+	RunID            RunID     `json:"run_id"`
+	Index            int       `json:"index"`
+	Model            string    `json:"model"`
+	System           string    `json:"system,omitempty"`
+	Prompt           string    `json:"prompt"` // raw 模式下为原始请求体
+	Response         string    `json:"response"`
+	PromptTokens     int       `json:"prompt_tokens"`
+	CompletionTokens int       `json:"completion_tokens"`
+	FinishReason     string    `json:"finish_reason,omitempty"`
+}
+
+// ioLog 把采样到的 IORecord 追加到 dir 下每次运行一个的 <run_id>.jsonl，写入串行化避免行内容交错。
+type ioLog struct {
+	mu         sync.Mutex
+	dir        string
+	sampleRate float64
+	rng        clock.Rand // 采样使用的随机源
+}
+
+var processIOLog atomic.Pointer[ioLog]
+
+// SetIOLog 设置本进程的 prompt / 响应落盘目录，通常在启动时由 --save-io-dir 设置；dir 为空时关闭。
+// sampleRate 为 (0, 1] 内的采样比例，大量请求时只保存其中一部分以控制磁盘占用。
+func SetIOLog(dir string, sampleRate float64) error {
+	if dir == "" {
+		processIOLog.Store(nil)
+		return nil
+	}
+	if sampleRate <= 0 || sampleRate > 1 {
+		return fmt.Errorf("采样比例必须在 (0, 1] 内，当前为 %g", sampleRate)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	processIOLog.Store(&ioLog{dir: dir, sampleRate: sampleRate, rng: clock.DefaultRand()})
+	return nil
+}
+
+// writeIORecord 在开启落盘且命中采样时追加一行；写入失败直接忽略，不影响运行。
+func writeIORecord(record IORecord) {
+	l := processIOLog.Load()
+	if l == nil || (l.sampleRate < 1 && l.rng.Float64() >= l.sampleRate) {
+		return
+	}
+	data, err := json.Marshal(record)
+	if err != nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	f, err := os.OpenFile(filepath.Join(l.dir, string(record.RunID)+".jsonl"), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	_, _ = f.Write(append(data, '\n'))
+}
+
+// saveIO 在开启落盘时记录一个成功请求实际发送的 prompt 与响应正文；
+// 没有 prompt 来源的请求（如接口完整性测试的用例）不记录。
+func (a *RunAggregator) saveIO(result RequestResult, rm *types.RequestMetrics) {
+	input := result.Job.Input
+	if processIOLog.Load() == nil || !rm.Success || result.Metrics == nil || input.PromptSource == nil {
+		return
+	}
+	record := IORecord{
+		Time:             time.Now(),
+		TaskID:           a.taskDef.ID,
+		RunID:            a.runID,
+		Index:            result.Job.Index,
+		Model:            input.Model,
+		Prompt:           rm.Prompt,
+		Response:         result.Metrics.ResponseText,
+		PromptTokens:     rm.PromptTokens,
+		CompletionTokens: rm.CompletionTokens,
+		FinishReason:     rm.FinishReason,
+	}
+	if input.PromptMode != "raw" {
+		record.System = input.PromptSource.GetSystemContent()
+	}
+	writeIORecord(record)
+}
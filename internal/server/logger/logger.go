@@ -6,6 +6,8 @@ import (
 	"log"
 	"os"
 	"time"
+
+	"github.com/yinxulai/ait/internal/server/redact"
 )
 
 // generateLogFilePath 生成日志文件路径，格式：ait-25-09-22-17-00-27.log
@@ -110,7 +112,7 @@ func (l *Logger) Info(model, message string) {
 // Error 记录错误日志
 func (l *Logger) Error(model, message string, err error) {
 	details := map[string]interface{}{
-		"error": err.Error(),
+		"error": redact.String(err.Error()),
 	}
 	l.writeLog(LevelError, model, message, details)
 }
@@ -138,18 +140,33 @@ type ResponseData struct {
 	Error         string            `json:"error,omitempty"`
 	StreamChunks  []string          `json:"stream_chunks,omitempty"`  // 流式响应的数据块
 	StreamEncoded []string          `json:"stream_encoded,omitempty"` // 编码后的流式数据块
+
+	// 数据块超过 --log-max-chunks / --log-max-bytes 上限时为 true，StreamDroppedChunks 为未记录的块数
+	StreamTruncated     bool `json:"stream_truncated,omitempty"`
+	StreamDroppedChunks int  `json:"stream_dropped_chunks,omitempty"`
 }
 
-// LogRequest 记录请求日志
+// LogRequest 记录请求日志；开启 --redact 时请求体先脱敏
 func (l *Logger) LogRequest(model string, req RequestData) {
+	req.Body = redact.JSON(req.Body)
 	// 对请求体进行编码处理
 	req.BodyEncoded = encodeSpecialChars(req.Body)
 
 	l.writeLog(LevelRequest, model, "HTTP Request", req)
 }
 
-// LogResponse 记录响应日志
+// LogResponse 记录响应日志；开启 --redact 时响应体、错误信息与各流式数据块先脱敏
 func (l *Logger) LogResponse(model string, resp ResponseData) {
+	if redact.Enabled() {
+		resp.Body = redact.JSON(resp.Body)
+		resp.Error = redact.String(resp.Error)
+		chunks := make([]string, len(resp.StreamChunks))
+		for i, chunk := range resp.StreamChunks {
+			chunks[i] = redact.JSON(chunk)
+		}
+		resp.StreamChunks = chunks
+	}
+
 	// 对响应体进行编码处理
 	if resp.Body != "" {
 		resp.BodyEncoded = encodeSpecialChars(resp.Body)
@@ -180,7 +197,7 @@ func encodeSpecialChars(input string) string {
 // LogTestStart 记录测试开始
 func (l *Logger) LogTestStart(model, prompt string, config map[string]interface{}) {
 	details := map[string]interface{}{
-		"prompt": prompt,
+		"prompt": redact.String(prompt),
 		"config": config,
 	}
 	l.writeLog(LevelInfo, model, "Test Started", details)
@@ -0,0 +1,43 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"strings"
+	"testing"
+
+	"github.com/yinxulai/ait/internal/server/redact"
+)
+
+func TestLogger_Redact(t *testing.T) {
+	var buf bytes.Buffer
+	l := &Logger{enabled: true, logger: log.New(&buf, "", 0)}
+	write := func() string {
+		buf.Reset()
+		l.LogTestStart("m", "我的手机 13800138000", nil)
+		l.LogRequest("m", RequestData{Body: `{"messages":[{"role":"user","content":"邮箱\nli@lei.cn"}]}`})
+		l.LogResponse("m", ResponseData{StatusCode: 400, Body: `{"error":"bad 13800138000"}`, Error: "echo li@lei.cn"})
+		l.LogResponse("m", ResponseData{StatusCode: 200, StreamChunks: []string{`{"delta":"13800138000"}`}})
+		l.Error("m", "failed", errors.New("prompt 13800138000 rejected"))
+		return buf.String()
+	}
+
+	if out := write(); !strings.Contains(out, "13800138000") || !strings.Contains(out, "li@lei.cn") {
+		t.Fatalf("redaction is off by default, log = %s", out)
+	}
+
+	r, err := redact.New(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	redact.Enable(r)
+	t.Cleanup(func() { redact.Enable(nil) })
+	out := write()
+	if strings.Contains(out, "13800138000") || strings.Contains(out, "li@lei.cn") {
+		t.Errorf("log still contains sensitive data:\n%s", out)
+	}
+	if n := strings.Count(out, redact.Placeholder); n < 7 {
+		t.Errorf("placeholder count = %d, want at least 7:\n%s", n, out)
+	}
+}
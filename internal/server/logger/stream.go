@@ -0,0 +1,116 @@
+package logger
+
+import (
+	"strings"
+	"sync/atomic"
+)
+
+// 单个流式响应写入日志的默认上限：长输出模型一个请求的数据块可达数 MB，高并发下全部留在内存会 OOM
+const (
+	DefaultMaxStreamChunks = 2000
+	DefaultMaxStreamBytes  = 1 << 20 // 1 MiB
+)
+
+// streamLimits 单个流式响应最多记录的数据块数与字节数，0 表示不限制
+type streamLimits struct {
+	maxChunks int
+	maxBytes  int
+}
+
+var processStreamLimits atomic.Pointer[streamLimits]
+
+// SetStreamLimits 设置本进程 --log 模式下单个流式响应最多记录的数据块数与字节数，0 表示不限制；
+// 通常在启动时由 --log-max-chunks / --log-max-bytes 设置，未设置时使用默认上限。
+// 字节上限同时约束请求结果中保存的流式原始响应体（见 BodyRecorder）。
+func SetStreamLimits(maxChunks, maxBytes int) {
+	processStreamLimits.Store(&streamLimits{maxChunks: maxChunks, maxBytes: maxBytes})
+}
+
+func currentStreamLimits() streamLimits {
+	if limits := processStreamLimits.Load(); limits != nil {
+		return *limits
+	}
+	return streamLimits{maxChunks: DefaultMaxStreamChunks, maxBytes: DefaultMaxStreamBytes}
+}
+
+// StreamRecorder 收集单个流式响应要写入日志的数据块，达到上限后只计数不再保存。
+// nil 的 StreamRecorder 可以安全调用，不记录任何内容。
+type StreamRecorder struct {
+	limits  streamLimits
+	chunks  []string
+	bytes   int
+	dropped int
+}
+
+// NewStreamRecorder 日志开启时返回按当前上限记录的 StreamRecorder，未开启时返回 nil。
+func (l *Logger) NewStreamRecorder() *StreamRecorder {
+	if l == nil || !l.IsEnabled() {
+		return nil
+	}
+	return &StreamRecorder{limits: currentStreamLimits()}
+}
+
+// Add 记录一个数据块；一旦超限，之后的数据块都不再保存，保证日志中的数据块是连续的前缀。
+func (r *StreamRecorder) Add(chunk string) {
+	if r == nil {
+		return
+	}
+	full := r.dropped > 0 ||
+		(r.limits.maxChunks > 0 && len(r.chunks) >= r.limits.maxChunks) ||
+		(r.limits.maxBytes > 0 && r.bytes+len(chunk) > r.limits.maxBytes)
+	if full {
+		r.dropped++
+		return
+	}
+	r.chunks = append(r.chunks, chunk)
+	r.bytes += len(chunk)
+}
+
+// Response 返回写日志用的响应数据；有数据块因超限未记录时标记为已截断并给出丢弃数。
+func (r *StreamRecorder) Response(statusCode int) ResponseData {
+	if r == nil {
+		return ResponseData{StatusCode: statusCode}
+	}
+	return ResponseData{
+		StatusCode:          statusCode,
+		StreamChunks:        r.chunks,
+		StreamTruncated:     r.dropped > 0,
+		StreamDroppedChunks: r.dropped,
+	}
+}
+
+// BodyRecorder 收集流式响应的原始文本（如拼接的 SSE 行），最多保留 --log-max-bytes 字节；
+// 作为 io.TeeReader 的写端使用，超出上限的部分直接丢弃，不影响响应解析。
+type BodyRecorder struct {
+	maxBytes  int
+	buf       strings.Builder
+	truncated bool
+}
+
+// NewBodyRecorder 返回按当前字节上限记录的 BodyRecorder；与日志是否开启无关，原始响应体总会随请求结果保存。
+func NewBodyRecorder() *BodyRecorder {
+	return &BodyRecorder{maxBytes: currentStreamLimits().maxBytes}
+}
+
+// Write 记录 p 中未超出上限的部分，总是返回 len(p)。
+func (r *BodyRecorder) Write(p []byte) (int, error) {
+	if r.maxBytes > 0 {
+		if room := r.maxBytes - r.buf.Len(); len(p) > room {
+			r.buf.Write(p[:max(room, 0)])
+			r.truncated = true
+			return len(p), nil
+		}
+	}
+	r.buf.Write(p)
+	return len(p), nil
+}
+
+// String 返回已记录的原始文本
+func (r *BodyRecorder) String() string {
+	return r.buf.String()
+}
+
+// Truncated 返回是否有内容因超限未记录
+func (r *BodyRecorder) Truncated() bool {
+	return r.truncated
+}
@@ -0,0 +1,112 @@
+package logger
+
+import (
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestStreamRecorder_Limits(t *testing.T) {
+	tests := []struct {
+		name        string
+		limits      streamLimits
+		wantChunks  int
+		wantDropped int
+	}{
+		{"unlimited", streamLimits{}, 10, 0},
+		{"max chunks", streamLimits{maxChunks: 4}, 4, 6},
+		{"max bytes", streamLimits{maxBytes: 25}, 2, 8}, // 每块 10 字节，第 3 块超限
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &StreamRecorder{limits: tt.limits}
+			for i := 0; i < 10; i++ {
+				r.Add(strings.Repeat("x", 10))
+			}
+			resp := r.Response(200)
+			if len(resp.StreamChunks) != tt.wantChunks || resp.StreamDroppedChunks != tt.wantDropped {
+				t.Errorf("chunks=%d dropped=%d, want %d/%d", len(resp.StreamChunks), resp.StreamDroppedChunks, tt.wantChunks, tt.wantDropped)
+			}
+			if resp.StreamTruncated != (tt.wantDropped > 0) {
+				t.Errorf("StreamTruncated = %v", resp.StreamTruncated)
+			}
+		})
+	}
+}
+
+func TestStreamRecorder_KeepsPrefixAfterOverflow(t *testing.T) {
+	r := &StreamRecorder{limits: streamLimits{maxBytes: 10}}
+	r.Add("12345678")
+	r.Add("too long")
+	r.Add("ok") // 已经超限，即使放得下也不再记录，避免日志中的数据块不连续
+	if resp := r.Response(200); len(resp.StreamChunks) != 1 || resp.StreamDroppedChunks != 2 {
+		t.Errorf("Response = %+v", resp)
+	}
+}
+
+func TestStreamRecorder_Nil(t *testing.T) {
+	var r *StreamRecorder
+	r.Add("chunk")
+	if resp := r.Response(200); resp.StatusCode != 200 || resp.StreamChunks != nil {
+		t.Errorf("nil recorder Response = %+v", resp)
+	}
+	if (&Logger{}).NewStreamRecorder() != nil {
+		t.Error("disabled logger should return a nil recorder")
+	}
+}
+
+// TestStreamRecorder_MemoryBounded 超大流响应下记录器保留的内存受上限约束。
+func TestStreamRecorder_MemoryBounded(t *testing.T) {
+	const chunkSize, chunkCount = 4 << 10, 20000 // 共约 80 MiB
+	r := &StreamRecorder{limits: streamLimits{maxChunks: DefaultMaxStreamChunks, maxBytes: DefaultMaxStreamBytes}}
+
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	for i := 0; i < chunkCount; i++ {
+		r.Add(strings.Repeat("x", chunkSize))
+	}
+	runtime.GC()
+	runtime.ReadMemStats(&after)
+
+	retained := int64(after.HeapAlloc) - int64(before.HeapAlloc)
+	if retained > 8<<20 {
+		t.Errorf("recorder retained %d bytes, want under 8 MiB", retained)
+	}
+	resp := r.Response(200)
+	if !resp.StreamTruncated || len(resp.StreamChunks) != DefaultMaxStreamBytes/chunkSize {
+		t.Errorf("chunks=%d truncated=%v", len(resp.StreamChunks), resp.StreamTruncated)
+	}
+	runtime.KeepAlive(r)
+}
+
+func TestSetStreamLimits(t *testing.T) {
+	defer processStreamLimits.Store(processStreamLimits.Load())
+
+	processStreamLimits.Store(nil)
+	if got := currentStreamLimits(); got.maxChunks != DefaultMaxStreamChunks || got.maxBytes != DefaultMaxStreamBytes {
+		t.Errorf("default limits = %+v", got)
+	}
+	SetStreamLimits(0, 100)
+	if got := currentStreamLimits(); got.maxChunks != 0 || got.maxBytes != 100 {
+		t.Errorf("limits = %+v", got)
+	}
+}
+
+func TestBodyRecorder_Limit(t *testing.T) {
+	r := &BodyRecorder{maxBytes: 10}
+	for _, chunk := range []string{"12345", "678", "90ab", "cd"} {
+		if n, err := r.Write([]byte(chunk)); n != len(chunk) || err != nil {
+			t.Fatalf("Write(%q) = %d, %v", chunk, n, err)
+		}
+	}
+	if r.String() != "1234567890" || !r.Truncated() {
+		t.Errorf("String = %q truncated = %v", r.String(), r.Truncated())
+	}
+
+	unlimited := &BodyRecorder{}
+	_, _ = unlimited.Write([]byte(strings.Repeat("x", 100)))
+	if len(unlimited.String()) != 100 || unlimited.Truncated() {
+		t.Errorf("unlimited recorder kept %d bytes, truncated = %v", len(unlimited.String()), unlimited.Truncated())
+	}
+}
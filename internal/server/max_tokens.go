@@ -0,0 +1,22 @@
+package server
+
+import (
+	"sync/atomic"
+
+	"github.com/yinxulai/ait/internal/server/types"
+)
+
+var processMaxTokens atomic.Int64
+
+// SetMaxTokens 设置本进程被测请求默认的输出 token 上限，通常在启动时由 --max-tokens 设置；
+// 0 表示不设置，任务自身设置了 max_tokens 时以任务为准。
+func SetMaxTokens(n int) {
+	processMaxTokens.Store(int64(n))
+}
+
+// applyProcessMaxTokens 任务未设置 max_tokens 时使用 --max-tokens；triton-grpc 不发送输出上限，保持原样。
+func applyProcessMaxTokens(input *types.Input) {
+	if n := int(processMaxTokens.Load()); n > 0 && input.MaxTokens == 0 && input.NormalizedProtocol() != types.ProtocolTritonGRPC {
+		input.MaxTokens = n
+	}
+}
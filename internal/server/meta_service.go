@@ -1,13 +1,15 @@
 package server
 
 import (
-	"errors"
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/yinxulai/ait/internal/server/client"
+	"github.com/yinxulai/ait/internal/server/config"
 	"github.com/yinxulai/ait/internal/server/modes/integrity"
 	"github.com/yinxulai/ait/internal/server/modes/turbo"
+	"github.com/yinxulai/ait/internal/server/prompt"
 	"github.com/yinxulai/ait/internal/server/types"
 )
 
@@ -19,56 +21,123 @@ type ProtocolMeta struct {
 }
 
 // ValidateTaskConfig validates and normalizes task configuration before it is persisted or executed.
+// 取值范围与字段组合由 config.CheckInput 统一校验（与 ait validate 共用同一套规则与提示），
+// 这里只补充需要加载文件或规则库的检查。
 func (s *serverImpl) ValidateTaskConfig(cfg TaskConfig) (TaskConfig, error) {
 	cfg.Name = strings.TrimSpace(cfg.Name)
 	if cfg.Name == "" {
-		return TaskConfig{}, errors.New("name is required")
+		return TaskConfig{}, config.Issue{Field: "name", Problem: "必填"}
 	}
 
 	input := cfg.Input
 	input.Mode = normalizeRunMode(input)
 	input.Protocol = types.NormalizeProtocol(input.Protocol)
-	if err := validateProtocol(input.Protocol); err != nil {
-		return TaskConfig{}, err
+	input.BaselineDir = strings.TrimSpace(input.BaselineDir)
+	input.WebhookURL = strings.TrimSpace(input.WebhookURL)
+	input.WebhookFormat = strings.ToLower(strings.TrimSpace(input.WebhookFormat))
+	input.Endpoints = normalizeEndpoints(input.Endpoints)
+	input.PromptLengthDist = strings.TrimSpace(input.PromptLengthDist)
+	input.ToolsFile = strings.TrimSpace(input.ToolsFile)
+	input.ResponseSchema = strings.TrimSpace(input.ResponseSchema)
+	input.EndpointStrategy = strings.ToLower(strings.TrimSpace(input.EndpointStrategy))
+	input.HTTPVersion = strings.ToLower(strings.TrimSpace(input.HTTPVersion))
+	input.ReplayFile = strings.TrimSpace(input.ReplayFile)
+	input.FallbackModel = strings.TrimSpace(input.FallbackModel)
+	if input.RunMode() == "standard" && input.ReplayFile != "" {
+		replay, err := prompt.LoadReplayFile(input.ReplayFile)
+		if err != nil {
+			return TaskConfig{}, fmt.Errorf("input.replay_file: %w", err)
+		}
+		// 每条失败样本只重跑一次
+		input.Count = replay.Count()
+	}
+	if issues := config.CheckInput(input); len(issues) > 0 {
+		return TaskConfig{}, issues[0]
 	}
-	if strings.TrimSpace(input.Model) == "" {
-		return TaskConfig{}, errors.New("input.model is required")
+
+	var slaExprs []string
+	for _, expr := range input.SLA {
+		if expr = strings.TrimSpace(expr); expr != "" {
+			slaExprs = append(slaExprs, expr)
+		}
+	}
+	input.SLA = slaExprs
+	if input.PromptLengthDist != "" {
+		// 语法已由 CheckInput 校验，这里统一为规范写法
+		if dist, err := prompt.ParseLengthDist(input.PromptLengthDist); err == nil {
+			input.PromptLengthDist = dist.String()
+		}
+	}
+	if input.ToolsFile != "" && input.RunMode() != "integrity" {
+		if _, err := client.LoadTools(input.ToolsFile); err != nil {
+			return TaskConfig{}, fmt.Errorf("input.tools_file: %w", err)
+		}
 	}
 
 	switch input.RunMode() {
 	case "standard":
 		input.Turbo = false
 		input.Integrity.Enabled = false
-		if err := validatePrompt(input); err != nil {
-			return TaskConfig{}, err
-		}
-		if input.Concurrency <= 0 {
-			return TaskConfig{}, errors.New("input.concurrency must be greater than 0")
+		if len(input.InputLengthSweep) > 0 && input.PromptLength <= 0 {
+			input.PromptLength = input.InputLengthSweep[0]
 		}
-		if input.Count <= 0 {
-			return TaskConfig{}, errors.New("input.count must be greater than 0")
+		if input.ConsistencyCheck {
+			// 确定性验证固定为非流式
+			input.Stream = false
 		}
 	case "turbo":
 		input.Turbo = true
 		input.Integrity.Enabled = false
-		if err := validatePrompt(input); err != nil {
-			return TaskConfig{}, err
-		}
+		input.CompareStream = false
+		input.Adaptive = false
+		input.BaselineDir = ""
+		input.TokenBudget = 0
+		input.WebhookURL = ""
+		input.ContentCheck = false
+		input.SLA = nil
+		input.VerifyRequestID = false
+		input.NormalizeTokens = false
+		input.BurstSize = 0
+		input.BurstInterval = 0
+		input.FallbackModel = ""
+		input.ProbeInterval = 0
+		input.InputLengthSweep = nil
+		input.PhaseRatio = 0
+		input.ReplayFile = ""
+		input.ConnectRetries = 0
+		input.ConsistencyCheck = false
+		input.CompareHTTPVersion = false
+		input.ResponseSchema = ""
+		input.PromptFilePerRun = false
 		input.TurboConfig = turbo.NormalizeConfig(input.TurboConfig, input.Count)
-		if input.TurboConfig.MaxConcurrency < input.TurboConfig.InitConcurrency {
-			return TaskConfig{}, errors.New("turbo_config.max_concurrency must be greater than or equal to init_concurrency")
-		}
 	case "integrity":
 		input.Turbo = false
 		input.Integrity.Enabled = true
-		if strings.TrimSpace(input.Integrity.Suite) == "" {
-			return TaskConfig{}, errors.New("integrity.suite is required")
-		}
+		input.CompareStream = false
+		input.Adaptive = false
+		input.BaselineDir = ""
+		input.TokenBudget = 0
+		input.WebhookURL = ""
+		input.ContentCheck = false
+		input.SLA = nil
+		input.VerifyRequestID = false
+		input.NormalizeTokens = false
+		input.BurstSize = 0
+		input.BurstInterval = 0
+		input.FallbackModel = ""
+		input.ProbeInterval = 0
+		input.InputLengthSweep = nil
+		input.PhaseRatio = 0
+		input.ReplayFile = ""
+		input.ConnectRetries = 0
+		input.ConsistencyCheck = false
+		input.CompareHTTPVersion = false
+		input.ResponseSchema = ""
+		input.PromptFilePerRun = false
+		input.ToolsFile = ""
 		if _, err := s.GetIntegritySuite(input.Protocol, input.Integrity.Suite); err != nil {
 			return TaskConfig{}, err
 		}
-	default:
-		return TaskConfig{}, fmt.Errorf("unsupported input.mode: %s", input.RunMode())
 	}
 
 	cfg.Input = input
@@ -138,11 +207,16 @@ func normalizeRunMode(input types.Input) string {
 	return input.RunMode()
 }
 
-func validatePrompt(input types.Input) error {
-	if strings.TrimSpace(input.PromptText) == "" && strings.TrimSpace(input.PromptFile) == "" && input.PromptLength <= 0 {
-		return errors.New("standard and turbo tasks require prompt_text, prompt_file or prompt_length")
+// normalizeEndpoints 去掉多端点列表中的空白项与重复项，保持原有顺序。
+func normalizeEndpoints(endpoints []string) []string {
+	var out []string
+	for _, endpoint := range endpoints {
+		endpoint = strings.TrimSpace(endpoint)
+		if endpoint != "" && !slices.Contains(out, endpoint) {
+			out = append(out, endpoint)
+		}
 	}
-	return nil
+	return out
 }
 
 func validateProtocol(protocol string) error {
@@ -157,5 +231,6 @@ func supportedProtocols() []ProtocolMeta {
 		{ID: types.ProtocolOpenAICompletions, Name: "OpenAI Chat Completions", DefaultEndpointURL: types.DefaultEndpointURL(types.ProtocolOpenAICompletions)},
 		{ID: types.ProtocolOpenAIResponses, Name: "OpenAI Responses", DefaultEndpointURL: types.DefaultEndpointURL(types.ProtocolOpenAIResponses)},
 		{ID: types.ProtocolAnthropicMessages, Name: "Anthropic Messages", DefaultEndpointURL: types.DefaultEndpointURL(types.ProtocolAnthropicMessages)},
+		{ID: types.ProtocolTritonGRPC, Name: "Triton gRPC (generate_stream)", DefaultEndpointURL: types.DefaultEndpointURL(types.ProtocolTritonGRPC)},
 	}
 }
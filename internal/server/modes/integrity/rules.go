@@ -11,6 +11,8 @@ import (
 	"path/filepath"
 	"strings"
 	"time"
+
+	"github.com/yinxulai/ait/internal/server/network"
 )
 
 // RuleIndex 规则索引结构
@@ -74,7 +76,7 @@ func NewRulesManager(version string) (*RulesManager, error) {
 	return &RulesManager{
 		version:      version,
 		cacheDir:     cacheDir,
-		httpClient:   &http.Client{Timeout: 10 * time.Second},
+		httpClient:   network.TelemetryOptions().NewClient(10 * time.Second),
 		updateSource: determineUpdateSource(version),
 	}, nil
 }
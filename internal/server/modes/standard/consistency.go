@@ -0,0 +1,101 @@
+package standard
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+
+	"github.com/yinxulai/ait/internal/server/client"
+	"github.com/yinxulai/ait/internal/server/redact"
+	"github.com/yinxulai/ait/internal/server/types"
+)
+
+// variantExcerptLength 确定性验证中每个输出版本摘要的最大字符数
+const variantExcerptLength = 100
+
+// outputHash 返回输出正文的 SHA-256 十六进制前 16 位，作为输出版本的标识。
+func outputHash(text string) string {
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:8])
+}
+
+// groupOutputs 按完整输出正文对成功请求分组，返回不同版本数、最常见版本的占比（百分比）
+// 与各版本明细。版本按次数从多到少排列，次数相同时先出现的在前；没有成功请求时返回零值。
+func groupOutputs(results []*client.ResponseMetrics) (int, float64, []types.OutputVariant) {
+	type group struct {
+		text  string
+		count int
+		first int
+	}
+	groups := map[string]*group{}
+	total := 0
+	for i, result := range results {
+		hash := outputHash(result.ResponseText)
+		g, ok := groups[hash]
+		if !ok {
+			g = &group{text: result.ResponseText, first: i}
+			groups[hash] = g
+		}
+		g.count++
+		total++
+	}
+	if total == 0 {
+		return 0, 0, nil
+	}
+
+	hashes := make([]string, 0, len(groups))
+	for hash := range groups {
+		hashes = append(hashes, hash)
+	}
+	sort.Slice(hashes, func(i, j int) bool {
+		a, b := groups[hashes[i]], groups[hashes[j]]
+		if a.count != b.count {
+			return a.count > b.count
+		}
+		return a.first < b.first
+	})
+
+	// 开启 --redact 时在脱敏后的文本上比较与截取，差异位置同样按脱敏后的文本计
+	base := []rune(redact.String(groups[hashes[0]].text))
+	variants := make([]types.OutputVariant, len(hashes))
+	for i, hash := range hashes {
+		g := groups[hash]
+		runes := []rune(redact.String(g.text))
+		offset := 0
+		if i > 0 {
+			offset = diffOffset(base, runes)
+		}
+		variants[i] = types.OutputVariant{
+			Hash:       hash,
+			Count:      g.count,
+			Rate:       float64(g.count) / float64(total) * 100,
+			DiffOffset: offset,
+			Excerpt:    runeExcerpt(runes, offset),
+		}
+	}
+	return len(variants), variants[0].Rate, variants
+}
+
+// diffOffset 返回 b 与 a 第一个不同字符的位置；b 是 a 的前缀或反之时为较短者的长度。
+func diffOffset(a, b []rune) int {
+	n := min(len(a), len(b))
+	for i := 0; i < n; i++ {
+		if a[i] != b[i] {
+			return i
+		}
+	}
+	return n
+}
+
+// runeExcerpt 返回从 offset 开始最多 variantExcerptLength 个字符的片段，前后被截断时加省略号。
+func runeExcerpt(runes []rune, offset int) string {
+	end := min(len(runes), offset+variantExcerptLength)
+	excerpt := string(runes[offset:end])
+	if offset > 0 {
+		excerpt = "…" + excerpt
+	}
+	if end < len(runes) {
+		excerpt += "…"
+	}
+	return excerpt
+}
@@ -0,0 +1,69 @@
+package standard
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/yinxulai/ait/internal/server/client"
+)
+
+func TestOutputHash(t *testing.T) {
+	a := outputHash("hello world")
+	if len(a) != 16 {
+		t.Fatalf("outputHash length = %d, want 16", len(a))
+	}
+	if a != outputHash("hello world") {
+		t.Error("outputHash should be stable for the same text")
+	}
+	if a == outputHash("hello world ") {
+		t.Error("outputHash should differ for different text")
+	}
+}
+
+func TestGroupOutputs(t *testing.T) {
+	results := []*client.ResponseMetrics{
+		{ResponseText: "巴黎是法国的首都。"},
+		{ResponseText: "巴黎是法国的首都，也是最大的城市。"},
+		{ResponseText: "巴黎是法国的首都。"},
+		{ResponseText: "巴黎是法国的首都。"},
+	}
+
+	distinct, rate, variants := groupOutputs(results)
+	if distinct != 2 || rate != 75 {
+		t.Fatalf("distinct = %d, rate = %v, want 2, 75", distinct, rate)
+	}
+	if variants[0].Count != 3 || variants[0].DiffOffset != 0 || variants[0].Excerpt != "巴黎是法国的首都。" {
+		t.Errorf("dominant variant = %+v", variants[0])
+	}
+	// 差异从第 9 个字符（"，"）开始
+	if v := variants[1]; v.Count != 1 || v.Rate != 25 || v.DiffOffset != 8 || v.Excerpt != "…，也是最大的城市。" {
+		t.Errorf("second variant = %+v", v)
+	}
+	if variants[0].Hash == variants[1].Hash {
+		t.Error("variants should have different hashes")
+	}
+}
+
+func TestGroupOutputs_TiesAndLongText(t *testing.T) {
+	long := strings.Repeat("a", 150)
+	results := []*client.ResponseMetrics{
+		{ResponseText: long},
+		{ResponseText: long[:120] + "b" + long[121:]},
+	}
+
+	distinct, rate, variants := groupOutputs(results)
+	if distinct != 2 || rate != 50 {
+		t.Fatalf("distinct = %d, rate = %v, want 2, 50", distinct, rate)
+	}
+	// 次数相同时先出现的版本在前，摘要截断为 100 个字符
+	if variants[0].Excerpt != strings.Repeat("a", 100)+"…" {
+		t.Errorf("dominant excerpt = %q", variants[0].Excerpt)
+	}
+	if v := variants[1]; v.DiffOffset != 120 || v.Excerpt != "…b"+strings.Repeat("a", 29) {
+		t.Errorf("second variant = %+v", v)
+	}
+
+	if distinct, rate, variants := groupOutputs(nil); distinct != 0 || rate != 0 || variants != nil {
+		t.Errorf("empty results: got %d, %v, %v", distinct, rate, variants)
+	}
+}
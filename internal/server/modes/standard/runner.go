@@ -3,13 +3,21 @@ package standard
 import (
 	"context"
 	"math"
+	"slices"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/yinxulai/ait/internal/server/client"
+	"github.com/yinxulai/ait/internal/server/clock"
 	"github.com/yinxulai/ait/internal/server/logger"
+	"github.com/yinxulai/ait/internal/server/network"
+	"github.com/yinxulai/ait/internal/server/prompt"
 	"github.com/yinxulai/ait/internal/server/queue"
+	"github.com/yinxulai/ait/internal/server/redact"
+	"github.com/yinxulai/ait/internal/server/sla"
+	"github.com/yinxulai/ait/internal/server/stats"
 	"github.com/yinxulai/ait/internal/server/types"
 	"github.com/yinxulai/ait/internal/server/upload"
 )
@@ -22,6 +30,11 @@ type Runner struct {
 	client   client.ModelClient
 	stopCh   chan struct{}
 	stopOnce sync.Once
+	clock    clock.Clock
+	conns    network.ConnGauge // 同时在途的连接数
+
+	breaker *stats.ErrorRateBreaker // 错误率熔断，nil 表示不熔断
+	schema  *stats.JSONSchema       // 响应 JSON schema，nil 表示不校验
 }
 
 type RequestDoneCallback func(metrics *client.ResponseMetrics, index int, err error)
@@ -38,22 +51,54 @@ func NewRunner(taskID string, config types.Input) (*Runner, error) {
 	if err != nil {
 		return nil, err
 	}
+	var schema *stats.JSONSchema
+	if config.ResponseSchema != "" {
+		if schema, err = stats.LoadJSONSchema(config.ResponseSchema); err != nil {
+			return nil, err
+		}
+	}
 
 	return &Runner{
 		taskID: taskID,
 		client: client,
 		input:  config,
-		upload: upload.New(),
+		upload: upload.New(network.TelemetryOptions()),
 		stopCh: make(chan struct{}),
+		clock:  clock.Real(),
+
+		breaker: stats.NewErrorRateBreaker(config.AbortOnErrorRate, config.AbortMinSamples),
+		schema:  schema,
 	}, nil
 }
 
+// SetClock 替换执行器的时间源，测试注入假时钟以瞬时推进耗时与进度刷新。
+func (r *Runner) SetClock(c clock.Clock) {
+	r.clock = c
+}
+
+// timeSource 返回执行器的时间源；未设置（如测试中直接构造的 Runner）时使用真实时钟。
+func (r *Runner) timeSource() clock.Clock {
+	if r.clock == nil {
+		return clock.Real()
+	}
+	return r.clock
+}
+
 func (r *Runner) Stop() {
 	r.stopOnce.Do(func() {
 		close(r.stopCh)
 	})
 }
 
+// observeAbort 把一个完成的请求计入错误率熔断；触发时与手动停止一样调用 Stop，
+// 不再派发新请求并取消在途请求。
+func (r *Runner) observeAbort(metrics *client.ResponseMetrics, err error) {
+	success := err == nil && metrics != nil && metrics.ErrorMessage == "" && metrics.HasOutput()
+	if r.breaker.Observe(success) {
+		r.Stop()
+	}
+}
+
 func (r *Runner) stopContext() context.Context {
 	ctx, cancel := context.WithCancel(context.Background())
 	go func() {
@@ -86,14 +131,37 @@ type requestJob struct {
 	index int
 }
 
-func (r *Runner) executeRequest(ctx context.Context, idx int) (*client.ResponseMetrics, error) {
-	if r.input.PromptMode == "raw" {
-		rawBody := r.input.PromptSource.GetContentByIndex(idx)
-		return r.client.RawRequest(ctx, rawBody)
+// executeRequest 执行第 idx 个请求；dispatchedAt 为派发给执行 goroutine 的时间，用于记录本地排队时间。
+func (r *Runner) executeRequest(ctx context.Context, idx int, dispatchedAt time.Time) (*client.ResponseMetrics, error) {
+	var metrics *client.ResponseMetrics
+	var err error
+	promptIndex := idx
+	if r.input.ConsistencyCheck {
+		// 确定性验证时所有请求使用同一 prompt
+		promptIndex = 0
+	}
+	ctx, release := r.conns.Track(ctx)
+	defer release()
+	startedAt := r.timeSource().Now()
+	content, err := types.PromptContent(ctx, r.input.PromptSource, promptIndex)
+	switch {
+	case err != nil:
+		// prompt 生成失败（如 --prompt-command 超时）时不发送请求，记为失败，耗时为生成 prompt 所用时间
+		metrics = &client.ResponseMetrics{ErrorMessage: err.Error(), TotalTime: r.timeSource().Now().Sub(startedAt)}
+	case r.input.PromptMode == "raw":
+		metrics, err = r.client.RawRequest(ctx, content)
+	default:
+		systemPrompt := r.input.PromptSource.GetSystemContent()
+		metrics, err = r.client.Request(ctx, systemPrompt, content, r.input.Stream)
+	}
+	if metrics != nil {
+		metrics.Prompt = content
+		metrics.Index = idx
+		metrics.StartedAt = startedAt
+		metrics.CompletedAt = r.timeSource().Now()
+		metrics.QueueWaitTime = max(startedAt.Sub(dispatchedAt), 0)
 	}
-	systemPrompt := r.input.PromptSource.GetSystemContent()
-	userPrompt := r.input.PromptSource.GetContentByIndex(idx)
-	return r.client.Request(ctx, systemPrompt, userPrompt, r.input.Stream)
+	return metrics, err
 }
 
 func (r *Runner) runRequestQueue(results []*client.ResponseMetrics, onDone RequestDoneCallback) int {
@@ -112,6 +180,7 @@ func (r *Runner) runRequestQueue(results []*client.ResponseMetrics, onDone Reque
 		go func() {
 			defer wg.Done()
 			for job := range jobs.Items() {
+				dispatchedAt := r.timeSource().Now()
 				select {
 				case <-r.stopCh:
 					return
@@ -119,11 +188,12 @@ func (r *Runner) runRequestQueue(results []*client.ResponseMetrics, onDone Reque
 				}
 
 				atomic.AddInt64(&launched, 1)
-				metrics, err := r.executeRequest(ctx, job.index)
+				metrics, err := r.executeRequest(ctx, job.index, dispatchedAt)
 				if metrics != nil {
 					results[job.index] = metrics
 				}
-				if err == nil && metrics != nil && metrics.ErrorMessage == "" && r.upload != nil {
+				r.observeAbort(metrics, err)
+				if err == nil && metrics != nil && metrics.ErrorMessage == "" && r.upload != nil && r.upload.Sampled() {
 					r.upload.UploadReport(r.taskID, metrics, r.input)
 				}
 				if onDone != nil {
@@ -147,18 +217,18 @@ enqueueLoop:
 // Run 执行性能测试，返回结果数据
 func (r *Runner) Run() (*types.ReportData, error) {
 	results := make([]*client.ResponseMetrics, r.input.Count)
-	start := time.Now()
+	start := r.timeSource().Now()
 	launchedCount := r.runRequestQueue(results, nil)
-	elapsed := time.Since(start)
-	return r.calculateResult(results, elapsed, launchedCount), nil
+	elapsed := r.timeSource().Since(start)
+	return r.result(results, elapsed, launchedCount), nil
 }
 
 func (r *Runner) RunWithCallback(cb RequestDoneCallback) (*types.ReportData, error) {
 	results := make([]*client.ResponseMetrics, r.input.Count)
-	start := time.Now()
+	start := r.timeSource().Now()
 	launchedCount := r.runRequestQueue(results, cb)
-	elapsed := time.Since(start)
-	return r.calculateResult(results, elapsed, launchedCount), nil
+	elapsed := r.timeSource().Since(start)
+	return r.result(results, elapsed, launchedCount), nil
 }
 
 // RunWithProgress 运行性能测试并实时显示进度
@@ -166,7 +236,8 @@ func (r *Runner) RunWithProgress(progressCallback func(types.StatsData)) (*types
 	ctx := r.stopContext()
 	var wg sync.WaitGroup
 	results := make([]*client.ResponseMetrics, r.input.Count)
-	start := time.Now()
+	clk := r.timeSource()
+	start := clk.Now()
 	ch := make(chan int, r.input.Concurrency)
 
 	completed := int64(0)
@@ -184,16 +255,17 @@ func (r *Runner) RunWithProgress(progressCallback func(types.StatsData)) (*types
 	var errorMessages []string
 	var ttftsMutex sync.Mutex
 	launchedCount := 0
+	tpsWindow := stats.NewTPSWindow(stats.DefaultTPSWindow)
 
 	// 启动进度更新 goroutine
 	stopProgress := make(chan bool)
 	go func() {
-		ticker := time.NewTicker(500 * time.Millisecond)
+		ticker := clk.NewTicker(500 * time.Millisecond)
 		defer ticker.Stop()
 
 		for {
 			select {
-			case <-ticker.C:
+			case <-ticker.C():
 				ttftsMutex.Lock()
 				stats := types.StatsData{
 					CompletedCount:         int(atomic.LoadInt64(&completed)),
@@ -210,7 +282,8 @@ func (r *Runner) RunWithProgress(progressCallback func(types.StatsData)) (*types
 					CacheHitRates:          make([]float64, len(cacheHitRates)),
 					ErrorMessages:          make([]string, len(errorMessages)),
 					StartTime:              start,
-					ElapsedTime:            time.Since(start),
+					ElapsedTime:            clk.Since(start),
+					InstantTPS:             tpsWindow.Rate(clk.Now(), start),
 				}
 				copy(stats.TTFTs, ttfts)
 				copy(stats.TotalTimes, totalTimes)
@@ -238,21 +311,13 @@ func (r *Runner) RunWithProgress(progressCallback func(types.StatsData)) (*types
 		}
 		launchedCount++
 		wg.Add(1)
+		dispatchedAt := clk.Now()
 		go func(idx int) {
 			defer wg.Done()
 			defer func() { <-ch }()
 
-			// 获取当前请求使用的prompt
-			var metrics *client.ResponseMetrics
-			var err error
-			if r.input.PromptMode == "raw" {
-				rawBody := r.input.PromptSource.GetContentByIndex(idx)
-				metrics, err = r.client.RawRequest(ctx, rawBody)
-			} else {
-				systemPrompt := r.input.PromptSource.GetSystemContent()
-				userPrompt := r.input.PromptSource.GetContentByIndex(idx)
-				metrics, err = r.client.Request(ctx, systemPrompt, userPrompt, r.input.Stream)
-			}
+			metrics, err := r.executeRequest(ctx, idx, dispatchedAt)
+			r.observeAbort(metrics, err)
 			if err != nil {
 				ttftsMutex.Lock()
 				errorMessages = append(errorMessages, err.Error())
@@ -279,6 +344,7 @@ func (r *Runner) RunWithProgress(progressCallback func(types.StatsData)) (*types
 			}
 
 			results[idx] = metrics
+			tpsWindow.Add(clk.Now(), metrics.CompletionTokens)
 
 			ttftsMutex.Lock()
 			ttfts = append(ttfts, metrics.TimeToFirstToken)
@@ -293,7 +359,7 @@ func (r *Runner) RunWithProgress(progressCallback func(types.StatsData)) (*types
 			cacheHitRates = append(cacheHitRates, calculateCacheHitRate(metrics))
 			ttftsMutex.Unlock()
 
-			if metrics.ErrorMessage == "" && r.upload != nil {
+			if metrics.ErrorMessage == "" && r.upload != nil && r.upload.Sampled() {
 				r.upload.UploadReport(r.taskID, metrics, r.input)
 			}
 
@@ -302,7 +368,7 @@ func (r *Runner) RunWithProgress(progressCallback func(types.StatsData)) (*types
 	}
 	wg.Wait()
 	close(stopProgress)
-	elapsed := time.Since(start)
+	elapsed := clk.Since(start)
 
 	// 最后一次进度更新
 	ttftsMutex.Lock()
@@ -322,6 +388,7 @@ func (r *Runner) RunWithProgress(progressCallback func(types.StatsData)) (*types
 		ErrorMessages:          make([]string, len(errorMessages)),
 		StartTime:              start,
 		ElapsedTime:            elapsed,
+		InstantTPS:             tpsWindow.Rate(start.Add(elapsed), start),
 	}
 	copy(finalStats.TTFTs, ttfts)
 	copy(finalStats.TotalTimes, totalTimes)
@@ -338,7 +405,21 @@ func (r *Runner) RunWithProgress(progressCallback func(types.StatsData)) (*types
 	progressCallback(finalStats)
 
 	// 计算并返回结果
-	return r.calculateResult(results, elapsed, launchedCount), nil
+	return r.result(results, elapsed, launchedCount), nil
+}
+
+// result 计算统计结果并附上运行期间的连接数峰值与错误率熔断的触发快照。此时所有请求都已结束，一并关闭客户端的空闲连接，
+// 避免多场景连跑时连接累积。
+func (r *Runner) result(results []*client.ResponseMetrics, elapsed time.Duration, launched int) *types.ReportData {
+	client.CloseIdleConnections(r.client)
+	data := r.calculateResult(results, elapsed, launched)
+	data.PeakConcurrentConnections = r.conns.Peak()
+	data.SetConnReuse(r.conns.Reuse())
+	if abort := r.breaker.Stats(); abort != nil {
+		data.AbortedEarly = true
+		data.Abort = abort
+	}
+	return data
 }
 
 // calculateResult 计算性能统计结果
@@ -363,13 +444,110 @@ func (r *Runner) calculateResult(results []*client.ResponseMetrics, totalTime ti
 			continue
 		}
 		allResults = append(allResults, result)
-		if result.ErrorMessage == "" && result.CompletionTokens > 0 {
+		if result.ErrorMessage == "" && result.HasOutput() {
 			successResults = append(successResults, result)
 		}
 	}
 	if len(allResults) == 0 {
 		return &types.ReportData{}
 	}
+	finishReasons := countFinishReasons(allResults)
+	maxTokensUsage := calculateMaxTokensUsage(r.input.MaxTokens, successResults)
+	slaResults := evaluateSLA(r.input.SLA, allResults)
+	var inputTokenHistogram []types.TokenBucket
+	if r.input.PromptLengthDist != "" {
+		inputTokens := make([]int, 0, len(successResults))
+		for _, result := range successResults {
+			inputTokens = append(inputTokens, result.PromptTokens)
+		}
+		inputTokenHistogram = stats.TokenHistogram(inputTokens)
+	}
+	endpointStats := calculateEndpointStats(r.input.Endpoints, allResults)
+	targetIPStats := calculateTargetIPStats(allResults)
+	resolvedIPs := collectResolvedIPs(allResults)
+	avgQueueWait, maxQueueWait := calculateQueueWait(allResults)
+	adaptedRequests, adaptedParams := countAdaptedRequests(allResults)
+	var requestIDCheck *types.RequestIDCheckStats
+	if r.input.VerifyRequestID {
+		requestIDCheck = checkRequestIDs(allResults)
+	}
+	var bursts []types.BurstStats
+	if r.input.BurstSize > 0 {
+		bursts = calculateBurstStats(results, r.input.BurstSize)
+	}
+	var fallback *types.FallbackStats
+	if r.input.FallbackModel != "" {
+		fallback = countFallbacks(allResults)
+	}
+	var avgToolCallCount float64
+	if r.input.ToolsFile != "" && len(successResults) > 0 {
+		toolCalls := 0
+		for _, result := range successResults {
+			toolCalls += result.ToolCallCount
+		}
+		avgToolCallCount = float64(toolCalls) / float64(len(successResults))
+	}
+	var avgNormalizedTPS, normalizedTokenRatio float64
+	if r.input.NormalizeTokens {
+		avgNormalizedTPS, normalizedTokenRatio = normalizedTPS(successResults)
+	}
+	var inputLength int
+	var avgPrefillTPS float64
+	if len(r.input.InputLengthSweep) > 0 {
+		// 扫描的每一轮以 PromptLength 为本轮目标长度
+		inputLength = r.input.PromptLength
+		avgPrefillTPS = prefillTPS(successResults)
+	}
+	phaseStats := calculatePhaseStats(successResults, r.input.PhaseRatio)
+	avgEventTimes, avgBlockStarts := averageEventTimes(successResults)
+	errorGroups := groupErrors(allResults)
+	var throttledCount int
+	var netErrorKinds map[string]int
+	var httpProtocols map[string]int
+	for _, result := range allResults {
+		if result.Throttled() {
+			throttledCount++
+		}
+		if result.HTTPProto != "" {
+			if httpProtocols == nil {
+				httpProtocols = map[string]int{}
+			}
+			httpProtocols[result.HTTPProto]++
+		}
+		if result.NetErrorKind != "" {
+			if netErrorKinds == nil {
+				netErrorKinds = map[string]int{}
+			}
+			netErrorKinds[result.NetErrorKind]++
+		}
+	}
+	var distinctOutputs int
+	var consistencyRate float64
+	var outputVariants []types.OutputVariant
+	if r.input.ConsistencyCheck {
+		distinctOutputs, consistencyRate, outputVariants = groupOutputs(successResults)
+	}
+	resolveOverridden := endpointResolveOverridden(r.input)
+	var replayOf string
+	if replay, ok := r.input.PromptSource.(types.ReplaySource); ok {
+		replayOf = replay.OriginTaskID()
+	}
+	firstRequest := calculateFirstRequest(successResults)
+	var emptyContentCount int
+	for _, result := range successResults {
+		if result.EmptyContent {
+			emptyContentCount++
+		}
+	}
+	var emptyContentRate float64
+	if len(successResults) > 0 {
+		emptyContentRate = float64(emptyContentCount) / float64(len(successResults)) * 100
+	}
+	var suspiciousCount int
+	var suspiciousSamples []types.SuspiciousContent
+	if r.input.ContentCheck {
+		suspiciousCount, suspiciousSamples = checkContents(successResults)
+	}
 
 	validResults := successResults
 	if len(validResults) == 0 {
@@ -439,7 +617,7 @@ func (r *Runner) calculateResult(results []*client.ResponseMetrics, totalTime ti
 
 	var sumTTFT, sumTotalTime time.Duration
 	var sumDNSTime, sumConnectTime, sumTLSTime time.Duration
-	var sumOutputTokens, sumInputTokens, sumCachedInputTokens int
+	var sumOutputTokens, sumInputTokens, sumCachedInputTokens, sumCacheCreationInputTokens int
 	var sumThinkingTokens int
 	var sumTPOT time.Duration
 	var sumCacheHitRate, sumTotalThroughputTPS float64
@@ -515,6 +693,7 @@ func (r *Runner) calculateResult(results []*client.ResponseMetrics, totalTime ti
 		}
 
 		sumCachedInputTokens += result.CachedInputTokens
+		sumCacheCreationInputTokens += result.CacheCreationInputTokens
 		if result.CachedInputTokens < minCachedInputTokens {
 			minCachedInputTokens = result.CachedInputTokens
 		}
@@ -574,16 +753,53 @@ func (r *Runner) calculateResult(results []*client.ResponseMetrics, totalTime ti
 
 	if validCount == 0 {
 		return &types.ReportData{
-			TotalRequests: requestCount,
-			Concurrency:   r.input.Concurrency,
-			TotalTime:     totalTime,
-			IsStream:      r.input.Stream,
-			IsThinking:    r.input.Thinking,
-			Protocol:      r.input.NormalizedProtocol(),
-			EndpointURL:   resolvedEndpoint,
-			BaseUrl:       resolvedEndpoint,
-			ErrorRate:     errorRate,
-			SuccessRate:   successRate,
+			TotalRequests:    requestCount,
+			Concurrency:      r.input.Concurrency,
+			TotalTime:        totalTime,
+			IsStream:         r.input.Stream,
+			IsThinking:       r.input.ThinkingEnabled(),
+			Timestamp:        r.timeSource().Now().Format(time.RFC3339),
+			Protocol:         r.input.NormalizedProtocol(),
+			Model:            r.input.Model,
+			ModelDisplayName: r.input.ModelAlias,
+			EndpointURL:      resolvedEndpoint,
+			BaseUrl:          resolvedEndpoint,
+			ErrorRate:        errorRate,
+			SuccessRate:      successRate,
+			FinishReasons:    finishReasons,
+			EndpointStats:    endpointStats,
+			TargetIPStats:    targetIPStats,
+			RequestIDCheck:   requestIDCheck,
+			Bursts:           bursts,
+			Fallback:         fallback,
+			InputLength:      inputLength,
+			PhaseStats:       phaseStats,
+			ReplayOf:         replayOf,
+			AvgEventTimes:    avgEventTimes,
+			AvgBlockStarts:   avgBlockStarts,
+			Errors:           errorGroups,
+			ThrottledCount:   throttledCount,
+			NetErrorKinds:    netErrorKinds,
+			DistinctOutputs:  distinctOutputs,
+			ConsistencyRate:  consistencyRate,
+			OutputVariants:   outputVariants,
+
+			ResolveOverridden: resolveOverridden,
+
+			HTTPVersion:   httpVersionLabel(r.input.HTTPVersion),
+			HTTPProtocols: httpProtocols,
+
+			AcceptEncoding: r.input.AcceptEncoding,
+
+			Redacted: redact.Enabled(),
+
+			ResolvedIPs: resolvedIPs,
+
+			AvgQueueWaitTime: avgQueueWait,
+			MaxQueueWaitTime: maxQueueWait,
+
+			AdaptedRequests: adaptedRequests,
+			AdaptedParams:   adaptedParams,
 		}
 	}
 
@@ -604,11 +820,38 @@ func (r *Runner) calculateResult(results []*client.ResponseMetrics, totalTime ti
 		avgTPOT = sumTPOT / time.Duration(validTPOTCount)
 	}
 
+	// 思考耗时只统计实际出现思考阶段的请求，避免未思考的请求把均值拉低
+	var avgThinkingTime, minThinkingTime, maxThinkingTime, sumThinkingTime time.Duration
+	thinkingTimeCount := 0
+	for _, result := range validResults {
+		if result.ThinkingTime <= 0 {
+			continue
+		}
+		if thinkingTimeCount == 0 || result.ThinkingTime < minThinkingTime {
+			minThinkingTime = result.ThinkingTime
+		}
+		if result.ThinkingTime > maxThinkingTime {
+			maxThinkingTime = result.ThinkingTime
+		}
+		sumThinkingTime += result.ThinkingTime
+		thinkingTimeCount++
+	}
+	if thinkingTimeCount > 0 {
+		avgThinkingTime = sumThinkingTime / time.Duration(thinkingTimeCount)
+	}
+
+	avgTTFB, minTTFB, maxTTFB, avgFirstTokenGap := calculateTTFB(validResults, r.input.Stream)
+
 	avgOutputTokens := sumOutputTokens / validCount
 	avgInputTokens := sumInputTokens / validCount
 	avgCachedInputTokens := sumCachedInputTokens / validCount
 	avgThinkingTokens := sumThinkingTokens / validCount
 	avgCacheHitRate := sumCacheHitRate / float64(validCount)
+	// 按 token 加权的缓存命中占比：长 prompt 的请求权重更大，更接近实际节省的输入量
+	var cachedTokenRatio float64
+	if sumInputTokens > 0 {
+		cachedTokenRatio = float64(sumCachedInputTokens) / float64(sumInputTokens)
+	}
 
 	var sumTPS float64
 	for _, result := range validResults {
@@ -617,6 +860,27 @@ func (r *Runner) calculateResult(results []*client.ResponseMetrics, totalTime ti
 		}
 	}
 	avgTPS := sumTPS / float64(validCount)
+
+	var sumSteadyTPS, minSteadyTPS, maxSteadyTPS float64
+	steadyCount := 0
+	for _, result := range validResults {
+		steady, ok := stats.SteadyTPS(result.CompletionTokens, result.TotalTime, result.TimeToFirstToken)
+		if !ok {
+			continue
+		}
+		if steadyCount == 0 || steady < minSteadyTPS {
+			minSteadyTPS = steady
+		}
+		if steady > maxSteadyTPS {
+			maxSteadyTPS = steady
+		}
+		sumSteadyTPS += steady
+		steadyCount++
+	}
+	var avgSteadyTPS float64
+	if steadyCount > 0 {
+		avgSteadyTPS = sumSteadyTPS / float64(steadyCount)
+	}
 	avgTotalThroughputTPS := sumTotalThroughputTPS / float64(validCount)
 
 	var varianceSumTotalTime, varianceSumTTFT, varianceSumTPOT float64
@@ -684,6 +948,12 @@ func (r *Runner) calculateResult(results []*client.ResponseMetrics, totalTime ti
 	stdDevTPS := math.Sqrt(varianceSumTPS / float64(validCount))
 	stdDevTotalThroughputTPS := math.Sqrt(varianceSumTotalThroughputTPS / float64(validCount))
 
+	totalTimes := make([]time.Duration, 0, len(validResults))
+	for _, result := range validResults {
+		totalTimes = append(totalTimes, result.TotalTime)
+	}
+	p99TotalTime := percentileDuration(totalTimes, 99)
+
 	var rpm, tpm float64
 	if totalTime.Minutes() > 0 {
 		rpm = float64(successCount) / totalTime.Minutes()
@@ -695,13 +965,17 @@ func (r *Runner) calculateResult(results []*client.ResponseMetrics, totalTime ti
 		Concurrency:                 r.input.Concurrency,
 		TotalTime:                   totalTime,
 		IsStream:                    r.input.Stream,
-		IsThinking:                  r.input.Thinking,
+		IsThinking:                  r.input.ThinkingEnabled(),
+		Timestamp:                   r.timeSource().Now().Format(time.RFC3339),
 		Protocol:                    r.input.NormalizedProtocol(),
+		Model:                       r.input.Model,
+		ModelDisplayName:            r.input.ModelAlias,
 		EndpointURL:                 resolvedEndpoint,
 		BaseUrl:                     resolvedEndpoint,
 		AvgTotalTime:                avgTotalTime,
 		MinTotalTime:                minTotalTime,
 		MaxTotalTime:                maxTotalTime,
+		P99TotalTime:                p99TotalTime,
 		AvgDNSTime:                  avgDNSTime,
 		MinDNSTime:                  minDNSTime,
 		MaxDNSTime:                  maxDNSTime,
@@ -736,6 +1010,9 @@ func (r *Runner) calculateResult(results []*client.ResponseMetrics, totalTime ti
 		AvgTPS:                      avgTPS,
 		MinTPS:                      minTPS,
 		MaxTPS:                      maxTPS,
+		AvgThinkingTime:             avgThinkingTime,
+		MinThinkingTime:             minThinkingTime,
+		MaxThinkingTime:             maxThinkingTime,
 		AvgTotalThroughputTPS:       avgTotalThroughputTPS,
 		MinTotalThroughputTPS:       minTotalThroughputTPS,
 		MaxTotalThroughputTPS:       maxTotalThroughputTPS,
@@ -753,5 +1030,842 @@ func (r *Runner) calculateResult(results []*client.ResponseMetrics, totalTime ti
 		StdDevTotalThroughputTPS:    stdDevTotalThroughputTPS,
 		ErrorRate:                   errorRate,
 		SuccessRate:                 successRate,
+		FinishReasons:               finishReasons,
+		AvgSteadyTPS:                avgSteadyTPS,
+		MinSteadyTPS:                minSteadyTPS,
+		MaxSteadyTPS:                maxSteadyTPS,
+		EndpointStats:               endpointStats,
+		SuspiciousContentCount:      suspiciousCount,
+		SuspiciousContentSamples:    suspiciousSamples,
+
+		TotalCachedInputTokens:        sumCachedInputTokens,
+		TotalCacheCreationInputTokens: sumCacheCreationInputTokens,
+		CachedTokenRatio:              cachedTokenRatio,
+
+		SLAResults:          slaResults,
+		InputTokenHistogram: inputTokenHistogram,
+		TargetIPStats:       targetIPStats,
+		AvgToolCallCount:    avgToolCallCount,
+		RequestIDCheck:      requestIDCheck,
+
+		AvgNormalizedTPS:     avgNormalizedTPS,
+		NormalizedTokenRatio: normalizedTokenRatio,
+
+		Bursts:   bursts,
+		Fallback: fallback,
+
+		InputLength:   inputLength,
+		AvgPrefillTPS: avgPrefillTPS,
+
+		PhaseStats: phaseStats,
+		ReplayOf:   replayOf,
+
+		AvgEventTimes:  avgEventTimes,
+		AvgBlockStarts: avgBlockStarts,
+
+		Errors:         errorGroups,
+		ThrottledCount: throttledCount,
+		NetErrorKinds:  netErrorKinds,
+
+		DistinctOutputs: distinctOutputs,
+		ConsistencyRate: consistencyRate,
+		OutputVariants:  outputVariants,
+
+		ResolveOverridden: resolveOverridden,
+
+		EmptyContentCount: emptyContentCount,
+		EmptyContentRate:  emptyContentRate,
+
+		FirstRequest: firstRequest,
+
+		HTTPVersion:   httpVersionLabel(r.input.HTTPVersion),
+		HTTPProtocols: httpProtocols,
+
+		MaxTokensUsage: maxTokensUsage,
+
+		AvgTTFB:          avgTTFB,
+		MinTTFB:          minTTFB,
+		MaxTTFB:          maxTTFB,
+		AvgFirstTokenGap: avgFirstTokenGap,
+
+		AcceptEncoding:    r.input.AcceptEncoding,
+		ResponseEncodings: calculateEncodingStats(validResults),
+
+		Samples: metricSamples(validResults, r.input.Stream),
+
+		SchemaCheck: checkSchema(r.schema, successResults),
+
+		Redacted: redact.Enabled(),
+
+		ResolvedIPs: resolvedIPs,
+
+		AvgQueueWaitTime: avgQueueWait,
+		MaxQueueWaitTime: maxQueueWait,
+
+		AdaptedRequests: adaptedRequests,
+		AdaptedParams:   adaptedParams,
+	}
+}
+
+// checkSchema 按 JSON schema 逐条校验成功响应的正文，统计合法 JSON 数、合规数与前几条不合规样例；
+// 未配置 schema 时返回 nil。
+func checkSchema(schema *stats.JSONSchema, results []*client.ResponseMetrics) *types.SchemaCheckStats {
+	if schema == nil {
+		return nil
+	}
+	s := &types.SchemaCheckStats{Checked: len(results)}
+	for _, result := range results {
+		reason, detail := schema.Check(result.ResponseText)
+		if reason != stats.SchemaInvalidJSON {
+			s.ValidJSON++
+		}
+		if reason == "" {
+			s.Compliant++
+			continue
+		}
+		if len(s.Samples) < suspiciousSampleLimit {
+			s.Samples = append(s.Samples, types.SchemaViolation{
+				Reason:  reason,
+				Detail:  contentExcerpt(detail),
+				Excerpt: contentExcerpt(result.ResponseText),
+			})
+		}
+	}
+	if s.Checked > 0 {
+		s.ValidJSONRate = float64(s.ValidJSON) / float64(s.Checked) * 100
+		s.ComplianceRate = float64(s.Compliant) / float64(s.Checked) * 100
+	}
+	return s
+}
+
+// metricSamples 收集参与平均值计算的逐请求样本，口径与 AvgTotalTime / AvgTTFT / AvgTPOT / AvgTPS 一致。
+func metricSamples(results []*client.ResponseMetrics, stream bool) *types.MetricSamples {
+	samples := &types.MetricSamples{
+		TotalTime: make([]float64, 0, len(results)),
+		TPS:       make([]float64, 0, len(results)),
+	}
+	for _, result := range results {
+		samples.TotalTime = append(samples.TotalTime, durationMillis(result.TotalTime))
+		var tps float64
+		if result.TotalTime.Seconds() > 0 {
+			tps = float64(result.CompletionTokens) / result.TotalTime.Seconds()
+		}
+		samples.TPS = append(samples.TPS, tps)
+		if stream {
+			samples.TTFT = append(samples.TTFT, durationMillis(result.TimeToFirstToken))
+		}
+		if result.CompletionTokens > 1 {
+			tpot := (result.TotalTime - result.TimeToFirstToken) / time.Duration(result.CompletionTokens-1)
+			samples.TPOT = append(samples.TPOT, durationMillis(tpot))
+		}
+	}
+	return samples
+}
+
+func durationMillis(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// httpVersionLabel 返回报告中展示的 HTTP 版本配置，auto 与留空时为空字符串。
+// percentileDuration 用最近秩法计算第 p 百分位数（0-100），会对 values 原地排序；空切片返回 0
+func percentileDuration(values []time.Duration, p float64) time.Duration {
+	if len(values) == 0 {
+		return 0
+	}
+	slices.Sort(values)
+	rank := int(math.Ceil(p / 100 * float64(len(values))))
+	if rank < 1 {
+		rank = 1
+	}
+	return values[rank-1]
+}
+
+func httpVersionLabel(version string) string {
+	if version == types.HTTPVersionAuto {
+		return ""
+	}
+	return version
+}
+
+// endpointResolveOverridden 返回任一被测端点是否被 --resolve 固定解析。
+func endpointResolveOverridden(input types.Input) bool {
+	dns := network.CurrentDNSConfig()
+	endpoints := input.Endpoints
+	if len(endpoints) == 0 {
+		endpoints = []string{input.ResolvedEndpointURL()}
+	}
+	for _, endpoint := range endpoints {
+		if dns.OverridesURL(endpoint) {
+			return true
+		}
+	}
+	return false
+}
+
+// evaluateSLA 按请求逐条判断各 SLA 是否达标；表达式已在创建任务时校验，解析失败的条目跳过。
+func evaluateSLA(exprs []string, results []*client.ResponseMetrics) []types.SLAResult {
+	if len(exprs) == 0 {
+		return nil
+	}
+	samples := make([]sla.Sample, 0, len(results))
+	for _, result := range results {
+		samples = append(samples, sla.Sample{
+			Success: result.ErrorMessage == "",
+			TTFT:    result.TimeToFirstToken,
+			Total:   result.TotalTime,
+		})
+	}
+	slaResults := make([]types.SLAResult, 0, len(exprs))
+	for _, expr := range exprs {
+		rule, err := sla.Parse(expr)
+		if err != nil {
+			continue
+		}
+		slaResults = append(slaResults, rule.Evaluate(samples))
+	}
+	return slaResults
+}
+
+// 疑似异常响应的样例条数与片段长度（字符）
+const (
+	suspiciousSampleLimit   = 3
+	suspiciousExcerptLength = 80
+)
+
+// checkContents 对成功响应的正文做乱码 / 复读检测，返回疑似异常数和前几条样例。
+func checkContents(results []*client.ResponseMetrics) (int, []types.SuspiciousContent) {
+	count := 0
+	var samples []types.SuspiciousContent
+	for _, result := range results {
+		reason := stats.CheckContent(result.ResponseText)
+		if reason == "" {
+			continue
+		}
+		count++
+		if len(samples) < suspiciousSampleLimit {
+			samples = append(samples, types.SuspiciousContent{Reason: reason, Excerpt: contentExcerpt(result.ResponseText)})
+		}
+	}
+	return count, samples
+}
+
+// contentExcerpt 截取正文开头片段，非法 UTF-8 替换为 U+FFFD 以便 JSON 输出；开启 --redact 时先脱敏再截取
+func contentExcerpt(text string) string {
+	runes := []rune(redact.String(strings.ToValidUTF8(text, "\uFFFD")))
+	if len(runes) > suspiciousExcerptLength {
+		return string(runes[:suspiciousExcerptLength]) + "…"
+	}
+	return string(runes)
+}
+
+// calculateEndpointStats 按端点分组统计请求数、成功率与成功请求的平均耗时/TTFT/TPS；
+// 未配置多端点时返回 nil。结果按 endpoints 配置顺序排列。
+func calculateEndpointStats(endpoints []string, results []*client.ResponseMetrics) []types.EndpointStats {
+	if len(endpoints) == 0 {
+		return nil
+	}
+
+	type accumulator struct {
+		requests, success int
+		totalTime, ttft   time.Duration
+		tps               float64
+		ips               []string
+	}
+	groups := make(map[string]*accumulator, len(endpoints))
+	for _, endpoint := range endpoints {
+		groups[endpoint] = &accumulator{}
+	}
+	for _, result := range results {
+		acc, ok := groups[result.Endpoint]
+		if !ok {
+			continue
+		}
+		acc.requests++
+		if result.TargetIP != "" && !slices.Contains(acc.ips, result.TargetIP) {
+			acc.ips = append(acc.ips, result.TargetIP)
+		}
+		if result.ErrorMessage != "" || !result.HasOutput() {
+			continue
+		}
+		acc.success++
+		acc.totalTime += result.TotalTime
+		acc.ttft += result.TimeToFirstToken
+		if result.TotalTime > 0 {
+			acc.tps += float64(result.CompletionTokens) / result.TotalTime.Seconds()
+		}
+	}
+
+	stats := make([]types.EndpointStats, 0, len(endpoints))
+	for _, endpoint := range endpoints {
+		acc := groups[endpoint]
+		item := types.EndpointStats{Endpoint: endpoint, TargetIPs: acc.ips, Requests: acc.requests}
+		if acc.requests > 0 {
+			item.SuccessRate = float64(acc.success) / float64(acc.requests) * 100
+		}
+		if acc.success > 0 {
+			item.AvgTotalTime = acc.totalTime / time.Duration(acc.success)
+			item.AvgTTFT = acc.ttft / time.Duration(acc.success)
+			item.AvgTPS = acc.tps / float64(acc.success)
+		}
+		stats = append(stats, item)
+	}
+	return stats
+}
+
+// calculateTargetIPStats 按实际连接的目标 IP 统计请求数与成功请求的平均耗时/TTFT；
+// 没有任何请求拿到 IP 时返回 nil。
+func calculateTargetIPStats(results []*client.ResponseMetrics) map[string]types.TargetIPStats {
+	type accumulator struct {
+		count, success  int
+		totalTime, ttft time.Duration
+	}
+	groups := make(map[string]*accumulator)
+	for _, result := range results {
+		if result.TargetIP == "" {
+			continue
+		}
+		acc, ok := groups[result.TargetIP]
+		if !ok {
+			acc = &accumulator{}
+			groups[result.TargetIP] = acc
+		}
+		acc.count++
+		if result.ErrorMessage != "" || !result.HasOutput() {
+			continue
+		}
+		acc.success++
+		acc.totalTime += result.TotalTime
+		acc.ttft += result.TimeToFirstToken
+	}
+	if len(groups) == 0 {
+		return nil
+	}
+
+	stats := make(map[string]types.TargetIPStats, len(groups))
+	for ip, acc := range groups {
+		item := types.TargetIPStats{Count: acc.count}
+		if acc.success > 0 {
+			item.AvgTotalTime = acc.totalTime / time.Duration(acc.success)
+			item.AvgTTFT = acc.ttft / time.Duration(acc.success)
+		}
+		stats[ip] = item
+	}
+	return stats
+}
+
+// collectResolvedIPs 汇总所有请求 DNS 解析到的 IP，去重后按字符串排序；没有请求做过 DNS 解析时返回 nil。
+func collectResolvedIPs(results []*client.ResponseMetrics) []string {
+	var ips []string
+	for _, result := range results {
+		for _, ip := range result.ResolvedIPs {
+			if !slices.Contains(ips, ip) {
+				ips = append(ips, ip)
+			}
+		}
+	}
+	slices.Sort(ips)
+	return ips
+}
+
+// calculateQueueWait 统计所有已发出请求的本地排队时间的平均值与最大值。
+func calculateQueueWait(results []*client.ResponseMetrics) (avg, maxWait time.Duration) {
+	if len(results) == 0 {
+		return 0, 0
+	}
+	var sum time.Duration
+	for _, result := range results {
+		sum += result.QueueWaitTime
+		maxWait = max(maxWait, result.QueueWaitTime)
+	}
+	return sum / time.Duration(len(results)), maxWait
+}
+
+// countAdaptedRequests 统计因参数不兼容改写请求体重试的请求数与出现过的改动说明（去重后排序）。
+func countAdaptedRequests(results []*client.ResponseMetrics) (int, []string) {
+	count := 0
+	var params []string
+	for _, result := range results {
+		if !result.AdaptedRequest {
+			continue
+		}
+		count++
+		if !slices.Contains(params, result.AdaptedParams) {
+			params = append(params, result.AdaptedParams)
+		}
+	}
+	slices.Sort(params)
+	return count, params
+}
+
+// normalizedTPS 用统一的本地估算分词器对成功响应的正文重新计 token，返回平均归一化 TPS
+// 以及本地计数与服务自报输出 token 的比值；正文不含思考内容，工具调用按 name(arguments) 计入。
+func normalizedTPS(results []*client.ResponseMetrics) (float64, float64) {
+	var sumTPS float64
+	var count, localTokens, reportedTokens int
+	for _, result := range results {
+		if result.TotalTime <= 0 {
+			continue
+		}
+		tokens := prompt.EstimateTokens(result.ResponseText)
+		sumTPS += float64(tokens) / result.TotalTime.Seconds()
+		count++
+		localTokens += tokens
+		reportedTokens += result.CompletionTokens
+	}
+	if count == 0 {
+		return 0, 0
+	}
+	var ratio float64
+	if reportedTokens > 0 {
+		ratio = float64(localTokens) / float64(reportedTokens)
+	}
+	return sumTPS / float64(count), ratio
+}
+
+// prefillTPS 返回成功请求 输入 token / TTFT 的平均值；没有 TTFT 或未上报输入 token 的请求不参与计算。
+func prefillTPS(results []*client.ResponseMetrics) float64 {
+	var sum float64
+	count := 0
+	for _, result := range results {
+		if result.TimeToFirstToken <= 0 || result.PromptTokens <= 0 {
+			continue
+		}
+		sum += float64(result.PromptTokens) / result.TimeToFirstToken.Seconds()
+		count++
+	}
+	if count == 0 {
+		return 0
+	}
+	return sum / float64(count)
+}
+
+// calculatePhaseStats 按完成时间排序成功请求，前后各取 ratio 比例作为预热段与收尾段、其余为稳态段，
+// 并比较稳态段与整体的平均 TTFT / TPS；缺少完成时间或某段不足 1 个请求时返回 nil。
+func calculatePhaseStats(results []*client.ResponseMetrics, ratio float64) *types.PhaseStats {
+	if ratio <= 0 {
+		ratio = types.DefaultPhaseRatio
+	}
+	timed := make([]*client.ResponseMetrics, 0, len(results))
+	for _, result := range results {
+		if !result.CompletedAt.IsZero() {
+			timed = append(timed, result)
+		}
+	}
+	edge := int(float64(len(timed)) * ratio)
+	if edge < 1 || len(timed)-2*edge < 1 {
+		return nil
+	}
+	slices.SortStableFunc(timed, func(a, b *client.ResponseMetrics) int {
+		return a.CompletedAt.Compare(b.CompletedAt)
+	})
+
+	overall := phaseMetrics(timed)
+	phases := &types.PhaseStats{
+		Warmup:   phaseMetrics(timed[:edge]),
+		Steady:   phaseMetrics(timed[edge : len(timed)-edge]),
+		Cooldown: phaseMetrics(timed[len(timed)-edge:]),
+	}
+	phases.SteadyDeviation = max(
+		relativeDiff(float64(phases.Steady.AvgTTFT), float64(overall.AvgTTFT)),
+		relativeDiff(phases.Steady.AvgTPS, overall.AvgTPS),
+	)
+	return phases
+}
+
+// calculateFirstRequest 取完成时间最早的成功请求作为首请求，与其余成功请求的平均值比较；
+// 缺少完成时间的请求不参与，成功请求不足 2 个时返回 nil。
+func calculateFirstRequest(results []*client.ResponseMetrics) *types.FirstRequestMetrics {
+	timed := make([]*client.ResponseMetrics, 0, len(results))
+	for _, result := range results {
+		if !result.CompletedAt.IsZero() {
+			timed = append(timed, result)
+		}
+	}
+	if len(timed) < 2 {
+		return nil
+	}
+	slices.SortStableFunc(timed, func(a, b *client.ResponseMetrics) int {
+		return a.CompletedAt.Compare(b.CompletedAt)
+	})
+
+	first, warm := timed[0], timed[1:]
+	var sumTotal time.Duration
+	for _, result := range warm {
+		sumTotal += result.TotalTime
+	}
+	warmPhase := phaseMetrics(warm)
+	metrics := &types.FirstRequestMetrics{
+		Index:            first.Index,
+		StartedAt:        first.StartedAt,
+		TTFT:             first.TimeToFirstToken,
+		TotalTime:        first.TotalTime,
+		DNSTime:          first.DNSTime,
+		ConnectTime:      first.ConnectTime,
+		TLSHandshakeTime: first.TLSHandshakeTime,
+		WarmRequests:     warmPhase.Requests,
+		WarmAvgTTFT:      warmPhase.AvgTTFT,
+		WarmAvgTotalTime: sumTotal / time.Duration(len(warm)),
+		WarmAvgTPS:       warmPhase.AvgTPS,
+	}
+	if first.TotalTime > 0 {
+		metrics.TPS = float64(first.CompletionTokens) / first.TotalTime.Seconds()
+	}
+	metrics.Deviation = max(
+		slowdown(float64(metrics.TTFT), float64(metrics.WarmAvgTTFT)),
+		slowdown(float64(metrics.TotalTime), float64(metrics.WarmAvgTotalTime)),
+	)
+	return metrics
+}
+
+// slowdown 返回 value 比 base 高出的百分比，value 不高于 base 或 base 为 0 时返回 0。
+func slowdown(value, base float64) float64 {
+	if base <= 0 || value <= base {
+		return 0
+	}
+	return (value - base) / base * 100
+}
+
+// phaseMetrics 统计一段请求的平均 TTFT 与平均 TPS。
+func phaseMetrics(results []*client.ResponseMetrics) types.PhaseMetrics {
+	var sumTTFT time.Duration
+	var sumTPS float64
+	for _, result := range results {
+		sumTTFT += result.TimeToFirstToken
+		if result.TotalTime > 0 {
+			sumTPS += float64(result.CompletionTokens) / result.TotalTime.Seconds()
+		}
+	}
+	return types.PhaseMetrics{
+		Requests: len(results),
+		AvgTTFT:  sumTTFT / time.Duration(len(results)),
+		AvgTPS:   sumTPS / float64(len(results)),
+	}
+}
+
+// relativeDiff 返回 value 相对 base 的差异百分比（绝对值），base 为 0 时返回 0。
+func relativeDiff(value, base float64) float64 {
+	if base == 0 {
+		return 0
+	}
+	return math.Abs(value-base) / base * 100
+}
+
+// averageEventTimes 对记录了 SSE 事件时序的请求，按 event 类型平均首次出现时间，
+// 并按 content block 类型平均其首次开始时间；没有请求记录时序时均返回 nil。
+func averageEventTimes(results []*client.ResponseMetrics) (map[string]time.Duration, map[string]time.Duration) {
+	eventSum, eventCount := map[string]time.Duration{}, map[string]int{}
+	blockSum, blockCount := map[string]time.Duration{}, map[string]int{}
+	for _, result := range results {
+		for event, at := range result.EventTimes {
+			eventSum[event] += at
+			eventCount[event]++
+		}
+		seen := map[string]bool{}
+		for _, block := range result.ContentBlocks {
+			if block.Type == "" || seen[block.Type] {
+				continue
+			}
+			seen[block.Type] = true
+			blockSum[block.Type] += block.Start
+			blockCount[block.Type]++
+		}
+	}
+	average := func(sum map[string]time.Duration, count map[string]int) map[string]time.Duration {
+		if len(sum) == 0 {
+			return nil
+		}
+		avg := make(map[string]time.Duration, len(sum))
+		for key, total := range sum {
+			avg[key] = total / time.Duration(count[key])
+		}
+		return avg
+	}
+	return average(eventSum, eventCount), average(blockSum, blockCount)
+}
+
+// groupErrors 按错误指纹对失败请求分组，记录次数、首次出现时间与一条样例，按次数从多到少排列。
+func groupErrors(results []*client.ResponseMetrics) []types.ErrorGroup {
+	var groups []types.ErrorGroup
+	index := map[string]int{}
+	for _, result := range results {
+		if result.ErrorMessage == "" {
+			continue
+		}
+		// 开启 --redact 时错误信息先脱敏再分组，报告中的指纹与样例都不含敏感信息
+		message := redact.String(result.ErrorMessage)
+		fp := types.ErrorFingerprint(message)
+		i, ok := index[fp]
+		if !ok {
+			i = len(groups)
+			index[fp] = i
+			groups = append(groups, types.ErrorGroup{
+				Fingerprint: fp,
+				Type:        client.ClassifyError(result.ErrorMessage).String(),
+				FirstSeen:   result.CompletedAt,
+				Sample:      message,
+			})
+		}
+		g := &groups[i]
+		g.Count++
+		if !result.CompletedAt.IsZero() && (g.FirstSeen.IsZero() || result.CompletedAt.Before(g.FirstSeen)) {
+			g.FirstSeen = result.CompletedAt
+			g.Sample = message
+		}
+	}
+	slices.SortStableFunc(groups, func(a, b types.ErrorGroup) int {
+		return b.Count - a.Count
+	})
+	return groups
+}
+
+// calculateBurstStats 按请求序号把 results 每 size 个分为一批，统计各批的请求数、成功数、完成耗时与 TTFT；
+// results 按请求序号排列，未发出的请求为 nil。批次的发出时间由调度方填写。
+func calculateBurstStats(results []*client.ResponseMetrics, size int) []types.BurstStats {
+	var bursts []types.BurstStats
+	for start := 0; start < len(results); start += size {
+		burst := types.BurstStats{Index: start / size}
+		var sumTTFT time.Duration
+		for _, result := range results[start:min(start+size, len(results))] {
+			if result == nil {
+				continue
+			}
+			burst.Requests++
+			burst.Duration = max(burst.Duration, result.TotalTime)
+			if result.ErrorMessage != "" || !result.HasOutput() {
+				continue
+			}
+			burst.Success++
+			sumTTFT += result.TimeToFirstToken
+			burst.MaxTTFT = max(burst.MaxTTFT, result.TimeToFirstToken)
+		}
+		if burst.Requests == 0 {
+			break
+		}
+		if burst.Success > 0 {
+			burst.AvgTTFT = sumTTFT / time.Duration(burst.Success)
+		}
+		bursts = append(bursts, burst)
+	}
+	return bursts
+}
+
+// checkRequestIDs 统计回传了 X-Request-Id 的请求数，以及回传值与发送值不一致的可疑请求数（含失败请求）。
+func checkRequestIDs(results []*client.ResponseMetrics) *types.RequestIDCheckStats {
+	stats := &types.RequestIDCheckStats{}
+	for _, result := range results {
+		if result.RequestIDEchoed {
+			stats.Echoed++
+		}
+		if result.RequestIDMismatch {
+			stats.Mismatched++
+		}
+	}
+	return stats
+}
+
+// countFallbacks 统计改用备用模型重试的请求数，以及其中成功（与主统计口径相同：无错误且有输出）的请求数。
+func countFallbacks(results []*client.ResponseMetrics) *types.FallbackStats {
+	stats := &types.FallbackStats{}
+	for _, result := range results {
+		if !result.FallbackUsed {
+			continue
+		}
+		stats.Triggered++
+		if result.ErrorMessage == "" && result.HasOutput() {
+			stats.Succeeded++
+		}
+	}
+	return stats
+}
+
+// countFinishReasons 统计各结束原因的请求数；没有任何请求返回结束原因时返回 nil。
+func countFinishReasons(results []*client.ResponseMetrics) map[string]int {
+	var counts map[string]int
+	for _, result := range results {
+		if result.FinishReason == "" {
+			continue
+		}
+		if counts == nil {
+			counts = make(map[string]int)
+		}
+		counts[result.FinishReason]++
+	}
+	return counts
+}
+
+// calculateTTFB 统计收到响应的请求的首字节到达时间（TTFB）；stream 为 true 时同时统计 TTFT 与 TTFB 差值的平均值，
+// 非流式的 TTFT 即总耗时，差值没有意义，返回 0。
+func calculateTTFB(results []*client.ResponseMetrics, stream bool) (avg, minTTFB, maxTTFB, avgGap time.Duration) {
+	var sum, sumGap time.Duration
+	count, gapCount := 0, 0
+	for _, result := range results {
+		if result.TimeToFirstByte <= 0 {
+			continue
+		}
+		if count == 0 || result.TimeToFirstByte < minTTFB {
+			minTTFB = result.TimeToFirstByte
+		}
+		if result.TimeToFirstByte > maxTTFB {
+			maxTTFB = result.TimeToFirstByte
+		}
+		sum += result.TimeToFirstByte
+		count++
+		if stream && result.TimeToFirstToken >= result.TimeToFirstByte {
+			sumGap += result.TimeToFirstToken - result.TimeToFirstByte
+			gapCount++
+		}
+	}
+	if count > 0 {
+		avg = sum / time.Duration(count)
+	}
+	if gapCount > 0 {
+		avgGap = sumGap / time.Duration(gapCount)
+	}
+	return avg, minTTFB, maxTTFB, avgGap
+}
+
+// calculateEncodingStats 按响应的 Content-Encoding 分组统计成功请求的平均 TTFT、解压前后的平均字节数与压缩率；
+// 没有记录编码的请求（triton-grpc 等）不参与统计，全部没有时返回 nil。
+func calculateEncodingStats(results []*client.ResponseMetrics) map[string]*types.EncodingStats {
+	type sums struct {
+		count                    int
+		ttft                     time.Duration
+		compressed, uncompressed int64
+	}
+	groups := map[string]*sums{}
+	for _, result := range results {
+		if result.Encoding == "" {
+			continue
+		}
+		g := groups[result.Encoding]
+		if g == nil {
+			g = &sums{}
+			groups[result.Encoding] = g
+		}
+		g.count++
+		g.ttft += result.TimeToFirstToken
+		g.compressed += result.CompressedBytes
+		g.uncompressed += result.UncompressedBytes
+	}
+	if len(groups) == 0 {
+		return nil
+	}
+	stats := make(map[string]*types.EncodingStats, len(groups))
+	for encoding, g := range groups {
+		s := &types.EncodingStats{
+			Count:                g.count,
+			AvgTTFT:              g.ttft / time.Duration(g.count),
+			AvgCompressedBytes:   g.compressed / int64(g.count),
+			AvgUncompressedBytes: g.uncompressed / int64(g.count),
+		}
+		if g.uncompressed > 0 {
+			s.CompressionRatio = float64(g.compressed) / float64(g.uncompressed)
+		}
+		stats[encoding] = s
+	}
+	return stats
+}
+
+// truncatedFinishReasons 输出达到 token 上限时的结束原因：OpenAI Chat Completions 的 length、
+// Anthropic 的 max_tokens 与 Responses API 的 max_output_tokens
+var truncatedFinishReasons = []string{"length", "max_tokens", "max_output_tokens"}
+
+// calculateMaxTokensUsage 统计成功请求的实际输出 token 相对 maxTokens 的分布；
+// 未设置 max_tokens 或没有成功请求时返回 nil。
+func calculateMaxTokensUsage(maxTokens int, results []*client.ResponseMetrics) *types.MaxTokensUsage {
+	if maxTokens <= 0 || len(results) == 0 {
+		return nil
+	}
+	usage := &types.MaxTokensUsage{MaxTokens: maxTokens, Requests: len(results)}
+	var ratioSum float64
+	for _, result := range results {
+		ratio := float64(result.CompletionTokens) / float64(maxTokens)
+		ratioSum += ratio
+		usage.Distribution[min(int(ratio*4), len(usage.Distribution)-1)]++
+		if result.CompletionTokens > maxTokens {
+			usage.Exceeded++
+		}
+		if result.CompletionTokens >= maxTokens || slices.Contains(truncatedFinishReasons, result.FinishReason) {
+			usage.Truncated++
+		}
+	}
+	usage.TruncatedRate = float64(usage.Truncated) / float64(usage.Requests) * 100
+	usage.AvgRatio = ratioSum / float64(usage.Requests)
+	return usage
+}
+
+// 网络探测结论的判定阈值：后半段均值超过前半段的 degradeRatio 倍视为恶化；
+// 探测延迟还须至少增加 probeDegradeMin，避免毫秒级的基线被噪声误判
+const (
+	degradeRatio    = 1.5
+	probeDegradeMin = 5 * time.Millisecond
+)
+
+// SummarizeNetworkProbes 汇总基线网络探测，并与业务请求对比给出结论：把探测与成功请求（按请求序号）各分为前后两半，
+// 请求延迟（流式取 TTFT，非流式取总耗时）恶化而探测延迟稳定时判为服务端原因，两者同步恶化时判为网络原因。
+// requestAvg 为业务请求的平均 TCP 连接 + TLS 握手耗时。没有任何探测时返回 nil。
+func SummarizeNetworkProbes(probes []types.NetworkProbe, results []*client.ResponseMetrics, stream bool, requestAvg time.Duration) *types.NetworkProbeSummary {
+	if len(probes) == 0 {
+		return nil
+	}
+	summary := &types.NetworkProbeSummary{Samples: len(probes), RequestAvg: requestAvg}
+	var latencies []time.Duration
+	for _, probe := range probes {
+		if probe.Error != "" {
+			summary.Errors++
+			continue
+		}
+		latencies = append(latencies, probe.Latency())
+	}
+	if len(latencies) == 0 {
+		return summary
+	}
+	summary.Min, summary.Max = latencies[0], latencies[0]
+	for _, l := range latencies {
+		summary.Min, summary.Max = min(summary.Min, l), max(summary.Max, l)
+	}
+	summary.Avg = averageDuration(latencies)
+
+	var requestLatencies []time.Duration
+	for _, result := range results {
+		if result == nil || result.ErrorMessage != "" || !result.HasOutput() {
+			continue
+		}
+		if stream {
+			requestLatencies = append(requestLatencies, result.TimeToFirstToken)
+		} else {
+			requestLatencies = append(requestLatencies, result.TotalTime)
+		}
+	}
+	if len(latencies) < 2 || len(requestLatencies) < 4 {
+		return summary
+	}
+	probeBefore, probeAfter := halfAverages(latencies)
+	requestBefore, requestAfter := halfAverages(requestLatencies)
+	if float64(requestAfter) <= float64(requestBefore)*degradeRatio {
+		return summary
+	}
+	if float64(probeAfter) > float64(probeBefore)*degradeRatio && probeAfter-probeBefore >= probeDegradeMin {
+		summary.Verdict = types.ProbeVerdictNetwork
+	} else {
+		summary.Verdict = types.ProbeVerdictServer
+	}
+	return summary
+}
+
+// halfAverages 返回前一半与后一半的均值；奇数个时中间一个归入后一半
+func halfAverages(values []time.Duration) (time.Duration, time.Duration) {
+	mid := len(values) / 2
+	return averageDuration(values[:mid]), averageDuration(values[mid:])
+}
+
+func averageDuration(values []time.Duration) time.Duration {
+	if len(values) == 0 {
+		return 0
+	}
+	var sum time.Duration
+	for _, v := range values {
+		sum += v
 	}
+	return sum / time.Duration(len(values))
 }
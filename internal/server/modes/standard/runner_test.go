@@ -4,13 +4,20 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math"
+	"reflect"
+	"strings"
 	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/yinxulai/ait/internal/server/client"
+	"github.com/yinxulai/ait/internal/server/clock"
 	"github.com/yinxulai/ait/internal/server/logger"
+	"github.com/yinxulai/ait/internal/server/network"
 	"github.com/yinxulai/ait/internal/server/prompt"
+	"github.com/yinxulai/ait/internal/server/redact"
+	"github.com/yinxulai/ait/internal/server/stats"
 	"github.com/yinxulai/ait/internal/server/types"
 	"github.com/yinxulai/ait/internal/server/upload"
 )
@@ -31,14 +38,19 @@ type MockClient struct {
 	failurePattern  []bool // 用于模拟间歇性失败的模式
 	protocol        string
 	model           string
+	clock           clock.Clock // requestDelay 的时间源，为空时使用真实时钟
 }
 
 func (m *MockClient) Request(ctx context.Context, systemPrompt, prompt string, stream bool) (*client.ResponseMetrics, error) {
 	callIndex := atomic.AddInt64(&m.callCount, 1) - 1
 
 	if m.requestDelay > 0 {
+		after := time.After
+		if m.clock != nil {
+			after = m.clock.After
+		}
 		select {
-		case <-time.After(m.requestDelay):
+		case <-after(m.requestDelay):
 		case <-ctx.Done():
 			return nil, ctx.Err()
 		}
@@ -112,8 +124,11 @@ func NewRunnerWithClient(input types.Input, client client.ModelClient) *Runner {
 	return &Runner{
 		input:  input,
 		client: client,
-		upload: upload.New(),
+		upload: upload.New(network.HTTPOptions{}),
 		stopCh: make(chan struct{}),
+		clock:  clock.Real(),
+
+		breaker: stats.NewErrorRateBreaker(input.AbortOnErrorRate, input.AbortMinSamples),
 	}
 }
 
@@ -420,9 +435,11 @@ func TestRunner_Run_ConcurrencyControl(t *testing.T) {
 		Stream:       true,
 	}
 
-	// 创建有延迟的mock客户端来测试并发控制
+	// 创建有延迟的mock客户端来测试并发控制，延迟基于假时钟
+	fake := clock.NewFake(time.Unix(1000, 0))
 	mockClient := &MockClient{
 		shouldError:  false,
+		clock:        fake,
 		requestDelay: 50 * time.Millisecond, // 每个请求延迟50ms
 		responseMetrics: &client.ResponseMetrics{
 			TotalTime:        100 * time.Millisecond,
@@ -436,10 +453,28 @@ func TestRunner_Run_ConcurrencyControl(t *testing.T) {
 	}
 
 	runner := NewRunnerWithClient(input, mockClient)
-
-	start := time.Now()
-	result, err := runner.Run()
-	elapsed := time.Since(start)
+	runner.SetClock(fake)
+
+	type runResult struct {
+		report *types.ReportData
+		err    error
+	}
+	done := make(chan runResult, 1)
+	go func() {
+		report, err := runner.Run()
+		done <- runResult{report, err}
+	}()
+
+	// 验证并发控制：6个请求，并发度为2，每轮恰好有2个请求在等待
+	for round := 0; round < 3; round++ {
+		fake.BlockUntil(2)
+		if n := fake.Waiters(); n != 2 {
+			t.Fatalf("round %d: %d requests in flight, want 2", round, n)
+		}
+		fake.Advance(50 * time.Millisecond)
+	}
+	res := <-done
+	result, err := res.report, res.err
 
 	if err != nil {
 		t.Errorf("Run() returned unexpected error: %v", err)
@@ -449,17 +484,9 @@ func TestRunner_Run_ConcurrencyControl(t *testing.T) {
 		t.Fatal("Run() returned nil result")
 	}
 
-	// 验证并发控制：6个请求，并发度为2，每个请求50ms
-	// 应该至少需要 (6/2) * 50ms = 150ms
-	minExpectedTime := 150 * time.Millisecond
-	if elapsed < minExpectedTime {
-		t.Errorf("Expected execution time >= %v, got %v (concurrency control may not be working)", minExpectedTime, elapsed)
-	}
-
-	// 但也不应该太长（比如不应该超过串行执行的时间）
-	maxExpectedTime := 400 * time.Millisecond // 给一些余量
-	if elapsed > maxExpectedTime {
-		t.Errorf("Execution time %v is too long, may indicate concurrency is not working", elapsed)
+	// 每轮 50ms，共 (6/2) * 50ms = 150ms
+	if result.TotalTime != 150*time.Millisecond {
+		t.Errorf("Expected TotalTime 150ms, got %v", result.TotalTime)
 	}
 
 	// 验证所有请求都被执行
@@ -561,6 +588,63 @@ func TestRunner_RunWithProgress_Success(t *testing.T) {
 	}
 }
 
+func TestRunner_RunWithProgress_AbortOnErrorRate(t *testing.T) {
+	input := types.Input{
+		Protocol:         "openai",
+		Model:            "gpt-3.5-turbo",
+		Concurrency:      2,
+		Count:            100,
+		PromptSource:     createTestPromptSource("test prompt"),
+		AbortOnErrorRate: 50,
+		AbortMinSamples:  4,
+	}
+	mockClient := &MockClient{shouldError: true, errorMsg: "HTTP 404: model not found", requestDelay: 5 * time.Millisecond}
+	runner := NewRunnerWithClient(input, mockClient)
+
+	result, err := runner.RunWithProgress(func(types.StatsData) {})
+	if err != nil {
+		t.Fatalf("RunWithProgress() error = %v", err)
+	}
+	if !result.AbortedEarly || result.Abort == nil {
+		t.Fatalf("AbortedEarly = %v, Abort = %+v, want aborted", result.AbortedEarly, result.Abort)
+	}
+	if result.Abort.Completed != 4 || result.Abort.Failed != 4 || result.Abort.ErrorRate != 100 {
+		t.Errorf("Abort = %+v, want snapshot at 4/4 failed", result.Abort)
+	}
+	if calls := atomic.LoadInt64(&mockClient.callCount); calls >= int64(input.Count) {
+		t.Errorf("client called %d times, want remaining requests not dispatched", calls)
+	}
+}
+
+func TestRunner_RunWithProgress_AbortBelowThreshold(t *testing.T) {
+	input := types.Input{
+		Protocol:         "openai",
+		Model:            "gpt-3.5-turbo",
+		Concurrency:      1,
+		Count:            8,
+		PromptSource:     createTestPromptSource("test prompt"),
+		AbortOnErrorRate: 50,
+		AbortMinSamples:  4,
+	}
+	// 失败率 3/8 始终不超过 50%
+	mockClient := &MockClient{
+		failurePattern:  []bool{true, false, false, true, false, false, true, false},
+		responseMetrics: &client.ResponseMetrics{TotalTime: 10 * time.Millisecond, CompletionTokens: 5},
+	}
+	runner := NewRunnerWithClient(input, mockClient)
+
+	result, err := runner.RunWithProgress(func(types.StatsData) {})
+	if err != nil {
+		t.Fatalf("RunWithProgress() error = %v", err)
+	}
+	if result.AbortedEarly || result.Abort != nil {
+		t.Errorf("AbortedEarly = %v, Abort = %+v, want not aborted", result.AbortedEarly, result.Abort)
+	}
+	if calls := atomic.LoadInt64(&mockClient.callCount); calls != int64(input.Count) {
+		t.Errorf("client called %d times, want all %d", calls, input.Count)
+	}
+}
+
 func TestRunner_RunWithProgress_WithFailures(t *testing.T) {
 	input := types.Input{
 		Protocol:     "openai",
@@ -652,9 +736,11 @@ func TestRunner_RunWithProgress_ProgressTiming(t *testing.T) {
 		Stream:       true,
 	}
 
+	fake := clock.NewFake(time.Unix(1000, 0))
 	mockClient := &MockClient{
 		shouldError:  false,
-		requestDelay: 100 * time.Millisecond, // 较长延迟确保多次进度更新
+		clock:        fake,
+		requestDelay: 300 * time.Millisecond, // 进度每 500ms 刷新一次，第 2 个请求期间刷新一次
 		responseMetrics: &client.ResponseMetrics{
 			TotalTime:        150 * time.Millisecond,
 			TimeToFirstToken: 40 * time.Millisecond,
@@ -667,56 +753,44 @@ func TestRunner_RunWithProgress_ProgressTiming(t *testing.T) {
 	}
 
 	runner := NewRunnerWithClient(input, mockClient)
+	runner.SetClock(fake)
 
-	var progressCallbacks []types.StatsData
-	var callbackTimes []time.Time
-	startTime := time.Now()
-
-	progressCallback := func(stats types.StatsData) {
-		progressCallbacks = append(progressCallbacks, stats)
-		callbackTimes = append(callbackTimes, time.Now())
-
-		// 验证ElapsedTime字段是合理的
-		elapsed := time.Since(startTime)
-		if stats.ElapsedTime > elapsed+100*time.Millisecond {
-			t.Errorf("Stats ElapsedTime %v is much greater than actual elapsed %v", stats.ElapsedTime, elapsed)
+	progress := make(chan types.StatsData, 4)
+	done := make(chan *types.ReportData, 1)
+	go func() {
+		result, err := runner.RunWithProgress(func(stats types.StatsData) { progress <- stats })
+		if err != nil {
+			t.Errorf("RunWithProgress() returned unexpected error: %v", err)
 		}
-	}
-
-	result, err := runner.RunWithProgress(progressCallback)
-
-	if err != nil {
-		t.Errorf("RunWithProgress() returned unexpected error: %v", err)
-	}
-
+		done <- result
+	}()
+
+	// 等待中的是进度 ticker 与当前请求
+	fake.BlockUntil(2)
+	fake.Advance(300 * time.Millisecond) // 第 1 个请求完成
+	fake.BlockUntil(2)
+	fake.Advance(200 * time.Millisecond) // 进度刷新
+	tick := <-progress
+	if tick.CompletedCount != 1 || tick.ElapsedTime != 500*time.Millisecond {
+		t.Errorf("progress at 500ms: completed=%d elapsed=%v, want 1 / 500ms", tick.CompletedCount, tick.ElapsedTime)
+	}
+	fake.Advance(100 * time.Millisecond) // 第 2 个请求完成
+	fake.BlockUntil(2)
+	fake.Advance(300 * time.Millisecond) // 第 3 个请求完成
+
+	result := <-done
 	if result == nil {
 		t.Fatal("RunWithProgress() returned nil result")
 	}
-
-	// 验证至少有一些进度回调（应该至少有最终回调）
-	if len(progressCallbacks) == 0 {
-		t.Error("Expected at least one progress callback")
+	final := <-progress
+	if final.CompletedCount != 3 || final.ElapsedTime != 900*time.Millisecond {
+		t.Errorf("final progress: completed=%d elapsed=%v, want 3 / 900ms", final.CompletedCount, final.ElapsedTime)
 	}
-
-	// 验证进度数据的递增性质
-	for i := 1; i < len(progressCallbacks); i++ {
-		curr := progressCallbacks[i]
-		prev := progressCallbacks[i-1]
-
-		// 完成数应该单调递增或保持不变
-		if curr.CompletedCount < prev.CompletedCount {
-			t.Errorf("CompletedCount decreased from %d to %d", prev.CompletedCount, curr.CompletedCount)
-		}
-
-		// 失败数应该单调递增或保持不变
-		if curr.FailedCount < prev.FailedCount {
-			t.Errorf("FailedCount decreased from %d to %d", prev.FailedCount, curr.FailedCount)
-		}
-
-		// ElapsedTime应该递增
-		if curr.ElapsedTime < prev.ElapsedTime {
-			t.Errorf("ElapsedTime decreased from %v to %v", prev.ElapsedTime, curr.ElapsedTime)
-		}
+	if len(progress) != 0 {
+		t.Errorf("unexpected extra progress callbacks: %d", len(progress))
+	}
+	if result.TotalTime != 900*time.Millisecond {
+		t.Errorf("TotalTime = %v, want 900ms", result.TotalTime)
 	}
 }
 
@@ -1560,3 +1634,792 @@ func (m *MockClientWithErrorMetrics) SetLogger(logger *logger.Logger) {
 func (m *MockClientWithErrorMetrics) RawRequest(ctx context.Context, rawBody string) (*client.ResponseMetrics, error) {
 	return m.Request(ctx, "", rawBody, false)
 }
+
+// TestRunner_CalculateResult_ThinkingTime 测试思考耗时只统计出现思考阶段的请求
+func TestRunner_CalculateResult_ThinkingTime(t *testing.T) {
+	input := types.Input{
+		Protocol:       "openai",
+		Model:          "deepseek-r1",
+		Concurrency:    1,
+		Count:          3,
+		Stream:         true,
+		ThinkingBudget: 2048,
+	}
+
+	runner := &Runner{input: input}
+	results := []*client.ResponseMetrics{
+		{TotalTime: 2 * time.Second, TimeToFirstToken: 100 * time.Millisecond, ThinkingTime: 800 * time.Millisecond, CompletionTokens: 10},
+		{TotalTime: 3 * time.Second, TimeToFirstToken: 120 * time.Millisecond, ThinkingTime: 1200 * time.Millisecond, CompletionTokens: 10},
+		{TotalTime: time.Second, TimeToFirstToken: 90 * time.Millisecond, CompletionTokens: 10}, // 未进入思考阶段
+	}
+
+	result := runner.calculateResult(results, 5*time.Second)
+
+	if !result.IsThinking {
+		t.Error("Expected IsThinking to be true when thinking budget is set")
+	}
+	if result.AvgThinkingTime != time.Second {
+		t.Errorf("Expected AvgThinkingTime 1s, got %v", result.AvgThinkingTime)
+	}
+	if result.MinThinkingTime != 800*time.Millisecond {
+		t.Errorf("Expected MinThinkingTime 800ms, got %v", result.MinThinkingTime)
+	}
+	if result.MaxThinkingTime != 1200*time.Millisecond {
+		t.Errorf("Expected MaxThinkingTime 1.2s, got %v", result.MaxThinkingTime)
+	}
+}
+
+func TestRunner_CalculateResult_FinishReasons(t *testing.T) {
+	runner := &Runner{input: types.Input{Protocol: "openai", Model: "gpt-4o", Concurrency: 1, Count: 4}}
+	results := []*client.ResponseMetrics{
+		{TotalTime: time.Second, CompletionTokens: 10, FinishReason: "stop"},
+		{TotalTime: time.Second, CompletionTokens: 10, FinishReason: "length"},
+		{TotalTime: time.Second, CompletionTokens: 10, FinishReason: "stop"},
+		{TotalTime: time.Second, CompletionTokens: 10}, // 未返回结束原因
+	}
+
+	result := runner.calculateResult(results, 4*time.Second)
+	if len(result.FinishReasons) != 2 || result.FinishReasons["stop"] != 2 || result.FinishReasons["length"] != 1 {
+		t.Errorf("FinishReasons = %v, want map[length:1 stop:2]", result.FinishReasons)
+	}
+
+	result = runner.calculateResult(results[3:], time.Second)
+	if result.FinishReasons != nil {
+		t.Errorf("FinishReasons should be nil when no request reports one, got %v", result.FinishReasons)
+	}
+}
+
+func TestRunner_CalculateResult_MaxTokensUsage(t *testing.T) {
+	runner := &Runner{input: types.Input{Protocol: "openai", Model: "gpt-4o", Concurrency: 1, Count: 5, MaxTokens: 100}}
+	results := []*client.ResponseMetrics{
+		{TotalTime: time.Second, CompletionTokens: 10, FinishReason: "stop"},
+		{TotalTime: time.Second, CompletionTokens: 60, FinishReason: "stop"},
+		{TotalTime: time.Second, CompletionTokens: 98, FinishReason: "length"}, // 按结束原因判定截断
+		{TotalTime: time.Second, CompletionTokens: 100},                        // 未返回结束原因，按输出达到上限判定
+		{TotalTime: time.Second, CompletionTokens: 150, FinishReason: "stop"},  // 服务忽略了上限
+	}
+
+	usage := runner.calculateResult(results, 5*time.Second).MaxTokensUsage
+	if usage == nil {
+		t.Fatal("MaxTokensUsage should be set when max_tokens is configured")
+	}
+	if usage.MaxTokens != 100 || usage.Requests != 5 || usage.Truncated != 3 || usage.Exceeded != 1 {
+		t.Errorf("MaxTokensUsage = %+v, want 5 requests, 3 truncated, 1 exceeded", usage)
+	}
+	if usage.TruncatedRate != 60 || usage.Distribution != [5]int{1, 0, 1, 1, 2} {
+		t.Errorf("TruncatedRate/Distribution = %v/%v, want 60/[1 0 1 1 2]", usage.TruncatedRate, usage.Distribution)
+	}
+	if math.Abs(usage.AvgRatio-0.836) > 1e-9 {
+		t.Errorf("AvgRatio = %v, want 0.836", usage.AvgRatio)
+	}
+
+	runner.input.MaxTokens = 0
+	if usage := runner.calculateResult(results, 5*time.Second).MaxTokensUsage; usage != nil {
+		t.Errorf("MaxTokensUsage should be nil without max_tokens, got %+v", usage)
+	}
+}
+
+func TestRunner_CalculateResult_SteadyTPS(t *testing.T) {
+	runner := &Runner{input: types.Input{Protocol: "openai", Model: "o1", Concurrency: 1, Count: 3, Stream: true}}
+	results := []*client.ResponseMetrics{
+		// 长 TTFT：普通 TPS 20，稳态 TPS 100
+		{TotalTime: 5 * time.Second, TimeToFirstToken: 4 * time.Second, CompletionTokens: 100},
+		// 短 TTFT：普通 TPS 40，稳态 TPS 50
+		{TotalTime: 2 * time.Second, TimeToFirstToken: 400 * time.Millisecond, CompletionTokens: 80},
+		{TotalTime: 3 * time.Second, TimeToFirstToken: time.Second, CompletionTokens: 100},
+	}
+
+	result := runner.calculateResult(results, 10*time.Second)
+	if math.Abs(result.MaxSteadyTPS-100) > 1e-9 || math.Abs(result.MinSteadyTPS-50) > 1e-9 {
+		t.Errorf("steady TPS min/max = %.2f/%.2f, want 50/100", result.MinSteadyTPS, result.MaxSteadyTPS)
+	}
+	if math.Abs(result.AvgSteadyTPS-(100+50+50)/3.0) > 1e-9 {
+		t.Errorf("AvgSteadyTPS = %.4f, want %.4f", result.AvgSteadyTPS, (100+50+50)/3.0)
+	}
+	if result.AvgSteadyTPS <= result.AvgTPS {
+		t.Errorf("steady TPS %.2f should exceed plain TPS %.2f when TTFT is excluded", result.AvgSteadyTPS, result.AvgTPS)
+	}
+
+	// 与 TPOT 交叉校验：单个请求 steady = tokens / ((tokens-1) * TPOT)
+	single := runner.calculateResult(results[:1], 5*time.Second)
+	want := float64(100) / (float64(99) * single.AvgTPOT.Seconds())
+	if math.Abs(single.AvgSteadyTPS-want) > 1e-6 {
+		t.Errorf("steady TPS %.6f does not match TPOT-derived %.6f", single.AvgSteadyTPS, want)
+	}
+}
+
+func TestRunner_CalculateResult_SteadyTPSNonStream(t *testing.T) {
+	runner := &Runner{input: types.Input{Protocol: "openai", Model: "gpt-4o", Concurrency: 1, Count: 1}}
+	results := []*client.ResponseMetrics{{TotalTime: time.Second, TimeToFirstToken: time.Second, CompletionTokens: 50}}
+
+	result := runner.calculateResult(results, time.Second)
+	if result.AvgSteadyTPS != 0 || result.MinSteadyTPS != 0 || result.MaxSteadyTPS != 0 {
+		t.Errorf("non-stream steady TPS should be 0, got %.2f/%.2f/%.2f", result.AvgSteadyTPS, result.MinSteadyTPS, result.MaxSteadyTPS)
+	}
+}
+
+func TestRunner_CalculateResult_CachedTokenRatio(t *testing.T) {
+	runner := &Runner{input: types.Input{Protocol: "anthropic", Model: "claude", Concurrency: 1, Count: 3}}
+	results := []*client.ResponseMetrics{
+		// 首个请求写入缓存，后续请求命中
+		{TotalTime: time.Second, CompletionTokens: 10, PromptTokens: 1000, CacheCreationInputTokens: 900},
+		{TotalTime: time.Second, CompletionTokens: 10, PromptTokens: 1000, CachedInputTokens: 900},
+		{TotalTime: time.Second, CompletionTokens: 10, PromptTokens: 200},
+		{ErrorMessage: "boom", PromptTokens: 1000, CachedInputTokens: 1000},
+	}
+
+	result := runner.calculateResult(results, 3*time.Second)
+	if result.TotalCachedInputTokens != 900 || result.TotalCacheCreationInputTokens != 900 {
+		t.Errorf("cached/creation tokens = %d/%d, want 900/900", result.TotalCachedInputTokens, result.TotalCacheCreationInputTokens)
+	}
+	// 按 token 加权：900 / 2200；逐请求平均为 (0 + 0.9 + 0) / 3
+	if math.Abs(result.CachedTokenRatio-900.0/2200.0) > 1e-9 {
+		t.Errorf("CachedTokenRatio = %.4f, want %.4f", result.CachedTokenRatio, 900.0/2200.0)
+	}
+	if math.Abs(result.AvgCacheHitRate-0.3) > 1e-9 {
+		t.Errorf("AvgCacheHitRate = %.4f, want 0.3", result.AvgCacheHitRate)
+	}
+}
+
+func TestRunner_CalculateResult_ToolCalls(t *testing.T) {
+	runner := &Runner{input: types.Input{Protocol: "openai", Model: "gpt-4o", Concurrency: 1, Count: 3, ToolsFile: "tools.json"}}
+	results := []*client.ResponseMetrics{
+		// 纯工具调用响应：没有正文，服务也未返回 usage
+		{TotalTime: time.Second, ToolCallCount: 2},
+		{TotalTime: time.Second, CompletionTokens: 20, ToolCallCount: 1},
+		{TotalTime: time.Second, CompletionTokens: 30},
+	}
+
+	result := runner.calculateResult(results, 3*time.Second)
+	if result.SuccessRate != 100 {
+		t.Errorf("SuccessRate = %.2f, tool-call-only response should count as success", result.SuccessRate)
+	}
+	if math.Abs(result.AvgToolCallCount-1) > 1e-9 {
+		t.Errorf("AvgToolCallCount = %.4f, want 1", result.AvgToolCallCount)
+	}
+
+	runner.input.ToolsFile = ""
+	if result := runner.calculateResult(results, 3*time.Second); result.AvgToolCallCount != 0 {
+		t.Errorf("AvgToolCallCount should be 0 without tools_file, got %.4f", result.AvgToolCallCount)
+	}
+}
+
+func TestRunner_CalculateResult_SLA(t *testing.T) {
+	runner := &Runner{input: types.Input{Protocol: "openai", Model: "gpt-4o", Concurrency: 1, Count: 4, Stream: true,
+		SLA: []string{"ttft<800ms,total<10s,p=75", "ttft<800ms,p=50"}}}
+	results := []*client.ResponseMetrics{
+		{TotalTime: 2 * time.Second, TimeToFirstToken: 300 * time.Millisecond, CompletionTokens: 10},
+		{TotalTime: 12 * time.Second, TimeToFirstToken: 500 * time.Millisecond, CompletionTokens: 10},
+		{TotalTime: 3 * time.Second, TimeToFirstToken: 900 * time.Millisecond, CompletionTokens: 10},
+		{TotalTime: time.Second, TimeToFirstToken: 100 * time.Millisecond, ErrorMessage: "boom"},
+	}
+
+	result := runner.calculateResult(results, 10*time.Second)
+	if len(result.SLAResults) != 2 {
+		t.Fatalf("SLAResults = %+v, want 2 entries", result.SLAResults)
+	}
+	if r := result.SLAResults[0]; r.Compliant != 1 || r.Total != 4 || r.Rate != 25 || r.Met || r.Target != 75 {
+		t.Errorf("SLA[0] = %+v, want 1/4 not met", r)
+	}
+	if r := result.SLAResults[1]; r.Compliant != 2 || !r.Met {
+		t.Errorf("SLA[1] = %+v, want 2/4 met", r)
+	}
+
+	runner.input.SLA = nil
+	if result := runner.calculateResult(results, 10*time.Second); result.SLAResults != nil {
+		t.Errorf("SLAResults should be nil without sla, got %+v", result.SLAResults)
+	}
+}
+
+func TestRunner_CalculateResult_InputTokenHistogram(t *testing.T) {
+	runner := &Runner{input: types.Input{Protocol: "openai", Model: "gpt-4o", Concurrency: 1, Count: 4, PromptLengthDist: "100:0.8,4000:0.2"}}
+	results := []*client.ResponseMetrics{
+		{TotalTime: time.Second, CompletionTokens: 10, PromptTokens: 80},
+		{TotalTime: time.Second, CompletionTokens: 10, PromptTokens: 90},
+		{TotalTime: time.Second, CompletionTokens: 10, PromptTokens: 3000},
+		{ErrorMessage: "boom", PromptTokens: 3000},
+	}
+
+	result := runner.calculateResult(results, 3*time.Second)
+	want := []types.TokenBucket{{Min: 0, Max: 128, Count: 2}, {Min: 2048, Max: 4096, Count: 1}}
+	if !reflect.DeepEqual(result.InputTokenHistogram, want) {
+		t.Errorf("InputTokenHistogram = %+v, want %+v", result.InputTokenHistogram, want)
+	}
+
+	runner.input.PromptLengthDist = ""
+	if result := runner.calculateResult(results, 3*time.Second); result.InputTokenHistogram != nil {
+		t.Errorf("InputTokenHistogram should be nil without prompt_length_dist, got %+v", result.InputTokenHistogram)
+	}
+}
+
+func TestCalculateEndpointStats(t *testing.T) {
+	if got := calculateEndpointStats(nil, []*client.ResponseMetrics{{Endpoint: "a"}}); got != nil {
+		t.Errorf("without endpoints should return nil, got %+v", got)
+	}
+
+	results := []*client.ResponseMetrics{
+		{Endpoint: "https://a", TargetIP: "10.0.0.1", TotalTime: time.Second, TimeToFirstToken: 100 * time.Millisecond, CompletionTokens: 100},
+		{Endpoint: "https://a", TargetIP: "10.0.0.2", TotalTime: 3 * time.Second, TimeToFirstToken: 300 * time.Millisecond, CompletionTokens: 300},
+		{Endpoint: "https://b", TargetIP: "10.0.1.1", ErrorMessage: "timeout"},
+		{Endpoint: "https://b", TargetIP: "10.0.1.1", TotalTime: 2 * time.Second, CompletionTokens: 100},
+	}
+	got := calculateEndpointStats([]string{"https://b", "https://a", "https://c"}, results)
+	want := []types.EndpointStats{
+		{Endpoint: "https://b", TargetIPs: []string{"10.0.1.1"}, Requests: 2, SuccessRate: 50, AvgTotalTime: 2 * time.Second, AvgTPS: 50},
+		{Endpoint: "https://a", TargetIPs: []string{"10.0.0.1", "10.0.0.2"}, Requests: 2, SuccessRate: 100, AvgTotalTime: 2 * time.Second, AvgTTFT: 200 * time.Millisecond, AvgTPS: 100},
+		{Endpoint: "https://c"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("calculateEndpointStats =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestCalculateTargetIPStats(t *testing.T) {
+	if got := calculateTargetIPStats([]*client.ResponseMetrics{{TotalTime: time.Second, CompletionTokens: 10}}); got != nil {
+		t.Errorf("without target ip should return nil, got %+v", got)
+	}
+
+	results := []*client.ResponseMetrics{
+		{TargetIP: "10.0.0.1", TotalTime: time.Second, TimeToFirstToken: 100 * time.Millisecond, CompletionTokens: 100},
+		{TargetIP: "10.0.0.1", TotalTime: 3 * time.Second, TimeToFirstToken: 300 * time.Millisecond, CompletionTokens: 100},
+		{TargetIP: "10.0.0.2", ErrorMessage: "timeout"},
+		{TargetIP: "10.0.0.2", TotalTime: 8 * time.Second, TimeToFirstToken: 2 * time.Second, CompletionTokens: 100},
+		{TotalTime: time.Second, CompletionTokens: 100},
+	}
+	got := calculateTargetIPStats(results)
+	want := map[string]types.TargetIPStats{
+		"10.0.0.1": {Count: 2, AvgTotalTime: 2 * time.Second, AvgTTFT: 200 * time.Millisecond},
+		"10.0.0.2": {Count: 2, AvgTotalTime: 8 * time.Second, AvgTTFT: 2 * time.Second},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("calculateTargetIPStats =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestCollectResolvedIPs(t *testing.T) {
+	if got := collectResolvedIPs([]*client.ResponseMetrics{{TargetIP: "10.0.0.1"}}); got != nil {
+		t.Errorf("without dns lookups should return nil, got %q", got)
+	}
+	got := collectResolvedIPs([]*client.ResponseMetrics{
+		{ResolvedIPs: []string{"10.0.0.2", "10.0.0.1"}},
+		{},
+		{ResolvedIPs: []string{"10.0.0.3", "10.0.0.2"}},
+	})
+	if want := []string{"10.0.0.1", "10.0.0.2", "10.0.0.3"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("collectResolvedIPs = %q, want %q", got, want)
+	}
+}
+
+func TestRunner_CalculateResult_QueueWait(t *testing.T) {
+	runner := &Runner{input: types.Input{Protocol: "openai", Model: "m", Concurrency: 4, Count: 3}}
+	results := []*client.ResponseMetrics{
+		{TotalTime: time.Second, CompletionTokens: 10, QueueWaitTime: 100 * time.Millisecond},
+		{TotalTime: time.Second, CompletionTokens: 10, QueueWaitTime: 500 * time.Millisecond},
+		{ErrorMessage: "timeout", QueueWaitTime: 300 * time.Millisecond},
+	}
+	data := runner.calculateResult(results, time.Second)
+	if data.AvgQueueWaitTime != 300*time.Millisecond || data.MaxQueueWaitTime != 500*time.Millisecond {
+		t.Errorf("queue wait avg/max = %v/%v, want 300ms/500ms", data.AvgQueueWaitTime, data.MaxQueueWaitTime)
+	}
+	if !data.QueueWaitHigh() {
+		t.Error("QueueWaitHigh() = false, want true for 30% of total time")
+	}
+
+	for _, result := range results {
+		result.QueueWaitTime /= 10
+	}
+	if data := runner.calculateResult(results, time.Second); data.QueueWaitHigh() {
+		t.Errorf("QueueWaitHigh() = true for avg %v", data.AvgQueueWaitTime)
+	}
+}
+
+func TestRunner_CalculateResult_AdaptedRequests(t *testing.T) {
+	runner := &Runner{input: types.Input{Protocol: "openai", Model: "o1", Concurrency: 1, Count: 3}}
+	results := []*client.ResponseMetrics{
+		{TotalTime: time.Second, CompletionTokens: 10, AdaptedRequest: true, AdaptedParams: "max_tokens→max_completion_tokens"},
+		{TotalTime: time.Second, CompletionTokens: 10, AdaptedRequest: true, AdaptedParams: "max_tokens→max_completion_tokens"},
+		{ErrorMessage: "HTTP 400", StatusCode: 400, AdaptedRequest: true, AdaptedParams: "-temperature"},
+	}
+	data := runner.calculateResult(results, time.Second)
+	want := []string{"-temperature", "max_tokens→max_completion_tokens"}
+	if data.AdaptedRequests != 3 || !reflect.DeepEqual(data.AdaptedParams, want) {
+		t.Errorf("AdaptedRequests = %d, AdaptedParams = %q, want 3, %q", data.AdaptedRequests, data.AdaptedParams, want)
+	}
+}
+
+func TestRunner_CalculateResult_NormalizeTokens(t *testing.T) {
+	// 同样的正文，一个服务按字符计 token、另一个按 BPE 计，本地重新计数后 TPS 一致
+	text := strings.Repeat("abcd", 50) // 本地计为 50 个 token
+	results := []*client.ResponseMetrics{
+		{TotalTime: time.Second, CompletionTokens: 200, ResponseText: text},
+		{TotalTime: 2 * time.Second, CompletionTokens: 50, ResponseText: text + text},
+		{TotalTime: time.Second, ErrorMessage: "timeout", ResponseText: text},
+	}
+
+	off := (&Runner{input: types.Input{Protocol: "openai", Model: "m", Concurrency: 1, Count: 3}}).calculateResult(results, time.Second)
+	if off.AvgNormalizedTPS != 0 || off.NormalizedTokenRatio != 0 {
+		t.Errorf("normalize_tokens is off by default, got %v %v", off.AvgNormalizedTPS, off.NormalizedTokenRatio)
+	}
+
+	on := (&Runner{input: types.Input{Protocol: "openai", Model: "m", Concurrency: 1, Count: 3, NormalizeTokens: true}}).calculateResult(results, time.Second)
+	if on.AvgNormalizedTPS != 50 {
+		t.Errorf("AvgNormalizedTPS = %v, want 50 (failed requests are skipped)", on.AvgNormalizedTPS)
+	}
+	if on.NormalizedTokenRatio != 150.0/250.0 {
+		t.Errorf("NormalizedTokenRatio = %v, want %v", on.NormalizedTokenRatio, 150.0/250.0)
+	}
+}
+
+func TestRunner_CalculateResult_RequestIDCheck(t *testing.T) {
+	results := []*client.ResponseMetrics{
+		{TotalTime: time.Second, CompletionTokens: 10, RequestIDEchoed: true},
+		{TotalTime: time.Second, CompletionTokens: 10, RequestIDEchoed: true, RequestIDMismatch: true},
+		{TotalTime: time.Second, CompletionTokens: 10},
+		{TotalTime: time.Second, ErrorMessage: "bad gateway", RequestIDEchoed: true, RequestIDMismatch: true},
+	}
+
+	off := (&Runner{input: types.Input{Protocol: "openai", Model: "m", Concurrency: 1, Count: 4}}).calculateResult(results, time.Second)
+	if off.RequestIDCheck != nil {
+		t.Errorf("request id check is off by default, got %+v", off.RequestIDCheck)
+	}
+
+	on := (&Runner{input: types.Input{Protocol: "openai", Model: "m", Concurrency: 1, Count: 4, VerifyRequestID: true}}).calculateResult(results, time.Second)
+	want := &types.RequestIDCheckStats{Echoed: 3, Mismatched: 2}
+	if !reflect.DeepEqual(on.RequestIDCheck, want) {
+		t.Errorf("RequestIDCheck = %+v, want %+v (failed requests are counted)", on.RequestIDCheck, want)
+	}
+}
+
+func TestRunner_CalculateResult_ContentCheck(t *testing.T) {
+	looping := strings.Repeat("我是一个语言模型。", 20)
+	results := []*client.ResponseMetrics{
+		{TotalTime: time.Second, CompletionTokens: 10, ResponseText: "你好，我是一个语言模型。"},
+		{TotalTime: time.Second, CompletionTokens: 180, ResponseText: looping},
+		{TotalTime: time.Second, CompletionTokens: 10, ResponseText: "bad \xff bytes"},
+		{TotalTime: time.Second, ErrorMessage: "timeout", ResponseText: looping},
+	}
+
+	off := (&Runner{input: types.Input{Protocol: "openai", Model: "m", Concurrency: 1, Count: 4}}).calculateResult(results, time.Second)
+	if off.SuspiciousContentCount != 0 || off.SuspiciousContentSamples != nil {
+		t.Errorf("content check is off by default, got %d %+v", off.SuspiciousContentCount, off.SuspiciousContentSamples)
+	}
+
+	on := (&Runner{input: types.Input{Protocol: "openai", Model: "m", Concurrency: 1, Count: 4, ContentCheck: true}}).calculateResult(results, time.Second)
+	if on.SuspiciousContentCount != 2 {
+		t.Fatalf("SuspiciousContentCount = %d, want 2 (failed requests are skipped)", on.SuspiciousContentCount)
+	}
+	samples := on.SuspiciousContentSamples
+	if len(samples) != 2 || samples[0].Reason != stats.ContentRepetition || samples[1].Reason != stats.ContentInvalidUTF8 {
+		t.Fatalf("samples = %+v", samples)
+	}
+	if !strings.HasSuffix(samples[0].Excerpt, "…") || samples[1].Excerpt != "bad � bytes" {
+		t.Errorf("excerpts = %q, %q", samples[0].Excerpt, samples[1].Excerpt)
+	}
+}
+
+func TestRunner_CalculateResult_SchemaCheck(t *testing.T) {
+	schema, err := stats.ParseJSONSchema([]byte(`{"type":"object","required":["answer"],"properties":{"answer":{"type":"string"}}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	results := []*client.ResponseMetrics{
+		{TotalTime: time.Second, CompletionTokens: 10, ResponseText: `{"answer":"42"}`},
+		{TotalTime: time.Second, CompletionTokens: 10, ResponseText: " {\"answer\": \"yes\"}\n"},
+		{TotalTime: time.Second, CompletionTokens: 10, ResponseText: `{"answer":42}`},
+		{TotalTime: time.Second, CompletionTokens: 10, ResponseText: "答案是 42"},
+		{TotalTime: time.Second, ErrorMessage: "timeout"},
+	}
+	input := types.Input{Protocol: "openai", Model: "m", Concurrency: 1, Count: 5}
+
+	if off := (&Runner{input: input}).calculateResult(results, time.Second); off.SchemaCheck != nil {
+		t.Errorf("schema check is off by default, got %+v", off.SchemaCheck)
+	}
+
+	got := (&Runner{input: input, schema: schema}).calculateResult(results, time.Second).SchemaCheck
+	if got == nil {
+		t.Fatal("SchemaCheck = nil")
+	}
+	if got.Checked != 4 || got.ValidJSON != 3 || got.Compliant != 2 || got.ValidJSONRate != 75 || got.ComplianceRate != 50 {
+		t.Errorf("SchemaCheck = %+v, want 4 checked (failed requests are skipped), 3 valid JSON, 2 compliant", got)
+	}
+	if len(got.Samples) != 2 || got.Samples[0].Reason != stats.SchemaViolation || got.Samples[1].Reason != stats.SchemaInvalidJSON {
+		t.Fatalf("samples = %+v", got.Samples)
+	}
+	if got.Samples[0].Excerpt != `{"answer":42}` || got.Samples[0].Detail == "" {
+		t.Errorf("sample = %+v", got.Samples[0])
+	}
+}
+
+func TestRunner_CalculateResult_Bursts(t *testing.T) {
+	results := []*client.ResponseMetrics{
+		{TotalTime: 2 * time.Second, TimeToFirstToken: 100 * time.Millisecond, CompletionTokens: 10},
+		{TotalTime: 3 * time.Second, TimeToFirstToken: 300 * time.Millisecond, CompletionTokens: 10},
+		{TotalTime: time.Second, ErrorMessage: "timeout"},
+		{TotalTime: time.Second, TimeToFirstToken: 200 * time.Millisecond, CompletionTokens: 10},
+		{TotalTime: time.Second, TimeToFirstToken: 50 * time.Millisecond, CompletionTokens: 10},
+		nil, // 预算耗尽后未发出
+	}
+
+	off := (&Runner{input: types.Input{Protocol: "openai", Model: "m", Concurrency: 1, Count: 6}}).calculateResult(results, time.Second)
+	if off.Bursts != nil {
+		t.Errorf("bursts are only reported in burst mode, got %+v", off.Bursts)
+	}
+
+	on := (&Runner{input: types.Input{Protocol: "openai", Model: "m", Concurrency: 1, Count: 6, BurstSize: 2, BurstInterval: time.Second}}).calculateResult(results, time.Second)
+	want := []types.BurstStats{
+		{Index: 0, Requests: 2, Success: 2, Duration: 3 * time.Second, AvgTTFT: 200 * time.Millisecond, MaxTTFT: 300 * time.Millisecond},
+		{Index: 1, Requests: 2, Success: 1, Duration: time.Second, AvgTTFT: 200 * time.Millisecond, MaxTTFT: 200 * time.Millisecond},
+		{Index: 2, Requests: 1, Success: 1, Duration: time.Second, AvgTTFT: 50 * time.Millisecond, MaxTTFT: 50 * time.Millisecond},
+	}
+	if !reflect.DeepEqual(on.Bursts, want) {
+		t.Errorf("Bursts = %+v, want %+v", on.Bursts, want)
+	}
+}
+
+func TestRunner_CalculateResult_Fallback(t *testing.T) {
+	results := []*client.ResponseMetrics{
+		{TotalTime: time.Second, CompletionTokens: 10},
+		{TotalTime: 2 * time.Second, CompletionTokens: 10, FallbackUsed: true},
+		{TotalTime: time.Second, ErrorMessage: "HTTP 503", FallbackUsed: true},
+	}
+
+	off := (&Runner{input: types.Input{Protocol: "openai", Model: "m", Concurrency: 1, Count: 3}}).calculateResult(results, time.Second)
+	if off.Fallback != nil {
+		t.Errorf("fallback stats are only reported with fallback_model, got %+v", off.Fallback)
+	}
+
+	on := (&Runner{input: types.Input{Protocol: "openai", Model: "m", Concurrency: 1, Count: 3, FallbackModel: "m-mini"}}).calculateResult(results, time.Second)
+	if on.Fallback == nil || *on.Fallback != (types.FallbackStats{Triggered: 2, Succeeded: 1}) {
+		t.Errorf("Fallback = %+v, want triggered 2, succeeded 1", on.Fallback)
+	}
+	if on.SuccessRate < 66 || on.SuccessRate > 67 {
+		t.Errorf("SuccessRate = %v, want 2/3 counting the fallback success", on.SuccessRate)
+	}
+}
+
+func TestRunner_CalculateResult_InputLengthSweep(t *testing.T) {
+	results := []*client.ResponseMetrics{
+		{TotalTime: time.Second, TimeToFirstToken: 500 * time.Millisecond, PromptTokens: 1000, CompletionTokens: 10},
+		{TotalTime: time.Second, TimeToFirstToken: 250 * time.Millisecond, PromptTokens: 1000, CompletionTokens: 10},
+		{TotalTime: time.Second, ErrorMessage: "HTTP 500"},
+	}
+
+	off := (&Runner{input: types.Input{Protocol: "openai", Model: "m", Concurrency: 1, Count: 3, Stream: true, PromptLength: 1024}}).calculateResult(results, time.Second)
+	if off.InputLength != 0 || off.AvgPrefillTPS != 0 {
+		t.Errorf("prefill stats are only reported for input_length_sweep, got %d / %v", off.InputLength, off.AvgPrefillTPS)
+	}
+
+	input := types.Input{Protocol: "openai", Model: "m", Concurrency: 1, Count: 3, Stream: true, PromptLength: 1024, InputLengthSweep: []int{1024, 4096}}
+	on := (&Runner{input: input}).calculateResult(results, time.Second)
+	if on.InputLength != 1024 {
+		t.Errorf("InputLength = %d, want 1024", on.InputLength)
+	}
+	// (1000/0.5 + 1000/0.25) / 2
+	if on.AvgPrefillTPS != 3000 {
+		t.Errorf("AvgPrefillTPS = %v, want 3000", on.AvgPrefillTPS)
+	}
+}
+
+func TestCalculatePhaseStats(t *testing.T) {
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	var results []*client.ResponseMetrics
+	for i := 0; i < 10; i++ {
+		ttft := 100 * time.Millisecond
+		if i == 0 {
+			ttft = time.Second // 预热段的冷启动请求
+		}
+		// 乱序写入，分段按完成时间而不是请求序号
+		results = append([]*client.ResponseMetrics{{
+			TimeToFirstToken: ttft,
+			TotalTime:        time.Second,
+			CompletionTokens: 50,
+			CompletedAt:      base.Add(time.Duration(i) * time.Second),
+		}}, results...)
+	}
+
+	phases := calculatePhaseStats(results, 0)
+	if phases == nil {
+		t.Fatal("calculatePhaseStats returned nil")
+	}
+	if phases.Warmup.Requests != 1 || phases.Steady.Requests != 8 || phases.Cooldown.Requests != 1 {
+		t.Errorf("phase sizes = %d/%d/%d, want 1/8/1", phases.Warmup.Requests, phases.Steady.Requests, phases.Cooldown.Requests)
+	}
+	if phases.Warmup.AvgTTFT != time.Second || phases.Steady.AvgTTFT != 100*time.Millisecond {
+		t.Errorf("warmup/steady TTFT = %v/%v", phases.Warmup.AvgTTFT, phases.Steady.AvgTTFT)
+	}
+	// 整体 TTFT 190ms，稳态段 100ms，相差约 47%
+	if !phases.Skewed() {
+		t.Errorf("SteadyDeviation = %.1f, want skewed", phases.SteadyDeviation)
+	}
+
+	if got := calculatePhaseStats(results[:5], 0.1); got != nil {
+		t.Errorf("too few requests should not be split, got %+v", got)
+	}
+	results[0].CompletedAt = time.Time{}
+	if got := calculatePhaseStats(results, 0.3); got == nil || got.Warmup.Requests != 2 {
+		t.Errorf("requests without CompletedAt should be skipped, got %+v", got)
+	}
+}
+
+func TestCalculateFirstRequest(t *testing.T) {
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	results := []*client.ResponseMetrics{
+		{Index: 1, TimeToFirstToken: 100 * time.Millisecond, TotalTime: time.Second, CompletionTokens: 50, CompletedAt: base.Add(2 * time.Second)},
+		{Index: 0, TimeToFirstToken: 400 * time.Millisecond, TotalTime: 2 * time.Second, CompletionTokens: 50, CompletedAt: base.Add(time.Second), StartedAt: base},
+		{Index: 2, TimeToFirstToken: 100 * time.Millisecond, TotalTime: time.Second, CompletionTokens: 50, CompletedAt: base.Add(3 * time.Second)},
+	}
+
+	first := calculateFirstRequest(results)
+	if first == nil {
+		t.Fatal("calculateFirstRequest returned nil")
+	}
+	if first.Index != 0 || !first.StartedAt.Equal(base) || first.TTFT != 400*time.Millisecond || first.TPS != 25 {
+		t.Errorf("first request = %+v", first)
+	}
+	if first.WarmRequests != 2 || first.WarmAvgTTFT != 100*time.Millisecond || first.WarmAvgTotalTime != time.Second || first.WarmAvgTPS != 50 {
+		t.Errorf("warm = %d/%v/%v/%v", first.WarmRequests, first.WarmAvgTTFT, first.WarmAvgTotalTime, first.WarmAvgTPS)
+	}
+	// TTFT 慢 300%，总耗时慢 100%
+	if first.Deviation != 300 || !first.ColdStart() {
+		t.Errorf("Deviation = %.1f, want 300 and cold start", first.Deviation)
+	}
+
+	// 首请求不慢于其余请求时不提示冷启动
+	results[1].TimeToFirstToken, results[1].TotalTime = 50*time.Millisecond, time.Second
+	if first := calculateFirstRequest(results); first.Deviation != 0 || first.ColdStart() {
+		t.Errorf("Deviation = %.1f, want 0", first.Deviation)
+	}
+
+	if got := calculateFirstRequest(results[:1]); got != nil {
+		t.Errorf("a single request should not be split, got %+v", got)
+	}
+}
+
+func TestSummarizeNetworkProbes(t *testing.T) {
+	probes := func(latencies ...time.Duration) []types.NetworkProbe {
+		var out []types.NetworkProbe
+		for i, l := range latencies {
+			out = append(out, types.NetworkProbe{Offset: time.Duration(i) * time.Second, Connect: l})
+		}
+		return out
+	}
+	results := func(ttfts ...time.Duration) []*client.ResponseMetrics {
+		var out []*client.ResponseMetrics
+		for _, ttft := range ttfts {
+			out = append(out, &client.ResponseMetrics{TimeToFirstToken: ttft, TotalTime: 2 * ttft, CompletionTokens: 10})
+		}
+		return out
+	}
+	ms := time.Millisecond
+	degrading := results(100*ms, 100*ms, 300*ms, 300*ms)
+
+	if got := SummarizeNetworkProbes(nil, degrading, true, 0); got != nil {
+		t.Errorf("no probes should give nil, got %+v", got)
+	}
+
+	withError := append(probes(10*ms, 30*ms), types.NetworkProbe{Error: "refused"})
+	summary := SummarizeNetworkProbes(withError, results(100*ms, 100*ms, 100*ms, 100*ms), true, 15*ms)
+	want := types.NetworkProbeSummary{Samples: 3, Errors: 1, Min: 10 * ms, Avg: 20 * ms, Max: 30 * ms, RequestAvg: 15 * ms}
+	if summary == nil || *summary != want {
+		t.Errorf("summary = %+v, want %+v", summary, want)
+	}
+
+	tests := []struct {
+		name    string
+		probes  []types.NetworkProbe
+		results []*client.ResponseMetrics
+		stream  bool
+		want    string
+	}{
+		{"stable probes, TTFT degraded", probes(20*ms, 21*ms, 20*ms, 22*ms), degrading, true, types.ProbeVerdictServer},
+		{"probes degraded with TTFT", probes(20*ms, 20*ms, 80*ms, 90*ms), degrading, true, types.ProbeVerdictNetwork},
+		{"tiny probe increase is noise", probes(1*ms, 1*ms, 3*ms, 3*ms), degrading, true, types.ProbeVerdictServer},
+		{"TTFT stable", probes(20*ms, 80*ms), results(100*ms, 100*ms, 110*ms, 100*ms), true, ""},
+		{"non-stream uses total time", probes(20*ms, 20*ms), degrading, false, types.ProbeVerdictServer},
+		{"too few requests", probes(20*ms, 20*ms), results(100*ms, 300*ms), true, ""},
+		{"single probe", probes(20 * ms), degrading, true, ""},
+	}
+	for _, tt := range tests {
+		got := SummarizeNetworkProbes(tt.probes, tt.results, tt.stream, 0)
+		if got == nil || got.Verdict != tt.want {
+			t.Errorf("%s: verdict = %+v, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestGroupErrors(t *testing.T) {
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	results := []*client.ResponseMetrics{
+		{ErrorMessage: "HTTP 429: rate limit exceeded (request id req_5f3a9c1e2b4d6f8a)", CompletedAt: base.Add(2 * time.Second)},
+		{ErrorMessage: "HTTP 500: internal server error", CompletedAt: base.Add(3 * time.Second)},
+		{CompletionTokens: 10},
+		{ErrorMessage: "HTTP 429: rate limit exceeded (request id req_0a1b2c3d4e5f6a7b)", CompletedAt: base.Add(time.Second)},
+	}
+
+	groups := groupErrors(results)
+	if len(groups) != 2 {
+		t.Fatalf("groups = %+v, want 2", groups)
+	}
+	limit := groups[0]
+	if limit.Count != 2 || limit.Type != "rate_limit" || limit.Fingerprint != "HTTP 429: rate limit exceeded (request id req_*)" {
+		t.Errorf("rate limit group = %+v", limit)
+	}
+	// 样例取最早出现的一条
+	if !limit.FirstSeen.Equal(base.Add(time.Second)) || !strings.Contains(limit.Sample, "req_0a1b") {
+		t.Errorf("rate limit group first seen = %v, sample = %q", limit.FirstSeen, limit.Sample)
+	}
+	if groups[1].Count != 1 || groups[1].Type != "server_error" {
+		t.Errorf("server error group = %+v", groups[1])
+	}
+}
+
+func TestRunner_CalculateResult_Redact(t *testing.T) {
+	r, err := redact.New(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	redact.Enable(r)
+	t.Cleanup(func() { redact.Enable(nil) })
+
+	results := []*client.ResponseMetrics{
+		{TotalTime: time.Second, ErrorMessage: `HTTP 400: {"error":"invalid content: 联系人 13800138000"}`},
+		{TotalTime: time.Second, ErrorMessage: `HTTP 400: {"error":"invalid content: 联系人 13912345678"}`},
+		{TotalTime: time.Second, CompletionTokens: 10, ResponseText: strings.Repeat("请联系 li@lei.cn。", 20)},
+	}
+	runner := &Runner{input: types.Input{Protocol: "openai", Model: "m", Concurrency: 1, Count: 3, ContentCheck: true}}
+	data := runner.calculateResult(results, time.Second)
+	if !data.Redacted {
+		t.Error("Redacted = false")
+	}
+	// 不同手机号脱敏后落入同一组
+	if len(data.Errors) != 1 || data.Errors[0].Count != 2 || strings.Contains(data.Errors[0].Sample, "138") || strings.Contains(data.Errors[0].Fingerprint, "138") {
+		t.Errorf("Errors = %+v", data.Errors)
+	}
+	if len(data.SuspiciousContentSamples) != 1 || !strings.HasPrefix(data.SuspiciousContentSamples[0].Excerpt, "请联系 [REDACTED]。") {
+		t.Errorf("SuspiciousContentSamples = %+v", data.SuspiciousContentSamples)
+	}
+}
+
+func TestRunner_CalculateResult_NetErrorKinds(t *testing.T) {
+	runner := &Runner{input: types.Input{Protocol: "openai", Model: "gpt-3.5-turbo", Concurrency: 1, Count: 4}}
+	results := []*client.ResponseMetrics{
+		{ErrorMessage: "Network error: dial tcp: connection refused", NetErrorKind: client.NetErrConnectionRefused, TotalTime: time.Millisecond},
+		{ErrorMessage: "Network error: dial tcp: lookup nowhere.invalid: no such host", NetErrorKind: client.NetErrDNS, TotalTime: time.Millisecond},
+		{ErrorMessage: "Network error: dial tcp: connection refused", NetErrorKind: client.NetErrConnectionRefused, TotalTime: time.Millisecond},
+		{ErrorMessage: "HTTP 500: internal server error", TotalTime: 50 * time.Millisecond},
+	}
+
+	result := runner.calculateResult(results, time.Second)
+	want := map[string]int{client.NetErrConnectionRefused: 2, client.NetErrDNS: 1}
+	if len(result.NetErrorKinds) != len(want) {
+		t.Fatalf("NetErrorKinds = %v, want %v", result.NetErrorKinds, want)
+	}
+	for kind, n := range want {
+		if result.NetErrorKinds[kind] != n {
+			t.Errorf("NetErrorKinds[%s] = %d, want %d", kind, result.NetErrorKinds[kind], n)
+		}
+	}
+}
+
+func TestRunner_CalculateResult_EmptyContent(t *testing.T) {
+	runner := &Runner{input: types.Input{Protocol: "openai", Model: "gpt-3.5-turbo", Concurrency: 1, Count: 5}}
+	results := []*client.ResponseMetrics{
+		{TotalTime: time.Second, CompletionTokens: 5, EmptyContent: true},
+		{TotalTime: time.Second, CompletionTokens: 10, ResponseText: "hello"},
+		{TotalTime: time.Second, CompletionTokens: 10, ResponseText: "hello"},
+		{TotalTime: time.Second, CompletionTokens: 10, ResponseText: "hello"},
+		{TotalTime: time.Second, ErrorMessage: "HTTP 500: internal server error", EmptyContent: true},
+	}
+
+	result := runner.calculateResult(results, 5*time.Second)
+	if result.EmptyContentCount != 1 || result.EmptyContentRate != 25 {
+		t.Errorf("EmptyContentCount = %d, EmptyContentRate = %v, want 1 and 25", result.EmptyContentCount, result.EmptyContentRate)
+	}
+}
+
+func TestRunner_CalculateResult_TTFB(t *testing.T) {
+	runner := &Runner{input: types.Input{Protocol: "openai", Model: "gpt-3.5-turbo", Concurrency: 1, Count: 3, Stream: true}}
+	results := []*client.ResponseMetrics{
+		{TimeToFirstByte: 100 * time.Millisecond, TimeToFirstToken: 300 * time.Millisecond, TotalTime: time.Second, CompletionTokens: 10},
+		{TimeToFirstByte: 200 * time.Millisecond, TimeToFirstToken: 300 * time.Millisecond, TotalTime: time.Second, CompletionTokens: 10},
+		{TimeToFirstToken: 300 * time.Millisecond, TotalTime: time.Second, CompletionTokens: 10},
+	}
+
+	result := runner.calculateResult(results, time.Second)
+	if result.AvgTTFB != 150*time.Millisecond || result.MinTTFB != 100*time.Millisecond || result.MaxTTFB != 200*time.Millisecond {
+		t.Errorf("TTFB avg/min/max = %v/%v/%v, want 150ms/100ms/200ms", result.AvgTTFB, result.MinTTFB, result.MaxTTFB)
+	}
+	if result.AvgFirstTokenGap != 150*time.Millisecond {
+		t.Errorf("AvgFirstTokenGap = %v, want 150ms", result.AvgFirstTokenGap)
+	}
+
+	runner.input.Stream = false
+	if gap := runner.calculateResult(results, time.Second).AvgFirstTokenGap; gap != 0 {
+		t.Errorf("AvgFirstTokenGap = %v in non-stream mode, want 0", gap)
+	}
+}
+
+func TestRunner_CalculateResult_P99TotalTime(t *testing.T) {
+	runner := &Runner{input: types.Input{Protocol: "openai", Model: "gpt-3.5-turbo", Concurrency: 1, Count: 100}}
+	var results []*client.ResponseMetrics
+	for i := 100; i >= 1; i-- {
+		results = append(results, &client.ResponseMetrics{TotalTime: time.Duration(i) * time.Millisecond, CompletionTokens: 10})
+	}
+
+	result := runner.calculateResult(results, time.Second)
+	if result.P99TotalTime != 99*time.Millisecond {
+		t.Errorf("P99TotalTime = %v, want 99ms", result.P99TotalTime)
+	}
+	if got := percentileDuration([]time.Duration{3 * time.Second}, 99); got != 3*time.Second {
+		t.Errorf("percentileDuration(single) = %v, want 3s", got)
+	}
+}
+
+func TestRunner_CalculateResult_ResponseEncodings(t *testing.T) {
+	runner := &Runner{input: types.Input{Protocol: "openai", Model: "gpt-3.5-turbo", Concurrency: 1, Count: 3, Stream: true, AcceptEncoding: types.AcceptEncodingBrotli}}
+	results := []*client.ResponseMetrics{
+		{Encoding: "br", CompressedBytes: 200, UncompressedBytes: 1000, TimeToFirstToken: 300 * time.Millisecond, TotalTime: time.Second, CompletionTokens: 10},
+		{Encoding: "br", CompressedBytes: 400, UncompressedBytes: 1000, TimeToFirstToken: 500 * time.Millisecond, TotalTime: time.Second, CompletionTokens: 10},
+		{Encoding: "identity", CompressedBytes: 900, UncompressedBytes: 900, TimeToFirstToken: 200 * time.Millisecond, TotalTime: time.Second, CompletionTokens: 10},
+	}
+
+	result := runner.calculateResult(results, time.Second)
+	if result.AcceptEncoding != "br" || len(result.ResponseEncodings) != 2 {
+		t.Fatalf("AcceptEncoding = %q, ResponseEncodings = %v", result.AcceptEncoding, result.ResponseEncodings)
+	}
+	br := result.ResponseEncodings["br"]
+	if br.Count != 2 || br.AvgTTFT != 400*time.Millisecond || br.AvgCompressedBytes != 300 || br.AvgUncompressedBytes != 1000 || br.CompressionRatio != 0.3 {
+		t.Errorf("br = %+v", br)
+	}
+	if identity := result.ResponseEncodings["identity"]; identity.Count != 1 || identity.CompressionRatio != 1 {
+		t.Errorf("identity = %+v", identity)
+	}
+
+	// 未记录编码（未设置 accept_encoding）时不统计
+	for _, r := range results {
+		r.Encoding = ""
+	}
+	if encodings := runner.calculateResult(results, time.Second).ResponseEncodings; encodings != nil {
+		t.Errorf("ResponseEncodings = %v, want nil", encodings)
+	}
+}
+
+func TestRunner_CalculateResult_Samples(t *testing.T) {
+	runner := &Runner{input: types.Input{Protocol: "openai", Model: "gpt-3.5-turbo", Concurrency: 1, Count: 3, Stream: true}}
+	results := []*client.ResponseMetrics{
+		{TimeToFirstToken: 200 * time.Millisecond, TotalTime: time.Second, CompletionTokens: 9},
+		{TimeToFirstToken: 400 * time.Millisecond, TotalTime: 2 * time.Second, CompletionTokens: 1},
+		{ErrorMessage: "boom", TotalTime: 100 * time.Millisecond},
+	}
+
+	// 失败请求不计入；单 token 的请求没有 TPOT
+	want := &types.MetricSamples{TotalTime: []float64{1000, 2000}, TTFT: []float64{200, 400}, TPOT: []float64{100}, TPS: []float64{9, 0.5}}
+	if samples := runner.calculateResult(results, time.Second).Samples; !reflect.DeepEqual(samples, want) {
+		t.Errorf("Samples = %+v, want %+v", samples, want)
+	}
+
+	runner.input.Stream = false
+	if ttft := runner.calculateResult(results, time.Second).Samples.TTFT; ttft != nil {
+		t.Errorf("non-stream TTFT samples = %v, want nil", ttft)
+	}
+}
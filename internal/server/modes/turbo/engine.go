@@ -124,6 +124,7 @@ func (e *Engine) Run(input types.Input) (*types.TurboResult, error) {
 			if e.onLevelDone != nil {
 				e.onLevelDone(level)
 			}
+			result.Knee = findKnee(result.Levels)
 			result.ProbeDuration = time.Since(startedAt)
 			return result, nil
 		default:
@@ -163,6 +164,7 @@ func (e *Engine) Run(input types.Input) (*types.TurboResult, error) {
 	if result.StopReason == "" {
 		result.StopReason = StopReasonMaxConcurrency
 	}
+	result.Knee = findKnee(result.Levels)
 	result.ProbeDuration = time.Since(startedAt)
 	return result, nil
 }
@@ -181,6 +183,8 @@ func buildLevelResult(report *types.ReportData, concurrency int) types.TurboLeve
 		AvgTotalTime:  report.AvgTotalTime,
 		StdDevTPS:     report.StdDevTPS,
 		Stable:        true,
+		ErrorRate:     1 - report.SuccessRate/100,
+		P99TotalTime:  report.P99TotalTime,
 	}
 }
 
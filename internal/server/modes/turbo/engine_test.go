@@ -283,3 +283,45 @@ func TestEngineFactoryReceivesCorrectConcurrency(t *testing.T) {
 		}
 	}
 }
+
+func TestEngineRunReportsKnee(t *testing.T) {
+	levels := map[int]*types.ReportData{
+		1: {TotalRequests: 10, SuccessRate: 100, AvgTotalTime: 200 * time.Millisecond, P99TotalTime: 300 * time.Millisecond},
+		2: {TotalRequests: 10, SuccessRate: 100, AvgTotalTime: 220 * time.Millisecond, P99TotalTime: 350 * time.Millisecond},
+		3: {TotalRequests: 10, SuccessRate: 100, AvgTotalTime: 300 * time.Millisecond, P99TotalTime: 500 * time.Millisecond},
+		4: {TotalRequests: 10, SuccessRate: 90, AvgTotalTime: 400 * time.Millisecond, P99TotalTime: 800 * time.Millisecond},
+	}
+	engine := New(func(input types.Input) (LevelRunner, error) {
+		return &fakeRunner{report: levels[input.Concurrency]}, nil
+	})
+
+	result, err := engine.Run(types.Input{
+		TurboConfig: types.TurboConfig{InitConcurrency: 1, MaxConcurrency: 4, StepSize: 1, LevelRequests: 10, MinSuccessRate: 0.5, MaxLatency: 5 * time.Second},
+	})
+	if err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+	if got := result.Levels[3].ErrorRate; got < 0.099 || got > 0.101 {
+		t.Fatalf("level[3] ErrorRate = %f, want 0.1", got)
+	}
+	if got := result.Levels[2].P99TotalTime; got != 500*time.Millisecond {
+		t.Fatalf("level[2] P99TotalTime = %s, want 500ms", got)
+	}
+	want := types.ConcurrencyKnee{ErrorRateConcurrency: 4, LatencyConcurrency: 3, SafeConcurrency: 2}
+	if result.Knee == nil || *result.Knee != want {
+		t.Fatalf("Knee = %+v, want %+v", result.Knee, want)
+	}
+}
+
+func TestFindKnee_NoneOrTooFewLevels(t *testing.T) {
+	flat := []types.TurboLevelResult{
+		{Concurrency: 1, ErrorRate: 0, P99TotalTime: time.Second},
+		{Concurrency: 2, ErrorRate: 0.02, P99TotalTime: 1200 * time.Millisecond},
+	}
+	if knee := findKnee(flat); knee != nil {
+		t.Fatalf("findKnee(flat) = %+v, want nil", knee)
+	}
+	if knee := findKnee(flat[:1]); knee != nil {
+		t.Fatalf("findKnee(single level) = %+v, want nil", knee)
+	}
+}
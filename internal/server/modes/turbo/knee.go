@@ -0,0 +1,41 @@
+package turbo
+
+import "github.com/yinxulai/ait/internal/server/types"
+
+// 拐点判定阈值：以第一个并发档位为基线
+const (
+	KneeErrorRateRise = 0.05 // 错误率比基线高出 5 个百分点即视为显著上升
+	KneeLatencyFactor = 1.5  // P99 延迟达到基线的 1.5 倍即视为显著上升
+)
+
+// findKnee 在按并发递增排列的档位中查找错误率与 P99 延迟开始显著上升的拐点，
+// 安全并发取最早拐点的前一个档位。档位不足两个或两类拐点都未出现时返回 nil。
+func findKnee(levels []types.TurboLevelResult) *types.ConcurrencyKnee {
+	if len(levels) < 2 {
+		return nil
+	}
+	base := levels[0]
+	knee := &types.ConcurrencyKnee{}
+	first := -1
+	for i, level := range levels[1:] {
+		idx := i + 1
+		if knee.ErrorRateConcurrency == 0 && level.ErrorRate-base.ErrorRate >= KneeErrorRateRise {
+			knee.ErrorRateConcurrency = level.Concurrency
+			if first < 0 {
+				first = idx
+			}
+		}
+		if knee.LatencyConcurrency == 0 && base.P99TotalTime > 0 &&
+			float64(level.P99TotalTime) >= float64(base.P99TotalTime)*KneeLatencyFactor {
+			knee.LatencyConcurrency = level.Concurrency
+			if first < 0 {
+				first = idx
+			}
+		}
+	}
+	if first < 0 {
+		return nil
+	}
+	knee.SafeConcurrency = levels[first-1].Concurrency
+	return knee
+}
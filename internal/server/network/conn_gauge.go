@@ -0,0 +1,67 @@
+package network
+
+import (
+	"context"
+	"net/http/httptrace"
+	"sync/atomic"
+)
+
+// ConnGauge 统计同时在途的连接数及其峰值：请求拿到连接（httptrace GotConn）时加一，请求结束时减一。
+// 峰值低于配置的并发时，说明服务端或中间件（网关、代理、连接池上限）限制了实际并发。
+// 同时按 GotConnInfo.Reused 统计复用了已有连接的请求数。零值可直接使用。
+type ConnGauge struct {
+	current atomic.Int64
+	peak    atomic.Int64
+
+	requests atomic.Int64 // 拿到过连接的请求数
+	reused   atomic.Int64 // 其中复用了已有连接的请求数
+}
+
+// Track 返回挂载了连接跟踪的 ctx 与结束函数，结束函数须在请求（含响应体读取）完成后调用一次。
+// 与调用方自己的 httptrace.ClientTrace 组合使用，互不覆盖。
+func (g *ConnGauge) Track(ctx context.Context) (context.Context, func()) {
+	var held atomic.Int64
+	var reused atomic.Bool
+	trace := &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			if info.Reused {
+				reused.Store(true)
+			}
+			held.Add(1)
+			n := g.current.Add(1)
+			for {
+				peak := g.peak.Load()
+				if n <= peak || g.peak.CompareAndSwap(peak, n) {
+					break
+				}
+			}
+		},
+	}
+	return httptrace.WithClientTrace(ctx, trace), func() {
+		// 一个请求（含重试）拿到多次连接时只计一次，任一次复用即算复用
+		n := held.Swap(0)
+		if n == 0 {
+			return
+		}
+		g.current.Add(-n)
+		g.requests.Add(1)
+		if reused.Swap(false) {
+			g.reused.Add(1)
+		}
+	}
+}
+
+// Current 返回当前在途的连接数。
+func (g *ConnGauge) Current() int {
+	return int(g.current.Load())
+}
+
+// Peak 返回在途连接数的峰值。
+func (g *ConnGauge) Peak() int {
+	return int(g.peak.Load())
+}
+
+// Reuse 返回复用了已有连接的请求数与拿到过连接的请求总数。
+func (g *ConnGauge) Reuse() (reused, requests int) {
+	return int(g.reused.Load()), int(g.requests.Load())
+}
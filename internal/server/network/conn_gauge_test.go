@@ -0,0 +1,119 @@
+package network
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httptrace"
+	"sync"
+	"testing"
+)
+
+func TestConnGauge_PeakTracksConcurrentConnections(t *testing.T) {
+	const concurrency = 3
+	arrived := make(chan struct{}, concurrency)
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		arrived <- struct{}{}
+		<-release
+		io.WriteString(w, "ok")
+	}))
+	defer srv.Close()
+
+	var g ConnGauge
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx, done := g.Track(context.Background())
+			defer done()
+			req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+			resp, err := srv.Client().Do(req)
+			if err != nil {
+				t.Errorf("request: %v", err)
+				return
+			}
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}()
+	}
+	for i := 0; i < concurrency; i++ {
+		<-arrived
+	}
+	if got := g.Current(); got != concurrency {
+		t.Errorf("Current while in flight = %d, want %d", got, concurrency)
+	}
+	close(release)
+	wg.Wait()
+
+	if got := g.Peak(); got != concurrency {
+		t.Errorf("Peak = %d, want %d", got, concurrency)
+	}
+	if got := g.Current(); got != 0 {
+		t.Errorf("Current after completion = %d, want 0", got)
+	}
+}
+
+func TestConnGauge_KeepsCallerTrace(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	var g ConnGauge
+	ctx, done := g.Track(context.Background())
+	callerSawConn := false
+	ctx = httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{GotConn: func(httptrace.GotConnInfo) { callerSawConn = true }})
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+	resp, err := srv.Client().Do(req)
+	if err != nil {
+		t.Fatalf("request: %v", err)
+	}
+	resp.Body.Close()
+	done()
+
+	if !callerSawConn || g.Peak() != 1 {
+		t.Errorf("callerSawConn = %v, Peak = %d, want both traces to fire", callerSawConn, g.Peak())
+	}
+}
+
+func TestConnGauge_Reuse(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "ok")
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name       string
+		keepAlive  bool
+		wantReused int
+	}{
+		{"keep-alive", true, 2},
+		{"keep-alive disabled", false, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpClient := &http.Client{Transport: &http.Transport{DisableKeepAlives: !tt.keepAlive}}
+			defer httpClient.CloseIdleConnections()
+			var g ConnGauge
+			for i := 0; i < 3; i++ {
+				ctx, done := g.Track(context.Background())
+				req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+				resp, err := httpClient.Do(req)
+				if err != nil {
+					t.Fatalf("request: %v", err)
+				}
+				io.Copy(io.Discard, resp.Body)
+				resp.Body.Close()
+				done()
+			}
+			// 未发出请求的 Track 不计入
+			_, done := g.Track(context.Background())
+			done()
+
+			if reused, requests := g.Reuse(); reused != tt.wantReused || requests != 3 {
+				t.Errorf("Reuse() = %d, %d, want %d, 3", reused, requests, tt.wantReused)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package network
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// HTTPOptions 出站辅助请求（遥测上报、webhook、公网 IP 查询、规则更新）的网络配置。
+// 与被测请求的 proxy_url / timeout 相互独立，互不继承。
+type HTTPOptions struct {
+	Proxy   string        // 代理地址；为空时按环境变量 HTTP_PROXY / HTTPS_PROXY / NO_PROXY 决定
+	Timeout time.Duration // 单次请求超时；为 0 时使用各调用方的默认值
+}
+
+// Validate 检查代理地址与超时是否合法。
+func (o HTTPOptions) Validate() error {
+	if proxy := strings.TrimSpace(o.Proxy); proxy != "" {
+		if u, err := url.Parse(proxy); err != nil || u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("invalid proxy url: %s", proxy)
+		}
+	}
+	if o.Timeout < 0 {
+		return fmt.Errorf("timeout must not be negative: %s", o.Timeout)
+	}
+	return nil
+}
+
+// NewClient 按配置创建 http.Client；Timeout 未设置时使用 defaultTimeout。
+// 代理地址不合法时请求直接失败，而不是悄悄绕过代理直连。
+func (o HTTPOptions) NewClient(defaultTimeout time.Duration) *http.Client {
+	transport := &http.Transport{
+		Proxy:               http.ProxyFromEnvironment,
+		MaxIdleConns:        10,
+		MaxIdleConnsPerHost: 5,
+		IdleConnTimeout:     30 * time.Second,
+	}
+	if proxy := strings.TrimSpace(o.Proxy); proxy != "" {
+		if u, err := url.Parse(proxy); err == nil && u.Scheme != "" && u.Host != "" {
+			transport.Proxy = http.ProxyURL(u)
+		} else {
+			transport.Proxy = func(*http.Request) (*url.URL, error) {
+				return nil, fmt.Errorf("invalid telemetry proxy: %s", proxy)
+			}
+		}
+	}
+
+	return &http.Client{Timeout: o.EffectiveTimeout(defaultTimeout), Transport: transport}
+}
+
+// EffectiveTimeout 返回 NewClient 创建的 http.Client 实际使用的单次请求超时：Timeout 未设置时为 defaultTimeout。
+func (o HTTPOptions) EffectiveTimeout(defaultTimeout time.Duration) time.Duration {
+	if o.Timeout > 0 {
+		return o.Timeout
+	}
+	return defaultTimeout
+}
+
+var telemetryOptions atomic.Pointer[HTTPOptions]
+
+// SetTelemetryOptions 设置进程内出站辅助请求使用的网络配置，通常在启动时由命令行参数设置一次。
+func SetTelemetryOptions(o HTTPOptions) {
+	telemetryOptions.Store(&o)
+}
+
+// TelemetryOptions 返回当前出站辅助请求的网络配置；未设置时为零值（环境变量代理 + 调用方默认超时）。
+func TelemetryOptions() HTTPOptions {
+	if o := telemetryOptions.Load(); o != nil {
+		return *o
+	}
+	return HTTPOptions{}
+}
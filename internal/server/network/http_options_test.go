@@ -0,0 +1,62 @@
+package network
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestHTTPOptions_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    HTTPOptions
+		wantErr bool
+	}{
+		{"zero value", HTTPOptions{}, false},
+		{"http proxy", HTTPOptions{Proxy: "http://127.0.0.1:7890", Timeout: time.Second}, false},
+		{"socks proxy", HTTPOptions{Proxy: "socks5://127.0.0.1:1080"}, false},
+		{"missing scheme", HTTPOptions{Proxy: "127.0.0.1:7890"}, true},
+		{"negative timeout", HTTPOptions{Timeout: -time.Second}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.opts.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestHTTPOptions_NewClient(t *testing.T) {
+	if c := (HTTPOptions{}).NewClient(3 * time.Second); c.Timeout != 3*time.Second {
+		t.Errorf("default timeout = %v, want 3s", c.Timeout)
+	}
+	if c := (HTTPOptions{Timeout: 8 * time.Second}).NewClient(3 * time.Second); c.Timeout != 8*time.Second {
+		t.Errorf("explicit timeout = %v, want 8s", c.Timeout)
+	}
+	if got := (HTTPOptions{Timeout: 8 * time.Second}).EffectiveTimeout(3 * time.Second); got != 8*time.Second {
+		t.Errorf("EffectiveTimeout = %v, want 8s", got)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, "http://example.com", nil)
+	transport := (HTTPOptions{Proxy: "http://127.0.0.1:7890"}).NewClient(0).Transport.(*http.Transport)
+	if u, err := transport.Proxy(req); err != nil || u == nil || u.Host != "127.0.0.1:7890" {
+		t.Errorf("proxy = %v, %v", u, err)
+	}
+	transport = (HTTPOptions{Proxy: "not a url"}).NewClient(0).Transport.(*http.Transport)
+	if _, err := transport.Proxy(req); err == nil {
+		t.Error("invalid proxy should fail requests instead of connecting directly")
+	}
+}
+
+func TestTelemetryOptions(t *testing.T) {
+	t.Cleanup(func() { SetTelemetryOptions(HTTPOptions{}) })
+	if got := TelemetryOptions(); got != (HTTPOptions{}) {
+		t.Errorf("default TelemetryOptions() = %+v", got)
+	}
+	want := HTTPOptions{Proxy: "http://127.0.0.1:7890", Timeout: 5 * time.Second}
+	SetTelemetryOptions(want)
+	if got := TelemetryOptions(); got != want {
+		t.Errorf("TelemetryOptions() = %+v, want %+v", got, want)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"io"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -48,9 +49,7 @@ func queryIPService(ctx context.Context, url string) (string, error) {
 	// 设置用户代理
 	req.Header.Set("User-Agent", "ait-tool/1.0")
 
-	client := &http.Client{
-		Timeout: 3 * time.Second,
-	}
+	client := TelemetryOptions().NewClient(3 * time.Second)
 
 	resp, err := client.Do(req)
 	if err != nil {
@@ -81,8 +80,11 @@ func queryIPService(ctx context.Context, url string) (string, error) {
 var cachedIP string
 var lastFetchTime time.Time
 var cacheDuration = 5 * time.Minute
+var cacheMu sync.Mutex // 保护 cachedIP / lastFetchTime，上报与报告可能并发读取
 
 func GetPublicIPCached() (string, error) {
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
 	now := time.Now()
 
 	// 如果缓存有效，直接返回
@@ -106,3 +108,10 @@ func GetPublicIPCached() (string, error) {
 
 	return ip, nil
 }
+
+// CachedPublicIP 返回已检测到的公网出口 IP，不发起网络请求；从未检测成功时返回空字符串。
+func CachedPublicIP() string {
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+	return cachedIP
+}
@@ -0,0 +1,157 @@
+package network
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yinxulai/ait/internal/server/types"
+)
+
+// maxProbeTimeout 单次探测的超时上限；探测间隔更短时以间隔为超时
+const maxProbeTimeout = 10 * time.Second
+
+// ProbeTarget 基线网络探测的目标：host:port 与是否做 TLS 握手。
+type ProbeTarget struct {
+	Addr       string
+	TLS        bool
+	ServerName string
+}
+
+// ParseProbeTarget 从被测端点地址解析探测目标：https / grpcs 做 TLS 握手，http / grpc 与不带 scheme 的
+// host:port（gRPC 端点）只建 TCP 连接；未写端口时按 scheme 取 443 / 80。
+func ParseProbeTarget(endpoint string) (ProbeTarget, error) {
+	endpoint = strings.TrimSpace(endpoint)
+	if !strings.Contains(endpoint, "://") {
+		endpoint = "grpc://" + endpoint
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Hostname() == "" {
+		return ProbeTarget{}, fmt.Errorf("invalid probe endpoint: %s", endpoint)
+	}
+	target := ProbeTarget{ServerName: u.Hostname()}
+	port := "80"
+	switch strings.ToLower(u.Scheme) {
+	case "https", "grpcs":
+		target.TLS = true
+		port = "443"
+	case "http", "grpc":
+	default:
+		return ProbeTarget{}, fmt.Errorf("unsupported probe scheme: %s", u.Scheme)
+	}
+	if p := u.Port(); p != "" {
+		port = p
+	}
+	target.Addr = net.JoinHostPort(u.Hostname(), port)
+	return target, nil
+}
+
+// Probe 对目标做一次 TCP 连接（含 DNS 解析）与 TLS 握手计时，握手完成后立即断开，不发送任何业务数据。
+// 探测只关心网络耗时，不校验证书；探测直连目标，不经过 proxy_url，但遵循 --resolve / --dns-server。
+func Probe(ctx context.Context, target ProbeTarget, timeout time.Duration) types.NetworkProbe {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var probe types.NetworkProbe
+	start := time.Now()
+	dial := CurrentDNSConfig().DialContext(&net.Dialer{})
+	conn, err := dial(ctx, "tcp", target.Addr)
+	if err != nil {
+		probe.Error = err.Error()
+		return probe
+	}
+	defer conn.Close()
+	probe.Connect = time.Since(start)
+
+	if target.TLS {
+		tlsStart := time.Now()
+		tlsConn := tls.Client(conn, &tls.Config{ServerName: target.ServerName, InsecureSkipVerify: true})
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			probe.Error = err.Error()
+			return probe
+		}
+		probe.TLS = time.Since(tlsStart)
+	}
+	return probe
+}
+
+// Prober 在运行期间按固定间隔探测基线网络延迟，与业务请求相互独立：不占用并发槽位，失败也不计入成功率。
+type Prober struct {
+	target   ProbeTarget
+	interval time.Duration
+
+	mu     sync.Mutex
+	start  time.Time
+	probes []types.NetworkProbe
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewProber 创建探测器；interval 必须大于 0。
+func NewProber(target ProbeTarget, interval time.Duration) *Prober {
+	return &Prober{target: target, interval: interval}
+}
+
+// Start 立即探测一次并开始后台周期探测。重复调用无效。
+func (p *Prober) Start(ctx context.Context) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.cancel != nil {
+		return
+	}
+	ctx, p.cancel = context.WithCancel(ctx)
+	p.start = time.Now()
+	p.done = make(chan struct{})
+	go p.loop(ctx, p.done)
+}
+
+func (p *Prober) loop(ctx context.Context, done chan struct{}) {
+	defer close(done)
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	for {
+		p.sample(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (p *Prober) sample(ctx context.Context) {
+	at := time.Now()
+	probe := Probe(ctx, p.target, min(p.interval, maxProbeTimeout))
+	if ctx.Err() != nil {
+		// 停止时被打断的探测不是网络问题，丢弃
+		return
+	}
+	p.mu.Lock()
+	probe.Offset = at.Sub(p.start)
+	p.probes = append(p.probes, probe)
+	p.mu.Unlock()
+}
+
+// Stop 停止探测并返回按时间排列的探测结果。对 nil 或未启动的探测器返回 nil，便于调用方无条件调用。
+func (p *Prober) Stop() []types.NetworkProbe {
+	if p == nil {
+		return nil
+	}
+	p.mu.Lock()
+	cancel, done := p.cancel, p.done
+	p.cancel = nil
+	p.mu.Unlock()
+	if cancel == nil {
+		return nil
+	}
+	cancel()
+	<-done
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.probes
+}
@@ -0,0 +1,121 @@
+package network
+
+import (
+	"context"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseProbeTarget(t *testing.T) {
+	tests := []struct {
+		endpoint string
+		want     ProbeTarget
+	}{
+		{"https://api.example.com/v1", ProbeTarget{Addr: "api.example.com:443", TLS: true, ServerName: "api.example.com"}},
+		{"http://127.0.0.1:8000/v1/chat/completions", ProbeTarget{Addr: "127.0.0.1:8000", ServerName: "127.0.0.1"}},
+		{"grpcs://triton.example.com", ProbeTarget{Addr: "triton.example.com:443", TLS: true, ServerName: "triton.example.com"}},
+		{"triton.example.com:8001", ProbeTarget{Addr: "triton.example.com:8001", ServerName: "triton.example.com"}},
+	}
+	for _, tt := range tests {
+		got, err := ParseProbeTarget(tt.endpoint)
+		if err != nil || got != tt.want {
+			t.Errorf("ParseProbeTarget(%q) = %+v, %v; want %+v", tt.endpoint, got, err, tt.want)
+		}
+	}
+
+	for _, endpoint := range []string{"", "ftp://example.com", "https://"} {
+		if _, err := ParseProbeTarget(endpoint); err == nil {
+			t.Errorf("ParseProbeTarget(%q) should fail", endpoint)
+		}
+	}
+}
+
+func TestProbe(t *testing.T) {
+	plain := httptest.NewServer(http.NotFoundHandler())
+	defer plain.Close()
+	secure := httptest.NewUnstartedServer(http.NotFoundHandler())
+	secure.Config.ErrorLog = log.New(io.Discard, "", 0) // 探测握手后立即断开，服务端会记录一条无关的错误
+	secure.StartTLS()
+	defer secure.Close()
+
+	target, _ := ParseProbeTarget(plain.URL)
+	if probe := Probe(context.Background(), target, time.Second); probe.Error != "" || probe.Connect <= 0 || probe.TLS != 0 {
+		t.Errorf("plain probe = %+v", probe)
+	}
+	target, _ = ParseProbeTarget(secure.URL)
+	if probe := Probe(context.Background(), target, time.Second); probe.Error != "" || probe.Connect <= 0 || probe.TLS <= 0 {
+		t.Errorf("TLS probe = %+v", probe)
+	}
+}
+
+func TestProbe_ConnectionRefused(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+
+	probe := Probe(context.Background(), ProbeTarget{Addr: addr}, time.Second)
+	if probe.Error == "" {
+		t.Errorf("probe to a closed port should fail, got %+v", probe)
+	}
+}
+
+func TestProber_StartStop(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	defer srv.Close()
+	target, _ := ParseProbeTarget(srv.URL)
+
+	p := NewProber(target, 20*time.Millisecond)
+	p.Start(context.Background())
+	time.Sleep(70 * time.Millisecond)
+	probes := p.Stop()
+	if len(probes) < 2 {
+		t.Fatalf("got %d probes, want at least 2", len(probes))
+	}
+	for i, probe := range probes {
+		if probe.Error != "" {
+			t.Errorf("probe %d failed: %s", i, probe.Error)
+		}
+		if i > 0 && probe.Offset <= probes[i-1].Offset {
+			t.Errorf("probe offsets not increasing: %v", probes)
+		}
+	}
+	if p.Stop() != nil {
+		t.Error("second Stop should return nil")
+	}
+	var nilProber *Prober
+	if nilProber.Stop() != nil {
+		t.Error("nil prober Stop should return nil")
+	}
+}
+
+func TestProbe_Timeout(t *testing.T) {
+	// 只接受 TCP 连接、不响应 TLS 握手的服务端，探测应在超时后失败
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			t.Cleanup(func() { conn.Close() })
+		}
+	}()
+
+	probe := Probe(context.Background(), ProbeTarget{Addr: ln.Addr().String(), TLS: true, ServerName: "localhost"}, 50*time.Millisecond)
+	if probe.Error == "" || (!strings.Contains(probe.Error, "deadline") && !strings.Contains(probe.Error, "timeout")) {
+		t.Errorf("probe should time out during the handshake, got %+v", probe)
+	}
+}
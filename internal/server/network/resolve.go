@@ -0,0 +1,204 @@
+package network
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// ResolveRule 一条固定解析规则，语法同 curl --resolve 的 host:port:addr：
+// 连接 host:port 时改连 addr，不做 DNS 解析。IPv6 地址写作 [2001:db8::1]。
+type ResolveRule struct {
+	Host string
+	Port string
+	Addr string // IP 地址，IPv6 不带方括号
+}
+
+// ParseResolveRule 解析 host:port:addr 形式的固定解析规则，host 与 addr 中的 IPv6 地址需加方括号。
+func ParseResolveRule(s string) (ResolveRule, error) {
+	s = strings.TrimSpace(s)
+	host, rest, err := splitBracketed(s)
+	if err != nil {
+		return ResolveRule{}, fmt.Errorf("invalid resolve rule %q: %w", s, err)
+	}
+	port, addr, ok := strings.Cut(rest, ":")
+	if !ok {
+		return ResolveRule{}, fmt.Errorf("invalid resolve rule %q: want host:port:addr", s)
+	}
+	if n, err := strconv.Atoi(port); err != nil || n <= 0 || n > 65535 {
+		return ResolveRule{}, fmt.Errorf("invalid resolve rule %q: bad port %q", s, port)
+	}
+	if strings.HasPrefix(addr, "[") {
+		if !strings.HasSuffix(addr, "]") {
+			return ResolveRule{}, fmt.Errorf("invalid resolve rule %q: missing ] in address", s)
+		}
+		addr = addr[1 : len(addr)-1]
+	}
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return ResolveRule{}, fmt.Errorf("invalid resolve rule %q: %q is not an IP address", s, addr)
+	}
+	if ip.To4() == nil && !strings.Contains(rest, "[") {
+		return ResolveRule{}, fmt.Errorf("invalid resolve rule %q: IPv6 address must be enclosed in brackets", s)
+	}
+	return ResolveRule{Host: strings.ToLower(host), Port: port, Addr: ip.String()}, nil
+}
+
+// splitBracketed 从 s 开头取出 host（可为 [IPv6]，返回时去掉方括号），返回 host 与其后 ":" 之后的部分。
+func splitBracketed(s string) (string, string, error) {
+	if strings.HasPrefix(s, "[") {
+		end := strings.Index(s, "]")
+		if end < 0 {
+			return "", "", fmt.Errorf("missing ] in host")
+		}
+		rest, ok := strings.CutPrefix(s[end+1:], ":")
+		if !ok || end == 1 {
+			return "", "", fmt.Errorf("want host:port:addr")
+		}
+		return s[1:end], rest, nil
+	}
+	host, rest, ok := strings.Cut(s, ":")
+	if !ok || host == "" {
+		return "", "", fmt.Errorf("want host:port:addr")
+	}
+	return host, rest, nil
+}
+
+// String 按 curl 语法格式化规则，IPv6 地址加方括号。
+func (r ResolveRule) String() string {
+	return net.JoinHostPort(r.Host, r.Port) + ":" + bracketIPv6(r.Addr)
+}
+
+func bracketIPv6(addr string) string {
+	if strings.Contains(addr, ":") {
+		return "[" + addr + "]"
+	}
+	return addr
+}
+
+// ParseDNSServer 解析上游 DNS 服务器地址：ip、ip:port、[ipv6] 或 [ipv6]:port，未指定端口时为 53。
+func ParseDNSServer(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	if ip := net.ParseIP(strings.Trim(s, "[]")); ip != nil {
+		return net.JoinHostPort(ip.String(), "53"), nil
+	}
+	host, port, err := net.SplitHostPort(s)
+	if err != nil {
+		return "", fmt.Errorf("invalid dns server %q: %w", s, err)
+	}
+	if net.ParseIP(host) == nil {
+		return "", fmt.Errorf("invalid dns server %q: %q is not an IP address", s, host)
+	}
+	if n, err := strconv.Atoi(port); err != nil || n <= 0 || n > 65535 {
+		return "", fmt.Errorf("invalid dns server %q: bad port %q", s, port)
+	}
+	return net.JoinHostPort(host, port), nil
+}
+
+// DNSConfig 被测请求的 DNS 解析策略：Resolve 中的 host:port 直接连接固定地址，
+// 其余域名在设置了 Server 时改用该上游 DNS 解析，否则使用系统解析。
+type DNSConfig struct {
+	Resolve []ResolveRule
+	Server  string // 上游 DNS 服务器 host:port，为空时使用系统解析
+}
+
+// Enabled 返回是否设置了任何解析策略。
+func (c DNSConfig) Enabled() bool {
+	return len(c.Resolve) > 0 || c.Server != ""
+}
+
+// Lookup 返回 host:port 的固定解析地址；没有匹配的规则时 ok 为 false。
+func (c DNSConfig) Lookup(host, port string) (addr string, ok bool) {
+	host = strings.ToLower(strings.Trim(host, "[]"))
+	for _, r := range c.Resolve {
+		if r.Host == host && r.Port == port {
+			return r.Addr, true
+		}
+	}
+	return "", false
+}
+
+// OverridesURL 返回 rawURL 的 host:port（未写端口时按 scheme 取默认端口）是否被固定解析。
+func (c DNSConfig) OverridesURL(rawURL string) bool {
+	if len(c.Resolve) == 0 {
+		return false
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	port := u.Port()
+	if port == "" {
+		switch u.Scheme {
+		case "https", "grpcs":
+			port = "443"
+		default:
+			port = "80"
+		}
+	}
+	_, ok := c.Lookup(u.Hostname(), port)
+	return ok
+}
+
+// Resolver 返回使用上游 DNS 的解析器；未设置 Server 时返回 nil（即系统解析）。
+func (c DNSConfig) Resolver() *net.Resolver {
+	if c.Server == "" {
+		return nil
+	}
+	server := c.Server
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, network, server)
+		},
+	}
+}
+
+// DialContext 返回按解析策略建立连接的拨号函数：命中固定解析的地址直接连接规则中的 IP，
+// 其余地址交给使用上游 DNS（如有）的 dialer。
+func (c DNSConfig) DialContext(dialer *net.Dialer) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	d := *dialer
+	if r := c.Resolver(); r != nil {
+		d.Resolver = r
+	}
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		if host, port, err := net.SplitHostPort(addr); err == nil {
+			if ip, ok := c.Lookup(host, port); ok {
+				addr = net.JoinHostPort(ip, port)
+			}
+		}
+		return d.DialContext(ctx, network, addr)
+	}
+}
+
+// String 返回解析策略的简短描述，如 "api.example.com:443 → 10.0.0.5 · DNS 8.8.8.8:53"；未设置时为空字符串。
+func (c DNSConfig) String() string {
+	parts := make([]string, 0, len(c.Resolve)+1)
+	for _, r := range c.Resolve {
+		parts = append(parts, net.JoinHostPort(r.Host, r.Port)+" → "+bracketIPv6(r.Addr))
+	}
+	if c.Server != "" {
+		parts = append(parts, "DNS "+c.Server)
+	}
+	return strings.Join(parts, " · ")
+}
+
+var dnsConfig atomic.Pointer[DNSConfig]
+
+// SetDNSConfig 设置本进程被测请求的 DNS 解析策略，通常在启动时由 --resolve / --dns-server 设置一次。
+func SetDNSConfig(c DNSConfig) {
+	dnsConfig.Store(&c)
+}
+
+// CurrentDNSConfig 返回当前的 DNS 解析策略；未设置时为零值（系统解析）。
+func CurrentDNSConfig() DNSConfig {
+	if c := dnsConfig.Load(); c != nil {
+		return *c
+	}
+	return DNSConfig{}
+}
@@ -0,0 +1,99 @@
+package network
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParseResolveRule(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    ResolveRule
+		wantErr bool
+	}{
+		{in: "api.example.com:443:10.0.0.5", want: ResolveRule{Host: "api.example.com", Port: "443", Addr: "10.0.0.5"}},
+		{in: " API.Example.com:8080:10.0.0.5 ", want: ResolveRule{Host: "api.example.com", Port: "8080", Addr: "10.0.0.5"}},
+		{in: "api.example.com:443:[2001:db8::1]", want: ResolveRule{Host: "api.example.com", Port: "443", Addr: "2001:db8::1"}},
+		{in: "[2001:db8::1]:443:[2001:db8::2]", want: ResolveRule{Host: "2001:db8::1", Port: "443", Addr: "2001:db8::2"}},
+		{in: "api.example.com:443:2001:db8::1", wantErr: true},
+		{in: "api.example.com:443:[2001:db8::1", wantErr: true},
+		{in: "[2001:db8::1:443:10.0.0.5", wantErr: true},
+		{in: "api.example.com:443", wantErr: true},
+		{in: "api.example.com:https:10.0.0.5", wantErr: true},
+		{in: "api.example.com:443:not-an-ip", wantErr: true},
+		{in: ":443:10.0.0.5", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseResolveRule(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseResolveRule(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("ParseResolveRule(%q) = %+v, want %+v", tt.in, got, tt.want)
+			}
+		})
+	}
+
+	rule, _ := ParseResolveRule("[2001:db8::1]:443:[2001:db8::2]")
+	if got := rule.String(); got != "[2001:db8::1]:443:[2001:db8::2]" {
+		t.Errorf("String() = %q", got)
+	}
+}
+
+func TestParseDNSServer(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{in: "8.8.8.8", want: "8.8.8.8:53"},
+		{in: "1.1.1.1:5353", want: "1.1.1.1:5353"},
+		{in: "2001:4860:4860::8888", want: "[2001:4860:4860::8888]:53"},
+		{in: "[2001:4860:4860::8888]", want: "[2001:4860:4860::8888]:53"},
+		{in: "[2001:4860:4860::8888]:5353", want: "[2001:4860:4860::8888]:5353"},
+		{in: "dns.google", wantErr: true},
+		{in: "8.8.8.8:0", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseDNSServer(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseDNSServer(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseDNSServer(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDNSConfig_DialContext(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+	_, port, _ := net.SplitHostPort(srv.Listener.Addr().String())
+
+	rule, err := ParseResolveRule("ait-resolve.invalid:" + port + ":127.0.0.1")
+	if err != nil {
+		t.Fatalf("ParseResolveRule: %v", err)
+	}
+	dns := DNSConfig{Resolve: []ResolveRule{rule}}
+	conn, err := dns.DialContext(&net.Dialer{})(context.Background(), "tcp", "ait-resolve.invalid:"+port)
+	if err != nil {
+		t.Fatalf("dial overridden host: %v", err)
+	}
+	conn.Close()
+
+	if !dns.OverridesURL("http://ait-resolve.invalid:" + port + "/v1") {
+		t.Error("OverridesURL should match the overridden host:port")
+	}
+	if dns.OverridesURL("https://ait-resolve.invalid/v1") {
+		t.Error("OverridesURL should not match a different port")
+	}
+	if got := dns.String(); got != "ait-resolve.invalid:"+port+" → 127.0.0.1" {
+		t.Errorf("String() = %q", got)
+	}
+}
@@ -0,0 +1,103 @@
+package server
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ProgressLine 机器可读进度流中的一行（JSON Lines），由运行的定时快照与开始 / 结束事件产生，
+// 供外部脚本 tail 监控无人值守的长测试。
+type ProgressLine struct {
+	Time        time.Time `json:"time"`
+	Event       EventKind `json:"event"`
+	RunID       RunID     `json:"run_id"`
+	TaskID      string    `json:"task_id"`
+	Mode        string    `json:"mode"`
+	Status      RunStatus `json:"status"`
+	Total       int       `json:"total"`
+	Done        int       `json:"done"`
+	Success     int       `json:"success"`
+	Failed      int       `json:"failed"`
+	Running     int       `json:"running"`
+	SuccessRate float64   `json:"success_rate"`
+	AvgTTFTMs   float64   `json:"avg_ttft_ms"`
+	AvgTPS      float64   `json:"avg_tps"`
+	InstantTPS  float64   `json:"instant_tps"`
+	ElapsedMs   int64     `json:"elapsed_ms"`
+	Error       string    `json:"error,omitempty"`
+}
+
+// progressStream 把进度行串行写入 w，避免多个运行并发写时行内容交错。
+type progressStream struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+var processProgress atomic.Pointer[progressStream]
+
+// SetProgressWriter 设置本进程的机器可读进度流，通常在启动时由 --progress-format=json 设置为 stderr；
+// 传入 nil 关闭。TUI 占用终端，命令行只在 --plan / --web / --mcp 等无界面模式下开启。
+func SetProgressWriter(w io.Writer) {
+	if w == nil {
+		processProgress.Store(nil)
+		return
+	}
+	processProgress.Store(&progressStream{w: w})
+}
+
+// progressEvent 返回该事件是否写入进度流：定时快照与运行开始 / 结束，逐请求事件过于频繁不写。
+func progressEvent(kind EventKind) bool {
+	switch kind {
+	case EventRunStarted, EventProgressTick, EventRunComplete, EventRunFailed, EventRunStopped:
+		return true
+	}
+	return false
+}
+
+// writeProgress 在开启进度流时把事件携带的运行快照写为一行 JSON；写入失败直接忽略，不影响运行。
+func writeProgress(event Event) {
+	stream := processProgress.Load()
+	if stream == nil || !progressEvent(event.Kind) {
+		return
+	}
+	snap, ok := event.Payload.(*RunState)
+	if !ok || snap == nil {
+		return
+	}
+	now := time.Now()
+	line := ProgressLine{
+		Time:        now,
+		Event:       event.Kind,
+		RunID:       event.RunID,
+		TaskID:      snap.TaskID,
+		Mode:        snap.Mode,
+		Status:      snap.Status,
+		Total:       snap.TotalReqs,
+		Done:        snap.DoneReqs,
+		Success:     snap.SuccessReqs,
+		Failed:      snap.FailedReqs,
+		Running:     snap.RunningReqs,
+		SuccessRate: snap.SuccessRate,
+		AvgTTFTMs:   float64(snap.AvgTTFT) / float64(time.Millisecond),
+		AvgTPS:      snap.AvgTPS,
+		InstantTPS:  snap.InstantTPS,
+		Error:       snap.ErrorMsg,
+	}
+	if !snap.StartedAt.IsZero() {
+		end := now
+		if snap.FinishedAt != nil {
+			end = *snap.FinishedAt
+		}
+		line.ElapsedMs = end.Sub(snap.StartedAt).Milliseconds()
+	}
+	data, err := json.Marshal(line)
+	if err != nil {
+		return
+	}
+	stream.mu.Lock()
+	defer stream.mu.Unlock()
+	_, _ = stream.w.Write(append(data, '\n'))
+}
@@ -0,0 +1,116 @@
+package prompt
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultCommandTimeout --prompt-command 单次执行的默认超时
+const DefaultCommandTimeout = 10 * time.Second
+
+// CommandSource 每个请求前执行一次外部命令、以其 stdout 作为 prompt 的来源（--prompt-command）。
+//
+// 命令经 shell 执行（Unix 为 sh -c，Windows 为 cmd /C），环境变量 AIT_PROMPT_INDEX 为请求序号；
+// 输出末尾的换行会被去掉。同时执行的命令数不超过 maxProcs，超出时排队等待，避免高并发下进程数失控。
+// 输出不缓存，实际发送的内容由调用方随请求结果记录（见 client.ResponseMetrics.Prompt）。
+type CommandSource struct {
+	command string
+	timeout time.Duration
+	slots   chan struct{}
+}
+
+// NewCommandSource 创建命令 prompt 来源；timeout <= 0 时使用 DefaultCommandTimeout，maxProcs <= 0 时为 CPU 核数。
+func NewCommandSource(command string, timeout time.Duration, maxProcs int) (*CommandSource, error) {
+	if strings.TrimSpace(command) == "" {
+		return nil, fmt.Errorf("prompt 命令不能为空")
+	}
+	if timeout <= 0 {
+		timeout = DefaultCommandTimeout
+	}
+	if maxProcs <= 0 {
+		maxProcs = runtime.NumCPU()
+	}
+	return &CommandSource{
+		command: command,
+		timeout: timeout,
+		slots:   make(chan struct{}, maxProcs),
+	}, nil
+}
+
+// Generate 执行一次命令生成第 index 个请求的 prompt；命令超时、退出码非 0 或输出为空时返回错误，
+// 该请求应记为失败而不发送。ctx 取消（如停止运行）时正在排队或执行的命令随之终止。
+func (s *CommandSource) Generate(ctx context.Context, index int) (string, error) {
+	select {
+	case s.slots <- struct{}{}:
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+	content, err := s.run(ctx, index)
+	<-s.slots
+	if err != nil {
+		return "", err
+	}
+	return content, nil
+}
+
+func (s *CommandSource) run(ctx context.Context, index int) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	name, args := "sh", []string{"-c", s.command}
+	if runtime.GOOS == "windows" {
+		name, args = "cmd", []string{"/C", s.command}
+	}
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Env = append(os.Environ(), "AIT_PROMPT_INDEX="+strconv.Itoa(index))
+	// 命令派生的子进程可能继续占用输出管道，终止后最多再等 1 秒
+	cmd.WaitDelay = time.Second
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return "", fmt.Errorf("prompt 命令执行超时（%s）", s.timeout)
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("prompt 命令执行失败: %w: %s", err, truncateToRunes(msg, 200))
+		}
+		return "", fmt.Errorf("prompt 命令执行失败: %w", err)
+	}
+	content := strings.TrimRight(stdout.String(), "\r\n")
+	if strings.TrimSpace(content) == "" {
+		return "", fmt.Errorf("prompt 命令没有输出")
+	}
+	return content, nil
+}
+
+// GetSystemContent 命令模式不发送公共前缀。
+func (s *CommandSource) GetSystemContent() string {
+	return ""
+}
+
+// GetRandomContent 返回第 0 个请求的 prompt。
+func (s *CommandSource) GetRandomContent() string {
+	return s.GetContentByIndex(0)
+}
+
+// GetContentByIndex 执行一次命令返回第 index 个请求的 prompt，命令失败时返回空字符串；
+// 发送请求时应使用 Generate 以便处理失败。
+func (s *CommandSource) GetContentByIndex(index int) string {
+	content, _ := s.Generate(context.Background(), index)
+	return content
+}
+
+// Count 命令每次生成一条 prompt，估算时只取一条样本。
+func (s *CommandSource) Count() int {
+	return 1
+}
@@ -0,0 +1,102 @@
+package prompt
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func skipOnWindows(t *testing.T) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("测试命令使用 sh 语法")
+	}
+}
+
+func TestCommandSource_Generate(t *testing.T) {
+	skipOnWindows(t)
+	if _, err := NewCommandSource("  ", 0, 0); err == nil {
+		t.Error("NewCommandSource(empty) error = nil")
+	}
+
+	// 每次执行往计数文件追加一行，用来统计执行次数
+	counter := filepath.Join(t.TempDir(), "runs")
+	src, err := NewCommandSource(`echo x >> `+counter+`; printf 'prompt-%s\n\n' "$AIT_PROMPT_INDEX"`, time.Second, 2)
+	if err != nil {
+		t.Fatalf("NewCommandSource: %v", err)
+	}
+	runs := func() int {
+		data, _ := os.ReadFile(counter)
+		return strings.Count(string(data), "x")
+	}
+	generate := func(index int) {
+		t.Helper()
+		got, err := src.Generate(context.Background(), index)
+		if err != nil {
+			t.Fatalf("Generate(%d): %v", index, err)
+		}
+		if want := "prompt-" + string(rune('0'+index)); got != want {
+			t.Errorf("Generate(%d) = %q, want %q", index, got, want)
+		}
+	}
+
+	// 不缓存输出，每次取用都执行一次命令
+	generate(3)
+	generate(3)
+	generate(5)
+	if got := src.GetContentByIndex(3); got != "prompt-3" {
+		t.Errorf("GetContentByIndex(3) = %q", got)
+	}
+	if got := runs(); got != 4 {
+		t.Errorf("command ran %d times, want 4", got)
+	}
+	if src.GetSystemContent() != "" || src.Count() != 1 {
+		t.Errorf("GetSystemContent = %q, Count = %d", src.GetSystemContent(), src.Count())
+	}
+}
+
+func TestCommandSource_Errors(t *testing.T) {
+	skipOnWindows(t)
+	cases := map[string]string{
+		"echo boom >&2; exit 3": "boom",
+		"exit 1":                "执行失败",
+		"printf '\\n'":          "没有输出",
+		"sleep 5":               "超时",
+	}
+	for command, want := range cases {
+		src, err := NewCommandSource(command, 200*time.Millisecond, 1)
+		if err != nil {
+			t.Fatalf("NewCommandSource(%q): %v", command, err)
+		}
+		start := time.Now()
+		_, err = src.Generate(context.Background(), 0)
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Generate(%q) error = %v, want it to contain %q", command, err, want)
+		}
+		if elapsed := time.Since(start); elapsed > 3*time.Second {
+			t.Errorf("Generate(%q) took %s", command, elapsed)
+		}
+		if got := src.GetContentByIndex(0); got != "" {
+			t.Errorf("GetContentByIndex after failure = %q", got)
+		}
+	}
+}
+
+func TestCommandSource_Canceled(t *testing.T) {
+	skipOnWindows(t)
+	src, err := NewCommandSource("echo hi", time.Second, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 占满执行槽位后，排队中的调用应随 ctx 取消返回
+	src.slots <- struct{}{}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := src.Generate(ctx, 0); err != context.Canceled {
+		t.Errorf("Generate with canceled ctx error = %v", err)
+	}
+}
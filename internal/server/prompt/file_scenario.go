@@ -0,0 +1,49 @@
+package prompt
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// FileScenario 按 prompt 文件分场景运行时的一个场景：只从一个文件中取 prompt。
+type FileScenario struct {
+	Name   string        // 场景名：文件名去掉扩展名，重名时追加序号
+	Path   string        // 文件路径
+	Source *PromptSource // 只含该文件的 prompt 来源
+}
+
+// FileScenarios 把文件来源按文件拆成场景，顺序与 FilePaths 一致（glob 匹配结果按文件名排序）；
+// 不是文件来源时返回 nil。各场景沿用原来源的系统消息。
+func (ps *PromptSource) FileScenarios() []FileScenario {
+	if !ps.IsFile {
+		return nil
+	}
+	scenarios := make([]FileScenario, 0, len(ps.FilePaths))
+	seen := map[string]int{}
+	for _, path := range ps.FilePaths {
+		name := ScenarioName(path)
+		// 不同目录下的同名文件追加序号，保证报告中的场景名唯一
+		seen[name]++
+		if n := seen[name]; n > 1 {
+			name = fmt.Sprintf("%s#%d", name, n)
+		}
+		scenarios = append(scenarios, FileScenario{
+			Name: name,
+			Path: path,
+			Source: &PromptSource{
+				IsFile:        true,
+				FilePaths:     []string{path},
+				SystemContent: ps.SystemContent,
+				DisplayText:   fmt.Sprintf("文件: %s (1个)", path),
+			},
+		})
+	}
+	return scenarios
+}
+
+// ScenarioName 返回 prompt 文件对应的场景名：文件名去掉扩展名，如 prompts/code.txt 为 code。
+func ScenarioName(path string) string {
+	base := filepath.Base(path)
+	return strings.TrimSuffix(base, filepath.Ext(base))
+}
@@ -0,0 +1,53 @@
+package prompt
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPromptSource_FileScenarios(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"code.txt":         "写一个快速排序",
+		"translate.md":     "翻译这段话",
+		"sub/code.txt":     "另一个代码场景",
+		"summary.prompt.v": "总结全文",
+	} {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	source, err := LoadPromptsFromFile(filepath.Join(dir, "*"))
+	if err != nil {
+		t.Fatalf("LoadPromptsFromFile: %v", err)
+	}
+	source.FilePaths = append(source.FilePaths, filepath.Join(dir, "sub", "code.txt"))
+	source.SystemContent = "system"
+
+	scenarios := source.FileScenarios()
+	want := []struct{ name, content string }{
+		{"code", "写一个快速排序"},
+		{"summary.prompt", "总结全文"},
+		{"translate", "翻译这段话"},
+		{"code#2", "另一个代码场景"},
+	}
+	if len(scenarios) != len(want) {
+		t.Fatalf("FileScenarios() = %d scenarios, want %d", len(scenarios), len(want))
+	}
+	for i, w := range want {
+		s := scenarios[i]
+		if s.Name != w.name || s.Source.GetContentByIndex(0) != w.content || s.Source.GetSystemContent() != "system" {
+			t.Errorf("scenario %d = %q / %q, want %q / %q", i, s.Name, s.Source.GetContentByIndex(0), w.name, w.content)
+		}
+	}
+
+	text, _ := LoadPrompts("hello")
+	if got := text.FileScenarios(); got != nil {
+		t.Errorf("text source FileScenarios() = %v, want nil", got)
+	}
+}
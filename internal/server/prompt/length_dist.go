@@ -0,0 +1,125 @@
+package prompt
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+)
+
+// LengthBucket 长度分布中的一档：目标长度（字符）与采样权重。
+type LengthBucket struct {
+	Length int
+	Weight float64
+}
+
+// LengthDist prompt 长度分布，如 "100:0.8,4000:0.2" 表示 80% 的请求约 100 字符、20% 约 4000 字符。
+type LengthDist []LengthBucket
+
+// ParseLengthDist 解析 "长度:权重,长度:权重" 形式的分布；权重只需为正数，不要求和为 1。
+func ParseLengthDist(s string) (LengthDist, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, fmt.Errorf("长度分布不能为空")
+	}
+	var dist LengthDist
+	for i, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		lengthText, weightText, ok := strings.Cut(item, ":")
+		if !ok {
+			return nil, fmt.Errorf("长度分布第 %d 项 %q 缺少权重，应为 长度:权重", i+1, item)
+		}
+		length, err := strconv.Atoi(strings.TrimSpace(lengthText))
+		if err != nil || length <= 0 {
+			return nil, fmt.Errorf("长度分布第 %d 项的长度 %q 必须是正整数", i+1, strings.TrimSpace(lengthText))
+		}
+		weight, err := strconv.ParseFloat(strings.TrimSpace(weightText), 64)
+		if err != nil || weight <= 0 {
+			return nil, fmt.Errorf("长度分布第 %d 项的权重 %q 必须是正数", i+1, strings.TrimSpace(weightText))
+		}
+		dist = append(dist, LengthBucket{Length: length, Weight: weight})
+	}
+	return dist, nil
+}
+
+// Pick 按权重把 [0,1) 内的 u 映射到一档长度。
+func (d LengthDist) Pick(u float64) int {
+	var total float64
+	for _, b := range d {
+		total += b.Weight
+	}
+	target := u * total
+	for _, b := range d {
+		if target < b.Weight {
+			return b.Length
+		}
+		target -= b.Weight
+	}
+	return d[len(d)-1].Length
+}
+
+// String 返回规范化的分布表达式。
+func (d LengthDist) String() string {
+	parts := make([]string, 0, len(d))
+	for _, b := range d {
+		parts = append(parts, fmt.Sprintf("%d:%s", b.Length, strconv.FormatFloat(b.Weight, 'g', -1, 64)))
+	}
+	return strings.Join(parts, ",")
+}
+
+// LengthDistSource 按长度分布为每个请求采样目标长度的 PromptSource。
+// 采样只取决于种子与请求序号，与请求的执行顺序无关，相同种子的多次运行得到相同的长度序列。
+// 各档内容由 generated 模式的用户消息生成逻辑产出，不附带公共前缀（system 消息）。
+type LengthDistSource struct {
+	dist     LengthDist
+	seed     int64
+	contents map[int][]string // 长度 → 该长度的用户消息变体
+}
+
+// LoadPromptByLengthDist 按长度分布与随机种子创建 PromptSource。
+func LoadPromptByLengthDist(dist LengthDist, seed int64) (*LengthDistSource, error) {
+	if len(dist) == 0 {
+		return nil, fmt.Errorf("长度分布不能为空")
+	}
+	contents := make(map[int][]string, len(dist))
+	for _, b := range dist {
+		if _, ok := contents[b.Length]; !ok {
+			contents[b.Length] = buildGeneratedUserPrompts(b.Length)
+		}
+	}
+	return &LengthDistSource{dist: dist, seed: seed, contents: contents}, nil
+}
+
+// LengthAt 返回第 index 个请求采样到的目标长度。
+func (s *LengthDistSource) LengthAt(index int) int {
+	r := rand.New(rand.NewSource(s.seed*1_000_003 + int64(index)))
+	return s.dist.Pick(r.Float64())
+}
+
+// GetSystemContent 长度分布模式不发送公共前缀，各档长度全部由用户消息承担。
+func (s *LengthDistSource) GetSystemContent() string {
+	return ""
+}
+
+// GetRandomContent 按第 0 个请求的采样结果返回内容。
+func (s *LengthDistSource) GetRandomContent() string {
+	return s.GetContentByIndex(0)
+}
+
+// GetContentByIndex 返回第 index 个请求的内容：先采样目标长度，再在该长度的变体间轮换。
+func (s *LengthDistSource) GetContentByIndex(index int) string {
+	if index < 0 {
+		index = 0
+	}
+	variants := s.contents[s.LengthAt(index)]
+	return variants[index%len(variants)]
+}
+
+// Count 返回各档变体总数。
+func (s *LengthDistSource) Count() int {
+	n := 0
+	for _, variants := range s.contents {
+		n += len(variants)
+	}
+	return n
+}
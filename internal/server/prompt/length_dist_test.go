@@ -0,0 +1,86 @@
+package prompt
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestParseLengthDist(t *testing.T) {
+	dist, err := ParseLengthDist(" 100:0.8, 4000 : 0.2 ")
+	if err != nil {
+		t.Fatalf("ParseLengthDist: %v", err)
+	}
+	if len(dist) != 2 || dist[0] != (LengthBucket{100, 0.8}) || dist[1] != (LengthBucket{4000, 0.2}) {
+		t.Errorf("dist = %+v", dist)
+	}
+	if got := dist.String(); got != "100:0.8,4000:0.2" {
+		t.Errorf("String() = %q", got)
+	}
+
+	for expr, want := range map[string]string{
+		"":              "不能为空",
+		"100":           "缺少权重",
+		"100:0.8,,":     "第 2 项",
+		"abc:1":         "必须是正整数",
+		"0:1":           "必须是正整数",
+		"100:-1":        "必须是正数",
+		"100:0.8,200:x": "第 2 项的权重",
+	} {
+		if _, err := ParseLengthDist(expr); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("ParseLengthDist(%q) error = %v, want it to contain %q", expr, err, want)
+		}
+	}
+}
+
+func TestLengthDist_Pick(t *testing.T) {
+	dist := LengthDist{{100, 4}, {4000, 1}} // 权重不要求和为 1
+	cases := map[float64]int{0: 100, 0.79: 100, 0.8: 4000, 0.999: 4000}
+	for u, want := range cases {
+		if got := dist.Pick(u); got != want {
+			t.Errorf("Pick(%v) = %d, want %d", u, got, want)
+		}
+	}
+}
+
+func TestLengthDistSource(t *testing.T) {
+	dist, _ := ParseLengthDist("100:0.8,4000:0.2")
+	source, err := LoadPromptByLengthDist(dist, 42)
+	if err != nil {
+		t.Fatalf("LoadPromptByLengthDist: %v", err)
+	}
+	if source.GetSystemContent() != "" {
+		t.Error("length distribution source should not send a shared system prefix")
+	}
+
+	const n = 2000
+	long := 0
+	for i := 0; i < n; i++ {
+		length := source.LengthAt(i)
+		if got := utf8.RuneCountInString(source.GetContentByIndex(i)); got != length {
+			t.Fatalf("request %d: content length %d, want sampled %d", i, got, length)
+		}
+		if length == 4000 {
+			long++
+		}
+	}
+	if ratio := float64(long) / n; ratio < 0.15 || ratio > 0.25 {
+		t.Errorf("long prompt ratio = %.3f, want about 0.2", ratio)
+	}
+
+	// 相同种子的长度序列一致，且与访问顺序无关；不同种子的序列不同
+	again, _ := LoadPromptByLengthDist(dist, 42)
+	other, _ := LoadPromptByLengthDist(dist, 7)
+	differs := false
+	for i := 99; i >= 0; i-- {
+		if again.LengthAt(i) != source.LengthAt(i) {
+			t.Fatalf("request %d: same seed sampled different lengths", i)
+		}
+		if other.LengthAt(i) != source.LengthAt(i) {
+			differs = true
+		}
+	}
+	if !differs {
+		t.Error("different seeds should sample different length sequences")
+	}
+}
@@ -0,0 +1,36 @@
+package prompt
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ParseLengthSweep 解析 "1k,4k,16k,64k" 形式的输入长度列表（token 数），k 表示 1024；
+// 长度必须为正整数且不能重复，按书写顺序返回。
+func ParseLengthSweep(s string) ([]int, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, fmt.Errorf("输入长度列表不能为空")
+	}
+	var lengths []int
+	seen := map[int]bool{}
+	for i, item := range strings.Split(s, ",") {
+		item = strings.ToLower(strings.TrimSpace(item))
+		multiplier := 1
+		if number, ok := strings.CutSuffix(item, "k"); ok {
+			item, multiplier = number, 1024
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(item))
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("输入长度列表第 %d 项 %q 必须是正整数，可带 k 后缀", i+1, item)
+		}
+		n *= multiplier
+		if seen[n] {
+			return nil, fmt.Errorf("输入长度列表第 %d 项 %d 重复", i+1, n)
+		}
+		seen[n] = true
+		lengths = append(lengths, n)
+	}
+	return lengths, nil
+}
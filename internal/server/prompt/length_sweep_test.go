@@ -0,0 +1,31 @@
+package prompt
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseLengthSweep(t *testing.T) {
+	lengths, err := ParseLengthSweep(" 1k, 4K ,500,64k ")
+	if err != nil {
+		t.Fatalf("ParseLengthSweep: %v", err)
+	}
+	if want := []int{1024, 4096, 500, 65536}; !slices.Equal(lengths, want) {
+		t.Errorf("lengths = %v, want %v", lengths, want)
+	}
+
+	for expr, want := range map[string]string{
+		"":        "不能为空",
+		"1k,,4k":  "第 2 项",
+		"abc":     "必须是正整数",
+		"0":       "必须是正整数",
+		"-1k":     "必须是正整数",
+		"1k,1024": "重复",
+		"1.5k,2k": "第 1 项",
+	} {
+		if _, err := ParseLengthSweep(expr); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("ParseLengthSweep(%q) error = %v, want it to contain %q", expr, err, want)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package prompt
+
+import (
+	"crypto/rand"
+	"fmt"
+	mathrand "math/rand"
+	"strconv"
+	"strings"
+)
+
+// prompt 模板占位符：每个请求在发送前独立展开，用于让 prompt 内容互不相同以绕过服务端缓存。
+const (
+	PlaceholderRandom = "{{random}}" // 16 位十六进制随机数
+	PlaceholderUUID   = "{{uuid}}"   // 随机 UUID v4
+	PlaceholderIndex  = "{{index}}"  // 请求序号（从 0 开始）
+)
+
+// HasPlaceholders 返回内容中是否包含任一占位符。
+func HasPlaceholders(content string) bool {
+	return strings.Contains(content, PlaceholderRandom) ||
+		strings.Contains(content, PlaceholderUUID) ||
+		strings.Contains(content, PlaceholderIndex)
+}
+
+// ExpandPlaceholders 展开内容中的占位符。同一内容中的多个 {{random}}/{{uuid}} 各自取不同的值。
+func ExpandPlaceholders(content string, index int) string {
+	if !strings.Contains(content, "{{") {
+		return content
+	}
+	content = strings.ReplaceAll(content, PlaceholderIndex, strconv.Itoa(index))
+	content = replaceEach(content, PlaceholderRandom, func() string {
+		return fmt.Sprintf("%016x", mathrand.Uint64())
+	})
+	return replaceEach(content, PlaceholderUUID, newUUID)
+}
+
+// replaceEach 将每一处 placeholder 替换为 gen 新生成的值。
+func replaceEach(content, placeholder string, gen func() string) string {
+	if !strings.Contains(content, placeholder) {
+		return content
+	}
+	parts := strings.Split(content, placeholder)
+	var b strings.Builder
+	b.WriteString(parts[0])
+	for _, part := range parts[1:] {
+		b.WriteString(gen())
+		b.WriteString(part)
+	}
+	return b.String()
+}
+
+// newUUID 生成随机 UUID v4。
+func newUUID() string {
+	var u [16]byte
+	_, _ = rand.Read(u[:])
+	u[6] = (u[6] & 0x0f) | 0x40 // version 4
+	u[8] = (u[8] & 0x3f) | 0x80 // RFC 4122 variant
+	return fmt.Sprintf("%x-%x-%x-%x-%x", u[0:4], u[4:6], u[6:8], u[8:10], u[10:16])
+}
@@ -0,0 +1,68 @@
+package prompt
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+func TestExpandPlaceholders(t *testing.T) {
+	got := ExpandPlaceholders("req={{index}} id={{uuid}} nonce={{random}}", 7)
+
+	fields := strings.Fields(got)
+	if len(fields) != 3 {
+		t.Fatalf("unexpected expansion: %q", got)
+	}
+	if fields[0] != "req=7" {
+		t.Errorf("index: got %q, want req=7", fields[0])
+	}
+	if id := strings.TrimPrefix(fields[1], "id="); !uuidPattern.MatchString(id) {
+		t.Errorf("uuid: got %q, want UUID v4", id)
+	}
+	if nonce := strings.TrimPrefix(fields[2], "nonce="); len(nonce) != 16 {
+		t.Errorf("random: got %q, want 16 hex chars", nonce)
+	}
+}
+
+func TestExpandPlaceholders_EachOccurrenceDiffers(t *testing.T) {
+	got := ExpandPlaceholders("{{uuid}} {{uuid}} {{random}} {{random}}", 0)
+	fields := strings.Fields(got)
+	if fields[0] == fields[1] || fields[2] == fields[3] {
+		t.Errorf("placeholders should expand independently: %q", got)
+	}
+}
+
+func TestExpandPlaceholders_NoPlaceholders(t *testing.T) {
+	for _, s := range []string{"", "plain text", "{{unknown}} stays"} {
+		if got := ExpandPlaceholders(s, 1); got != s {
+			t.Errorf("ExpandPlaceholders(%q) = %q, want unchanged", s, got)
+		}
+	}
+}
+
+func TestHasPlaceholders(t *testing.T) {
+	if !HasPlaceholders("hi {{index}}") || HasPlaceholders("hi {{name}}") {
+		t.Error("HasPlaceholders detected wrong placeholders")
+	}
+}
+
+func TestPromptSource_ExpandsPlaceholdersPerRequest(t *testing.T) {
+	ps, _ := LoadPrompts("请求 {{index}}：{{random}}")
+
+	first, second := ps.GetContentByIndex(0), ps.GetContentByIndex(1)
+	if !strings.HasPrefix(first, "请求 0：") || !strings.HasPrefix(second, "请求 1：") {
+		t.Errorf("unexpected contents: %q / %q", first, second)
+	}
+	if strings.TrimPrefix(first, "请求 0：") == strings.TrimPrefix(second, "请求 1：") {
+		t.Error("random part should differ between requests")
+	}
+	if got := ps.GetRandomContent(); HasPlaceholders(got) {
+		t.Errorf("GetRandomContent left placeholders: %q", got)
+	}
+	// 原始内容保持模板形式，便于展示
+	if ps.Contents[0] != "请求 {{index}}：{{random}}" {
+		t.Errorf("template mutated: %q", ps.Contents[0])
+	}
+}
@@ -1,7 +1,9 @@
 package prompt
 
 import (
+	"bufio"
 	"fmt"
+	"io"
 	"io/fs"
 	"log/slog"
 	"math/rand"
@@ -48,6 +50,45 @@ func LoadPrompts(promptArg string) (*PromptSource, error) {
 	}, nil
 }
 
+// LoadPromptsFromLines 逐行读取 r，每个非空行作为一个候选 prompt（行尾的 \r 会被去掉）。
+// 与 LoadPrompts 把整块文本当作单个 prompt 不同，适合 `cat prompts.txt | ait ...` 这类数据集用法。
+func LoadPromptsFromLines(r io.Reader) (*PromptSource, error) {
+	var contents []string
+	if err := readLines(r, func(_ int, line string) {
+		contents = append(contents, line)
+	}); err != nil {
+		return nil, fmt.Errorf("读取 prompt 失败: %w", err)
+	}
+	if len(contents) == 0 {
+		return nil, fmt.Errorf("没有读取到非空的 prompt 行")
+	}
+
+	return &PromptSource{
+		IsFile:         false,
+		Contents:       contents,
+		DisplayText:    fmt.Sprintf("逐行 prompt (%d条)", len(contents)),
+		ShouldTruncate: false,
+	}, nil
+}
+
+// readLines 逐行读取 r，对每个非空行回调其行号（从 1 开始）与去掉换行符后的内容。
+func readLines(r io.Reader, fn func(lineNo int, line string)) error {
+	br := bufio.NewReader(r)
+	for lineNo := 1; ; lineNo++ {
+		line, err := br.ReadString('\n')
+		line = strings.TrimRight(line, "\r\n")
+		if strings.TrimSpace(line) != "" {
+			fn(lineNo, line)
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
 // LoadPromptsFromFile 从文件路径加载prompt，支持单文件和通配符
 func LoadPromptsFromFile(pathPattern string) (*PromptSource, error) {
 	// 检查是否包含通配符
@@ -120,8 +161,18 @@ func (ps *PromptSource) GetSystemContent() string {
 	return ps.SystemContent
 }
 
-// GetRandomContent 随机获取一个prompt内容
+// GetRandomContent 随机获取一个prompt内容，并展开其中的占位符（{{index}} 按 0 处理）
 func (ps *PromptSource) GetRandomContent() string {
+	return ExpandPlaceholders(ps.randomContent(), 0)
+}
+
+// GetContentByIndex 根据索引获取prompt内容，并展开其中的占位符
+func (ps *PromptSource) GetContentByIndex(index int) string {
+	return ExpandPlaceholders(ps.contentByIndex(index), index)
+}
+
+// randomContent 随机获取一个未展开占位符的prompt内容
+func (ps *PromptSource) randomContent() string {
 	// 如果不是文件源，直接返回内容
 	if !ps.IsFile {
 		if len(ps.Contents) == 0 {
@@ -162,15 +213,15 @@ func (ps *PromptSource) GetRandomContent() string {
 	return string(content)
 }
 
-// GetContentByIndex 根据索引获取prompt内容
-func (ps *PromptSource) GetContentByIndex(index int) string {
+// contentByIndex 根据索引获取未展开占位符的prompt内容
+func (ps *PromptSource) contentByIndex(index int) string {
 	// 如果不是文件源，直接返回内容
 	if !ps.IsFile {
 		if len(ps.Contents) == 0 {
-			return ps.GetRandomContent()
+			return ps.randomContent()
 		}
 		if index < 0 {
-			return ps.GetRandomContent()
+			return ps.randomContent()
 		}
 		// 用取模循环，确保多个请求在有限 Contents 上均匀分布
 		return ps.Contents[index%len(ps.Contents)]
@@ -178,14 +229,14 @@ func (ps *PromptSource) GetContentByIndex(index int) string {
 
 	// 文件源：根据索引读取对应文件
 	if index < 0 || index >= len(ps.FilePaths) {
-		return ps.GetRandomContent()
+		return ps.randomContent()
 	}
 
 	filePath := ps.FilePaths[index]
 	content, err := os.ReadFile(filePath)
 	if err != nil {
 		slog.Warn("failed to read prompt file, falling back to random", "path", filePath, "error", err)
-		return ps.GetRandomContent()
+		return ps.randomContent()
 	}
 
 	return string(content)
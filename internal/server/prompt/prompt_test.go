@@ -1,6 +1,7 @@
 package prompt
 
 import (
+	"reflect"
 	"strings"
 	"testing"
 	"unicode/utf8"
@@ -199,3 +200,21 @@ func TestGeneratePromptByLengthQuality(t *testing.T) {
 		t.Errorf("生成的内容不应该以空格开头或结尾")
 	}
 }
+
+func TestLoadPromptsFromLines(t *testing.T) {
+	source, err := LoadPromptsFromLines(strings.NewReader("first\r\n\n  \nsecond {{index}}\nthird"))
+	if err != nil {
+		t.Fatalf("LoadPromptsFromLines() error = %v", err)
+	}
+	want := []string{"first", "second {{index}}", "third"}
+	if source.IsFile || !reflect.DeepEqual(source.Contents, want) {
+		t.Fatalf("Contents = %q, want %q", source.Contents, want)
+	}
+	if got := source.GetContentByIndex(4); got != "second 4" {
+		t.Errorf("GetContentByIndex(4) = %q, want %q", got, "second 4")
+	}
+
+	if _, err := LoadPromptsFromLines(strings.NewReader("\n\n")); err == nil {
+		t.Error("expected error for input without non-empty lines")
+	}
+}
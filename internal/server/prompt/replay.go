@@ -0,0 +1,114 @@
+package prompt
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+)
+
+// ReplayEntry 重放文件（JSONL）中的一行：一个失败请求的 prompt 及其来源。
+type ReplayEntry struct {
+	TaskID string `json:"task_id"`          // 原任务 ID
+	RunID  string `json:"run_id,omitempty"` // 原运行 ID
+	Index  int    `json:"index"`            // 请求在原运行中的序号
+	System string `json:"system,omitempty"` // 系统消息，为空时不发送
+	Prompt string `json:"prompt"`           // 已展开占位符的 prompt；raw 模式下为原始请求体
+	Error  string `json:"error,omitempty"`  // 原请求的错误信息，仅供查阅
+}
+
+// WriteReplayEntries 把 entries 逐行以 JSON 写入 w。
+func WriteReplayEntries(w io.Writer, entries []ReplayEntry) error {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	for _, e := range entries {
+		if err := enc.Encode(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReplaySource 从重放文件加载的 prompt 来源：按文件顺序逐条返回 prompt，并保留每条在原运行中的序号。
+type ReplaySource struct {
+	Path    string
+	Entries []ReplayEntry
+}
+
+// LoadReplayFile 读取 --failed-output 导出的 JSONL 重放文件；文件中的条目必须来自同一个任务。
+func LoadReplayFile(path string) (*ReplaySource, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取重放文件失败: %w", err)
+	}
+	defer f.Close()
+
+	var entries []ReplayEntry
+	var parseErr error
+	if err := readLines(f, func(lineNo int, line string) {
+		if parseErr != nil {
+			return
+		}
+		var e ReplayEntry
+		if err := json.Unmarshal([]byte(line), &e); err != nil {
+			parseErr = fmt.Errorf("重放文件第 %d 行解析失败: %w", lineNo, err)
+			return
+		}
+		if e.Prompt == "" {
+			parseErr = fmt.Errorf("重放文件第 %d 行缺少 prompt", lineNo)
+			return
+		}
+		if len(entries) > 0 && e.TaskID != entries[0].TaskID {
+			parseErr = fmt.Errorf("重放文件第 %d 行的 task_id %q 与第一行 %q 不同，一个文件只能重放一个任务", lineNo, e.TaskID, entries[0].TaskID)
+			return
+		}
+		entries = append(entries, e)
+	}); err != nil {
+		return nil, fmt.Errorf("读取重放文件失败: %w", err)
+	}
+	if parseErr != nil {
+		return nil, parseErr
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("重放文件 %s 中没有请求", path)
+	}
+	return &ReplaySource{Path: path, Entries: entries}, nil
+}
+
+// GetSystemContent 返回第一条记录的系统消息（同一任务的系统消息相同）。
+func (s *ReplaySource) GetSystemContent() string {
+	return s.Entries[0].System
+}
+
+// GetRandomContent 随机返回一条 prompt
+func (s *ReplaySource) GetRandomContent() string {
+	return s.Entries[rand.Intn(len(s.Entries))].Prompt
+}
+
+// GetContentByIndex 返回第 index 条 prompt（超出条数时取模循环）。prompt 导出时已展开占位符，这里原样返回。
+func (s *ReplaySource) GetContentByIndex(index int) string {
+	return s.entry(index).Prompt
+}
+
+// Count 返回重放的请求数
+func (s *ReplaySource) Count() int {
+	return len(s.Entries)
+}
+
+// OriginTaskID 返回被重放的原任务 ID
+func (s *ReplaySource) OriginTaskID() string {
+	return s.Entries[0].TaskID
+}
+
+// OriginIndex 返回第 index 条 prompt 在原运行中的请求序号
+func (s *ReplaySource) OriginIndex(index int) int {
+	return s.entry(index).Index
+}
+
+func (s *ReplaySource) entry(index int) ReplayEntry {
+	if index < 0 {
+		index = 0
+	}
+	return s.Entries[index%len(s.Entries)]
+}
@@ -0,0 +1,60 @@
+package prompt
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReplayFileRoundTrip(t *testing.T) {
+	entries := []ReplayEntry{
+		{TaskID: "task-1", RunID: "run-1", Index: 7, System: "sys", Prompt: "a <b> & c", Error: "HTTP 502"},
+		{TaskID: "task-1", RunID: "run-1", Index: 12, System: "sys", Prompt: "second"},
+	}
+	var buf bytes.Buffer
+	if err := WriteReplayEntries(&buf, entries); err != nil {
+		t.Fatalf("WriteReplayEntries: %v", err)
+	}
+	if !strings.Contains(buf.String(), "a <b> & c") {
+		t.Errorf("prompt should not be HTML-escaped:\n%s", buf.String())
+	}
+	path := filepath.Join(t.TempDir(), "failed.jsonl")
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	src, err := LoadReplayFile(path)
+	if err != nil {
+		t.Fatalf("LoadReplayFile: %v", err)
+	}
+	if src.Count() != 2 || src.OriginTaskID() != "task-1" || src.GetSystemContent() != "sys" {
+		t.Errorf("source = %+v", src)
+	}
+	if src.GetContentByIndex(1) != "second" || src.OriginIndex(1) != 12 || src.OriginIndex(2) != 7 {
+		t.Errorf("index 1 = %q / #%d, index 2 = #%d", src.GetContentByIndex(1), src.OriginIndex(1), src.OriginIndex(2))
+	}
+}
+
+func TestLoadReplayFile_Invalid(t *testing.T) {
+	dir := t.TempDir()
+	cases := map[string]string{
+		"empty":       "\n",
+		"bad json":    "{not json}\n",
+		"no prompt":   `{"task_id":"t","index":0}` + "\n",
+		"mixed tasks": `{"task_id":"a","index":0,"prompt":"x"}` + "\n" + `{"task_id":"b","index":1,"prompt":"y"}` + "\n",
+	}
+	for name, content := range cases {
+		path := filepath.Join(dir, strings.ReplaceAll(name, " ", "_")+".jsonl")
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadReplayFile(path); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+	if _, err := LoadReplayFile(filepath.Join(dir, "missing.jsonl")); err == nil {
+		t.Error("missing file: expected error")
+	}
+}
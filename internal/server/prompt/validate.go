@@ -0,0 +1,163 @@
+package prompt
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// DefaultMaxFileSize 校验时单个 prompt 文件的默认大小上限（1 MiB）
+const DefaultMaxFileSize = 1 << 20
+
+// FileIssue 单个 prompt 文件的校验问题
+type FileIssue struct {
+	Path    string
+	Problem string
+}
+
+// FileStats 通过校验的 prompt 文件的长度分布（字符数与估算 token 数）
+type FileStats struct {
+	Files     int // 匹配到的文件数（逐行校验时为非空行数）
+	Valid     int // 通过校验的文件数（逐行校验时为行数）
+	MinChars  int
+	MaxChars  int
+	AvgChars  float64
+	MinTokens int
+	MaxTokens int
+	AvgTokens float64
+
+	totalChars  int
+	totalTokens int
+}
+
+// add 把一条通过校验的 prompt 计入长度分布
+func (s *FileStats) add(content string) {
+	chars, tokens := utf8.RuneCountInString(content), EstimateTokens(content)
+	if s.Valid == 0 || chars < s.MinChars {
+		s.MinChars = chars
+	}
+	if chars > s.MaxChars {
+		s.MaxChars = chars
+	}
+	if s.Valid == 0 || tokens < s.MinTokens {
+		s.MinTokens = tokens
+	}
+	if tokens > s.MaxTokens {
+		s.MaxTokens = tokens
+	}
+	s.Valid++
+	s.totalChars += chars
+	s.totalTokens += tokens
+	s.AvgChars = float64(s.totalChars) / float64(s.Valid)
+	s.AvgTokens = float64(s.totalTokens) / float64(s.Valid)
+}
+
+// FileValidation prompt 文件校验结果
+type FileValidation struct {
+	Stats  FileStats
+	Issues []FileIssue
+}
+
+// ValidateFiles 离线校验 pathPattern（单文件或通配符）匹配到的 prompt 文件：
+// 文件可读、非空、不超过 maxSize 字节（<= 0 时使用 DefaultMaxFileSize）、UTF-8 编码合法。
+// 没有匹配到文件时返回错误。
+func ValidateFiles(pathPattern string, maxSize int64) (*FileValidation, error) {
+	source, err := LoadPromptsFromFile(pathPattern)
+	if err != nil {
+		return nil, err
+	}
+	if maxSize <= 0 {
+		maxSize = DefaultMaxFileSize
+	}
+
+	result := &FileValidation{Stats: FileStats{Files: len(source.FilePaths)}}
+	for _, path := range source.FilePaths {
+		content, problem := checkFile(path, maxSize)
+		if problem != "" {
+			result.Issues = append(result.Issues, FileIssue{Path: path, Problem: problem})
+			continue
+		}
+		result.Stats.add(content)
+	}
+	return result, nil
+}
+
+// ValidateLines 按 LoadPromptsFromLines 的规则逐行校验 r 中的 prompt：
+// 每个非空行不超过 maxSize 字节（<= 0 时使用 DefaultMaxFileSize）且 UTF-8 编码合法。
+// 问题以 "<name>:<行号>" 定位。
+func ValidateLines(r io.Reader, name string, maxSize int64) (*FileValidation, error) {
+	if maxSize <= 0 {
+		maxSize = DefaultMaxFileSize
+	}
+
+	result := &FileValidation{}
+	err := readLines(r, func(lineNo int, line string) {
+		result.Stats.Files++
+		path := fmt.Sprintf("%s:%d", name, lineNo)
+		switch {
+		case int64(len(line)) > maxSize:
+			result.Issues = append(result.Issues, FileIssue{Path: path, Problem: fmt.Sprintf("行过长: %d 字节，上限 %d 字节", len(line), maxSize)})
+		case !utf8.ValidString(line):
+			result.Issues = append(result.Issues, FileIssue{Path: path, Problem: fmt.Sprintf("UTF-8 编码不合法（首个非法字节位于偏移 %d）", invalidUTF8Offset([]byte(line)))})
+		default:
+			result.Stats.add(line)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	if result.Stats.Files == 0 {
+		return nil, fmt.Errorf("没有读取到非空的 prompt 行")
+	}
+	return result, nil
+}
+
+// checkFile 读取并检查单个文件，返回文件内容与问题描述（无问题时为空）。
+func checkFile(path string, maxSize int64) (string, string) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", fmt.Sprintf("无法读取: %v", err)
+	}
+	if info.Size() > maxSize {
+		return "", fmt.Sprintf("文件过大: %d 字节，上限 %d 字节", info.Size(), maxSize)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Sprintf("无法读取: %v", err)
+	}
+	if strings.TrimSpace(string(data)) == "" {
+		return "", "空文件"
+	}
+	if !utf8.Valid(data) {
+		return "", fmt.Sprintf("UTF-8 编码不合法（首个非法字节位于偏移 %d）", invalidUTF8Offset(data))
+	}
+	return string(data), ""
+}
+
+func invalidUTF8Offset(data []byte) int {
+	for i := 0; i < len(data); {
+		r, size := utf8.DecodeRune(data[i:])
+		if r == utf8.RuneError && size <= 1 {
+			return i
+		}
+		i += size
+	}
+	return -1
+}
+
+// EstimateTokens 粗略估算文本的 token 数：CJK 字符按每字 1 个 token，其余字符按每 4 个 1 个 token。
+// 仅用于离线统计，与具体模型的分词结果可能存在偏差。
+func EstimateTokens(text string) int {
+	cjk, other := 0, 0
+	for _, r := range text {
+		if unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul) {
+			cjk++
+		} else {
+			other++
+		}
+	}
+	return cjk + (other+3)/4
+}
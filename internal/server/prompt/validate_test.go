@@ -0,0 +1,76 @@
+package prompt
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writePromptFiles(t *testing.T, files map[string][]byte) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), content, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestValidateFiles(t *testing.T) {
+	dir := writePromptFiles(t, map[string][]byte{
+		"a.txt":     []byte("hello world"),
+		"b.txt":     []byte("你好世界"),
+		"empty.txt": []byte("  \n"),
+		"bad.txt":   {'o', 'k', 0xff, 0xfe},
+		"big.txt":   []byte(strings.Repeat("x", 64)),
+	})
+
+	result, err := ValidateFiles(filepath.Join(dir, "*.txt"), 32)
+	if err != nil {
+		t.Fatalf("ValidateFiles: %v", err)
+	}
+	if result.Stats.Files != 5 || result.Stats.Valid != 2 {
+		t.Fatalf("stats = %+v, want 5 files / 2 valid", result.Stats)
+	}
+
+	problems := make(map[string]string)
+	for _, issue := range result.Issues {
+		problems[filepath.Base(issue.Path)] = issue.Problem
+	}
+	for name, want := range map[string]string{"empty.txt": "空文件", "bad.txt": "偏移 2", "big.txt": "文件过大"} {
+		if !strings.Contains(problems[name], want) {
+			t.Errorf("%s problem = %q, want mention of %q", name, problems[name], want)
+		}
+	}
+
+	s := result.Stats
+	if s.MinChars != 4 || s.MaxChars != 11 || s.AvgChars != 7.5 {
+		t.Errorf("char stats = %d/%d/%.1f, want 4/11/7.5", s.MinChars, s.MaxChars, s.AvgChars)
+	}
+	if s.MinTokens != 3 || s.MaxTokens != 4 {
+		t.Errorf("token stats = %d/%d, want 3/4", s.MinTokens, s.MaxTokens)
+	}
+}
+
+func TestValidateFiles_NoMatch(t *testing.T) {
+	if _, err := ValidateFiles(filepath.Join(t.TempDir(), "*.txt"), 0); err == nil {
+		t.Fatal("expected error when no files match")
+	}
+}
+
+func TestEstimateTokens(t *testing.T) {
+	tests := map[string]int{
+		"":       0,
+		"abcd":   1,
+		"abcde":  2,
+		"你好":     2,
+		"你好 abc": 3,
+	}
+	for text, want := range tests {
+		if got := EstimateTokens(text); got != want {
+			t.Errorf("EstimateTokens(%q) = %d, want %d", text, got, want)
+		}
+	}
+}
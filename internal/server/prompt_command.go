@@ -0,0 +1,44 @@
+package server
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/yinxulai/ait/internal/server/prompt"
+	"github.com/yinxulai/ait/internal/server/types"
+)
+
+// promptCommandConfig 进程级的 prompt 命令配置
+type promptCommandConfig struct {
+	command  string
+	timeout  time.Duration
+	maxProcs int
+}
+
+var processPromptCommand atomic.Pointer[promptCommandConfig]
+
+// SetPromptCommand 设置本进程每个请求前执行的 prompt 生成命令，通常在启动时由 --prompt-command /
+// --prompt-command-timeout / --prompt-command-procs 设置；command 为空表示关闭。
+// timeout 为单次执行的超时，maxProcs 为同时执行的命令数上限，<= 0 时分别使用默认值与 CPU 核数。
+func SetPromptCommand(command string, timeout time.Duration, maxProcs int) {
+	if command == "" {
+		processPromptCommand.Store(nil)
+		return
+	}
+	processPromptCommand.Store(&promptCommandConfig{command: command, timeout: timeout, maxProcs: maxProcs})
+}
+
+// applyProcessPromptCommand 设置了 --prompt-command 时以命令输出代替任务的 prompt 配置（raw 模式下输出作为原始请求体）；
+// 重放运行保持重放文件中的 prompt。每次运行使用独立的命令来源。
+func applyProcessPromptCommand(input *types.Input) error {
+	c := processPromptCommand.Load()
+	if c == nil || input.ReplayFile != "" {
+		return nil
+	}
+	source, err := prompt.NewCommandSource(c.command, c.timeout, c.maxProcs)
+	if err != nil {
+		return err
+	}
+	input.PromptSource = source
+	return nil
+}
@@ -0,0 +1,37 @@
+package server
+
+import (
+	"sync/atomic"
+
+	"github.com/yinxulai/ait/internal/server/prompt"
+	"github.com/yinxulai/ait/internal/server/types"
+)
+
+var processPromptFilePerRun atomic.Bool
+
+// SetPromptFilePerRun 设置本进程是否对 prompt 文件匹配多个文件的标准运行按文件分场景执行，
+// 通常在启动时由 --prompt-file-per-run 设置；开启后等同于为任务打开 prompt_file_per_run。
+func SetPromptFilePerRun(enabled bool) {
+	processPromptFilePerRun.Store(enabled)
+}
+
+// promptFileScenarios 返回该运行按 prompt 文件拆分的场景：任务或进程开启了分场景运行、运行为标准模式，
+// 且 prompt 来源是匹配到多个文件的文件来源时才拆分，否则返回 nil。A/B 对比、长度扫描、重放与确定性验证
+// 自成多轮或固定 prompt，--prompt-command 替换了 prompt 来源，均保持原样。
+func promptFileScenarios(input types.Input) []prompt.FileScenario {
+	if !input.PromptFilePerRun && !processPromptFilePerRun.Load() {
+		return nil
+	}
+	if input.RunMode() != "standard" || input.PromptMode != "file" || input.CompareStream || input.CompareHTTPVersion ||
+		len(input.InputLengthSweep) > 0 || input.ReplayFile != "" || input.ConsistencyCheck {
+		return nil
+	}
+	source, ok := input.PromptSource.(*prompt.PromptSource)
+	if !ok {
+		return nil
+	}
+	if scenarios := source.FileScenarios(); len(scenarios) > 1 {
+		return scenarios
+	}
+	return nil
+}
@@ -0,0 +1,314 @@
+// Package ratelimit 提供自适应限流控制器：遇到供应商限流时降低发送速率，恢复后逐步回升。
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/yinxulai/ait/internal/server/clock"
+	"github.com/yinxulai/ait/internal/server/types"
+)
+
+// 速率变化原因
+const (
+	ReasonThrottle  = "throttle"
+	ReasonRecover   = "recover"
+	ReasonRateLimit = "ratelimit"
+)
+
+// Config 自适应限流参数，零值字段使用默认值。
+type Config struct {
+	Decrease     float64       // 限流时的乘性降速系数，默认 0.5
+	Increase     float64       // 恢复期每秒加性提升的速率（req/s），默认 1
+	MinRate      float64       // 速率下限（req/s），默认 0.2
+	BaseBackoff  time.Duration // 无 Retry-After 时的初始退避时长，默认 1s，连续限流时指数增长
+	MaxBackoff   time.Duration // 退避时长上限，默认 30s
+	StableWindow time.Duration // 无限流持续该时长后的速率才计为稳定速率，默认 5s
+	MaxRetries   int           // 单个请求因限流的最大重试次数，默认 5
+	LowRemaining float64       // 响应头剩余配额占总量的比例低于该值时主动降速，默认 0.1
+}
+
+func (c Config) withDefaults() Config {
+	if c.Decrease <= 0 || c.Decrease >= 1 {
+		c.Decrease = 0.5
+	}
+	if c.Increase <= 0 {
+		c.Increase = 1
+	}
+	if c.MinRate <= 0 {
+		c.MinRate = 0.2
+	}
+	if c.BaseBackoff <= 0 {
+		c.BaseBackoff = time.Second
+	}
+	if c.MaxBackoff <= 0 {
+		c.MaxBackoff = 30 * time.Second
+	}
+	if c.StableWindow <= 0 {
+		c.StableWindow = 5 * time.Second
+	}
+	if c.MaxRetries <= 0 {
+		c.MaxRetries = 5
+	}
+	if c.LowRemaining <= 0 || c.LowRemaining >= 1 {
+		c.LowRemaining = 0.1
+	}
+	return c
+}
+
+// Adaptive AIMD 风格的发送速率控制器。
+//
+// 初始不限速；收到限流响应时按 Retry-After（或指数退避）暂停发送，并把速率乘性降低；
+// 之后每个成功请求让速率加性回升（约每秒提升 Config.Increase req/s）。
+// 同一退避窗口内并发到达的多个限流响应只降速一次。
+//
+// 状态迁移由 Reserve / OnThrottle / OnSuccess 驱动，均以调用方传入的时间为准，便于单测。
+type Adaptive struct {
+	cfg Config
+
+	mu           sync.Mutex
+	start        time.Time
+	rate         float64 // 当前限速（req/s），0 表示未限速
+	nextSend     time.Time
+	pausedUntil  time.Time
+	backoffLevel int // 连续限流次数，决定退避时长
+	lastThrottle time.Time
+	lastSend     time.Time
+	firstSend    time.Time
+	sends        int           // 已占用的发送时隙数，尚无发送间隔可用时据此估计速率
+	gapEWMA      time.Duration // 发送间隔的指数滑动平均，用于估计未限速时的实际速率
+	lastRecord   time.Time
+	stats        types.AdaptiveStats
+}
+
+// NewAdaptive 创建控制器，start 为运行开始时间（时间线偏移的基准）。
+func NewAdaptive(cfg Config, start time.Time) *Adaptive {
+	return &Adaptive{cfg: cfg.withDefaults(), start: start}
+}
+
+// MaxRetries 返回单个请求因限流的最大重试次数。
+func (a *Adaptive) MaxRetries() int {
+	return a.cfg.MaxRetries
+}
+
+// Reserve 占用下一个发送时隙，返回需要等待的时长（不阻塞）。
+func (a *Adaptive) Reserve(now time.Time) time.Duration {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	at := now
+	if a.pausedUntil.After(at) {
+		at = a.pausedUntil
+	}
+	if a.rate > 0 {
+		if a.nextSend.After(at) {
+			at = a.nextSend
+		}
+		a.nextSend = at.Add(rateInterval(a.rate))
+	}
+
+	if !a.lastSend.IsZero() && at.After(a.lastSend) {
+		gap := at.Sub(a.lastSend)
+		if a.gapEWMA == 0 {
+			a.gapEWMA = gap
+		} else {
+			a.gapEWMA = (a.gapEWMA*4 + gap) / 5
+		}
+	}
+	if at.After(a.lastSend) {
+		a.lastSend = at
+	}
+	if a.sends == 0 {
+		a.firstSend = at
+	}
+	a.sends++
+	return at.Sub(now)
+}
+
+// Wait 按时间源 c 阻塞直到允许发送下一个请求，ctx 结束时返回其错误。
+func (a *Adaptive) Wait(ctx context.Context, c clock.Clock) error {
+	d := a.Reserve(c.Now())
+	if d <= 0 {
+		return ctx.Err()
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-c.After(d):
+		return nil
+	}
+}
+
+// OnThrottle 记录一次限流响应：暂停发送 retryAfter（为 0 时按指数退避），并乘性降速。
+func (a *Adaptive) OnThrottle(now time.Time, retryAfter time.Duration) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.stats.ThrottledResponses++
+	a.lastThrottle = now
+
+	if now.Before(a.pausedUntil) {
+		// 同一退避窗口内的并发限流响应只按 Retry-After 延长暂停，不重复降速
+		if until := now.Add(retryAfter); retryAfter > 0 && until.After(a.pausedUntil) {
+			a.stats.ThrottleLostTime += until.Sub(a.pausedUntil)
+			a.pausedUntil = until
+		}
+		return
+	}
+
+	pause := retryAfter
+	if pause <= 0 {
+		pause = a.cfg.BaseBackoff << a.backoffLevel
+		if pause > a.cfg.MaxBackoff || pause <= 0 {
+			pause = a.cfg.MaxBackoff
+		}
+		a.backoffLevel++
+	}
+	a.pausedUntil = now.Add(pause)
+	a.stats.ThrottleLostTime += pause
+
+	current := a.rate
+	if current == 0 {
+		current = a.measuredRateLocked()
+	}
+	rate := current * a.cfg.Decrease
+	if rate < a.cfg.MinRate {
+		rate = a.cfg.MinRate
+	}
+	a.rate = rate
+	a.nextSend = a.pausedUntil
+	a.recordLocked(now, ReasonThrottle)
+}
+
+// OnSuccess 记录一次成功响应：重置退避并加性提升速率。
+func (a *Adaptive) OnSuccess(now time.Time) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.backoffLevel = 0
+	if a.rate == 0 {
+		return
+	}
+	if !a.lastThrottle.IsZero() && now.Sub(a.lastThrottle) >= a.cfg.StableWindow && a.rate > a.stats.MaxStableRate {
+		a.stats.MaxStableRate = a.rate
+	}
+	// 每个成功请求提升 Increase/rate，按当前速率发送时约每秒提升 Increase
+	a.rate += a.cfg.Increase / a.rate
+	if now.Sub(a.lastRecord) >= time.Second {
+		a.recordLocked(now, ReasonRecover)
+	}
+}
+
+// OnRateLimit 根据响应头中的请求配额主动降速，在真正被限流前避开 429：
+// 剩余配额为 0 时暂停发送到窗口重置（未给出重置时间时按初始退避时长）；剩余配额低于总量的
+// LowRemaining 时，把速率降到恰好在窗口重置前用完剩余配额（remaining / reset）。
+// 只降不升，回升仍由成功请求驱动。limit、reset 为 0 表示响应头未给出。
+func (a *Adaptive) OnRateLimit(now time.Time, remaining, limit int, reset time.Duration) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if remaining <= 0 {
+		pause := reset
+		if pause <= 0 {
+			pause = a.cfg.BaseBackoff
+		}
+		if pause > a.cfg.MaxBackoff {
+			pause = a.cfg.MaxBackoff
+		}
+		until := now.Add(pause)
+		if !until.After(a.pausedUntil) {
+			return // 并发响应报告的是同一个已在暂停中的窗口
+		}
+		a.stats.ThrottleLostTime += until.Sub(maxTime(now, a.pausedUntil))
+		a.pausedUntil = until
+		if a.nextSend.Before(until) {
+			a.nextSend = until
+		}
+		a.stats.RateLimitSlowdowns++
+		a.recordLocked(now, ReasonRateLimit)
+		return
+	}
+	if limit <= 0 || reset <= 0 || float64(remaining) >= float64(limit)*a.cfg.LowRemaining {
+		return
+	}
+
+	rate := float64(remaining) / reset.Seconds()
+	if rate < a.cfg.MinRate {
+		rate = a.cfg.MinRate
+	}
+	current := a.rate
+	if current == 0 {
+		current = a.measuredRateLocked()
+	}
+	if current > 0 && rate >= current {
+		return
+	}
+	a.rate = rate
+	a.stats.RateLimitSlowdowns++
+	a.recordLocked(now, ReasonRateLimit)
+}
+
+// RecordRetry 记录一次因限流发起的重试。
+func (a *Adaptive) RecordRetry() {
+	a.mu.Lock()
+	a.stats.Retries++
+	a.mu.Unlock()
+}
+
+// Rate 返回当前限速（req/s），0 表示未限速。
+func (a *Adaptive) Rate() float64 {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.rate
+}
+
+// Stats 返回统计结果的拷贝。从未限流时最大稳定速率取实测发送速率。
+func (a *Adaptive) Stats() *types.AdaptiveStats {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	stats := a.stats
+	stats.FinalRate = a.rate
+	if stats.ThrottledResponses == 0 {
+		stats.MaxStableRate = a.measuredRateLocked()
+	}
+	stats.Timeline = append([]types.RateChange(nil), a.stats.Timeline...)
+	return &stats
+}
+
+// measuredRateLocked 根据发送间隔估计实际发送速率（调用方须持有 mu）。
+// 还没有发送间隔（只发过一个请求，或所有请求同时发出）时按已发送数除以发送跨度估计，跨度不足 1s 按 1s 计，
+// 避免首次限流时因无实测速率而直接降到下限。
+func (a *Adaptive) measuredRateLocked() float64 {
+	if a.gapEWMA > 0 {
+		return float64(time.Second) / float64(a.gapEWMA)
+	}
+	if a.sends == 0 {
+		return 0
+	}
+	span := a.lastSend.Sub(a.firstSend)
+	if span < time.Second {
+		span = time.Second
+	}
+	return float64(a.sends) / span.Seconds()
+}
+
+func (a *Adaptive) recordLocked(now time.Time, reason string) {
+	a.lastRecord = now
+	a.stats.Timeline = append(a.stats.Timeline, types.RateChange{
+		Offset: now.Sub(a.start),
+		Rate:   a.rate,
+		Reason: reason,
+	})
+}
+
+func rateInterval(rate float64) time.Duration {
+	return time.Duration(float64(time.Second) / rate)
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
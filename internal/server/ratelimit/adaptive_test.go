@@ -0,0 +1,218 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/yinxulai/ait/internal/server/clock"
+)
+
+var t0 = time.Unix(1000, 0)
+
+// sendEvery 以固定间隔占用 n 个时隙，用于建立实测发送速率。
+func sendEvery(a *Adaptive, from time.Time, gap time.Duration, n int) time.Time {
+	now := from
+	for i := 0; i < n; i++ {
+		a.Reserve(now)
+		now = now.Add(gap)
+	}
+	return now
+}
+
+func TestAdaptive_UnlimitedUntilThrottled(t *testing.T) {
+	a := NewAdaptive(Config{}, t0)
+	for i := 0; i < 5; i++ {
+		if d := a.Reserve(t0.Add(time.Duration(i) * time.Millisecond)); d != 0 {
+			t.Fatalf("Reserve #%d waited %v before any throttle", i, d)
+		}
+	}
+	if a.Rate() != 0 {
+		t.Errorf("Rate = %v, want 0 (unlimited)", a.Rate())
+	}
+}
+
+func TestAdaptive_ThrottleHonorsRetryAfterAndHalvesRate(t *testing.T) {
+	a := NewAdaptive(Config{}, t0)
+	now := sendEvery(a, t0, 100*time.Millisecond, 10) // 实测约 10 req/s
+
+	a.OnThrottle(now, 3*time.Second)
+	if got := a.Rate(); math.Abs(got-5) > 0.01 {
+		t.Fatalf("Rate after throttle = %v, want 5", got)
+	}
+	if d := a.Reserve(now); d != 3*time.Second {
+		t.Errorf("first send after throttle waits %v, want 3s (Retry-After)", d)
+	}
+	// 之后按 5 req/s 的间隔排队
+	if d := a.Reserve(now); d != 3*time.Second+200*time.Millisecond {
+		t.Errorf("second send waits %v, want 3.2s", d)
+	}
+
+	stats := a.Stats()
+	if stats.ThrottledResponses != 1 || stats.ThrottleLostTime != 3*time.Second {
+		t.Errorf("stats = %+v", stats)
+	}
+	if len(stats.Timeline) != 1 || stats.Timeline[0].Reason != ReasonThrottle || stats.Timeline[0].Offset != now.Sub(t0) {
+		t.Errorf("timeline = %+v", stats.Timeline)
+	}
+}
+
+func TestAdaptive_FirstThrottleWithoutMeasuredGap(t *testing.T) {
+	a := NewAdaptive(Config{}, t0)
+	// 10 个并发请求同时发出，没有可用的发送间隔
+	for i := 0; i < 10; i++ {
+		a.Reserve(t0)
+	}
+	a.OnThrottle(t0.Add(200*time.Millisecond), time.Second)
+	if got := a.Rate(); math.Abs(got-5) > 0.01 {
+		t.Errorf("Rate after first throttle = %v, want 5 (half of 10 sends in 1s), not MinRate", got)
+	}
+}
+
+func TestAdaptive_ConcurrentThrottlesDecreaseOnce(t *testing.T) {
+	a := NewAdaptive(Config{}, t0)
+	now := sendEvery(a, t0, 100*time.Millisecond, 10)
+
+	a.OnThrottle(now, time.Second)
+	a.OnThrottle(now.Add(10*time.Millisecond), time.Second)
+	a.OnThrottle(now.Add(20*time.Millisecond), 0)
+
+	if got := a.Rate(); math.Abs(got-5) > 0.01 {
+		t.Errorf("Rate = %v, want 5 (decreased once)", got)
+	}
+	stats := a.Stats()
+	if stats.ThrottledResponses != 3 {
+		t.Errorf("ThrottledResponses = %d, want 3", stats.ThrottledResponses)
+	}
+	// 第二个响应把暂停延长 10ms，第三个无 Retry-After 不延长
+	if stats.ThrottleLostTime != time.Second+10*time.Millisecond {
+		t.Errorf("ThrottleLostTime = %v, want 1.01s", stats.ThrottleLostTime)
+	}
+}
+
+func TestAdaptive_ExponentialBackoffWithoutRetryAfter(t *testing.T) {
+	a := NewAdaptive(Config{BaseBackoff: time.Second, MaxBackoff: 3 * time.Second}, t0)
+	now := t0
+	want := []time.Duration{time.Second, 2 * time.Second, 3 * time.Second, 3 * time.Second}
+	for i, w := range want {
+		a.OnThrottle(now, 0)
+		if d := a.Reserve(now); d < w {
+			t.Fatalf("throttle #%d: wait %v, want >= %v", i, d, w)
+		}
+		now = now.Add(10 * time.Second) // 退避结束后再次被限流
+	}
+
+	// 成功请求重置退避
+	a.OnSuccess(now)
+	a.OnThrottle(now, 0)
+	if d := a.Reserve(now); d != time.Second {
+		t.Errorf("backoff after success = %v, want 1s", d)
+	}
+}
+
+func TestAdaptive_AdditiveRecoveryAndStableRate(t *testing.T) {
+	a := NewAdaptive(Config{MinRate: 1, StableWindow: 5 * time.Second}, t0)
+	a.OnThrottle(t0, time.Second) // 无实测速率，降到下限 1 req/s
+	if a.Rate() != 1 {
+		t.Fatalf("Rate = %v, want MinRate 1", a.Rate())
+	}
+
+	now := t0.Add(2 * time.Second)
+	a.OnSuccess(now)
+	if got := a.Rate(); got != 2 {
+		t.Fatalf("Rate after one success = %v, want 2", got)
+	}
+	if a.Stats().MaxStableRate != 0 {
+		t.Error("rate should not count as stable within StableWindow")
+	}
+
+	now = t0.Add(6 * time.Second)
+	a.OnSuccess(now)
+	stats := a.Stats()
+	if stats.MaxStableRate != 2 {
+		t.Errorf("MaxStableRate = %v, want 2", stats.MaxStableRate)
+	}
+	if stats.FinalRate != 2.5 {
+		t.Errorf("FinalRate = %v, want 2.5", stats.FinalRate)
+	}
+	if last := stats.Timeline[len(stats.Timeline)-1]; last.Reason != ReasonRecover {
+		t.Errorf("last timeline entry = %+v, want recover", last)
+	}
+}
+
+func TestAdaptive_StatsWithoutThrottleUsesMeasuredRate(t *testing.T) {
+	a := NewAdaptive(Config{}, t0)
+	sendEvery(a, t0, 250*time.Millisecond, 8)
+	if got := a.Stats().MaxStableRate; math.Abs(got-4) > 0.01 {
+		t.Errorf("MaxStableRate = %v, want ~4 req/s", got)
+	}
+}
+
+func TestAdaptive_WaitRespectsContext(t *testing.T) {
+	fake := clock.NewFake(t0)
+	a := NewAdaptive(Config{}, t0)
+	a.OnThrottle(t0, time.Hour)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go func() { errCh <- a.Wait(ctx, fake) }()
+	fake.BlockUntil(1)
+	cancel()
+	if err := <-errCh; err == nil {
+		t.Fatal("Wait should return context error while paused")
+	}
+}
+
+func TestAdaptive_WaitUntilPauseEnds(t *testing.T) {
+	fake := clock.NewFake(t0)
+	a := NewAdaptive(Config{}, t0)
+	a.OnThrottle(t0, 3*time.Second)
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- a.Wait(context.Background(), fake) }()
+	fake.BlockUntil(1)
+	fake.Advance(3 * time.Second)
+	if err := <-errCh; err != nil {
+		t.Fatalf("Wait: %v", err)
+	}
+}
+
+func TestAdaptive_RateLimitHeaders(t *testing.T) {
+	a := NewAdaptive(Config{}, t0)
+	now := sendEvery(a, t0, 100*time.Millisecond, 10) // 实测约 10 req/s
+
+	// 剩余配额充足时不降速
+	a.OnRateLimit(now, 50, 100, 10*time.Second)
+	if a.Rate() != 0 {
+		t.Fatalf("Rate with plenty remaining = %v, want 0 (unlimited)", a.Rate())
+	}
+
+	// 剩余 5/100、10s 后重置：速率降到 0.5 req/s
+	a.OnRateLimit(now, 5, 100, 10*time.Second)
+	if got := a.Rate(); math.Abs(got-0.5) > 0.01 {
+		t.Fatalf("Rate with low remaining = %v, want 0.5", got)
+	}
+	// 只降不升
+	a.OnRateLimit(now, 8, 100, 10*time.Second)
+	if got := a.Rate(); math.Abs(got-0.5) > 0.01 {
+		t.Errorf("Rate should not increase from headers, got %v", got)
+	}
+
+	// 配额耗尽时暂停到窗口重置，速率不变
+	a.OnRateLimit(now, 0, 100, 4*time.Second)
+	if d := a.Reserve(now); d < 4*time.Second {
+		t.Errorf("Reserve after exhausted quota waited %v, want >= 4s", d)
+	}
+	if got := a.Rate(); math.Abs(got-0.5) > 0.01 {
+		t.Errorf("Rate after exhausted quota = %v, want unchanged 0.5", got)
+	}
+
+	stats := a.Stats()
+	if stats.RateLimitSlowdowns != 2 || stats.ThrottledResponses != 0 {
+		t.Errorf("stats = %+v, want 2 header slowdowns and no throttled responses", stats)
+	}
+	if n := len(stats.Timeline); n != 2 || stats.Timeline[n-1].Reason != ReasonRateLimit {
+		t.Errorf("timeline = %+v, want two ratelimit entries", stats.Timeline)
+	}
+}
@@ -0,0 +1,183 @@
+// Package redact 在写日志、上报遥测与生成报告前抹去文本中的敏感信息（--redact）。
+package redact
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync/atomic"
+)
+
+// Placeholder 敏感片段被替换成的文本
+const Placeholder = "[REDACTED]"
+
+// BuiltinPatterns 内置的敏感信息模式：中国大陆手机号（可带 +86）、邮箱、18 位身份证号与 16~19 位银行卡号。
+// \b 只把 ASCII 字母数字视为单词字符，中文与数字相邻处同样是边界，中英文混排时也能匹配。
+var BuiltinPatterns = []string{
+	`\b(?:\+?86[- ]?)?1[3-9]\d{9}\b`,
+	`[A-Za-z0-9._%+-]+@[A-Za-z0-9-]+(?:\.[A-Za-z0-9-]+)*\.[A-Za-z]{2,}`,
+	`\b[1-9]\d{5}(?:18|19|20)\d{2}(?:0[1-9]|1[0-2])(?:0[1-9]|[12]\d|3[01])\d{3}[\dXx]\b`,
+	`\b[3-6]\d{15,18}\b`,
+}
+
+// Redactor 按一组正则抹去文本中的敏感片段。nil 的 Redactor 原样返回文本。
+type Redactor struct {
+	rules []*regexp.Regexp
+}
+
+// New 以内置模式加上 extra 中的自定义正则创建 Redactor；自定义正则无法编译时返回错误。
+func New(extra []string) (*Redactor, error) {
+	r := &Redactor{}
+	for _, pattern := range BuiltinPatterns {
+		r.rules = append(r.rules, regexp.MustCompile(pattern))
+	}
+	for i, pattern := range extra {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("第 %d 条脱敏规则 %q 无效: %w", i+1, pattern, err)
+		}
+		r.rules = append(r.rules, re)
+	}
+	return r, nil
+}
+
+// LoadPatterns 读取自定义脱敏规则文件（--redact-patterns）：每行一条正则，忽略空行与 # 开头的注释行。
+func LoadPatterns(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取脱敏规则文件失败: %w", err)
+	}
+	defer f.Close()
+	var patterns []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		patterns = append(patterns, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("读取脱敏规则文件失败: %w", err)
+	}
+	return patterns, nil
+}
+
+// Rules 返回规则总数（内置加自定义）。
+func (r *Redactor) Rules() int {
+	if r == nil {
+		return 0
+	}
+	return len(r.rules)
+}
+
+// Redact 把各规则命中的片段替换为 Placeholder。不同规则的命中区间重叠或相邻时合并为一段，
+// 只替换一次，避免留下半截敏感信息。
+func (r *Redactor) Redact(s string) string {
+	if r == nil || s == "" {
+		return s
+	}
+	var spans [][]int
+	for _, re := range r.rules {
+		for _, loc := range re.FindAllStringIndex(s, -1) {
+			if loc[1] > loc[0] {
+				spans = append(spans, loc)
+			}
+		}
+	}
+	if len(spans) == 0 {
+		return s
+	}
+	sort.Slice(spans, func(i, j int) bool { return spans[i][0] < spans[j][0] })
+	var b strings.Builder
+	last := 0
+	for i := 0; i < len(spans); {
+		start, end := spans[i][0], spans[i][1]
+		for i++; i < len(spans) && spans[i][0] <= end; i++ {
+			end = max(end, spans[i][1])
+		}
+		b.WriteString(s[last:start])
+		b.WriteString(Placeholder)
+		last = end
+	}
+	b.WriteString(s[last:])
+	return b.String()
+}
+
+// RedactJSON 处理 JSON 文本（如请求体）：逐个字符串值脱敏后重新编码，避免转义序列（如 \n）紧挨敏感信息时漏判；
+// 不是合法 JSON 时按普通文本处理。没有命中时原样返回，否则重新编码，对象的键按字母序排列。
+func (r *Redactor) RedactJSON(s string) string {
+	if r == nil || s == "" {
+		return s
+	}
+	dec := json.NewDecoder(strings.NewReader(s))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return r.Redact(s)
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return r.Redact(s)
+	}
+	changed := false
+	v = r.redactValue(v, &changed)
+	if !changed {
+		return s
+	}
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return r.Redact(s)
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// redactValue 递归处理解码后的 JSON 值，有字符串被改写时把 changed 置为 true。
+func (r *Redactor) redactValue(v any, changed *bool) any {
+	switch v := v.(type) {
+	case string:
+		redacted := r.Redact(v)
+		if redacted != v {
+			*changed = true
+		}
+		return redacted
+	case []any:
+		for i := range v {
+			v[i] = r.redactValue(v[i], changed)
+		}
+	case map[string]any:
+		for k := range v {
+			v[k] = r.redactValue(v[k], changed)
+		}
+	}
+	return v
+}
+
+var process atomic.Pointer[Redactor]
+
+// Enable 设置本进程使用的 Redactor，通常在启动时由 --redact / --redact-patterns 设置；nil 表示关闭脱敏。
+func Enable(r *Redactor) {
+	process.Store(r)
+}
+
+// Enabled 报告本进程是否开启了脱敏。
+func Enabled() bool {
+	return process.Load() != nil
+}
+
+// String 用本进程的 Redactor 处理文本，未开启脱敏时原样返回。
+func String(s string) string {
+	return process.Load().Redact(s)
+}
+
+// JSON 用本进程的 Redactor 处理 JSON 文本，未开启脱敏时原样返回。
+func JSON(s string) string {
+	return process.Load().RedactJSON(s)
+}
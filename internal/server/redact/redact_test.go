@@ -0,0 +1,118 @@
+package redact
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRedactor_Builtin(t *testing.T) {
+	r, err := New(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name, in, want string
+	}{
+		{"phone", "call 13800138000 now", "call [REDACTED] now"},
+		{"phone with country code", "tel:+86 13800138000", "tel:+[REDACTED]"},
+		{"phone in chinese", "我的手机号是13912345678，请回电", "我的手机号是[REDACTED]，请回电"},
+		{"email", "联系 Zhang.San+ait@example.com.cn 获取", "联系 [REDACTED] 获取"},
+		{"id card", "身份证110101199003071234号", "身份证[REDACTED]号"},
+		{"id card with X", "ID: 11010119900307123X.", "ID: [REDACTED]."},
+		{"bank card", "卡号 6222021234567890123 余额", "卡号 [REDACTED] 余额"},
+		{"mixed", "name=李雷, phone=13800138000, mail=li@lei.cn", "name=李雷, phone=[REDACTED], mail=[REDACTED]"},
+		// 手机号同时是邮箱的一部分：重叠的命中合并为一段
+		{"overlap", "邮箱13800138000@163.com。", "邮箱[REDACTED]。"},
+		{"longer number is not a phone", "订单号 2138001380001 和 138001380", "订单号 2138001380001 和 138001380"},
+		{"invalid date is not an id card", "110101199013071234", "110101199013071234"},
+		{"no match", "hello 世界", "hello 世界"},
+		{"empty", "", ""},
+	}
+	for _, tt := range tests {
+		if got := r.Redact(tt.in); got != tt.want {
+			t.Errorf("%s: Redact(%q) = %q, want %q", tt.name, tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestRedactor_Custom(t *testing.T) {
+	if _, err := New([]string{"("}); err == nil || !strings.Contains(err.Error(), "第 1 条") {
+		t.Errorf("New(invalid) error = %v", err)
+	}
+	r, err := New([]string{`客户编号[:：]\s*\w+`, `sk-[A-Za-z0-9]{8,}`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Rules() != len(BuiltinPatterns)+2 {
+		t.Errorf("Rules() = %d", r.Rules())
+	}
+	in := "客户编号：C10086 的密钥 sk-abcdefgh12345 手机13800138000"
+	if got, want := r.Redact(in), "[REDACTED] 的密钥 [REDACTED] 手机[REDACTED]"; got != want {
+		t.Errorf("Redact = %q, want %q", got, want)
+	}
+	// 相邻的两段命中同样合并
+	r, _ = New([]string{`ab`, `cd`})
+	if got := r.Redact("xabcdy"); got != "x[REDACTED]y" {
+		t.Errorf("adjacent: Redact = %q", got)
+	}
+	// 空匹配的规则不应插入占位符
+	r, _ = New([]string{`x*`})
+	if got := r.Redact("abc"); got != "abc" {
+		t.Errorf("empty-match rule: Redact = %q", got)
+	}
+}
+
+func TestRedactor_RedactJSON(t *testing.T) {
+	r, _ := New(nil)
+	tests := []struct {
+		name, in, want string
+	}{
+		// 转义的换行紧挨手机号：按文本处理时 \b 不成立，解码后才能识别
+		{"escaped newline", `{"model":"m","messages":[{"role":"user","content":"姓名\n13800138000"}]}`,
+			`{"messages":[{"content":"姓名\n[REDACTED]","role":"user"}],"model":"m"}`},
+		{"numbers keep precision", `{"n":12345678901234567890,"s":"li@lei.cn <b>"}`, `{"n":12345678901234567890,"s":"[REDACTED] <b>"}`},
+		{"unchanged keeps formatting", "{\n  \"a\": \"hi\"\n}", "{\n  \"a\": \"hi\"\n}"},
+		{"not json", "data: 13800138000", "data: [REDACTED]"},
+		{"trailing content", `{"a":1} 13800138000`, `{"a":1} [REDACTED]`},
+	}
+	for _, tt := range tests {
+		if got := r.RedactJSON(tt.in); got != tt.want {
+			t.Errorf("%s: RedactJSON(%q) = %q, want %q", tt.name, tt.in, got, tt.want)
+		}
+	}
+	if got := (*Redactor)(nil).RedactJSON("13800138000"); got != "13800138000" {
+		t.Errorf("nil RedactJSON = %q", got)
+	}
+}
+
+func TestLoadPatterns(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "patterns.txt")
+	content := "# 客户编号\nC\\d{6}\n\n  sk-\\w+  \n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	patterns, err := LoadPatterns(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(patterns) != 2 || patterns[0] != `C\d{6}` || patterns[1] != `sk-\w+` {
+		t.Errorf("patterns = %q", patterns)
+	}
+	if _, err := LoadPatterns(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("LoadPatterns(missing) error = nil")
+	}
+}
+
+func TestProcessRedactor(t *testing.T) {
+	t.Cleanup(func() { Enable(nil) })
+	if Enabled() || String("13800138000") != "13800138000" {
+		t.Fatal("redaction should be off by default")
+	}
+	r, _ := New(nil)
+	Enable(r)
+	if !Enabled() || String("13800138000") != Placeholder {
+		t.Errorf("String = %q after Enable", String("13800138000"))
+	}
+}
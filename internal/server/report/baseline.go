@@ -0,0 +1,133 @@
+package report
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/yinxulai/ait/internal/server/types"
+)
+
+// DefaultRegressionThreshold 默认回退阈值（百分比）
+const DefaultRegressionThreshold = 10.0
+
+// baselineMetric 参与基线对比的指标。higherIsBetter 为 true 时数值下降视为回退；
+// samples 取该指标的逐请求样本用于显著性检验，为 nil 时不做检验。
+type baselineMetric struct {
+	name           string
+	higherIsBetter bool
+	value          func(*types.ReportData) float64
+	samples        func(*types.MetricSamples) []float64
+}
+
+var baselineMetrics = []baselineMetric{
+	{"avg_total_time", false, func(d *types.ReportData) float64 { return millis(d.AvgTotalTime) }, totalTimeSamples},
+	{"avg_ttft", false, func(d *types.ReportData) float64 { return millis(d.AvgTTFT) }, ttftSamples},
+	{"avg_tpot", false, func(d *types.ReportData) float64 { return millis(d.AvgTPOT) }, tpotSamples},
+	{"avg_tps", true, func(d *types.ReportData) float64 { return d.AvgTPS }, tpsSamples},
+	{"success_rate", true, func(d *types.ReportData) float64 { return d.SuccessRate }, nil},
+}
+
+func millis(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// BaselinePath 返回任务基线文件路径：<dir>/<任务名>.baseline.json，
+// 任务名中不适合做文件名的字符替换为 _。
+func BaselinePath(dir, taskName string) string {
+	name := strings.Map(func(r rune) rune {
+		switch r {
+		case '/', '\\', ':', '*', '?', '"', '<', '>', '|', ' ':
+			return '_'
+		}
+		return r
+	}, strings.TrimSpace(taskName))
+	if name == "" {
+		name = "task"
+	}
+	return filepath.Join(dir, name+".baseline.json")
+}
+
+// CheckBaseline 用 dir 下的任务基线检查本次结果。
+// 基线不存在时把 data 保存为基线并返回 Created=true；存在时逐项对比关键指标。
+// threshold <= 0 时使用 DefaultRegressionThreshold。
+func CheckBaseline(dir, taskName string, data *types.ReportData, threshold float64) (*types.BaselineResult, error) {
+	if threshold <= 0 {
+		threshold = DefaultRegressionThreshold
+	}
+	result := &types.BaselineResult{Path: BaselinePath(dir, taskName), Threshold: threshold}
+
+	raw, err := os.ReadFile(result.Path)
+	if errors.Is(err, fs.ErrNotExist) {
+		if err := saveBaseline(result.Path, data); err != nil {
+			return nil, err
+		}
+		result.Created = true
+		return result, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read baseline: %w", err)
+	}
+
+	var baseline types.ReportData
+	if err := json.Unmarshal(raw, &baseline); err != nil {
+		return nil, fmt.Errorf("failed to parse baseline %s: %w", result.Path, err)
+	}
+	result.Metrics = CompareWithBaseline(&baseline, data, threshold)
+	for _, m := range result.Metrics {
+		if m.Regressed {
+			result.Regressed = true
+		}
+	}
+	return result, nil
+}
+
+// CompareWithBaseline 对比本次结果与基线的关键指标。
+// 基线值为 0 的指标（如非流式的 TTFT）无法计算相对变化，直接跳过。
+func CompareWithBaseline(baseline, current *types.ReportData, threshold float64) []types.MetricDelta {
+	var deltas []types.MetricDelta
+	for _, m := range baselineMetrics {
+		base, cur := m.value(baseline), m.value(current)
+		if base == 0 {
+			continue
+		}
+		change := (cur - base) / base * 100
+		worse := change
+		if m.higherIsBetter {
+			worse = -change
+		}
+		delta := types.MetricDelta{
+			Metric:    m.name,
+			Baseline:  base,
+			Current:   cur,
+			Change:    change,
+			Regressed: worse > threshold,
+		}
+		if m.samples != nil {
+			if sig, ok := testSamples(baseline, current, m.samples); ok {
+				delta.PValue, delta.Significance, delta.LowSamples = sig.p, sig.mark, sig.lowSamples
+			}
+		}
+		deltas = append(deltas, delta)
+	}
+	return deltas
+}
+
+func saveBaseline(path string, data *types.ReportData) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create baseline dir: %w", err)
+	}
+	content, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal baseline: %w", err)
+	}
+	if err := os.WriteFile(path, content, 0o644); err != nil {
+		return fmt.Errorf("failed to write baseline: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package report
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/yinxulai/ait/internal/server/types"
+)
+
+func baselineReport(avgTotal time.Duration, tps, successRate float64) *types.ReportData {
+	return &types.ReportData{
+		TotalRequests: 10,
+		IsStream:      true,
+		AvgTotalTime:  avgTotal,
+		AvgTTFT:       200 * time.Millisecond,
+		AvgTPOT:       20 * time.Millisecond,
+		AvgTPS:        tps,
+		SuccessRate:   successRate,
+	}
+}
+
+func TestCheckBaseline_CreatesThenCompares(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "baselines")
+
+	first, err := CheckBaseline(dir, "nightly qwen", baselineReport(time.Second, 50, 100), 0)
+	if err != nil {
+		t.Fatalf("CheckBaseline (create): %v", err)
+	}
+	if !first.Created || first.Regressed || first.Threshold != DefaultRegressionThreshold {
+		t.Fatalf("first run: %+v", first)
+	}
+	if filepath.Base(first.Path) != "nightly_qwen.baseline.json" {
+		t.Errorf("baseline path = %s", first.Path)
+	}
+	if _, err := os.Stat(first.Path); err != nil {
+		t.Fatalf("baseline not written: %v", err)
+	}
+
+	// 耗时增加 5%，TPS 下降 20%：仅 TPS 回退
+	second, err := CheckBaseline(dir, "nightly qwen", baselineReport(1050*time.Millisecond, 40, 100), 10)
+	if err != nil {
+		t.Fatalf("CheckBaseline (compare): %v", err)
+	}
+	if second.Created || !second.Regressed {
+		t.Fatalf("second run: %+v", second)
+	}
+	byMetric := make(map[string]types.MetricDelta)
+	for _, m := range second.Metrics {
+		byMetric[m.Metric] = m
+	}
+	if m := byMetric["avg_total_time"]; m.Regressed || m.Baseline != 1000 || m.Current != 1050 {
+		t.Errorf("avg_total_time = %+v", m)
+	}
+	if m := byMetric["avg_tps"]; !m.Regressed || m.Change != -20 {
+		t.Errorf("avg_tps = %+v", m)
+	}
+}
+
+func TestCompareWithBaseline_Directions(t *testing.T) {
+	base := baselineReport(time.Second, 50, 100)
+	cur := baselineReport(1200*time.Millisecond, 60, 85)
+	deltas := CompareWithBaseline(base, cur, 10)
+
+	want := map[string]bool{
+		"avg_total_time": true,  // 耗时 +20%
+		"avg_ttft":       false, // 不变
+		"avg_tpot":       false,
+		"avg_tps":        false, // TPS 提升不算回退
+		"success_rate":   true,  // 成功率 -15%
+	}
+	if len(deltas) != len(want) {
+		t.Fatalf("got %d deltas, want %d", len(deltas), len(want))
+	}
+	for _, d := range deltas {
+		if d.Regressed != want[d.Metric] {
+			t.Errorf("%s regressed = %v, want %v (%+v)", d.Metric, d.Regressed, want[d.Metric], d)
+		}
+	}
+}
+
+func TestCompareWithBaseline_Significance(t *testing.T) {
+	base := baselineReport(time.Second, 50, 100)
+	cur := baselineReport(1100*time.Millisecond, 50, 100)
+	// 旧版基线没有逐请求样本，不做检验
+	for _, d := range CompareWithBaseline(base, cur, 10) {
+		if d.Significance != "" || d.PValue != 0 {
+			t.Errorf("%s: significance without samples: %+v", d.Metric, d)
+		}
+	}
+
+	base.Samples = &types.MetricSamples{TotalTime: []float64{27.5, 21.0, 19.0, 23.6, 17.0, 17.9, 16.9, 20.1, 21.9, 22.6, 23.1, 19.6, 19.0, 21.7, 21.4}}
+	cur.Samples = &types.MetricSamples{TotalTime: []float64{27.1, 22.0, 20.8, 23.4, 23.4, 23.5, 25.8, 22.0, 24.8, 20.2, 21.9, 22.1, 22.9, 20.5, 24.4}}
+	for _, d := range CompareWithBaseline(base, cur, 10) {
+		switch d.Metric {
+		case "avg_total_time":
+			if d.Significance != "*" || d.PValue < 0.02 || d.PValue > 0.025 || !d.LowSamples {
+				t.Errorf("avg_total_time = %+v, want p≈0.021 (*) with low samples", d)
+			}
+		default:
+			if d.Significance != "" {
+				t.Errorf("%s: significance without samples: %+v", d.Metric, d)
+			}
+		}
+	}
+}
+
+func TestCompareWithBaseline_SkipsZeroBaseline(t *testing.T) {
+	base := baselineReport(time.Second, 50, 100)
+	base.AvgTTFT, base.AvgTPOT = 0, 0 // 非流式基线
+	for _, d := range CompareWithBaseline(base, baselineReport(time.Second, 50, 100), 10) {
+		if d.Metric == "avg_ttft" || d.Metric == "avg_tpot" {
+			t.Errorf("metric %s should be skipped", d.Metric)
+		}
+	}
+}
+
+func TestCheckBaseline_InvalidFile(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(BaselinePath(dir, "bad"), []byte("{"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := CheckBaseline(dir, "bad", baselineReport(time.Second, 50, 100), 0); err == nil {
+		t.Fatal("expected parse error")
+	}
+}
@@ -0,0 +1,89 @@
+package report
+
+import (
+	"fmt"
+
+	"github.com/yinxulai/ait/internal/server/types"
+)
+
+// StreamComparisonRow 流式 / 非流式对比表中的一行
+type StreamComparisonRow struct {
+	Metric    string `json:"metric"`
+	Stream    string `json:"stream"`
+	NonStream string `json:"non_stream"`
+}
+
+// BuildStreamComparison 从 A/B 对比结果中提取对比表。
+// data 中需同时包含 stream 与 non-stream 两份结果，否则返回 nil。
+// TTFT 与稳态 TPS 仅对流式有意义，非流式列固定为 "-"。
+func BuildStreamComparison(data []types.ReportData) []StreamComparisonRow {
+	var stream, nonStream *types.ReportData
+	for i := range data {
+		switch data[i].StreamMode {
+		case types.StreamModeStream:
+			stream = &data[i]
+		case types.StreamModeNonStream:
+			nonStream = &data[i]
+		}
+	}
+	if stream == nil || nonStream == nil {
+		return nil
+	}
+
+	return []StreamComparisonRow{
+		{Metric: "total_requests", Stream: fmt.Sprintf("%d", stream.TotalRequests), NonStream: fmt.Sprintf("%d", nonStream.TotalRequests)},
+		{Metric: "avg_total_time", Stream: stream.AvgTotalTime.String(), NonStream: nonStream.AvgTotalTime.String()},
+		{Metric: "avg_ttft", Stream: stream.AvgTTFT.String(), NonStream: "-"},
+		{Metric: "avg_tps", Stream: fmt.Sprintf("%.2f", stream.AvgTPS), NonStream: fmt.Sprintf("%.2f", nonStream.AvgTPS)},
+		{Metric: "avg_steady_tps", Stream: fmt.Sprintf("%.2f", stream.AvgSteadyTPS), NonStream: "-"},
+		{Metric: "success_rate", Stream: fmt.Sprintf("%.2f%%", stream.SuccessRate), NonStream: fmt.Sprintf("%.2f%%", nonStream.SuccessRate)},
+	}
+}
+
+// ScenarioMatrix 按 prompt 文件分场景运行时的模型×场景矩阵：Cells[i][j] 为 Models[i] 在 Scenarios[j] 上的结果，
+// 该组合没有结果时为 null。模型与场景按在 data 中首次出现的顺序排列。
+type ScenarioMatrix struct {
+	Models    []string              `json:"models"`
+	Scenarios []string              `json:"scenarios"`
+	Cells     [][]*types.ReportData `json:"cells"`
+}
+
+// BuildScenarioMatrix 从分场景运行的结果中构建模型×场景矩阵，data 中没有带场景名的结果时返回 nil。
+// 模型以显示名（未配置别名时为模型名）区分。
+func BuildScenarioMatrix(data []types.ReportData) *ScenarioMatrix {
+	m := &ScenarioMatrix{}
+	type cell struct{ model, scenario string }
+	cells := map[cell]*types.ReportData{}
+	for i := range data {
+		d := &data[i]
+		if d.Scenario == "" {
+			continue
+		}
+		model := d.ModelDisplayName
+		if model == "" {
+			model = d.Model
+		}
+		m.Models = appendUnique(m.Models, model)
+		m.Scenarios = appendUnique(m.Scenarios, d.Scenario)
+		cells[cell{model, d.Scenario}] = d
+	}
+	if len(m.Scenarios) == 0 {
+		return nil
+	}
+	m.Cells = make([][]*types.ReportData, len(m.Models))
+	for i, model := range m.Models {
+		m.Cells[i] = make([]*types.ReportData, len(m.Scenarios))
+		for j, scenario := range m.Scenarios {
+			m.Cells[i][j] = cells[cell{model, scenario}]
+		}
+	}
+	return m
+}
+
+// scenarioLabel 在结果行的模型名后追加场景名（"模型/场景"），不是分场景运行的结果时原样返回。
+func scenarioLabel(model string, d *types.ReportData) string {
+	if d.Scenario == "" {
+		return model
+	}
+	return model + "/" + d.Scenario
+}
@@ -0,0 +1,68 @@
+package report
+
+import (
+	"testing"
+	"time"
+
+	"github.com/yinxulai/ait/internal/server/types"
+)
+
+func TestBuildStreamComparison(t *testing.T) {
+	data := []types.ReportData{
+		{StreamMode: types.StreamModeStream, TotalRequests: 5, AvgTotalTime: 2 * time.Second, AvgTTFT: 300 * time.Millisecond, AvgTPS: 40, AvgSteadyTPS: 47.06, SuccessRate: 100},
+		{StreamMode: types.StreamModeNonStream, TotalRequests: 5, AvgTotalTime: 1800 * time.Millisecond, AvgTPS: 45, SuccessRate: 80},
+	}
+
+	rows := BuildStreamComparison(data)
+	if len(rows) != 6 {
+		t.Fatalf("expected 6 rows, got %d", len(rows))
+	}
+
+	byMetric := make(map[string]StreamComparisonRow, len(rows))
+	for _, row := range rows {
+		byMetric[row.Metric] = row
+	}
+	if got := byMetric["avg_ttft"]; got.Stream != "300ms" || got.NonStream != "-" {
+		t.Errorf("unexpected avg_ttft row: %+v", got)
+	}
+	if got := byMetric["avg_total_time"]; got.Stream != "2s" || got.NonStream != "1.8s" {
+		t.Errorf("unexpected avg_total_time row: %+v", got)
+	}
+	if got := byMetric["avg_steady_tps"]; got.Stream != "47.06" || got.NonStream != "-" {
+		t.Errorf("unexpected avg_steady_tps row: %+v", got)
+	}
+	if got := byMetric["success_rate"]; got.Stream != "100.00%" || got.NonStream != "80.00%" {
+		t.Errorf("unexpected success_rate row: %+v", got)
+	}
+}
+
+func TestBuildStreamComparison_RequiresBothModes(t *testing.T) {
+	if rows := BuildStreamComparison([]types.ReportData{{StreamMode: types.StreamModeStream}}); rows != nil {
+		t.Fatalf("expected nil rows for single mode, got %+v", rows)
+	}
+	if rows := BuildStreamComparison([]types.ReportData{{}, {}}); rows != nil {
+		t.Fatalf("expected nil rows for regular reports, got %+v", rows)
+	}
+}
+
+func TestBuildScenarioMatrix(t *testing.T) {
+	if m := BuildScenarioMatrix([]types.ReportData{{Model: "gpt-4o"}}); m != nil {
+		t.Errorf("results without scenario should not build a matrix, got %+v", m)
+	}
+
+	data := []types.ReportData{
+		{Model: "gpt-4o", Scenario: "code", TotalRequests: 1},
+		{Model: "gpt-4o", Scenario: "translate", TotalRequests: 2},
+		{Model: "claude", ModelDisplayName: "sonnet", Scenario: "code", TotalRequests: 3},
+	}
+	m := BuildScenarioMatrix(data)
+	if m == nil || len(m.Models) != 2 || m.Models[1] != "sonnet" || len(m.Scenarios) != 2 || m.Scenarios[1] != "translate" {
+		t.Fatalf("matrix = %+v", m)
+	}
+	if m.Cells[0][1].TotalRequests != 2 || m.Cells[1][0].TotalRequests != 3 || m.Cells[1][1] != nil {
+		t.Errorf("cells = %+v", m.Cells)
+	}
+	if got := markdownModel(&data[2]); got != "sonnet/code" {
+		t.Errorf("markdownModel = %q, want sonnet/code", got)
+	}
+}
@@ -4,19 +4,31 @@ import (
 	"encoding/csv"
 	"fmt"
 	"os"
+	"slices"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/yinxulai/ait/internal/server/types"
 )
 
 // CSVRenderer 统一的CSV格式渲染器
-type CSVRenderer struct{}
+//
+// 默认输出规范化格式：时间列为毫秒浮点数且列名带 _ms 后缀，比率列带 _percent 后缀，
+// 便于在 Excel 等工具中直接计算。LegacyFormat 为过渡期兼容开关，输出旧版的
+// Go Duration 字符串与无单位列名。
+type CSVRenderer struct {
+	LegacyFormat  bool   // 输出旧版格式
+	HeaderComment bool   // 在表头前输出一行以 # 开头的元信息
+	Version       string // 元信息中的工具版本
+	TaskID        string // 元信息中的任务 ID
+}
 
 // Render 渲染CSV报告
 func (cr *CSVRenderer) Render(data []types.ReportData) (string, error) {
-	timestamp := time.Now().Format("06-01-02-15-04-05")
-	filename := fmt.Sprintf("ait-report-%s.csv", timestamp)
+	now := time.Now()
+	timestamp := now.Format("06-01-02-15-04-05")
+	filename := reportFilename(timestamp, "csv", data)
 
 	file, err := os.Create(filename)
 	if err != nil {
@@ -24,22 +36,30 @@ func (cr *CSVRenderer) Render(data []types.ReportData) (string, error) {
 	}
 	defer file.Close()
 
+	if cr.HeaderComment {
+		if _, err := fmt.Fprintln(file, cr.headerComment(now)); err != nil {
+			return "", fmt.Errorf("failed to write CSV header comment: %v", err)
+		}
+	}
+
 	writer := csv.NewWriter(file)
 	defer writer.Flush()
 
+	ms, pct := cr.unit("ms"), cr.unit("percent")
+
 	// 完整的CSV头部，包含所有ReportData指标
 	headers := []string{
 		// 基础信息
-		"模型", "协议", "时间戳", "基础URL", "总请求数", "并发数", "流模式", "思考模式", "总测试时间",
+		"模型", "协议", "时间戳", "基础URL", "总请求数", "并发数", "流模式", "思考模式", "总测试时间" + ms,
 		// 时间性能指标
-		"平均总耗时", "最小总耗时", "最大总耗时",
+		"平均总耗时" + ms, "最小总耗时" + ms, "最大总耗时" + ms,
 		// 网络性能指标
-		"目标IP", "平均DNS时间", "最小DNS时间", "最大DNS时间",
-		"平均连接时间", "最小连接时间", "最大连接时间",
-		"平均TLS握手时间", "最小TLS握手时间", "最大TLS握手时间",
+		"目标IP", "平均DNS时间" + ms, "最小DNS时间" + ms, "最大DNS时间" + ms,
+		"平均连接时间" + ms, "最小连接时间" + ms, "最大连接时间" + ms,
+		"平均TLS握手时间" + ms, "最小TLS握手时间" + ms, "最大TLS握手时间" + ms,
 		// 服务性能指标
-		"平均TTFT", "最小TTFT", "最大TTFT",
-		"平均TPOT", "最小TPOT", "最大TPOT",
+		"平均TTFT" + ms, "最小TTFT" + ms, "最大TTFT" + ms,
+		"平均TPOT" + ms, "最小TPOT" + ms, "最大TPOT" + ms,
 		"平均输入Token数", "最小输入Token数", "最大输入Token数",
 		"平均输出Token数", "最小输出Token数", "最大输出Token数",
 		"平均思考Token数", "最小思考Token数", "最大思考Token数",
@@ -47,28 +67,37 @@ func (cr *CSVRenderer) Render(data []types.ReportData) (string, error) {
 		// 吞吐量指标
 		"平均吞吐TPS", "最小吞吐TPS", "最大吞吐TPS",
 		// 标准差指标
-		"总耗时标准差", "TTFT标准差", "TPOT标准差",
+		"总耗时标准差" + ms, "TTFT标准差" + ms, "TPOT标准差" + ms,
 		"输入Token数标准差", "输出Token数标准差", "思考Token数标准差",
 		"输出TPS标准差", "吞吐TPS标准差",
 		// 可靠性指标
-		"成功率", "错误率",
+		"成功率" + pct, "错误率" + pct,
+		// 扩展信息
+		"模型显示名", "流式对比模式",
+		"平均思考耗时" + ms, "最小思考耗时" + ms, "最大思考耗时" + ms,
+		"限流响应数", "限流重试数", "限流损失时间" + ms, "最大稳定速率",
+		"平均稳态TPS", "最小稳态TPS", "最大稳态TPS",
+		// 按目标 IP 的统计：多 IP 时每个 IP 一行，其余列重复该运行的整体指标
+		"目标IP请求数", "目标IP平均总耗时" + ms, "目标IP平均TTFT" + ms,
+		"平均工具调用次数", "归一化平均TPS",
 	}
 	if err := writer.Write(headers); err != nil {
 		return "", fmt.Errorf("failed to write CSV headers: %v", err)
 	}
+	targetIPColumn := slices.Index(headers, "目标IP")
 
 	for _, modelData := range data {
-		// 处理TTFT和TPOT字段，非流式模式显示为"-"
-		avgTTFT := formatDurationForCSV(modelData.AvgTTFT, modelData.IsStream)
-		minTTFT := formatDurationForCSV(modelData.MinTTFT, modelData.IsStream)
-		maxTTFT := formatDurationForCSV(modelData.MaxTTFT, modelData.IsStream)
-		avgTPOT := formatDurationForCSV(modelData.AvgTPOT, modelData.IsStream)
-		minTPOT := formatDurationForCSV(modelData.MinTPOT, modelData.IsStream)
-		maxTPOT := formatDurationForCSV(modelData.MaxTPOT, modelData.IsStream)
+		// 处理TTFT和TPOT字段，非流式模式无意义
+		avgTTFT := cr.streamDuration(modelData.AvgTTFT, modelData.IsStream)
+		minTTFT := cr.streamDuration(modelData.MinTTFT, modelData.IsStream)
+		maxTTFT := cr.streamDuration(modelData.MaxTTFT, modelData.IsStream)
+		avgTPOT := cr.streamDuration(modelData.AvgTPOT, modelData.IsStream)
+		minTPOT := cr.streamDuration(modelData.MinTPOT, modelData.IsStream)
+		maxTPOT := cr.streamDuration(modelData.MaxTPOT, modelData.IsStream)
 
 		record := []string{
 			// 基础信息
-			modelData.Model,
+			scenarioLabel(modelData.Model, &modelData),
 			modelData.Protocol,
 			modelData.Timestamp,
 			modelData.BaseUrl,
@@ -76,22 +105,22 @@ func (cr *CSVRenderer) Render(data []types.ReportData) (string, error) {
 			strconv.Itoa(modelData.Concurrency),
 			strconv.FormatBool(modelData.IsStream),
 			strconv.FormatBool(modelData.IsThinking),
-			modelData.TotalTime.String(),
+			cr.duration(modelData.TotalTime),
 			// 时间性能指标
-			modelData.AvgTotalTime.String(),
-			modelData.MinTotalTime.String(),
-			modelData.MaxTotalTime.String(),
+			cr.duration(modelData.AvgTotalTime),
+			cr.duration(modelData.MinTotalTime),
+			cr.duration(modelData.MaxTotalTime),
 			// 网络性能指标
 			modelData.TargetIP,
-			modelData.AvgDNSTime.String(),
-			modelData.MinDNSTime.String(),
-			modelData.MaxDNSTime.String(),
-			modelData.AvgConnectTime.String(),
-			modelData.MinConnectTime.String(),
-			modelData.MaxConnectTime.String(),
-			modelData.AvgTLSHandshakeTime.String(),
-			modelData.MinTLSHandshakeTime.String(),
-			modelData.MaxTLSHandshakeTime.String(),
+			cr.duration(modelData.AvgDNSTime),
+			cr.duration(modelData.MinDNSTime),
+			cr.duration(modelData.MaxDNSTime),
+			cr.duration(modelData.AvgConnectTime),
+			cr.duration(modelData.MinConnectTime),
+			cr.duration(modelData.MaxConnectTime),
+			cr.duration(modelData.AvgTLSHandshakeTime),
+			cr.duration(modelData.MinTLSHandshakeTime),
+			cr.duration(modelData.MaxTLSHandshakeTime),
 			// 服务性能指标
 			avgTTFT,
 			minTTFT,
@@ -116,9 +145,9 @@ func (cr *CSVRenderer) Render(data []types.ReportData) (string, error) {
 			strconv.FormatFloat(modelData.MinTotalThroughputTPS, 'f', 2, 64),
 			strconv.FormatFloat(modelData.MaxTotalThroughputTPS, 'f', 2, 64),
 			// 标准差指标
-			modelData.StdDevTotalTime.String(),
-			formatDurationForCSV(modelData.StdDevTTFT, modelData.IsStream),
-			formatDurationForCSV(modelData.StdDevTPOT, modelData.IsStream),
+			cr.duration(modelData.StdDevTotalTime),
+			cr.streamDuration(modelData.StdDevTTFT, modelData.IsStream),
+			cr.streamDuration(modelData.StdDevTPOT, modelData.IsStream),
 			strconv.FormatFloat(modelData.StdDevInputTokenCount, 'f', 2, 64),
 			strconv.FormatFloat(modelData.StdDevOutputTokenCount, 'f', 2, 64),
 			strconv.FormatFloat(modelData.StdDevThinkingTokenCount, 'f', 2, 64),
@@ -127,18 +156,173 @@ func (cr *CSVRenderer) Render(data []types.ReportData) (string, error) {
 			// 可靠性指标
 			strconv.FormatFloat(modelData.SuccessRate, 'f', 2, 64),
 			strconv.FormatFloat(modelData.ErrorRate, 'f', 2, 64),
+			// 扩展信息
+			modelData.ModelDisplayName,
+			modelData.StreamMode,
+			cr.duration(modelData.AvgThinkingTime),
+			cr.duration(modelData.MinThinkingTime),
+			cr.duration(modelData.MaxThinkingTime),
 		}
-		if err := writer.Write(record); err != nil {
-			return "", fmt.Errorf("failed to write CSV record: %v", err)
+		record = append(record, cr.adaptiveFields(modelData.Adaptive)...)
+		record = append(record,
+			cr.streamFloat(modelData.AvgSteadyTPS, modelData.IsStream),
+			cr.streamFloat(modelData.MinSteadyTPS, modelData.IsStream),
+			cr.streamFloat(modelData.MaxSteadyTPS, modelData.IsStream),
+		)
+
+		// 目标 IP 列之后的列
+		tail := []string{
+			strconv.FormatFloat(modelData.AvgToolCallCount, 'f', 2, 64),
+			strconv.FormatFloat(modelData.AvgNormalizedTPS, 'f', 2, 64),
 		}
+
+		ips := SortedTargetIPs(modelData.TargetIPStats)
+		if len(ips) <= 1 {
+			record = append(record, cr.targetIPFields(&modelData, modelData.TargetIP)...)
+			record = append(record, tail...)
+			if err := writer.Write(record); err != nil {
+				return "", fmt.Errorf("failed to write CSV record: %v", err)
+			}
+			continue
+		}
+		for _, ip := range ips {
+			ipRecord := append(slices.Clone(record), cr.targetIPFields(&modelData, ip)...)
+			ipRecord = append(ipRecord, tail...)
+			ipRecord[targetIPColumn] = ip
+			if err := writer.Write(ipRecord); err != nil {
+				return "", fmt.Errorf("failed to write CSV record: %v", err)
+			}
+		}
+	}
+	if err := writeErrorsCSV(errorsCSVFilename(filename), data); err != nil {
+		return "", err
 	}
 	return filename, nil
 }
 
+// errorsCSVFilename 返回 CSV 报告对应的错误明细文件名：<报告名>_errors.csv。
+func errorsCSVFilename(reportFile string) string {
+	return strings.TrimSuffix(reportFile, ".csv") + "_errors.csv"
+}
+
+// writeErrorsCSV 把各模型按错误指纹分组的失败明细写入 filename，每组一行；没有失败请求时不生成文件。
+func writeErrorsCSV(filename string, data []types.ReportData) error {
+	if !slices.ContainsFunc(data, func(d types.ReportData) bool { return len(d.Errors) > 0 }) {
+		return nil
+	}
+	file, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("failed to create errors CSV file: %v", err)
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	if err := writer.Write([]string{"模型", "错误类型", "错误指纹", "次数", "首次出现时间", "样例"}); err != nil {
+		return fmt.Errorf("failed to write errors CSV headers: %v", err)
+	}
+	for _, d := range data {
+		for _, g := range d.Errors {
+			var firstSeen string
+			if !g.FirstSeen.IsZero() {
+				firstSeen = g.FirstSeen.Format(time.RFC3339)
+			}
+			if err := writer.Write([]string{d.Model, g.Type, g.Fingerprint, strconv.Itoa(g.Count), firstSeen, g.Sample}); err != nil {
+				return fmt.Errorf("failed to write errors CSV record: %v", err)
+			}
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
 func (cr *CSVRenderer) GetFormat() string {
 	return "csv"
 }
 
+// unit 返回列名的单位后缀，旧版格式不带后缀。
+func (cr *CSVRenderer) unit(name string) string {
+	if cr.LegacyFormat {
+		return ""
+	}
+	return "_" + name
+}
+
+// duration 格式化时间字段：规范化格式为毫秒浮点数，旧版格式为 Go Duration 字符串。
+func (cr *CSVRenderer) duration(d time.Duration) string {
+	if cr.LegacyFormat {
+		return d.String()
+	}
+	return formatMillisForCSV(d)
+}
+
+// streamDuration 格式化仅在流式模式下有意义的时间字段（TTFT/TPOT），
+// 非流式且无数据时规范化格式留空、旧版格式输出"-"。
+func (cr *CSVRenderer) streamDuration(d time.Duration, isStream bool) string {
+	if cr.LegacyFormat {
+		return formatDurationForCSV(d, isStream)
+	}
+	if !isStream && d == 0 {
+		return ""
+	}
+	return formatMillisForCSV(d)
+}
+
+// streamFloat 格式化仅在流式模式下有意义的数值字段（稳态 TPS），
+// 非流式时规范化格式留空、旧版格式输出"-"。
+func (cr *CSVRenderer) streamFloat(v float64, isStream bool) string {
+	if !isStream {
+		if cr.LegacyFormat {
+			return "-"
+		}
+		return ""
+	}
+	return strconv.FormatFloat(v, 'f', 2, 64)
+}
+
+// adaptiveFields 格式化自适应限流统计；未开启时输出空值。
+func (cr *CSVRenderer) adaptiveFields(a *types.AdaptiveStats) []string {
+	if a == nil {
+		return []string{"", "", "", ""}
+	}
+	return []string{
+		strconv.Itoa(a.ThrottledResponses),
+		strconv.Itoa(a.Retries),
+		cr.duration(a.ThrottleLostTime),
+		strconv.FormatFloat(a.MaxStableRate, 'f', 2, 64),
+	}
+}
+
+// targetIPFields 格式化单个目标 IP 的统计；没有该 IP 的统计时输出空值。
+func (cr *CSVRenderer) targetIPFields(d *types.ReportData, ip string) []string {
+	s, ok := d.TargetIPStats[ip]
+	if !ok {
+		return []string{"", "", ""}
+	}
+	return []string{
+		strconv.Itoa(s.Count),
+		cr.duration(s.AvgTotalTime),
+		cr.streamDuration(s.AvgTTFT, d.IsStream),
+	}
+}
+
+// headerComment 生成表头前的元信息行。
+func (cr *CSVRenderer) headerComment(now time.Time) string {
+	version := cr.Version
+	if version == "" {
+		version = "dev"
+	}
+	comment := fmt.Sprintf("# ait_version=%s", version)
+	if cr.TaskID != "" {
+		comment += fmt.Sprintf(" task_id=%s", cr.TaskID)
+	}
+	return comment + fmt.Sprintf(" generated_at=%s", now.Format(time.RFC3339))
+}
+
+// formatMillisForCSV 将时间格式化为毫秒浮点数（保留 3 位小数，即微秒精度）
+func formatMillisForCSV(d time.Duration) string {
+	return strconv.FormatFloat(float64(d)/float64(time.Millisecond), 'f', 3, 64)
+}
+
 // formatDurationForCSV 格式化时间字段，非流式模式下的TTFT返回"-"
 func formatDurationForCSV(duration time.Duration, isStream bool) string {
 	if !isStream && (duration == 0) {
@@ -2,7 +2,9 @@ package report
 
 import (
 	"encoding/csv"
+	"flag"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -10,6 +12,8 @@ import (
 	"github.com/yinxulai/ait/internal/server/types"
 )
 
+var updateGolden = flag.Bool("update", false, "update golden files")
+
 func TestCSVRenderer_GetFormat(t *testing.T) {
 	renderer := &CSVRenderer{}
 	expected := "csv"
@@ -55,7 +59,7 @@ func TestCSVRenderer_Render_EmptyData(t *testing.T) {
 
 	// 验证头部存在
 	headers := strings.Split(lines[0], ",")
-	expectedHeaderCount := 53 // 更新后的头部数量，包含思考模式、思考token、总吞吐量TPS和方差字段
+	expectedHeaderCount := 70 // 更新后的头部数量，包含思考模式、思考token、总吞吐量TPS、方差字段和扩展信息
 	if len(headers) != expectedHeaderCount {
 		t.Errorf("Expected %d headers, got %d", expectedHeaderCount, len(headers))
 	}
@@ -122,7 +126,7 @@ func TestCSVRenderer_Render_SingleModel(t *testing.T) {
 
 	// 验证头部
 	headers := records[0]
-	expectedHeaderCount := 53 // 额外增加思考模式、思考token、总吞吐量TPS和方差字段
+	expectedHeaderCount := 70 // 额外增加思考模式、思考token、总吞吐量TPS、方差字段和扩展信息
 	if len(headers) != expectedHeaderCount {
 		t.Errorf("Expected %d headers, got %d", expectedHeaderCount, len(headers))
 	}
@@ -248,7 +252,7 @@ func TestCSVRenderer_Render_StreamVsNonStream(t *testing.T) {
 		t.Fatalf("Expected 3 rows in CSV (header + 2 data rows), got %d", len(records))
 	}
 
-	const expectedHeaderCount = 53
+	const expectedHeaderCount = 70
 	headers := records[0]
 	if len(headers) != expectedHeaderCount {
 		t.Fatalf("Expected %d headers, got %d", expectedHeaderCount, len(headers))
@@ -276,16 +280,109 @@ func TestCSVRenderer_Render_StreamVsNonStream(t *testing.T) {
 		t.Errorf("Expected thinking 'false' for non-stream data, got '%s'", nonStreamRow[7])
 	}
 
-	// 验证非流式模式下TTFT字段应该是"-"
-	// TTFT字段在CSV中是第22-24列 (平均、最小、最大TTFT)
-	if nonStreamRow[22] != "-" { // 平均TTFT
-		t.Errorf("Expected '-' for AvgTTFT in non-stream mode, got '%s'", nonStreamRow[22])
+	// 验证非流式模式下TTFT字段留空，流式模式下为毫秒数
+	for _, header := range []string{"平均TTFT_ms", "最小TTFT_ms", "最大TTFT_ms"} {
+		col := csvColumnIndex(t, headers, header)
+		if nonStreamRow[col] != "" {
+			t.Errorf("Expected empty %s in non-stream mode, got '%s'", header, nonStreamRow[col])
+		}
+	}
+	if got := streamRow[csvColumnIndex(t, headers, "平均TTFT_ms")]; got != "200.000" {
+		t.Errorf("Expected AvgTTFT '200.000' in stream mode, got '%s'", got)
+	}
+}
+
+func TestCSVRenderer_Render_LegacyFormat(t *testing.T) {
+	renderer := &CSVRenderer{LegacyFormat: true}
+
+	nonStreamData := createTestReportDataForCSV()
+	nonStreamData.IsStream = false
+	nonStreamData.AvgTTFT = 0
+	nonStreamData.MinTTFT = 0
+	nonStreamData.MaxTTFT = 0
+
+	records := renderCSVRecords(t, renderer, []types.ReportData{createTestReportDataForCSV(), nonStreamData})
+	headers := records[0]
+	if len(headers) != 70 {
+		t.Fatalf("Expected 70 headers, got %d", len(headers))
+	}
+
+	// 旧版格式：列名无单位后缀，时间为 Go Duration 字符串，非流式 TTFT 为"-"
+	if got := records[1][csvColumnIndex(t, headers, "平均总耗时")]; got != "500ms" {
+		t.Errorf("Expected AvgTotalTime '500ms', got '%s'", got)
+	}
+	if got := records[1][csvColumnIndex(t, headers, "成功率")]; got != "95.00" {
+		t.Errorf("Expected SuccessRate '95.00', got '%s'", got)
+	}
+	if got := records[2][csvColumnIndex(t, headers, "平均TTFT")]; got != "-" {
+		t.Errorf("Expected '-' for AvgTTFT in non-stream mode, got '%s'", got)
+	}
+}
+
+func TestCSVRenderer_Render_HeaderComment(t *testing.T) {
+	renderer := &CSVRenderer{HeaderComment: true, Version: "v1.2.3", TaskID: "task-42"}
+	fileName, err := renderer.Render([]types.ReportData{createTestReportDataForCSV()})
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	defer os.Remove(fileName)
+
+	content, err := os.ReadFile(fileName)
+	if err != nil {
+		t.Fatalf("Failed to read generated file: %v", err)
+	}
+	firstLine, rest, _ := strings.Cut(string(content), "\n")
+	if !strings.HasPrefix(firstLine, "# ait_version=v1.2.3 task_id=task-42 generated_at=") {
+		t.Errorf("unexpected header comment: %q", firstLine)
+	}
+	if !strings.HasPrefix(rest, "模型,") {
+		t.Errorf("expected CSV header after comment line, got %q", rest[:min(len(rest), 20)])
+	}
+
+	// 标准 CSV 读取器可通过 Comment 字段跳过元信息行
+	reader := csv.NewReader(strings.NewReader(string(content)))
+	reader.Comment = '#'
+	records, err := reader.ReadAll()
+	if err != nil || len(records) != 2 {
+		t.Fatalf("ReadAll with comment = (%d rows, %v), want 2 rows", len(records), err)
+	}
+}
+
+func TestCSVRenderer_Render_Golden(t *testing.T) {
+	data := createTestReportDataForCSV()
+	data.Timestamp = "2025-01-02T03:04:05Z"
+	data.AvgTPOT = 12500 * time.Microsecond
+	data.MinTPOT = 10 * time.Millisecond
+	data.MaxTPOT = 15 * time.Millisecond
+	data.StdDevTotalTime = 123456 * time.Microsecond
+	data.ModelDisplayName = "GPT35"
+	data.AvgSteadyTPS = 320.5
+	data.MinSteadyTPS = 260
+	data.MaxSteadyTPS = 380.25
+
+	fileName, err := (&CSVRenderer{}).Render([]types.ReportData{data})
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	defer os.Remove(fileName)
+
+	got, err := os.ReadFile(fileName)
+	if err != nil {
+		t.Fatalf("Failed to read generated file: %v", err)
+	}
+
+	golden := filepath.Join("testdata", "csv_normalized.golden")
+	if *updateGolden {
+		if err := os.WriteFile(golden, got, 0o644); err != nil {
+			t.Fatalf("update golden: %v", err)
+		}
 	}
-	if nonStreamRow[23] != "-" { // 最小TTFT
-		t.Errorf("Expected '-' for MinTTFT in non-stream mode, got '%s'", nonStreamRow[23])
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatalf("read golden: %v", err)
 	}
-	if nonStreamRow[24] != "-" { // 最大TTFT
-		t.Errorf("Expected '-' for MaxTTFT in non-stream mode, got '%s'", nonStreamRow[24])
+	if string(got) != string(want) {
+		t.Errorf("CSV output differs from %s (run with -update to refresh)\n--- got ---\n%s\n--- want ---\n%s", golden, got, want)
 	}
 }
 
@@ -395,3 +492,217 @@ func createTestReportDataForCSVWithModel(model string) types.ReportData {
 	data.Model = model
 	return data
 }
+
+func TestCSVRenderer_Render_ModelDisplayName(t *testing.T) {
+	renderer := &CSVRenderer{}
+	fileName, err := renderer.Render([]types.ReportData{{
+		Model:            "accounts/fireworks/models/llama-v3p1-70b-instruct",
+		ModelDisplayName: "Llama70B",
+		Protocol:         "openai",
+		TotalRequests:    1,
+		Concurrency:      1,
+	}})
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	defer os.Remove(fileName)
+
+	file, err := os.Open(fileName)
+	if err != nil {
+		t.Fatalf("Failed to open generated file: %v", err)
+	}
+	defer file.Close()
+
+	records, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		t.Fatalf("Failed to read CSV: %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("Expected 2 rows, got %d", len(records))
+	}
+	col := csvColumnIndex(t, records[0], "模型显示名")
+	if records[1][0] != "accounts/fireworks/models/llama-v3p1-70b-instruct" {
+		t.Errorf("Expected real model id in first column, got '%s'", records[1][0])
+	}
+	if records[1][col] != "Llama70B" {
+		t.Errorf("Expected display name 'Llama70B', got '%s'", records[1][col])
+	}
+}
+
+func TestCSVRenderer_Render_ThinkingTime(t *testing.T) {
+	renderer := &CSVRenderer{}
+	fileName, err := renderer.Render([]types.ReportData{{
+		Model:           "deepseek-r1",
+		Protocol:        "openai",
+		TotalRequests:   2,
+		Concurrency:     1,
+		IsStream:        true,
+		IsThinking:      true,
+		AvgThinkingTime: 1500 * time.Millisecond,
+		MinThinkingTime: time.Second,
+		MaxThinkingTime: 2 * time.Second,
+	}})
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	defer os.Remove(fileName)
+
+	file, err := os.Open(fileName)
+	if err != nil {
+		t.Fatalf("Failed to open generated file: %v", err)
+	}
+	defer file.Close()
+
+	records, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		t.Fatalf("Failed to read CSV: %v", err)
+	}
+	want := map[string]string{"平均思考耗时_ms": "1500.000", "最小思考耗时_ms": "1000.000", "最大思考耗时_ms": "2000.000"}
+	for header, value := range want {
+		if got := records[1][csvColumnIndex(t, records[0], header)]; got != value {
+			t.Errorf("%s: got %q, want %q", header, got, value)
+		}
+	}
+}
+
+// renderCSVRecords 渲染并读回 CSV 全部行，测试结束后删除文件。
+func renderCSVRecords(t *testing.T, renderer *CSVRenderer, data []types.ReportData) [][]string {
+	t.Helper()
+	fileName, err := renderer.Render(data)
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	defer os.Remove(fileName)
+
+	file, err := os.Open(fileName)
+	if err != nil {
+		t.Fatalf("Failed to open generated file: %v", err)
+	}
+	defer file.Close()
+
+	records, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		t.Fatalf("Failed to read CSV: %v", err)
+	}
+	return records
+}
+
+// csvColumnIndex 按表头名称查找列下标，避免测试依赖列的具体位置。
+func csvColumnIndex(t *testing.T, headers []string, name string) int {
+	t.Helper()
+	for i, header := range headers {
+		if header == name {
+			return i
+		}
+	}
+	t.Fatalf("header %q not found", name)
+	return -1
+}
+
+func TestCSVRenderer_Render_Adaptive(t *testing.T) {
+	data := createTestReportDataForCSV()
+	data.Adaptive = &types.AdaptiveStats{
+		ThrottledResponses: 4,
+		Retries:            3,
+		ThrottleLostTime:   2500 * time.Millisecond,
+		MaxStableRate:      7.5,
+	}
+	records := renderCSVRecords(t, &CSVRenderer{}, []types.ReportData{data, createTestReportDataForCSV()})
+
+	want := map[string]string{"限流响应数": "4", "限流重试数": "3", "限流损失时间_ms": "2500.000", "最大稳定速率": "7.50"}
+	for header, value := range want {
+		col := csvColumnIndex(t, records[0], header)
+		if got := records[1][col]; got != value {
+			t.Errorf("%s: got %q, want %q", header, got, value)
+		}
+		if got := records[2][col]; got != "" {
+			t.Errorf("%s without adaptive: got %q, want empty", header, got)
+		}
+	}
+}
+
+func TestCSVRenderer_Render_SteadyTPSStreamOnly(t *testing.T) {
+	data := createTestReportDataForCSV()
+	data.IsStream = false
+	for _, tc := range []struct {
+		renderer *CSVRenderer
+		want     string
+	}{
+		{&CSVRenderer{}, ""},
+		{&CSVRenderer{LegacyFormat: true}, "-"},
+	} {
+		records := renderCSVRecords(t, tc.renderer, []types.ReportData{data})
+		col := csvColumnIndex(t, records[0], "平均稳态TPS")
+		if got := records[1][col]; got != tc.want {
+			t.Errorf("legacy=%v: non-stream steady TPS = %q, want %q", tc.renderer.LegacyFormat, got, tc.want)
+		}
+	}
+}
+
+func TestCSVRenderer_Render_TargetIPStats(t *testing.T) {
+	single := createTestReportDataForCSV()
+	single.TargetIPStats = map[string]types.TargetIPStats{single.TargetIP: {Count: 10, AvgTotalTime: 500 * time.Millisecond, AvgTTFT: 200 * time.Millisecond}}
+	multi := createTestReportDataForCSVWithModel("multi-ip")
+	multi.TargetIPStats = map[string]types.TargetIPStats{
+		"10.0.0.2": {Count: 4, AvgTotalTime: 2 * time.Second, AvgTTFT: time.Second},
+		"10.0.0.1": {Count: 6, AvgTotalTime: time.Second, AvgTTFT: 100 * time.Millisecond},
+	}
+
+	records := renderCSVRecords(t, &CSVRenderer{}, []types.ReportData{single, multi})
+	if len(records) != 4 {
+		t.Fatalf("Expected header + 1 single-ip row + 2 multi-ip rows, got %d", len(records))
+	}
+	headers := records[0]
+	ipCol := csvColumnIndex(t, headers, "目标IP")
+	countCol := csvColumnIndex(t, headers, "目标IP请求数")
+	totalCol := csvColumnIndex(t, headers, "目标IP平均总耗时_ms")
+	modelCol := csvColumnIndex(t, headers, "模型")
+
+	if got := records[1][ipCol] + "|" + records[1][countCol]; got != single.TargetIP+"|10" {
+		t.Errorf("single-ip row = %s", got)
+	}
+	for i, want := range []string{"10.0.0.1|6|1000.000", "10.0.0.2|4|2000.000"} {
+		row := records[i+2]
+		if got := row[ipCol] + "|" + row[countCol] + "|" + row[totalCol]; got != want || row[modelCol] != "multi-ip" {
+			t.Errorf("multi-ip row %d = %s (model %s), want %s", i, got, row[modelCol], want)
+		}
+	}
+}
+
+func TestCSVRenderer_Render_ErrorsFile(t *testing.T) {
+	data := createTestReportDataForCSV()
+	data.Errors = []types.ErrorGroup{
+		{Fingerprint: "HTTP 429: rate limit", Type: "rate_limit", Count: 3, FirstSeen: time.Date(2026, 1, 1, 8, 0, 0, 0, time.UTC), Sample: "HTTP 429: rate limit, retry later"},
+	}
+	clean := createTestReportDataForCSVWithModel("clean")
+
+	fileName, err := (&CSVRenderer{}).Render([]types.ReportData{data, clean})
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	errorsFile := errorsCSVFilename(fileName)
+	content, err := os.ReadFile(errorsFile)
+	os.Remove(fileName)
+	os.Remove(errorsFile)
+	if err != nil {
+		t.Fatalf("errors CSV not created: %v", err)
+	}
+	records, err := csv.NewReader(strings.NewReader(string(content))).ReadAll()
+	if err != nil {
+		t.Fatalf("Failed to read errors CSV: %v", err)
+	}
+	want := []string{data.Model, "rate_limit", "HTTP 429: rate limit", "3", "2026-01-01T08:00:00Z", "HTTP 429: rate limit, retry later"}
+	if len(records) != 2 || strings.Join(records[1], "|") != strings.Join(want, "|") {
+		t.Errorf("errors CSV = %v, want header + %v", records, want)
+	}
+
+	// 没有失败请求时不生成错误明细文件
+	fileName, err = (&CSVRenderer{}).Render([]types.ReportData{clean})
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	defer os.Remove(fileName)
+	if _, err := os.Stat(errorsCSVFilename(fileName)); !os.IsNotExist(err) {
+		t.Errorf("errors CSV should not exist without errors, stat err = %v", err)
+	}
+}
@@ -0,0 +1,108 @@
+package report
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/yinxulai/ait/internal/server/types"
+)
+
+// HistoryEntry 历史文件（JSON Lines）中的一行：一次运行（A/B 对比运行的一轮）的核心指标，
+// 由 --history-file 在每次会话结束时追加，供 ait report 渲染趋势。
+type HistoryEntry struct {
+	Time          time.Time `json:"time"`
+	Model         string    `json:"model"`
+	Protocol      string    `json:"protocol,omitempty"`
+	StreamMode    string    `json:"stream_mode,omitempty"`
+	Concurrency   int       `json:"concurrency"`
+	TotalRequests int       `json:"total_requests"`
+	SuccessRate   float64   `json:"success_rate"`
+	AvgTotalMs    float64   `json:"avg_total_time_ms"`
+	AvgTTFTMs     float64   `json:"avg_ttft_ms,omitempty"` // 非流式运行没有 TTFT
+	AvgTPS        float64   `json:"avg_tps"`
+	RPM           float64   `json:"rpm"`
+	TPM           float64   `json:"tpm"`
+}
+
+// NewHistoryEntry 从一份结果生成历史记录；时间取结果的 Timestamp，解析失败时取当前时间。
+func NewHistoryEntry(d *types.ReportData) HistoryEntry {
+	at, err := time.Parse(time.RFC3339, d.Timestamp)
+	if err != nil {
+		at = time.Now()
+	}
+	entry := HistoryEntry{
+		Time:          at,
+		Model:         markdownModel(d),
+		Protocol:      d.Protocol,
+		StreamMode:    tableStreamMode(d),
+		Concurrency:   d.Concurrency,
+		TotalRequests: d.TotalRequests,
+		SuccessRate:   d.SuccessRate,
+		AvgTotalMs:    millis(d.AvgTotalTime),
+		AvgTPS:        d.AvgTPS,
+		RPM:           d.RPM,
+		TPM:           d.TPM,
+	}
+	if d.IsStream {
+		entry.AvgTTFTMs = millis(d.AvgTTFT)
+	}
+	return entry
+}
+
+// AppendHistory 把 data 中每份结果作为一行追加到历史文件 path，文件不存在时创建；data 为空时不写文件。
+func AppendHistory(path string, data []types.ReportData) error {
+	if len(data) == 0 {
+		return nil
+	}
+	var buf bytes.Buffer
+	for i := range data {
+		line, err := json.Marshal(NewHistoryEntry(&data[i]))
+		if err != nil {
+			return err
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := buf.WriteTo(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// ReadHistory 读取历史文件内容，空行忽略；任一行不是合法的记录时返回带行号的错误。
+func ReadHistory(r io.Reader) ([]HistoryEntry, error) {
+	var entries []HistoryEntry
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var entry HistoryEntry
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			return nil, fmt.Errorf("history line %d: %w", lineNo, err)
+		}
+		if entry.Model == "" || entry.Time.IsZero() {
+			return nil, fmt.Errorf("history line %d: model and time are required", lineNo)
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
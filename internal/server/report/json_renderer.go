@@ -24,9 +24,17 @@ func (jr *JSONRenderer) Render(data []types.ReportData) (string, error) {
 		"total_models": len(data),
 		"models":       data,
 	}
+	// A/B 对比运行附带流式 / 非流式对比表
+	if comparison := BuildStreamComparison(data); comparison != nil {
+		content["stream_comparison"] = comparison
+	}
+	// 按 prompt 文件分场景运行附带模型×场景矩阵
+	if matrix := BuildScenarioMatrix(data); matrix != nil {
+		content["scenario_matrix"] = matrix
+	}
 
 	// 统一的文件名格式
-	filename := fmt.Sprintf("ait-report-%s.json", timestamp)
+	filename := reportFilename(timestamp, "json", data)
 
 	jsonData, err := json.MarshalIndent(content, "", "  ")
 	if err != nil {
@@ -288,3 +288,31 @@ func createTestReportDataForJSONWithModel(model string) types.ReportData {
 	data.Model = model
 	return data
 }
+
+func TestJSONRenderer_Render_Errors(t *testing.T) {
+	data := createTestReportDataForJSON()
+	data.Errors = []types.ErrorGroup{{Fingerprint: "HTTP 500", Type: "server_error", Count: 2, Sample: "HTTP 500: boom"}}
+
+	fileName, err := (&JSONRenderer{}).Render([]types.ReportData{data})
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	defer os.Remove(fileName)
+	content, err := os.ReadFile(fileName)
+	if err != nil {
+		t.Fatalf("Failed to read generated file: %v", err)
+	}
+
+	var result struct {
+		Models []types.ReportData `json:"models"`
+	}
+	if err := json.Unmarshal(content, &result); err != nil {
+		t.Fatalf("Failed to parse generated JSON: %v", err)
+	}
+	if len(result.Models) != 1 || len(result.Models[0].Errors) != 1 {
+		t.Fatalf("models = %+v, want one model with one error group", result.Models)
+	}
+	if got := result.Models[0].Errors[0]; got.Fingerprint != "HTTP 500" || got.Count != 2 || got.Type != "server_error" || got.Sample != "HTTP 500: boom" {
+		t.Errorf("error group = %+v", got)
+	}
+}
@@ -0,0 +1,279 @@
+package report
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"time"
+
+	"github.com/yinxulai/ait/internal/server/types"
+)
+
+// K6Summary 与 k6 --summary-export / handleSummary 数据结构兼容的结果摘要，供已有的 k6 可视化流水线直接读取。
+// 一个请求对应 k6 的一次 iteration；趋势类指标只有 avg / min / max（ait 不保留分位数）。
+type K6Summary struct {
+	RootGroup K6Group             `json:"root_group"`
+	Options   K6Options           `json:"options"`
+	State     K6State             `json:"state"`
+	Metrics   map[string]K6Metric `json:"metrics"`
+}
+
+// K6Group k6 的 group，ait 只有根 group，其下挂各项检查。
+type K6Group struct {
+	Name   string    `json:"name"`
+	Path   string    `json:"path"`
+	ID     string    `json:"id"`
+	Groups []K6Group `json:"groups"`
+	Checks []K6Check `json:"checks"`
+}
+
+// K6Check k6 的一项 check：passes / fails 为通过与未通过的次数。
+type K6Check struct {
+	Name   string `json:"name"`
+	Path   string `json:"path"`
+	ID     string `json:"id"`
+	Passes int    `json:"passes"`
+	Fails  int    `json:"fails"`
+}
+
+// K6Options 摘要的展示选项。
+type K6Options struct {
+	SummaryTrendStats []string `json:"summaryTrendStats"`
+	SummaryTimeUnit   string   `json:"summaryTimeUnit"`
+	NoColor           bool     `json:"noColor"`
+}
+
+// K6State 运行状态，testRunDurationMs 为总运行时长。
+type K6State struct {
+	IsStdOutTTY       bool    `json:"isStdOutTTY"`
+	IsStdErrTTY       bool    `json:"isStdErrTTY"`
+	TestRunDurationMs float64 `json:"testRunDurationMs"`
+}
+
+// K6Metric 一项 k6 指标：type 为 trend / counter / rate / gauge，contains 为 time / data / default。
+type K6Metric struct {
+	Type     string             `json:"type"`
+	Contains string             `json:"contains"`
+	Values   map[string]float64 `json:"values"`
+}
+
+// k6SuccessCheck 请求成功的 check 名称
+const k6SuccessCheck = "request succeeded"
+
+// BuildK6Summary 把报告数据转换为 k6 摘要：
+//   - http_req_duration / http_req_waiting / http_req_connecting / http_req_tls_handshaking 对应总耗时 / TTFT / TCP 连接 / TLS 握手
+//   - http_reqs、iterations 为请求数，http_req_failed 为失败率，checks 汇总请求成功与各 SLA 的达标情况
+//   - ait_ 前缀的自定义指标补充 TPOT、DNS 解析、输出 TPS 与 token 数
+//
+// 多个结果（多次运行、A/B 对比）时，计数类指标求和，趋势类指标按成功请求数加权平均、min / max 取极值，
+// 并额外输出带 {model:...} 标签的子指标。
+func BuildK6Summary(data []types.ReportData) K6Summary {
+	summary := K6Summary{
+		RootGroup: K6Group{Groups: []K6Group{}, Checks: []K6Check{}},
+		Options:   K6Options{SummaryTrendStats: []string{"avg", "min", "max"}},
+		Metrics:   map[string]K6Metric{},
+	}
+
+	var total, succeeded int
+	var duration time.Duration
+	for i := range data {
+		d := &data[i]
+		total += d.TotalRequests
+		succeeded += succeededRequests(d)
+		duration += d.TotalTime
+	}
+	summary.State.TestRunDurationMs = millis(duration)
+	addK6Metrics(summary.Metrics, "", data)
+	if len(data) > 1 {
+		for i := range data {
+			addK6Metrics(summary.Metrics, k6ModelTag(&data[i]), data[i:i+1])
+		}
+	}
+
+	checks := []K6Check{newK6Check(k6SuccessCheck, succeeded, total-succeeded)}
+	for i := range data {
+		for _, r := range data[i].SLAResults {
+			name := "SLA " + r.Expr
+			if len(data) > 1 {
+				name += " " + k6ModelTag(&data[i])
+			}
+			checks = append(checks, newK6Check(name, r.Compliant, r.Total-r.Compliant))
+		}
+	}
+	passes, fails := 0, 0
+	for _, c := range checks {
+		passes += c.Passes
+		fails += c.Fails
+	}
+	summary.RootGroup.Checks = checks
+	summary.Metrics["checks"] = k6Rate(passes, fails)
+	return summary
+}
+
+// addK6Metrics 把 data 汇总后的请求数、失败率与各趋势指标写入 metrics，tag 非空时作为子指标名后缀。
+func addK6Metrics(metrics map[string]K6Metric, tag string, data []types.ReportData) {
+	var total, succeeded, concurrency int
+	var duration time.Duration
+	for i := range data {
+		total += data[i].TotalRequests
+		succeeded += succeededRequests(&data[i])
+		duration += data[i].TotalTime
+		concurrency = max(concurrency, data[i].Concurrency)
+	}
+	rate := 0.0
+	if duration > 0 {
+		rate = float64(total) / duration.Seconds()
+	}
+	counter := K6Metric{Type: "counter", Contains: "default", Values: map[string]float64{"count": float64(total), "rate": rate}}
+	metrics["http_reqs"+tag] = counter
+	metrics["iterations"+tag] = counter
+	// k6 的 rate 指标中 passes 为取值为真的次数，http_req_failed 为真即请求失败
+	metrics["http_req_failed"+tag] = k6Rate(total-succeeded, succeeded)
+	if tag == "" {
+		vus := float64(concurrency)
+		metrics["vus"] = K6Metric{Type: "gauge", Contains: "default", Values: map[string]float64{"value": vus, "min": vus, "max": vus}}
+		metrics["vus_max"] = metrics["vus"]
+	}
+
+	timeTrends := []struct {
+		name          string
+		avg, min, max func(*types.ReportData) time.Duration
+	}{
+		{"http_req_duration",
+			func(d *types.ReportData) time.Duration { return d.AvgTotalTime },
+			func(d *types.ReportData) time.Duration { return d.MinTotalTime },
+			func(d *types.ReportData) time.Duration { return d.MaxTotalTime }},
+		{"http_req_waiting",
+			func(d *types.ReportData) time.Duration { return d.AvgTTFT },
+			func(d *types.ReportData) time.Duration { return d.MinTTFT },
+			func(d *types.ReportData) time.Duration { return d.MaxTTFT }},
+		{"http_req_connecting",
+			func(d *types.ReportData) time.Duration { return d.AvgConnectTime },
+			func(d *types.ReportData) time.Duration { return d.MinConnectTime },
+			func(d *types.ReportData) time.Duration { return d.MaxConnectTime }},
+		{"http_req_tls_handshaking",
+			func(d *types.ReportData) time.Duration { return d.AvgTLSHandshakeTime },
+			func(d *types.ReportData) time.Duration { return d.MinTLSHandshakeTime },
+			func(d *types.ReportData) time.Duration { return d.MaxTLSHandshakeTime }},
+		{"ait_dns_time",
+			func(d *types.ReportData) time.Duration { return d.AvgDNSTime },
+			func(d *types.ReportData) time.Duration { return d.MinDNSTime },
+			func(d *types.ReportData) time.Duration { return d.MaxDNSTime }},
+		{"ait_tpot",
+			func(d *types.ReportData) time.Duration { return d.AvgTPOT },
+			func(d *types.ReportData) time.Duration { return d.MinTPOT },
+			func(d *types.ReportData) time.Duration { return d.MaxTPOT }},
+	}
+	for _, t := range timeTrends {
+		if m, ok := k6Trend(data, "time", func(d *types.ReportData) (float64, float64, float64) {
+			return millis(t.avg(d)), millis(t.min(d)), millis(t.max(d))
+		}); ok {
+			metrics[t.name+tag] = m
+		}
+	}
+	if m, ok := k6Trend(data, "default", func(d *types.ReportData) (float64, float64, float64) {
+		return d.AvgTPS, d.MinTPS, d.MaxTPS
+	}); ok {
+		metrics["ait_output_tps"+tag] = m
+	}
+	if m, ok := k6Trend(data, "default", func(d *types.ReportData) (float64, float64, float64) {
+		return float64(d.AvgOutputTokenCount), float64(d.MinOutputTokenCount), float64(d.MaxOutputTokenCount)
+	}); ok {
+		metrics["ait_output_tokens"+tag] = m
+	}
+}
+
+// k6Trend 按成功请求数加权平均各结果的 avg，min / max 取所有结果的极值；没有成功请求时 ok 为 false。
+func k6Trend(data []types.ReportData, contains string, values func(*types.ReportData) (avg, min, max float64)) (K6Metric, bool) {
+	var sum float64
+	var weight int
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for i := range data {
+		n := succeededRequests(&data[i])
+		if n == 0 {
+			continue
+		}
+		avg, mn, mx := values(&data[i])
+		sum += avg * float64(n)
+		weight += n
+		lo = math.Min(lo, mn)
+		hi = math.Max(hi, mx)
+	}
+	if weight == 0 {
+		return K6Metric{}, false
+	}
+	return K6Metric{Type: "trend", Contains: contains, Values: map[string]float64{"avg": sum / float64(weight), "min": lo, "max": hi}}, true
+}
+
+func k6Rate(passes, fails int) K6Metric {
+	rate := 0.0
+	if passes+fails > 0 {
+		rate = float64(passes) / float64(passes+fails)
+	}
+	return K6Metric{Type: "rate", Contains: "default", Values: map[string]float64{"rate": rate, "passes": float64(passes), "fails": float64(fails)}}
+}
+
+// newK6Check 按 k6 的规则生成 check：path 为 "::" + 名称，id 为 path 的 MD5。
+func newK6Check(name string, passes, fails int) K6Check {
+	path := "::" + name
+	sum := md5.Sum([]byte(path))
+	return K6Check{Name: name, Path: path, ID: hex.EncodeToString(sum[:]), Passes: passes, Fails: fails}
+}
+
+// k6ModelTag 返回子指标的标签后缀，如 {model:gpt-4o,stream_mode:stream}、{model:gpt-4o,http_version:2}。
+func k6ModelTag(d *types.ReportData) string {
+	tag := "model:" + markdownModel(d)
+	if d.StreamMode != "" {
+		tag += ",stream_mode:" + d.StreamMode
+	}
+	if d.HTTPVersion != "" {
+		tag += ",http_version:" + d.HTTPVersion
+	}
+	return "{" + tag + "}"
+}
+
+// succeededRequests 由成功率还原成功请求数。
+func succeededRequests(d *types.ReportData) int {
+	return int(math.Round(float64(d.TotalRequests) * d.SuccessRate / 100))
+}
+
+// WriteK6Summary 把报告数据以 k6 摘要 JSON 写入 w。
+func WriteK6Summary(w io.Writer, data []types.ReportData) error {
+	if len(data) == 0 {
+		return fmt.Errorf("no data to write")
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(BuildK6Summary(data))
+}
+
+// K6Renderer k6 摘要格式渲染器，输出 ait-report-<timestamp>.k6.json
+type K6Renderer struct{}
+
+// Render 渲染 k6 摘要报告
+func (kr *K6Renderer) Render(data []types.ReportData) (string, error) {
+	timestamp := time.Now().Format("06-01-02-15-04-05")
+	filename := reportFilename(timestamp, "k6.json", data)
+
+	f, err := os.Create(filename)
+	if err != nil {
+		return "", fmt.Errorf("failed to create k6 summary file: %v", err)
+	}
+	if err := WriteK6Summary(f, data); err != nil {
+		f.Close()
+		return "", fmt.Errorf("failed to write k6 summary: %v", err)
+	}
+	if err := f.Close(); err != nil {
+		return "", fmt.Errorf("failed to write k6 summary: %v", err)
+	}
+	return filename, nil
+}
+
+// GetFormat 返回格式名称
+func (kr *K6Renderer) GetFormat() string {
+	return "k6"
+}
@@ -0,0 +1,99 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/yinxulai/ait/internal/server/types"
+)
+
+func TestBuildK6Summary_Single(t *testing.T) {
+	data := []types.ReportData{{
+		Model: "gpt-4o", Concurrency: 4, TotalRequests: 10, SuccessRate: 90, TotalTime: 5 * time.Second,
+		AvgTotalTime: 1500 * time.Millisecond, MinTotalTime: time.Second, MaxTotalTime: 2 * time.Second,
+		AvgTTFT: 250 * time.Millisecond, MinTTFT: 200 * time.Millisecond, MaxTTFT: 300 * time.Millisecond,
+		SLAResults: []types.SLAResult{{Expr: "ttft<800ms,p=95", Compliant: 8, Total: 10}},
+	}}
+	s := BuildK6Summary(data)
+
+	if got := s.Metrics["http_reqs"].Values; got["count"] != 10 || got["rate"] != 2 {
+		t.Errorf("http_reqs = %v, want count 10 rate 2", got)
+	}
+	if got := s.Metrics["http_req_failed"].Values; got["passes"] != 1 || got["fails"] != 9 {
+		t.Errorf("http_req_failed = %v, want passes 1 fails 9", got)
+	}
+	d := s.Metrics["http_req_duration"]
+	if d.Type != "trend" || d.Contains != "time" || d.Values["avg"] != 1500 || d.Values["min"] != 1000 || d.Values["max"] != 2000 {
+		t.Errorf("http_req_duration = %+v", d)
+	}
+	if got := s.Metrics["http_req_waiting"].Values["avg"]; got != 250 {
+		t.Errorf("http_req_waiting avg = %v, want 250", got)
+	}
+	if got := s.Metrics["vus"].Values["value"]; got != 4 {
+		t.Errorf("vus = %v, want 4", got)
+	}
+	if _, ok := s.Metrics["http_req_duration{model:gpt-4o}"]; ok {
+		t.Error("single report should not emit submetrics")
+	}
+	if s.State.TestRunDurationMs != 5000 {
+		t.Errorf("testRunDurationMs = %v, want 5000", s.State.TestRunDurationMs)
+	}
+
+	if len(s.RootGroup.Checks) != 2 {
+		t.Fatalf("checks = %+v, want request + SLA", s.RootGroup.Checks)
+	}
+	sla := s.RootGroup.Checks[1]
+	if sla.Name != "SLA ttft<800ms,p=95" || sla.Passes != 8 || sla.Fails != 2 || sla.Path != "::"+sla.Name || len(sla.ID) != 32 {
+		t.Errorf("SLA check = %+v", sla)
+	}
+	if got := s.Metrics["checks"].Values; got["passes"] != 17 || got["fails"] != 3 {
+		t.Errorf("checks = %v, want passes 17 fails 3", got)
+	}
+}
+
+func TestBuildK6Summary_MultipleReportsWeighted(t *testing.T) {
+	data := []types.ReportData{
+		{Model: "a", TotalRequests: 10, SuccessRate: 100, TotalTime: time.Second,
+			AvgTotalTime: time.Second, MinTotalTime: 500 * time.Millisecond, MaxTotalTime: 3 * time.Second},
+		{Model: "b", StreamMode: "stream", TotalRequests: 30, SuccessRate: 100, TotalTime: time.Second,
+			AvgTotalTime: 2 * time.Second, MinTotalTime: time.Second, MaxTotalTime: 4 * time.Second},
+	}
+	s := BuildK6Summary(data)
+
+	d := s.Metrics["http_req_duration"].Values
+	if math.Abs(d["avg"]-1750) > 1e-9 || d["min"] != 500 || d["max"] != 4000 {
+		t.Errorf("http_req_duration = %v, want weighted avg 1750 min 500 max 4000", d)
+	}
+	if got := s.Metrics["http_reqs"].Values["count"]; got != 40 {
+		t.Errorf("http_reqs count = %v, want 40", got)
+	}
+	if got := s.Metrics["http_req_duration{model:a}"].Values["avg"]; got != 1000 {
+		t.Errorf("submetric a avg = %v, want 1000", got)
+	}
+	if got := s.Metrics["http_reqs{model:b,stream_mode:stream}"].Values["count"]; got != 30 {
+		t.Errorf("submetric b count = %v, want 30", got)
+	}
+}
+
+func TestWriteK6Summary(t *testing.T) {
+	if err := WriteK6Summary(&bytes.Buffer{}, nil); err == nil {
+		t.Error("expected error for empty data")
+	}
+
+	var buf bytes.Buffer
+	if err := WriteK6Summary(&buf, []types.ReportData{{Model: "m", TotalRequests: 1, SuccessRate: 100, AvgTotalTime: time.Second}}); err != nil {
+		t.Fatalf("WriteK6Summary: %v", err)
+	}
+	var decoded map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	for _, key := range []string{"root_group", "options", "state", "metrics"} {
+		if _, ok := decoded[key]; !ok {
+			t.Errorf("missing top-level key %q", key)
+		}
+	}
+}
@@ -0,0 +1,668 @@
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yinxulai/ait/internal/server/stats"
+	"github.com/yinxulai/ait/internal/server/types"
+)
+
+// markdownMetrics Markdown 结果表的指标列。higherIsBetter 决定多模型对比时哪个值最优；
+// value 返回数值与是否有值（非流式的 TTFT 等没有值），format 把数值渲染为单元格文本。
+var markdownMetrics = []struct {
+	header         string
+	higherIsBetter bool
+	value          func(d *types.ReportData) (float64, bool)
+	format         func(v float64) string
+}{
+	{"成功率 (%)", true, func(d *types.ReportData) (float64, bool) { return d.SuccessRate, true }, formatTableFloat},
+	{"平均总耗时 (ms)", false, func(d *types.ReportData) (float64, bool) { return millis(d.AvgTotalTime), true }, formatMarkdownMillis},
+	{"平均 TTFT (ms)", false, func(d *types.ReportData) (float64, bool) { return millis(d.AvgTTFT), d.IsStream }, formatMarkdownMillis},
+	{"平均 TPOT (ms)", false, func(d *types.ReportData) (float64, bool) { return millis(d.AvgTPOT), d.IsStream }, formatMarkdownMillis},
+	{"平均 TPS", true, func(d *types.ReportData) (float64, bool) { return d.AvgTPS, true }, formatTableFloat},
+	{"稳态 TPS", true, func(d *types.ReportData) (float64, bool) { return d.AvgSteadyTPS, d.IsStream }, formatTableFloat},
+	{"RPM", true, func(d *types.ReportData) (float64, bool) { return d.RPM, true }, formatTableFloat},
+	{"TPM", true, func(d *types.ReportData) (float64, bool) { return d.TPM, true }, formatTableFloat},
+}
+
+// MarkdownRenderer 把结果渲染为 GitHub 风格 Markdown（GFM），可直接贴进 PR 评论或 Actions 的 job summary。
+type MarkdownRenderer struct{}
+
+// Render 渲染 Markdown 报告并写入 ait-report-<timestamp>.md
+func (mr *MarkdownRenderer) Render(data []types.ReportData) (string, error) {
+	var buf bytes.Buffer
+	if err := WriteMarkdown(&buf, data); err != nil {
+		return "", err
+	}
+	filename := reportFilename(time.Now().Format("06-01-02-15-04-05"), "md", data)
+	if err := os.WriteFile(filename, buf.Bytes(), 0644); err != nil {
+		return "", fmt.Errorf("failed to write Markdown file: %v", err)
+	}
+	return filename, nil
+}
+
+// GetFormat 返回格式名称
+func (mr *MarkdownRenderer) GetFormat() string {
+	return "md"
+}
+
+// WriteMarkdown 把测试配置摘要与结果表以 Markdown 写入 w：单份结果输出"指标 | 值"表，
+// 多份结果输出每行一个模型的对比表，并加粗每列的最优值。
+func WriteMarkdown(w io.Writer, data []types.ReportData) error {
+	if len(data) == 0 {
+		return fmt.Errorf("no data to render")
+	}
+	var b strings.Builder
+	b.WriteString("## ait 测试结果\n\n")
+	writeMarkdownSummary(&b, data)
+	b.WriteString("\n")
+	if len(data) == 1 {
+		writeMarkdownSingle(&b, &data[0])
+	} else {
+		writeMarkdownCompare(&b, data)
+	}
+	writeMarkdownSignificance(&b, data)
+	writeMarkdownBaseline(&b, data)
+	writeMarkdownProbes(&b, data)
+	writeMarkdownHTTPVersions(&b, data)
+	writeMarkdownTargetIPs(&b, data)
+	writeMarkdownResponseEncodings(&b, data)
+	writeMarkdownLengthSweep(&b, data)
+	writeMarkdownPhases(&b, data)
+	writeMarkdownConsistency(&b, data)
+	writeMarkdownSchemaCheck(&b, data)
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeMarkdownSummary 输出测试配置摘要：模型、协议、并发、请求数与时间，多份结果的取值去重后合并。
+func writeMarkdownSummary(b *strings.Builder, data []types.ReportData) {
+	var models, protocols, concurrency, counts, replays []string
+	var redacted string
+	var queueWaits, adapted, connReuse []string
+	for i := range data {
+		d := &data[i]
+		if d.Redacted {
+			redacted = "错误样例与响应片段已脱敏"
+		}
+		if d.AdaptedRequests > 0 {
+			adapted = append(adapted, fmt.Sprintf("%s %d 个请求（%s）", markdownModel(d), d.AdaptedRequests, strings.Join(d.AdaptedParams, "; ")))
+		}
+		if d.ConnRequests > 0 {
+			connReuse = append(connReuse, fmt.Sprintf("%s %d/%d（%.1f%%）", markdownModel(d), d.ConnReusedRequests, d.ConnRequests, d.ConnReuseRate))
+		}
+		if d.QueueWaitHigh() {
+			queueWaits = append(queueWaits, fmt.Sprintf("%s 平均 %s ms / 最大 %s ms", markdownModel(d),
+				formatMarkdownMillis(millis(d.AvgQueueWaitTime)), formatMarkdownMillis(millis(d.MaxQueueWaitTime))))
+		}
+		if d.ReplayOf != "" {
+			replays = appendUnique(replays, d.ReplayOf)
+		}
+		models = appendUnique(models, markdownModel(d))
+		protocols = appendUnique(protocols, d.Protocol)
+		concurrency = appendUnique(concurrency, strconv.Itoa(d.Concurrency))
+		counts = appendUnique(counts, strconv.Itoa(d.TotalRequests))
+	}
+	items := [][2]string{
+		{"模型", strings.Join(models, ", ")},
+		{"协议", strings.Join(protocols, ", ")},
+		{"并发", strings.Join(concurrency, ", ")},
+		{"请求数", strings.Join(counts, ", ")},
+		{"时间", data[0].Timestamp},
+		{"重放自任务", strings.Join(replays, ", ")},
+		{"脱敏", redacted},
+		{"本地排队", strings.Join(queueWaits, ", ")},
+		{"参数适配", strings.Join(adapted, ", ")},
+		{"连接复用", strings.Join(connReuse, ", ")},
+	}
+	for _, item := range items {
+		if item[1] == "" {
+			continue
+		}
+		fmt.Fprintf(b, "- **%s**: %s\n", item[0], escapeMarkdown(item[1]))
+	}
+	if len(queueWaits) > 0 {
+		b.WriteString("\n> 并发设置可能过高，本地排队明显：请求派发后等待执行的时间已超过平均总耗时的 " +
+			strconv.Itoa(int(types.QueueWaitWarnRatio*100)) + "%，结果可能受压测客户端本身限制。\n")
+	}
+}
+
+func writeMarkdownSingle(b *strings.Builder, d *types.ReportData) {
+	writeMarkdownRow(b, []string{"指标", "值"})
+	writeMarkdownRow(b, []string{"---", "---:"})
+	writeMarkdownRow(b, []string{"模式", tableStreamMode(d)})
+	for _, m := range markdownMetrics {
+		cell := "-"
+		if v, ok := m.value(d); ok {
+			cell = m.format(v)
+		}
+		writeMarkdownRow(b, []string{m.header, cell})
+	}
+}
+
+func writeMarkdownCompare(b *strings.Builder, data []types.ReportData) {
+	header := []string{"模型", "模式"}
+	align := []string{"---", "---"}
+	for _, m := range markdownMetrics {
+		header = append(header, m.header)
+		align = append(align, "---:")
+	}
+	writeMarkdownRow(b, header)
+	writeMarkdownRow(b, align)
+
+	best := make([]float64, len(markdownMetrics))
+	bestCount := make([]int, len(markdownMetrics)) // 有值的行数，只有一行有值时不加粗
+	for j, m := range markdownMetrics {
+		for i := range data {
+			v, ok := m.value(&data[i])
+			if !ok {
+				continue
+			}
+			if bestCount[j] == 0 || (m.higherIsBetter && v > best[j]) || (!m.higherIsBetter && v < best[j]) {
+				best[j] = v
+			}
+			bestCount[j]++
+		}
+	}
+
+	for i := range data {
+		d := &data[i]
+		row := []string{markdownModel(d), tableStreamMode(d)}
+		for j, m := range markdownMetrics {
+			v, ok := m.value(d)
+			if !ok {
+				row = append(row, "-")
+				continue
+			}
+			cell := m.format(v)
+			if bestCount[j] > 1 && cell == m.format(best[j]) {
+				cell = "**" + cell + "**"
+			}
+			row = append(row, cell)
+		}
+		writeMarkdownRow(b, row)
+	}
+}
+
+// markdownSignificanceMetrics 多模型对比时做显著性检验的指标：均值的差异百分比与逐请求样本
+var markdownSignificanceMetrics = []struct {
+	header  string
+	value   func(d *types.ReportData) float64
+	samples func(*types.MetricSamples) []float64
+}{
+	{"平均总耗时", func(d *types.ReportData) float64 { return millis(d.AvgTotalTime) }, totalTimeSamples},
+	{"平均 TTFT", func(d *types.ReportData) float64 { return millis(d.AvgTTFT) }, ttftSamples},
+	{"平均 TPOT", func(d *types.ReportData) float64 { return millis(d.AvgTPOT) }, tpotSamples},
+	{"平均 TPS", func(d *types.ReportData) float64 { return d.AvgTPS }, tpsSamples},
+}
+
+// markdownSignificanceLegend 显著性符号的说明
+const markdownSignificanceLegend = "> `**` p<0.01，`*` p<0.05，`n.s.` 差异不显著（Welch's t 检验，基于逐请求样本）\n"
+
+// markdownLowSamplesHint 样本不足时的提示
+var markdownLowSamplesHint = fmt.Sprintf(">\n> ⚠️ 部分结果的样本少于 %d 个，结论不可靠，建议增加 count 后再对比\n", stats.MinSignificanceSamples)
+
+// writeMarkdownSignificance 多份结果时以第一份为参照，列出其余结果各指标均值的差异百分比与显著性符号，
+// 避免把采样噪声当成差异。没有逐请求样本（如旧版报告）或某指标无值时该格为 "-"。
+func writeMarkdownSignificance(b *strings.Builder, data []types.ReportData) {
+	if len(data) < 2 || data[0].Samples == nil {
+		return
+	}
+	ref := &data[0]
+	var rows [][]string
+	lowSamples := false
+	for i := 1; i < len(data); i++ {
+		d := &data[i]
+		if d.Samples == nil {
+			continue
+		}
+		row := []string{markdownCompareLabel(d, data)}
+		for _, m := range markdownSignificanceMetrics {
+			cell := "-"
+			base := m.value(ref)
+			if sig, ok := testSamples(ref, d, m.samples); ok && base != 0 {
+				cell = fmt.Sprintf("%+.1f%% %s", (m.value(d)-base)/base*100, markdownMark(sig.mark))
+				lowSamples = lowSamples || sig.lowSamples
+			}
+			row = append(row, cell)
+		}
+		rows = append(rows, row)
+	}
+	if len(rows) == 0 {
+		return
+	}
+	fmt.Fprintf(b, "\n### 差异显著性（相对 %s）\n\n", escapeMarkdown(markdownCompareLabel(ref, data)))
+	header := []string{"模型"}
+	align := []string{"---"}
+	for _, m := range markdownSignificanceMetrics {
+		header = append(header, m.header)
+		align = append(align, "---:")
+	}
+	writeMarkdownRow(b, header)
+	writeMarkdownRow(b, align)
+	for _, row := range rows {
+		writeMarkdownRow(b, row)
+	}
+	b.WriteString("\n" + markdownSignificanceLegend)
+	if lowSamples {
+		b.WriteString(markdownLowSamplesHint)
+	}
+}
+
+// writeMarkdownBaseline 有基线对比结果时输出各指标的基线值、本次值与变化，变化旁附显著性符号。
+func writeMarkdownBaseline(b *strings.Builder, data []types.ReportData) {
+	var rows [][]string
+	lowSamples, tested := false, false
+	for i := range data {
+		baseline := data[i].Baseline
+		if baseline == nil || len(baseline.Metrics) == 0 {
+			continue
+		}
+		for _, m := range baseline.Metrics {
+			change := fmt.Sprintf("%+.1f%%", m.Change)
+			if m.Significance != "" {
+				change += " " + markdownMark(m.Significance)
+				tested = true
+			}
+			if m.Regressed {
+				change += " ⚠️"
+			}
+			lowSamples = lowSamples || m.LowSamples
+			rows = append(rows, []string{markdownCompareLabel(&data[i], data), m.Metric, formatTableFloat(m.Baseline), formatTableFloat(m.Current), change})
+		}
+	}
+	if len(rows) == 0 {
+		return
+	}
+	b.WriteString("\n### 基线对比\n\n")
+	writeMarkdownRow(b, []string{"模型", "指标", "基线", "本次", "变化"})
+	writeMarkdownRow(b, []string{"---", "---", "---:", "---:", "---:"})
+	for _, row := range rows {
+		writeMarkdownRow(b, row)
+	}
+	if tested {
+		b.WriteString("\n" + markdownSignificanceLegend)
+		if lowSamples {
+			b.WriteString(markdownLowSamplesHint)
+		}
+	}
+}
+
+// markdownCompareLabel 对比表的行标签：模型名，有多份结果时附上模式（流式 / 非流式）。
+func markdownCompareLabel(d *types.ReportData, data []types.ReportData) string {
+	label := markdownModel(d)
+	if mode := tableStreamMode(d); len(data) > 1 && mode != "" {
+		label += " (" + mode + ")"
+	}
+	return label
+}
+
+// markdownMark 转义显著性符号中的 *，避免被渲染为强调。
+func markdownMark(mark string) string {
+	return strings.ReplaceAll(mark, "*", `\*`)
+}
+
+// markdownProbeVerdicts 网络探测结论在 Markdown 报告中的提示
+var markdownProbeVerdicts = map[string]string{
+	types.ProbeVerdictServer:  "疑似服务端原因：网络探测延迟稳定而 TTFT 变差",
+	types.ProbeVerdictNetwork: "疑似网络原因：网络探测延迟与 TTFT 同步变差",
+}
+
+// writeMarkdownProbes 有基线网络探测时输出探测延迟与业务请求建连耗时的对比表，并在表后给出结论提示。
+func writeMarkdownProbes(b *strings.Builder, data []types.ReportData) {
+	var rows [][]string
+	var verdicts []string
+	for i := range data {
+		s := data[i].NetworkProbeSummary
+		if s == nil {
+			continue
+		}
+		model := markdownCompareLabel(&data[i], data)
+		rows = append(rows, []string{
+			model,
+			strconv.Itoa(s.Samples),
+			strconv.Itoa(s.Errors),
+			formatMarkdownMillis(millis(s.Min)),
+			formatMarkdownMillis(millis(s.Avg)),
+			formatMarkdownMillis(millis(s.Max)),
+			formatMarkdownMillis(millis(s.RequestAvg)),
+		})
+		if text, ok := markdownProbeVerdicts[s.Verdict]; ok {
+			verdicts = append(verdicts, fmt.Sprintf("> ⚠️ **%s**：%s\n", escapeMarkdown(model), text))
+		}
+	}
+	if len(rows) == 0 {
+		return
+	}
+	b.WriteString("\n### 网络探测\n\n")
+	writeMarkdownRow(b, []string{"模型", "探测次数", "失败", "最小 (ms)", "平均 (ms)", "最大 (ms)", "请求建连均值 (ms)"})
+	writeMarkdownRow(b, []string{"---", "---:", "---:", "---:", "---:", "---:", "---:"})
+	for _, row := range rows {
+		writeMarkdownRow(b, row)
+	}
+	if len(verdicts) > 0 {
+		b.WriteString("\n")
+		for _, v := range verdicts {
+			b.WriteString(v)
+		}
+	}
+}
+
+// writeMarkdownHTTPVersions 指定了 HTTP 版本（含 HTTP 版本 A/B 对比）或同一结果中协商到多种协议版本时，
+// 按结果输出配置的版本、实际协商到的各版本请求数与主要指标，A/B 对比时即两种版本的对比表。
+func writeMarkdownHTTPVersions(b *strings.Builder, data []types.ReportData) {
+	show := false
+	for i := range data {
+		if data[i].HTTPVersion != "" || len(data[i].HTTPProtocols) > 1 {
+			show = true
+		}
+	}
+	if !show {
+		return
+	}
+	b.WriteString("\n### HTTP 版本\n\n")
+	writeMarkdownRow(b, []string{"模型", "配置版本", "协商版本", "成功率 (%)", "平均总耗时 (ms)", "平均 TTFT (ms)", "平均 TPS"})
+	writeMarkdownRow(b, []string{"---", "---", "---", "---:", "---:", "---:", "---:"})
+	for i := range data {
+		d := &data[i]
+		version := d.HTTPVersion
+		if version == "" {
+			version = types.HTTPVersionAuto
+		}
+		ttft := "-"
+		if d.IsStream {
+			ttft = formatMarkdownMillis(millis(d.AvgTTFT))
+		}
+		writeMarkdownRow(b, []string{
+			markdownModel(d),
+			version,
+			FormatHTTPProtocols(d.HTTPProtocols),
+			formatTableFloat(d.SuccessRate),
+			formatMarkdownMillis(millis(d.AvgTotalTime)),
+			ttft,
+			formatTableFloat(d.AvgTPS),
+		})
+	}
+}
+
+// writeMarkdownTargetIPs 结果涉及多个后端 IP 时，按 IP 输出是否由 DNS 解析得到、实际连接的请求数与平均耗时；
+// 解析到但从未连接的 IP 请求数为 0，用于发现连接复用或客户端偏好导致的后端倾斜。
+func writeMarkdownTargetIPs(b *strings.Builder, data []types.ReportData) {
+	var rows [][]string
+	for i := range data {
+		d := &data[i]
+		ips := SortedTargetIPs(d.TargetIPStats)
+		for _, ip := range d.ResolvedIPs {
+			if !slices.Contains(ips, ip) {
+				ips = append(ips, ip)
+			}
+		}
+		if len(ips) <= 1 {
+			continue
+		}
+		slices.Sort(ips)
+		for _, ip := range ips {
+			resolved := "-"
+			if len(d.ResolvedIPs) > 0 {
+				resolved = "否"
+				if slices.Contains(d.ResolvedIPs, ip) {
+					resolved = "是"
+				}
+			}
+			row := []string{markdownModel(d), ip, resolved, "0", "-", "-"}
+			if s, ok := d.TargetIPStats[ip]; ok {
+				row[3] = strconv.Itoa(s.Count)
+				if s.AvgTotalTime > 0 {
+					row[4] = formatMarkdownMillis(millis(s.AvgTotalTime))
+					if d.IsStream {
+						row[5] = formatMarkdownMillis(millis(s.AvgTTFT))
+					}
+				}
+			}
+			rows = append(rows, row)
+		}
+	}
+	if len(rows) == 0 {
+		return
+	}
+	b.WriteString("\n### 后端 IP\n\n")
+	writeMarkdownRow(b, []string{"模型", "IP", "DNS 解析到", "请求数", "平均总耗时 (ms)", "平均 TTFT (ms)"})
+	writeMarkdownRow(b, []string{"---", "---", "---", "---:", "---:", "---:"})
+	for _, row := range rows {
+		writeMarkdownRow(b, row)
+	}
+}
+
+// FormatHTTPProtocols 把各协议版本的请求数格式化为 "HTTP/1.1 ×3, HTTP/2.0 ×7"（按版本排序），没有记录时为 "-"。
+func FormatHTTPProtocols(protocols map[string]int) string {
+	if len(protocols) == 0 {
+		return "-"
+	}
+	parts := make([]string, 0, len(protocols))
+	for _, proto := range slices.Sorted(maps.Keys(protocols)) {
+		parts = append(parts, fmt.Sprintf("%s ×%d", proto, protocols[proto]))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// writeMarkdownResponseEncodings 配置了 accept_encoding 时按结果与响应实际的 Content-Encoding 输出请求数、平均 TTFT、
+// 解压前后的平均字节数与压缩率；用 --accept-encoding 分别跑几轮后合并渲染即为不同编码的对比表。
+func writeMarkdownResponseEncodings(b *strings.Builder, data []types.ReportData) {
+	var rows [][]string
+	for i := range data {
+		d := &data[i]
+		for _, encoding := range slices.Sorted(maps.Keys(d.ResponseEncodings)) {
+			s := d.ResponseEncodings[encoding]
+			ttft := "-"
+			if d.IsStream {
+				ttft = formatMarkdownMillis(millis(s.AvgTTFT))
+			}
+			rows = append(rows, []string{
+				markdownModel(d),
+				d.AcceptEncoding,
+				encoding,
+				strconv.Itoa(s.Count),
+				ttft,
+				strconv.FormatInt(s.AvgCompressedBytes, 10),
+				strconv.FormatInt(s.AvgUncompressedBytes, 10),
+				formatTableFloat(s.CompressionRatio * 100),
+			})
+		}
+	}
+	if len(rows) == 0 {
+		return
+	}
+	b.WriteString("\n### 响应压缩\n\n")
+	writeMarkdownRow(b, []string{"模型", "Accept-Encoding", "Content-Encoding", "请求数", "平均 TTFT (ms)", "平均线上字节", "平均解压后字节", "压缩率 (%)"})
+	writeMarkdownRow(b, []string{"---", "---", "---", "---:", "---:", "---:", "---:", "---:"})
+	for _, row := range rows {
+		writeMarkdownRow(b, row)
+	}
+}
+
+// writeMarkdownLengthSweep 输入长度扫描时按长度输出 TTFT 与 prefill TPS 的对比表。
+func writeMarkdownLengthSweep(b *strings.Builder, data []types.ReportData) {
+	var rows [][]string
+	for i := range data {
+		d := &data[i]
+		if d.InputLength <= 0 {
+			continue
+		}
+		rows = append(rows, []string{
+			markdownModel(d),
+			strconv.Itoa(d.InputLength),
+			strconv.Itoa(d.AvgInputTokenCount),
+			formatMarkdownMillis(millis(d.AvgTTFT)),
+			formatMarkdownMillis(millis(d.MinTTFT)),
+			formatMarkdownMillis(millis(d.MaxTTFT)),
+			strconv.FormatFloat(d.AvgPrefillTPS, 'f', 1, 64),
+		})
+	}
+	if len(rows) == 0 {
+		return
+	}
+	b.WriteString("\n### 输入长度扫描\n\n")
+	writeMarkdownRow(b, []string{"模型", "目标长度", "平均输入 token", "平均 TTFT (ms)", "最小 TTFT (ms)", "最大 TTFT (ms)", "Prefill TPS"})
+	writeMarkdownRow(b, []string{"---", "---:", "---:", "---:", "---:", "---:", "---:"})
+	for _, row := range rows {
+		writeMarkdownRow(b, row)
+	}
+}
+
+// writeMarkdownPhases 有分段统计时输出预热段 / 稳态段 / 收尾段的对比表，
+// 稳态段与整体差异超过阈值时在表后提示。
+func writeMarkdownPhases(b *strings.Builder, data []types.ReportData) {
+	var rows [][]string
+	var hints []string
+	for i := range data {
+		p := data[i].PhaseStats
+		if p == nil {
+			continue
+		}
+		model := markdownModel(&data[i])
+		if mode := tableStreamMode(&data[i]); len(data) > 1 && mode != "" {
+			model += " (" + mode + ")"
+		}
+		for _, phase := range []struct {
+			name string
+			m    types.PhaseMetrics
+		}{{"预热段", p.Warmup}, {"稳态段", p.Steady}, {"收尾段", p.Cooldown}} {
+			rows = append(rows, []string{
+				model,
+				phase.name,
+				strconv.Itoa(phase.m.Requests),
+				formatMarkdownMillis(millis(phase.m.AvgTTFT)),
+				strconv.FormatFloat(phase.m.AvgTPS, 'f', 1, 64),
+			})
+		}
+		if p.Skewed() {
+			hints = append(hints, fmt.Sprintf("> ⚠️ **%s**：稳态段与整体相差 %.0f%%，建议先预热或延长测试时间\n", escapeMarkdown(model), p.SteadyDeviation))
+		}
+	}
+	if len(rows) == 0 {
+		return
+	}
+	b.WriteString("\n### 分段分析\n\n")
+	writeMarkdownRow(b, []string{"模型", "阶段", "请求数", "平均 TTFT (ms)", "平均 TPS"})
+	writeMarkdownRow(b, []string{"---", "---", "---:", "---:", "---:"})
+	for _, row := range rows {
+		writeMarkdownRow(b, row)
+	}
+	if len(hints) > 0 {
+		b.WriteString("\n")
+		for _, h := range hints {
+			b.WriteString(h)
+		}
+	}
+}
+
+// writeMarkdownConsistency 确定性验证的一致率低于 100% 时输出各输出版本的次数、占比与差异摘要。
+func writeMarkdownConsistency(b *strings.Builder, data []types.ReportData) {
+	var rows [][]string
+	for i := range data {
+		d := &data[i]
+		if d.DistinctOutputs <= 1 {
+			continue
+		}
+		for j, v := range d.OutputVariants {
+			offset := "-"
+			if j > 0 {
+				offset = strconv.Itoa(v.DiffOffset)
+			}
+			rows = append(rows, []string{
+				markdownModel(d),
+				v.Hash,
+				strconv.Itoa(v.Count),
+				strconv.FormatFloat(v.Rate, 'f', 1, 64) + "%",
+				offset,
+				v.Excerpt,
+			})
+		}
+	}
+	if len(rows) == 0 {
+		return
+	}
+	b.WriteString("\n### 输出一致性\n\n")
+	writeMarkdownRow(b, []string{"模型", "版本", "次数", "占比", "差异位置", "摘要"})
+	writeMarkdownRow(b, []string{"---", "---", "---:", "---:", "---:", "---"})
+	for _, row := range rows {
+		writeMarkdownRow(b, row)
+	}
+}
+
+// writeMarkdownSchemaCheck 配置了 response_schema 时输出各结果的合法 JSON 占比、合规率与第一条不合规样例。
+func writeMarkdownSchemaCheck(b *strings.Builder, data []types.ReportData) {
+	var rows [][]string
+	for i := range data {
+		d := &data[i]
+		s := d.SchemaCheck
+		if s == nil {
+			continue
+		}
+		sample := "-"
+		if len(s.Samples) > 0 {
+			sample = s.Samples[0].Reason + ": " + s.Samples[0].Detail
+		}
+		rows = append(rows, []string{
+			markdownModel(d),
+			strconv.Itoa(s.Checked),
+			formatTableFloat(s.ValidJSONRate),
+			formatTableFloat(s.ComplianceRate),
+			sample,
+		})
+	}
+	if len(rows) == 0 {
+		return
+	}
+	b.WriteString("\n### JSON schema 校验\n\n")
+	writeMarkdownRow(b, []string{"模型", "校验数", "合法 JSON (%)", "合规率 (%)", "不合规样例"})
+	writeMarkdownRow(b, []string{"---", "---:", "---:", "---:", "---"})
+	for _, row := range rows {
+		writeMarkdownRow(b, row)
+	}
+}
+
+// writeMarkdownRow 输出表格的一行，单元格内容转义管道符与换行。
+func writeMarkdownRow(b *strings.Builder, cells []string) {
+	b.WriteString("|")
+	for _, cell := range cells {
+		b.WriteString(" ")
+		b.WriteString(escapeMarkdown(cell))
+		b.WriteString(" |")
+	}
+	b.WriteString("\n")
+}
+
+// escapeMarkdown 转义表格单元格中的管道符，并把换行替换为空格，避免破坏表格结构。
+func escapeMarkdown(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	s = strings.ReplaceAll(s, "\r\n", " ")
+	return strings.ReplaceAll(s, "\n", " ")
+}
+
+// markdownModel 优先使用模型显示名
+func markdownModel(d *types.ReportData) string {
+	if d.ModelDisplayName != "" {
+		return scenarioLabel(d.ModelDisplayName, d)
+	}
+	return scenarioLabel(d.Model, d)
+}
+
+func appendUnique(list []string, v string) []string {
+	if v == "" || slices.Contains(list, v) {
+		return list
+	}
+	return append(list, v)
+}
+
+func formatMarkdownMillis(v float64) string {
+	return strconv.FormatFloat(v, 'f', 1, 64)
+}
@@ -0,0 +1,443 @@
+package report
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yinxulai/ait/internal/server/types"
+)
+
+func markdownTestData() []types.ReportData {
+	return []types.ReportData{
+		{Model: "gpt-4o", Protocol: "openai-completions", IsStream: true, Concurrency: 4, TotalRequests: 10, SuccessRate: 100,
+			Timestamp: "2025-01-02T03:04:05Z", AvgTotalTime: 1500 * time.Millisecond, AvgTTFT: 250 * time.Millisecond,
+			AvgTPOT: 12500 * time.Microsecond, AvgTPS: 42.123, AvgSteadyTPS: 50.5, RPM: 60, TPM: 3000},
+		{Model: "qwen|max", ModelDisplayName: "Qwen|Max", Protocol: "openai-completions", IsStream: true, Concurrency: 4, TotalRequests: 10, SuccessRate: 90,
+			Timestamp: "2025-01-02T03:05:00Z", AvgTotalTime: time.Second, AvgTTFT: 400 * time.Millisecond,
+			AvgTPOT: 10 * time.Millisecond, AvgTPS: 55, AvgSteadyTPS: 48, RPM: 60, TPM: 3300},
+		{Model: "claude", Protocol: "anthropic-messages", Concurrency: 8, TotalRequests: 20, SuccessRate: 100,
+			Timestamp: "2025-01-02T03:06:00Z", AvgTotalTime: 2 * time.Second, AvgTPS: 30, RPM: 120, TPM: 2000},
+	}
+}
+
+func checkMarkdownGolden(t *testing.T, name string, data []types.ReportData) {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := WriteMarkdown(&buf, data); err != nil {
+		t.Fatalf("WriteMarkdown: %v", err)
+	}
+	golden := filepath.Join("testdata", name)
+	if *updateGolden {
+		if err := os.WriteFile(golden, buf.Bytes(), 0o644); err != nil {
+			t.Fatalf("update golden: %v", err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatalf("read golden: %v", err)
+	}
+	if buf.String() != string(want) {
+		t.Errorf("Markdown output differs from %s (run with -update to refresh)\n--- got ---\n%s\n--- want ---\n%s", golden, buf.String(), want)
+	}
+}
+
+func TestWriteMarkdown_Single_Golden(t *testing.T) {
+	checkMarkdownGolden(t, "markdown_single.golden", markdownTestData()[:1])
+}
+
+func TestWriteMarkdown_Compare_Golden(t *testing.T) {
+	checkMarkdownGolden(t, "markdown_compare.golden", markdownTestData())
+}
+
+func TestWriteMarkdown_BoldsBestValues(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteMarkdown(&buf, markdownTestData()); err != nil {
+		t.Fatalf("WriteMarkdown: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"| **1000.0** |", // 平均总耗时越低越好
+		"| **250.0** |",  // 非流式没有 TTFT，只在流式之间比较
+		"| **55.00** |",  // 平均 TPS 越高越好
+		"| **100.00** |", // 成功率并列最优都加粗
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Count(out, "**100.00**") != 2 {
+		t.Errorf("tied best values should all be bold:\n%s", out)
+	}
+}
+
+func TestWriteMarkdown_EscapesPipes(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteMarkdown(&buf, markdownTestData()); err != nil {
+		t.Fatalf("WriteMarkdown: %v", err)
+	}
+	if !strings.Contains(buf.String(), `| Qwen\|Max | stream |`) {
+		t.Errorf("pipe in model name should be escaped:\n%s", buf.String())
+	}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if !strings.HasPrefix(line, "|") {
+			continue
+		}
+		if cells := strings.Count(strings.ReplaceAll(line, `\|`, ""), "|"); cells != 11 {
+			t.Errorf("row has %d separators, want 11: %q", cells, line)
+		}
+	}
+}
+
+func TestWriteMarkdown_NetworkProbes(t *testing.T) {
+	data := markdownTestData()[:1]
+	var buf bytes.Buffer
+	if err := WriteMarkdown(&buf, data); err != nil {
+		t.Fatalf("WriteMarkdown: %v", err)
+	}
+	if strings.Contains(buf.String(), "网络探测") {
+		t.Error("probe section should only appear when probes were taken")
+	}
+
+	data[0].NetworkProbeSummary = &types.NetworkProbeSummary{Samples: 4, Errors: 1, Min: 20 * time.Millisecond, Avg: 25 * time.Millisecond,
+		Max: 32 * time.Millisecond, RequestAvg: 40 * time.Millisecond, Verdict: types.ProbeVerdictServer}
+	buf.Reset()
+	if err := WriteMarkdown(&buf, data); err != nil {
+		t.Fatalf("WriteMarkdown: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{"### 网络探测", "| gpt-4o | 4 | 1 | 20.0 | 25.0 | 32.0 | 40.0 |", "> ⚠️ **gpt-4o**：疑似服务端原因"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestWriteMarkdown_LengthSweep(t *testing.T) {
+	data := markdownTestData()[:1]
+	var buf bytes.Buffer
+	if err := WriteMarkdown(&buf, data); err != nil {
+		t.Fatalf("WriteMarkdown: %v", err)
+	}
+	if strings.Contains(buf.String(), "输入长度扫描") {
+		t.Error("sweep section should only appear for input length sweeps")
+	}
+
+	short, long := data[0], data[0]
+	short.InputLength, short.AvgInputTokenCount, short.AvgTTFT, short.MinTTFT, short.MaxTTFT, short.AvgPrefillTPS = 1024, 1030, 200*time.Millisecond, 150*time.Millisecond, 260*time.Millisecond, 5150
+	long.InputLength, long.AvgInputTokenCount, long.AvgTTFT, long.MinTTFT, long.MaxTTFT, long.AvgPrefillTPS = 4096, 4100, 600*time.Millisecond, 500*time.Millisecond, 700*time.Millisecond, 6833.3
+	buf.Reset()
+	if err := WriteMarkdown(&buf, []types.ReportData{short, long}); err != nil {
+		t.Fatalf("WriteMarkdown: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{"### 输入长度扫描", "| gpt-4o | 1024 | 1030 | 200.0 | 150.0 | 260.0 | 5150.0 |", "| gpt-4o | 4096 | 4100 | 600.0 | 500.0 | 700.0 | 6833.3 |"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestWriteMarkdown_HTTPVersions(t *testing.T) {
+	data := markdownTestData()[:1]
+	data[0].HTTPProtocols = map[string]int{"HTTP/2.0": 10}
+	var buf bytes.Buffer
+	if err := WriteMarkdown(&buf, data); err != nil {
+		t.Fatalf("WriteMarkdown: %v", err)
+	}
+	if strings.Contains(buf.String(), "HTTP 版本") {
+		t.Error("HTTP version section should only appear when a version is configured or protocols are mixed")
+	}
+
+	http1, http2 := data[0], data[0]
+	http1.HTTPVersion, http1.HTTPProtocols = types.HTTPVersion1, map[string]int{"HTTP/1.1": 10}
+	http2.HTTPVersion, http2.HTTPProtocols = types.HTTPVersion2, map[string]int{"HTTP/2.0": 8, "HTTP/1.1": 2}
+	buf.Reset()
+	if err := WriteMarkdown(&buf, []types.ReportData{http1, http2}); err != nil {
+		t.Fatalf("WriteMarkdown: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{"### HTTP 版本", "| gpt-4o | 1.1 | HTTP/1.1 ×10 |", "| gpt-4o | 2 | HTTP/1.1 ×2, HTTP/2.0 ×8 |"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestWriteMarkdown_ResponseEncodings(t *testing.T) {
+	data := markdownTestData()[:1]
+	var buf bytes.Buffer
+	if err := WriteMarkdown(&buf, data); err != nil {
+		t.Fatalf("WriteMarkdown: %v", err)
+	}
+	if strings.Contains(buf.String(), "响应压缩") {
+		t.Error("response encoding section should only appear when accept_encoding is configured")
+	}
+
+	gzipRun, brRun := data[0], data[0]
+	gzipRun.IsStream, brRun.IsStream = true, true
+	gzipRun.AcceptEncoding = types.AcceptEncodingGzip
+	gzipRun.ResponseEncodings = map[string]*types.EncodingStats{
+		"gzip": {Count: 10, AvgTTFT: 300 * time.Millisecond, AvgCompressedBytes: 400, AvgUncompressedBytes: 1000, CompressionRatio: 0.4},
+	}
+	brRun.AcceptEncoding = types.AcceptEncodingBrotli
+	brRun.ResponseEncodings = map[string]*types.EncodingStats{
+		"br":       {Count: 8, AvgTTFT: 320 * time.Millisecond, AvgCompressedBytes: 300, AvgUncompressedBytes: 1000, CompressionRatio: 0.3},
+		"identity": {Count: 2, AvgTTFT: 280 * time.Millisecond, AvgCompressedBytes: 1000, AvgUncompressedBytes: 1000, CompressionRatio: 1},
+	}
+	buf.Reset()
+	if err := WriteMarkdown(&buf, []types.ReportData{gzipRun, brRun}); err != nil {
+		t.Fatalf("WriteMarkdown: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"### 响应压缩",
+		"| gpt-4o | gzip | gzip | 10 | 300.0 | 400 | 1000 | 40.00 |",
+		"| gpt-4o | br | br | 8 | 320.0 | 300 | 1000 | 30.00 |",
+		"| gpt-4o | br | identity | 2 | 280.0 | 1000 | 1000 | 100.00 |",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestWriteMarkdown_Phases(t *testing.T) {
+	data := markdownTestData()[:1]
+	data[0].PhaseStats = &types.PhaseStats{
+		Warmup:          types.PhaseMetrics{Requests: 1, AvgTTFT: time.Second, AvgTPS: 20},
+		Steady:          types.PhaseMetrics{Requests: 8, AvgTTFT: 100 * time.Millisecond, AvgTPS: 50},
+		Cooldown:        types.PhaseMetrics{Requests: 1, AvgTTFT: 120 * time.Millisecond, AvgTPS: 45},
+		SteadyDeviation: 47.4,
+	}
+	var buf bytes.Buffer
+	if err := WriteMarkdown(&buf, data); err != nil {
+		t.Fatalf("WriteMarkdown: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{"### 分段分析", "| gpt-4o | 预热段 | 1 | 1000.0 | 20.0 |", "| gpt-4o | 稳态段 | 8 | 100.0 | 50.0 |", "稳态段与整体相差 47%"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+
+	data[0].PhaseStats.SteadyDeviation = 3
+	buf.Reset()
+	if err := WriteMarkdown(&buf, data); err != nil {
+		t.Fatalf("WriteMarkdown: %v", err)
+	}
+	if strings.Contains(buf.String(), "建议先预热") {
+		t.Error("hint should only appear when the steady phase is skewed")
+	}
+}
+
+func TestWriteMarkdown_Consistency(t *testing.T) {
+	data := markdownTestData()[:1]
+	data[0].DistinctOutputs = 1
+	data[0].ConsistencyRate = 100
+	data[0].OutputVariants = []types.OutputVariant{{Hash: "a1b2c3d4e5f60718", Count: 4, Rate: 100, Excerpt: "你好"}}
+	var buf bytes.Buffer
+	if err := WriteMarkdown(&buf, data); err != nil {
+		t.Fatalf("WriteMarkdown: %v", err)
+	}
+	if strings.Contains(buf.String(), "输出一致性") {
+		t.Error("consistency table should only appear when outputs differ")
+	}
+
+	data[0].DistinctOutputs = 2
+	data[0].ConsistencyRate = 75
+	data[0].OutputVariants = []types.OutputVariant{
+		{Hash: "a1b2c3d4e5f60718", Count: 3, Rate: 75, Excerpt: "你好"},
+		{Hash: "0f1e2d3c4b5a6978", Count: 1, Rate: 25, DiffOffset: 1, Excerpt: "…们"},
+	}
+	buf.Reset()
+	if err := WriteMarkdown(&buf, data); err != nil {
+		t.Fatalf("WriteMarkdown: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{"### 输出一致性", "| gpt-4o | a1b2c3d4e5f60718 | 3 | 75.0% | - | 你好 |", "| gpt-4o | 0f1e2d3c4b5a6978 | 1 | 25.0% | 1 | …们 |"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestWriteMarkdown_Significance(t *testing.T) {
+	data := markdownTestData()[:2]
+	if strings.Contains(markdownString(t, data), "差异显著性") {
+		t.Error("significance table should only appear with per-request samples")
+	}
+
+	// 总耗时差异明显；TPS 两组交错，差异不显著
+	fast, slow, tpsA, tpsB := make([]float64, 40), make([]float64, 40), make([]float64, 40), make([]float64, 40)
+	for i := range fast {
+		fast[i] = 1000 + float64(i%5)*10
+		slow[i] = 1500 + float64(i%5)*10
+		tpsA[i] = 40 + float64(i%7)
+		tpsB[i] = 40 + float64((i+3)%7)
+	}
+	data[0].Samples = &types.MetricSamples{TotalTime: slow, TPS: tpsA}
+	data[1].Samples = &types.MetricSamples{TotalTime: fast, TPS: tpsB}
+	out := markdownString(t, data)
+	for _, want := range []string{
+		"### 差异显著性（相对 gpt-4o (stream)）",
+		`| Qwen\|Max (stream) | -33.3% \*\* | - | - | +30.6% n.s. |`,
+		"Welch's t 检验",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "建议增加 count") {
+		t.Error("40 samples per side should not trigger the low-sample hint")
+	}
+
+	data[1].Samples.TotalTime = fast[:10]
+	if out := markdownString(t, data); !strings.Contains(out, "建议增加 count") {
+		t.Errorf("expected low-sample hint:\n%s", out)
+	}
+}
+
+func TestWriteMarkdown_Baseline(t *testing.T) {
+	data := markdownTestData()[:1]
+	data[0].Baseline = &types.BaselineResult{Metrics: []types.MetricDelta{
+		{Metric: "avg_total_time", Baseline: 1200, Current: 1500, Change: 25, Regressed: true, PValue: 0.001, Significance: "**"},
+		{Metric: "avg_tps", Baseline: 40, Current: 42.123, Change: 5.3075, PValue: 0.4, Significance: "n.s.", LowSamples: true},
+	}}
+	out := markdownString(t, data)
+	for _, want := range []string{
+		"### 基线对比",
+		`| gpt-4o | avg_total_time | 1200.00 | 1500.00 | +25.0% \*\* ⚠️ |`,
+		"| gpt-4o | avg_tps | 40.00 | 42.12 | +5.3% n.s. |",
+		"建议增加 count",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestWriteMarkdown_SchemaCheck(t *testing.T) {
+	data := markdownTestData()[:2]
+	if strings.Contains(markdownString(t, data), "JSON schema 校验") {
+		t.Error("schema check table should only appear with response_schema")
+	}
+	data[0].SchemaCheck = &types.SchemaCheckStats{Checked: 4, ValidJSON: 3, Compliant: 2, ValidJSONRate: 75, ComplianceRate: 50,
+		Samples: []types.SchemaViolation{{Reason: "schema_violation", Detail: `required: missing properties: ["age"]`, Excerpt: `{"name":"a"}`}}}
+	data[1].SchemaCheck = &types.SchemaCheckStats{Checked: 2, ValidJSON: 2, Compliant: 2, ValidJSONRate: 100, ComplianceRate: 100}
+	out := markdownString(t, data)
+	for _, want := range []string{
+		"### JSON schema 校验",
+		`| gpt-4o | 4 | 75.00 | 50.00 | schema_violation: required: missing properties: ["age"] |`,
+		`| Qwen\|Max | 2 | 100.00 | 100.00 | - |`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestWriteMarkdown_Redacted(t *testing.T) {
+	data := markdownTestData()[:1]
+	if strings.Contains(markdownString(t, data), "**脱敏**") {
+		t.Error("redaction note should only appear for redacted runs")
+	}
+	data[0].Redacted = true
+	if out := markdownString(t, data); !strings.Contains(out, "- **脱敏**: 错误样例与响应片段已脱敏") {
+		t.Errorf("output missing redaction note:\n%s", out)
+	}
+}
+
+func markdownString(t *testing.T, data []types.ReportData) string {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := WriteMarkdown(&buf, data); err != nil {
+		t.Fatalf("WriteMarkdown: %v", err)
+	}
+	return buf.String()
+}
+
+func TestWriteMarkdown_Empty(t *testing.T) {
+	if err := WriteMarkdown(&bytes.Buffer{}, nil); err == nil {
+		t.Error("expected error for empty data")
+	}
+}
+
+func TestEscapeMarkdown(t *testing.T) {
+	if got := escapeMarkdown("a|b\nc"); got != `a\|b c` {
+		t.Errorf("escapeMarkdown = %q", got)
+	}
+}
+
+func TestWriteMarkdown_TargetIPs(t *testing.T) {
+	data := markdownTestData()[:2]
+	data[1].TargetIPStats = map[string]types.TargetIPStats{"10.0.0.1": {Count: 10, AvgTotalTime: time.Second}}
+	data[1].ResolvedIPs = []string{"10.0.0.1"}
+	if strings.Contains(markdownString(t, data), "### 后端 IP") {
+		t.Error("target ip table should only appear with more than one ip")
+	}
+	data[0].TargetIPStats = map[string]types.TargetIPStats{
+		"10.0.0.2": {Count: 3, AvgTotalTime: 2 * time.Second, AvgTTFT: 500 * time.Millisecond},
+		"10.0.0.1": {Count: 7, AvgTotalTime: time.Second, AvgTTFT: 200 * time.Millisecond},
+	}
+	data[0].ResolvedIPs = []string{"10.0.0.1", "10.0.0.2", "10.0.0.3"}
+	data[1].ResolvedIPs = nil
+	data[1].TargetIPStats["10.0.0.9"] = types.TargetIPStats{Count: 1}
+	out := markdownString(t, data)
+	for _, want := range []string{
+		"### 后端 IP",
+		"| gpt-4o | 10.0.0.1 | 是 | 7 | 1000.0 | 200.0 |",
+		"| gpt-4o | 10.0.0.2 | 是 | 3 | 2000.0 | 500.0 |",
+		"| gpt-4o | 10.0.0.3 | 是 | 0 | - | - |",
+		"| Qwen\\|Max | 10.0.0.9 | - | 1 | - | - |",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestWriteMarkdown_QueueWaitHint(t *testing.T) {
+	data := markdownTestData()[:2]
+	data[0].AvgQueueWaitTime = 50 * time.Millisecond
+	if strings.Contains(markdownString(t, data), "本地排队") {
+		t.Error("queue wait hint should only appear when local queueing is noticeable")
+	}
+	data[1].AvgQueueWaitTime = 300 * time.Millisecond
+	data[1].MaxQueueWaitTime = 900 * time.Millisecond
+	out := markdownString(t, data)
+	for _, want := range []string{
+		"- **本地排队**: Qwen\\|Max 平均 300.0 ms / 最大 900.0 ms",
+		"> 并发设置可能过高，本地排队明显",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestWriteMarkdown_AdaptedRequests(t *testing.T) {
+	data := markdownTestData()[:1]
+	if strings.Contains(markdownString(t, data), "参数适配") {
+		t.Error("adaptation note should only appear when requests were adapted")
+	}
+	data[0].AdaptedRequests = 10
+	data[0].AdaptedParams = []string{"max_tokens→max_completion_tokens"}
+	if out := markdownString(t, data); !strings.Contains(out, "- **参数适配**: gpt-4o 10 个请求（max_tokens→max_completion_tokens）") {
+		t.Errorf("output missing adaptation note:\n%s", out)
+	}
+}
+
+func TestWriteMarkdown_ConnReuse(t *testing.T) {
+	data := markdownTestData()[:1]
+	if strings.Contains(markdownString(t, data), "连接复用") {
+		t.Error("connection reuse should only appear when it was tracked")
+	}
+	data[0].SetConnReuse(3, 4)
+	if out := markdownString(t, data); !strings.Contains(out, "- **连接复用**: gpt-4o 3/4（75.0%）") {
+		t.Errorf("output missing connection reuse:\n%s", out)
+	}
+}
@@ -2,6 +2,7 @@ package report
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/yinxulai/ait/internal/server/types"
 )
@@ -26,6 +27,8 @@ func NewReportManager() *ReportManager {
 	// 注册默认的渲染器
 	manager.RegisterRenderer("json", &JSONRenderer{})
 	manager.RegisterRenderer("csv", &CSVRenderer{})
+	manager.RegisterRenderer("md", &MarkdownRenderer{})
+	manager.RegisterRenderer("k6", &K6Renderer{})
 
 	return manager
 }
@@ -59,3 +62,14 @@ func (rm *ReportManager) GenerateReports(data []types.ReportData, formats []stri
 
 	return filePaths, nil
 }
+
+// reportFilename 生成报告文件名 ait-report-<timestamp>.<ext>；分片运行带 shard 后缀
+// （如 ait-report-<timestamp>-shard-1-of-4.json），避免多个进程同时写同一文件。
+func reportFilename(timestamp, ext string, data []types.ReportData) string {
+	for i := range data {
+		if shard := data[i].Shard; shard != "" {
+			return fmt.Sprintf("ait-report-%s-shard-%s.%s", timestamp, strings.Replace(shard, "/", "-of-", 1), ext)
+		}
+	}
+	return fmt.Sprintf("ait-report-%s.%s", timestamp, ext)
+}
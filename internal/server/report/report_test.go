@@ -298,3 +298,14 @@ func TestMain(m *testing.M) {
 
 	os.Exit(code)
 }
+
+func TestReportFilename_Shard(t *testing.T) {
+	data := []types.ReportData{createTestReportData()}
+	if got := reportFilename("26-10-17-10-00-00", "json", data); got != "ait-report-26-10-17-10-00-00.json" {
+		t.Errorf("reportFilename = %q", got)
+	}
+	data[0].Shard = "2/4"
+	if got := reportFilename("26-10-17-10-00-00", "csv", data); got != "ait-report-26-10-17-10-00-00-shard-2-of-4.csv" {
+		t.Errorf("reportFilename with shard = %q", got)
+	}
+}
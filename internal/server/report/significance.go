@@ -0,0 +1,36 @@
+package report
+
+import (
+	"github.com/yinxulai/ait/internal/server/stats"
+	"github.com/yinxulai/ait/internal/server/types"
+)
+
+// significance 两份结果同一指标的显著性检验结果。
+type significance struct {
+	p          float64 // Welch's t 检验的双侧 p 值
+	mark       string  // "**"、"*" 或 "n.s."
+	lowSamples bool    // 任一方样本少于 stats.MinSignificanceSamples，结论不可靠
+}
+
+func totalTimeSamples(s *types.MetricSamples) []float64 { return s.TotalTime }
+func ttftSamples(s *types.MetricSamples) []float64      { return s.TTFT }
+func tpotSamples(s *types.MetricSamples) []float64      { return s.TPOT }
+func tpsSamples(s *types.MetricSamples) []float64       { return s.TPS }
+
+// testSamples 用 pick 取出两份结果的逐请求样本做 Welch's t 检验。
+// 任一方没有原始样本（如旧版报告或基线）或样本少于 2 个时返回 false。
+func testSamples(a, b *types.ReportData, pick func(*types.MetricSamples) []float64) (significance, bool) {
+	if a.Samples == nil || b.Samples == nil {
+		return significance{}, false
+	}
+	xs, ys := pick(a.Samples), pick(b.Samples)
+	result, ok := stats.WelchTTest(xs, ys)
+	if !ok {
+		return significance{}, false
+	}
+	return significance{
+		p:          result.P,
+		mark:       stats.SignificanceMark(result.P),
+		lowSamples: len(xs) < stats.MinSignificanceSamples || len(ys) < stats.MinSignificanceSamples,
+	}, true
+}
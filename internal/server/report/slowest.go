@@ -0,0 +1,145 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/yinxulai/ait/internal/server/redact"
+	"github.com/yinxulai/ait/internal/server/types"
+)
+
+// slowPromptLength 慢请求表中 prompt 片段的最大字符数
+const slowPromptLength = 40
+
+// SlowRequest 慢请求表中的一行
+type SlowRequest struct {
+	Index     int
+	Success   bool
+	TTFT      time.Duration
+	TotalTime time.Duration
+	Prompt    string // 该请求所用 prompt 的开头片段
+	CacheHit  bool   // 按返回的缓存 token 数推测是否命中 prompt 缓存
+}
+
+// SlowestRequests 按总耗时从长到短取前 n 个请求（含失败请求），耗时相同按 index 排序；n <= 0 时返回 nil。
+func SlowestRequests(requests []*types.RequestMetrics, n int) []SlowRequest {
+	if n <= 0 {
+		return nil
+	}
+	sorted := make([]*types.RequestMetrics, 0, len(requests))
+	for _, r := range requests {
+		if r != nil && r.TotalTime > 0 {
+			sorted = append(sorted, r)
+		}
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].TotalTime != sorted[j].TotalTime {
+			return sorted[i].TotalTime > sorted[j].TotalTime
+		}
+		return sorted[i].Index < sorted[j].Index
+	})
+	if len(sorted) > n {
+		sorted = sorted[:n]
+	}
+
+	rows := make([]SlowRequest, 0, len(sorted))
+	for _, r := range sorted {
+		rows = append(rows, SlowRequest{
+			Index:     r.Index,
+			Success:   r.Success,
+			TTFT:      r.TTFT,
+			TotalTime: r.TotalTime,
+			Prompt:    promptExcerpt(redact.JSON(r.RequestBody), slowPromptLength),
+			CacheHit:  r.CachedTokens > 0,
+		})
+	}
+	return rows
+}
+
+// WriteSlowestTable 以对齐的纯文本表格写出慢请求列表，title 作为首行标题；rows 为空时不输出。
+func WriteSlowestTable(w io.Writer, title string, rows []SlowRequest) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	if _, err := fmt.Fprintln(w, title); err != nil {
+		return err
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "index\tstatus\tttft_ms\ttotal_time_ms\tcache_guess\tprompt")
+	for _, r := range rows {
+		status, cache := "ok", "miss"
+		if !r.Success {
+			status = "failed"
+		}
+		if r.CacheHit {
+			cache = "hit"
+		}
+		ttft := "-"
+		if r.TTFT > 0 {
+			ttft = fmt.Sprintf("%.0f", millis(r.TTFT))
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%.0f\t%s\t%s\n", r.Index, status, ttft, millis(r.TotalTime), cache, r.Prompt)
+	}
+	return tw.Flush()
+}
+
+// promptExcerpt 从请求体中取出最后一条用户输入的开头片段：依次尝试 messages（OpenAI / Anthropic）、
+// input（Responses API）与 prompt 字段，都没有时退回原始请求体。空白折叠为单个空格。
+func promptExcerpt(body string, maxRunes int) string {
+	text := body
+	var req map[string]any
+	if err := json.Unmarshal([]byte(body), &req); err == nil {
+		if messages, ok := req["messages"].([]any); ok {
+			text = lastUserText(messages)
+		} else if input, ok := req["input"]; ok {
+			if items, ok := input.([]any); ok {
+				text = lastUserText(items)
+			} else {
+				text = contentText(input)
+			}
+		} else if prompt, ok := req["prompt"]; ok {
+			text = contentText(prompt)
+		}
+	}
+
+	text = strings.Join(strings.Fields(text), " ")
+	if runes := []rune(text); len(runes) > maxRunes {
+		return string(runes[:maxRunes]) + "…"
+	}
+	return text
+}
+
+// lastUserText 返回消息列表中最后一条 user 消息的文本
+func lastUserText(messages []any) string {
+	for i := len(messages) - 1; i >= 0; i-- {
+		msg, ok := messages[i].(map[string]any)
+		if ok && msg["role"] == "user" {
+			return contentText(msg["content"])
+		}
+	}
+	return ""
+}
+
+// contentText 把字符串或内容块数组（[{"type":"text","text":"..."}]）转成文本
+func contentText(content any) string {
+	switch c := content.(type) {
+	case string:
+		return c
+	case []any:
+		parts := make([]string, 0, len(c))
+		for _, block := range c {
+			if b, ok := block.(map[string]any); ok {
+				if text, ok := b["text"].(string); ok {
+					parts = append(parts, text)
+				}
+			}
+		}
+		return strings.Join(parts, " ")
+	}
+	return ""
+}
@@ -0,0 +1,79 @@
+package report
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yinxulai/ait/internal/server/types"
+)
+
+func TestSlowestRequests(t *testing.T) {
+	requests := []*types.RequestMetrics{
+		{Index: 0, Success: true, TotalTime: time.Second, TTFT: 200 * time.Millisecond, RequestBody: `{"messages":[{"role":"system","content":"sys"},{"role":"user","content":"第一个问题"}]}`},
+		{Index: 1, Success: false, TotalTime: 5 * time.Second, RequestBody: `{"input":"  timeout\n prompt  "}`},
+		nil,
+		{Index: 2, Success: true, TotalTime: 3 * time.Second, TTFT: 2 * time.Second, CachedTokens: 64, RequestBody: `{"messages":[{"role":"user","content":[{"type":"text","text":"cached"}]}]}`},
+		{Index: 3, Success: true, TotalTime: time.Second, RequestBody: "not json"},
+		{Index: 4, Success: false},
+	}
+
+	got := SlowestRequests(requests, 3)
+	want := []SlowRequest{
+		{Index: 1, TotalTime: 5 * time.Second, Prompt: "timeout prompt"},
+		{Index: 2, Success: true, TTFT: 2 * time.Second, TotalTime: 3 * time.Second, Prompt: "cached", CacheHit: true},
+		{Index: 0, Success: true, TTFT: 200 * time.Millisecond, TotalTime: time.Second, Prompt: "第一个问题"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("SlowestRequests =\n%+v\nwant\n%+v", got, want)
+	}
+	if got := SlowestRequests(requests, 0); got != nil {
+		t.Errorf("n=0 should return nil, got %+v", got)
+	}
+}
+
+func TestPromptExcerpt(t *testing.T) {
+	long := strings.Repeat("长", slowPromptLength+5)
+	tests := []struct {
+		body string
+		want string
+	}{
+		{`{"prompt":"triton"}`, "triton"},
+		{`{"input":[{"role":"user","content":[{"type":"input_text","text":"a"},{"type":"input_text","text":"b"}]}]}`, "a b"},
+		{`{"messages":[{"role":"user","content":"` + long + `"}]}`, strings.Repeat("长", slowPromptLength) + "…"},
+		{"raw   body", "raw body"},
+	}
+	for _, tt := range tests {
+		if got := promptExcerpt(tt.body, slowPromptLength); got != tt.want {
+			t.Errorf("promptExcerpt(%q) = %q, want %q", tt.body, got, tt.want)
+		}
+	}
+}
+
+func TestWriteSlowestTable(t *testing.T) {
+	var buf bytes.Buffer
+	rows := []SlowRequest{
+		{Index: 7, TotalTime: 4500 * time.Millisecond, Prompt: "hello"},
+		{Index: 3, Success: true, TTFT: 1200 * time.Millisecond, TotalTime: 3 * time.Second, Prompt: "world", CacheHit: true},
+	}
+	if err := WriteSlowestTable(&buf, "Top 2 最慢请求 · gpt-4o", rows); err != nil {
+		t.Fatalf("WriteSlowestTable: %v", err)
+	}
+	lines := strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")
+	if len(lines) != 4 || lines[0] != "Top 2 最慢请求 · gpt-4o" {
+		t.Fatalf("unexpected output:\n%s", buf.String())
+	}
+	if fields := strings.Fields(lines[2]); !reflect.DeepEqual(fields, []string{"7", "failed", "-", "4500", "miss", "hello"}) {
+		t.Errorf("row = %v", fields)
+	}
+	if fields := strings.Fields(lines[3]); !reflect.DeepEqual(fields, []string{"3", "ok", "1200", "3000", "hit", "world"}) {
+		t.Errorf("row = %v", fields)
+	}
+
+	buf.Reset()
+	if err := WriteSlowestTable(&buf, "empty", nil); err != nil || buf.Len() != 0 {
+		t.Errorf("empty rows should write nothing, got %q (%v)", buf.String(), err)
+	}
+}
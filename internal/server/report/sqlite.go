@@ -0,0 +1,188 @@
+package report
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	_ "modernc.org/sqlite" // 纯 Go 的 SQLite 驱动，注册为 "sqlite"
+
+	"github.com/yinxulai/ait/internal/server/types"
+)
+
+// SQLiteRecord 写入 SQLite 的一行：一次运行（A/B 对比、长度扫描运行的一轮）的结果及其任务配置。
+type SQLiteRecord struct {
+	RunID    string
+	TaskID   string
+	TaskName string
+	Seq      int         // 同一次运行中的第几份结果，从 0 开始
+	Input    types.Input // 任务配置，写入前去除密钥与 webhook 地址
+	Report   *types.ReportData
+}
+
+// sqliteColumn results 表中由 ReportData 展开的一列。
+type sqliteColumn struct {
+	name  string
+	typ   string
+	value func(r *SQLiteRecord) any
+}
+
+// sqliteColumns results 表的列。耗时统一为毫秒（REAL），便于直接 SQL 聚合；
+// 未展开的字段可从 report_json 用 json_extract 查询。新增列追加在末尾，已有的库会在写入时自动补列。
+var sqliteColumns = []sqliteColumn{
+	{"run_id", "TEXT NOT NULL", func(r *SQLiteRecord) any { return r.RunID }},
+	{"seq", "INTEGER NOT NULL", func(r *SQLiteRecord) any { return r.Seq }},
+	{"task_id", "TEXT", func(r *SQLiteRecord) any { return r.TaskID }},
+	{"task_name", "TEXT", func(r *SQLiteRecord) any { return r.TaskName }},
+	{"timestamp", "TEXT", func(r *SQLiteRecord) any { return r.Report.Timestamp }},
+	{"protocol", "TEXT", func(r *SQLiteRecord) any { return r.Report.Protocol }},
+	{"model", "TEXT", func(r *SQLiteRecord) any { return r.Report.Model }},
+	{"model_display_name", "TEXT", func(r *SQLiteRecord) any { return r.Report.ModelDisplayName }},
+	{"endpoint_url", "TEXT", func(r *SQLiteRecord) any { return r.Report.EndpointURL }},
+	{"stream_mode", "TEXT", func(r *SQLiteRecord) any { return tableStreamMode(r.Report) }},
+	{"is_stream", "INTEGER", func(r *SQLiteRecord) any { return r.Report.IsStream }},
+	{"is_thinking", "INTEGER", func(r *SQLiteRecord) any { return r.Report.IsThinking }},
+	{"concurrency", "INTEGER", func(r *SQLiteRecord) any { return r.Report.Concurrency }},
+	{"total_requests", "INTEGER", func(r *SQLiteRecord) any { return r.Report.TotalRequests }},
+	{"total_time_ms", "REAL", func(r *SQLiteRecord) any { return millis(r.Report.TotalTime) }},
+	{"success_rate", "REAL", func(r *SQLiteRecord) any { return r.Report.SuccessRate }},
+	{"error_rate", "REAL", func(r *SQLiteRecord) any { return r.Report.ErrorRate }},
+	{"throttled_count", "INTEGER", func(r *SQLiteRecord) any { return r.Report.ThrottledCount }},
+	{"avg_total_time_ms", "REAL", func(r *SQLiteRecord) any { return millis(r.Report.AvgTotalTime) }},
+	{"min_total_time_ms", "REAL", func(r *SQLiteRecord) any { return millis(r.Report.MinTotalTime) }},
+	{"max_total_time_ms", "REAL", func(r *SQLiteRecord) any { return millis(r.Report.MaxTotalTime) }},
+	{"stddev_total_time_ms", "REAL", func(r *SQLiteRecord) any { return millis(r.Report.StdDevTotalTime) }},
+	{"avg_ttft_ms", "REAL", func(r *SQLiteRecord) any { return millis(r.Report.AvgTTFT) }},
+	{"min_ttft_ms", "REAL", func(r *SQLiteRecord) any { return millis(r.Report.MinTTFT) }},
+	{"max_ttft_ms", "REAL", func(r *SQLiteRecord) any { return millis(r.Report.MaxTTFT) }},
+	{"stddev_ttft_ms", "REAL", func(r *SQLiteRecord) any { return millis(r.Report.StdDevTTFT) }},
+	{"avg_ttfb_ms", "REAL", func(r *SQLiteRecord) any { return millis(r.Report.AvgTTFB) }},
+	{"avg_tpot_ms", "REAL", func(r *SQLiteRecord) any { return millis(r.Report.AvgTPOT) }},
+	{"min_tpot_ms", "REAL", func(r *SQLiteRecord) any { return millis(r.Report.MinTPOT) }},
+	{"max_tpot_ms", "REAL", func(r *SQLiteRecord) any { return millis(r.Report.MaxTPOT) }},
+	{"avg_thinking_time_ms", "REAL", func(r *SQLiteRecord) any { return millis(r.Report.AvgThinkingTime) }},
+	{"avg_dns_time_ms", "REAL", func(r *SQLiteRecord) any { return millis(r.Report.AvgDNSTime) }},
+	{"avg_connect_time_ms", "REAL", func(r *SQLiteRecord) any { return millis(r.Report.AvgConnectTime) }},
+	{"avg_tls_handshake_time_ms", "REAL", func(r *SQLiteRecord) any { return millis(r.Report.AvgTLSHandshakeTime) }},
+	{"target_ip", "TEXT", func(r *SQLiteRecord) any { return r.Report.TargetIP }},
+	{"avg_tps", "REAL", func(r *SQLiteRecord) any { return r.Report.AvgTPS }},
+	{"min_tps", "REAL", func(r *SQLiteRecord) any { return r.Report.MinTPS }},
+	{"max_tps", "REAL", func(r *SQLiteRecord) any { return r.Report.MaxTPS }},
+	{"avg_steady_tps", "REAL", func(r *SQLiteRecord) any { return r.Report.AvgSteadyTPS }},
+	{"avg_total_throughput_tps", "REAL", func(r *SQLiteRecord) any { return r.Report.AvgTotalThroughputTPS }},
+	{"rpm", "REAL", func(r *SQLiteRecord) any { return r.Report.RPM }},
+	{"tpm", "REAL", func(r *SQLiteRecord) any { return r.Report.TPM }},
+	{"avg_input_tokens", "INTEGER", func(r *SQLiteRecord) any { return r.Report.AvgInputTokenCount }},
+	{"avg_cached_input_tokens", "INTEGER", func(r *SQLiteRecord) any { return r.Report.AvgCachedInputTokenCount }},
+	{"avg_output_tokens", "INTEGER", func(r *SQLiteRecord) any { return r.Report.AvgOutputTokenCount }},
+	{"avg_thinking_tokens", "INTEGER", func(r *SQLiteRecord) any { return r.Report.AvgThinkingTokenCount }},
+	{"avg_cache_hit_rate", "REAL", func(r *SQLiteRecord) any { return r.Report.AvgCacheHitRate }},
+	{"config_json", "TEXT", func(r *SQLiteRecord) any { return sqliteConfigJSON(r.Input) }},
+	{"report_json", "TEXT", func(r *SQLiteRecord) any { return sqliteJSON(r.Report) }},
+}
+
+// WriteSQLite 把 records 写入 path 指向的 SQLite 数据库的 results 表，库或表不存在时创建，
+// 旧版本建的表缺少的列会自动补上。同一次运行的同一份结果（run_id + seq）重复写入时覆盖，不会产生重复行。
+func WriteSQLite(path string, records []SQLiteRecord) error {
+	if len(records) == 0 {
+		return nil
+	}
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	if err := ensureSQLiteSchema(db); err != nil {
+		return err
+	}
+
+	names := make([]string, len(sqliteColumns))
+	for i, c := range sqliteColumns {
+		names[i] = c.name
+	}
+	stmt := fmt.Sprintf("INSERT OR REPLACE INTO results (%s) VALUES (%s)",
+		strings.Join(names, ", "), strings.TrimSuffix(strings.Repeat("?, ", len(names)), ", "))
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	for i := range records {
+		r := &records[i]
+		args := make([]any, len(sqliteColumns))
+		for j, c := range sqliteColumns {
+			args[j] = c.value(r)
+		}
+		if _, err := tx.Exec(stmt, args...); err != nil {
+			return fmt.Errorf("写入 %s 失败: %w", r.RunID, err)
+		}
+	}
+	return tx.Commit()
+}
+
+// ensureSQLiteSchema 建表与索引（已存在时跳过），并为旧表补齐新增的列。
+func ensureSQLiteSchema(db *sql.DB) error {
+	defs := make([]string, 0, len(sqliteColumns)+2)
+	defs = append(defs, "id INTEGER PRIMARY KEY AUTOINCREMENT")
+	for _, c := range sqliteColumns {
+		defs = append(defs, c.name+" "+c.typ)
+	}
+	defs = append(defs, "UNIQUE (run_id, seq)")
+	if _, err := db.Exec("CREATE TABLE IF NOT EXISTS results (\n  " + strings.Join(defs, ",\n  ") + "\n)"); err != nil {
+		return err
+	}
+
+	rows, err := db.Query("SELECT name FROM pragma_table_info('results')")
+	if err != nil {
+		return err
+	}
+	existing := map[string]bool{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return err
+		}
+		existing[name] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	for _, c := range sqliteColumns {
+		if existing[c.name] {
+			continue
+		}
+		// ALTER TABLE 不能加 NOT NULL 且无默认值的列；新增列都是可空的指标列
+		typ := strings.TrimSuffix(c.typ, " NOT NULL")
+		if _, err := db.Exec(fmt.Sprintf("ALTER TABLE results ADD COLUMN %s %s", c.name, typ)); err != nil {
+			return err
+		}
+	}
+	for _, stmt := range []string{
+		"CREATE INDEX IF NOT EXISTS idx_results_timestamp ON results (timestamp)",
+		"CREATE INDEX IF NOT EXISTS idx_results_model ON results (model, timestamp)",
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sqliteConfigJSON 返回去除密钥与 webhook 地址的任务配置 JSON。
+func sqliteConfigJSON(input types.Input) string {
+	input.ApiKey = ""
+	input.WebhookURL = ""
+	return sqliteJSON(input)
+}
+
+func sqliteJSON(v any) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
@@ -0,0 +1,90 @@
+package report
+
+import (
+	"database/sql"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yinxulai/ait/internal/server/types"
+)
+
+func sqliteTestRecords() []SQLiteRecord {
+	data := markdownTestData()
+	input := types.Input{Protocol: types.ProtocolOpenAICompletions, Model: "gpt-4o", ApiKey: "sk-secret", Count: 10, Concurrency: 4}
+	return []SQLiteRecord{
+		{RunID: "run-1", TaskID: "task-1", TaskName: "bench", Seq: 0, Input: input, Report: &data[0]},
+		{RunID: "run-1", TaskID: "task-1", TaskName: "bench", Seq: 1, Input: input, Report: &data[1]},
+	}
+}
+
+func TestWriteSQLite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "results.db")
+	records := sqliteTestRecords()
+	// 重复写入同一次运行的结果不产生重复行
+	for range 2 {
+		if err := WriteSQLite(path, records); err != nil {
+			t.Fatalf("WriteSQLite: %v", err)
+		}
+	}
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	var count int
+	if err := db.QueryRow("SELECT COUNT(*) FROM results").Scan(&count); err != nil || count != 2 {
+		t.Fatalf("row count = %d, %v, want 2", count, err)
+	}
+
+	var model, taskName, config, reportJSON string
+	var concurrency int
+	var avgTTFT, successRate float64
+	err = db.QueryRow("SELECT model, task_name, concurrency, avg_ttft_ms, success_rate, config_json, report_json FROM results WHERE seq = 0").
+		Scan(&model, &taskName, &concurrency, &avgTTFT, &successRate, &config, &reportJSON)
+	if err != nil {
+		t.Fatalf("query: %v", err)
+	}
+	want := records[0].Report
+	if model != want.Model || taskName != "bench" || concurrency != want.Concurrency ||
+		avgTTFT != millis(want.AvgTTFT) || successRate != want.SuccessRate {
+		t.Errorf("row = %s %s %d %g %g", model, taskName, concurrency, avgTTFT, successRate)
+	}
+	if strings.Contains(config, "sk-secret") || !strings.Contains(config, `"count":10`) {
+		t.Errorf("config_json = %s", config)
+	}
+	var tps float64
+	if err := db.QueryRow("SELECT json_extract(report_json, '$.avg_tps') FROM results WHERE seq = 0").Scan(&tps); err != nil || tps != want.AvgTPS {
+		t.Errorf("json_extract avg_tps = %g, %v, want %g", tps, err, want.AvgTPS)
+	}
+}
+
+func TestWriteSQLite_AddsMissingColumns(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "results.db")
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 旧版本建的表只有部分列
+	if _, err := db.Exec("CREATE TABLE results (id INTEGER PRIMARY KEY AUTOINCREMENT, run_id TEXT NOT NULL, seq INTEGER NOT NULL, model TEXT, UNIQUE (run_id, seq))"); err != nil {
+		t.Fatal(err)
+	}
+	db.Close()
+
+	records := sqliteTestRecords()[:1]
+	records[0].Report.AvgTTFB = 80 * time.Millisecond
+	if err := WriteSQLite(path, records); err != nil {
+		t.Fatalf("WriteSQLite: %v", err)
+	}
+	db, err = sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	var ttfb float64
+	if err := db.QueryRow("SELECT avg_ttfb_ms FROM results").Scan(&ttfb); err != nil || ttfb != 80 {
+		t.Errorf("avg_ttfb_ms = %g, %v, want 80", ttfb, err)
+	}
+}
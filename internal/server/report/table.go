@@ -0,0 +1,157 @@
+package report
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+
+	"github.com/yinxulai/ait/internal/server/types"
+)
+
+// 终端结果表格式
+const (
+	TableFormatTSV = "tsv"
+	TableFormatCSV = "csv"
+)
+
+// tableColumns 结果表的列：表头与取值函数。耗时统一为毫秒，无颜色无边框，便于直接粘贴进表格软件。
+var tableColumns = []struct {
+	header string
+	value  func(d *types.ReportData) string
+}{
+	{"model", func(d *types.ReportData) string { return scenarioLabel(d.Model, d) }},
+	{"stream_mode", func(d *types.ReportData) string { return tableStreamMode(d) }},
+	{"concurrency", func(d *types.ReportData) string { return strconv.Itoa(d.Concurrency) }},
+	{"total_requests", func(d *types.ReportData) string { return strconv.Itoa(d.TotalRequests) }},
+	{"success_rate_percent", func(d *types.ReportData) string { return formatTableFloat(d.SuccessRate) }},
+	{"avg_total_time_ms", func(d *types.ReportData) string { return formatMillisForCSV(d.AvgTotalTime) }},
+	{"avg_ttft_ms", func(d *types.ReportData) string { return streamOnly(d, formatMillisForCSV(d.AvgTTFT)) }},
+	{"avg_tpot_ms", func(d *types.ReportData) string { return streamOnly(d, formatMillisForCSV(d.AvgTPOT)) }},
+	{"avg_tps", func(d *types.ReportData) string { return formatTableFloat(d.AvgTPS) }},
+	{"avg_steady_tps", func(d *types.ReportData) string { return streamOnly(d, formatTableFloat(d.AvgSteadyTPS)) }},
+	{"avg_total_throughput_tps", func(d *types.ReportData) string { return formatTableFloat(d.AvgTotalThroughputTPS) }},
+	{"rpm", func(d *types.ReportData) string { return formatTableFloat(d.RPM) }},
+	{"tpm", func(d *types.ReportData) string { return formatTableFloat(d.TPM) }},
+	{"avg_normalized_tps", func(d *types.ReportData) string { return normalizedOnly(d, formatTableFloat(d.AvgNormalizedTPS)) }},
+}
+
+// TargetIPTableHeaders "按目标 IP"小表的表头。
+var TargetIPTableHeaders = []string{"model", "target_ip", "requests", "avg_total_time_ms", "avg_ttft_ms"}
+
+// TableHeaders 返回结果表的表头，与 WriteTable 输出的首行一致。
+func TableHeaders() []string {
+	headers := make([]string, len(tableColumns))
+	for i, col := range tableColumns {
+		headers[i] = col.header
+	}
+	return headers
+}
+
+// IsTableFormat 返回 format 是否为受支持的终端结果表格式。
+func IsTableFormat(format string) bool {
+	return format == TableFormatTSV || format == TableFormatCSV
+}
+
+// WriteTable 把结果表以 TSV 或 CSV 写入 w，每份 ReportData 一行。
+func WriteTable(w io.Writer, format string, data []types.ReportData) error {
+	if !IsTableFormat(format) {
+		return fmt.Errorf("unsupported table format: %s", format)
+	}
+	writer := csv.NewWriter(w)
+	if format == TableFormatTSV {
+		writer.Comma = '\t'
+	}
+
+	if err := writer.Write(TableHeaders()); err != nil {
+		return err
+	}
+	for i := range data {
+		row := make([]string, len(tableColumns))
+		for j, col := range tableColumns {
+			row[j] = col.value(&data[i])
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// WriteTargetIPTable 为命中多个目标 IP 的运行输出"按目标 IP"的小表（TSV 或 CSV），每个 IP 一行；
+// 所有运行都只有一个 IP 时不输出。
+func WriteTargetIPTable(w io.Writer, format string, data []types.ReportData) error {
+	if !IsTableFormat(format) {
+		return fmt.Errorf("unsupported table format: %s", format)
+	}
+	writer := csv.NewWriter(w)
+	if format == TableFormatTSV {
+		writer.Comma = '\t'
+	}
+
+	wroteHeader := false
+	for i := range data {
+		d := &data[i]
+		ips := SortedTargetIPs(d.TargetIPStats)
+		if len(ips) <= 1 {
+			continue
+		}
+		if !wroteHeader {
+			if err := writer.Write(TargetIPTableHeaders); err != nil {
+				return err
+			}
+			wroteHeader = true
+		}
+		for _, ip := range ips {
+			s := d.TargetIPStats[ip]
+			row := []string{scenarioLabel(d.Model, d), ip, strconv.Itoa(s.Count), formatMillisForCSV(s.AvgTotalTime), streamOnly(d, formatMillisForCSV(s.AvgTTFT))}
+			if err := writer.Write(row); err != nil {
+				return err
+			}
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// SortedTargetIPs 按 IP 字符串排序返回 stats 中的目标 IP，保证输出顺序稳定。
+func SortedTargetIPs(stats map[string]types.TargetIPStats) []string {
+	ips := make([]string, 0, len(stats))
+	for ip := range stats {
+		ips = append(ips, ip)
+	}
+	sort.Strings(ips)
+	return ips
+}
+
+func tableStreamMode(d *types.ReportData) string {
+	if d.StreamMode != "" {
+		return d.StreamMode
+	}
+	if d.IsStream {
+		return types.StreamModeStream
+	}
+	return types.StreamModeNonStream
+}
+
+// streamOnly 非流式请求的 TTFT/TPOT/稳态 TPS 没有意义，留空。
+func streamOnly(d *types.ReportData, value string) string {
+	if !d.IsStream {
+		return ""
+	}
+	return value
+}
+
+// normalizedOnly 未开启本地重新计 token 时归一化 TPS 留空。
+func normalizedOnly(d *types.ReportData, value string) string {
+	if d.AvgNormalizedTPS == 0 {
+		return ""
+	}
+	return value
+}
+
+func formatTableFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', 2, 64)
+}
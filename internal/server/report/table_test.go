@@ -0,0 +1,96 @@
+package report
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yinxulai/ait/internal/server/types"
+)
+
+func tableTestData() []types.ReportData {
+	return []types.ReportData{
+		{Model: "gpt-4o", IsStream: true, Concurrency: 4, TotalRequests: 10, SuccessRate: 100,
+			AvgTotalTime: 1500 * time.Millisecond, AvgTTFT: 250 * time.Millisecond, AvgTPOT: 12500 * time.Microsecond, AvgTPS: 42.123, AvgSteadyTPS: 50.5, RPM: 60,
+			AvgNormalizedTPS: 38.5},
+		{Model: "gpt-4o", Concurrency: 4, TotalRequests: 10, SuccessRate: 90, AvgTotalTime: time.Second, AvgTTFT: time.Second},
+	}
+}
+
+func TestWriteTable_TSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteTable(&buf, TableFormatTSV, tableTestData()); err != nil {
+		t.Fatalf("WriteTable: %v", err)
+	}
+	lines := strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("got %d lines, want header + 2 rows:\n%s", len(lines), buf.String())
+	}
+
+	header := strings.Split(lines[0], "\t")
+	if len(header) != len(tableColumns) || header[0] != "model" || header[5] != "avg_total_time_ms" {
+		t.Errorf("unexpected header: %q", lines[0])
+	}
+	stream := strings.Split(lines[1], "\t")
+	want := []string{"gpt-4o", "stream", "4", "10", "100.00", "1500.000", "250.000", "12.500", "42.12", "50.50", "0.00", "60.00", "0.00", "38.50"}
+	if strings.Join(stream, "|") != strings.Join(want, "|") {
+		t.Errorf("stream row = %q\nwant       %q", stream, want)
+	}
+	nonStream := strings.Split(lines[2], "\t")
+	if nonStream[1] != "non-stream" || nonStream[6] != "" || nonStream[7] != "" || nonStream[9] != "" {
+		t.Errorf("non-stream row should leave TTFT/TPOT/steady TPS empty: %q", nonStream)
+	}
+	if nonStream[13] != "" {
+		t.Errorf("normalized TPS should be empty when not enabled: %q", nonStream)
+	}
+	if strings.ContainsRune(buf.String(), '\x1b') {
+		t.Error("table output must not contain ANSI escapes")
+	}
+}
+
+func TestWriteTable_CSV(t *testing.T) {
+	var buf bytes.Buffer
+	data := []types.ReportData{{Model: "a,b"}}
+	if err := WriteTable(&buf, TableFormatCSV, data); err != nil {
+		t.Fatalf("WriteTable: %v", err)
+	}
+	if !strings.Contains(buf.String(), "\n\"a,b\",non-stream,") {
+		t.Errorf("CSV row should quote fields containing commas:\n%s", buf.String())
+	}
+}
+
+func TestWriteTable_UnsupportedFormat(t *testing.T) {
+	if err := WriteTable(&bytes.Buffer{}, "markdown", nil); err == nil {
+		t.Fatal("expected error for unsupported format")
+	}
+	if IsTableFormat("") || !IsTableFormat("tsv") {
+		t.Error("IsTableFormat mismatch")
+	}
+}
+
+func TestWriteTargetIPTable(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteTargetIPTable(&buf, TableFormatTSV, tableTestData()); err != nil {
+		t.Fatalf("WriteTargetIPTable: %v", err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("single-ip runs should produce no output, got:\n%s", buf.String())
+	}
+
+	data := tableTestData()
+	data[0].TargetIPStats = map[string]types.TargetIPStats{
+		"10.0.0.2": {Count: 3, AvgTotalTime: 4 * time.Second, AvgTTFT: time.Second},
+		"10.0.0.1": {Count: 7, AvgTotalTime: time.Second, AvgTTFT: 200 * time.Millisecond},
+	}
+	data[1].TargetIPStats = map[string]types.TargetIPStats{"10.0.0.1": {Count: 10}}
+	if err := WriteTargetIPTable(&buf, TableFormatTSV, data); err != nil {
+		t.Fatalf("WriteTargetIPTable: %v", err)
+	}
+	want := "model\ttarget_ip\trequests\tavg_total_time_ms\tavg_ttft_ms\n" +
+		"gpt-4o\t10.0.0.1\t7\t1000.000\t200.000\n" +
+		"gpt-4o\t10.0.0.2\t3\t4000.000\t1000.000\n"
+	if buf.String() != want {
+		t.Errorf("WriteTargetIPTable =\n%q\nwant\n%q", buf.String(), want)
+	}
+}
@@ -0,0 +1,246 @@
+package report
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ModelTrend 一个模型的历史记录，按时间升序排列。
+type ModelTrend struct {
+	Model   string
+	Entries []HistoryEntry
+}
+
+// BuildTrends 按模型分组历史记录：模型按名称排序，组内按时间升序（同一时间保持原有顺序）。
+func BuildTrends(entries []HistoryEntry) []ModelTrend {
+	index := make(map[string]int)
+	var trends []ModelTrend
+	for _, entry := range entries {
+		i, ok := index[entry.Model]
+		if !ok {
+			i = len(trends)
+			index[entry.Model] = i
+			trends = append(trends, ModelTrend{Model: entry.Model})
+		}
+		trends[i].Entries = append(trends[i].Entries, entry)
+	}
+	sort.Slice(trends, func(i, j int) bool { return trends[i].Model < trends[j].Model })
+	for i := range trends {
+		sort.SliceStable(trends[i].Entries, func(a, b int) bool {
+			return trends[i].Entries[a].Time.Before(trends[i].Entries[b].Time)
+		})
+	}
+	return trends
+}
+
+// trendTimeLayout 趋势表中的时间格式，保留记录自身的时区
+const trendTimeLayout = "2006-01-02 15:04"
+
+// trendHeaders 趋势表的列，Markdown 与 HTML 共用
+var trendHeaders = []string{"时间", "模式", "并发", "请求数", "成功率 (%)", "平均总耗时 (ms)", "平均 TTFT (ms)", "平均 TPS", "RPM", "TPM"}
+
+// trendRow 把一条记录渲染为趋势表的一行，没有 TTFT 的记录该列为 "-"。
+func trendRow(e HistoryEntry) []string {
+	ttft := "-"
+	if e.AvgTTFTMs > 0 {
+		ttft = formatMarkdownMillis(e.AvgTTFTMs)
+	}
+	return []string{
+		e.Time.Format(trendTimeLayout),
+		e.StreamMode,
+		strconv.Itoa(e.Concurrency),
+		strconv.Itoa(e.TotalRequests),
+		formatTableFloat(e.SuccessRate),
+		formatMarkdownMillis(e.AvgTotalMs),
+		ttft,
+		formatTableFloat(e.AvgTPS),
+		formatTableFloat(e.RPM),
+		formatTableFloat(e.TPM),
+	}
+}
+
+// WriteTrendMarkdown 以 Markdown 输出趋势：每个模型一节，节内一张按时间排序的表。
+func WriteTrendMarkdown(w io.Writer, trends []ModelTrend) error {
+	if len(trends) == 0 {
+		return fmt.Errorf("no history to render")
+	}
+	var b strings.Builder
+	b.WriteString("## ait 历史趋势\n")
+	for _, trend := range trends {
+		fmt.Fprintf(&b, "\n### %s\n\n", escapeMarkdown(trend.Model))
+		writeMarkdownRow(&b, trendHeaders)
+		align := make([]string, len(trendHeaders))
+		for i := range align {
+			align[i] = "---:"
+		}
+		align[0], align[1] = "---", "---"
+		writeMarkdownRow(&b, align)
+		for _, entry := range trend.Entries {
+			writeMarkdownRow(&b, trendRow(entry))
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// 趋势图尺寸（SVG 像素），四周留白 trendChartPad
+const (
+	trendChartWidth  = 480
+	trendChartHeight = 120
+	trendChartPad    = 8
+)
+
+type trendChart struct {
+	Title  string
+	Points string // SVG polyline 的 points 属性
+	Dots   []trendDot
+	Min    string
+	Max    string
+}
+
+type trendDot struct {
+	X, Y  string
+	Label string // 悬停提示：时间与数值
+}
+
+type trendSection struct {
+	Model  string
+	Charts []trendChart
+	Rows   [][]string
+}
+
+type trendPage struct {
+	Width, Height int
+	Headers       []string
+	Sections      []trendSection
+}
+
+// trendSeries 趋势图的指标；value 返回数值与是否有值（非流式运行没有 TTFT）
+var trendSeries = []struct {
+	title string
+	value func(e HistoryEntry) (float64, bool)
+}{
+	{"平均 TPS", func(e HistoryEntry) (float64, bool) { return e.AvgTPS, true }},
+	{"平均 TTFT (ms)", func(e HistoryEntry) (float64, bool) { return e.AvgTTFTMs, e.AvgTTFTMs > 0 }},
+	{"成功率 (%)", func(e HistoryEntry) (float64, bool) { return e.SuccessRate, true }},
+}
+
+// buildTrendChart 把一个指标随时间的变化画成折线：横轴按记录顺序等距排列，纵轴按该指标的最小 / 最大值缩放。
+// 没有任何记录有值时返回 false。
+func buildTrendChart(title string, entries []HistoryEntry, value func(HistoryEntry) (float64, bool)) (trendChart, bool) {
+	type point struct {
+		pos   int
+		value float64
+		entry HistoryEntry
+	}
+	var points []point
+	for i, entry := range entries {
+		if v, ok := value(entry); ok {
+			points = append(points, point{i, v, entry})
+		}
+	}
+	if len(points) == 0 {
+		return trendChart{}, false
+	}
+	lo, hi := points[0].value, points[0].value
+	for _, p := range points {
+		lo, hi = min(lo, p.value), max(hi, p.value)
+	}
+
+	chart := trendChart{Title: title, Min: formatTableFloat(lo), Max: formatTableFloat(hi)}
+	plotW := float64(trendChartWidth - 2*trendChartPad)
+	plotH := float64(trendChartHeight - 2*trendChartPad)
+	coords := make([]string, 0, len(points))
+	for _, p := range points {
+		x := float64(trendChartPad) + plotW/2
+		if len(entries) > 1 {
+			x = float64(trendChartPad) + plotW*float64(p.pos)/float64(len(entries)-1)
+		}
+		y := float64(trendChartPad) + plotH/2
+		if hi > lo {
+			y = float64(trendChartPad) + plotH*(hi-p.value)/(hi-lo)
+		}
+		xs, ys := strconv.FormatFloat(x, 'f', 1, 64), strconv.FormatFloat(y, 'f', 1, 64)
+		coords = append(coords, xs+","+ys)
+		chart.Dots = append(chart.Dots, trendDot{X: xs, Y: ys, Label: p.entry.Time.Format(trendTimeLayout) + "  " + formatTableFloat(p.value)})
+	}
+	chart.Points = strings.Join(coords, " ")
+	return chart, true
+}
+
+var trendHTMLTemplate = template.Must(template.New("trend").Parse(`<!DOCTYPE html>
+<html lang="zh-CN">
+<head>
+<meta charset="utf-8">
+<title>ait 历史趋势</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", sans-serif; margin: 24px; color: #222; }
+h2 { margin-top: 32px; }
+.charts { display: flex; flex-wrap: wrap; gap: 16px; }
+figure { margin: 0; }
+figcaption { font-size: 13px; color: #555; }
+svg { background: #fafafa; border: 1px solid #ddd; }
+polyline { fill: none; stroke: #2f6fde; stroke-width: 2; }
+circle { fill: #2f6fde; }
+table { border-collapse: collapse; margin-top: 16px; font-size: 13px; }
+th, td { border: 1px solid #ddd; padding: 4px 8px; text-align: right; }
+th:nth-child(-n+2), td:nth-child(-n+2) { text-align: left; }
+</style>
+</head>
+<body>
+<h1>ait 历史趋势</h1>
+{{- range .Sections}}
+<h2>{{.Model}}</h2>
+<div class="charts">
+{{- range .Charts}}
+<figure>
+<figcaption>{{.Title}}（{{.Min}} ~ {{.Max}}）</figcaption>
+<svg width="{{$.Width}}" height="{{$.Height}}" viewBox="0 0 {{$.Width}} {{$.Height}}">
+<polyline points="{{.Points}}"/>
+{{- range .Dots}}
+<circle cx="{{.X}}" cy="{{.Y}}" r="3"><title>{{.Label}}</title></circle>
+{{- end}}
+</svg>
+</figure>
+{{- end}}
+</div>
+<table>
+<tr>{{range $.Headers}}<th>{{.}}</th>{{end}}</tr>
+{{- range .Rows}}
+<tr>{{range .}}<td>{{.}}</td>{{end}}</tr>
+{{- end}}
+</table>
+{{- end}}
+</body>
+</html>
+`))
+
+// WriteTrendHTML 输出独立的 HTML 趋势页：每个模型一节，包含各指标随时间变化的折线图与趋势表。
+func WriteTrendHTML(w io.Writer, trends []ModelTrend) error {
+	if len(trends) == 0 {
+		return fmt.Errorf("no history to render")
+	}
+	sections := make([]trendSection, 0, len(trends))
+	for _, trend := range trends {
+		section := trendSection{Model: trend.Model}
+		for _, series := range trendSeries {
+			if chart, ok := buildTrendChart(series.title, trend.Entries, series.value); ok {
+				section.Charts = append(section.Charts, chart)
+			}
+		}
+		for _, entry := range trend.Entries {
+			section.Rows = append(section.Rows, trendRow(entry))
+		}
+		sections = append(sections, section)
+	}
+	return trendHTMLTemplate.Execute(w, trendPage{
+		Width:    trendChartWidth,
+		Height:   trendChartHeight,
+		Headers:  trendHeaders,
+		Sections: sections,
+	})
+}
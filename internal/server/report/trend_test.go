@@ -0,0 +1,130 @@
+package report
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yinxulai/ait/internal/server/types"
+)
+
+func TestAppendHistory_RoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	data := markdownTestData()
+	if err := AppendHistory(path, data[:2]); err != nil {
+		t.Fatalf("AppendHistory: %v", err)
+	}
+	if err := AppendHistory(path, data[2:]); err != nil {
+		t.Fatalf("AppendHistory: %v", err)
+	}
+	if err := AppendHistory(path, nil); err != nil {
+		t.Fatalf("AppendHistory(nil): %v", err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	entries, err := ReadHistory(f)
+	if err != nil {
+		t.Fatalf("ReadHistory: %v", err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("got %d entries, want 3", len(entries))
+	}
+	first := entries[0]
+	if first.Model != "gpt-4o" || first.StreamMode != types.StreamModeStream || first.AvgTTFTMs != 250 || first.AvgTotalMs != 1500 || first.Concurrency != 4 {
+		t.Errorf("first entry = %+v", first)
+	}
+	if !first.Time.Equal(time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)) {
+		t.Errorf("time = %v, want the report timestamp", first.Time)
+	}
+	if entries[1].Model != "Qwen|Max" {
+		t.Errorf("model display name should be used, got %q", entries[1].Model)
+	}
+	if entries[2].AvgTTFTMs != 0 {
+		t.Errorf("non-stream entry should have no TTFT, got %v", entries[2].AvgTTFTMs)
+	}
+}
+
+func TestReadHistory_Errors(t *testing.T) {
+	if _, err := ReadHistory(strings.NewReader("{\"time\":\"2025-01-02T03:04:05Z\",\"model\":\"m\"}\n\nnot json\n")); err == nil || !strings.Contains(err.Error(), "line 3") {
+		t.Errorf("want error on line 3, got %v", err)
+	}
+	if _, err := ReadHistory(strings.NewReader(`{"model":"m"}`)); err == nil {
+		t.Error("want error for entry without time")
+	}
+}
+
+func trendTestEntries() []HistoryEntry {
+	at := func(day int) time.Time { return time.Date(2025, 1, day, 10, 0, 0, 0, time.UTC) }
+	return []HistoryEntry{
+		{Time: at(3), Model: "gpt-4o", StreamMode: "stream", Concurrency: 4, TotalRequests: 10, SuccessRate: 100, AvgTotalMs: 1400, AvgTTFTMs: 240, AvgTPS: 45, RPM: 60, TPM: 3000},
+		{Time: at(2), Model: "claude", StreamMode: "non-stream", Concurrency: 8, TotalRequests: 20, SuccessRate: 95, AvgTotalMs: 2000, AvgTPS: 30, RPM: 120, TPM: 2000},
+		{Time: at(1), Model: "gpt-4o", StreamMode: "stream", Concurrency: 4, TotalRequests: 10, SuccessRate: 90, AvgTotalMs: 1500, AvgTTFTMs: 250, AvgTPS: 42.5, RPM: 58, TPM: 2900},
+		{Time: at(5), Model: "gpt-4o", StreamMode: "stream", Concurrency: 4, TotalRequests: 10, SuccessRate: 100, AvgTotalMs: 1300, AvgTTFTMs: 200, AvgTPS: 50, RPM: 62, TPM: 3100},
+	}
+}
+
+func TestBuildTrends_GroupsByModelSortedByTime(t *testing.T) {
+	trends := BuildTrends(trendTestEntries())
+	if len(trends) != 2 || trends[0].Model != "claude" || trends[1].Model != "gpt-4o" {
+		t.Fatalf("trends = %+v", trends)
+	}
+	var days []int
+	for _, e := range trends[1].Entries {
+		days = append(days, e.Time.Day())
+	}
+	if len(days) != 3 || days[0] != 1 || days[1] != 3 || days[2] != 5 {
+		t.Errorf("gpt-4o entries by day = %v, want [1 3 5]", days)
+	}
+}
+
+func checkTrendGolden(t *testing.T, name string, render func(*bytes.Buffer) error) {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := render(&buf); err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	golden := filepath.Join("testdata", name)
+	if *updateGolden {
+		if err := os.WriteFile(golden, buf.Bytes(), 0o644); err != nil {
+			t.Fatalf("update golden: %v", err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatalf("read golden: %v", err)
+	}
+	if buf.String() != string(want) {
+		t.Errorf("output differs from %s (run with -update to refresh)\n--- got ---\n%s\n--- want ---\n%s", golden, buf.String(), want)
+	}
+}
+
+func TestWriteTrendHTML_Golden(t *testing.T) {
+	trends := BuildTrends(trendTestEntries())
+	checkTrendGolden(t, "trend.html.golden", func(buf *bytes.Buffer) error { return WriteTrendHTML(buf, trends) })
+}
+
+func TestWriteTrendMarkdown_Golden(t *testing.T) {
+	trends := BuildTrends(trendTestEntries())
+	checkTrendGolden(t, "trend_md.golden", func(buf *bytes.Buffer) error { return WriteTrendMarkdown(buf, trends) })
+}
+
+func TestWriteTrendHTML_EscapesModel(t *testing.T) {
+	var buf bytes.Buffer
+	entries := []HistoryEntry{{Time: time.Now(), Model: "<script>alert(1)</script>", AvgTPS: 1}}
+	if err := WriteTrendHTML(&buf, BuildTrends(entries)); err != nil {
+		t.Fatalf("WriteTrendHTML: %v", err)
+	}
+	if strings.Contains(buf.String(), "<script>") {
+		t.Error("model name must be HTML-escaped")
+	}
+	if err := WriteTrendHTML(&buf, nil); err == nil {
+		t.Error("want error for empty history")
+	}
+}
@@ -0,0 +1,262 @@
+package report
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/yinxulai/ait/internal/server/types"
+)
+
+// webhook 消息格式
+const (
+	WebhookFormatGeneric = "generic"
+	WebhookFormatFeishu  = "feishu"
+	WebhookFormatSlack   = "slack"
+	WebhookFormatWeCom   = "wecom"
+)
+
+// WebhookRetries 首次发送失败后的重试次数
+const WebhookRetries = 2
+
+// webhookTopErrors 消息中展示的错误条数上限
+const webhookTopErrors = 3
+
+// ErrorCount 一类错误及其出现次数
+type ErrorCount struct {
+	Message string `json:"message"`
+	Count   int    `json:"count"`
+}
+
+// WebhookSummary 推送到 webhook 的一次运行摘要。
+// 只包含核心指标，不含 apiKey、baseUrl 等连接信息。
+type WebhookSummary struct {
+	TaskName  string
+	Status    string
+	Reports   []types.ReportData
+	TopErrors []ErrorCount
+}
+
+// WebhookRenderer 把运行摘要渲染成对应平台的消息 JSON
+type WebhookRenderer struct {
+	Format string // generic / feishu / slack / wecom，空值按 generic 处理
+}
+
+// IsWebhookFormat 返回 format 是否为受支持的 webhook 消息格式（空值视为 generic）。
+func IsWebhookFormat(format string) bool {
+	switch format {
+	case "", WebhookFormatGeneric, WebhookFormatFeishu, WebhookFormatSlack, WebhookFormatWeCom:
+		return true
+	}
+	return false
+}
+
+// Render 渲染消息体
+func (wr *WebhookRenderer) Render(summary WebhookSummary) ([]byte, error) {
+	switch wr.Format {
+	case "", WebhookFormatGeneric:
+		return json.Marshal(genericWebhookMessage(summary))
+	case WebhookFormatFeishu:
+		return json.Marshal(map[string]any{
+			"msg_type": "interactive",
+			"card": map[string]any{
+				"header": map[string]any{
+					"title":    map[string]any{"tag": "plain_text", "content": webhookTitle(summary)},
+					"template": feishuTemplate(summary),
+				},
+				"elements": []any{
+					map[string]any{"tag": "div", "text": map[string]any{"tag": "lark_md", "content": webhookMarkdown(summary, "**")}},
+				},
+			},
+		})
+	case WebhookFormatSlack:
+		return json.Marshal(map[string]any{
+			"text": webhookTitle(summary),
+			"blocks": []any{
+				map[string]any{"type": "header", "text": map[string]any{"type": "plain_text", "text": webhookTitle(summary)}},
+				map[string]any{"type": "section", "text": map[string]any{"type": "mrkdwn", "text": webhookMarkdown(summary, "*")}},
+			},
+		})
+	case WebhookFormatWeCom:
+		return json.Marshal(map[string]any{
+			"msgtype": "markdown",
+			"markdown": map[string]any{
+				"content": "## " + webhookTitle(summary) + "\n" + webhookMarkdown(summary, "**"),
+			},
+		})
+	default:
+		return nil, fmt.Errorf("unsupported webhook format: %s", wr.Format)
+	}
+}
+
+type genericWebhookModel struct {
+	Model       string  `json:"model"`
+	StreamMode  string  `json:"stream_mode"`
+	Requests    int     `json:"total_requests"`
+	SuccessRate float64 `json:"success_rate"`
+	AvgTTFTMs   float64 `json:"avg_ttft_ms"`
+	AvgTPS      float64 `json:"avg_tps"`
+}
+
+func genericWebhookMessage(summary WebhookSummary) map[string]any {
+	models := make([]genericWebhookModel, 0, len(summary.Reports))
+	for i := range summary.Reports {
+		d := &summary.Reports[i]
+		models = append(models, genericWebhookModel{
+			Model:       webhookModelName(d),
+			StreamMode:  tableStreamMode(d),
+			Requests:    d.TotalRequests,
+			SuccessRate: d.SuccessRate,
+			AvgTTFTMs:   millis(d.AvgTTFT),
+			AvgTPS:      d.AvgTPS,
+		})
+	}
+	topErrors := summary.TopErrors
+	if topErrors == nil {
+		topErrors = []ErrorCount{}
+	}
+	return map[string]any{
+		"task":       summary.TaskName,
+		"status":     summary.Status,
+		"models":     models,
+		"top_errors": topErrors,
+	}
+}
+
+func webhookTitle(summary WebhookSummary) string {
+	return fmt.Sprintf("AIT 测试完成: %s", summary.TaskName)
+}
+
+// feishuTemplate 有失败请求时卡片标题为橙色，否则为绿色
+func feishuTemplate(summary WebhookSummary) string {
+	for i := range summary.Reports {
+		if summary.Reports[i].SuccessRate < 100 {
+			return "orange"
+		}
+	}
+	return "green"
+}
+
+// webhookMarkdown 渲染消息正文，bold 为对应平台的加粗标记（飞书/企业微信 **，Slack *）
+func webhookMarkdown(summary WebhookSummary, bold string) string {
+	var b strings.Builder
+	for i := range summary.Reports {
+		d := &summary.Reports[i]
+		fmt.Fprintf(&b, "%s%s%s (%s, %d 请求)\n", bold, webhookModelName(d), bold, tableStreamMode(d), d.TotalRequests)
+		fmt.Fprintf(&b, "成功率 %.1f%% · 平均 TTFT %s · 平均 TPS %.1f\n", d.SuccessRate, webhookTTFT(d), d.AvgTPS)
+	}
+	if len(summary.TopErrors) > 0 {
+		fmt.Fprintf(&b, "%s错误 Top%d%s\n", bold, webhookTopErrors, bold)
+		for i, e := range summary.TopErrors {
+			fmt.Fprintf(&b, "%d. %s (%d 次)\n", i+1, e.Message, e.Count)
+		}
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+func webhookModelName(d *types.ReportData) string {
+	if d.ModelDisplayName != "" {
+		return scenarioLabel(d.ModelDisplayName, d)
+	}
+	return scenarioLabel(d.Model, d)
+}
+
+func webhookTTFT(d *types.ReportData) string {
+	if !d.IsStream {
+		return "-"
+	}
+	return fmt.Sprintf("%.0fms", millis(d.AvgTTFT))
+}
+
+// TopErrors 按错误指纹（与报告的错误分组一致，忽略 trace 标注、请求 ID 等易变部分）统计出现次数最多的 n 类错误，
+// 次数相同按消息排序；空消息忽略。
+func TopErrors(messages []string, n int) []ErrorCount {
+	counts := make(map[string]int)
+	for _, msg := range messages {
+		if fp := types.ErrorFingerprint(msg); fp != "" {
+			counts[fp]++
+		}
+	}
+	result := make([]ErrorCount, 0, len(counts))
+	for msg, count := range counts {
+		result = append(result, ErrorCount{Message: msg, Count: count})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Count != result[j].Count {
+			return result[i].Count > result[j].Count
+		}
+		return result[i].Message < result[j].Message
+	})
+	if len(result) > n {
+		result = result[:n]
+	}
+	return result
+}
+
+// RedactSecrets 把 msg 中出现的敏感字符串（apiKey、baseUrl 等）替换为 ***，空字符串忽略。
+func RedactSecrets(msg string, secrets ...string) string {
+	for _, secret := range secrets {
+		if strings.TrimSpace(secret) != "" {
+			msg = strings.ReplaceAll(msg, secret, "***")
+		}
+	}
+	return msg
+}
+
+// BuildWebhookSummary 由运行结果与失败请求的错误信息组装 webhook 摘要；
+// 错误信息中出现的 secrets 会被打码。
+func BuildWebhookSummary(taskName, status string, reports []types.ReportData, errorMessages []string, secrets ...string) WebhookSummary {
+	redacted := make([]string, len(errorMessages))
+	for i, msg := range errorMessages {
+		redacted[i] = RedactSecrets(msg, secrets...)
+	}
+	return WebhookSummary{
+		TaskName:  taskName,
+		Status:    status,
+		Reports:   reports,
+		TopErrors: TopErrors(redacted, webhookTopErrors),
+	}
+}
+
+// SendWebhook 把 body POST 到 url，失败（网络错误或非 2xx）时间隔 retryDelay 重试两次，
+// 返回最后一次的错误。
+func SendWebhook(ctx context.Context, client *http.Client, url string, body []byte, retryDelay time.Duration) error {
+	var lastErr error
+	for attempt := 0; attempt <= WebhookRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(retryDelay):
+			}
+		}
+		if lastErr = postWebhook(ctx, client, url, body); lastErr == nil {
+			return nil
+		}
+	}
+	return fmt.Errorf("webhook failed after %d attempts: %w", WebhookRetries+1, lastErr)
+}
+
+func postWebhook(ctx context.Context, client *http.Client, url string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
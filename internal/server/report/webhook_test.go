@@ -0,0 +1,149 @@
+package report
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/yinxulai/ait/internal/server/types"
+)
+
+func webhookTestSummary() WebhookSummary {
+	reports := []types.ReportData{{
+		Model:         "qwen-max",
+		IsStream:      true,
+		TotalRequests: 20,
+		SuccessRate:   95,
+		AvgTTFT:       320 * time.Millisecond,
+		AvgTPS:        45.25,
+		BaseUrl:       "https://api.example.com/v1",
+	}}
+	errors := []string{
+		"Post \"https://api.example.com/v1/chat/completions\": timeout",
+		"Post \"https://api.example.com/v1/chat/completions\": timeout",
+		"status 429",
+		"",
+	}
+	return BuildWebhookSummary("nightly", "completed", reports, errors, "sk-secret", "https://api.example.com/v1")
+}
+
+func renderWebhook(t *testing.T, format string) (string, map[string]any) {
+	t.Helper()
+	body, err := (&WebhookRenderer{Format: format}).Render(webhookTestSummary())
+	if err != nil {
+		t.Fatalf("Render(%s): %v", format, err)
+	}
+	var msg map[string]any
+	if err := json.Unmarshal(body, &msg); err != nil {
+		t.Fatalf("Render(%s) produced invalid JSON: %v", format, err)
+	}
+	if strings.Contains(string(body), "api.example.com") || strings.Contains(string(body), "sk-secret") {
+		t.Errorf("Render(%s) leaks connection info: %s", format, body)
+	}
+	return string(body), msg
+}
+
+func TestWebhookRenderer_Generic(t *testing.T) {
+	_, msg := renderWebhook(t, "")
+	if msg["task"] != "nightly" || msg["status"] != "completed" {
+		t.Errorf("task/status: %v", msg)
+	}
+	models := msg["models"].([]any)
+	model := models[0].(map[string]any)
+	want := map[string]any{
+		"model": "qwen-max", "stream_mode": "stream", "total_requests": float64(20),
+		"success_rate": float64(95), "avg_ttft_ms": float64(320), "avg_tps": 45.25,
+	}
+	if !reflect.DeepEqual(model, want) {
+		t.Errorf("model = %v, want %v", model, want)
+	}
+	topErrors := msg["top_errors"].([]any)
+	if len(topErrors) != 2 || topErrors[0].(map[string]any)["count"] != float64(2) {
+		t.Errorf("top_errors = %v", topErrors)
+	}
+}
+
+func TestWebhookRenderer_PlatformCards(t *testing.T) {
+	_, feishu := renderWebhook(t, WebhookFormatFeishu)
+	if feishu["msg_type"] != "interactive" {
+		t.Errorf("feishu msg_type = %v", feishu["msg_type"])
+	}
+	card := feishu["card"].(map[string]any)
+	if card["header"].(map[string]any)["template"] != "orange" {
+		t.Errorf("feishu header should be orange when requests failed: %v", card["header"])
+	}
+	content := card["elements"].([]any)[0].(map[string]any)["text"].(map[string]any)["content"].(string)
+	for _, want := range []string{"**qwen-max**", "成功率 95.0%", "平均 TTFT 320ms", "平均 TPS 45.2", "***/chat/completions"} {
+		if !strings.Contains(content, want) {
+			t.Errorf("feishu content missing %q:\n%s", want, content)
+		}
+	}
+
+	_, slack := renderWebhook(t, WebhookFormatSlack)
+	blocks := slack["blocks"].([]any)
+	if len(blocks) != 2 || blocks[1].(map[string]any)["text"].(map[string]any)["type"] != "mrkdwn" {
+		t.Errorf("slack blocks = %v", blocks)
+	}
+
+	_, wecom := renderWebhook(t, WebhookFormatWeCom)
+	if wecom["msgtype"] != "markdown" {
+		t.Errorf("wecom msgtype = %v", wecom["msgtype"])
+	}
+
+	if _, err := (&WebhookRenderer{Format: "teams"}).Render(webhookTestSummary()); err == nil {
+		t.Error("expected error for unsupported format")
+	}
+}
+
+func TestTopErrors(t *testing.T) {
+	got := TopErrors([]string{"b", "a", "c", "c", "b", "d", " "}, 3)
+	want := []ErrorCount{{"b", 2}, {"c", 2}, {"a", 1}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("TopErrors = %v, want %v", got, want)
+	}
+
+	// 每条错误都带有不同的 trace 标注，仍应按同一类错误计数
+	traced := []string{
+		"HTTP 429: rate limited [client_request_id=3f2b9c1e-8d4a-4f6b-9c2d-1a2b3c4d5e6f]",
+		"HTTP 429: rate limited [client_request_id=7a1c2d3e-4f5a-4b6c-8d7e-9f0a1b2c3d4e request_id=req_01abc]",
+		"HTTP 429: rate limited [request_id=req_02xyz]",
+		"HTTP 500: internal error [client_request_id=0b1c2d3e-4f5a-4b6c-8d7e-9f0a1b2c3d4e]",
+	}
+	got = TopErrors(traced, 3)
+	want = []ErrorCount{{"HTTP 429: rate limited", 3}, {"HTTP 500: internal error", 1}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("TopErrors with trace suffixes = %v, want %v", got, want)
+	}
+}
+
+func TestSendWebhook_Retries(t *testing.T) {
+	var attempts atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		if r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("Content-Type = %q", r.Header.Get("Content-Type"))
+		}
+	}))
+	defer srv.Close()
+
+	if err := SendWebhook(context.Background(), srv.Client(), srv.URL, []byte(`{}`), 0); err != nil {
+		t.Fatalf("SendWebhook should succeed on the third attempt: %v", err)
+	}
+	if got := attempts.Load(); got != 3 {
+		t.Errorf("attempts = %d, want 3", got)
+	}
+
+	attempts.Store(-10)
+	if err := SendWebhook(context.Background(), srv.Client(), srv.URL, []byte(`{}`), 0); err == nil {
+		t.Error("expected error after exhausting retries")
+	}
+}
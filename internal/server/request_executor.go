@@ -2,8 +2,12 @@ package server
 
 import (
 	"context"
+	"time"
 
 	"github.com/yinxulai/ait/internal/server/client"
+	"github.com/yinxulai/ait/internal/server/clock"
+	"github.com/yinxulai/ait/internal/server/network"
+	"github.com/yinxulai/ait/internal/server/ratelimit"
 	"github.com/yinxulai/ait/internal/server/types"
 )
 
@@ -14,6 +18,9 @@ type RequestJob struct {
 	Input  types.Input
 	Level  int
 	CaseID string
+
+	// DispatchedAt 派发给执行 goroutine 的时间，由请求队列填写；为零时不统计本地排队时间
+	DispatchedAt time.Time
 }
 
 // RequestResult 是 RequestJob 的执行结果。
@@ -21,30 +28,146 @@ type RequestResult struct {
 	Job     RequestJob
 	Metrics *client.ResponseMetrics
 	Err     error
+	Retries int // 自适应限流模式下因限流重试的次数
+
+	ConnectRetries int // 连接建立失败后的重试次数
 }
 
 // RequestExecutor 执行单个 RequestJob。
 type RequestExecutor struct {
-	client client.ModelClient
+	client  client.ModelClient
+	limiter *ratelimit.Adaptive
+	clock   clock.Clock
+	conns   network.ConnGauge
 }
 
 func NewRequestExecutor(c client.ModelClient) *RequestExecutor {
-	return &RequestExecutor{client: c}
+	return &RequestExecutor{client: c, clock: clock.Real()}
+}
+
+// PeakConnections 返回经该执行器发出的请求同时在途的连接数峰值。
+func (e *RequestExecutor) PeakConnections() int {
+	return e.conns.Peak()
+}
+
+// ConnReuse 返回经该执行器发出的请求中复用了已有连接的请求数与拿到过连接的请求总数。
+func (e *RequestExecutor) ConnReuse() (reused, requests int) {
+	return e.conns.Reuse()
+}
+
+// SetClock 替换时间源：限速等待、连接重试退避与请求起止时间均取自该时钟。
+func (e *RequestExecutor) SetClock(c clock.Clock) {
+	e.clock = c
+}
+
+// SetLimiter 启用自适应限流：发送前按限速等待，限流响应自动重试且不计为失败。
+func (e *RequestExecutor) SetLimiter(l *ratelimit.Adaptive) {
+	e.limiter = l
 }
 
 func (e *RequestExecutor) Execute(ctx context.Context, job RequestJob) RequestResult {
+	if e.limiter == nil {
+		wait := e.queueWait(job)
+		result := e.execute(ctx, job)
+		result.setQueueWait(wait)
+		return result
+	}
+	var wait time.Duration
+	for attempt := 0; ; attempt++ {
+		if err := e.limiter.Wait(ctx, e.clock); err != nil {
+			return RequestResult{Job: job, Err: err, Retries: attempt}
+		}
+		// 排队时间只算到第一次发送前，限流重试的退避不计入
+		if attempt == 0 {
+			wait = e.queueWait(job)
+		}
+		result := e.execute(ctx, job)
+		result.Retries = attempt
+		result.setQueueWait(wait)
+		if m := result.Metrics; m != nil && m.RateLimit != nil {
+			e.limiter.OnRateLimit(e.clock.Now(), m.RateLimit.Remaining, m.RateLimit.Limit, m.RateLimit.Reset)
+		}
+		if !result.Metrics.Throttled() {
+			if result.Err == nil {
+				e.limiter.OnSuccess(e.clock.Now())
+			}
+			return result
+		}
+		e.limiter.OnThrottle(e.clock.Now(), result.Metrics.RetryAfter)
+		if attempt >= e.limiter.MaxRetries() {
+			return result
+		}
+		e.limiter.RecordRetry()
+	}
+}
+
+// queueWait 返回 job 从派发到此刻的等待时间，未记录派发时间时为 0。
+func (e *RequestExecutor) queueWait(job RequestJob) time.Duration {
+	if job.DispatchedAt.IsZero() {
+		return 0
+	}
+	return max(e.clock.Now().Sub(job.DispatchedAt), 0)
+}
+
+// setQueueWait 把本地排队时间写入请求指标。
+func (r *RequestResult) setQueueWait(wait time.Duration) {
+	if r.Metrics != nil {
+		r.Metrics.QueueWaitTime = wait
+	}
+}
+
+// connectRetryDelay 连接建立失败后首次重试前的等待时长，之后每次重试翻倍
+var connectRetryDelay = 200 * time.Millisecond
+
+// execute 发送一次请求；job.Input.ConnectRetries 大于 0 时，连接建立阶段失败（请求未发出）的请求按退避间隔重试。
+func (e *RequestExecutor) execute(ctx context.Context, job RequestJob) RequestResult {
+	delay := connectRetryDelay
+	for attempt := 0; ; attempt++ {
+		result := e.send(ctx, job)
+		result.ConnectRetries = attempt
+		if attempt >= job.Input.ConnectRetries || result.Metrics == nil || !client.ConnectFailed(result.Metrics.NetErrorKind) {
+			return result
+		}
+		select {
+		case <-ctx.Done():
+			return result
+		case <-e.clock.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+func (e *RequestExecutor) send(ctx context.Context, job RequestJob) RequestResult {
 	result := RequestResult{Job: job}
 	if e.client == nil {
 		result.Err = context.Canceled
 		return result
 	}
-	if job.Input.PromptMode == "raw" {
-		rawBody := job.Input.PromptSource.GetContentByIndex(job.Index)
-		result.Metrics, result.Err = e.client.RawRequest(ctx, rawBody)
-		return result
+	// 确定性验证时所有请求使用同一 prompt，占位符也按第 0 个请求展开
+	promptIndex := job.Index
+	if job.Input.ConsistencyCheck {
+		promptIndex = 0
+	}
+	ctx, release := e.conns.Track(ctx)
+	defer release()
+	startedAt := e.clock.Now()
+	content, err := types.PromptContent(ctx, job.Input.PromptSource, promptIndex)
+	switch {
+	case err != nil:
+		// prompt 生成失败（如 --prompt-command 超时）时不发送请求，记为失败，耗时为生成 prompt 所用时间
+		result.Metrics = &client.ResponseMetrics{ErrorMessage: err.Error(), TotalTime: e.clock.Now().Sub(startedAt)}
+		result.Err = err
+	case job.Input.PromptMode == "raw":
+		result.Metrics, result.Err = e.client.RawRequest(ctx, content)
+	default:
+		systemPrompt := job.Input.PromptSource.GetSystemContent()
+		result.Metrics, result.Err = e.client.Request(ctx, systemPrompt, content, job.Input.Stream)
+	}
+	if result.Metrics != nil {
+		result.Metrics.Prompt = content
+		result.Metrics.Index = job.Index
+		result.Metrics.StartedAt = startedAt
+		result.Metrics.CompletedAt = e.clock.Now()
 	}
-	systemPrompt := job.Input.PromptSource.GetSystemContent()
-	userPrompt := job.Input.PromptSource.GetContentByIndex(job.Index)
-	result.Metrics, result.Err = e.client.Request(ctx, systemPrompt, userPrompt, job.Input.Stream)
 	return result
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/yinxulai/ait/internal/server/queue"
 )
@@ -18,6 +19,8 @@ type RequestQueueHooks struct {
 	OnStarted func(RequestJob)
 	OnSkipped func(RequestJob)
 	OnDone    func(RequestResult)
+	// CanStart 在每个请求发出前调用，返回 false 时该请求按跳过处理（如 token 预算耗尽）
+	CanStart func() bool
 }
 
 func NewRequestQueue(capacity int) *RequestQueue {
@@ -41,23 +44,8 @@ func RunRequestBatch(ctx context.Context, jobs []RequestJob, concurrency int, ex
 		go func() {
 			defer wg.Done()
 			for job := range requestQueue.queue.Items() {
-				select {
-				case <-ctx.Done():
-					if hooks.OnSkipped != nil {
-						hooks.OnSkipped(job)
-					}
-					continue
-				default:
-				}
-
-				atomic.AddInt64(&launched, 1)
-				if hooks.OnStarted != nil {
-					hooks.OnStarted(job)
-				}
-				result := executor.Execute(ctx, job)
-				if hooks.OnDone != nil {
-					hooks.OnDone(result)
-				}
+				job.DispatchedAt = executor.clock.Now()
+				runRequestJob(ctx, job, executor, hooks, &launched)
 			}
 		}()
 	}
@@ -77,3 +65,96 @@ func RunRequestBatch(ctx context.Context, jobs []RequestJob, concurrency int, ex
 	wg.Wait()
 	return int(atomic.LoadInt64(&launched))
 }
+
+// RunRequestBatchWithLimit 与 RunRequestBatch 相同，但同时执行的请求数由 limit 控制，
+// 运行期间调整 limit 即可增减活跃 worker 数。
+func RunRequestBatchWithLimit(ctx context.Context, jobs []RequestJob, limit *ConcurrencyLimit, executor *RequestExecutor, hooks RequestQueueHooks) int {
+	var wg sync.WaitGroup
+	var launched int64
+
+	for _, job := range jobs {
+		if hooks.OnQueued != nil {
+			hooks.OnQueued(job)
+		}
+		if !limit.acquire(ctx) {
+			if hooks.OnSkipped != nil {
+				hooks.OnSkipped(job)
+			}
+			break
+		}
+		wg.Add(1)
+		job.DispatchedAt = executor.clock.Now()
+		go func(job RequestJob) {
+			defer wg.Done()
+			defer limit.release()
+			runRequestJob(ctx, job, executor, hooks, &launched)
+		}(job)
+	}
+	wg.Wait()
+	return int(atomic.LoadInt64(&launched))
+}
+
+// RunRequestBursts 把 jobs 按每批 size 个分批，同一批的请求同时发出（不受并发限制）；
+// 下一批在上一批发出 interval 后、且上一批全部完成后才发出。返回实际发出的请求数与各批的发出时间。
+func RunRequestBursts(ctx context.Context, jobs []RequestJob, size int, interval time.Duration, executor *RequestExecutor, hooks RequestQueueHooks) (int, []time.Time) {
+	if size <= 0 {
+		size = 1
+	}
+	var launched int64
+	var starts []time.Time
+
+	for begin := 0; begin < len(jobs); begin += size {
+		if len(starts) > 0 {
+			select {
+			case <-ctx.Done():
+				return int(atomic.LoadInt64(&launched)), starts
+			case <-executor.clock.After(starts[len(starts)-1].Add(interval).Sub(executor.clock.Now())):
+			}
+		}
+		burst := jobs[begin:min(begin+size, len(jobs))]
+		for _, job := range burst {
+			if hooks.OnQueued != nil {
+				hooks.OnQueued(job)
+			}
+		}
+		starts = append(starts, executor.clock.Now())
+		var wg sync.WaitGroup
+		for _, job := range burst {
+			wg.Add(1)
+			job.DispatchedAt = executor.clock.Now()
+			go func(job RequestJob) {
+				defer wg.Done()
+				runRequestJob(ctx, job, executor, hooks, &launched)
+			}(job)
+		}
+		wg.Wait()
+	}
+	return int(atomic.LoadInt64(&launched)), starts
+}
+
+// runRequestJob 执行单个请求：ctx 已取消或 CanStart 拒绝时按跳过处理，否则计入 launched 并执行。
+func runRequestJob(ctx context.Context, job RequestJob, executor *RequestExecutor, hooks RequestQueueHooks, launched *int64) {
+	select {
+	case <-ctx.Done():
+		if hooks.OnSkipped != nil {
+			hooks.OnSkipped(job)
+		}
+		return
+	default:
+	}
+	if hooks.CanStart != nil && !hooks.CanStart() {
+		if hooks.OnSkipped != nil {
+			hooks.OnSkipped(job)
+		}
+		return
+	}
+
+	atomic.AddInt64(launched, 1)
+	if hooks.OnStarted != nil {
+		hooks.OnStarted(job)
+	}
+	result := executor.Execute(ctx, job)
+	if hooks.OnDone != nil {
+		hooks.OnDone(result)
+	}
+}
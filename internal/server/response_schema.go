@@ -0,0 +1,31 @@
+package server
+
+import (
+	"strings"
+	"sync/atomic"
+
+	"github.com/yinxulai/ait/internal/server/stats"
+	"github.com/yinxulai/ait/internal/server/types"
+)
+
+var processResponseSchema atomic.Value // string
+
+// SetResponseSchema 设置本进程标准运行默认的响应 JSON schema 文件，通常在启动时由 --response-schema 设置；
+// 空字符串表示不校验，任务自身设置了 response_schema 时以任务为准。设置前会先解析 schema，无效时返回错误。
+func SetResponseSchema(path string) error {
+	path = strings.TrimSpace(path)
+	if path != "" {
+		if _, err := stats.LoadJSONSchema(path); err != nil {
+			return err
+		}
+	}
+	processResponseSchema.Store(path)
+	return nil
+}
+
+// applyProcessResponseSchema 任务未设置 response_schema 时使用 --response-schema；只对标准模式生效。
+func applyProcessResponseSchema(input *types.Input) {
+	if path, _ := processResponseSchema.Load().(string); path != "" && input.ResponseSchema == "" && input.RunMode() == "standard" {
+		input.ResponseSchema = path
+	}
+}
@@ -1,8 +1,10 @@
 package server
 
 import (
+	"context"
 	"time"
 
+	"github.com/yinxulai/ait/internal/server/stats"
 	"github.com/yinxulai/ait/internal/server/store"
 	"github.com/yinxulai/ait/internal/server/types"
 )
@@ -14,12 +16,21 @@ type RunAggregator struct {
 	runID    RunID
 	taskDef  types.TaskDefinition
 	runStore *store.RunStore
+
+	// 错误率熔断：每个完成的请求计入 breaker，触发时调用 abort 取消运行的 context
+	breaker *stats.ErrorRateBreaker
+	abort   context.CancelCauseFunc
 }
 
 func newRunAggregator(s *serverImpl, ar *activeRun, runID RunID, taskDef types.TaskDefinition, runStore *store.RunStore) *RunAggregator {
 	return &RunAggregator{server: s, active: ar, runID: runID, taskDef: taskDef, runStore: runStore}
 }
 
+// setBreaker 开启错误率熔断；breaker 为 nil 时不熔断。
+func (a *RunAggregator) setBreaker(breaker *stats.ErrorRateBreaker, abort context.CancelCauseFunc) {
+	a.breaker, a.abort = breaker, abort
+}
+
 func (a *RunAggregator) MarkQueued(job RequestJob) {
 	a.active.mu.Lock()
 	if a.active.state.RequestStates == nil {
@@ -76,7 +87,24 @@ func (a *RunAggregator) MarkSkipped(job RequestJob) {
 func (a *RunAggregator) Complete(result RequestResult) *types.RequestMetrics {
 	rm := mapRequestMetrics(result.Metrics, result.Job.Index, result.Err)
 	rm.Level = result.Job.Level
+	rm.ThrottleRetries = result.Retries
+	rm.ConnectRetries = result.ConnectRetries
+	if replay, ok := result.Job.Input.PromptSource.(types.ReplaySource); ok {
+		origin := replay.OriginIndex(result.Job.Index)
+		rm.OriginIndex = &origin
+	}
+	if rm.Success {
+		a.active.mu.Lock()
+		rm.Cold = !a.active.coldMarked
+		a.active.coldMarked = true
+		a.active.mu.Unlock()
+	}
 	_ = a.runStore.AppendRequest(a.taskDef.ID, string(a.runID), *rm)
+	a.saveIO(result, rm)
+	a.saveTokenTrace(result)
+	if a.breaker.Observe(rm.Success) {
+		a.abort(ErrAbortedOnErrorRate)
+	}
 
 	now := time.Now()
 	a.active.mu.Lock()
@@ -106,6 +134,22 @@ func (a *RunAggregator) Complete(result RequestResult) *types.RequestMetrics {
 		a.active.ttftSum += rm.TTFT
 		a.active.cacheSum += rm.CacheHitRate
 		a.active.tokenSum += int64(rm.CompletionTokens)
+		a.active.promptTokenSum += int64(rm.PromptTokens)
+		a.active.cachedTokenSum += int64(rm.CachedTokens)
+		if a.active.promptTokenSum > 0 {
+			a.active.state.CachedTokenRatio = float64(a.active.cachedTokenSum) / float64(a.active.promptTokenSum)
+		}
+		a.active.tpsWindow.Add(now, rm.CompletionTokens)
+		if rm.ThinkingTime > 0 {
+			a.active.thinkingSum += rm.ThinkingTime
+			a.active.thinkingCount++
+			a.active.state.AvgThinkingTime = a.active.thinkingSum / time.Duration(a.active.thinkingCount)
+		}
+		if steady, ok := stats.SteadyTPS(rm.CompletionTokens, rm.TotalTime, rm.TTFT); ok {
+			a.active.steadyTPSSum += steady
+			a.active.steadyTPSCount++
+			a.active.state.AvgSteadyTPS = a.active.steadyTPSSum / float64(a.active.steadyTPSCount)
+		}
 	} else {
 		a.active.state.FailedReqs++
 	}
@@ -117,10 +161,11 @@ func (a *RunAggregator) Complete(result RequestResult) *types.RequestMetrics {
 	if a.active.state.DoneReqs > 0 {
 		a.active.state.SuccessRate = float64(a.active.state.SuccessReqs) / float64(a.active.state.DoneReqs) * 100
 	}
-	if elapsed := time.Since(a.active.state.StartedAt).Minutes(); elapsed > 0 {
+	if elapsed := a.active.activeTime(now).Minutes(); elapsed > 0 {
 		a.active.state.RPM = float64(a.active.state.DoneReqs) / elapsed
 		a.active.state.TPM = float64(a.active.tokenSum) / elapsed
 	}
+	a.active.state.InstantTPS = a.active.tpsWindow.Rate(now, a.active.state.StartedAt)
 	a.recountRequestStatesLocked()
 	snap := a.active.snapshotState()
 	a.active.mu.Unlock()
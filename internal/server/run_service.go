@@ -4,6 +4,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"os"
+	"runtime"
+	"slices"
 	"sync"
 	"time"
 
@@ -14,7 +17,12 @@ import (
 	"github.com/yinxulai/ait/internal/server/modes/integrity"
 	"github.com/yinxulai/ait/internal/server/modes/standard"
 	"github.com/yinxulai/ait/internal/server/modes/turbo"
+	"github.com/yinxulai/ait/internal/server/network"
+	"github.com/yinxulai/ait/internal/server/prompt"
+	"github.com/yinxulai/ait/internal/server/ratelimit"
 	"github.com/yinxulai/ait/internal/server/report"
+	"github.com/yinxulai/ait/internal/server/sla"
+	"github.com/yinxulai/ait/internal/server/stats"
 	"github.com/yinxulai/ait/internal/server/store"
 	"github.com/yinxulai/ait/internal/server/task"
 	"github.com/yinxulai/ait/internal/server/types"
@@ -34,6 +42,42 @@ type activeRun struct {
 	cacheSum  float64
 	tokenSum  int64 // 累计成功请求的输出 Token 数，用于计算 TPM
 	doneCount int   // 与 state.DoneReqs 保持同步，方便不加锁时计算
+	// 滑动窗口实时 TPS（自带锁，可在持有读锁时调用）
+	tpsWindow *stats.TPSWindow
+	// 思考耗时仅统计出现思考阶段的请求
+	thinkingSum   time.Duration
+	thinkingCount int
+	// 稳态 TPS 仅统计流式且输出 token > 1 的请求
+	steadyTPSSum   float64
+	steadyTPSCount int
+	// 按 token 加权的缓存命中占比所需的累计输入 / 缓存命中 token
+	promptTokenSum int64
+	cachedTokenSum int64
+	// 是否已有成功请求完成，第 1 个完成的成功请求标记为冷请求
+	coldMarked bool
+	// 自监控采样器（仅 Input.SelfStats 开启时非空）
+	selfMonitor *stats.SelfMonitor
+	// 标准模式运行的可调并发上限，其它模式为 nil
+	concurrency *ConcurrencyLimit
+	// 多进程分片时请求序号的编排，未分片时为零值
+	shard shardLayout
+}
+
+// activeTime 返回运行开始到 end 之间扣除暂停后的时长，用于计算 RPM / TPM（调用方须持有 activeRun.mu）。
+func (ar *activeRun) activeTime(end time.Time) time.Duration {
+	elapsed := end.Sub(ar.state.StartedAt)
+	if ar.concurrency != nil {
+		elapsed -= ar.concurrency.PausedTime(ar.state.StartedAt)
+	}
+	return elapsed
+}
+
+// stopSelfMonitor 停止自监控并把结果写入 state（调用方须持有 activeRun.mu 写锁）。
+func (ar *activeRun) stopSelfMonitor() {
+	if ar.selfMonitor == nil {
+		return
+	}
+	ar.state.SelfStats = ar.selfMonitor.Stop()
 }
 
 // snapshotState 返回 state 的深度拷贝（调用方须已持有 activeRun.mu 读锁）。
@@ -103,17 +147,36 @@ func mapRequestMetrics(m *client.ResponseMetrics, idx int, err error) *types.Req
 	rm.Success = m.ErrorMessage == "" && err == nil
 	rm.TotalTime = m.TotalTime
 	rm.TTFT = m.TimeToFirstToken
+	rm.TTFB = m.TimeToFirstByte
 	rm.PromptTokens = m.PromptTokens
 	rm.CompletionTokens = m.CompletionTokens
 	rm.CachedTokens = m.CachedInputTokens
 	rm.DNSTime = m.DNSTime
 	rm.ConnectTime = m.ConnectTime
 	rm.TLSTime = m.TLSHandshakeTime
+	rm.ThinkingTime = m.ThinkingTime
 	rm.TargetIP = m.TargetIP
+	rm.ResolvedIPs = m.ResolvedIPs
+	rm.QueueWaitTime = m.QueueWaitTime
+	rm.AdaptedParams = m.AdaptedParams
+	rm.ClientRequestID = m.ClientRequestID
+	rm.ServerRequestID = m.ServerRequestID
+	rm.FinishReason = m.FinishReason
+	rm.Endpoint = m.Endpoint
+	rm.CacheCreationTokens = m.CacheCreationInputTokens
+	rm.ToolCallCount = m.ToolCallCount
+	rm.RequestIDMismatch = m.RequestIDMismatch
+	rm.Fallback = m.FallbackUsed
+	rm.EventTimes = m.EventTimes
+	rm.NetErrorKind = m.NetErrorKind
+	rm.EmptyContent = m.EmptyContent
+	rm.StartedAt = m.StartedAt
+	rm.ContentBlocks = m.ContentBlocks
 	rm.ErrorMessage = m.ErrorMessage
 	if err != nil && rm.ErrorMessage == "" {
 		rm.ErrorMessage = err.Error()
 	}
+	rm.Prompt = m.Prompt
 	rm.RequestBody = m.RequestBody
 	rm.ResponseBody = m.ResponseBody
 
@@ -126,6 +189,22 @@ func mapRequestMetrics(m *client.ResponseMetrics, idx int, err error) *types.Req
 	return rm
 }
 
+// averageThinkingTime 计算出现思考阶段的成功请求的平均思考耗时。
+func averageThinkingTime(requests []types.RequestMetrics) time.Duration {
+	var sum time.Duration
+	count := 0
+	for _, req := range requests {
+		if req.Success && req.ThinkingTime > 0 {
+			sum += req.ThinkingTime
+			count++
+		}
+	}
+	if count == 0 {
+		return 0
+	}
+	return sum / time.Duration(count)
+}
+
 func requestPointers(requests []types.RequestMetrics) []*types.RequestMetrics {
 	if len(requests) == 0 {
 		return nil
@@ -149,7 +228,9 @@ func uploadRequest(taskID string, metrics *client.ResponseMetrics, input types.I
 	if metrics == nil || metrics.ErrorMessage != "" {
 		return
 	}
-	upload.New().UploadReport(taskID, metrics, input)
+	if u := upload.New(network.TelemetryOptions()); u.Sampled() {
+		u.UploadReport(taskID, metrics, input)
+	}
 }
 
 func (s *serverImpl) handleRulesStatus(status integrity.RulesStatus) {
@@ -190,6 +271,8 @@ func (s *serverImpl) startProgressTicker(ar *activeRun, runID RunID) chan struct
 				ar.mu.RLock()
 				snap := ar.snapshotState()
 				ar.mu.RUnlock()
+				// 窗口内无新完成请求时实时 TPS 也需要随时间衰减
+				snap.InstantTPS = ar.tpsWindow.Rate(time.Now(), snap.StartedAt)
 				s.bus.publishRunEvent(Event{RunID: runID, Kind: EventProgressTick, Payload: snap})
 			case <-stopTick:
 				return
@@ -211,6 +294,7 @@ func buildStoredRunMetadata(taskDef types.TaskDefinition, snap *RunState) store.
 		Mode:       snap.Mode,
 		Protocol:   taskDef.Input.NormalizedProtocol(),
 		Model:      taskDef.Input.Model,
+		ModelAlias: taskDef.Input.ModelAlias,
 		Status:     string(snap.Status),
 		StartedAt:  snap.StartedAt,
 		FinishedAt: finishedAt,
@@ -241,6 +325,7 @@ func buildStoredRunResult(snap *RunState) store.RunResult {
 	result.CacheHitRate = snap.CacheHitRate
 	result.RPM = snap.RPM
 	result.TPM = snap.TPM
+	result.SelfStats = snap.SelfStats
 	return result
 }
 
@@ -262,6 +347,11 @@ func buildRunStateFromStoredRun(run *store.StoredRun, requests []types.RequestMe
 		SuccessRate:  summary.SuccessRate,
 		CacheHitRate: summary.CacheHitRate,
 		ErrorMsg:     summary.ErrorSummary,
+
+		AvgThinkingTime: averageThinkingTime(requests),
+	}
+	if run.Result != nil {
+		state.SelfStats = run.Result.SelfStats
 	}
 	if run.Metadata.FinishedAt != nil {
 		finished := *run.Metadata.FinishedAt
@@ -340,6 +430,7 @@ func buildRunningRunSummary(taskDef types.TaskDefinition, snap *RunState) types.
 		Status:       string(snap.Status),
 		Protocol:     taskDef.Input.NormalizedProtocol(),
 		Model:        taskDef.Input.Model,
+		ModelAlias:   taskDef.Input.ModelAlias,
 		StartedAt:    snap.StartedAt,
 		SuccessRate:  snap.SuccessRate,
 		AvgTTFT:      snap.AvgTTFT,
@@ -371,16 +462,61 @@ func (s *serverImpl) StartRun(taskID string) (RunID, error) {
 		return "", fmt.Errorf("hydrate input: %w", err)
 	}
 
-	// 若任务未单独配置代理，使用全局配置中的代理地址
-	if hydratedInput.ProxyURL == "" {
-		if cfg, err := config.Load(); err == nil {
+	// 若任务未单独配置代理或模型别名，使用全局配置中的值
+	if cfg, err := config.Load(); err == nil {
+		if hydratedInput.ProxyURL == "" {
 			hydratedInput.ProxyURL = cfg.ProxyURL
 		}
+		if hydratedInput.ModelAlias == "" {
+			hydratedInput.ModelAlias = cfg.ModelAlias(hydratedInput.Model)
+		}
 	}
+	// 运行期元数据（历史记录、报告）沿用解析后的别名
+	taskDef.Input.ModelAlias = hydratedInput.ModelAlias
 
 	runID := RunID(fmt.Sprintf("run_%d", time.Now().UnixNano()))
 	now := time.Now()
 	mode := hydratedInput.RunMode()
+	// 多进程分片：标准运行只执行本分片的请求，运行 ID 带分片后缀，各进程的明细互不覆盖
+	var layout shardLayout
+	if shard := CurrentShard(); shard.Enabled() && mode == "standard" {
+		layout = newShardLayout(shard, hydratedInput.Count)
+		hydratedInput.Count = layout.count
+		if hydratedInput.Count <= 0 {
+			return "", fmt.Errorf("shard %s has no requests: input.count %d is less than the shard total", shard, taskDef.Input.Count)
+		}
+		runID = RunID(fmt.Sprintf("%s_%s", runID, shard.Suffix()))
+	}
+	// 进程级 --consistency-check 打开确定性验证，固定为非流式
+	if consistencyCheckApplies(hydratedInput) {
+		hydratedInput.ConsistencyCheck = true
+		hydratedInput.Stream = false
+		hydratedInput.CompareStream = false
+	}
+	// 进程级 --stream-both 打开流式 / 非流式 A/B 对比；任务自身已开启时沿用其 compare_stream_split
+	if streamBothApplies(hydratedInput) {
+		hydratedInput.CompareStream = true
+	}
+	// 进程级 --http-version / --compare-http-version
+	applyProcessHTTPVersion(&hydratedInput)
+	applyProcessAcceptEncoding(&hydratedInput)
+	// 进程级 --max-tokens
+	applyProcessMaxTokens(&hydratedInput)
+	applyProcessTokenTrace(&hydratedInput)
+	applyProcessAbort(&hydratedInput)
+	applyProcessResponseSchema(&hydratedInput)
+	applyProcessStdinPrompts(&hydratedInput)
+	if err := applyProcessPromptCommand(&hydratedInput); err != nil {
+		return "", fmt.Errorf("prompt command: %w", err)
+	}
+	// 进程级 --self-stats / --self-monitor
+	if selfStatsEnabled() {
+		hydratedInput.SelfStats = true
+	}
+	// 进程级 --sla 追加在任务自身的 SLA 之后
+	if extra := CurrentSLA(); len(extra) > 0 && mode == "standard" {
+		hydratedInput.SLA = append(slices.Clone(hydratedInput.SLA), extra...)
+	}
 	// 使用 Server 的生命周期 Context，这样运行可以响应 Server 关闭
 	// 如果 Server 没有 ctx（测试场景），使用 Background
 	parentCtx := s.ctx
@@ -401,9 +537,22 @@ func (s *serverImpl) StartRun(taskID string) (RunID, error) {
 		state.TotalReqs = 0
 	default:
 		state.TotalReqs = hydratedInput.Count
+		if hydratedInput.CompareStream {
+			streamCount, nonStreamCount := hydratedInput.CompareStreamCounts()
+			state.TotalReqs = streamCount + nonStreamCount
+		}
+		if len(hydratedInput.InputLengthSweep) > 0 {
+			state.TotalReqs = hydratedInput.Count * len(hydratedInput.InputLengthSweep)
+		}
+		if hydratedInput.CompareHTTPVersion {
+			state.TotalReqs = hydratedInput.Count * 2
+		}
+		if scenarios := promptFileScenarios(hydratedInput); scenarios != nil {
+			state.TotalReqs = hydratedInput.Count * len(scenarios)
+		}
 	}
 
-	ar := &activeRun{state: state, ctx: ctx, cancel: cancel}
+	ar := &activeRun{state: state, ctx: ctx, cancel: cancel, tpsWindow: stats.NewTPSWindow(stats.DefaultTPSWindow), shard: layout}
 
 	s.mu.Lock()
 	if s.scheduler == nil {
@@ -438,6 +587,11 @@ func (s *serverImpl) dispatchQueuedRun(item runQueueItem) {
 	}
 	ar.state.Status = RunStatusRunning
 	ar.state.StartedAt = time.Now()
+	if item.Input.SelfStats {
+		ar.selfMonitor = stats.NewSelfMonitor(0)
+		ar.selfMonitor.OnSample(selfSampleHook(item.RunID))
+		ar.selfMonitor.Start()
+	}
 	ar.mu.Unlock()
 
 	ar.mu.RLock()
@@ -472,32 +626,245 @@ func (s *serverImpl) runStandard(ar *activeRun, runID RunID, taskDef types.TaskD
 		return
 	}
 	aggregator := newRunAggregator(s, ar, runID, taskDef, runStore)
-	jobs := make([]RequestJob, 0, input.Count)
-	for i := 0; i < input.Count; i++ {
-		jobs = append(jobs, RequestJob{RunID: runID, Index: i, Input: input})
+	// 错误率熔断与手动取消共用运行的 context：触发后排队中的请求按跳过处理，在途请求被取消
+	ctx, abort := context.WithCancelCause(ctx)
+	defer abort(nil)
+	breaker := stats.NewErrorRateBreaker(input.AbortOnErrorRate, input.AbortMinSamples)
+	aggregator.setBreaker(breaker, abort)
+	if input.BurstSize <= 0 {
+		ar.mu.Lock()
+		ar.concurrency = NewConcurrencyLimit(input.Concurrency)
+		ar.state.Concurrency = ar.concurrency.Limit()
+		ar.mu.Unlock()
 	}
-
 	stopTick := s.startProgressTicker(ar, runID)
-	results := make([]*client.ResponseMetrics, input.Count)
+
+	budget := stats.NewTokenBudget(input.TokenBudget)
+
+	// 多轮运行（A/B 对比、长度扫描、按 prompt 文件分场景）的结果为空时保留运行期间的实时聚合值
+	var modeResult any
+	switch {
+	case input.CompareHTTPVersion:
+		modeResult = s.runHTTPVersionCompare(ctx, taskDef, input, aggregator, budget)
+	case input.CompareStream:
+		modeResult = s.runStreamCompare(ctx, taskDef, input, modelClient, aggregator, budget)
+	case len(input.InputLengthSweep) > 0:
+		modeResult = s.runLengthSweep(ctx, taskDef, input, modelClient, aggregator, budget)
+	default:
+		if scenarios := promptFileScenarios(input); scenarios != nil {
+			modeResult = s.runPromptFiles(ctx, taskDef, input, scenarios, modelClient, aggregator, budget)
+		}
+	}
+	var reportData *types.ReportData
+	if modeResult == nil {
+		reportData = s.runStandardBatch(ctx, taskDef, input, 0, input.Count, modelClient, aggregator, budget)
+	}
+	close(stopTick)
+	// 所有请求都已结束，先释放连接再统计自监控的残留 goroutine
+	client.CloseIdleConnections(modelClient)
+	if abortStats := breaker.Stats(); abortStats != nil {
+		s.abortedEarly.Store(true)
+		for _, data := range modeReports(modeResult) {
+			data.AbortedEarly = true
+			data.Abort = abortStats
+		}
+		if reportData != nil {
+			reportData.AbortedEarly = true
+			reportData.Abort = abortStats
+		}
+	}
+	if modeResult != nil {
+		s.finishStandardRun(ar, runID, taskDef, runStore, modeResult, nil)
+		return
+	}
+	s.completeStandardRun(ar, runID, taskDef, runStore, reportData)
+}
+
+// runStandardBatch 以 input 配置执行 count 个请求（请求序号从 offset 开始），返回该批次的统计结果。
+// 多进程分片时序号按分片编排映射为完整运行中的序号。
+// budget 非空时每个请求完成后累加消耗的 token，预算耗尽后剩余请求不再派发。
+func (s *serverImpl) runStandardBatch(ctx context.Context, taskDef types.TaskDefinition, input types.Input, offset, count int, modelClient client.ModelClient, aggregator *RunAggregator, budget *stats.TokenBudget) *types.ReportData {
+	jobs := make([]RequestJob, 0, count)
+	slots := make(map[int]int, count) // 请求序号 → 在本批次 results 中的位置
+	for i := 0; i < count; i++ {
+		index := aggregator.active.shard.index(offset + i)
+		slots[index] = i
+		jobs = append(jobs, RequestJob{RunID: aggregator.runID, Index: index, Input: input})
+	}
+
+	results := make([]*client.ResponseMetrics, count)
 	start := time.Now()
-	launched := RunRequestBatch(ctx, jobs, input.Concurrency, NewRequestExecutor(modelClient), RequestQueueHooks{
+	executor := NewRequestExecutor(modelClient)
+	var limiter *ratelimit.Adaptive
+	if input.Adaptive {
+		limiter = ratelimit.NewAdaptive(ratelimit.Config{}, start)
+		executor.SetLimiter(limiter)
+	}
+	if input.BurstSize > 0 {
+		// 突发模式下同时在途的请求数即每批大小
+		input.Concurrency = input.BurstSize
+	}
+	hooks := RequestQueueHooks{
 		OnQueued:  aggregator.MarkQueued,
 		OnStarted: aggregator.MarkStarted,
 		OnSkipped: aggregator.MarkSkipped,
+		CanStart:  func() bool { return !budget.Exhausted() },
 		OnDone: func(result RequestResult) {
 			if result.Metrics != nil {
-				results[result.Job.Index] = result.Metrics
+				results[slots[result.Job.Index]] = result.Metrics
+				budget.Add(result.Metrics.PromptTokens + result.Metrics.CompletionTokens)
 			}
 			rm := aggregator.Complete(result)
 			if rm.Success {
 				uploadRequest(taskDef.ID, result.Metrics, input)
 			}
 		},
-	})
-	close(stopTick)
+	}
 
-	reportData := standard.CalculateResult(input, results, time.Since(start), launched)
-	s.completeStandardRun(ar, runID, taskDef, runStore, reportData)
+	var prober *network.Prober
+	if input.ProbeInterval > 0 {
+		if target, err := network.ParseProbeTarget(input.ProbeEndpoint()); err == nil {
+			prober = network.NewProber(target, input.ProbeInterval)
+			prober.Start(ctx)
+		}
+	}
+
+	var launched int
+	var limit *ConcurrencyLimit
+	var burstStarts []time.Time
+	if input.BurstSize > 0 {
+		launched, burstStarts = RunRequestBursts(ctx, jobs, input.BurstSize, input.BurstInterval, executor, hooks)
+	} else {
+		ar := aggregator.active
+		ar.mu.RLock()
+		limit = ar.concurrency
+		ar.mu.RUnlock()
+		if limit == nil {
+			limit = NewConcurrencyLimit(input.Concurrency)
+		}
+		launched = RunRequestBatchWithLimit(ctx, jobs, limit, executor, hooks)
+	}
+
+	probes := prober.Stop()
+	var paused time.Duration
+	if limit != nil {
+		paused = limit.PausedTime(start)
+	}
+	data := standard.CalculateResult(input, results, time.Since(start)-paused, launched)
+	if limiter != nil && data != nil {
+		data.Adaptive = limiter.Stats()
+	}
+	if data != nil {
+		data.TokenBudget = budget.Stats()
+		data.Shard = CurrentShard().String()
+		data.PeakConcurrentConnections = executor.PeakConnections()
+		data.SetConnReuse(executor.ConnReuse())
+		if limit != nil {
+			data.ConcurrencyChanges = limit.Changes(start)
+		}
+		data.PausedTime = paused
+		for i := range data.Bursts {
+			if i < len(burstStarts) {
+				data.Bursts[i].Start = burstStarts[i].Sub(start)
+			}
+		}
+		if probes != nil {
+			data.NetworkProbes = probes
+			data.NetworkProbeSummary = standard.SummarizeNetworkProbes(probes, results, input.Stream, data.AvgConnectTime+data.AvgTLSHandshakeTime)
+		}
+	}
+	return data
+}
+
+// runStreamCompare 依次以流式、非流式各执行一轮，产出 A/B 对比结果。
+// 两轮共用同一个运行进度（TotalReqs 为两轮之和），请求序号连续编排。
+// 两轮共享同一份 token 预算，第一轮耗尽预算时不再执行第二轮。
+func (s *serverImpl) runStreamCompare(ctx context.Context, taskDef types.TaskDefinition, input types.Input, modelClient client.ModelClient, aggregator *RunAggregator, budget *stats.TokenBudget) *types.StreamCompareResult {
+	streamCount, nonStreamCount := input.CompareStreamCounts()
+	result := &types.StreamCompareResult{}
+
+	streamInput := input
+	streamInput.Stream = true
+	result.Stream = s.runStandardBatch(ctx, taskDef, streamInput, 0, streamCount, modelClient, aggregator, budget)
+	result.Stream.StreamMode = types.StreamModeStream
+
+	if ctx.Err() == nil && nonStreamCount > 0 && !budget.Exhausted() {
+		nonStreamInput := input
+		nonStreamInput.Stream = false
+		result.NonStream = s.runStandardBatch(ctx, taskDef, nonStreamInput, streamCount, nonStreamCount, modelClient, aggregator, budget)
+		result.NonStream.StreamMode = types.StreamModeNonStream
+	}
+	return result
+}
+
+// runHTTPVersionCompare 依次以 HTTP/1.1、HTTP/2 各执行一轮完整的 Count，产出 A/B 对比结果。
+// 协议版本在创建 Transport 时确定，因此每轮各自创建客户端；两轮共用同一个运行进度与 token 预算，
+// 请求序号连续编排，运行被停止或预算耗尽时不再执行第二轮。
+func (s *serverImpl) runHTTPVersionCompare(ctx context.Context, taskDef types.TaskDefinition, input types.Input, aggregator *RunAggregator, budget *stats.TokenBudget) *types.HTTPVersionCompareResult {
+	result := &types.HTTPVersionCompareResult{}
+	for i, version := range []string{types.HTTPVersion1, types.HTTPVersion2} {
+		if i > 0 && (ctx.Err() != nil || budget.Exhausted()) {
+			break
+		}
+		roundInput := input
+		roundInput.HTTPVersion = version
+		modelClient, err := client.NewClient(roundInput, loggerForInput(roundInput))
+		if err != nil {
+			break
+		}
+		data := s.runStandardBatch(ctx, taskDef, roundInput, i*input.Count, input.Count, modelClient, aggregator, budget)
+		client.CloseIdleConnections(modelClient)
+		if version == types.HTTPVersion1 {
+			result.HTTP1 = data
+		} else {
+			result.HTTP2 = data
+		}
+	}
+	return result
+}
+
+// runLengthSweep 按 InputLengthSweep 中的每个目标长度生成 prompt 依次执行一轮，产出输入长度扫描结果。
+// 各轮共用同一个运行进度（TotalReqs 为各轮之和），请求序号连续编排；
+// 运行被停止或 token 预算耗尽时不再执行后续长度。
+func (s *serverImpl) runLengthSweep(ctx context.Context, taskDef types.TaskDefinition, input types.Input, modelClient client.ModelClient, aggregator *RunAggregator, budget *stats.TokenBudget) *types.InputLengthSweepResult {
+	result := &types.InputLengthSweepResult{}
+	offset := 0
+	for _, length := range input.InputLengthSweep {
+		if ctx.Err() != nil || budget.Exhausted() {
+			break
+		}
+		source, err := prompt.LoadPromptByLength(length)
+		if err != nil {
+			break
+		}
+		roundInput := input
+		roundInput.PromptLength = length
+		roundInput.PromptSource = source
+		result.Points = append(result.Points, s.runStandardBatch(ctx, taskDef, roundInput, offset, input.Count, modelClient, aggregator, budget))
+		offset += input.Count
+	}
+	return result
+}
+
+// runPromptFiles 把 prompt 文件的每个文件作为一个场景依次执行一轮 Count 个请求，产出按场景分行的结果。
+// 与长度扫描一样各轮共用同一个运行进度，请求序号连续编排；运行被停止或 token 预算耗尽时不再执行后续场景。
+func (s *serverImpl) runPromptFiles(ctx context.Context, taskDef types.TaskDefinition, input types.Input, scenarios []prompt.FileScenario, modelClient client.ModelClient, aggregator *RunAggregator, budget *stats.TokenBudget) *types.PromptFileRunsResult {
+	result := &types.PromptFileRunsResult{}
+	offset := 0
+	for _, scenario := range scenarios {
+		if ctx.Err() != nil || budget.Exhausted() {
+			break
+		}
+		roundInput := input
+		roundInput.PromptSource = scenario.Source
+		data := s.runStandardBatch(ctx, taskDef, roundInput, offset, input.Count, modelClient, aggregator, budget)
+		if data != nil {
+			data.Scenario = scenario.Name
+		}
+		result.Scenarios = append(result.Scenarios, data)
+		offset += input.Count
+	}
+	return result
 }
 
 // runIntegrity 在 goroutine 中执行接口完整性测试。
@@ -556,11 +923,14 @@ func (s *serverImpl) runIntegrity(ar *activeRun, runID RunID, taskDef types.Task
 	caseIndex := 0
 
 	executor := integrity.NewExecutor(taskDef.ID, input, suite)
+	// 各用例的客户端在测试集跑完后统一释放连接
+	var caseClients []client.ModelClient
 	executor.RunnerFactory = func(caseInput types.Input, c types.IntegrityCase) (integrity.CaseRunner, error) {
 		modelClient, err := client.NewClient(caseInput, loggerForInput(caseInput))
 		if err != nil {
 			return nil, err
 		}
+		caseClients = append(caseClients, modelClient)
 		idx := caseIndex
 		caseIndex++
 		return newQueuedCaseRunner(ctx, runID, caseInput, modelClient, aggregator, idx, c.ID), nil
@@ -627,6 +997,9 @@ func (s *serverImpl) runIntegrity(ar *activeRun, runID RunID, taskDef types.Task
 	}
 
 	result, err := executor.Run()
+	for _, c := range caseClients {
+		client.CloseIdleConnections(c)
+	}
 	if result != nil {
 		result.Protocol = input.NormalizedProtocol()
 		result.Model = input.Model
@@ -690,6 +1063,7 @@ func (s *serverImpl) runTurbo(ar *activeRun, runID RunID, taskDef types.TaskDefi
 	ar.mu.Unlock()
 
 	turboResult, err := engine.Run(input)
+	client.CloseIdleConnections(modelClient)
 	if err != nil {
 		s.failRun(ar, runID, taskDef, runStore, err)
 		return
@@ -700,28 +1074,52 @@ func (s *serverImpl) runTurbo(ar *activeRun, runID RunID, taskDef types.TaskDefi
 
 // completeStandardRun 处理标准运行成功完成的后续工作。
 func (s *serverImpl) completeStandardRun(ar *activeRun, runID RunID, taskDef types.TaskDefinition, runStore *store.RunStore, data *types.ReportData) {
+	s.finishStandardRun(ar, runID, taskDef, runStore, data, data)
+}
+
+// finishStandardRun 写入标准模式最终结果。data 非空时用其统计值覆盖实时均值；
+// 为空时（如 A/B 对比）保留运行期间的实时聚合值。
+func (s *serverImpl) finishStandardRun(ar *activeRun, runID RunID, taskDef types.TaskDefinition, runStore *store.RunStore, modeResult any, data *types.ReportData) {
 	finishedAt := time.Now()
+	env := s.runEnvironment()
+	for _, d := range modeReports(modeResult) {
+		d.Environment = env
+	}
 
 	ar.mu.Lock()
 	if ar.state.Status != RunStatusStopped {
 		ar.state.Status = RunStatusCompleted
 	}
 	ar.state.FinishedAt = &finishedAt
-	ar.state.ModeResult = data
+	ar.state.ModeResult = modeResult
+	ar.stopSelfMonitor()
 	if data != nil {
 		ar.state.AvgTPS = data.AvgTPS
 		ar.state.AvgTTFT = data.AvgTTFT
+		ar.state.AvgThinkingTime = data.AvgThinkingTime
+		ar.state.AvgSteadyTPS = data.AvgSteadyTPS
 		ar.state.SuccessRate = data.SuccessRate
 		ar.state.CacheHitRate = data.AvgCacheHitRate
+		ar.state.CachedTokenRatio = data.CachedTokenRatio
+		data.SelfStats = ar.state.SelfStats
+		// 提前终止的结果不完整，不参与基线对比
+		if ar.state.Status == RunStatusCompleted && taskDef.Input.BaselineDir != "" && !data.AbortedEarly {
+			data.Baseline = s.checkBaseline(taskDef, data)
+		}
+		if ar.state.Status == RunStatusCompleted && sla.AnyFailed(data.SLAResults) {
+			s.slaFailed.Store(true)
+		}
 	}
-	// 使用完整运行时长计算最终稳定的 RPM/TPM
-	if elapsed := finishedAt.Sub(ar.state.StartedAt).Minutes(); elapsed > 0 {
+	// 使用完整运行时长（扣除暂停时间）计算最终稳定的 RPM/TPM
+	if elapsed := ar.activeTime(finishedAt).Minutes(); elapsed > 0 {
 		ar.state.RPM = float64(ar.state.DoneReqs) / elapsed
 		ar.state.TPM = float64(ar.tokenSum) / elapsed
 	}
 	snap := ar.snapshotState()
 	ar.mu.Unlock()
 
+	// 先登记 webhook 再发结束事件，等待运行结束的调用方退出前能等到推送完成
+	sendWebhook := trackRunWebhook(taskDef, snap)
 	if snap.Status == RunStatusStopped {
 		s.bus.publishRunEvent(Event{RunID: runID, Kind: EventRunStopped, Payload: snap})
 	} else {
@@ -731,6 +1129,70 @@ func (s *serverImpl) completeStandardRun(ar *activeRun, runID RunID, taskDef typ
 	if err := s.persistFinalRun(runStore, taskDef, snap); err == nil {
 		s.removeActiveRun(runID)
 	}
+	sendWebhook()
+}
+
+// runEnvironment 采集报告溯源用的运行环境。出口 IP 复用上报模块检测并缓存的结果，
+// 这里只读缓存，不为此阻塞运行结束；尚未检测到时留空。
+func (s *serverImpl) runEnvironment() *types.RunEnvironment {
+	hostname, _ := os.Hostname()
+	return &types.RunEnvironment{
+		Hostname:   hostname,
+		OS:         runtime.GOOS,
+		Arch:       runtime.GOARCH,
+		AitVersion: s.version,
+		SourceIP:   network.CachedPublicIP(),
+	}
+}
+
+// checkBaseline 将标准运行结果与任务基线对比；出现回归时记录到 Server，供进程退出码使用。
+// 读写基线失败不影响运行本身，错误信息写入结果。
+func (s *serverImpl) checkBaseline(taskDef types.TaskDefinition, data *types.ReportData) *types.BaselineResult {
+	input := taskDef.Input
+	result, err := report.CheckBaseline(input.BaselineDir, taskDef.Name, data, input.RegressionThreshold)
+	if err != nil {
+		return &types.BaselineResult{
+			Path:  report.BaselinePath(input.BaselineDir, taskDef.Name),
+			Error: err.Error(),
+		}
+	}
+	if result.Regressed {
+		s.regressed.Store(true)
+	}
+	return result
+}
+
+// modeReports 返回标准模式运行结果中的各份报告（单轮为其自身，A/B 对比、长度扫描、分场景运行为各轮），跳过未执行的轮次。
+func modeReports(modeResult any) []*types.ReportData {
+	var reports []*types.ReportData
+	switch result := modeResult.(type) {
+	case *types.ReportData:
+		reports = []*types.ReportData{result}
+	case *types.StreamCompareResult:
+		reports = []*types.ReportData{result.Stream, result.NonStream}
+	case *types.InputLengthSweepResult:
+		reports = result.Points
+	case *types.PromptFileRunsResult:
+		reports = result.Scenarios
+	case *types.HTTPVersionCompareResult:
+		reports = []*types.ReportData{result.HTTP1, result.HTTP2}
+	}
+	return slices.DeleteFunc(slices.Clone(reports), func(d *types.ReportData) bool { return d == nil })
+}
+
+// Regressed 返回本进程内是否有运行相对基线出现回归。
+func (s *serverImpl) Regressed() bool {
+	return s.regressed.Load()
+}
+
+// SLAFailed 返回本进程内是否有运行未达到 SLA。
+func (s *serverImpl) SLAFailed() bool {
+	return s.slaFailed.Load()
+}
+
+// AbortedEarly 返回本进程内是否有运行因错误率熔断提前终止。
+func (s *serverImpl) AbortedEarly() bool {
+	return s.abortedEarly.Load()
 }
 
 // completeTurboRun 处理 Turbo 运行成功完成的后续工作。
@@ -743,6 +1205,7 @@ func (s *serverImpl) completeTurboRun(ar *activeRun, runID RunID, taskDef types.
 	}
 	ar.state.FinishedAt = &finishedAt
 	ar.state.ModeResult = result
+	ar.stopSelfMonitor()
 	if result != nil {
 		// 更新模式状态为最终结果
 		if ar.state.ModeState == nil {
@@ -783,6 +1246,7 @@ func (s *serverImpl) completeIntegrityRun(ar *activeRun, runID RunID, taskDef ty
 	}
 	ar.state.FinishedAt = &finishedAt
 	ar.state.ModeResult = result
+	ar.stopSelfMonitor()
 	if result != nil {
 		// 更新模式状态为最终结果
 		if ar.state.ModeState == nil {
@@ -819,6 +1283,7 @@ func (s *serverImpl) failRun(ar *activeRun, runID RunID, taskDef types.TaskDefin
 	ar.state.Status = RunStatusFailed
 	ar.state.FinishedAt = &finishedAt
 	ar.state.ErrorMsg = runErr.Error()
+	ar.stopSelfMonitor()
 	snap := ar.snapshotState()
 	ar.mu.Unlock()
 
@@ -881,6 +1346,55 @@ func (s *serverImpl) StopRun(runID RunID) error {
 	return nil
 }
 
+// AdjustRunConcurrency 把运行中的标准模式运行的并发上限增减 delta（最小为 1），返回调整后的上限。
+func (s *serverImpl) AdjustRunConcurrency(runID RunID, delta int) (int, error) {
+	s.mu.RLock()
+	ar, ok := s.activeRuns[runID]
+	s.mu.RUnlock()
+
+	if !ok {
+		return 0, fmt.Errorf("run %q not found or already finished", runID)
+	}
+
+	ar.mu.Lock()
+	if ar.concurrency == nil || ar.state.Status != RunStatusRunning {
+		ar.mu.Unlock()
+		return 0, fmt.Errorf("run %q does not support adjusting concurrency", runID)
+	}
+	n := ar.concurrency.Adjust(delta)
+	ar.state.Concurrency = n
+	snap := ar.snapshotState()
+	ar.mu.Unlock()
+	s.bus.publishRunEvent(Event{RunID: runID, Kind: EventProgressTick, Payload: snap})
+	return n, nil
+}
+
+// SetRunPaused 暂停或恢复运行中的标准模式运行。
+func (s *serverImpl) SetRunPaused(runID RunID, paused bool) error {
+	s.mu.RLock()
+	ar, ok := s.activeRuns[runID]
+	s.mu.RUnlock()
+
+	if !ok {
+		return fmt.Errorf("run %q not found or already finished", runID)
+	}
+
+	ar.mu.Lock()
+	if ar.concurrency == nil || ar.state.Status != RunStatusRunning {
+		ar.mu.Unlock()
+		return fmt.Errorf("run %q does not support pausing", runID)
+	}
+	if !ar.concurrency.SetPaused(paused) {
+		ar.mu.Unlock()
+		return nil
+	}
+	ar.state.Paused = paused
+	snap := ar.snapshotState()
+	ar.mu.Unlock()
+	s.bus.publishRunEvent(Event{RunID: runID, Kind: EventProgressTick, Payload: snap})
+	return nil
+}
+
 // GetRunState 返回指定运行的当前状态快照。
 // 先查内存中的 activeRuns；若不存在，再尝试从磁盘加载最终运行结果（历史回放）。
 func (s *serverImpl) GetRunState(runID RunID) (*RunState, bool) {
@@ -928,6 +1442,16 @@ func (s *serverImpl) ListTaskRunHistory(taskID string, limit int) ([]types.TaskR
 	return s.runStore.ListSummariesByTask(taskID, limit)
 }
 
+// csvRenderer 按任务配置创建 CSV 渲染器；任务已被删除时使用默认格式。
+func (s *serverImpl) csvRenderer(taskID string) *report.CSVRenderer {
+	renderer := &report.CSVRenderer{Version: s.version, TaskID: taskID}
+	if taskDef, err := s.taskStore.Get(taskID); err == nil {
+		renderer.HeaderComment = taskDef.Input.CSVHeaderComment
+		renderer.LegacyFormat = taskDef.Input.CSVLegacyFormat
+	}
+	return renderer
+}
+
 // GenerateRunReport 为已完成的标准运行生成报告文件。
 // 先查内存中的 activeRuns，若不存在则从最终结果文件加载（支持跨 session 历史运行）。
 func (s *serverImpl) GenerateRunReport(runID RunID, format ReportFormat) (string, error) {
@@ -937,15 +1461,29 @@ func (s *serverImpl) GenerateRunReport(runID RunID, format ReportFormat) (string
 	s.mu.RUnlock()
 
 	var status RunStatus
-	var mode string
+	var mode, taskID string
 	var standardResult *types.ReportData
+	var compareResult *types.StreamCompareResult
+	var sweepResult *types.InputLengthSweepResult
+	var httpCompareResult *types.HTTPVersionCompareResult
+	var promptFilesResult *types.PromptFileRunsResult
 
 	if ok {
 		ar.mu.RLock()
 		status = ar.state.Status
 		mode = ar.state.Mode
-		if reportData, ok := ar.state.ModeResult.(*types.ReportData); ok {
-			standardResult = reportData
+		taskID = ar.state.TaskID
+		switch result := ar.state.ModeResult.(type) {
+		case *types.ReportData:
+			standardResult = result
+		case *types.StreamCompareResult:
+			compareResult = result
+		case *types.InputLengthSweepResult:
+			sweepResult = result
+		case *types.HTTPVersionCompareResult:
+			httpCompareResult = result
+		case *types.PromptFileRunsResult:
+			promptFilesResult = result
 		}
 		ar.mu.RUnlock()
 	} else {
@@ -955,10 +1493,19 @@ func (s *serverImpl) GenerateRunReport(runID RunID, format ReportFormat) (string
 		}
 		status = RunStatus(run.Metadata.Status)
 		mode = run.Metadata.Mode
+		taskID = run.Metadata.TaskID
 		if run.Result != nil {
 			// 优先从 ModeResult 读取
 			if reportData, ok := run.Result.ModeResult.(*types.ReportData); ok {
 				standardResult = reportData
+			} else if compare, ok := run.Result.ModeResult.(*types.StreamCompareResult); ok {
+				compareResult = compare
+			} else if sweep, ok := run.Result.ModeResult.(*types.InputLengthSweepResult); ok {
+				sweepResult = sweep
+			} else if httpCompare, ok := run.Result.ModeResult.(*types.HTTPVersionCompareResult); ok {
+				httpCompareResult = httpCompare
+			} else if promptFiles, ok := run.Result.ModeResult.(*types.PromptFileRunsResult); ok {
+				promptFilesResult = promptFiles
 			} else if run.Result.StandardResult != nil {
 				// 向后兼容：从旧字段读取
 				standardResult = run.Result.StandardResult
@@ -974,12 +1521,26 @@ func (s *serverImpl) GenerateRunReport(runID RunID, format ReportFormat) (string
 		return "", fmt.Errorf("report generation for turbo runs is not yet supported")
 	}
 
-	if standardResult == nil {
+	var reports []types.ReportData
+	switch {
+	case compareResult != nil:
+		reports = compareResult.Reports()
+	case sweepResult != nil:
+		reports = sweepResult.Reports()
+	case httpCompareResult != nil:
+		reports = httpCompareResult.Reports()
+	case promptFilesResult != nil:
+		reports = promptFilesResult.Reports()
+	case standardResult != nil:
+		reports = []types.ReportData{*standardResult}
+	}
+	if len(reports) == 0 {
 		return "", fmt.Errorf("no result data available for run %q", runID)
 	}
 
 	rm := report.NewReportManager()
-	paths, err := rm.GenerateReports([]types.ReportData{*standardResult}, []string{string(format)})
+	rm.RegisterRenderer("csv", s.csvRenderer(taskID))
+	paths, err := rm.GenerateReports(reports, []string{string(format)})
 	if err != nil {
 		return "", fmt.Errorf("generate report: %w", err)
 	}
@@ -0,0 +1,2030 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/yinxulai/ait/internal/server/client"
+	"github.com/yinxulai/ait/internal/server/clock"
+	"github.com/yinxulai/ait/internal/server/config"
+	"github.com/yinxulai/ait/internal/server/logger"
+	"github.com/yinxulai/ait/internal/server/network"
+	"github.com/yinxulai/ait/internal/server/prompt"
+	"github.com/yinxulai/ait/internal/server/types"
+)
+
+// ── test helpers ──────────────────────────────────────────────────────────────
+
+// openAIStub 是最小的 OpenAI chat/completions 兼容服务，记录收到的请求体。
+type openAIStub struct {
+	*httptest.Server
+	release chan struct{}
+
+	mu     sync.Mutex
+	bodies []map[string]any
+}
+
+// newOpenAIStub 创建桩服务；所有请求会阻塞到 Release 被调用，
+// 便于测试在运行开始前完成事件订阅。
+func newOpenAIStub(t *testing.T) *openAIStub {
+	t.Helper()
+	stub := &openAIStub{release: make(chan struct{})}
+	stub.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-stub.release
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		stub.mu.Lock()
+		stub.bodies = append(stub.bodies, body)
+		stub.mu.Unlock()
+
+		if stream, _ := body["stream"].(bool); stream {
+			w.Header().Set("Content-Type", "text/event-stream")
+			fmt.Fprint(w, "data: {\"choices\":[{\"index\":0,\"delta\":{\"content\":\"hi\"}}]}\n\n")
+			fmt.Fprint(w, "data: {\"choices\":[],\"usage\":{\"prompt_tokens\":3,\"completion_tokens\":2}}\n\n")
+			fmt.Fprint(w, "data: [DONE]\n\n")
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"choices":[{"index":0,"message":{"role":"assistant","content":"hi"}}],"usage":{"prompt_tokens":3,"completion_tokens":2}}`)
+	}))
+	t.Cleanup(func() {
+		stub.Release()
+		stub.Close()
+	})
+	return stub
+}
+
+// Release 放行所有（包括后续）请求。
+func (s *openAIStub) Release() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	select {
+	case <-s.release:
+	default:
+		close(s.release)
+	}
+}
+
+func (s *openAIStub) Bodies() []map[string]any {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]map[string]any(nil), s.bodies...)
+}
+
+// runTaskToCompletion 启动任务并等待运行结束，返回最终事件携带的状态快照。
+func runTaskToCompletion(t *testing.T, s *serverImpl, taskID string, stub *openAIStub) *RunState {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	runID, err := s.StartRun(taskID)
+	if err != nil {
+		t.Fatalf("StartRun: %v", err)
+	}
+	ch, cancel := s.SubscribeRunEvents(runID)
+	defer cancel()
+	stub.Release()
+
+	timeout := time.After(10 * time.Second)
+	for {
+		select {
+		case ev, ok := <-ch:
+			if !ok {
+				t.Fatal("event channel closed before run finished")
+			}
+			if ev.Kind == EventRunComplete || ev.Kind == EventRunFailed || ev.Kind == EventRunStopped {
+				snap, _ := ev.Payload.(*RunState)
+				if snap == nil {
+					t.Fatalf("final event %s carries no run state", ev.Kind)
+				}
+				return snap
+			}
+		case <-timeout:
+			t.Fatal("timeout waiting for run to finish")
+		}
+	}
+}
+
+// ── compare stream ────────────────────────────────────────────────────────────
+
+func TestStartRun_CompareStreamRunsBothPhases(t *testing.T) {
+	s := newTestServer(t)
+	stub := newOpenAIStub(t)
+
+	cfg := makeTaskConfig("compare-stream")
+	cfg.Input.EndpointURL = stub.URL
+	cfg.Input.Count = 2
+	cfg.Input.CompareStream = true
+	task, err := s.CreateTask(cfg)
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+
+	snap := runTaskToCompletion(t, s, task.ID, stub)
+	if snap.Status != RunStatusCompleted {
+		t.Fatalf("Status: got %q, want completed (err=%q)", snap.Status, snap.ErrorMsg)
+	}
+	if snap.TotalReqs != 4 || snap.DoneReqs != 4 {
+		t.Errorf("TotalReqs/DoneReqs: got %d/%d, want 4/4", snap.TotalReqs, snap.DoneReqs)
+	}
+
+	result, ok := snap.ModeResult.(*types.StreamCompareResult)
+	if !ok {
+		t.Fatalf("ModeResult: got %T, want *types.StreamCompareResult", snap.ModeResult)
+	}
+	if result.Stream == nil || result.Stream.StreamMode != types.StreamModeStream || !result.Stream.IsStream {
+		t.Errorf("unexpected stream phase: %+v", result.Stream)
+	}
+	if result.NonStream == nil || result.NonStream.StreamMode != types.StreamModeNonStream || result.NonStream.IsStream {
+		t.Errorf("unexpected non-stream phase: %+v", result.NonStream)
+	}
+
+	streamed := 0
+	for _, body := range stub.Bodies() {
+		if stream, _ := body["stream"].(bool); stream {
+			streamed++
+		}
+	}
+	if total := len(stub.Bodies()); total != 4 || streamed != 2 {
+		t.Errorf("requests: got %d total / %d streamed, want 4 / 2", total, streamed)
+	}
+}
+
+func TestStartRun_CompareStreamSplitCount(t *testing.T) {
+	s := newTestServer(t)
+	stub := newOpenAIStub(t)
+
+	cfg := makeTaskConfig("compare-stream-split")
+	cfg.Input.EndpointURL = stub.URL
+	cfg.Input.Count = 3
+	cfg.Input.CompareStream = true
+	cfg.Input.CompareStreamSplit = true
+	task, err := s.CreateTask(cfg)
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+
+	snap := runTaskToCompletion(t, s, task.ID, stub)
+	result, ok := snap.ModeResult.(*types.StreamCompareResult)
+	if !ok {
+		t.Fatalf("ModeResult: got %T, want *types.StreamCompareResult", snap.ModeResult)
+	}
+	if result.Stream.TotalRequests != 2 || result.NonStream.TotalRequests != 1 {
+		t.Errorf("phase sizes: got %d/%d, want 2/1", result.Stream.TotalRequests, result.NonStream.TotalRequests)
+	}
+	if snap.TotalReqs != 3 {
+		t.Errorf("TotalReqs: got %d, want 3", snap.TotalReqs)
+	}
+}
+
+// ── self stats ────────────────────────────────────────────────────────────────
+
+func TestStartRun_SelfStatsRecordedAndPersisted(t *testing.T) {
+	s := newTestServer(t)
+	stub := newOpenAIStub(t)
+
+	cfg := makeTaskConfig("self-stats")
+	cfg.Input.EndpointURL = stub.URL
+	cfg.Input.Count = 2
+	cfg.Input.SelfStats = true
+	task, err := s.CreateTask(cfg)
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+
+	snap := runTaskToCompletion(t, s, task.ID, stub)
+	if snap.SelfStats == nil {
+		t.Fatal("SelfStats: got nil, want sampled stats")
+	}
+	if snap.SelfStats.PeakGoroutines <= 0 || snap.SelfStats.PeakHeapAlloc == 0 {
+		t.Errorf("unexpected self stats: %+v", snap.SelfStats)
+	}
+	if data, ok := snap.ModeResult.(*types.ReportData); !ok || data.SelfStats == nil {
+		t.Errorf("ReportData.SelfStats not set: %T", snap.ModeResult)
+	}
+
+	result := buildStoredRunResult(snap)
+	if result.SelfStats != snap.SelfStats {
+		t.Error("stored run result does not carry SelfStats")
+	}
+}
+
+func TestStartRun_SelfStatsDisabledByDefault(t *testing.T) {
+	s := newTestServer(t)
+	stub := newOpenAIStub(t)
+
+	cfg := makeTaskConfig("no-self-stats")
+	cfg.Input.EndpointURL = stub.URL
+	cfg.Input.Count = 1
+	task, err := s.CreateTask(cfg)
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+
+	if snap := runTaskToCompletion(t, s, task.ID, stub); snap.SelfStats != nil {
+		t.Errorf("SelfStats: got %+v, want nil", snap.SelfStats)
+	}
+}
+
+// ── csv report options ────────────────────────────────────────────────────────
+
+func TestCSVRenderer_UsesTaskReportOptions(t *testing.T) {
+	s := newTestServer(t)
+	s.version = "v9.9.9"
+
+	cfg := makeTaskConfig("csv-options")
+	cfg.Input.CSVHeaderComment = true
+	cfg.Input.CSVLegacyFormat = true
+	task, err := s.CreateTask(cfg)
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+
+	r := s.csvRenderer(task.ID)
+	if !r.HeaderComment || !r.LegacyFormat || r.Version != "v9.9.9" || r.TaskID != task.ID {
+		t.Errorf("unexpected renderer: %+v", r)
+	}
+
+	if r := s.csvRenderer("missing"); r.HeaderComment || r.LegacyFormat || r.TaskID != "missing" {
+		t.Errorf("missing task should fall back to defaults: %+v", r)
+	}
+}
+
+// ── adaptive rate limiting ────────────────────────────────────────────────────
+
+func TestStartRun_AdaptiveRetriesThrottledRequests(t *testing.T) {
+	s := newTestServer(t)
+	stub := newOpenAIStub(t)
+
+	// 前两个请求返回 429，之后交给桩服务正常响应
+	var mu sync.Mutex
+	throttled := 0
+	inner := stub.Config.Handler
+	stub.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		reject := throttled < 2
+		if reject {
+			throttled++
+		}
+		mu.Unlock()
+		if reject {
+			<-stub.release
+			w.Header().Set("Retry-After", "0.05")
+			w.WriteHeader(http.StatusTooManyRequests)
+			fmt.Fprint(w, `{"error":{"type":"rate_limit_error","message":"too many requests"}}`)
+			return
+		}
+		inner.ServeHTTP(w, r)
+	})
+
+	cfg := makeTaskConfig("adaptive")
+	cfg.Input.EndpointURL = stub.URL
+	cfg.Input.Count = 3
+	cfg.Input.Concurrency = 1
+	cfg.Input.Adaptive = true
+	task, err := s.CreateTask(cfg)
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+
+	snap := runTaskToCompletion(t, s, task.ID, stub)
+	if snap.SuccessReqs != 3 || snap.FailedReqs != 0 {
+		t.Fatalf("Success/Failed: got %d/%d, want 3/0 (429 must be retried)", snap.SuccessReqs, snap.FailedReqs)
+	}
+	data, ok := snap.ModeResult.(*types.ReportData)
+	if !ok || data.Adaptive == nil {
+		t.Fatalf("ReportData.Adaptive missing: %T", snap.ModeResult)
+	}
+	if data.Adaptive.ThrottledResponses != 2 || data.Adaptive.Retries != 2 {
+		t.Errorf("adaptive stats = %+v, want 2 throttled / 2 retries", data.Adaptive)
+	}
+	if data.Adaptive.ThrottleLostTime < 50*time.Millisecond || len(data.Adaptive.Timeline) == 0 {
+		t.Errorf("adaptive stats = %+v", data.Adaptive)
+	}
+	retried := 0
+	for _, r := range snap.Requests {
+		retried += r.ThrottleRetries
+	}
+	if retried != 2 {
+		t.Errorf("request ThrottleRetries sum = %d, want 2", retried)
+	}
+}
+
+// ── baseline regression ───────────────────────────────────────────────────────
+
+func TestStartRun_BaselineSavedThenCompared(t *testing.T) {
+	s := newTestServer(t)
+	stub := newOpenAIStub(t)
+	baselineDir := t.TempDir()
+
+	cfg := makeTaskConfig("nightly")
+	cfg.Input.EndpointURL = stub.URL
+	cfg.Input.Count = 2
+	cfg.Input.Concurrency = 1
+	cfg.Input.BaselineDir = baselineDir
+	task, err := s.CreateTask(cfg)
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+
+	// 首次运行：保存基线，不判定回归
+	first := runTaskToCompletion(t, s, task.ID, stub)
+	data, ok := first.ModeResult.(*types.ReportData)
+	if !ok || data.Baseline == nil || !data.Baseline.Created {
+		t.Fatalf("first run should create baseline: %+v", data)
+	}
+	if s.Regressed() {
+		t.Fatal("creating a baseline must not count as regression")
+	}
+
+	// 把基线的平均耗时改成 1ns，使第二次运行必然判定为回归
+	raw, err := os.ReadFile(data.Baseline.Path)
+	if err != nil {
+		t.Fatalf("read baseline: %v", err)
+	}
+	var baseline types.ReportData
+	if err := json.Unmarshal(raw, &baseline); err != nil {
+		t.Fatalf("parse baseline: %v", err)
+	}
+	baseline.AvgTotalTime = time.Nanosecond
+	raw, _ = json.Marshal(&baseline)
+	if err := os.WriteFile(data.Baseline.Path, raw, 0o644); err != nil {
+		t.Fatalf("write baseline: %v", err)
+	}
+
+	second := runTaskToCompletion(t, s, task.ID, stub)
+	data, ok = second.ModeResult.(*types.ReportData)
+	if !ok || data.Baseline == nil || data.Baseline.Created || !data.Baseline.Regressed {
+		t.Fatalf("second run should regress against baseline: %+v", data.Baseline)
+	}
+	if !s.Regressed() {
+		t.Error("Server.Regressed should report the regression")
+	}
+}
+
+// ── SLA ───────────────────────────────────────────────────────────────────────
+
+func TestStartRun_SLA(t *testing.T) {
+	s := newTestServer(t)
+	stub := newOpenAIStub(t)
+
+	cfg := makeTaskConfig("sla")
+	cfg.Input.EndpointURL = stub.URL
+	cfg.Input.Count = 2
+	cfg.Input.Concurrency = 1
+	cfg.Input.SLA = []string{" total<1h,p=95 ", ""}
+	task, err := s.CreateTask(cfg)
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+	if got := task.Input.SLA; len(got) != 1 || got[0] != "total<1h,p=95" {
+		t.Fatalf("sla should be trimmed and blanks dropped, got %q", got)
+	}
+
+	// 进程级 --sla 追加在任务 SLA 之后；1ns 必然不达标
+	SetSLA([]string{"ttft<1ns"})
+	t.Cleanup(func() { SetSLA(nil) })
+
+	snap := runTaskToCompletion(t, s, task.ID, stub)
+	data, ok := snap.ModeResult.(*types.ReportData)
+	if !ok {
+		t.Fatalf("ModeResult: got %T, want *types.ReportData", snap.ModeResult)
+	}
+	if len(data.SLAResults) != 2 {
+		t.Fatalf("SLAResults = %+v, want 2 entries", data.SLAResults)
+	}
+	if r := data.SLAResults[0]; !r.Met || r.Compliant != 2 || r.Rate != 100 {
+		t.Errorf("task SLA = %+v, want met", r)
+	}
+	if r := data.SLAResults[1]; r.Expr != "ttft<1ns" || r.Met {
+		t.Errorf("process SLA = %+v, want failed", r)
+	}
+	if !s.SLAFailed() {
+		t.Error("Server.SLAFailed should report the failed SLA")
+	}
+}
+
+func TestStartRun_ReportEnvironment(t *testing.T) {
+	s := newTestServer(t)
+	s.version = "v9.9.9"
+	stub := newOpenAIStub(t)
+
+	cfg := makeTaskConfig("environment")
+	cfg.Input.EndpointURL = stub.URL
+	task, err := s.CreateTask(cfg)
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+
+	snap := runTaskToCompletion(t, s, task.ID, stub)
+	data, ok := snap.ModeResult.(*types.ReportData)
+	if !ok {
+		t.Fatalf("ModeResult: got %T, want *types.ReportData", snap.ModeResult)
+	}
+	env := data.Environment
+	if env == nil {
+		t.Fatal("Environment should be filled for standard runs")
+	}
+	hostname, _ := os.Hostname()
+	if env.OS != runtime.GOOS || env.Arch != runtime.GOARCH || env.AitVersion != "v9.9.9" || env.Hostname != hostname {
+		t.Errorf("Environment = %+v", env)
+	}
+}
+
+func TestCreateTask_RejectsInvalidSLA(t *testing.T) {
+	s := newTestServer(t)
+	cfg := makeTaskConfig("bad-sla")
+	cfg.Input.SLA = []string{"ttft<800ms", "ttft<fast"}
+	_, err := s.CreateTask(cfg)
+	if err == nil || !strings.Contains(err.Error(), `invalid duration "fast"`) {
+		t.Fatalf("expected sla syntax error, got %v", err)
+	}
+}
+
+func TestCreateTask_PromptLengthDist(t *testing.T) {
+	s := newTestServer(t)
+	cfg := makeTaskConfig("length-dist")
+	cfg.Input.PromptMode = "generated"
+	cfg.Input.PromptText = ""
+	cfg.Input.PromptLengthDist = " 100 : 0.8 , 4000:0.2 "
+	task, err := s.CreateTask(cfg)
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+	if got := task.Input.PromptLengthDist; got != "100:0.8,4000:0.2" {
+		t.Errorf("prompt_length_dist should be normalized, got %q", got)
+	}
+
+	cfg.Input.PromptMode = "text"
+	cfg.Input.PromptText = "hi"
+	if _, err := s.CreateTask(cfg); err == nil {
+		t.Error("expected error for prompt_length_dist outside generated mode")
+	}
+	cfg.Input.PromptMode = "generated"
+	cfg.Input.PromptLengthDist = "100:0"
+	if _, err := s.CreateTask(cfg); err == nil {
+		t.Error("expected error for a zero weight")
+	}
+}
+
+func TestCreateTask_ToolsFile(t *testing.T) {
+	s := newTestServer(t)
+	path := filepath.Join(t.TempDir(), "tools.json")
+	if err := os.WriteFile(path, []byte(`[{"type":"function","function":{"name":"get_weather"}}]`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg := makeTaskConfig("tools")
+	cfg.Input.ToolsFile = " " + path + " "
+	task, err := s.CreateTask(cfg)
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+	if task.Input.ToolsFile != path {
+		t.Errorf("tools_file should be trimmed, got %q", task.Input.ToolsFile)
+	}
+
+	cfg.Input.Protocol = types.ProtocolAnthropicMessages
+	if _, err := s.CreateTask(cfg); err == nil {
+		t.Error("expected error for tools_file with a non-openai protocol")
+	}
+	cfg.Input.Protocol = types.ProtocolOpenAICompletions
+	cfg.Input.ToolsFile = filepath.Join(t.TempDir(), "missing.json")
+	if _, err := s.CreateTask(cfg); err == nil {
+		t.Error("expected error for a missing tools_file")
+	}
+}
+
+// ── progress stream ───────────────────────────────────────────────────────────
+
+func TestStartRun_ProgressStream(t *testing.T) {
+	s := newTestServer(t)
+	stub := newOpenAIStub(t)
+	var buf syncBuffer
+	SetProgressWriter(&buf)
+	t.Cleanup(func() { SetProgressWriter(nil) })
+
+	cfg := makeTaskConfig("progress")
+	cfg.Input.EndpointURL = stub.URL
+	cfg.Input.Count = 3
+	cfg.Input.Concurrency = 1
+	task, err := s.CreateTask(cfg)
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+	snap := runTaskToCompletion(t, s, task.ID, stub)
+
+	var lines []ProgressLine
+	for _, raw := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var line ProgressLine
+		if err := json.Unmarshal([]byte(raw), &line); err != nil {
+			t.Fatalf("progress line is not JSON: %q: %v", raw, err)
+		}
+		if line.RunID != snap.RunID {
+			t.Errorf("line run_id = %q, want %q", line.RunID, snap.RunID)
+		}
+		if line.Event == EventRequestDone || line.Event == EventRequestQueued {
+			t.Errorf("per-request event %s should not be written", line.Event)
+		}
+		lines = append(lines, line)
+	}
+	if len(lines) < 2 || lines[0].Event != EventRunStarted {
+		t.Fatalf("progress lines = %+v, want run_started first", lines)
+	}
+	last := lines[len(lines)-1]
+	if last.Event != EventRunComplete || last.Total != 3 || last.Done != 3 || last.Success != 3 || last.Status != RunStatusCompleted {
+		t.Errorf("last progress line = %+v", last)
+	}
+}
+
+// syncBuffer 并发安全的 bytes.Buffer，用于收集进度流。
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// ── token budget ──────────────────────────────────────────────────────────────
+
+func TestStartRun_TokenBudgetStopsDispatch(t *testing.T) {
+	s := newTestServer(t)
+	stub := newOpenAIStub(t)
+
+	// 桩服务每个请求消耗 3+2=5 个 token，串行执行时第 3 个请求完成后超出预算
+	cfg := makeTaskConfig("token-budget")
+	cfg.Input.EndpointURL = stub.URL
+	cfg.Input.Concurrency = 1
+	cfg.Input.Count = 10
+	cfg.Input.TokenBudget = 12
+	task, err := s.CreateTask(cfg)
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+
+	snap := runTaskToCompletion(t, s, task.ID, stub)
+	if snap.Status != RunStatusCompleted {
+		t.Fatalf("Status: got %q, want completed (err=%q)", snap.Status, snap.ErrorMsg)
+	}
+	if got := len(stub.Bodies()); got != 3 {
+		t.Errorf("requests sent: got %d, want 3", got)
+	}
+	if snap.DoneReqs != 3 || snap.SkippedReqs != 7 {
+		t.Errorf("DoneReqs/SkippedReqs: got %d/%d, want 3/7", snap.DoneReqs, snap.SkippedReqs)
+	}
+
+	data, ok := snap.ModeResult.(*types.ReportData)
+	if !ok {
+		t.Fatalf("ModeResult: got %T, want *types.ReportData", snap.ModeResult)
+	}
+	if data.TotalRequests != 3 || data.SuccessRate != 100 {
+		t.Errorf("report covers %d requests at %.0f%%, want 3 at 100%%", data.TotalRequests, data.SuccessRate)
+	}
+	want := types.TokenBudgetStats{Budget: 12, Used: 15, Exhausted: true}
+	if data.TokenBudget == nil || *data.TokenBudget != want {
+		t.Errorf("TokenBudget: got %+v, want %+v", data.TokenBudget, want)
+	}
+}
+
+func TestStartRun_AbortOnErrorRate(t *testing.T) {
+	s := newTestServer(t)
+	stub := newOpenAIStub(t)
+	var sent atomic.Int32
+	notFound := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sent.Add(1)
+		http.Error(w, `{"error":{"message":"model not found"}}`, http.StatusNotFound)
+	}))
+	t.Cleanup(notFound.Close)
+	SetAbortOnErrorRate(50, 5)
+	t.Cleanup(func() { SetAbortOnErrorRate(0, 0) })
+
+	cfg := makeTaskConfig("abort-on-error")
+	cfg.Input.EndpointURL = notFound.URL
+	cfg.Input.Concurrency = 1
+	cfg.Input.Count = 50
+	task, err := s.CreateTask(cfg)
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+
+	snap := runTaskToCompletion(t, s, task.ID, stub)
+	if got := sent.Load(); got != 5 {
+		t.Errorf("requests sent: got %d, want 5", got)
+	}
+	if snap.DoneReqs != 5 {
+		t.Errorf("DoneReqs: got %d, want 5", snap.DoneReqs)
+	}
+	data, ok := snap.ModeResult.(*types.ReportData)
+	if !ok {
+		t.Fatalf("ModeResult: got %T, want *types.ReportData", snap.ModeResult)
+	}
+	want := types.AbortStats{Threshold: 50, MinSamples: 5, Completed: 5, Failed: 5, ErrorRate: 100}
+	if !data.AbortedEarly || data.Abort == nil || *data.Abort != want {
+		t.Errorf("AbortedEarly/Abort: got %v/%+v, want true/%+v", data.AbortedEarly, data.Abort, want)
+	}
+	if !s.AbortedEarly() {
+		t.Error("server should report an aborted run")
+	}
+}
+
+func TestStartRun_AbortOnErrorRateNotTriggered(t *testing.T) {
+	s := newTestServer(t)
+	stub := newOpenAIStub(t)
+
+	cfg := makeTaskConfig("abort-not-triggered")
+	cfg.Input.EndpointURL = stub.URL
+	cfg.Input.Count = 6
+	cfg.Input.AbortOnErrorRate = 10
+	cfg.Input.AbortMinSamples = 2
+	task, err := s.CreateTask(cfg)
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+
+	snap := runTaskToCompletion(t, s, task.ID, stub)
+	if snap.DoneReqs != 6 {
+		t.Errorf("DoneReqs: got %d, want 6", snap.DoneReqs)
+	}
+	data, ok := snap.ModeResult.(*types.ReportData)
+	if !ok {
+		t.Fatalf("ModeResult: got %T, want *types.ReportData", snap.ModeResult)
+	}
+	if data.AbortedEarly || data.Abort != nil || s.AbortedEarly() {
+		t.Errorf("run should not be aborted: %v/%+v", data.AbortedEarly, data.Abort)
+	}
+}
+
+func TestStartRun_TokenBudgetSharedByCompareStream(t *testing.T) {
+	s := newTestServer(t)
+	stub := newOpenAIStub(t)
+
+	cfg := makeTaskConfig("token-budget-compare")
+	cfg.Input.EndpointURL = stub.URL
+	cfg.Input.Concurrency = 1
+	cfg.Input.Count = 2
+	cfg.Input.CompareStream = true
+	cfg.Input.TokenBudget = 5
+	task, err := s.CreateTask(cfg)
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+
+	snap := runTaskToCompletion(t, s, task.ID, stub)
+	result, ok := snap.ModeResult.(*types.StreamCompareResult)
+	if !ok {
+		t.Fatalf("ModeResult: got %T, want *types.StreamCompareResult", snap.ModeResult)
+	}
+	if result.Stream == nil || result.Stream.TotalRequests != 1 {
+		t.Errorf("stream phase should stop after the first request: %+v", result.Stream)
+	}
+	if result.NonStream != nil {
+		t.Errorf("non-stream phase should be skipped once the budget is exhausted: %+v", result.NonStream)
+	}
+	if got := len(stub.Bodies()); got != 1 {
+		t.Errorf("requests sent: got %d, want 1", got)
+	}
+}
+
+func TestCreateTask_RejectsNegativeTokenBudget(t *testing.T) {
+	s := newTestServer(t)
+	cfg := makeTaskConfig("negative-budget")
+	cfg.Input.TokenBudget = -1
+	if _, err := s.CreateTask(cfg); err == nil {
+		t.Fatal("expected error for negative token_budget")
+	}
+}
+
+func TestCreateTask_RejectsThinkingBudgetNotBelowMaxTokens(t *testing.T) {
+	s := newTestServer(t)
+	cfg := makeTaskConfig("thinking-budget")
+	cfg.Input.ThinkingBudget = 4096
+	cfg.Input.MaxTokens = 1024
+	if _, err := s.CreateTask(cfg); err == nil || !strings.Contains(err.Error(), "thinking_budget") {
+		t.Fatalf("err = %v, want thinking_budget error", err)
+	}
+
+	cfg = makeTaskConfig("anthropic-thinking")
+	cfg.Input.Protocol = types.ProtocolAnthropicMessages
+	cfg.Input.Thinking = true
+	cfg.Input.MaxTokens = 512
+	if _, err := s.CreateTask(cfg); err == nil || !strings.Contains(err.Error(), "max_tokens") {
+		t.Fatalf("err = %v, want max_tokens error", err)
+	}
+}
+
+// TestCreateTask_UsesSharedValidator 创建任务与 ait validate 共用 config.CheckInput 的规则与提示。
+func TestCreateTask_UsesSharedValidator(t *testing.T) {
+	s := newTestServer(t)
+	acceptEncoding := makeTaskConfig("accept-encoding")
+	acceptEncoding.Input.AcceptEncoding = "deflate"
+	turboCfg := makeTaskConfig("turbo-levels")
+	turboCfg.Input.Mode = "turbo"
+	turboCfg.Input.TurboConfig = types.TurboConfig{InitConcurrency: 8, MaxConcurrency: 4}
+	webhook := makeTaskConfig("webhook-url")
+	webhook.Input.WebhookURL = "hooks.example.com"
+
+	for _, cfg := range []TaskConfig{acceptEncoding, turboCfg, webhook} {
+		issues := config.CheckInput(cfg.Input)
+		if len(issues) == 0 {
+			t.Fatalf("%s: CheckInput found no issue", cfg.Name)
+		}
+		if _, err := s.CreateTask(cfg); err == nil || err.Error() != issues[0].Error() {
+			t.Errorf("%s: CreateTask err = %v, want %q", cfg.Name, err, issues[0].Error())
+		}
+	}
+}
+
+// ── webhook ───────────────────────────────────────────────────────────────────
+
+func TestStartRun_SendsWebhookSummary(t *testing.T) {
+	s := newTestServer(t)
+	stub := newOpenAIStub(t)
+
+	received := make(chan map[string]any, 1)
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		// 推送晚于运行结束完成，验证退出前的等待确实覆盖了它
+		time.Sleep(100 * time.Millisecond)
+		received <- body
+	}))
+	t.Cleanup(hook.Close)
+
+	cfg := makeTaskConfig("nightly-webhook")
+	cfg.Input.EndpointURL = stub.URL
+	cfg.Input.ApiKey = "sk-secret"
+	cfg.Input.WebhookURL = hook.URL
+	task, err := s.CreateTask(cfg)
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+	runTaskToCompletion(t, s, task.ID, stub)
+	if !WaitWebhooks(5 * time.Second) {
+		t.Fatal("WaitWebhooks timed out")
+	}
+
+	select {
+	case body := <-received:
+		if body["task"] != "nightly-webhook" || body["status"] != string(RunStatusCompleted) {
+			t.Errorf("webhook body: %v", body)
+		}
+		raw, _ := json.Marshal(body)
+		if strings.Contains(string(raw), "sk-secret") || strings.Contains(string(raw), stub.URL) {
+			t.Errorf("webhook body leaks connection info: %s", raw)
+		}
+	default:
+		t.Fatal("webhook was not sent before WaitWebhooks returned")
+	}
+}
+
+func TestWebhookWaitTimeout_FollowsTelemetryTimeout(t *testing.T) {
+	if got, want := WebhookWaitTimeout(), 3*webhookTimeout+2*webhookRetryDelay; got != want {
+		t.Errorf("default wait = %v, want %v", got, want)
+	}
+	network.SetTelemetryOptions(network.HTTPOptions{Timeout: 30 * time.Second})
+	t.Cleanup(func() { network.SetTelemetryOptions(network.HTTPOptions{}) })
+	if got, want := WebhookWaitTimeout(), 90*time.Second+2*webhookRetryDelay; got != want {
+		t.Errorf("wait with --telemetry-timeout 30s = %v, want %v", got, want)
+	}
+}
+
+func TestSendRunWebhook_RetriesTwiceOnFailure(t *testing.T) {
+	oldDelay := webhookRetryDelay
+	webhookRetryDelay = 0
+	var warnings bytes.Buffer
+	oldOut := webhookWarnOut
+	webhookWarnOut = &warnings
+	t.Cleanup(func() {
+		webhookRetryDelay = oldDelay
+		webhookWarnOut = oldOut
+	})
+
+	var attempts atomic.Int32
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	t.Cleanup(hook.Close)
+
+	taskDef := types.TaskDefinition{Name: "retry", Input: types.Input{WebhookURL: hook.URL}}
+	sendRunWebhook(taskDef, &RunState{Status: RunStatusCompleted, ModeResult: &types.ReportData{TotalRequests: 1}})
+	if got := attempts.Load(); got != 3 {
+		t.Errorf("attempts: got %d, want 3", got)
+	}
+	if !strings.Contains(warnings.String(), "retry") || !strings.Contains(warnings.String(), "webhook") {
+		t.Errorf("stdout warning: %q", warnings.String())
+	}
+}
+
+func TestCreateTask_RejectsInvalidWebhook(t *testing.T) {
+	s := newTestServer(t)
+	for _, input := range []types.Input{
+		{WebhookURL: "ftp://example.com/hook"},
+		{WebhookURL: "https://example.com/hook", WebhookFormat: "teams"},
+	} {
+		cfg := makeTaskConfig("bad-webhook")
+		cfg.Input.WebhookURL = input.WebhookURL
+		cfg.Input.WebhookFormat = input.WebhookFormat
+		if _, err := s.CreateTask(cfg); err == nil {
+			t.Errorf("expected error for webhook %q (%s)", input.WebhookURL, input.WebhookFormat)
+		}
+	}
+}
+
+// ── multi-endpoint ────────────────────────────────────────────────────────────
+
+func TestStartRun_MultiEndpointGroupsReport(t *testing.T) {
+	s := newTestServer(t)
+	stubA := newOpenAIStub(t)
+	stubB := newOpenAIStub(t)
+	stubB.Release()
+
+	cfg := makeTaskConfig("multi-endpoint")
+	cfg.Input.Endpoints = []string{stubA.URL, " ", stubB.URL, stubA.URL}
+	cfg.Input.Count = 4
+	task, err := s.CreateTask(cfg)
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+	if got := task.Input.Endpoints; len(got) != 2 {
+		t.Fatalf("endpoints should be trimmed and deduplicated, got %q", got)
+	}
+
+	snap := runTaskToCompletion(t, s, task.ID, stubA)
+	if len(stubA.Bodies()) != 2 || len(stubB.Bodies()) != 2 {
+		t.Errorf("requests per endpoint: %d/%d, want 2/2", len(stubA.Bodies()), len(stubB.Bodies()))
+	}
+	for _, req := range snap.Requests {
+		if req.Endpoint != stubA.URL && req.Endpoint != stubB.URL {
+			t.Errorf("request %d endpoint = %q", req.Index, req.Endpoint)
+		}
+	}
+	data, ok := snap.ModeResult.(*types.ReportData)
+	if !ok {
+		t.Fatalf("ModeResult: got %T, want *types.ReportData", snap.ModeResult)
+	}
+	if len(data.EndpointStats) != 2 || data.EndpointStats[0].Endpoint != stubA.URL || data.EndpointStats[0].Requests != 2 || data.EndpointStats[1].SuccessRate != 100 {
+		t.Errorf("EndpointStats = %+v", data.EndpointStats)
+	}
+}
+
+func TestCreateTask_RejectsUnknownEndpointStrategy(t *testing.T) {
+	s := newTestServer(t)
+	cfg := makeTaskConfig("bad-strategy")
+	cfg.Input.Endpoints = []string{"http://a", "http://b"}
+	cfg.Input.EndpointStrategy = "least-latency"
+	if _, err := s.CreateTask(cfg); err == nil {
+		t.Fatal("expected error for unknown endpoint_strategy")
+	}
+}
+
+// ── dynamic concurrency ───────────────────────────────────────────────────────
+
+func TestStartRun_AdjustRunConcurrency(t *testing.T) {
+	s := newTestServer(t)
+	stub := newOpenAIStub(t)
+	t.Setenv("HOME", t.TempDir())
+
+	cfg := makeTaskConfig("adjust-concurrency")
+	cfg.Input.EndpointURL = stub.URL
+	cfg.Input.Count = 6
+	cfg.Input.Concurrency = 1
+	task, err := s.CreateTask(cfg)
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+	runID, err := s.StartRun(task.ID)
+	if err != nil {
+		t.Fatalf("StartRun: %v", err)
+	}
+	ch, cancel := s.SubscribeRunEvents(runID)
+	defer cancel()
+
+	// waitRunning 等到同时执行的请求数达到 want（桩服务阻塞所有请求，运行数只增不减）
+	waitRunning := func(want int) {
+		t.Helper()
+		timeout := time.After(10 * time.Second)
+		for {
+			if snap, ok := s.GetRunState(runID); ok && snap.RunningReqs >= want {
+				if snap.RunningReqs != want {
+					t.Fatalf("RunningReqs = %d, want %d", snap.RunningReqs, want)
+				}
+				return
+			}
+			select {
+			case <-ch:
+			case <-time.After(20 * time.Millisecond):
+			case <-timeout:
+				t.Fatalf("timeout waiting for %d running requests", want)
+			}
+		}
+	}
+	waitRunning(1)
+	if n, err := s.AdjustRunConcurrency(runID, 2); err != nil || n != 3 {
+		t.Fatalf("AdjustRunConcurrency(+2) = %d, %v; want 3", n, err)
+	}
+	waitRunning(3)
+	if n, _ := s.AdjustRunConcurrency(runID, -10); n != 1 {
+		t.Errorf("concurrency should not drop below 1, got %d", n)
+	}
+	if snap, _ := s.GetRunState(runID); snap.Concurrency != 1 {
+		t.Errorf("RunState.Concurrency = %d, want 1", snap.Concurrency)
+	}
+	stub.Release()
+
+	timeout := time.After(10 * time.Second)
+	for {
+		select {
+		case ev, ok := <-ch:
+			if !ok {
+				t.Fatal("event channel closed before run finished")
+			}
+			if ev.Kind != EventRunComplete {
+				continue
+			}
+			snap := ev.Payload.(*RunState)
+			data := snap.ModeResult.(*types.ReportData)
+			if snap.SuccessReqs != 6 || data.Concurrency != 1 {
+				t.Errorf("Success = %d, Concurrency = %d", snap.SuccessReqs, data.Concurrency)
+			}
+			if len(data.ConcurrencyChanges) != 2 || data.ConcurrencyChanges[0].Concurrency != 3 || data.ConcurrencyChanges[1].Concurrency != 1 {
+				t.Errorf("ConcurrencyChanges = %+v", data.ConcurrencyChanges)
+			}
+			if _, err := s.AdjustRunConcurrency(runID, 1); err == nil {
+				t.Error("adjusting a finished run should fail")
+			}
+			return
+		case <-timeout:
+			t.Fatal("timeout waiting for run to finish")
+		}
+	}
+}
+
+func TestStartRun_SetRunPaused(t *testing.T) {
+	s := newTestServer(t)
+	stub := newOpenAIStub(t)
+	t.Setenv("HOME", t.TempDir())
+
+	cfg := makeTaskConfig("pause-run")
+	cfg.Input.EndpointURL = stub.URL
+	cfg.Input.Count = 4
+	cfg.Input.Concurrency = 1
+	task, err := s.CreateTask(cfg)
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+	runID, err := s.StartRun(task.ID)
+	if err != nil {
+		t.Fatalf("StartRun: %v", err)
+	}
+	ch, cancel := s.SubscribeRunEvents(runID)
+	defer cancel()
+
+	// waitState 轮询运行状态直到 cond 成立
+	waitState := func(desc string, cond func(*RunState) bool) *RunState {
+		t.Helper()
+		timeout := time.After(10 * time.Second)
+		for {
+			if snap, ok := s.GetRunState(runID); ok && cond(snap) {
+				return snap
+			}
+			select {
+			case <-ch:
+			case <-time.After(20 * time.Millisecond):
+			case <-timeout:
+				t.Fatalf("timeout waiting for %s", desc)
+			}
+		}
+	}
+	waitState("first request", func(rs *RunState) bool { return rs.RunningReqs == 1 })
+	if err := s.SetRunPaused(runID, true); err != nil {
+		t.Fatalf("SetRunPaused(true): %v", err)
+	}
+	// 暂停后在途请求照常完成，但不再派发新请求
+	stub.Release()
+	snap := waitState("in-flight request done", func(rs *RunState) bool { return rs.DoneReqs == 1 })
+	if !snap.Paused {
+		t.Error("RunState.Paused = false after pausing")
+	}
+	time.Sleep(150 * time.Millisecond)
+	if snap, _ := s.GetRunState(runID); snap.DoneReqs != 1 || snap.RunningReqs != 0 {
+		t.Fatalf("dispatched while paused: done = %d, running = %d", snap.DoneReqs, snap.RunningReqs)
+	}
+	if err := s.SetRunPaused(runID, false); err != nil {
+		t.Fatalf("SetRunPaused(false): %v", err)
+	}
+
+	timeout := time.After(10 * time.Second)
+	for {
+		select {
+		case ev, ok := <-ch:
+			if !ok {
+				t.Fatal("event channel closed before run finished")
+			}
+			if ev.Kind != EventRunComplete {
+				continue
+			}
+			snap := ev.Payload.(*RunState)
+			data := snap.ModeResult.(*types.ReportData)
+			if snap.SuccessReqs != 4 || snap.Paused {
+				t.Errorf("Success = %d, Paused = %v", snap.SuccessReqs, snap.Paused)
+			}
+			// 暂停时间不计入总耗时
+			if data.PausedTime < 150*time.Millisecond || data.TotalTime >= snap.FinishedAt.Sub(snap.StartedAt)-data.PausedTime+50*time.Millisecond {
+				t.Errorf("PausedTime = %s, TotalTime = %s, wall = %s", data.PausedTime, data.TotalTime, snap.FinishedAt.Sub(snap.StartedAt))
+			}
+			if err := s.SetRunPaused(runID, true); err == nil {
+				t.Error("pausing a finished run should fail")
+			}
+			return
+		case <-timeout:
+			t.Fatal("timeout waiting for run to finish")
+		}
+	}
+}
+
+// ── burst ─────────────────────────────────────────────────────────────────────
+
+func TestStartRun_BurstMode(t *testing.T) {
+	s := newTestServer(t)
+	stub := newOpenAIStub(t)
+
+	cfg := makeTaskConfig("burst")
+	cfg.Input.EndpointURL = stub.URL
+	cfg.Input.Count = 5
+	cfg.Input.Concurrency = 1
+	cfg.Input.BurstSize = 2
+	cfg.Input.BurstInterval = 100 * time.Millisecond
+	task, err := s.CreateTask(cfg)
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+
+	snap := runTaskToCompletion(t, s, task.ID, stub)
+	if snap.SuccessReqs != 5 {
+		t.Fatalf("SuccessReqs = %d, want 5", snap.SuccessReqs)
+	}
+	data, ok := snap.ModeResult.(*types.ReportData)
+	if !ok {
+		t.Fatalf("ModeResult: got %T, want *types.ReportData", snap.ModeResult)
+	}
+	if data.Concurrency != 2 {
+		t.Errorf("Concurrency = %d, want burst size 2", data.Concurrency)
+	}
+	if len(data.Bursts) != 3 || data.Bursts[0].Requests != 2 || data.Bursts[2].Requests != 1 {
+		t.Fatalf("Bursts = %+v", data.Bursts)
+	}
+	for i := 1; i < len(data.Bursts); i++ {
+		if gap := data.Bursts[i].Start - data.Bursts[i-1].Start; gap < 100*time.Millisecond {
+			t.Errorf("burst %d started %v after the previous one, want >= 100ms", i, gap)
+		}
+	}
+}
+
+func TestStartRun_NetworkProbes(t *testing.T) {
+	s := newTestServer(t)
+	stub := newOpenAIStub(t)
+
+	cfg := makeTaskConfig("probe")
+	cfg.Input.EndpointURL = stub.URL
+	cfg.Input.Count = 2
+	cfg.Input.ProbeInterval = 20 * time.Millisecond
+	task, err := s.CreateTask(cfg)
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+
+	snap := runTaskToCompletion(t, s, task.ID, stub)
+	data, ok := snap.ModeResult.(*types.ReportData)
+	if !ok {
+		t.Fatalf("ModeResult: got %T, want *types.ReportData", snap.ModeResult)
+	}
+	if len(data.NetworkProbes) == 0 || data.NetworkProbeSummary == nil || data.NetworkProbeSummary.Samples != len(data.NetworkProbes) {
+		t.Fatalf("probes = %+v, summary = %+v", data.NetworkProbes, data.NetworkProbeSummary)
+	}
+	if data.NetworkProbes[0].Error != "" {
+		t.Errorf("probe to the stub failed: %s", data.NetworkProbes[0].Error)
+	}
+	if snap.SuccessReqs != 2 {
+		t.Errorf("SuccessReqs = %d, probes must not count as requests", snap.SuccessReqs)
+	}
+}
+
+func TestStartRun_InputLengthSweep(t *testing.T) {
+	s := newTestServer(t)
+	stub := newOpenAIStub(t)
+
+	cfg := makeTaskConfig("length-sweep")
+	cfg.Input.EndpointURL = stub.URL
+	cfg.Input.Count = 2
+	cfg.Input.Stream = true
+	cfg.Input.PromptMode = "generated"
+	cfg.Input.PromptText = ""
+	cfg.Input.InputLengthSweep = []int{100, 400}
+	task, err := s.CreateTask(cfg)
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+
+	snap := runTaskToCompletion(t, s, task.ID, stub)
+	if snap.Status != RunStatusCompleted {
+		t.Fatalf("Status: got %q, want completed (err=%q)", snap.Status, snap.ErrorMsg)
+	}
+	if snap.TotalReqs != 4 || snap.DoneReqs != 4 {
+		t.Errorf("TotalReqs/DoneReqs: got %d/%d, want 4/4", snap.TotalReqs, snap.DoneReqs)
+	}
+	result, ok := snap.ModeResult.(*types.InputLengthSweepResult)
+	if !ok {
+		t.Fatalf("ModeResult: got %T, want *types.InputLengthSweepResult", snap.ModeResult)
+	}
+	if len(result.Points) != 2 || result.Points[0].InputLength != 100 || result.Points[1].InputLength != 400 {
+		t.Fatalf("points = %+v", result.Points)
+	}
+	if result.Points[0].AvgPrefillTPS <= 0 {
+		t.Errorf("AvgPrefillTPS = %v, want > 0", result.Points[0].AvgPrefillTPS)
+	}
+
+	// 并发为 1 时请求按序号发出，后一轮的 prompt 更长
+	promptSize := func(body map[string]any) int {
+		b, _ := json.Marshal(body["messages"])
+		return len(b)
+	}
+	bodies := stub.Bodies()
+	if len(bodies) != 4 || promptSize(bodies[1]) >= promptSize(bodies[2]) {
+		t.Errorf("want 4 requests with a longer prompt in the second round, got %d", len(bodies))
+	}
+}
+
+func TestCreateTask_RejectsInvalidLengthSweep(t *testing.T) {
+	s := newTestServer(t)
+	for name, mutate := range map[string]func(*types.Input){
+		"text prompt":    func(in *types.Input) { in.PromptMode = "text" },
+		"non-stream":     func(in *types.Input) { in.Stream = false },
+		"compare stream": func(in *types.Input) { in.CompareStream = true },
+		"duplicate":      func(in *types.Input) { in.InputLengthSweep = []int{100, 100} },
+		"zero length":    func(in *types.Input) { in.InputLengthSweep = []int{0} },
+	} {
+		cfg := makeTaskConfig("bad-sweep")
+		cfg.Input.PromptMode = "generated"
+		cfg.Input.Stream = true
+		cfg.Input.InputLengthSweep = []int{100, 400}
+		mutate(&cfg.Input)
+		if _, err := s.CreateTask(cfg); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestStartRun_PromptFilePerRun(t *testing.T) {
+	s := newTestServer(t)
+	stub := newOpenAIStub(t)
+
+	dir := t.TempDir()
+	for name, content := range map[string]string{"code.txt": "prompt-code", "translate.txt": "prompt-translate"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cfg := makeTaskConfig("prompt-files")
+	cfg.Input.EndpointURL = stub.URL
+	cfg.Input.Count = 2
+	cfg.Input.PromptMode = "file"
+	cfg.Input.PromptText = ""
+	cfg.Input.PromptFile = filepath.Join(dir, "*.txt")
+	cfg.Input.PromptFilePerRun = true
+	task, err := s.CreateTask(cfg)
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+
+	snap := runTaskToCompletion(t, s, task.ID, stub)
+	if snap.Status != RunStatusCompleted {
+		t.Fatalf("Status: got %q, want completed (err=%q)", snap.Status, snap.ErrorMsg)
+	}
+	if snap.TotalReqs != 4 || snap.DoneReqs != 4 {
+		t.Errorf("TotalReqs/DoneReqs: got %d/%d, want 4/4", snap.TotalReqs, snap.DoneReqs)
+	}
+	result, ok := snap.ModeResult.(*types.PromptFileRunsResult)
+	if !ok {
+		t.Fatalf("ModeResult: got %T, want *types.PromptFileRunsResult", snap.ModeResult)
+	}
+	if len(result.Scenarios) != 2 || result.Scenarios[0].Scenario != "code" || result.Scenarios[1].Scenario != "translate" {
+		t.Fatalf("scenarios = %+v", result.Scenarios)
+	}
+
+	// 并发为 1 时请求按序号发出，每轮只取本场景文件中的 prompt
+	bodies := stub.Bodies()
+	if len(bodies) != 4 {
+		t.Fatalf("want 4 requests, got %d", len(bodies))
+	}
+	for i, want := range []string{"prompt-code", "prompt-code", "prompt-translate", "prompt-translate"} {
+		if b, _ := json.Marshal(bodies[i]["messages"]); !strings.Contains(string(b), want) {
+			t.Errorf("request %d messages = %s, want %q", i, b, want)
+		}
+	}
+}
+
+func TestCreateTask_RejectsInvalidPromptFilePerRun(t *testing.T) {
+	s := newTestServer(t)
+	for name, mutate := range map[string]func(*types.Input){
+		"text prompt":       func(in *types.Input) { in.PromptMode = "text"; in.PromptText = "hi" },
+		"compare stream":    func(in *types.Input) { in.CompareStream = true },
+		"consistency check": func(in *types.Input) { in.ConsistencyCheck = true },
+	} {
+		cfg := makeTaskConfig("bad-prompt-files")
+		cfg.Input.PromptMode = "file"
+		cfg.Input.PromptFile = "prompts/*.txt"
+		cfg.Input.PromptFilePerRun = true
+		mutate(&cfg.Input)
+		if _, err := s.CreateTask(cfg); err == nil || !strings.Contains(err.Error(), "prompt_file_per_run") {
+			t.Errorf("%s: err = %v, want prompt_file_per_run error", name, err)
+		}
+	}
+}
+
+func TestStartRun_ReplayFile(t *testing.T) {
+	s := newTestServer(t)
+	stub := newOpenAIStub(t)
+
+	replayPath := filepath.Join(t.TempDir(), "failed.jsonl")
+	lines := `{"task_id":"origin","index":5,"prompt":"replay-five"}` + "\n" + `{"task_id":"origin","index":9,"prompt":"replay-nine"}` + "\n"
+	if err := os.WriteFile(replayPath, []byte(lines), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg := makeTaskConfig("replay")
+	cfg.Input.EndpointURL = stub.URL
+	cfg.Input.Count = 10
+	cfg.Input.ReplayFile = replayPath
+	task, err := s.CreateTask(cfg)
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+	if task.Input.Count != 2 {
+		t.Errorf("Count = %d, want 2 (one per replayed prompt)", task.Input.Count)
+	}
+
+	snap := runTaskToCompletion(t, s, task.ID, stub)
+	if snap.Status != RunStatusCompleted {
+		t.Fatalf("Status: got %q, want completed (err=%q)", snap.Status, snap.ErrorMsg)
+	}
+	data, ok := snap.ModeResult.(*types.ReportData)
+	if !ok || data.ReplayOf != "origin" {
+		t.Fatalf("ModeResult = %#v, want ReportData replaying origin", snap.ModeResult)
+	}
+	origins := map[int]int{}
+	for _, r := range snap.Requests {
+		if r.OriginIndex == nil {
+			t.Fatalf("request %d has no origin index", r.Index)
+		}
+		origins[r.Index] = *r.OriginIndex
+	}
+	if origins[0] != 5 || origins[1] != 9 {
+		t.Errorf("origin indexes = %v, want 0→5, 1→9", origins)
+	}
+	var prompts []string
+	for _, body := range stub.Bodies() {
+		b, _ := json.Marshal(body["messages"])
+		prompts = append(prompts, string(b))
+	}
+	if joined := strings.Join(prompts, "\n"); !strings.Contains(joined, "replay-five") || !strings.Contains(joined, "replay-nine") {
+		t.Errorf("requests should use the replayed prompts, got %s", joined)
+	}
+
+	cfg.Input.ReplayFile = filepath.Join(t.TempDir(), "missing.jsonl")
+	if _, err := s.CreateTask(cfg); err == nil {
+		t.Error("expected error for a missing replay file")
+	}
+}
+
+func TestCreateTask_RejectsInvalidBurst(t *testing.T) {
+	s := newTestServer(t)
+	for name, mutate := range map[string]func(*types.Input){
+		"size without interval": func(in *types.Input) { in.BurstSize = 2 },
+		"interval without size": func(in *types.Input) { in.BurstInterval = time.Second },
+		"size exceeds count":    func(in *types.Input) { in.BurstSize = in.Count + 1; in.BurstInterval = time.Second },
+		"with adaptive":         func(in *types.Input) { in.BurstSize = 1; in.BurstInterval = time.Second; in.Adaptive = true },
+	} {
+		cfg := makeTaskConfig("bad-burst")
+		mutate(&cfg.Input)
+		if _, err := s.CreateTask(cfg); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestStartRun_SaveIO(t *testing.T) {
+	s := newTestServer(t)
+	stub := newOpenAIStub(t)
+	dir := t.TempDir()
+	if err := SetIOLog(dir, 1); err != nil {
+		t.Fatalf("SetIOLog: %v", err)
+	}
+	t.Cleanup(func() { _ = SetIOLog("", 0) })
+
+	cfg := makeTaskConfig("save-io")
+	cfg.Input.EndpointURL = stub.URL
+	cfg.Input.Count = 3
+	task, err := s.CreateTask(cfg)
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+	snap := runTaskToCompletion(t, s, task.ID, stub)
+
+	data, err := os.ReadFile(filepath.Join(dir, string(snap.RunID)+".jsonl"))
+	if err != nil {
+		t.Fatalf("read io file: %v", err)
+	}
+	indexes := map[int]bool{}
+	for _, raw := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		var record IORecord
+		if err := json.Unmarshal([]byte(raw), &record); err != nil {
+			t.Fatalf("io line is not JSON: %q: %v", raw, err)
+		}
+		if record.TaskID != task.ID || record.Prompt != "hello" || record.Response != "hi" {
+			t.Errorf("record = %+v, want prompt hello / response hi", record)
+		}
+		indexes[record.Index] = true
+	}
+	if len(indexes) != 3 {
+		t.Errorf("saved request indexes = %v, want all 3", indexes)
+	}
+}
+
+// TestStartRun_SaveIORecordsSentPrompt 落盘的 prompt 是实际发送的内容：占位符每次展开取值不同，不能按序号重新生成。
+func TestStartRun_SaveIORecordsSentPrompt(t *testing.T) {
+	s := newTestServer(t)
+	stub := newOpenAIStub(t)
+	dir := t.TempDir()
+	if err := SetIOLog(dir, 1); err != nil {
+		t.Fatalf("SetIOLog: %v", err)
+	}
+	t.Cleanup(func() { _ = SetIOLog("", 0) })
+
+	cfg := makeTaskConfig("save-io-placeholder")
+	cfg.Input.EndpointURL = stub.URL
+	cfg.Input.Count = 3
+	cfg.Input.PromptText = "q-{{index}}-{{uuid}}"
+	task, err := s.CreateTask(cfg)
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+	snap := runTaskToCompletion(t, s, task.ID, stub)
+
+	sent := map[string]bool{}
+	stub.mu.Lock()
+	for _, body := range stub.bodies {
+		messages, _ := body["messages"].([]any)
+		last, _ := messages[len(messages)-1].(map[string]any)
+		content, _ := last["content"].(string)
+		sent[content] = true
+	}
+	stub.mu.Unlock()
+
+	data, err := os.ReadFile(filepath.Join(dir, string(snap.RunID)+".jsonl"))
+	if err != nil {
+		t.Fatalf("read io file: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("saved %d records, want 3", len(lines))
+	}
+	for _, raw := range lines {
+		var record IORecord
+		if err := json.Unmarshal([]byte(raw), &record); err != nil {
+			t.Fatalf("io line is not JSON: %q: %v", raw, err)
+		}
+		if !sent[record.Prompt] || !strings.HasPrefix(record.Prompt, fmt.Sprintf("q-%d-", record.Index)) {
+			t.Errorf("record %d prompt %q was not sent (sent %v)", record.Index, record.Prompt, sent)
+		}
+	}
+}
+
+// TestStartRun_ShardsSendDisjointRequests 两个分片各自发送完整运行中不重叠的一段请求，合起来恰好覆盖全部序号。
+func TestStartRun_ShardsSendDisjointRequests(t *testing.T) {
+	t.Cleanup(func() { SetShard(Shard{}) })
+	s := newTestServer(t)
+	seen := map[string]int{}
+	for _, shard := range []Shard{{Index: 1, Total: 2}, {Index: 2, Total: 2}} {
+		SetShard(shard)
+		stub := newOpenAIStub(t)
+		cfg := makeTaskConfig("shard-" + shard.Suffix())
+		cfg.Input.EndpointURL = stub.URL
+		cfg.Input.Count = 5
+		cfg.Input.PromptText = "q-{{index}}"
+		task, err := s.CreateTask(cfg)
+		if err != nil {
+			t.Fatalf("CreateTask: %v", err)
+		}
+		runTaskToCompletion(t, s, task.ID, stub)
+
+		stub.mu.Lock()
+		for _, body := range stub.bodies {
+			messages, _ := body["messages"].([]any)
+			last, _ := messages[len(messages)-1].(map[string]any)
+			content, _ := last["content"].(string)
+			seen[content]++
+		}
+		stub.mu.Unlock()
+	}
+	for i := 0; i < 5; i++ {
+		if prompt := fmt.Sprintf("q-%d", i); seen[prompt] != 1 {
+			t.Errorf("prompt %q sent %d times across shards, want exactly once (seen %v)", prompt, seen[prompt], seen)
+		}
+	}
+	if len(seen) != 5 {
+		t.Errorf("shards sent %d distinct prompts, want 5: %v", len(seen), seen)
+	}
+}
+
+func TestStartRun_TokenTrace(t *testing.T) {
+	s := newTestServer(t)
+	stub := newOpenAIStub(t)
+	dir := t.TempDir()
+	if err := SetTokenTrace(dir, 10); err != nil {
+		t.Fatalf("SetTokenTrace: %v", err)
+	}
+	t.Cleanup(func() { _ = SetTokenTrace("", 0) })
+
+	cfg := makeTaskConfig("token-trace")
+	cfg.Input.EndpointURL = stub.URL
+	cfg.Input.Count = 2
+	cfg.Input.Stream = true
+	task, err := s.CreateTask(cfg)
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+	snap := runTaskToCompletion(t, s, task.ID, stub)
+
+	for index := 0; index < 2; index++ {
+		f, err := os.Open(tokenTracePath(dir, snap.RunID, index))
+		if err != nil {
+			t.Fatalf("open trace file of request %d: %v", index, err)
+		}
+		events, err := ParseTokenTrace(f)
+		f.Close()
+		if err != nil {
+			t.Fatalf("ParseTokenTrace: %v", err)
+		}
+		if len(events) != 1 || events[0].Chars != 2 || events[0].OffsetMs <= 0 {
+			t.Errorf("request %d events = %+v, want one chunk of 2 chars", index, events)
+		}
+	}
+}
+
+func TestWriteIORecord_SamplesWithRandSource(t *testing.T) {
+	dir := t.TempDir()
+	processIOLog.Store(&ioLog{dir: dir, sampleRate: 0.5, rng: clock.NewRand(3)})
+	t.Cleanup(func() { processIOLog.Store(nil) })
+
+	expected := clock.NewRand(3)
+	want := 0
+	for i := 0; i < 20; i++ {
+		if expected.Float64() < 0.5 {
+			want++
+		}
+		writeIORecord(IORecord{RunID: "run_sample", Index: i})
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "run_sample.jsonl"))
+	if err != nil && want > 0 {
+		t.Fatalf("read io file: %v", err)
+	}
+	if got := strings.Count(string(data), "\n"); got != want {
+		t.Errorf("sampled records = %d, want %d", got, want)
+	}
+}
+
+func TestSetIOLog_RejectsInvalidSampleRate(t *testing.T) {
+	for _, rate := range []float64{0, -0.1, 1.5} {
+		if err := SetIOLog(t.TempDir(), rate); err == nil {
+			t.Errorf("SetIOLog(rate=%g) should fail", rate)
+		}
+	}
+	if processIOLog.Load() != nil {
+		t.Error("invalid sample rate should not enable io log")
+	}
+}
+
+func TestStartRun_StreamBoth(t *testing.T) {
+	s := newTestServer(t)
+	stub := newOpenAIStub(t)
+	SetStreamBoth(true)
+	t.Cleanup(func() { SetStreamBoth(false) })
+
+	cfg := makeTaskConfig("stream-both")
+	cfg.Input.EndpointURL = stub.URL
+	cfg.Input.Count = 2
+	task, err := s.CreateTask(cfg)
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+
+	snap := runTaskToCompletion(t, s, task.ID, stub)
+	if snap.TotalReqs != 4 || snap.DoneReqs != 4 {
+		t.Errorf("TotalReqs/DoneReqs: got %d/%d, want 4/4", snap.TotalReqs, snap.DoneReqs)
+	}
+	result, ok := snap.ModeResult.(*types.StreamCompareResult)
+	if !ok || result.Stream == nil || result.NonStream == nil {
+		t.Fatalf("ModeResult: got %#v, want both stream phases", snap.ModeResult)
+	}
+	// 进程级开关不改写任务配置
+	saved, err := s.GetTask(task.ID)
+	if err != nil || saved.Input.CompareStream {
+		t.Errorf("task compare_stream = %v (err=%v), want unchanged false", saved.Input.CompareStream, err)
+	}
+}
+
+func TestStartRun_CompareHTTPVersion(t *testing.T) {
+	s := newTestServer(t)
+	stub := newOpenAIStub(t)
+
+	cfg := makeTaskConfig("compare-http-version")
+	cfg.Input.EndpointURL = stub.URL
+	cfg.Input.Count = 2
+	cfg.Input.CompareHTTPVersion = true
+	task, err := s.CreateTask(cfg)
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+
+	snap := runTaskToCompletion(t, s, task.ID, stub)
+	if snap.TotalReqs != 4 || snap.DoneReqs != 4 {
+		t.Errorf("TotalReqs/DoneReqs: got %d/%d, want 4/4", snap.TotalReqs, snap.DoneReqs)
+	}
+	result, ok := snap.ModeResult.(*types.HTTPVersionCompareResult)
+	if !ok || result.HTTP1 == nil || result.HTTP2 == nil {
+		t.Fatalf("ModeResult: got %#v, want both HTTP versions", snap.ModeResult)
+	}
+	if result.HTTP1.HTTPVersion != types.HTTPVersion1 || result.HTTP2.HTTPVersion != types.HTTPVersion2 {
+		t.Errorf("versions: got %q / %q, want 1.1 / 2", result.HTTP1.HTTPVersion, result.HTTP2.HTTPVersion)
+	}
+	// 明文 HTTP 无法通过 ALPN 协商 h2，两轮实际都是 HTTP/1.1
+	if result.HTTP1.HTTPProtocols["HTTP/1.1"] != 2 || result.HTTP2.HTTPProtocols["HTTP/1.1"] != 2 {
+		t.Errorf("protocols: got %v / %v, want HTTP/1.1 x2 each", result.HTTP1.HTTPProtocols, result.HTTP2.HTTPProtocols)
+	}
+}
+
+func TestStartRun_SelfStatsFlag(t *testing.T) {
+	SetSelfStats(true)
+	t.Cleanup(func() { SetSelfStats(false) })
+	s := newTestServer(t)
+	stub := newOpenAIStub(t)
+
+	cfg := makeTaskConfig("self-stats")
+	cfg.Input.EndpointURL = stub.URL
+	cfg.Input.Count = 3
+	task, err := s.CreateTask(cfg)
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+
+	snap := runTaskToCompletion(t, s, task.ID, stub)
+	if snap.SelfStats == nil {
+		t.Fatal("SelfStats: got nil, want self stats enabled by SetSelfStats")
+	}
+	if snap.SelfStats.BaselineGoroutines == 0 || snap.SelfStats.LeakSuspected() {
+		t.Errorf("SelfStats: got baseline %d residual %d", snap.SelfStats.BaselineGoroutines, snap.SelfStats.ResidualGoroutines)
+	}
+}
+
+func TestStartRun_SelfMonitorWritesSamples(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "selfstats.jsonl")
+	SetSelfMonitor(path)
+	t.Cleanup(func() { SetSelfMonitor("") })
+	s := newTestServer(t)
+	stub := newOpenAIStub(t)
+
+	cfg := makeTaskConfig("self-monitor")
+	cfg.Input.EndpointURL = stub.URL
+	task, err := s.CreateTask(cfg)
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+
+	snap := runTaskToCompletion(t, s, task.ID, stub)
+	if snap.SelfStats == nil || len(snap.SelfStats.Timeline) < 2 {
+		t.Fatalf("SelfStats: got %+v, want start and end samples", snap.SelfStats)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read samples: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != len(snap.SelfStats.Timeline) {
+		t.Fatalf("got %d lines, want %d:\n%s", len(lines), len(snap.SelfStats.Timeline), data)
+	}
+	var record SelfSampleRecord
+	if err := json.Unmarshal([]byte(lines[0]), &record); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if record.RunID != snap.RunID || record.Goroutines == 0 || record.At.IsZero() {
+		t.Errorf("record = %+v, want run %s", record, snap.RunID)
+	}
+}
+
+func TestCreateTask_HTTPVersionValidation(t *testing.T) {
+	s := newTestServer(t)
+	tests := []struct {
+		name   string
+		modify func(*types.Input)
+	}{
+		{"unknown version", func(in *types.Input) { in.HTTPVersion = "3" }},
+		{"compare with fixed version", func(in *types.Input) { in.CompareHTTPVersion, in.HTTPVersion = true, "2" }},
+		{"compare with compare_stream", func(in *types.Input) { in.CompareHTTPVersion, in.CompareStream = true, true }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := makeTaskConfig("http-version")
+			tt.modify(&cfg.Input)
+			if _, err := s.CreateTask(cfg); err == nil {
+				t.Error("expected validation error")
+			}
+		})
+	}
+
+	cfg := makeTaskConfig("http-version")
+	cfg.Input.HTTPVersion = " 1.1 "
+	task, err := s.CreateTask(cfg)
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+	if task.Input.HTTPVersion != types.HTTPVersion1 {
+		t.Errorf("HTTPVersion = %q, want normalized 1.1", task.Input.HTTPVersion)
+	}
+}
+
+func TestStartRun_ConnectRetries(t *testing.T) {
+	s := newTestServer(t)
+	stub := newOpenAIStub(t)
+	// 监听后立即关闭，得到一个拒绝连接的本地端口
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+	delay := connectRetryDelay
+	connectRetryDelay = time.Millisecond
+	t.Cleanup(func() { connectRetryDelay = delay })
+
+	cfg := makeTaskConfig("connect-retries")
+	cfg.Input.EndpointURL = "http://" + addr + "/v1/chat/completions"
+	cfg.Input.Count = 2
+	cfg.Input.ConnectRetries = 2
+	task, err := s.CreateTask(cfg)
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+
+	snap := runTaskToCompletion(t, s, task.ID, stub)
+	for _, r := range snap.Requests {
+		if r.Success || r.NetErrorKind != "connection_refused" || r.ConnectRetries != 2 {
+			t.Errorf("request %d: success=%v kind=%q retries=%d, want failed connection_refused after 2 retries", r.Index, r.Success, r.NetErrorKind, r.ConnectRetries)
+		}
+	}
+	report, ok := snap.ModeResult.(*types.ReportData)
+	if !ok || report.NetErrorKinds["connection_refused"] != 2 {
+		t.Errorf("ModeResult: got %#v, want net_error_kinds connection_refused=2", snap.ModeResult)
+	}
+}
+
+func TestStartRun_PeakConcurrentConnections(t *testing.T) {
+	s := newTestServer(t)
+	stub := newOpenAIStub(t)
+
+	// 三个请求全部到达后才一起响应，在途连接数峰值应为 3
+	const concurrency = 3
+	var wg sync.WaitGroup
+	wg.Add(concurrency)
+	inner := stub.Config.Handler
+	stub.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		wg.Done()
+		wg.Wait()
+		inner.ServeHTTP(w, r)
+	})
+
+	cfg := makeTaskConfig("peak-connections")
+	cfg.Input.EndpointURL = stub.URL
+	cfg.Input.Count = concurrency
+	cfg.Input.Concurrency = concurrency
+	task, err := s.CreateTask(cfg)
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+
+	snap := runTaskToCompletion(t, s, task.ID, stub)
+	data, ok := snap.ModeResult.(*types.ReportData)
+	if !ok {
+		t.Fatalf("ModeResult: got %T, want *types.ReportData", snap.ModeResult)
+	}
+	if data.PeakConcurrentConnections != concurrency {
+		t.Errorf("PeakConcurrentConnections = %d, want %d", data.PeakConcurrentConnections, concurrency)
+	}
+}
+
+// refusingClient 每次请求都以连接被拒绝失败，记录各次请求的发起时间。
+type refusingClient struct {
+	clock clock.Clock
+	mu    sync.Mutex
+	calls []time.Time
+}
+
+func (c *refusingClient) Request(ctx context.Context, systemPrompt, userPrompt string, stream bool) (*client.ResponseMetrics, error) {
+	c.mu.Lock()
+	c.calls = append(c.calls, c.clock.Now())
+	c.mu.Unlock()
+	return &client.ResponseMetrics{NetErrorKind: client.NetErrConnectionRefused, ErrorMessage: "connection refused"}, errors.New("connection refused")
+}
+
+func (c *refusingClient) RawRequest(ctx context.Context, rawBody string) (*client.ResponseMetrics, error) {
+	return c.Request(ctx, "", rawBody, false)
+}
+
+func (c *refusingClient) GetProtocol() string        { return "mock" }
+func (c *refusingClient) GetModel() string           { return "mock-model" }
+func (c *refusingClient) SetLogger(_ *logger.Logger) {}
+
+func TestRequestExecutor_ConnectRetryBackoff(t *testing.T) {
+	start := time.Unix(1000, 0)
+	fake := clock.NewFake(start)
+	c := &refusingClient{clock: fake}
+	executor := NewRequestExecutor(c)
+	executor.SetClock(fake)
+
+	input := makeTaskConfig("backoff").Input
+	input.ConnectRetries = 3
+	input.PromptSource, _ = prompt.LoadPrompts("hello")
+	done := make(chan RequestResult, 1)
+	go func() { done <- executor.Execute(context.Background(), RequestJob{Input: input}) }()
+
+	// 退避间隔依次为 connectRetryDelay 的 1、2、4 倍
+	for _, d := range []time.Duration{connectRetryDelay, 2 * connectRetryDelay, 4 * connectRetryDelay} {
+		fake.BlockUntil(1)
+		fake.Advance(d)
+	}
+	result := <-done
+	if result.ConnectRetries != 3 {
+		t.Errorf("ConnectRetries = %d, want 3", result.ConnectRetries)
+	}
+	var offsets []time.Duration
+	for _, at := range c.calls {
+		offsets = append(offsets, at.Sub(start))
+	}
+	want := []time.Duration{0, connectRetryDelay, 3 * connectRetryDelay, 7 * connectRetryDelay}
+	if !slices.Equal(offsets, want) {
+		t.Errorf("request offsets = %v, want %v", offsets, want)
+	}
+	if !result.Metrics.StartedAt.Equal(start.Add(7 * connectRetryDelay)) {
+		t.Errorf("StartedAt = %v, want last attempt time", result.Metrics.StartedAt)
+	}
+}
+
+func TestRequestExecutor_QueueWait(t *testing.T) {
+	start := time.Unix(1000, 0)
+	fake := clock.NewFake(start)
+	executor := NewRequestExecutor(&refusingClient{clock: fake})
+	executor.SetClock(fake)
+
+	input := makeTaskConfig("queue-wait").Input
+	input.ConnectRetries = 1
+	input.PromptSource, _ = prompt.LoadPrompts("hello")
+	done := make(chan RequestResult, 1)
+	go func() {
+		done <- executor.Execute(context.Background(), RequestJob{Input: input, DispatchedAt: start.Add(-250 * time.Millisecond)})
+	}()
+	fake.BlockUntil(1)
+	fake.Advance(connectRetryDelay)
+
+	// 连接重试的退避不算排队时间
+	if result := <-done; result.Metrics.QueueWaitTime != 250*time.Millisecond {
+		t.Errorf("QueueWaitTime = %v, want 250ms", result.Metrics.QueueWaitTime)
+	}
+	input.ConnectRetries = 0
+	if result := executor.Execute(context.Background(), RequestJob{Input: input}); result.Metrics.QueueWaitTime != 0 {
+		t.Errorf("QueueWaitTime without DispatchedAt = %v, want 0", result.Metrics.QueueWaitTime)
+	}
+}
+
+func TestStartRun_ConsistencyCheck(t *testing.T) {
+	s := newTestServer(t)
+	stub := newOpenAIStub(t)
+	SetConsistencyCheck(true)
+	t.Cleanup(func() { SetConsistencyCheck(false) })
+
+	cfg := makeTaskConfig("consistency")
+	cfg.Input.EndpointURL = stub.URL
+	cfg.Input.Count = 3
+	cfg.Input.Stream = true
+	cfg.Input.PromptText = "hello {{index}}"
+	task, err := s.CreateTask(cfg)
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+
+	snap := runTaskToCompletion(t, s, task.ID, stub)
+	bodies := stub.Bodies()
+	if len(bodies) != 3 {
+		t.Fatalf("requests: got %d, want 3", len(bodies))
+	}
+	for _, body := range bodies {
+		messages, _ := body["messages"].([]any)
+		last, _ := messages[len(messages)-1].(map[string]any)
+		if body["stream"] == true || body["temperature"] != 0.0 || last["content"] != "hello 0" {
+			t.Errorf("body = %v, want non-stream, temperature 0 and the first prompt", body)
+		}
+	}
+	report, ok := snap.ModeResult.(*types.ReportData)
+	if !ok || report.DistinctOutputs != 1 || report.ConsistencyRate != 100 {
+		t.Errorf("ModeResult: got %#v, want one distinct output at 100%%", snap.ModeResult)
+	}
+}
+
+func TestStartRun_FirstRequestMarkedCold(t *testing.T) {
+	s := newTestServer(t)
+	stub := newOpenAIStub(t)
+
+	cfg := makeTaskConfig("cold")
+	cfg.Input.EndpointURL = stub.URL
+	cfg.Input.Count = 3
+	cfg.Input.Concurrency = 1
+	task, err := s.CreateTask(cfg)
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+
+	snap := runTaskToCompletion(t, s, task.ID, stub)
+	cold := 0
+	for _, r := range snap.Requests {
+		if r.Cold {
+			cold++
+		}
+		if r.StartedAt.IsZero() {
+			t.Errorf("request %d has no StartedAt", r.Index)
+		}
+	}
+	if cold != 1 {
+		t.Errorf("cold requests = %d, want 1", cold)
+	}
+	report, ok := snap.ModeResult.(*types.ReportData)
+	if !ok || report.FirstRequest == nil || report.FirstRequest.WarmRequests != 2 {
+		t.Errorf("ModeResult: got %#v, want FirstRequest with 2 warm requests", snap.ModeResult)
+	}
+}
+
+func TestStartRun_PromptCommand(t *testing.T) {
+	s := newTestServer(t)
+	stub := newOpenAIStub(t)
+	SetPromptCommand(`echo "prompt-$AIT_PROMPT_INDEX"`, time.Second, 2)
+	t.Cleanup(func() { SetPromptCommand("", 0, 0) })
+
+	cfg := makeTaskConfig("prompt-command")
+	cfg.Input.EndpointURL = stub.URL
+	cfg.Input.Count = 3
+	task, err := s.CreateTask(cfg)
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+
+	snap := runTaskToCompletion(t, s, task.ID, stub)
+	stub.mu.Lock()
+	var prompts []string
+	for _, body := range stub.bodies {
+		messages, _ := body["messages"].([]any)
+		last, _ := messages[len(messages)-1].(map[string]any)
+		content, _ := last["content"].(string)
+		prompts = append(prompts, content)
+	}
+	stub.mu.Unlock()
+	slices.Sort(prompts)
+	if want := []string{"prompt-0", "prompt-1", "prompt-2"}; !slices.Equal(prompts, want) {
+		t.Errorf("prompts: got %q, want %q", prompts, want)
+	}
+	if data, ok := snap.ModeResult.(*types.ReportData); !ok || data.SuccessRate != 100 {
+		t.Errorf("ModeResult: %+v", snap.ModeResult)
+	}
+}
+
+// TestStartRun_PromptCommandSaveIO 开启 --save-io-dir 时落盘的 prompt 取自命令输出缓存，每个序号只执行一次命令。
+func TestStartRun_PromptCommandSaveIO(t *testing.T) {
+	s := newTestServer(t)
+	stub := newOpenAIStub(t)
+	dir := t.TempDir()
+	if err := SetIOLog(dir, 1); err != nil {
+		t.Fatalf("SetIOLog: %v", err)
+	}
+	t.Cleanup(func() { _ = SetIOLog("", 0) })
+	counter := filepath.Join(t.TempDir(), "runs")
+	SetPromptCommand(`echo x >> `+counter+`; echo "prompt-$AIT_PROMPT_INDEX"`, time.Second, 2)
+	t.Cleanup(func() { SetPromptCommand("", 0, 0) })
+
+	cfg := makeTaskConfig("prompt-command-io")
+	cfg.Input.EndpointURL = stub.URL
+	cfg.Input.Count = 3
+	task, err := s.CreateTask(cfg)
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+	snap := runTaskToCompletion(t, s, task.ID, stub)
+
+	data, err := os.ReadFile(filepath.Join(dir, string(snap.RunID)+".jsonl"))
+	if err != nil {
+		t.Fatalf("read io file: %v", err)
+	}
+	for _, raw := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		var record IORecord
+		if err := json.Unmarshal([]byte(raw), &record); err != nil {
+			t.Fatalf("io line is not JSON: %q: %v", raw, err)
+		}
+		if want := fmt.Sprintf("prompt-%d", record.Index); record.Prompt != want {
+			t.Errorf("record %d prompt = %q, want %q", record.Index, record.Prompt, want)
+		}
+	}
+	runs, _ := os.ReadFile(counter)
+	if got := strings.Count(string(runs), "x"); got != 3 {
+		t.Errorf("command ran %d times, want 3", got)
+	}
+}
+
+func TestStartRun_StdinPrompts(t *testing.T) {
+	s := newTestServer(t)
+	stub := newOpenAIStub(t)
+	source, err := prompt.LoadPromptsFromLines(strings.NewReader("line-a\n\nline-b\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	SetStdinPrompts(source)
+	t.Cleanup(func() { SetStdinPrompts(nil) })
+
+	cfg := makeTaskConfig("stdin-prompts")
+	cfg.Input.EndpointURL = stub.URL
+	cfg.Input.Count = 4
+	task, err := s.CreateTask(cfg)
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+
+	runTaskToCompletion(t, s, task.ID, stub)
+	stub.mu.Lock()
+	seen := map[string]bool{}
+	for _, body := range stub.bodies {
+		messages, _ := body["messages"].([]any)
+		last, _ := messages[len(messages)-1].(map[string]any)
+		content, _ := last["content"].(string)
+		seen[content] = true
+	}
+	stub.mu.Unlock()
+	if len(seen) != 2 || !seen["line-a"] || !seen["line-b"] {
+		t.Errorf("prompts sent: %v, want only the stdin lines", seen)
+	}
+}
+
+func TestStartRun_PromptCommandFailureFailsRequests(t *testing.T) {
+	s := newTestServer(t)
+	stub := newOpenAIStub(t)
+	SetPromptCommand("echo boom >&2; exit 3", time.Second, 0)
+	t.Cleanup(func() { SetPromptCommand("", 0, 0) })
+
+	cfg := makeTaskConfig("prompt-command-fail")
+	cfg.Input.EndpointURL = stub.URL
+	cfg.Input.Count = 2
+	task, err := s.CreateTask(cfg)
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+
+	snap := runTaskToCompletion(t, s, task.ID, stub)
+	stub.mu.Lock()
+	sent := len(stub.bodies)
+	stub.mu.Unlock()
+	if sent != 0 {
+		t.Errorf("requests sent: got %d, want 0", sent)
+	}
+	data, ok := snap.ModeResult.(*types.ReportData)
+	if !ok || data.SuccessRate != 0 || data.TotalRequests != 2 {
+		t.Fatalf("ModeResult: %+v", snap.ModeResult)
+	}
+	if len(data.Errors) == 0 || !strings.Contains(data.Errors[0].Sample, "boom") {
+		t.Errorf("Errors: %+v", data.Errors)
+	}
+}
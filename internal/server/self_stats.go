@@ -0,0 +1,70 @@
+package server
+
+import (
+	"encoding/json"
+	"os"
+	"sync"
+	"sync/atomic"
+
+	"github.com/yinxulai/ait/internal/server/types"
+)
+
+var (
+	processSelfStats   atomic.Bool
+	processSelfMonitor atomic.Pointer[selfMonitorLog]
+)
+
+// SetSelfStats 设置本进程是否对每次运行开启自监控，通常在启动时由 --self-stats 设置；
+// 开启后等同于为任务打开 self_stats，运行结束时报告资源占用与残留 goroutine 数。
+func SetSelfStats(enabled bool) {
+	processSelfStats.Store(enabled)
+}
+
+// SetSelfMonitor 设置本进程的长稳自监控，通常在启动时由 --self-monitor 设置：开启 self_stats，
+// 并把每次运行的自监控时间线采样（每分钟一次）追加到 path（JSONL）；path 为空时关闭。
+func SetSelfMonitor(path string) {
+	if path == "" {
+		processSelfMonitor.Store(nil)
+		return
+	}
+	processSelfMonitor.Store(&selfMonitorLog{path: path})
+}
+
+// selfStatsEnabled 返回进程级 --self-stats / --self-monitor 是否开启。
+func selfStatsEnabled() bool {
+	return processSelfStats.Load() || processSelfMonitor.Load() != nil
+}
+
+// SelfSampleRecord 自监控落盘文件（JSONL）中的一行：某次运行的一次时间线采样。
+type SelfSampleRecord struct {
+	RunID RunID `json:"run_id"`
+	types.SelfSample
+}
+
+// selfMonitorLog 把自监控时间线采样追加到 path，写入串行化避免多个运行的行内容交错。
+type selfMonitorLog struct {
+	mu   sync.Mutex
+	path string
+}
+
+// selfSampleHook 返回把 runID 的时间线采样写入 --self-monitor 文件的回调，未开启时返回 nil；写入失败直接忽略，不影响运行。
+func selfSampleHook(runID RunID) func(types.SelfSample) {
+	l := processSelfMonitor.Load()
+	if l == nil {
+		return nil
+	}
+	return func(sample types.SelfSample) {
+		data, err := json.Marshal(SelfSampleRecord{RunID: runID, SelfSample: sample})
+		if err != nil {
+			return
+		}
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		f, err := os.OpenFile(l.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+		if err != nil {
+			return
+		}
+		defer f.Close()
+		_, _ = f.Write(append(data, '\n'))
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/yinxulai/ait/internal/server/config"
@@ -33,6 +34,14 @@ type Server interface {
 	// StopRun 请求停止指定运行（软停止，等待当前批次完成）。
 	StopRun(runID RunID) error
 
+	// AdjustRunConcurrency 把运行中的标准模式运行的并发上限增减 delta（最小为 1），返回调整后的上限。
+	// 调低时正在执行的请求照常完成，活跃请求数降到新上限以下后才派发新请求。
+	AdjustRunConcurrency(runID RunID, delta int) (int, error)
+
+	// SetRunPaused 暂停或恢复运行中的标准模式运行。暂停时不再派发新请求，在途请求照常完成，
+	// 已收集的数据与连接保留；暂停时间不计入吞吐统计。
+	SetRunPaused(runID RunID, paused bool) error
+
 	// GetRunState 返回指定运行的当前状态快照（线程安全的深度拷贝）。
 	GetRunState(runID RunID) (*RunState, bool)
 
@@ -62,6 +71,15 @@ type Server interface {
 	// Context 返回 Server 的生命周期 Context，用于子操作。
 	// 当 Server 关闭时，此 Context 会被取消。
 	Context() context.Context
+
+	// Regressed 返回本进程内是否有运行相对基线（Input.BaselineDir）出现回归。
+	Regressed() bool
+
+	// SLAFailed 返回本进程内是否有运行未达到 SLA（Input.SLA 或 --sla）。
+	SLAFailed() bool
+
+	// AbortedEarly 返回本进程内是否有运行因错误率熔断（Input.AbortOnErrorRate 或 --abort-on-error-rate）提前终止。
+	AbortedEarly() bool
 }
 
 // serverImpl 是 Server 的具体实现。
@@ -75,6 +93,10 @@ type serverImpl struct {
 	scheduler    *RunScheduler
 	rulesManager *integrity.RulesManager
 	rulesStatus  *integrity.RulesStatus
+	version      string
+	regressed    atomic.Bool // 是否有运行相对基线出现回归
+	slaFailed    atomic.Bool // 是否有运行未达到 SLA
+	abortedEarly atomic.Bool // 是否有运行因错误率熔断提前终止
 
 	// 生命周期 Context，用于优雅关闭
 	ctx    context.Context
@@ -123,6 +145,7 @@ func NewWithVersion(version string) (Server, error) {
 		bus:          newEventBus(),
 		activeRuns:   make(map[RunID]*activeRun),
 		rulesManager: rulesManager,
+		version:      version,
 		ctx:          ctx,
 		cancel:       cancel,
 	}
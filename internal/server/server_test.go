@@ -1056,3 +1056,82 @@ func TestSubscribeRunEvents_ChannelClosedAfterCloseRunEvents(t *testing.T) {
 		t.Fatal("timeout: channel not closed after closeRunEvents")
 	}
 }
+
+// ── shard ─────────────────────────────────────────────────────────────────────
+
+func TestParseShard(t *testing.T) {
+	if sh, err := ParseShard(" 2/4 "); err != nil || sh != (Shard{Index: 2, Total: 4}) {
+		t.Fatalf("ParseShard(2/4) = %+v, %v", sh, err)
+	}
+	if sh, err := ParseShard(""); err != nil || sh.Enabled() {
+		t.Fatalf("empty shard should be disabled, got %+v, %v", sh, err)
+	}
+	for _, bad := range []string{"5/4", "0/4", "1/0", "1/-2", "2", "a/4", "1/b"} {
+		if _, err := ParseShard(bad); err == nil {
+			t.Errorf("ParseShard(%q) expected error", bad)
+		}
+	}
+}
+
+func TestShard_Count(t *testing.T) {
+	// 10 个请求分 4 片：3 3 2 2，余数分给前两片
+	want := []int{3, 3, 2, 2}
+	sum := 0
+	for i, w := range want {
+		got := Shard{Index: i + 1, Total: 4}.Count(10)
+		if got != w {
+			t.Errorf("shard %d/4 of 10 = %d, want %d", i+1, got, w)
+		}
+		sum += got
+	}
+	if sum != 10 {
+		t.Errorf("shards should cover all requests, got %d", sum)
+	}
+	if got := (Shard{Index: 3, Total: 3}).Count(2); got != 0 {
+		t.Errorf("shard 3/3 of 2 = %d, want 0", got)
+	}
+	// 各分片的起始序号首尾相接：0 3 6 8
+	for i, w := range []int{0, 3, 6, 8} {
+		if got := (Shard{Index: i + 1, Total: 4}).Start(10); got != w {
+			t.Errorf("shard %d/4 of 10 starts at %d, want %d", i+1, got, w)
+		}
+	}
+	if got := (Shard{}).Count(7); got != 7 {
+		t.Errorf("disabled shard should keep count, got %d", got)
+	}
+	if s := (Shard{Index: 1, Total: 4}); s.String() != "1/4" || s.Suffix() != "shard-1-of-4" {
+		t.Errorf("String/Suffix = %q/%q", s.String(), s.Suffix())
+	}
+}
+
+func TestStartRun_Shard(t *testing.T) {
+	SetShard(Shard{Index: 1, Total: 4})
+	defer SetShard(Shard{})
+
+	s := newTestServer(t)
+	cfg := makeTaskConfig("shard-task")
+	cfg.Input.Count = 10
+	task, _ := s.CreateTask(cfg)
+	runID, err := s.StartRun(task.ID)
+	if err != nil {
+		t.Fatalf("StartRun: %v", err)
+	}
+	if !strings.HasSuffix(string(runID), "_shard-1-of-4") {
+		t.Errorf("runID %q should carry the shard suffix", runID)
+	}
+	state, ok := s.GetRunState(runID)
+	if !ok || state.TotalReqs != 3 {
+		t.Errorf("TotalReqs = %d, want 3 (first shard of 10 over 4)", state.TotalReqs)
+	}
+
+	SetShard(Shard{Index: 4, Total: 4})
+	cfg.Name = "tiny"
+	cfg.Input.Count = 3
+	tiny, err := s.CreateTask(cfg)
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+	if _, err := s.StartRun(tiny.ID); err == nil || !strings.Contains(err.Error(), "no requests") {
+		t.Errorf("expected no-requests error for an empty shard, got %v", err)
+	}
+}
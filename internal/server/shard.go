@@ -0,0 +1,125 @@
+package server
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// Shard 多进程分片压测时本进程负责的分片：共 Total 片中的第 Index 片（从 1 开始）。
+// 零值表示不分片。
+type Shard struct {
+	Index int
+	Total int
+}
+
+// ParseShard 解析 "i/n" 形式的分片参数，要求 n >= 1 且 1 <= i <= n；空字符串返回零值。
+func ParseShard(s string) (Shard, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return Shard{}, nil
+	}
+	before, after, ok := strings.Cut(s, "/")
+	if !ok {
+		return Shard{}, fmt.Errorf("invalid shard %q, expected i/n", s)
+	}
+	index, err := strconv.Atoi(strings.TrimSpace(before))
+	if err != nil {
+		return Shard{}, fmt.Errorf("invalid shard index %q", before)
+	}
+	total, err := strconv.Atoi(strings.TrimSpace(after))
+	if err != nil {
+		return Shard{}, fmt.Errorf("invalid shard total %q", after)
+	}
+	if total <= 0 {
+		return Shard{}, fmt.Errorf("shard total must be positive, got %d", total)
+	}
+	if index < 1 || index > total {
+		return Shard{}, fmt.Errorf("shard index must be between 1 and %d, got %d", total, index)
+	}
+	return Shard{Index: index, Total: total}, nil
+}
+
+// Enabled 返回是否开启了分片。
+func (sh Shard) Enabled() bool {
+	return sh.Total > 0
+}
+
+// Count 返回总请求数 total 中本分片负责的请求数：均分后余数依次分给前几片。
+// 未开启分片时原样返回 total。
+func (sh Shard) Count(total int) int {
+	if !sh.Enabled() || total <= 0 {
+		return total
+	}
+	count := total / sh.Total
+	if sh.Index <= total%sh.Total {
+		count++
+	}
+	return count
+}
+
+// Start 返回总请求数 total 中本分片负责的第一个请求序号：本分片负责 [Start(total), Start(total)+Count(total))，
+// 各分片的区间首尾相接、互不重叠。未开启分片时为 0。
+func (sh Shard) Start(total int) int {
+	if !sh.Enabled() || total <= 0 {
+		return 0
+	}
+	before := sh.Index - 1
+	return before*(total/sh.Total) + min(before, total%sh.Total)
+}
+
+// String 返回 "i/n" 形式；未开启分片时为空。
+func (sh Shard) String() string {
+	if !sh.Enabled() {
+		return ""
+	}
+	return fmt.Sprintf("%d/%d", sh.Index, sh.Total)
+}
+
+// Suffix 返回用于文件名 / 运行 ID 的后缀，如 "shard-1-of-4"；未开启分片时为空。
+func (sh Shard) Suffix() string {
+	if !sh.Enabled() {
+		return ""
+	}
+	return fmt.Sprintf("shard-%d-of-%d", sh.Index, sh.Total)
+}
+
+// shardLayout 分片运行中请求序号的编排：完整运行的每一轮（A/B 对比、长度扫描等多轮运行的各轮）占 fullCount 个序号，
+// 本分片负责每轮中从 start 开始的 count 个。零值表示不分片，序号原样使用。
+type shardLayout struct {
+	start     int
+	count     int
+	fullCount int
+}
+
+// newShardLayout 返回分片 sh 在每轮 fullCount 个请求的运行中的序号编排；未开启分片时为零值。
+func newShardLayout(sh Shard, fullCount int) shardLayout {
+	if !sh.Enabled() {
+		return shardLayout{}
+	}
+	return shardLayout{start: sh.Start(fullCount), count: sh.Count(fullCount), fullCount: fullCount}
+}
+
+// index 把本分片内连续编排的请求序号映射为完整运行中的序号，使各分片发送的 prompt 与 {{index}} 互不重复。
+func (l shardLayout) index(local int) int {
+	if l.count <= 0 {
+		return local
+	}
+	return local/l.count*l.fullCount + l.start + local%l.count
+}
+
+var processShard atomic.Pointer[Shard]
+
+// SetShard 设置本进程的分片，通常在启动时由 --shard 参数设置一次；此后标准运行只执行本分片的请求。
+func SetShard(sh Shard) {
+	processShard.Store(&sh)
+}
+
+// CurrentShard 返回本进程的分片；未设置时为零值（不分片）。
+func CurrentShard() Shard {
+	if sh := processShard.Load(); sh != nil {
+		return *sh
+	}
+	return Shard{}
+}
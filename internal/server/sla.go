@@ -0,0 +1,23 @@
+package server
+
+import (
+	"slices"
+	"sync/atomic"
+)
+
+var processSLA atomic.Pointer[[]string]
+
+// SetSLA 设置本进程的 SLA 表达式，通常在启动时由 --sla 参数设置一次（调用方负责校验）；
+// 此后每次标准运行在任务自身的 Input.SLA 之外额外评估这些 SLA。
+func SetSLA(exprs []string) {
+	exprs = slices.Clone(exprs)
+	processSLA.Store(&exprs)
+}
+
+// CurrentSLA 返回本进程的 SLA 表达式；未设置时为空。
+func CurrentSLA() []string {
+	if exprs := processSLA.Load(); exprs != nil {
+		return *exprs
+	}
+	return nil
+}
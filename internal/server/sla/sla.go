@@ -0,0 +1,163 @@
+// Package sla 解析并评估请求级 SLA 表达式。
+//
+// 表达式由逗号分隔的若干项组成，例如 "ttft<800ms,total<10s,p=95"：
+//   - 指标条件：ttft / total 后接 < 或 <=，再接 Go duration（如 800ms、10s）
+//   - 目标分位：p=N，要求至少 N% 的请求满足全部指标条件；省略时为 100
+package sla
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yinxulai/ait/internal/server/types"
+)
+
+// 可约束的指标
+const (
+	MetricTTFT  = "ttft"  // 首 token 耗时（非流式请求等于总耗时）
+	MetricTotal = "total" // 请求总耗时
+)
+
+// defaultPercentile 未写 p= 时的目标达标率（%）
+const defaultPercentile = 100
+
+// Condition 单个指标条件，如 ttft<800ms。
+type Condition struct {
+	Metric    string
+	Inclusive bool // true 表示 <=，false 表示 <
+	Threshold time.Duration
+}
+
+// Rule 一条解析后的 SLA。
+type Rule struct {
+	Expr       string // 表达式原文（去掉首尾空白）
+	Conditions []Condition
+	Percentile float64 // 目标达标率（%），取值 (0, 100]
+}
+
+// Sample 参与评估的单个请求。
+type Sample struct {
+	Success bool
+	TTFT    time.Duration
+	Total   time.Duration
+}
+
+// Parse 解析一条 SLA 表达式；语法错误时返回指出具体出错项的错误。
+func Parse(expr string) (Rule, error) {
+	expr = strings.TrimSpace(expr)
+	if expr == "" {
+		return Rule{}, fmt.Errorf("empty sla expression")
+	}
+	rule := Rule{Expr: expr, Percentile: defaultPercentile}
+	seen := make(map[string]bool)
+	for i, term := range strings.Split(expr, ",") {
+		term = strings.TrimSpace(term)
+		if term == "" {
+			return Rule{}, fmt.Errorf("sla %q: term %d is empty", expr, i+1)
+		}
+		if name, value, ok := strings.Cut(term, "="); ok && !strings.HasSuffix(name, "<") {
+			if strings.ToLower(strings.TrimSpace(name)) != "p" {
+				return Rule{}, fmt.Errorf("sla %q: unknown setting %q, only p=N is supported", expr, term)
+			}
+			if seen["p"] {
+				return Rule{}, fmt.Errorf("sla %q: p is set more than once", expr)
+			}
+			seen["p"] = true
+			p, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			if err != nil || p <= 0 || p > 100 {
+				return Rule{}, fmt.Errorf("sla %q: invalid percentile %q, must be in (0, 100]", expr, strings.TrimSpace(value))
+			}
+			rule.Percentile = p
+			continue
+		}
+		cond, err := parseCondition(term)
+		if err != nil {
+			return Rule{}, fmt.Errorf("sla %q: %w", expr, err)
+		}
+		if seen[cond.Metric] {
+			return Rule{}, fmt.Errorf("sla %q: %s is constrained more than once", expr, cond.Metric)
+		}
+		seen[cond.Metric] = true
+		rule.Conditions = append(rule.Conditions, cond)
+	}
+	if len(rule.Conditions) == 0 {
+		return Rule{}, fmt.Errorf("sla %q: no metric condition, expected e.g. ttft<800ms", expr)
+	}
+	return rule, nil
+}
+
+// parseCondition 解析 metric<duration / metric<=duration 形式的一项。
+func parseCondition(term string) (Condition, error) {
+	idx := strings.Index(term, "<")
+	if idx < 0 {
+		return Condition{}, fmt.Errorf("term %q has no operator, expected < or <=", term)
+	}
+	cond := Condition{Metric: strings.ToLower(strings.TrimSpace(term[:idx]))}
+	value := term[idx+1:]
+	if strings.HasPrefix(value, "=") {
+		cond.Inclusive = true
+		value = value[1:]
+	}
+	switch cond.Metric {
+	case MetricTTFT, MetricTotal:
+	case "":
+		return Condition{}, fmt.Errorf("term %q has no metric, expected %s or %s", term, MetricTTFT, MetricTotal)
+	default:
+		return Condition{}, fmt.Errorf("unknown metric %q, expected %s or %s", cond.Metric, MetricTTFT, MetricTotal)
+	}
+	value = strings.TrimSpace(value)
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return Condition{}, fmt.Errorf("invalid duration %q in %q, expected e.g. 800ms or 10s", value, term)
+	}
+	if d <= 0 {
+		return Condition{}, fmt.Errorf("threshold in %q must be positive", term)
+	}
+	cond.Threshold = d
+	return cond, nil
+}
+
+// Met 返回单个请求是否满足全部指标条件；失败请求一律不达标。
+func (r Rule) Met(s Sample) bool {
+	if !s.Success {
+		return false
+	}
+	for _, c := range r.Conditions {
+		v := s.TTFT
+		if c.Metric == MetricTotal {
+			v = s.Total
+		}
+		if v > c.Threshold || (v == c.Threshold && !c.Inclusive) {
+			return false
+		}
+	}
+	return true
+}
+
+// Evaluate 统计 samples 中满足本条 SLA 的请求占比，并判断是否达到目标分位。
+func (r Rule) Evaluate(samples []Sample) types.SLAResult {
+	result := types.SLAResult{Expr: r.Expr, Target: r.Percentile, Total: len(samples)}
+	for _, s := range samples {
+		if r.Met(s) {
+			result.Compliant++
+		}
+	}
+	if result.Total > 0 {
+		result.Rate = float64(result.Compliant) / float64(result.Total) * 100
+	}
+	// 用整数计数比较，避免 95% 这类边界因浮点误差误判
+	result.Met = result.Total > 0 && float64(result.Compliant)*100 >= result.Target*float64(result.Total)
+	return result
+}
+
+// AnyFailed 返回 results 中是否有未达标的 SLA。
+func AnyFailed(results []types.SLAResult) bool {
+	for _, r := range results {
+		if !r.Met {
+			return true
+		}
+	}
+	return false
+}
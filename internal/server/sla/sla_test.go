@@ -0,0 +1,86 @@
+package sla
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	rule, err := Parse(" ttft<800ms, TOTAL<=10s ,p=95 ")
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if rule.Expr != "ttft<800ms, TOTAL<=10s ,p=95" || rule.Percentile != 95 {
+		t.Errorf("rule = %+v", rule)
+	}
+	want := []Condition{
+		{Metric: MetricTTFT, Threshold: 800 * time.Millisecond},
+		{Metric: MetricTotal, Inclusive: true, Threshold: 10 * time.Second},
+	}
+	if len(rule.Conditions) != len(want) || rule.Conditions[0] != want[0] || rule.Conditions[1] != want[1] {
+		t.Errorf("conditions = %+v, want %+v", rule.Conditions, want)
+	}
+
+	rule, err = Parse("total<2s")
+	if err != nil || rule.Percentile != 100 {
+		t.Errorf("percentile should default to 100: %+v, %v", rule, err)
+	}
+}
+
+func TestParse_SyntaxErrors(t *testing.T) {
+	cases := []struct {
+		expr string
+		want string
+	}{
+		{"", "empty sla expression"},
+		{"ttft<800ms,,p=95", "term 2 is empty"},
+		{"ttft800ms", `term "ttft800ms" has no operator`},
+		{"<800ms", `term "<800ms" has no metric`},
+		{"tpot<50ms", `unknown metric "tpot"`},
+		{"ttft<fast", `invalid duration "fast"`},
+		{"ttft<0s", "must be positive"},
+		{"ttft<1s,ttft<2s", "ttft is constrained more than once"},
+		{"ttft<1s,p=0", `invalid percentile "0"`},
+		{"ttft<1s,p=101", `invalid percentile "101"`},
+		{"ttft<1s,p=95,p=99", "p is set more than once"},
+		{"ttft<1s,q=95", `unknown setting "q=95"`},
+		{"p=95", "no metric condition"},
+	}
+	for _, tc := range cases {
+		_, err := Parse(tc.expr)
+		if err == nil {
+			t.Errorf("Parse(%q): expected error", tc.expr)
+			continue
+		}
+		if !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("Parse(%q) error = %q, want it to contain %q", tc.expr, err, tc.want)
+		}
+	}
+}
+
+func TestRule_Evaluate(t *testing.T) {
+	rule, err := Parse("ttft<800ms,total<=10s,p=75")
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	samples := []Sample{
+		{Success: true, TTFT: 500 * time.Millisecond, Total: 10 * time.Second}, // total 取等号达标
+		{Success: true, TTFT: 799 * time.Millisecond, Total: 3 * time.Second},
+		{Success: true, TTFT: 800 * time.Millisecond, Total: 3 * time.Second}, // ttft 严格小于，不达标
+		{Success: false, TTFT: time.Millisecond, Total: time.Millisecond},     // 失败请求不达标
+	}
+	got := rule.Evaluate(samples)
+	if got.Compliant != 2 || got.Total != 4 || got.Rate != 50 || got.Met {
+		t.Errorf("Evaluate = %+v, want 2/4 not met", got)
+	}
+
+	got = rule.Evaluate(append(samples[:3:3], samples[1]))
+	if got.Compliant != 3 || got.Rate != 75 || !got.Met {
+		t.Errorf("Evaluate at the target boundary = %+v, want met", got)
+	}
+
+	if got := rule.Evaluate(nil); got.Met {
+		t.Errorf("no samples must not count as met: %+v", got)
+	}
+}
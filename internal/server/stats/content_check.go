@@ -0,0 +1,77 @@
+package stats
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// 响应内容异常类型
+const (
+	ContentInvalidUTF8 = "invalid_utf8"      // 不是合法的 UTF-8
+	ContentReplacement = "replacement_chars" // 替换字符 U+FFFD 占比过高（编码错乱）
+	ContentRepetition  = "repetition"        // 重复片段占比过高（死循环复读）
+	ContentWhitespace  = "whitespace"        // 空白字符占比过高
+)
+
+// 判定参数：过短的回答不做复读 / 空白判定，避免误报
+const (
+	contentNGram         = 8 // 复读检测使用的字符 n-gram 长度
+	contentMinRepetition = 64
+	contentMinWhitespace = 32
+	maxReplacementRatio  = 0.05
+	maxRepetitionRatio   = 0.5
+	maxWhitespaceRatio   = 0.5
+)
+
+// CheckContent 用启发式规则检查一条模型输出是否疑似乱码或死循环：
+// UTF-8 合法性、替换字符占比、重复 n-gram 占比和空白占比。
+// 正常时返回空字符串，否则返回命中的异常类型（Content* 常量）；空文本视为正常。
+func CheckContent(text string) string {
+	if text == "" {
+		return ""
+	}
+	if !utf8.ValidString(text) {
+		return ContentInvalidUTF8
+	}
+
+	runes := []rune(text)
+	var replacement, whitespace int
+	for _, r := range runes {
+		switch {
+		case r == utf8.RuneError:
+			replacement++
+		case unicode.IsSpace(r):
+			whitespace++
+		}
+	}
+	total := float64(len(runes))
+	if float64(replacement)/total > maxReplacementRatio {
+		return ContentReplacement
+	}
+	if len(runes) >= contentMinWhitespace && float64(whitespace)/total > maxWhitespaceRatio {
+		return ContentWhitespace
+	}
+	if len(runes) >= contentMinRepetition && repeatedNGramRatio(runes, contentNGram) > maxRepetitionRatio {
+		return ContentRepetition
+	}
+	return ""
+}
+
+// repeatedNGramRatio 返回长度为 n 的字符片段中重复出现（非首次）的比例。
+func repeatedNGramRatio(runes []rune, n int) float64 {
+	total := len(runes) - n + 1
+	if total <= 0 {
+		return 0
+	}
+	seen := make(map[string]struct{}, total)
+	repeated := 0
+	for i := 0; i < total; i++ {
+		gram := string(runes[i : i+n])
+		if _, ok := seen[gram]; ok {
+			repeated++
+			continue
+		}
+		seen[gram] = struct{}{}
+	}
+	return float64(repeated) / float64(total)
+}
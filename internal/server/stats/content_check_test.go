@@ -0,0 +1,30 @@
+package stats
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheckContent(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"empty", "", ""},
+		{"normal english", "The quick brown fox jumps over the lazy dog. Benchmarks measure latency, throughput and error rates across providers.", ""},
+		{"normal chinese", "性能测试需要关注首 token 延迟、输出速率和错误率，不同供应商在高并发下的表现差异很大，因此需要在相同条件下多次测量并比较结果。", ""},
+		{"short repeat", "好的好的", ""},
+		{"invalid utf8", "hello \xff\xfe world", ContentInvalidUTF8},
+		{"replacement chars", "结果：���� 完成", ContentReplacement},
+		{"looping", strings.Repeat("我是一个语言模型。", 20), ContentRepetition},
+		{"whitespace", "a" + strings.Repeat(" \n\t", 20) + "b", ContentWhitespace},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CheckContent(tt.text); got != tt.want {
+				t.Errorf("CheckContent(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package stats
+
+import (
+	"sync"
+
+	"github.com/yinxulai/ait/internal/server/types"
+)
+
+// DefaultAbortMinSamples 错误率熔断默认的最少完成请求数，样本太少时错误率波动大，不做判断
+const DefaultAbortMinSamples = 20
+
+// ErrorRateBreaker 一次运行的错误率熔断器。
+//
+// 每个请求完成时调用 Observe 累计成功 / 失败数；累计完成数达到 minSamples 后错误率严格超过阈值即触发
+// （因此阈值须小于 100，由 ParseErrorRate 与任务校验保证），
+// 调用方据此取消运行的 context（与手动取消共用同一条路径）。触发后不再累计，Stats 保留触发时的快照。
+// 所有方法并发安全，nil 表示不熔断。
+type ErrorRateBreaker struct {
+	threshold  float64
+	minSamples int
+
+	mu        sync.Mutex
+	completed int
+	failed    int
+	tripped   *types.AbortStats
+}
+
+// NewErrorRateBreaker 创建错误率熔断器；threshold 为百分比，<= 0 时返回 nil（不熔断）；
+// minSamples <= 0 时使用 DefaultAbortMinSamples。
+func NewErrorRateBreaker(threshold float64, minSamples int) *ErrorRateBreaker {
+	if threshold <= 0 {
+		return nil
+	}
+	if minSamples <= 0 {
+		minSamples = DefaultAbortMinSamples
+	}
+	return &ErrorRateBreaker{threshold: threshold, minSamples: minSamples}
+}
+
+// Observe 记录一个完成的请求，本次调用使熔断器触发时返回 true（只返回一次）。
+func (b *ErrorRateBreaker) Observe(success bool) bool {
+	if b == nil {
+		return false
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.tripped != nil {
+		return false
+	}
+	b.completed++
+	if !success {
+		b.failed++
+	}
+	if b.completed < b.minSamples {
+		return false
+	}
+	rate := float64(b.failed) / float64(b.completed) * 100
+	if rate <= b.threshold {
+		return false
+	}
+	b.tripped = &types.AbortStats{
+		Threshold:  b.threshold,
+		MinSamples: b.minSamples,
+		Completed:  b.completed,
+		Failed:     b.failed,
+		ErrorRate:  rate,
+	}
+	return true
+}
+
+// Tripped 返回熔断器是否已触发。
+func (b *ErrorRateBreaker) Tripped() bool {
+	return b.Stats() != nil
+}
+
+// Stats 返回触发时的统计快照；未触发或未启用时返回 nil。
+func (b *ErrorRateBreaker) Stats() *types.AbortStats {
+	if b == nil {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.tripped == nil {
+		return nil
+	}
+	stats := *b.tripped
+	return &stats
+}
@@ -0,0 +1,54 @@
+package stats
+
+import (
+	"testing"
+
+	"github.com/yinxulai/ait/internal/server/types"
+)
+
+func TestErrorRateBreaker(t *testing.T) {
+	b := NewErrorRateBreaker(50, 4)
+	// 样本不足时即使全部失败也不触发
+	for i := 0; i < 3; i++ {
+		if b.Observe(false) {
+			t.Fatalf("tripped after %d samples, want at least 4", i+1)
+		}
+	}
+	if !b.Observe(true) {
+		t.Fatal("3/4 failed (75%) should trip the 50% breaker")
+	}
+	// 只触发一次，之后的请求不再计入快照
+	if b.Observe(false) {
+		t.Error("Observe should report tripping only once")
+	}
+	want := types.AbortStats{Threshold: 50, MinSamples: 4, Completed: 4, Failed: 3, ErrorRate: 75}
+	if got := b.Stats(); got == nil || *got != want || !b.Tripped() {
+		t.Errorf("Stats = %+v, want %+v", got, want)
+	}
+}
+
+func TestErrorRateBreakerBelowThreshold(t *testing.T) {
+	b := NewErrorRateBreaker(50, 2)
+	// 错误率恰好等于阈值不触发
+	for _, success := range []bool{false, true, true, false, true, false} {
+		if b.Observe(success) {
+			t.Fatal("error rate never exceeds 50%, breaker should not trip")
+		}
+	}
+	if b.Tripped() || b.Stats() != nil {
+		t.Error("breaker should not be tripped")
+	}
+}
+
+func TestErrorRateBreakerDisabled(t *testing.T) {
+	b := NewErrorRateBreaker(0, 10)
+	if b != nil {
+		t.Fatal("NewErrorRateBreaker(0) should return nil")
+	}
+	if b.Observe(false) || b.Tripped() || b.Stats() != nil {
+		t.Error("nil breaker must never trip")
+	}
+	if d := NewErrorRateBreaker(30, 0); d.minSamples != DefaultAbortMinSamples {
+		t.Errorf("minSamples = %d, want default %d", d.minSamples, DefaultAbortMinSamples)
+	}
+}
@@ -0,0 +1,66 @@
+package stats
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/google/jsonschema-go/jsonschema"
+)
+
+// 响应 JSON schema 校验不合规的类型
+const (
+	SchemaInvalidJSON = "invalid_json"     // 正文不是一个完整的 JSON 值
+	SchemaViolation   = "schema_violation" // 是合法 JSON，但不满足 schema
+)
+
+// JSONSchema 解析并完成引用解析的响应 JSON schema（--response-schema），支持 draft-07 与 draft 2020-12。
+type JSONSchema struct {
+	resolved *jsonschema.Resolved
+}
+
+// LoadJSONSchema 读取并解析 JSON schema 文件；只解析文件内的 $ref，不加载远程引用。
+func LoadJSONSchema(path string) (*JSONSchema, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取 JSON schema 文件失败: %w", err)
+	}
+	schema, err := ParseJSONSchema(data)
+	if err != nil {
+		return nil, fmt.Errorf("JSON schema 文件 %s 无效: %w", path, err)
+	}
+	return schema, nil
+}
+
+// ParseJSONSchema 从 JSON 文本解析 schema。
+func ParseJSONSchema(data []byte) (*JSONSchema, error) {
+	var schema jsonschema.Schema
+	if err := json.Unmarshal(data, &schema); err != nil {
+		return nil, err
+	}
+	resolved, err := schema.Resolve(nil)
+	if err != nil {
+		return nil, err
+	}
+	return &JSONSchema{resolved: resolved}, nil
+}
+
+// Check 校验一条模型输出：正文去掉首尾空白后必须恰好是一个 JSON 值且满足 schema，
+// 包在 Markdown 代码块里或前后带说明文字都视为不合规。合规时返回两个空字符串，
+// 否则返回不合规类型（Schema* 常量）与具体原因。
+func (s *JSONSchema) Check(text string) (reason, detail string) {
+	dec := json.NewDecoder(strings.NewReader(strings.TrimSpace(text)))
+	var instance any
+	if err := dec.Decode(&instance); err != nil {
+		return SchemaInvalidJSON, err.Error()
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return SchemaInvalidJSON, "JSON 值之后还有多余内容"
+	}
+	if err := s.resolved.Validate(instance); err != nil {
+		return SchemaViolation, strings.TrimPrefix(err.Error(), "validating root: ")
+	}
+	return "", ""
+}
@@ -0,0 +1,73 @@
+package stats
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testSchema = `{
+	"$schema": "https://json-schema.org/draft/2020-12/schema",
+	"type": "object",
+	"required": ["name", "age"],
+	"properties": {
+		"name": {"type": "string"},
+		"age": {"type": "integer", "minimum": 0},
+		"tags": {"type": "array", "items": {"$ref": "#/$defs/tag"}}
+	},
+	"$defs": {"tag": {"type": "string"}}
+}`
+
+func TestJSONSchema_Check(t *testing.T) {
+	schema, err := ParseJSONSchema([]byte(testSchema))
+	if err != nil {
+		t.Fatalf("ParseJSONSchema: %v", err)
+	}
+	tests := []struct {
+		text       string
+		wantReason string
+	}{
+		{`{"name":"a","age":3}`, ""},
+		{"\n  {\"name\":\"a\",\"age\":3,\"tags\":[\"x\"]}  \n", ""},
+		{`{"name":"a"}`, SchemaViolation},
+		{`{"name":"a","age":-1}`, SchemaViolation},
+		{`{"name":"a","age":1.5}`, SchemaViolation},
+		{`{"name":"a","age":3,"tags":[1]}`, SchemaViolation},
+		{`{"name":"a","age":3`, SchemaInvalidJSON},
+		{`{"name":"a","age":3} trailing`, SchemaInvalidJSON},
+		{`{"name":"a","age":3}]`, SchemaInvalidJSON},
+		{"```json\n{\"name\":\"a\",\"age\":3}\n```", SchemaInvalidJSON},
+		{"", SchemaInvalidJSON},
+	}
+	for _, tt := range tests {
+		reason, detail := schema.Check(tt.text)
+		if reason != tt.wantReason {
+			t.Errorf("Check(%q) = %q (%s), want %q", tt.text, reason, detail, tt.wantReason)
+		}
+		if (reason == "") != (detail == "") {
+			t.Errorf("Check(%q) reason = %q but detail = %q", tt.text, reason, detail)
+		}
+	}
+}
+
+func TestLoadJSONSchema(t *testing.T) {
+	dir := t.TempDir()
+	valid := filepath.Join(dir, "schema.json")
+	if err := os.WriteFile(valid, []byte(testSchema), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadJSONSchema(valid); err != nil {
+		t.Errorf("LoadJSONSchema(valid): %v", err)
+	}
+
+	badRef := filepath.Join(dir, "bad.json")
+	if err := os.WriteFile(badRef, []byte(`{"$ref": "#/$defs/missing"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{badRef, filepath.Join(dir, "missing.json")} {
+		if _, err := LoadJSONSchema(path); err == nil || !strings.Contains(err.Error(), "JSON schema") {
+			t.Errorf("LoadJSONSchema(%s) error = %v", filepath.Base(path), err)
+		}
+	}
+}
@@ -0,0 +1,220 @@
+package stats
+
+import (
+	"runtime"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/yinxulai/ait/internal/server/types"
+)
+
+// DefaultSelfSampleInterval 自监控默认采样间隔。
+const DefaultSelfSampleInterval = 200 * time.Millisecond
+
+// residualSettleTimeout 停止时等待连接读写等 goroutine 退出的最长时间，超时后仍多出的计为残留。
+const residualSettleTimeout = 500 * time.Millisecond
+
+// SelfMonitor 周期采样当前进程的 goroutine 数与 runtime.MemStats，
+// 统计运行期间的峰值以及 GC/分配相对开始时的增量；停止时记录比开始时多出的残留 goroutine 数。
+// 同一采样 goroutine 每隔 timelineInterval 另记一次时间线采样，停止时据此判断是否持续增长。
+//
+// ReadMemStats 会短暂 stop-the-world，采样间隔不宜过小。
+type SelfMonitor struct {
+	interval         time.Duration
+	settle           time.Duration
+	timelineInterval time.Duration
+	onSample         func(types.SelfSample)
+
+	mu         sync.Mutex
+	baseline   runtime.MemStats
+	stats      types.SelfStats
+	lastRecord time.Time
+	stop       chan struct{}
+	done       chan struct{}
+}
+
+// NewSelfMonitor 创建自监控采样器；interval <= 0 时使用 DefaultSelfSampleInterval。
+func NewSelfMonitor(interval time.Duration) *SelfMonitor {
+	if interval <= 0 {
+		interval = DefaultSelfSampleInterval
+	}
+	return &SelfMonitor{interval: interval, settle: residualSettleTimeout, timelineInterval: types.SelfTimelineInterval}
+}
+
+// OnSample 设置每次时间线采样后的回调（如追加到 selfstats.jsonl），须在 Start 前调用。
+// 回调在采样 goroutine 中执行，不应阻塞。
+func (m *SelfMonitor) OnSample(fn func(types.SelfSample)) {
+	m.onSample = fn
+}
+
+// Start 记录基线并开始后台采样。重复调用无效。
+func (m *SelfMonitor) Start() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.stop != nil {
+		return
+	}
+	runtime.ReadMemStats(&m.baseline)
+	m.stats.BaselineGoroutines = runtime.NumGoroutine()
+	m.observeLocked(&m.baseline)
+	first := m.recordLocked(&m.baseline)
+	m.stop = make(chan struct{})
+	m.done = make(chan struct{})
+	go m.loop(m.stop, m.done)
+	m.emit(&first)
+}
+
+func (m *SelfMonitor) loop(stop, done chan struct{}) {
+	defer close(done)
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			m.sample(false)
+		}
+	}
+}
+
+// sample 采样一次；距上次时间线采样满 timelineInterval 或 record 为 true 时同时记入时间线。
+func (m *SelfMonitor) sample(record bool) {
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+	m.mu.Lock()
+	m.observeLocked(&ms)
+	var sample *types.SelfSample
+	if record || time.Since(m.lastRecord) >= m.timelineInterval {
+		s := m.recordLocked(&ms)
+		sample = &s
+	}
+	m.mu.Unlock()
+	m.emit(sample)
+}
+
+// recordLocked 追加一次时间线采样并返回它（调用方须持有 mu）。
+func (m *SelfMonitor) recordLocked(ms *runtime.MemStats) types.SelfSample {
+	m.lastRecord = time.Now()
+	sample := types.SelfSample{
+		At:         m.lastRecord,
+		Goroutines: runtime.NumGoroutine(),
+		HeapAlloc:  ms.HeapAlloc,
+		NumGC:      ms.NumGC - m.baseline.NumGC,
+	}
+	m.stats.Timeline = append(m.stats.Timeline, sample)
+	return sample
+}
+
+// emit 在锁外把时间线采样交给 onSample 回调。
+func (m *SelfMonitor) emit(sample *types.SelfSample) {
+	if sample != nil && m.onSample != nil {
+		m.onSample(*sample)
+	}
+}
+
+// observeLocked 用一次采样更新峰值与增量（调用方须持有 mu）。
+func (m *SelfMonitor) observeLocked(ms *runtime.MemStats) {
+	s := &m.stats
+	s.Samples++
+	if n := runtime.NumGoroutine(); n > s.PeakGoroutines {
+		s.PeakGoroutines = n
+	}
+	if ms.HeapAlloc > s.PeakHeapAlloc {
+		s.PeakHeapAlloc = ms.HeapAlloc
+	}
+	if ms.Sys > s.PeakSys {
+		s.PeakSys = ms.Sys
+	}
+	s.TotalAlloc = ms.TotalAlloc - m.baseline.TotalAlloc
+	s.Mallocs = ms.Mallocs - m.baseline.Mallocs
+	s.NumGC = ms.NumGC - m.baseline.NumGC
+	s.GCPauseTotal = time.Duration(ms.PauseTotalNs - m.baseline.PauseTotalNs)
+}
+
+// Stop 停止采样，补采最后一次并记录残留 goroutine 数后返回统计结果；调用方应先释放连接等资源。
+// 对 nil 或未启动的采样器返回 nil，便于调用方无条件调用。
+func (m *SelfMonitor) Stop() *types.SelfStats {
+	if m == nil {
+		return nil
+	}
+	m.mu.Lock()
+	stop, done := m.stop, m.done
+	m.stop = nil
+	m.mu.Unlock()
+	if stop == nil {
+		return m.Snapshot()
+	}
+	close(stop)
+	<-done
+	m.sample(true)
+	residual := m.residualGoroutines()
+	m.mu.Lock()
+	m.stats.ResidualGoroutines = residual
+	m.stats.GoroutineGrowing, m.stats.HeapGrowing = DetectSelfGrowth(m.stats.Timeline)
+	m.mu.Unlock()
+	return m.Snapshot()
+}
+
+// DetectSelfGrowth 判断时间线上的 goroutine 数与堆内存是否持续增长，判定规则见 types.SelfGrowthMinSamples。
+func DetectSelfGrowth(timeline []types.SelfSample) (goroutines, heap bool) {
+	if len(timeline) < types.SelfGrowthMinSamples {
+		return false, false
+	}
+	gs := make([]float64, len(timeline))
+	hs := make([]float64, len(timeline))
+	for i, s := range timeline {
+		gs[i], hs[i] = float64(s.Goroutines), float64(s.HeapAlloc)
+	}
+	return growing(gs, types.SelfGoroutineGrowthThreshold, types.SelfGoroutineGrowthMin),
+		growing(hs, types.SelfHeapGrowthThreshold, 0)
+}
+
+// growing 把 values 按先后均分为三段，三段均值依次递增、末段均值相对首段增幅超过 ratio 且至少增加 minDelta 时返回 true。
+func growing(values []float64, ratio, minDelta float64) bool {
+	n := len(values) / 3
+	head := mean(values[:n])
+	middle := mean(values[n : len(values)-n])
+	tail := mean(values[len(values)-n:])
+	return head < middle && middle < tail && tail-head >= minDelta && tail > head*(1+ratio)
+}
+
+func mean(values []float64) float64 {
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+// residualGoroutines 返回比开始时多出的 goroutine 数。刚关闭的连接等 goroutine 退出需要一点时间，
+// 超过阈值时在 settle 内轮询等待其回落。
+func (m *SelfMonitor) residualGoroutines() int {
+	m.mu.Lock()
+	baseline := m.stats.BaselineGoroutines
+	m.mu.Unlock()
+	deadline := time.Now().Add(m.settle)
+	for {
+		residual := max(runtime.NumGoroutine()-baseline, 0)
+		if residual <= types.ResidualGoroutineThreshold || !time.Now().Before(deadline) {
+			return residual
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// Snapshot 返回当前统计结果的拷贝；未启动时返回 nil。
+func (m *SelfMonitor) Snapshot() *types.SelfStats {
+	if m == nil {
+		return nil
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.stats.Samples == 0 {
+		return nil
+	}
+	snap := m.stats
+	snap.Timeline = slices.Clone(m.stats.Timeline)
+	return &snap
+}
@@ -0,0 +1,166 @@
+package stats
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/yinxulai/ait/internal/server/types"
+)
+
+func TestSelfMonitorTracksPeakAndDeltas(t *testing.T) {
+	m := NewSelfMonitor(5 * time.Millisecond)
+	m.Start()
+
+	// 制造一批阻塞的 goroutine 和一些分配，确保采样期间可见
+	release := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-release
+		}()
+	}
+	var sink [][]byte
+	for i := 0; i < 100; i++ {
+		sink = append(sink, make([]byte, 4096))
+	}
+	time.Sleep(30 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	_ = sink
+
+	got := m.Stop()
+	if got == nil {
+		t.Fatal("Stop returned nil after Start")
+	}
+	if got.PeakGoroutines < 50 {
+		t.Errorf("PeakGoroutines = %d, want >= 50", got.PeakGoroutines)
+	}
+	if got.TotalAlloc < 100*4096 {
+		t.Errorf("TotalAlloc = %d, want >= %d", got.TotalAlloc, 100*4096)
+	}
+	if got.Mallocs == 0 || got.PeakHeapAlloc == 0 || got.PeakSys == 0 {
+		t.Errorf("unexpected zero stats: %+v", got)
+	}
+	if got.Samples < 2 {
+		t.Errorf("Samples = %d, want >= 2", got.Samples)
+	}
+}
+
+func TestSelfMonitorStopWithoutStart(t *testing.T) {
+	var nilMonitor *SelfMonitor
+	if got := nilMonitor.Stop(); got != nil {
+		t.Errorf("nil monitor Stop = %+v, want nil", got)
+	}
+	if got := NewSelfMonitor(0).Stop(); got != nil {
+		t.Errorf("unstarted monitor Stop = %+v, want nil", got)
+	}
+}
+
+func TestSelfMonitorStopIsIdempotent(t *testing.T) {
+	m := NewSelfMonitor(time.Millisecond)
+	m.Start()
+	first := m.Stop()
+	second := m.Stop()
+	if first == nil || second == nil {
+		t.Fatalf("Stop results: %v, %v", first, second)
+	}
+	if second.Samples != first.Samples {
+		t.Errorf("second Stop sampled again: %d -> %d", first.Samples, second.Samples)
+	}
+}
+
+func TestSelfMonitorResidualGoroutines(t *testing.T) {
+	m := NewSelfMonitor(time.Millisecond)
+	m.settle = 20 * time.Millisecond
+	m.Start()
+	release := make(chan struct{})
+	defer close(release)
+	for range types.ResidualGoroutineThreshold + 5 {
+		go func() { <-release }()
+	}
+	stats := m.Stop()
+	if stats.BaselineGoroutines == 0 {
+		t.Errorf("baseline goroutines not recorded")
+	}
+	if stats.ResidualGoroutines < types.ResidualGoroutineThreshold+5 || !stats.LeakSuspected() {
+		t.Errorf("residual = %d, want leak suspected", stats.ResidualGoroutines)
+	}
+}
+
+func TestSelfMonitorNoResidualAfterExit(t *testing.T) {
+	m := NewSelfMonitor(time.Millisecond)
+	m.Start()
+	var wg sync.WaitGroup
+	for range 20 {
+		wg.Add(1)
+		go func() { defer wg.Done(); time.Sleep(5 * time.Millisecond) }()
+	}
+	wg.Wait()
+	if stats := m.Stop(); stats.LeakSuspected() {
+		t.Errorf("residual = %d after goroutines exited", stats.ResidualGoroutines)
+	}
+}
+
+func TestSelfMonitorTimeline(t *testing.T) {
+	m := NewSelfMonitor(time.Millisecond)
+	m.timelineInterval = 5 * time.Millisecond
+	var mu sync.Mutex
+	var emitted []types.SelfSample
+	m.OnSample(func(s types.SelfSample) {
+		mu.Lock()
+		emitted = append(emitted, s)
+		mu.Unlock()
+	})
+	m.Start()
+	time.Sleep(30 * time.Millisecond)
+	stats := m.Stop()
+
+	// 开始、运行期间与结束时各有采样
+	if len(stats.Timeline) < 3 {
+		t.Fatalf("Timeline has %d samples, want at least 3", len(stats.Timeline))
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(emitted) != len(stats.Timeline) {
+		t.Errorf("OnSample called %d times, want %d", len(emitted), len(stats.Timeline))
+	}
+	for i := 1; i < len(stats.Timeline); i++ {
+		if stats.Timeline[i].At.Before(stats.Timeline[i-1].At) {
+			t.Fatalf("Timeline not in order: %v", stats.Timeline)
+		}
+	}
+}
+
+func TestDetectSelfGrowth(t *testing.T) {
+	timeline := func(goroutines []int, heaps []uint64) []types.SelfSample {
+		samples := make([]types.SelfSample, len(goroutines))
+		for i := range samples {
+			samples[i] = types.SelfSample{Goroutines: goroutines[i], HeapAlloc: heaps[i]}
+		}
+		return samples
+	}
+	flat := []uint64{10 << 20, 11 << 20, 10 << 20, 12 << 20, 10 << 20, 11 << 20}
+	tests := []struct {
+		name                    string
+		samples                 []types.SelfSample
+		wantGoroutine, wantHeap bool
+	}{
+		{"too few samples", timeline([]int{10, 50, 100, 200, 400}, []uint64{1, 2, 3, 4, 5}), false, false},
+		{"stable", timeline([]int{20, 22, 21, 20, 23, 21}, flat), false, false},
+		{"goroutines grow", timeline([]int{20, 25, 30, 36, 44, 50}, flat), true, false},
+		{"small absolute growth", timeline([]int{4, 5, 6, 7, 8, 9}, flat), false, false},
+		{"heap grows", timeline([]int{20, 22, 21, 20, 23, 21}, []uint64{10 << 20, 12 << 20, 15 << 20, 18 << 20, 22 << 20, 26 << 20}), false, true},
+		{"grow then recover", timeline([]int{20, 60, 80, 80, 30, 20}, flat), false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			goroutines, heap := DetectSelfGrowth(tt.samples)
+			if goroutines != tt.wantGoroutine || heap != tt.wantHeap {
+				t.Errorf("DetectSelfGrowth = %v, %v; want %v, %v", goroutines, heap, tt.wantGoroutine, tt.wantHeap)
+			}
+		})
+	}
+}
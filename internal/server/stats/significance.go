@@ -0,0 +1,131 @@
+package stats
+
+import "math"
+
+// MinSignificanceSamples 显著性检验建议的最小样本数：任一组少于该值时 p 值不可靠，应增加 count 后再下结论。
+const MinSignificanceSamples = 30
+
+// WelchResult Welch's t 检验的结果。
+type WelchResult struct {
+	T  float64 // t 统计量，正数表示 a 的均值更大
+	DF float64 // Welch–Satterthwaite 近似自由度
+	P  float64 // 双侧 p 值
+}
+
+// WelchTTest 对两组独立样本的均值做 Welch's t 检验（不假设两组方差相等）。
+// 任一组少于 2 个样本时无法估计方差，返回 false。
+func WelchTTest(a, b []float64) (WelchResult, bool) {
+	if len(a) < 2 || len(b) < 2 {
+		return WelchResult{}, false
+	}
+	meanA, varA := meanVariance(a)
+	meanB, varB := meanVariance(b)
+	seA, seB := varA/float64(len(a)), varB/float64(len(b))
+	se := seA + seB
+	if se == 0 {
+		// 两组都没有波动：均值相同则无差异，否则差异确定
+		if meanA == meanB {
+			return WelchResult{P: 1}, true
+		}
+		return WelchResult{T: math.Copysign(math.Inf(1), meanA-meanB), DF: math.Inf(1), P: 0}, true
+	}
+	t := (meanA - meanB) / math.Sqrt(se)
+	df := se * se / (seA*seA/float64(len(a)-1) + seB*seB/float64(len(b)-1))
+	return WelchResult{T: t, DF: df, P: studentTwoSidedP(t, df)}, true
+}
+
+// SignificanceMark 把 p 值转为显著性符号：p < 0.01 为 "**"，p < 0.05 为 "*"，否则为 "n.s."（差异不显著）。
+func SignificanceMark(p float64) string {
+	switch {
+	case p < 0.01:
+		return "**"
+	case p < 0.05:
+		return "*"
+	}
+	return "n.s."
+}
+
+// meanVariance 返回样本均值与无偏样本方差。
+func meanVariance(xs []float64) (mean, variance float64) {
+	for _, x := range xs {
+		mean += x
+	}
+	mean /= float64(len(xs))
+	for _, x := range xs {
+		variance += (x - mean) * (x - mean)
+	}
+	return mean, variance / float64(len(xs)-1)
+}
+
+// studentTwoSidedP 返回自由度 df 的 t 分布中 |T| >= |t| 的概率，即 I_{df/(df+t²)}(df/2, 1/2)。
+func studentTwoSidedP(t, df float64) float64 {
+	if math.IsInf(df, 1) {
+		return math.Erfc(math.Abs(t) / math.Sqrt2)
+	}
+	return regIncBeta(df/(df+t*t), df/2, 0.5)
+}
+
+// regIncBeta 正则化不完全 beta 函数 I_x(a, b)，用连分式（修正 Lentz 法）求值。
+func regIncBeta(x, a, b float64) float64 {
+	switch {
+	case x <= 0:
+		return 0
+	case x >= 1:
+		return 1
+	}
+	lga, _ := math.Lgamma(a)
+	lgb, _ := math.Lgamma(b)
+	lgab, _ := math.Lgamma(a + b)
+	front := math.Exp(lgab - lga - lgb + a*math.Log(x) + b*math.Log(1-x))
+	// 连分式在 x < (a+1)/(a+b+2) 时收敛快，否则用 I_x(a,b) = 1 - I_{1-x}(b,a)
+	if x < (a+1)/(a+b+2) {
+		return front * betaContinuedFraction(x, a, b) / a
+	}
+	return 1 - front*betaContinuedFraction(1-x, b, a)/b
+}
+
+func betaContinuedFraction(x, a, b float64) float64 {
+	const (
+		maxIterations = 300
+		epsilon       = 1e-14
+		tiny          = 1e-300
+	)
+	c, d := 1.0, 1-(a+b)*x/(a+1)
+	if math.Abs(d) < tiny {
+		d = tiny
+	}
+	d = 1 / d
+	h := d
+	for m := 1; m <= maxIterations; m++ {
+		fm := float64(m)
+		// 偶数项
+		num := fm * (b - fm) * x / ((a + 2*fm - 1) * (a + 2*fm))
+		d = 1 + num*d
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = 1 + num/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		h *= d * c
+		// 奇数项
+		num = -(a + fm) * (a + b + fm) * x / ((a + 2*fm) * (a + 2*fm + 1))
+		d = 1 + num*d
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = 1 + num/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		delta := d * c
+		h *= delta
+		if math.Abs(delta-1) < epsilon {
+			break
+		}
+	}
+	return h
+}
@@ -0,0 +1,90 @@
+package stats
+
+import (
+	"math"
+	"testing"
+)
+
+func TestStudentTwoSidedP(t *testing.T) {
+	tests := []struct {
+		t, df, want float64
+	}{
+		{1, 1, 0.5},                    // df=1 为柯西分布：P(|T|>=1) = 1/2
+		{2.228, 10, 0.05},              // t 分布表：df=10 双侧 5% 临界值
+		{3.169, 10, 0.01},              // df=10 双侧 1% 临界值
+		{1.96, math.Inf(1), 0.0499958}, // 自由度无穷大退化为正态分布
+		{0, 5, 1},
+	}
+	for _, tt := range tests {
+		if got := studentTwoSidedP(tt.t, tt.df); math.Abs(got-tt.want) > 2e-4 {
+			t.Errorf("studentTwoSidedP(%v, %v) = %v, want %v", tt.t, tt.df, got, tt.want)
+		}
+	}
+}
+
+func TestWelchTTest(t *testing.T) {
+	// 英文维基百科 Welch's t-test 条目中的示例数据集与参考值
+	tests := []struct {
+		name      string
+		a, b      []float64
+		t, df, p  float64
+		wantLabel string
+	}{
+		{
+			name:      "equal sizes",
+			a:         []float64{27.5, 21.0, 19.0, 23.6, 17.0, 17.9, 16.9, 20.1, 21.9, 22.6, 23.1, 19.6, 19.0, 21.7, 21.4},
+			b:         []float64{27.1, 22.0, 20.8, 23.4, 23.4, 23.5, 25.8, 22.0, 24.8, 20.2, 21.9, 22.1, 22.9, 20.5, 24.4},
+			t:         -2.4554,
+			df:        24.989,
+			p:         0.02138,
+			wantLabel: "*",
+		},
+		{
+			name:      "unequal sizes and variances",
+			a:         []float64{17.2, 20.9, 22.6, 18.1, 21.7, 21.4, 23.5, 24.2, 14.7, 21.8},
+			b:         []float64{21.5, 22.8, 21.0, 23.0, 21.6, 23.6, 22.5, 20.7, 23.4, 21.8, 20.7, 21.7, 21.5, 22.5, 23.6, 21.5, 22.5, 23.5, 21.5, 21.8},
+			t:         -1.5654,
+			df:        9.905,
+			p:         0.14884,
+			wantLabel: "n.s.",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := WelchTTest(tt.a, tt.b)
+			if !ok {
+				t.Fatal("WelchTTest returned false")
+			}
+			if math.Abs(got.T-tt.t) > 1e-3 || math.Abs(got.DF-tt.df) > 1e-2 || math.Abs(got.P-tt.p) > 1e-4 {
+				t.Errorf("WelchTTest = %+v, want t=%v df=%v p=%v", got, tt.t, tt.df, tt.p)
+			}
+			if label := SignificanceMark(got.P); label != tt.wantLabel {
+				t.Errorf("SignificanceMark(%v) = %q, want %q", got.P, label, tt.wantLabel)
+			}
+			// 交换两组只改变 t 的符号
+			if swapped, _ := WelchTTest(tt.b, tt.a); math.Abs(swapped.T+got.T) > 1e-9 || math.Abs(swapped.P-got.P) > 1e-9 {
+				t.Errorf("swapped = %+v, want t=%v p=%v", swapped, -got.T, got.P)
+			}
+		})
+	}
+}
+
+func TestWelchTTest_Degenerate(t *testing.T) {
+	if _, ok := WelchTTest([]float64{1}, []float64{1, 2}); ok {
+		t.Error("a single sample should not be testable")
+	}
+	if got, _ := WelchTTest([]float64{5, 5, 5}, []float64{5, 5}); got.P != 1 {
+		t.Errorf("identical constant samples: p = %v, want 1", got.P)
+	}
+	if got, _ := WelchTTest([]float64{5, 5, 5}, []float64{6, 6}); got.P != 0 || !math.IsInf(got.T, -1) {
+		t.Errorf("different constant samples: %+v, want p=0, t=-Inf", got)
+	}
+}
+
+func TestSignificanceMark(t *testing.T) {
+	for p, want := range map[float64]string{0.001: "**", 0.0099: "**", 0.01: "*", 0.049: "*", 0.05: "n.s.", 0.8: "n.s."} {
+		if got := SignificanceMark(p); got != want {
+			t.Errorf("SignificanceMark(%v) = %q, want %q", p, got, want)
+		}
+	}
+}
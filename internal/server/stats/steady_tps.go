@@ -0,0 +1,16 @@
+package stats
+
+import "time"
+
+// SteadyTPS 计算单个请求的稳态输出 TPS：tokens / (totalTime - ttft)。
+//
+// 普通 TPS 以总耗时为分母，首 token 前的 prefill / 排队时间也被摊进去，
+// 对 TTFT 很长的推理型模型偏低；稳态 TPS 只看首 token 之后的生成阶段。
+// 仅流式且输出 token > 1 时有意义（非流式 TTFT 等于总耗时），否则返回 false。
+func SteadyTPS(tokens int, totalTime, ttft time.Duration) (float64, bool) {
+	generation := totalTime - ttft
+	if tokens <= 1 || ttft <= 0 || generation <= 0 {
+		return 0, false
+	}
+	return float64(tokens) / generation.Seconds(), true
+}
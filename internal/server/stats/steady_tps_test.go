@@ -0,0 +1,31 @@
+package stats
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestSteadyTPS(t *testing.T) {
+	tests := []struct {
+		name      string
+		tokens    int
+		total     time.Duration
+		ttft      time.Duration
+		want      float64
+		wantValid bool
+	}{
+		{"excludes ttft", 100, 3 * time.Second, time.Second, 50, true},
+		{"single token", 1, time.Second, 500 * time.Millisecond, 0, false},
+		{"non-stream", 100, time.Second, time.Second, 0, false},
+		{"no ttft", 100, time.Second, 0, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := SteadyTPS(tt.tokens, tt.total, tt.ttft)
+			if ok != tt.wantValid || math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("SteadyTPS = %v, %v; want %v, %v", got, ok, tt.want, tt.wantValid)
+			}
+		})
+	}
+}
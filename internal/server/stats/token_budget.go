@@ -0,0 +1,57 @@
+package stats
+
+import (
+	"sync/atomic"
+
+	"github.com/yinxulai/ait/internal/server/types"
+)
+
+// TokenBudget 一次运行的总 token 预算（input+output）。
+//
+// 每个请求完成时原子累加实际消耗；累计达到上限后 Exhausted 返回 true，
+// 调用方据此停止派发新请求，已发出的请求照常完成。所有方法并发安全，nil 表示不限预算。
+type TokenBudget struct {
+	limit int64
+	used  atomic.Int64
+}
+
+// NewTokenBudget 创建 token 预算；limit <= 0 时返回 nil（不限）。
+func NewTokenBudget(limit int64) *TokenBudget {
+	if limit <= 0 {
+		return nil
+	}
+	return &TokenBudget{limit: limit}
+}
+
+// Add 累加一次请求消耗的 token 数。
+func (b *TokenBudget) Add(tokens int) {
+	if b == nil || tokens <= 0 {
+		return
+	}
+	b.used.Add(int64(tokens))
+}
+
+// Used 返回已累计消耗的 token 数。
+func (b *TokenBudget) Used() int64 {
+	if b == nil {
+		return 0
+	}
+	return b.used.Load()
+}
+
+// Exhausted 返回预算是否已用完。
+func (b *TokenBudget) Exhausted() bool {
+	return b != nil && b.used.Load() >= b.limit
+}
+
+// Stats 返回当前预算使用情况；未设置预算时返回 nil。
+func (b *TokenBudget) Stats() *types.TokenBudgetStats {
+	if b == nil {
+		return nil
+	}
+	return &types.TokenBudgetStats{
+		Budget:    b.limit,
+		Used:      b.Used(),
+		Exhausted: b.Exhausted(),
+	}
+}
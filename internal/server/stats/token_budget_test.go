@@ -0,0 +1,51 @@
+package stats
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestTokenBudget(t *testing.T) {
+	b := NewTokenBudget(100)
+	b.Add(60)
+	if b.Exhausted() {
+		t.Fatal("budget exhausted too early")
+	}
+	b.Add(40)
+	if !b.Exhausted() {
+		t.Fatal("budget should be exhausted at the limit")
+	}
+	got := b.Stats()
+	if got.Budget != 100 || got.Used != 100 || !got.Exhausted {
+		t.Errorf("Stats = %+v", got)
+	}
+}
+
+func TestTokenBudgetUnlimited(t *testing.T) {
+	b := NewTokenBudget(0)
+	if b != nil {
+		t.Fatal("NewTokenBudget(0) should return nil")
+	}
+	b.Add(1 << 30)
+	if b.Exhausted() || b.Used() != 0 || b.Stats() != nil {
+		t.Error("nil budget must never be exhausted")
+	}
+}
+
+func TestTokenBudgetConcurrentAdd(t *testing.T) {
+	b := NewTokenBudget(1 << 40)
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				b.Add(3)
+			}
+		}()
+	}
+	wg.Wait()
+	if got := b.Used(); got != 50*100*3 {
+		t.Fatalf("Used = %d, want %d", got, 50*100*3)
+	}
+}
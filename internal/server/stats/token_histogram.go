@@ -0,0 +1,32 @@
+package stats
+
+import "github.com/yinxulai/ait/internal/server/types"
+
+// histogramMinBound 直方图第一个桶的上界：[0,128) 之后按 2 的幂翻倍（[128,256)、[256,512)…）
+const histogramMinBound = 128
+
+// TokenHistogram 把 token 数按 2 的幂分桶统计，只返回非空的桶，按区间升序排列；
+// 长短混合的负载在直方图上表现为分离的几个峰。
+func TokenHistogram(tokens []int) []types.TokenBucket {
+	counts := make(map[int]int)
+	maxUpper := 0
+	for _, n := range tokens {
+		upper := histogramMinBound
+		for n >= upper {
+			upper *= 2
+		}
+		counts[upper]++
+		maxUpper = max(maxUpper, upper)
+	}
+	if len(counts) == 0 {
+		return nil
+	}
+	var buckets []types.TokenBucket
+	lower := 0
+	for upper := histogramMinBound; upper <= maxUpper; lower, upper = upper, upper*2 {
+		if c := counts[upper]; c > 0 {
+			buckets = append(buckets, types.TokenBucket{Min: lower, Max: upper, Count: c})
+		}
+	}
+	return buckets
+}
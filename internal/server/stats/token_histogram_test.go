@@ -0,0 +1,24 @@
+package stats
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/yinxulai/ait/internal/server/types"
+)
+
+func TestTokenHistogram(t *testing.T) {
+	got := TokenHistogram([]int{0, 90, 127, 128, 3000, 4095, 4096})
+	want := []types.TokenBucket{
+		{Min: 0, Max: 128, Count: 3},
+		{Min: 128, Max: 256, Count: 1},
+		{Min: 2048, Max: 4096, Count: 2},
+		{Min: 4096, Max: 8192, Count: 1},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("TokenHistogram = %+v, want %+v", got, want)
+	}
+	if got := TokenHistogram(nil); got != nil {
+		t.Errorf("TokenHistogram(nil) = %+v, want nil", got)
+	}
+}
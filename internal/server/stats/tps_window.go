@@ -0,0 +1,96 @@
+package stats
+
+import (
+	"sync"
+	"time"
+)
+
+// DefaultTPSWindow 实时 TPS 默认统计窗口。
+const DefaultTPSWindow = 5 * time.Second
+
+type tokenEvent struct {
+	at     time.Time
+	tokens int
+}
+
+// TPSWindow 基于滑动时间窗口的实时 TPS 统计器。
+//
+// 累计平均 TPS 在长时间运行中会被早期数据"稀释"，无法反映当前吞吐变化。
+// TPSWindow 记录每次请求完成时的时间戳和输出 token 数，只统计最近 window
+// 时间内的 token，从而得到瞬时吞吐。所有方法并发安全。
+type TPSWindow struct {
+	mu     sync.Mutex
+	window time.Duration
+	events []tokenEvent
+}
+
+// NewTPSWindow 创建滑动窗口统计器；window <= 0 时使用 DefaultTPSWindow。
+func NewTPSWindow(window time.Duration) *TPSWindow {
+	if window <= 0 {
+		window = DefaultTPSWindow
+	}
+	return &TPSWindow{window: window}
+}
+
+// Window 返回统计窗口长度。
+func (w *TPSWindow) Window() time.Duration {
+	if w == nil {
+		return 0
+	}
+	return w.window
+}
+
+// Add 记录一次 token 完成事件。
+func (w *TPSWindow) Add(at time.Time, tokens int) {
+	if w == nil || tokens <= 0 {
+		return
+	}
+	w.mu.Lock()
+	w.events = append(w.events, tokenEvent{at: at, tokens: tokens})
+	w.pruneLocked(at)
+	w.mu.Unlock()
+}
+
+// Rate 返回截止 now 的窗口内 TPS（tokens / window 秒）。
+//
+// 运行开始不足一个窗口时，分母使用已过去的时间而非完整窗口，
+// 避免刚启动时数值被低估；since 为零值时始终使用完整窗口。
+func (w *TPSWindow) Rate(now, since time.Time) float64 {
+	if w == nil {
+		return 0
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.pruneLocked(now)
+
+	total := 0
+	for _, e := range w.events {
+		if e.at.After(now) {
+			continue
+		}
+		total += e.tokens
+	}
+	if total == 0 {
+		return 0
+	}
+
+	span := w.window
+	if !since.IsZero() {
+		if elapsed := now.Sub(since); elapsed > 0 && elapsed < span {
+			span = elapsed
+		}
+	}
+	return float64(total) / span.Seconds()
+}
+
+// pruneLocked 丢弃窗口之外的旧事件。调用方需持有锁。
+func (w *TPSWindow) pruneLocked(now time.Time) {
+	cutoff := now.Add(-w.window)
+	drop := 0
+	for drop < len(w.events) && !w.events[drop].at.After(cutoff) {
+		drop++
+	}
+	if drop > 0 {
+		w.events = append(w.events[:0], w.events[drop:]...)
+	}
+}
@@ -0,0 +1,77 @@
+package stats
+
+import (
+	"math"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestTPSWindowRate(t *testing.T) {
+	w := NewTPSWindow(5 * time.Second)
+	base := time.Unix(1000, 0)
+	w.Add(base, 100)
+	w.Add(base.Add(2*time.Second), 150)
+
+	got := w.Rate(base.Add(4*time.Second), time.Time{})
+	if math.Abs(got-50) > 1e-9 {
+		t.Fatalf("Rate = %v, want 50", got)
+	}
+}
+
+func TestTPSWindowDropsExpiredEvents(t *testing.T) {
+	w := NewTPSWindow(5 * time.Second)
+	base := time.Unix(1000, 0)
+	w.Add(base, 500)
+	w.Add(base.Add(6*time.Second), 100)
+
+	got := w.Rate(base.Add(7*time.Second), time.Time{})
+	if math.Abs(got-20) > 1e-9 {
+		t.Fatalf("Rate = %v, want 20 (expired event must be ignored)", got)
+	}
+	if got := w.Rate(base.Add(20*time.Second), time.Time{}); got != 0 {
+		t.Fatalf("Rate after window = %v, want 0", got)
+	}
+}
+
+func TestTPSWindowUsesElapsedBeforeFullWindow(t *testing.T) {
+	w := NewTPSWindow(5 * time.Second)
+	start := time.Unix(1000, 0)
+	w.Add(start.Add(time.Second), 100)
+
+	got := w.Rate(start.Add(2*time.Second), start)
+	if math.Abs(got-50) > 1e-9 {
+		t.Fatalf("Rate = %v, want 50", got)
+	}
+}
+
+func TestTPSWindowDefaultsAndNil(t *testing.T) {
+	if got := NewTPSWindow(0).Window(); got != DefaultTPSWindow {
+		t.Fatalf("Window = %v, want %v", got, DefaultTPSWindow)
+	}
+	var w *TPSWindow
+	w.Add(time.Now(), 10)
+	if got := w.Rate(time.Now(), time.Time{}); got != 0 {
+		t.Fatalf("nil Rate = %v, want 0", got)
+	}
+}
+
+func TestTPSWindowConcurrentAdd(t *testing.T) {
+	w := NewTPSWindow(time.Minute)
+	now := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w.Add(now, 2)
+		}()
+	}
+	wg.Wait()
+
+	got := w.Rate(now, time.Time{})
+	want := 100 / time.Minute.Seconds()
+	if math.Abs(got-want) > 1e-9 {
+		t.Fatalf("Rate = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,26 @@
+package server
+
+import (
+	"sync/atomic"
+
+	"github.com/yinxulai/ait/internal/server/prompt"
+	"github.com/yinxulai/ait/internal/server/types"
+)
+
+var processStdinPrompts atomic.Pointer[prompt.PromptSource]
+
+// SetStdinPrompts 设置本进程从 stdin 逐行读入的 prompt 数据集，通常在启动时由 --prompt-stdin-lines 设置；
+// source 为 nil 表示关闭。
+func SetStdinPrompts(source *prompt.PromptSource) {
+	processStdinPrompts.Store(source)
+}
+
+// applyProcessStdinPrompts 设置了 --prompt-stdin-lines 时以 stdin 各行代替任务的 prompt 配置
+// （raw 模式下每行作为一个原始请求体）；重放运行保持重放文件中的 prompt。
+func applyProcessStdinPrompts(input *types.Input) {
+	source := processStdinPrompts.Load()
+	if source == nil || input.ReplayFile != "" {
+		return
+	}
+	input.PromptSource = source
+}
@@ -19,6 +19,7 @@ type RunMetadata struct {
 	Mode       string     `json:"mode"`
 	Protocol   string     `json:"protocol"`
 	Model      string     `json:"model"`
+	ModelAlias string     `json:"model_alias,omitempty"`
 	Status     string     `json:"status"`
 	StartedAt  time.Time  `json:"started_at"`
 	FinishedAt *time.Time `json:"finished_at,omitempty"`
@@ -50,6 +51,9 @@ type RunResult struct {
 	CacheHitRate float64       `json:"cache_hit_rate,omitempty"`
 	RPM          float64       `json:"rpm,omitempty"`
 	TPM          float64       `json:"tpm,omitempty"`
+
+	// 自监控结果（仅开启 self_stats 时存在）
+	SelfStats *types.SelfStats `json:"self_stats,omitempty"`
 }
 
 type StoredRun struct {
@@ -189,6 +193,7 @@ func (s *RunStore) Load(taskID, runID string) (*StoredRun, error) {
 		if err != nil {
 			return nil, err
 		}
+		loaded.ModeResult = decodeModeResult(meta.Mode, loaded.ModeResult)
 		result = &loaded
 	} else if !os.IsNotExist(err) {
 		return nil, err
@@ -197,6 +202,47 @@ func (s *RunStore) Load(taskID, runID string) (*StoredRun, error) {
 	return &StoredRun{Metadata: meta, Result: result}, nil
 }
 
+// decodeModeResult 把从 JSON 读回的 ModeResult（map[string]any）还原为模式对应的具体类型，
+// 标准运行按字段区分 A/B 对比（stream / non_stream）、HTTP 版本对比（http1 / http2）、输入长度扫描（points）、
+// 按 prompt 文件分场景运行（scenarios）与普通结果。
+// 无法识别或还原失败时原样返回。
+func decodeModeResult(mode string, v any) any {
+	fields, ok := v.(map[string]any)
+	if !ok {
+		return v
+	}
+	var target any
+	switch mode {
+	case "standard":
+		if _, ok := fields["points"]; ok {
+			target = &types.InputLengthSweepResult{}
+		} else if _, ok := fields["scenarios"]; ok {
+			target = &types.PromptFileRunsResult{}
+		} else if _, ok := fields["stream"]; ok {
+			target = &types.StreamCompareResult{}
+		} else if _, ok := fields["non_stream"]; ok {
+			target = &types.StreamCompareResult{}
+		} else if _, ok := fields["http1"]; ok {
+			target = &types.HTTPVersionCompareResult{}
+		} else if _, ok := fields["http2"]; ok {
+			target = &types.HTTPVersionCompareResult{}
+		} else {
+			target = &types.ReportData{}
+		}
+	case "turbo":
+		target = &types.TurboResult{}
+	case "integrity":
+		target = &types.IntegrityResult{}
+	default:
+		return v
+	}
+	raw, err := json.Marshal(fields)
+	if err != nil || json.Unmarshal(raw, target) != nil {
+		return v
+	}
+	return target
+}
+
 func (s *RunStore) LoadByRunID(runID string) (*StoredRun, error) {
 	taskEntries, err := os.ReadDir(s.root)
 	if os.IsNotExist(err) {
@@ -315,13 +361,14 @@ func (s *RunStore) ListSummariesByTask(taskID string, limit int) ([]types.TaskRu
 
 func (r StoredRun) Summary(requests []types.RequestMetrics) types.TaskRunSummary {
 	summary := types.TaskRunSummary{
-		RunID:     r.Metadata.RunID,
-		TaskID:    r.Metadata.TaskID,
-		Mode:      r.Metadata.Mode,
-		Status:    r.Metadata.Status,
-		Protocol:  r.Metadata.Protocol,
-		Model:     r.Metadata.Model,
-		StartedAt: r.Metadata.StartedAt,
+		RunID:      r.Metadata.RunID,
+		TaskID:     r.Metadata.TaskID,
+		Mode:       r.Metadata.Mode,
+		Status:     r.Metadata.Status,
+		Protocol:   r.Metadata.Protocol,
+		Model:      r.Metadata.Model,
+		ModelAlias: r.Metadata.ModelAlias,
+		StartedAt:  r.Metadata.StartedAt,
 	}
 	if r.Metadata.FinishedAt != nil {
 		summary.FinishedAt = *r.Metadata.FinishedAt
@@ -424,6 +471,22 @@ func (r StoredRun) TotalReqs(requests []types.RequestMetrics) int {
 			if result.TotalRequests > 0 {
 				return result.TotalRequests
 			}
+		case *types.StreamCompareResult:
+			if total := result.TotalRequests(); total > 0 {
+				return total
+			}
+		case *types.InputLengthSweepResult:
+			if total := result.TotalRequests(); total > 0 {
+				return total
+			}
+		case *types.PromptFileRunsResult:
+			if total := result.TotalRequests(); total > 0 {
+				return total
+			}
+		case *types.HTTPVersionCompareResult:
+			if total := result.TotalRequests(); total > 0 {
+				return total
+			}
 		case *types.TurboResult:
 			total := 0
 			for _, level := range result.Levels {
@@ -94,3 +94,48 @@ func TestRunStore_ResultOmitsDerivedSummaryFields(t *testing.T) {
 		t.Fatalf("expected error_summary to remain in result.json, got %s", raw)
 	}
 }
+
+func TestRunStore_LoadRestoresModeResultType(t *testing.T) {
+	store := NewRunStore(t.TempDir())
+	finishedAt := time.Now().UTC().Truncate(time.Second)
+
+	cases := map[string]struct {
+		mode   string
+		result any
+		check  func(any) bool
+	}{
+		"report": {"standard", &types.ReportData{TotalRequests: 4}, func(v any) bool {
+			r, ok := v.(*types.ReportData)
+			return ok && r.TotalRequests == 4
+		}},
+		"compare": {"standard", &types.StreamCompareResult{NonStream: &types.ReportData{TotalRequests: 2}}, func(v any) bool {
+			r, ok := v.(*types.StreamCompareResult)
+			return ok && r.Stream == nil && r.NonStream.TotalRequests == 2
+		}},
+		"sweep": {"standard", &types.InputLengthSweepResult{Points: []*types.ReportData{{TotalRequests: 3}}}, func(v any) bool {
+			r, ok := v.(*types.InputLengthSweepResult)
+			return ok && len(r.Points) == 1 && r.Points[0].TotalRequests == 3
+		}},
+		"prompt files": {"standard", &types.PromptFileRunsResult{Scenarios: []*types.ReportData{{TotalRequests: 2, Scenario: "code"}}}, func(v any) bool {
+			r, ok := v.(*types.PromptFileRunsResult)
+			return ok && len(r.Scenarios) == 1 && r.Scenarios[0].Scenario == "code"
+		}},
+		"turbo": {"turbo", &types.TurboResult{MaxStableConcurrency: 8}, func(v any) bool {
+			r, ok := v.(*types.TurboResult)
+			return ok && r.MaxStableConcurrency == 8
+		}},
+	}
+	for name, c := range cases {
+		meta := RunMetadata{RunID: "run-" + name, TaskID: "task", Mode: c.mode, Status: "completed", StartedAt: finishedAt, FinishedAt: &finishedAt}
+		if err := store.SaveFinalRun(meta, RunResult{ModeResult: c.result}); err != nil {
+			t.Fatalf("%s: SaveFinalRun: %v", name, err)
+		}
+		loaded, err := store.Load(meta.TaskID, meta.RunID)
+		if err != nil || loaded == nil || loaded.Result == nil {
+			t.Fatalf("%s: Load = %+v, %v", name, loaded, err)
+		}
+		if !c.check(loaded.Result.ModeResult) {
+			t.Errorf("%s: ModeResult = %#v", name, loaded.Result.ModeResult)
+		}
+	}
+}
@@ -0,0 +1,22 @@
+package server
+
+import (
+	"sync/atomic"
+
+	"github.com/yinxulai/ait/internal/server/types"
+)
+
+var processStreamBoth atomic.Bool
+
+// SetStreamBoth 设置本进程是否对每次标准运行做流式 / 非流式 A/B 对比，通常在启动时由 --stream-both 设置；
+// 开启后等同于为任务打开 compare_stream，每轮各跑完整的请求数。
+func SetStreamBoth(enabled bool) {
+	processStreamBoth.Store(enabled)
+}
+
+// streamBothApplies 返回进程级 --stream-both 是否作用于该运行：只对标准模式生效，
+// raw prompt 无法切换 stream 字段、输入长度扫描自成多轮、确定性验证固定非流式，均保持原样。
+func streamBothApplies(input types.Input) bool {
+	return processStreamBoth.Load() && input.RunMode() == "standard" && input.PromptMode != "raw" && len(input.InputLengthSweep) == 0 &&
+		!input.ConsistencyCheck
+}
@@ -2,6 +2,7 @@ package task
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/yinxulai/ait/internal/server/prompt"
 	"github.com/yinxulai/ait/internal/server/types"
@@ -11,6 +12,15 @@ func HydrateInput(input types.Input) (types.Input, error) {
 	if input.PromptSource != nil {
 		return input, nil
 	}
+	if input.ReplayFile != "" {
+		// 重放运行以重放文件中的 prompt 代替 prompt 配置
+		source, err := prompt.LoadReplayFile(input.ReplayFile)
+		if err != nil {
+			return input, err
+		}
+		input.PromptSource = source
+		return input, nil
+	}
 
 	switch input.PromptMode {
 	case "", "text":
@@ -32,6 +42,18 @@ func HydrateInput(input types.Input) (types.Input, error) {
 		}
 		input.PromptSource = source
 	case "generated":
+		if strings.TrimSpace(input.PromptLengthDist) != "" {
+			dist, err := prompt.ParseLengthDist(input.PromptLengthDist)
+			if err != nil {
+				return input, err
+			}
+			source, err := prompt.LoadPromptByLengthDist(dist, input.PromptSeed)
+			if err != nil {
+				return input, err
+			}
+			input.PromptSource = source
+			break
+		}
 		if input.PromptLength <= 0 {
 			return input, fmt.Errorf("prompt_length must be greater than zero for prompt_mode=generated")
 		}
@@ -3,6 +3,7 @@ package task
 import (
 	"testing"
 
+	"github.com/yinxulai/ait/internal/server/prompt"
 	"github.com/yinxulai/ait/internal/server/types"
 )
 
@@ -32,6 +33,20 @@ func TestHydrateInputGeneratedMode(t *testing.T) {
 	}
 }
 
+func TestHydrateInputGeneratedLengthDist(t *testing.T) {
+	input, err := HydrateInput(types.Input{PromptMode: "generated", PromptLengthDist: "50:1,300:1", PromptSeed: 3})
+	if err != nil {
+		t.Fatalf("HydrateInput(generated dist) returned unexpected error: %v", err)
+	}
+	if _, ok := input.PromptSource.(*prompt.LengthDistSource); !ok {
+		t.Fatalf("PromptSource = %T, want *prompt.LengthDistSource", input.PromptSource)
+	}
+
+	if _, err := HydrateInput(types.Input{PromptMode: "generated", PromptLengthDist: "50"}); err == nil {
+		t.Fatal("expected HydrateInput to reject a malformed prompt_length_dist")
+	}
+}
+
 func TestHydrateInputRejectsInvalidMode(t *testing.T) {
 	if _, err := HydrateInput(types.Input{PromptMode: "unknown"}); err == nil {
 		t.Fatal("expected HydrateInput to reject unsupported prompt_mode")
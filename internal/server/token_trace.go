@@ -0,0 +1,143 @@
+package server
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	"github.com/yinxulai/ait/internal/server/types"
+)
+
+// DefaultTokenTraceMaxChunks --token-trace 单个请求默认最多记录的 chunk 数
+const DefaultTokenTraceMaxChunks = 10000
+
+// TokenTraceEvent token 级时序文件中的一行，对应流式响应的一个增量 chunk。
+//
+// 文件格式固定为 JSONL：<dir>/<run_id>/<请求序号，6 位补零>.jsonl，每行一个 chunk 事件，按到达顺序排列，
+// 如 {"seq":0,"t_ms":312.5,"chars":4,"tokens":1}。t_ms 为相对请求发出时刻的毫秒数（保留微秒精度），
+// chars 为该 chunk 增量内容（正文、思考与工具调用参数）的字符数，tokens 为按字符估算的 token 数；
+// chunk 数达到上限被截断时，最后一行带 "truncated":true。
+type TokenTraceEvent struct {
+	Seq       int     `json:"seq"`
+	OffsetMs  float64 `json:"t_ms"`
+	Chars     int     `json:"chars"`
+	Tokens    int     `json:"tokens"`
+	Truncated bool    `json:"truncated,omitempty"`
+}
+
+// Offset 返回 chunk 相对请求发出时刻的到达时间。
+func (e TokenTraceEvent) Offset() time.Duration {
+	return time.Duration(e.OffsetMs * float64(time.Millisecond))
+}
+
+// tokenTrace 进程级的 token 时序输出配置
+type tokenTrace struct {
+	dir       string
+	maxChunks int
+}
+
+var processTokenTrace atomic.Pointer[tokenTrace]
+
+// SetTokenTrace 设置本进程流式请求的 token 时序输出目录，通常在启动时由 --token-trace 设置；dir 为空时关闭。
+// maxChunks 为单个请求最多记录的 chunk 数，超出部分截断。
+func SetTokenTrace(dir string, maxChunks int) error {
+	if dir == "" {
+		processTokenTrace.Store(nil)
+		return nil
+	}
+	if maxChunks <= 0 {
+		return fmt.Errorf("单请求 chunk 上限必须大于 0，当前为 %d", maxChunks)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	processTokenTrace.Store(&tokenTrace{dir: dir, maxChunks: maxChunks})
+	return nil
+}
+
+// applyProcessTokenTrace 开启 --token-trace 时让客户端记录 chunk 时序。
+func applyProcessTokenTrace(input *types.Input) {
+	if t := processTokenTrace.Load(); t != nil {
+		input.TokenTraceMaxChunks = t.maxChunks
+	}
+}
+
+// tokenTraceEvents 把客户端记录的 chunk 转换为时序文件中的事件。
+func tokenTraceEvents(chunks []types.TokenChunk, truncated bool) []TokenTraceEvent {
+	events := make([]TokenTraceEvent, len(chunks))
+	for i, c := range chunks {
+		events[i] = TokenTraceEvent{
+			Seq:      i,
+			OffsetMs: float64(c.Offset.Microseconds()) / 1000,
+			Chars:    c.Chars,
+			Tokens:   c.Tokens,
+		}
+	}
+	if truncated && len(events) > 0 {
+		events[len(events)-1].Truncated = true
+	}
+	return events
+}
+
+// WriteTokenTrace 按 TokenTraceEvent 描述的格式写出一个请求的 chunk 事件。
+func WriteTokenTrace(w io.Writer, events []TokenTraceEvent) error {
+	enc := json.NewEncoder(w)
+	for _, e := range events {
+		if err := enc.Encode(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ParseTokenTrace 解析 WriteTokenTrace 写出的 token 时序文件，空行被忽略。
+func ParseTokenTrace(r io.Reader) ([]TokenTraceEvent, error) {
+	var events []TokenTraceEvent
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var e TokenTraceEvent
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return nil, fmt.Errorf("第 %d 行: %w", line, err)
+		}
+		events = append(events, e)
+	}
+	return events, scanner.Err()
+}
+
+// tokenTracePath 返回一个请求的 token 时序文件路径。
+func tokenTracePath(dir string, runID RunID, index int) string {
+	return filepath.Join(dir, string(runID), fmt.Sprintf("%06d.jsonl", index))
+}
+
+// saveTokenTrace 在开启 --token-trace 时把一个请求记录到的 chunk 时序写入单独的文件；
+// 没有 chunk（非流式、失败或无内容）的请求不写，写入失败直接忽略，不影响运行。
+func (a *RunAggregator) saveTokenTrace(result RequestResult) {
+	t := processTokenTrace.Load()
+	if t == nil || result.Metrics == nil || len(result.Metrics.TokenChunks) == 0 {
+		return
+	}
+	path := tokenTracePath(t.dir, a.runID, result.Job.Index)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	w := bufio.NewWriter(f)
+	if err := WriteTokenTrace(w, tokenTraceEvents(result.Metrics.TokenChunks, result.Metrics.TokenChunksTruncated)); err != nil {
+		return
+	}
+	_ = w.Flush()
+}
@@ -0,0 +1,76 @@
+package server
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yinxulai/ait/internal/server/types"
+)
+
+func TestParseTokenTrace(t *testing.T) {
+	// 文件格式固定：每行一个 chunk 事件，截断时最后一行带 truncated
+	data := `{"seq":0,"t_ms":312.5,"chars":4,"tokens":1}
+
+{"seq":1,"t_ms":340.125,"chars":6,"tokens":2,"truncated":true}
+`
+	events, err := ParseTokenTrace(strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("ParseTokenTrace: %v", err)
+	}
+	want := []TokenTraceEvent{
+		{Seq: 0, OffsetMs: 312.5, Chars: 4, Tokens: 1},
+		{Seq: 1, OffsetMs: 340.125, Chars: 6, Tokens: 2, Truncated: true},
+	}
+	if len(events) != len(want) {
+		t.Fatalf("events = %+v, want %+v", events, want)
+	}
+	for i := range want {
+		if events[i] != want[i] {
+			t.Errorf("events[%d] = %+v, want %+v", i, events[i], want[i])
+		}
+	}
+	if got := events[1].Offset(); got != 340125*time.Microsecond {
+		t.Errorf("Offset() = %v, want 340.125ms", got)
+	}
+
+	if _, err := ParseTokenTrace(strings.NewReader("{\"seq\":0}\nnot json\n")); err == nil || !strings.Contains(err.Error(), "第 2 行") {
+		t.Errorf("ParseTokenTrace(invalid) error = %v, want line number", err)
+	}
+}
+
+func TestWriteTokenTrace_RoundTrip(t *testing.T) {
+	chunks := []types.TokenChunk{
+		{Offset: 100 * time.Millisecond, Chars: 3, Tokens: 1},
+		{Offset: 120*time.Millisecond + 500*time.Microsecond, Chars: 8, Tokens: 2},
+	}
+	var buf bytes.Buffer
+	if err := WriteTokenTrace(&buf, tokenTraceEvents(chunks, true)); err != nil {
+		t.Fatalf("WriteTokenTrace: %v", err)
+	}
+	if lines := strings.Count(buf.String(), "\n"); lines != 2 {
+		t.Fatalf("wrote %d lines, want 2: %q", lines, buf.String())
+	}
+	events, err := ParseTokenTrace(&buf)
+	if err != nil {
+		t.Fatalf("ParseTokenTrace: %v", err)
+	}
+	for i, e := range events {
+		if e.Seq != i || e.Offset() != chunks[i].Offset || e.Chars != chunks[i].Chars || e.Tokens != chunks[i].Tokens {
+			t.Errorf("events[%d] = %+v, want chunk %+v", i, e, chunks[i])
+		}
+	}
+	if events[0].Truncated || !events[1].Truncated {
+		t.Errorf("only the last event should be marked truncated: %+v", events)
+	}
+}
+
+func TestSetTokenTrace_RejectsInvalidLimit(t *testing.T) {
+	if err := SetTokenTrace(t.TempDir(), 0); err == nil {
+		t.Error("SetTokenTrace(maxChunks=0) should fail")
+	}
+	if processTokenTrace.Load() != nil {
+		t.Error("invalid limit should not enable token trace")
+	}
+}
@@ -16,8 +16,10 @@ type CancelFunc func()
 type ReportFormat string
 
 const (
-	ReportFormatJSON ReportFormat = "json"
-	ReportFormatCSV  ReportFormat = "csv"
+	ReportFormatJSON     ReportFormat = "json"
+	ReportFormatCSV      ReportFormat = "csv"
+	ReportFormatMarkdown ReportFormat = "md"
+	ReportFormatK6       ReportFormat = "k6" // k6 summary JSON 兼容格式
 )
 
 // TaskConfig 新建/更新任务时提交的可变配置。
@@ -91,6 +93,27 @@ type RunState struct {
 	RPM float64
 	TPM float64
 
+	// InstantTPS 最近滑动窗口（默认 5s）内的输出 TPS，反映当前瞬时吞吐
+	InstantTPS float64
+
+	// AvgThinkingTime 出现思考阶段的成功请求的平均思考耗时
+	AvgThinkingTime time.Duration
+
+	// AvgSteadyTPS 排除 TTFT 后的生成阶段平均 TPS（仅流式请求）
+	AvgSteadyTPS float64
+
+	// CachedTokenRatio 按 token 加权的 prompt 缓存命中占比（缓存命中 token / 输入 token，0~1）
+	CachedTokenRatio float64
+
+	// SelfStats ait 进程自身资源占用（仅开启自监控的运行在结束后填充）
+	SelfStats *types.SelfStats
+
+	// Concurrency 当前并发上限（标准模式运行中可通过 AdjustRunConcurrency 调整）
+	Concurrency int
+
+	// Paused 是否已暂停派发新请求（标准模式运行中可通过 SetRunPaused 切换）
+	Paused bool
+
 	// 详细请求列表（按 index 排序）
 	Requests []*types.RequestMetrics
 
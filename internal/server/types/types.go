@@ -1,7 +1,9 @@
 package types
 
 import (
+	"context"
 	"encoding/json"
+	"regexp"
 	"strings"
 	"time"
 )
@@ -10,8 +12,12 @@ const (
 	ProtocolOpenAICompletions = "openai-completions"
 	ProtocolOpenAIResponses   = "openai-responses"
 	ProtocolAnthropicMessages = "anthropic-messages"
+	ProtocolTritonGRPC        = "triton-grpc"
 )
 
+// DefaultGRPCMethod Triton 流式推理的完整 gRPC 方法名（vLLM backend 的 generate_stream 走此接口）
+const DefaultGRPCMethod = "/inference.GRPCInferenceService/ModelStreamInfer"
+
 func NormalizeProtocol(protocol string) string {
 	switch strings.ToLower(strings.TrimSpace(protocol)) {
 	case "", "openai", ProtocolOpenAICompletions:
@@ -20,6 +26,8 @@ func NormalizeProtocol(protocol string) string {
 		return ProtocolOpenAIResponses
 	case "anthropic", ProtocolAnthropicMessages:
 		return ProtocolAnthropicMessages
+	case "grpc", "triton", ProtocolTritonGRPC:
+		return ProtocolTritonGRPC
 	default:
 		return strings.TrimSpace(protocol)
 	}
@@ -33,6 +41,8 @@ func DefaultEndpointURL(protocol string) string {
 		return "https://api.openai.com/v1/responses"
 	case ProtocolAnthropicMessages:
 		return "https://api.anthropic.com/v1/messages"
+	case ProtocolTritonGRPC:
+		return "localhost:8001"
 	default:
 		return ""
 	}
@@ -84,6 +94,26 @@ type PromptSource interface {
 	Count() int
 }
 
+// GeneratingSource 由每个请求前动态生成 prompt 的来源（如 --prompt-command）额外实现；
+// 生成失败时返回错误，该请求记为失败而不发送。
+type GeneratingSource interface {
+	Generate(ctx context.Context, index int) (string, error)
+}
+
+// PromptContent 返回 source 中第 index 个请求的内容；source 实现了 GeneratingSource 时经 Generate 生成，并返回生成错误。
+func PromptContent(ctx context.Context, source PromptSource, index int) (string, error) {
+	if g, ok := source.(GeneratingSource); ok {
+		return g.Generate(ctx, index)
+	}
+	return source.GetContentByIndex(index), nil
+}
+
+// ReplaySource 由重放文件加载的 prompt 来源额外实现，提供被重放的原任务 ID 与每条 prompt 在原运行中的请求序号
+type ReplaySource interface {
+	OriginTaskID() string
+	OriginIndex(index int) int
+}
+
 // Input 测试配置信息 - 统一的配置结构
 type Input struct {
 	Mode         string          `json:"mode,omitempty"`
@@ -93,6 +123,7 @@ type Input struct {
 	ProxyURL     string          `json:"proxy_url,omitempty"`
 	ApiKey       string          `json:"api_key,omitempty"`
 	Model        string          `json:"model"`
+	ModelAlias   string          `json:"model_alias,omitempty"` // 模型显示名，仅用于展示和报告，请求仍使用 Model
 	Concurrency  int             `json:"concurrency,omitempty"`
 	Count        int             `json:"count,omitempty"`
 	Stream       bool            `json:"stream,omitempty"`
@@ -108,6 +139,196 @@ type Input struct {
 	Report       bool            `json:"report,omitempty"`  // 是否生成报告文件
 	Timeout      time.Duration   `json:"timeout,omitempty"` // 请求超时时间
 	Log          bool            `json:"log,omitempty"`     // 是否开启详细日志记录
+
+	// A/B 对比：开启后同一任务依次以 stream=true / stream=false 各跑一轮（仅标准模式）。
+	// CompareStreamSplit 为 true 时两轮平分 Count，否则每轮各跑完整 Count。
+	CompareStream      bool `json:"compare_stream,omitempty"`
+	CompareStreamSplit bool `json:"compare_stream_split,omitempty"`
+
+	// gRPC 协议：EndpointURL 为 host:port（可带 grpc:// 或 grpcs:// 前缀），
+	// GRPCMethod 为完整方法名，留空时使用 DefaultGRPCMethod。
+	GRPCMethod string `json:"grpc_method,omitempty"`
+
+	// 思考预算（token 数），大于 0 时隐含开启 thinking
+	ThinkingBudget int `json:"thinking_budget,omitempty"`
+
+	// 自监控：运行期间采样 ait 进程自身的 goroutine 与内存/GC 状况
+	SelfStats bool `json:"self_stats,omitempty"`
+
+	// CSV 报告：CSVHeaderComment 在表头前输出一行 # 元信息（版本、任务 ID、生成时间）；
+	// CSVLegacyFormat 输出旧版格式（Duration 字符串、列名无单位），过渡期兼容开关。
+	CSVHeaderComment bool `json:"csv_header_comment,omitempty"`
+	CSVLegacyFormat  bool `json:"csv_legacy_format,omitempty"`
+
+	// 自适应限流：遇到 429 / Retry-After 时降低发送速率并重试，恢复后逐步回升（仅标准模式）
+	Adaptive bool `json:"adaptive,omitempty"`
+
+	// 基线回归检测（仅标准模式）：BaselineDir 下没有该任务的基线时把本次结果存为基线，
+	// 否则对比关键指标，任一指标回退超过 RegressionThreshold（百分比，0 表示默认 10）即判定为回归
+	BaselineDir         string  `json:"baseline_dir,omitempty"`
+	RegressionThreshold float64 `json:"regression_threshold,omitempty"`
+
+	// 总 token 预算（input+output，仅标准模式）：累计消耗达到后停止派发新请求，
+	// 已发出的请求完成后按实际完成的请求出报告；0 表示不限
+	TokenBudget int64 `json:"token_budget,omitempty"`
+
+	// 运行结束后把核心指标推送到 webhook（仅标准模式）。WebhookFormat 为 generic / feishu / slack / wecom，
+	// 留空按 generic；推送失败重试两次，只记录警告，不影响运行结果
+	WebhookURL    string `json:"webhook_url,omitempty"`
+	WebhookFormat string `json:"webhook_format,omitempty"`
+
+	// 多端点轮询：非空时每个请求按 EndpointStrategy（round-robin / random，留空为 round-robin）
+	// 从中选择一个完整接口地址代替 EndpointURL，报告按端点分组统计
+	Endpoints        []string `json:"endpoints,omitempty"`
+	EndpointStrategy string   `json:"endpoint_strategy,omitempty"`
+
+	// 单次请求的输出 token 上限：OpenAI Chat Completions / Anthropic 写入 max_tokens，Responses API 写入
+	// max_output_tokens；0 表示不设置（Anthropic 协议要求必填，此时默认 1024）
+	MaxTokens int `json:"max_tokens,omitempty"`
+
+	// Anthropic 协议请求头：AnthropicVersion 覆盖 anthropic-version（留空为 2023-06-01），
+	// AnthropicBeta 非空时作为 anthropic-beta 发送（多个特性用逗号分隔，如 prompt-caching-2024-07-31）
+	AnthropicVersion string `json:"anthropic_version,omitempty"`
+	AnthropicBeta    string `json:"anthropic_beta,omitempty"`
+
+	// 响应内容检测（仅标准模式）：对成功响应的正文做乱码 / 复读启发式检查，报告中统计疑似异常数
+	ContentCheck bool `json:"content_check,omitempty"`
+
+	// SLA 表达式（仅标准模式），如 "ttft<800ms,total<10s,p=95"：至少 p% 的请求同时满足各条件才算达标，
+	// 失败请求计为不达标；报告的 sla_results 给出每条的达标率
+	SLA []string `json:"sla,omitempty"`
+
+	// 请求级 prompt 长度分布（仅 generated 模式），如 "100:0.8,4000:0.2"（长度:权重）：每个请求按权重
+	// 采样目标长度，代替固定的 PromptLength；PromptSeed 为采样种子，相同种子的多次运行长度序列一致
+	PromptLengthDist string `json:"prompt_length_dist,omitempty"`
+	PromptSeed       int64  `json:"prompt_seed,omitempty"`
+
+	// 工具调用压测（仅 openai-completions 协议）：OpenAI 格式的工具定义数组文件，内容作为请求体的 tools 字段发送；
+	// 返回 tool_calls 而没有正文的响应同样计为成功
+	ToolsFile string `json:"tools_file,omitempty"`
+
+	// 请求体 gzip 压缩（HTTP 协议）：发送前压缩请求体并设置 Content-Encoding: gzip，用于长上下文压测时节省上行带宽；
+	// 服务端不支持时通常返回 400 / 415
+	CompressRequest bool `json:"compress_request,omitempty"`
+
+	// 请求 ID 回传校验（仅标准模式，HTTP 协议）：为每个请求生成 UUID 作为 X-Request-Id 发送，
+	// 响应回传了不同的 X-Request-Id 时计为可疑，用于排查代理层乱序 / 串包
+	VerifyRequestID bool `json:"verify_request_id,omitempty"`
+
+	// 本地重新计 token（仅标准模式）：用统一的本地估算分词器对响应正文计数，报告给出归一化 TPS，
+	// 消除各服务分词粒度不同带来的 TPS 偏差
+	NormalizeTokens bool `json:"normalize_tokens,omitempty"`
+
+	// 突发模式（仅标准模式）：BurstSize 大于 0 时按每批 BurstSize 个请求同时发出（不受 Concurrency 限制），
+	// 下一批在上一批开始 BurstInterval 后、且上一批全部完成后才发出，批与批之间没有请求；
+	// 最后一批可能不足 BurstSize，总请求数仍由 Count 决定
+	BurstSize     int           `json:"burst_size,omitempty"`
+	BurstInterval time.Duration `json:"burst_interval,omitempty"`
+
+	// 备用模型（仅标准模式）：对 Model 的请求失败时立即以 FallbackModel 重试一次（其余配置相同），
+	// 两者之一成功即算该请求成功，报告统计备用模型的触发次数
+	FallbackModel string `json:"fallback_model,omitempty"`
+
+	// 基线网络探测间隔（仅标准模式）：大于 0 时运行期间按此间隔对端点做 TCP 连接 + TLS 握手计时，
+	// 用于区分网络抖动与服务变慢；探测不发业务请求、不占并发，失败也不影响成功率
+	ProbeInterval time.Duration `json:"probe_interval,omitempty"`
+
+	// 输入长度扫描（仅标准模式，需 prompt_mode=generated 且开启流式）：按列表中的每个目标长度（token 数）
+	// 生成 prompt 各跑一轮 Count 个请求，报告给出输入长度与 TTFT / prefill TPS 的对比
+	InputLengthSweep []int `json:"input_length_sweep,omitempty"`
+
+	// 分段分析比例（仅标准模式）：按完成时间把成功请求划分为预热段 / 稳态段 / 收尾段，
+	// 预热段与收尾段各占该比例，默认 0.1（即 10% / 80% / 10%），取值需小于 0.5
+	PhaseRatio float64 `json:"phase_ratio,omitempty"`
+
+	// 重放文件（仅标准模式）：--failed-output 导出的 JSONL，设置后以其中的 prompt 代替 prompt 配置，
+	// 每条只跑一次（count 自动设为文件中的条数），报告标注为重放运行并引用原任务 ID
+	ReplayFile string `json:"replay_file,omitempty"`
+
+	// 连接建立失败（DNS 解析失败、连接被拒绝、连接超时）时的重试次数（仅标准模式），0 表示不重试；
+	// 请求未发出，重试不影响服务端统计，最终仍失败时按网络错误类别计入报告
+	ConnectRetries int `json:"connect_retries,omitempty"`
+
+	// 确定性验证（仅标准模式）：所有请求使用同一 prompt、非流式并设置 temperature=0，
+	// 报告统计完整输出的不同版本数量与占比，用于发现网关改写参数或路由到不同副本
+	ConsistencyCheck bool `json:"consistency_check,omitempty"`
+
+	// HTTP 版本（HTTP 协议）：1.1 禁用 HTTP/2，2 强制尝试 HTTP/2（需 TLS 且服务端支持 ALPN h2，否则仍为 HTTP/1.1），
+	// 留空或 auto 保持 Go 默认的协商行为；实际协商到的版本记录在每个请求与报告中
+	HTTPVersion string `json:"http_version,omitempty"`
+	// HTTP 版本 A/B 对比（仅标准模式）：依次以 HTTP/1.1、HTTP/2 各跑一轮完整的 Count，报告给出两者的对比
+	CompareHTTPVersion bool `json:"compare_http_version,omitempty"`
+
+	// token 级时序（仅流式）：大于 0 时客户端记录每个增量 chunk 的到达时刻与长度，
+	// 单个请求最多记录该数量的 chunk，超出部分截断；0 表示不记录，由 --token-trace 设置
+	TokenTraceMaxChunks int `json:"token_trace_max_chunks,omitempty"`
+
+	// 错误率熔断（仅标准模式）：已完成请求数达到 AbortMinSamples（0 表示默认 20）后，错误率（百分比）
+	// 超过 AbortOnErrorRate 即停止派发新请求并取消在途请求，按已完成的请求出报告；0 表示不熔断
+	AbortOnErrorRate float64 `json:"abort_on_error_rate,omitempty"`
+	AbortMinSamples  int     `json:"abort_min_samples,omitempty"`
+
+	// 响应压缩（HTTP 协议）：请求头 Accept-Encoding 取 identity、gzip 或 br，设置后客户端自行解压并记录
+	// 每个响应的 Content-Encoding 与压缩前后字节数，用于对比不同编码对 TTFT / 带宽的影响；
+	// 留空保持 Go 默认行为（请求 gzip 并透明解压），不记录压缩统计
+	AcceptEncoding string `json:"accept_encoding,omitempty"`
+
+	// 响应 JSON schema 校验（仅标准模式）：JSON schema 文件路径，成功响应的正文逐条按 schema 校验，
+	// 报告统计合规率；openai-completions 协议同时在请求体中发送 response_format: {"type": "json_object"}
+	ResponseSchema string `json:"response_schema,omitempty"`
+
+	// 按 prompt 文件分场景运行（仅标准模式，需 prompt_mode=file）：prompt_file 匹配多个文件时，每个文件作为一个场景
+	// 依次各跑一轮 Count 个请求，而不是混在一起随机抽取；场景名为文件名去掉扩展名，报告按"模型/场景"分行
+	PromptFilePerRun bool `json:"prompt_file_per_run,omitempty"`
+}
+
+// AcceptEncoding 取值
+const (
+	AcceptEncodingIdentity = "identity"
+	AcceptEncodingGzip     = "gzip"
+	AcceptEncodingBrotli   = "br"
+)
+
+// HTTPVersion 取值
+const (
+	HTTPVersionAuto = "auto"
+	HTTPVersion1    = "1.1"
+	HTTPVersion2    = "2"
+)
+
+// EndpointStrategy 取值
+const (
+	EndpointStrategyRoundRobin = "round-robin"
+	EndpointStrategyRandom     = "random"
+)
+
+// ThinkingEnabled 返回是否开启了思考模式（显式开启或设置了思考预算）。
+func (i Input) ThinkingEnabled() bool {
+	return i.Thinking || i.ThinkingBudget > 0
+}
+
+// DisplayModel 返回用于展示的模型名称：配置了别名时使用别名，否则使用真实模型 ID。
+func (i Input) DisplayModel() string {
+	if alias := strings.TrimSpace(i.ModelAlias); alias != "" {
+		return alias
+	}
+	return i.Model
+}
+
+// CompareStreamCounts 返回 A/B 对比时流式与非流式两轮各自的请求数。
+func (i Input) CompareStreamCounts() (streamCount, nonStreamCount int) {
+	if !i.CompareStreamSplit {
+		return i.Count, i.Count
+	}
+	return (i.Count + 1) / 2, i.Count / 2
+}
+
+// ResolvedGRPCMethod 返回实际使用的 gRPC 方法名。
+func (i Input) ResolvedGRPCMethod() string {
+	if method := strings.TrimSpace(i.GRPCMethod); method != "" {
+		return method
+	}
+	return DefaultGRPCMethod
 }
 
 func (i Input) RunMode() string {
@@ -140,6 +361,14 @@ func (i Input) ResolvedEndpointURL() string {
 	return ResolveEndpointURL(i.Protocol, i.EndpointURL, i.BaseUrl)
 }
 
+// ProbeEndpoint 基线网络探测的目标端点：多端点时取第一个
+func (i Input) ProbeEndpoint() string {
+	if len(i.Endpoints) > 0 {
+		return i.Endpoints[0]
+	}
+	return i.ResolvedEndpointURL()
+}
+
 // StatsData 实时测试统计数据 - runner 内部使用的统计结构
 // 用于在测试过程中实时收集和更新统计信息
 type StatsData struct {
@@ -169,6 +398,9 @@ type StatsData struct {
 	// 测试控制
 	StartTime   time.Time     // 测试开始时间
 	ElapsedTime time.Duration // 已经过时间
+
+	// 实时吞吐 - 最近一个滑动窗口内的输出 TPS（区别于累计平均值）
+	InstantTPS float64
 }
 
 // ReportData runner 返回的统一测试结果数据结构
@@ -183,16 +415,19 @@ type ReportData struct {
 	TotalTime     time.Duration `json:"total_time"`     // 总测试时间
 
 	// 扁平化的元数据信息
-	Timestamp   string `json:"timestamp"`              // 测试时间戳
-	Protocol    string `json:"protocol"`               // 协议类型
-	Model       string `json:"model"`                  // 模型名称
-	EndpointURL string `json:"endpoint_url,omitempty"` // 完整接口地址
-	BaseUrl     string `json:"base_url"`               // 基础URL
+	Timestamp        string `json:"timestamp"`                    // 测试时间戳
+	Protocol         string `json:"protocol"`                     // 协议类型
+	Model            string `json:"model"`                        // 模型名称（真实模型 ID）
+	ModelDisplayName string `json:"model_display_name,omitempty"` // 模型显示名（别名），未配置时为空
+	EndpointURL      string `json:"endpoint_url,omitempty"`       // 完整接口地址
+	BaseUrl          string `json:"base_url"`                     // 基础URL
+	StreamMode       string `json:"stream_mode,omitempty"`        // A/B 对比时标注 stream / non-stream
 
 	// 时间性能指标 - 统计结果
 	AvgTotalTime time.Duration `json:"avg_total_time"` // 平均总耗时
 	MinTotalTime time.Duration `json:"min_total_time"` // 最小总耗时
 	MaxTotalTime time.Duration `json:"max_total_time"` // 最大总耗时
+	P99TotalTime time.Duration `json:"p99_total_time"` // P99 总耗时（最近秩法）
 
 	// 网络性能指标 - 统计结果
 	AvgDNSTime          time.Duration `json:"avg_dns_time"`           // 平均DNS解析时间
@@ -232,6 +467,11 @@ type ReportData struct {
 	MinTPS                   float64       `json:"min_tps"`                      // 最小输出 TPS
 	MaxTPS                   float64       `json:"max_tps"`                      // 最大输出 TPS
 
+	// 思考阶段耗时（首个思考块到首个正文块），仅统计出现思考阶段的流式请求
+	AvgThinkingTime time.Duration `json:"avg_thinking_time"`
+	MinThinkingTime time.Duration `json:"min_thinking_time"`
+	MaxThinkingTime time.Duration `json:"max_thinking_time"`
+
 	// 分钟吩吐量（基于整体运行时长，最终稳定值）
 	RPM float64 `json:"rpm"` // 每分钟完成请求数
 	TPM float64 `json:"tpm"` // 每分钟输出 Token 数
@@ -256,6 +496,569 @@ type ReportData struct {
 	// 可靠性指标 - 统计结果
 	ErrorRate   float64 `json:"error_rate"`   // 错误率 (%)
 	SuccessRate float64 `json:"success_rate"` // 成功率 (%)
+
+	// 工具自身资源占用（仅开启 self_stats 时存在）
+	SelfStats *SelfStats `json:"self_stats,omitempty"`
+
+	// 自适应限流统计（仅开启 adaptive 时存在）
+	Adaptive *AdaptiveStats `json:"adaptive,omitempty"`
+
+	// 基线对比结果（仅配置 baseline_dir 时存在）
+	Baseline *BaselineResult `json:"baseline,omitempty"`
+
+	// token 预算使用情况（仅配置 token_budget 时存在）
+	TokenBudget *TokenBudgetStats `json:"token_budget,omitempty"`
+
+	// 结束原因分布：finish_reason → 请求数（仅统计返回了结束原因的请求）。
+	// length / max_tokens 占比高说明输出常被 max_tokens 截断
+	FinishReasons map[string]int `json:"finish_reasons,omitempty"`
+
+	// 稳态输出 TPS：tokens / (总耗时 - TTFT)，排除首 token 前的 prefill 耗时；
+	// 仅统计流式且输出 token > 1 的请求，非流式为 0
+	AvgSteadyTPS float64 `json:"avg_steady_tps"`
+	MinSteadyTPS float64 `json:"min_steady_tps"`
+	MaxSteadyTPS float64 `json:"max_steady_tps"`
+
+	// 按端点分组的统计（仅配置了多端点轮询时存在），按 Endpoints 配置顺序排列
+	EndpointStats []EndpointStats `json:"endpoint_stats,omitempty"`
+
+	// 疑似乱码 / 复读的成功响应数与前几条样例（仅开启 content_check 时统计）
+	SuspiciousContentCount   int                 `json:"suspicious_content_count,omitempty"`
+	SuspiciousContentSamples []SuspiciousContent `json:"suspicious_content_samples,omitempty"`
+
+	// 多进程分片压测时本进程负责的分片（"i/n"），未分片时为空
+	Shard string `json:"shard,omitempty"`
+
+	// prompt 缓存：成功请求累计的缓存命中 / 缓存写入输入 token 数，
+	// 以及按 token 加权的缓存命中占比（命中 token 总数 / 输入 token 总数，0~1）
+	TotalCachedInputTokens        int     `json:"total_cached_input_tokens"`
+	TotalCacheCreationInputTokens int     `json:"total_cache_creation_input_tokens"`
+	CachedTokenRatio              float64 `json:"cached_token_ratio"`
+
+	// 各条 SLA 的评估结果，顺序与 Input.SLA 一致（仅配置 sla 时存在）
+	SLAResults []SLAResult `json:"sla_results,omitempty"`
+
+	// 实际输入 token 数的分布直方图（仅配置 prompt_length_dist 时存在）
+	InputTokenHistogram []TokenBucket `json:"input_token_histogram,omitempty"`
+
+	// 按实际连接的目标 IP 聚合的统计（域名后有多台机器轮询时用于定位慢后端），
+	// 没有任何请求拿到 IP 时为空；TargetIP 仍只记录第一个有效值
+	TargetIPStats map[string]TargetIPStats `json:"target_ip_stats,omitempty"`
+
+	// 成功请求的平均工具调用次数（仅配置 tools_file 时统计）
+	AvgToolCallCount float64 `json:"avg_tool_call_count,omitempty"`
+
+	// 运行环境（主机、系统、ait 版本、出口 IP），便于报告溯源与归档对比
+	Environment *RunEnvironment `json:"environment,omitempty"`
+
+	// 请求 ID 回传校验结果（仅开启 verify_request_id 时存在）
+	RequestIDCheck *RequestIDCheckStats `json:"request_id_check,omitempty"`
+
+	// 归一化 TPS（仅开启 normalize_tokens 时统计）：成功请求按本地分词器重新计数的输出 token / 总耗时 的平均值；
+	// NormalizedTokenRatio 为本地计数总和 / 服务自报输出 token 总和，反映服务分词的粒度
+	AvgNormalizedTPS     float64 `json:"avg_normalized_tps,omitempty"`
+	NormalizedTokenRatio float64 `json:"normalized_token_ratio,omitempty"`
+
+	// 运行期间手动调整并发的记录（按时间先后），未调整时为空；Concurrency 仍为初始并发
+	ConcurrencyChanges []ConcurrencyChange `json:"concurrency_changes,omitempty"`
+
+	// 运行期间同时在途的连接数峰值；明显低于 Concurrency 时说明服务端或中间件限制了实际并发
+	PeakConcurrentConnections int `json:"peak_concurrent_connections,omitempty"`
+
+	// 配置的 HTTP 版本（1.1 / 2，auto 时为空）与各请求实际协商到的协议版本（如 HTTP/2.0）的请求数
+	HTTPVersion   string         `json:"http_version,omitempty"`
+	HTTPProtocols map[string]int `json:"http_protocols,omitempty"`
+
+	// 突发模式下每批的统计（按批次顺序），用于观察批内的队头阻塞
+	Bursts []BurstStats `json:"bursts,omitempty"`
+
+	// 配置 fallback_model 时备用模型的触发统计
+	Fallback *FallbackStats `json:"fallback,omitempty"`
+
+	// 配置 probe_interval 时运行期间的基线网络探测（按时间先后）及其汇总
+	NetworkProbes       []NetworkProbe       `json:"network_probes,omitempty"`
+	NetworkProbeSummary *NetworkProbeSummary `json:"network_probe_summary,omitempty"`
+
+	// 输入长度扫描时本轮的目标输入长度（token 数）与 prefill TPS：
+	// 成功请求的 输入 token / TTFT 的平均值，近似服务端处理 prompt 的速度
+	InputLength   int     `json:"input_length,omitempty"`
+	AvgPrefillTPS float64 `json:"avg_prefill_tps,omitempty"`
+
+	// 按完成时间分段的预热段 / 稳态段 / 收尾段统计，成功请求过少无法分段时为空
+	PhaseStats *PhaseStats `json:"phase_stats,omitempty"`
+
+	// 重放运行时被重放的原任务 ID
+	ReplayOf string `json:"replay_of,omitempty"`
+
+	// Anthropic 流式：成功请求中各 SSE event 类型首次出现时间的平均值，
+	// 以及各类型 content block（thinking / text 等）首次开始时间的平均值
+	AvgEventTimes  map[string]time.Duration `json:"avg_event_times,omitempty"`
+	AvgBlockStarts map[string]time.Duration `json:"avg_block_starts,omitempty"`
+
+	// 失败请求按错误指纹分组的明细，按次数从多到少排列
+	Errors []ErrorGroup `json:"errors,omitempty"`
+
+	// 收到限流响应（429 / Retry-After）的请求数；开启 adaptive 时被重试成功的限流响应只计入 Adaptive 统计
+	ThrottledCount int `json:"throttled_count,omitempty"`
+
+	// 网络错误按类别（dns / connection_refused / connect_timeout / tls / timeout 等）的请求数
+	NetErrorKinds map[string]int `json:"net_error_kinds,omitempty"`
+
+	// 确定性验证：成功请求完整输出的不同版本数、最常见版本的占比（百分比）与各版本明细（按次数从多到少）
+	DistinctOutputs int             `json:"distinct_outputs,omitempty"`
+	ConsistencyRate float64         `json:"consistency_rate,omitempty"`
+	OutputVariants  []OutputVariant `json:"output_variants,omitempty"`
+
+	// 端点地址被 --resolve 固定解析到指定 IP（绕过 DNS），此时请求的 DNS 耗时为 0
+	ResolveOverridden bool `json:"resolve_overridden,omitempty"`
+
+	// 成功但正文为空的请求数及其占成功请求的百分比："看似成功实则无输出"，常见于内容被安全策略过滤
+	EmptyContentCount int     `json:"empty_content_count,omitempty"`
+	EmptyContentRate  float64 `json:"empty_content_rate,omitempty"`
+
+	// 第 1 个完成的成功请求（冷请求）的指标与其余成功请求的平均值，成功请求不足 2 个时为空
+	FirstRequest *FirstRequestMetrics `json:"first_request,omitempty"`
+
+	// 实际输出 token 相对 max_tokens 的分布（仅设置 max_tokens 时统计），用于确认参数是否生效
+	MaxTokensUsage *MaxTokensUsage `json:"max_tokens_usage,omitempty"`
+
+	// 首字节到达时间（TTFB，服务器开始响应）的统计，只统计收到响应的请求；
+	// AvgFirstTokenGap 为流式请求 TTFT 与 TTFB 差值的平均值，反映首个内容 token 之前的预处理开销
+	// （如先发送只含 role 的空 delta）
+	AvgTTFB          time.Duration `json:"avg_ttfb,omitempty"`
+	MinTTFB          time.Duration `json:"min_ttfb,omitempty"`
+	MaxTTFB          time.Duration `json:"max_ttfb,omitempty"`
+	AvgFirstTokenGap time.Duration `json:"avg_first_token_gap,omitempty"`
+
+	// 错误率熔断（仅配置 abort_on_error_rate 时）：AbortedEarly 表示错误率超过阈值后提前终止，
+	// 剩余请求未派发、在途请求被取消；Abort 为触发时的统计快照
+	AbortedEarly bool        `json:"aborted_early,omitempty"`
+	Abort        *AbortStats `json:"abort,omitempty"`
+
+	// 响应压缩（仅配置 accept_encoding 时）：AcceptEncoding 为配置的 Accept-Encoding，ResponseEncodings 按响应
+	// 实际的 Content-Encoding（未压缩为 identity）统计成功请求的数量、平均 TTFT 与压缩率
+	AcceptEncoding    string                    `json:"accept_encoding,omitempty"`
+	ResponseEncodings map[string]*EncodingStats `json:"response_encodings,omitempty"`
+
+	// 运行期间手动暂停的累计时长，未暂停时为 0；TotalTime 与 RPM / TPM 等吞吐统计已扣除暂停时间
+	PausedTime time.Duration `json:"paused_time,omitempty"`
+
+	// 参与平均值计算的逐请求样本，供多模型对比与基线对比做显著性检验
+	Samples *MetricSamples `json:"samples,omitempty"`
+
+	// 响应 JSON schema 校验结果（仅配置 response_schema 时）
+	SchemaCheck *SchemaCheckStats `json:"schema_check,omitempty"`
+
+	// 运行时开启了 --redact：日志、遥测上报与报告中的错误样例、响应片段均已脱敏
+	Redacted bool `json:"redacted,omitempty"`
+
+	// DNS 解析到的全部 IP（去重后按字符串排序），与 TargetIPStats 对照可看出哪些解析到的后端从未被连接；
+	// 请求都复用已有连接或使用 --resolve 固定地址时为空
+	ResolvedIPs []string `json:"resolved_ips,omitempty"`
+
+	// 请求在本地排队（派发给执行 goroutine 到真正开始执行）的平均与最大时间，统计所有已发出的请求；
+	// 平均值相对平均总耗时超过 QueueWaitWarnRatio 时说明瓶颈在客户端，见 QueueWaitHigh
+	AvgQueueWaitTime time.Duration `json:"avg_queue_wait_time,omitempty"`
+	MaxQueueWaitTime time.Duration `json:"max_queue_wait_time,omitempty"`
+
+	// 因参数不兼容（400）而改写请求体重试的请求数，以及出现过的改动说明（去重后排序），如 "max_tokens→max_completion_tokens"
+	AdaptedRequests int      `json:"adapted_requests,omitempty"`
+	AdaptedParams   []string `json:"adapted_params,omitempty"`
+
+	// 连接复用：拿到过连接的请求数、其中复用了已有连接（httptrace GotConnInfo.Reused）的请求数与复用比例（百分比）；
+	// 禁用 keep-alive 时每个请求都新建连接，复用率为 0
+	ConnRequests       int     `json:"conn_requests,omitempty"`
+	ConnReusedRequests int     `json:"conn_reused_requests,omitempty"`
+	ConnReuseRate      float64 `json:"conn_reuse_rate,omitempty"`
+
+	// 按 prompt 文件分场景运行时本轮的场景名（prompt 文件名去掉扩展名）
+	Scenario string `json:"scenario,omitempty"`
+}
+
+// SetConnReuse 记录连接复用的统计，requests 为 0（没有请求拿到过 HTTP 连接，如 gRPC）时不设置。
+func (d *ReportData) SetConnReuse(reused, requests int) {
+	if requests == 0 {
+		return
+	}
+	d.ConnRequests = requests
+	d.ConnReusedRequests = reused
+	d.ConnReuseRate = float64(reused) / float64(requests) * 100
+}
+
+// QueueWaitWarnRatio 平均本地排队时间占平均总耗时的比例超过该值时，提示并发设置可能过高。
+const QueueWaitWarnRatio = 0.1
+
+// QueueWaitHigh 返回本地排队是否明显：平均排队时间超过平均总耗时的 QueueWaitWarnRatio。
+func (d *ReportData) QueueWaitHigh() bool {
+	return d.AvgQueueWaitTime > 0 && float64(d.AvgQueueWaitTime) > float64(d.AvgTotalTime)*QueueWaitWarnRatio
+}
+
+// SchemaCheckStats 成功响应按 JSON schema 校验的统计。
+type SchemaCheckStats struct {
+	Checked        int               `json:"checked"`           // 参与校验的成功响应数
+	ValidJSON      int               `json:"valid_json"`        // 正文是合法 JSON 的响应数
+	Compliant      int               `json:"compliant"`         // 满足 schema 的响应数
+	ValidJSONRate  float64           `json:"valid_json_rate"`   // 合法 JSON 占比（%）
+	ComplianceRate float64           `json:"compliance_rate"`   // 合规率（%）
+	Samples        []SchemaViolation `json:"samples,omitempty"` // 前几条不合规响应
+}
+
+// SchemaViolation 一条不满足 JSON schema 的响应摘要。
+type SchemaViolation struct {
+	Reason  string `json:"reason"`  // 不合规类型：invalid_json / schema_violation
+	Detail  string `json:"detail"`  // 解析或校验失败的具体原因
+	Excerpt string `json:"excerpt"` // 响应正文开头片段
+}
+
+// MetricSamples 逐请求的原始指标样本，耗时类为毫秒。
+type MetricSamples struct {
+	TotalTime []float64 `json:"total_time,omitempty"`
+	TTFT      []float64 `json:"ttft,omitempty"` // 仅流式
+	TPOT      []float64 `json:"tpot,omitempty"` // 仅输出多于 1 个 token 的请求
+	TPS       []float64 `json:"tps,omitempty"`
+}
+
+// EncodingStats 同一 Content-Encoding 的成功请求的统计。
+type EncodingStats struct {
+	Count                int           `json:"count"`                  // 请求数
+	AvgTTFT              time.Duration `json:"avg_ttft"`               // 平均 TTFT
+	AvgCompressedBytes   int64         `json:"avg_compressed_bytes"`   // 平均响应体线上字节数（解压前）
+	AvgUncompressedBytes int64         `json:"avg_uncompressed_bytes"` // 平均响应体解压后字节数
+	CompressionRatio     float64       `json:"compression_ratio"`      // 压缩率：线上字节总数 / 解压后字节总数，越小压缩越明显
+}
+
+// AbortStats 错误率熔断触发时的统计快照。
+type AbortStats struct {
+	Threshold  float64 `json:"threshold"`   // 错误率阈值（百分比）
+	MinSamples int     `json:"min_samples"` // 开始判断所需的最少完成请求数
+	Completed  int     `json:"completed"`   // 触发时已完成的请求数
+	Failed     int     `json:"failed"`      // 触发时失败的请求数
+	ErrorRate  float64 `json:"error_rate"`  // 触发时的错误率（百分比）
+}
+
+// MaxTokensUsage 成功请求的实际输出 token 与 max_tokens 上限的比例分布。
+type MaxTokensUsage struct {
+	MaxTokens     int     `json:"max_tokens"`     // 请求的输出 token 上限
+	Requests      int     `json:"requests"`       // 参与统计的成功请求数
+	Truncated     int     `json:"truncated"`      // 被截断的请求数：结束原因为 length / max_tokens，或输出达到上限
+	TruncatedRate float64 `json:"truncated_rate"` // 被截断请求的占比（%）
+	Exceeded      int     `json:"exceeded"`       // 输出超过上限的请求数，非零说明服务可能忽略了 max_tokens
+	AvgRatio      float64 `json:"avg_ratio"`      // 实际输出 / 上限的平均值，超出上限时大于 1
+
+	// 按 实际输出 / 上限 分为 <25%、25~50%、50~75%、75~100%、≥100% 五档的请求数
+	Distribution [5]int `json:"distribution"`
+}
+
+// OutputVariant 确定性验证中完整输出相同的一组响应。
+type OutputVariant struct {
+	Hash       string  `json:"hash"`                  // 输出正文的 SHA-256（十六进制前 16 位）
+	Count      int     `json:"count"`                 // 出现次数
+	Rate       float64 `json:"rate"`                  // 占成功请求的百分比
+	DiffOffset int     `json:"diff_offset,omitempty"` // 与最常见版本第一个不同字符的位置（按字符计）
+	Excerpt    string  `json:"excerpt"`               // 最常见版本为开头片段，其余版本为从第一个不同字符起的片段
+}
+
+// ErrorGroup 一组错误指纹相同的失败请求。
+type ErrorGroup struct {
+	Fingerprint string    `json:"fingerprint"`          // 去掉请求 ID、时间戳等易变部分后的错误信息首行
+	Type        string    `json:"type"`                 // 错误分类：auth / rate_limit / timeout / server_error 等
+	Count       int       `json:"count"`                // 出现次数
+	FirstSeen   time.Time `json:"first_seen,omitempty"` // 首次出现（请求完成）的时间
+	Sample      string    `json:"sample"`               // 首次出现时的完整错误信息
+}
+
+// traceErrorSuffix 匹配客户端附加在错误信息末尾的 trace 标注（client_request_id / request_id），每个请求都不同。
+var traceErrorSuffix = regexp.MustCompile(`\s*\[(?:client_request_id|request_id)=[^\]]*\]\s*$`)
+
+// volatileErrorPart 匹配错误信息中随请求变化的部分：请求 ID、UUID 等长十六进制串与时间戳等长数字。
+var volatileErrorPart = regexp.MustCompile(`[0-9a-fA-F][0-9a-fA-F-]{15,}|\d{5,}`)
+
+// ErrorFingerprint 去掉 trace 标注后取错误信息首行，并把易变部分替换为 *，使同一类错误归为一组；状态码等短数字保留。
+func ErrorFingerprint(msg string) string {
+	msg = traceErrorSuffix.ReplaceAllString(strings.TrimSpace(msg), "")
+	line, _, _ := strings.Cut(msg, "\n")
+	line = volatileErrorPart.ReplaceAllString(line, "*")
+	if runes := []rune(line); len(runes) > 200 {
+		line = string(runes[:200]) + "…"
+	}
+	return line
+}
+
+// PhaseDeviationThreshold 稳态段与整体在 TTFT / TPS 上的相对差异（百分比）超过该值时，
+// 认为预热或收尾阶段明显拉偏了整体统计。
+const PhaseDeviationThreshold = 10.0
+
+// DefaultPhaseRatio 未配置 phase_ratio 时预热段与收尾段各占的比例。
+const DefaultPhaseRatio = 0.1
+
+// PhaseStats 按完成时间先后划分的三段统计。
+type PhaseStats struct {
+	Warmup   PhaseMetrics `json:"warmup"`   // 最先完成的一段
+	Steady   PhaseMetrics `json:"steady"`   // 中间段
+	Cooldown PhaseMetrics `json:"cooldown"` // 最后完成的一段
+
+	// SteadyDeviation 稳态段与整体在平均 TTFT、平均 TPS 上相对差异的较大者（百分比）
+	SteadyDeviation float64 `json:"steady_deviation"`
+}
+
+// Skewed 返回稳态段与整体的差异是否超过 PhaseDeviationThreshold。
+func (p *PhaseStats) Skewed() bool {
+	return p != nil && p.SteadyDeviation > PhaseDeviationThreshold
+}
+
+// PhaseMetrics 一段请求的统计。
+type PhaseMetrics struct {
+	Requests int           `json:"requests"` // 该段的成功请求数
+	AvgTTFT  time.Duration `json:"avg_ttft"` // 平均 TTFT
+	AvgTPS   float64       `json:"avg_tps"`  // 平均 TPS
+}
+
+// ColdStartThreshold 首请求在 TTFT / 总耗时上比其余请求平均值慢的幅度（百分比）超过该值时，认为冷启动明显。
+const ColdStartThreshold = 50.0
+
+// FirstRequestMetrics 第 1 个完成的成功请求的指标，以及去除首请求后其余成功请求的平均值。
+// 无论是否预热，第一个到达后端实例的请求往往更慢（建连、加载、缓存未命中）。
+type FirstRequestMetrics struct {
+	Index            int           `json:"index"`      // 首请求的全局序号
+	StartedAt        time.Time     `json:"started_at"` // 首请求的发出时间
+	TTFT             time.Duration `json:"ttft"`
+	TotalTime        time.Duration `json:"total_time"`
+	TPS              float64       `json:"tps"`
+	DNSTime          time.Duration `json:"dns_time"`
+	ConnectTime      time.Duration `json:"connect_time"`
+	TLSHandshakeTime time.Duration `json:"tls_handshake_time"`
+
+	// 其余成功请求的数量与平均值
+	WarmRequests     int           `json:"warm_requests"`
+	WarmAvgTTFT      time.Duration `json:"warm_avg_ttft"`
+	WarmAvgTotalTime time.Duration `json:"warm_avg_total_time"`
+	WarmAvgTPS       float64       `json:"warm_avg_tps"`
+
+	// Deviation 首请求比其余请求平均值在 TTFT、总耗时上慢的幅度的较大者（百分比），不慢于平均值时为 0
+	Deviation float64 `json:"deviation"`
+}
+
+// ColdStart 返回首请求比其余请求慢的幅度是否超过 ColdStartThreshold。
+func (f *FirstRequestMetrics) ColdStart() bool {
+	return f != nil && f.Deviation > ColdStartThreshold
+}
+
+// BurstStats 突发模式下一批请求的统计。
+type BurstStats struct {
+	Index    int           `json:"index"`    // 批次序号，从 0 开始
+	Start    time.Duration `json:"start"`    // 该批发出时间，相对本批请求开始
+	Requests int           `json:"requests"` // 实际发出的请求数
+	Success  int           `json:"success"`  // 成功请求数
+	Duration time.Duration `json:"duration"` // 整批完成耗时（批内最慢请求的总耗时）
+	AvgTTFT  time.Duration `json:"avg_ttft"` // 批内成功请求的平均 TTFT
+	MaxTTFT  time.Duration `json:"max_ttft"` // 批内成功请求的最大 TTFT
+}
+
+// ConcurrencyChange 一次运行期间的并发调整。
+type ConcurrencyChange struct {
+	At          time.Duration `json:"at"`          // 相对本批请求开始的时间
+	Concurrency int           `json:"concurrency"` // 调整后的并发上限
+}
+
+// RequestIDCheckStats 请求 ID 回传校验的统计。
+type RequestIDCheckStats struct {
+	Echoed     int `json:"echoed"`     // 响应回传了 X-Request-Id 的请求数
+	Mismatched int `json:"mismatched"` // 回传值与发送值不一致的可疑请求数
+}
+
+// FallbackStats 备用模型的触发统计。
+type FallbackStats struct {
+	Triggered int `json:"triggered"` // 主模型失败、改用备用模型重试的请求数
+	Succeeded int `json:"succeeded"` // 其中备用模型成功的请求数
+}
+
+// NetworkProbe 一次基线网络探测：TCP 连接（含 DNS 解析）与 TLS 握手耗时，不发业务请求。
+type NetworkProbe struct {
+	Offset  time.Duration `json:"offset"`          // 相对本批请求开始的时间
+	Connect time.Duration `json:"connect"`         // TCP 连接耗时（含 DNS 解析）
+	TLS     time.Duration `json:"tls,omitempty"`   // TLS 握手耗时，http / grpc 端点为 0
+	Error   string        `json:"error,omitempty"` // 探测失败原因，失败的探测不计入延迟统计
+}
+
+// Latency 探测的总耗时（连接 + 握手）
+func (p NetworkProbe) Latency() time.Duration {
+	return p.Connect + p.TLS
+}
+
+// 网络探测结论取值
+const (
+	ProbeVerdictServer  = "server"  // 探测延迟稳定而请求延迟恶化，疑似服务端原因
+	ProbeVerdictNetwork = "network" // 探测延迟与请求延迟同步恶化，疑似网络原因
+)
+
+// NetworkProbeSummary 基线网络探测的汇总，以及与业务请求网络指标的对比。
+type NetworkProbeSummary struct {
+	Samples    int           `json:"samples"`           // 探测次数
+	Errors     int           `json:"errors"`            // 失败次数
+	Min        time.Duration `json:"min"`               // 成功探测延迟（连接 + 握手）的最小值
+	Avg        time.Duration `json:"avg"`               // 平均值
+	Max        time.Duration `json:"max"`               // 最大值
+	RequestAvg time.Duration `json:"request_avg"`       // 业务请求的平均 TCP 连接 + TLS 握手耗时
+	Verdict    string        `json:"verdict,omitempty"` // ProbeVerdictServer / ProbeVerdictNetwork，无法判断时为空
+}
+
+// RunEnvironment 执行本次运行的环境信息。
+type RunEnvironment struct {
+	Hostname   string `json:"hostname,omitempty"`
+	OS         string `json:"os"`                    // runtime.GOOS
+	Arch       string `json:"arch"`                  // runtime.GOARCH
+	AitVersion string `json:"ait_version,omitempty"` // ait 版本
+	SourceIP   string `json:"source_ip,omitempty"`   // 本机出口公网 IP，未检测到时为空
+}
+
+// TargetIPStats 单个目标 IP 的统计。
+type TargetIPStats struct {
+	Count        int           `json:"count"`          // 连接到该 IP 的请求数（含失败请求）
+	AvgTotalTime time.Duration `json:"avg_total_time"` // 成功请求的平均总耗时
+	AvgTTFT      time.Duration `json:"avg_ttft"`       // 成功请求的平均 TTFT
+}
+
+// TokenBucket 直方图中的一个桶：token 数落在 [Min, Max) 的请求数。
+type TokenBucket struct {
+	Min   int `json:"min"`
+	Max   int `json:"max"`
+	Count int `json:"count"`
+}
+
+// SLAResult 一条 SLA 的评估结果。
+type SLAResult struct {
+	Expr      string  `json:"expr"`      // SLA 表达式
+	Target    float64 `json:"target"`    // 目标达标率（%），即表达式中的 p
+	Rate      float64 `json:"rate"`      // 实际达标率（%）
+	Compliant int     `json:"compliant"` // 达标请求数
+	Total     int     `json:"total"`     // 参与评估的请求数（失败请求计为不达标）
+	Met       bool    `json:"met"`       // 是否达到目标达标率
+}
+
+// SuspiciousContent 一条疑似异常响应的摘要。
+type SuspiciousContent struct {
+	Reason  string `json:"reason"`  // 异常类型：invalid_utf8 / replacement_chars / repetition / whitespace
+	Excerpt string `json:"excerpt"` // 响应正文开头片段
+}
+
+// EndpointStats 多端点轮询时单个端点的统计。
+type EndpointStats struct {
+	Endpoint     string        `json:"endpoint"`             // 端点地址
+	TargetIPs    []string      `json:"target_ips,omitempty"` // 实际连接到的 IP
+	Requests     int           `json:"requests"`             // 分配到该端点的请求数
+	SuccessRate  float64       `json:"success_rate"`         // 成功率 (%)
+	AvgTotalTime time.Duration `json:"avg_total_time"`       // 成功请求的平均总耗时
+	AvgTTFT      time.Duration `json:"avg_ttft"`             // 成功请求的平均 TTFT
+	AvgTPS       float64       `json:"avg_tps"`              // 成功请求的平均输出 TPS
+}
+
+// TokenBudgetStats 一次运行的 token 预算使用情况。
+type TokenBudgetStats struct {
+	Budget    int64 `json:"budget"`    // 预算上限（input+output token）
+	Used      int64 `json:"used"`      // 实际消耗
+	Exhausted bool  `json:"exhausted"` // 是否因预算耗尽提前停止派发
+}
+
+// BaselineResult 一次运行与基线的对比结果。
+type BaselineResult struct {
+	Path      string        `json:"path"`              // 基线文件路径
+	Created   bool          `json:"created"`           // 本次运行是否新建了基线（此时不做对比）
+	Threshold float64       `json:"threshold"`         // 回退阈值（百分比）
+	Regressed bool          `json:"regressed"`         // 是否有指标回退超过阈值
+	Metrics   []MetricDelta `json:"metrics,omitempty"` // 各指标对比明细
+	Error     string        `json:"error,omitempty"`   // 读写基线失败时的错误信息
+}
+
+// MetricDelta 单个指标相对基线的变化。
+type MetricDelta struct {
+	Metric    string  `json:"metric"`    // 指标名，与报告 JSON 字段名一致
+	Baseline  float64 `json:"baseline"`  // 基线值（耗时类为毫秒）
+	Current   float64 `json:"current"`   // 本次值（耗时类为毫秒）
+	Change    float64 `json:"change"`    // 相对基线的变化（百分比，正数表示数值变大）
+	Regressed bool    `json:"regressed"` // 是否朝不利方向变化且超过阈值
+
+	// 基于两次运行原始样本的 Welch's t 检验，任一方没有样本（如旧版基线）时为空：PValue 为双侧 p 值，
+	// Significance 为 "**"（p<0.01）、"*"（p<0.05）或 "n.s."（差异不显著），LowSamples 表示样本少于 30 个、结论不可靠
+	PValue       float64 `json:"p_value,omitempty"`
+	Significance string  `json:"significance,omitempty"`
+	LowSamples   bool    `json:"low_samples,omitempty"`
+}
+
+// AdaptiveStats 自适应限流模式下的速率控制统计。
+type AdaptiveStats struct {
+	ThrottledResponses int           `json:"throttled_responses"` // 收到的限流响应数（429 / Retry-After）
+	Retries            int           `json:"retries"`             // 因限流发起的重试次数
+	ThrottleLostTime   time.Duration `json:"throttle_lost_time"`  // 因限流退避暂停发送的累计时长
+	MaxStableRate      float64       `json:"max_stable_rate"`     // 实际达到的最大稳定发送速率（req/s）
+	FinalRate          float64       `json:"final_rate"`          // 结束时的限速（req/s），0 表示未限速
+	Timeline           []RateChange  `json:"timeline,omitempty"`  // 速率变化时间线
+
+	// RateLimitSlowdowns 因响应头剩余配额（x-ratelimit-remaining 等）偏低而主动降速 / 暂停的次数
+	RateLimitSlowdowns int `json:"ratelimit_slowdowns,omitempty"`
+}
+
+// RateChange 一次发送速率调整。
+type RateChange struct {
+	Offset time.Duration `json:"offset"` // 相对运行开始的时间
+	Rate   float64       `json:"rate"`   // 调整后的限速（req/s）
+	Reason string        `json:"reason"` // throttle：限流降速；recover：恢复升速；ratelimit：剩余配额偏低主动降速
+}
+
+// SelfStats ait 进程在一次运行期间的自身资源占用，用于判断结果是否受压测工具本身限制。
+// 峰值为运行期间周期采样所得；累计值为运行结束时相对开始时的增量。
+type SelfStats struct {
+	PeakGoroutines int           `json:"peak_goroutines"` // goroutine 峰值
+	PeakHeapAlloc  uint64        `json:"peak_heap_alloc"` // 堆内存占用峰值（字节）
+	PeakSys        uint64        `json:"peak_sys"`        // 向操作系统申请的内存峰值（字节）
+	TotalAlloc     uint64        `json:"total_alloc"`     // 运行期间累计分配字节数
+	Mallocs        uint64        `json:"mallocs"`         // 运行期间累计分配对象数
+	NumGC          uint32        `json:"num_gc"`          // 运行期间 GC 次数
+	GCPauseTotal   time.Duration `json:"gc_pause_total"`  // 运行期间 GC 累计停顿
+	Samples        int           `json:"samples"`         // 采样次数
+
+	// 运行开始时的 goroutine 数，与运行结束（已释放连接）后比开始时多出的 goroutine 数
+	BaselineGoroutines int `json:"baseline_goroutines,omitempty"`
+	ResidualGoroutines int `json:"residual_goroutines,omitempty"`
+
+	// 时间线：开始时、此后每 SelfTimelineInterval 以及结束时各一次采样，用于长稳运行的起止对比与增长趋势判断
+	Timeline []SelfSample `json:"timeline,omitempty"`
+
+	// goroutine 数 / 堆内存在时间线上持续增长且增幅超过阈值，见 SelfGrowthMinSamples
+	GoroutineGrowing bool `json:"goroutine_growing,omitempty"`
+	HeapGrowing      bool `json:"heap_growing,omitempty"`
+}
+
+// SelfSample 自监控时间线上的一次采样。
+type SelfSample struct {
+	At         time.Time `json:"at"`
+	Goroutines int       `json:"goroutines"`
+	HeapAlloc  uint64    `json:"heap_alloc"` // 堆内存占用（字节）
+	NumGC      uint32    `json:"num_gc"`     // 运行开始以来的 GC 次数
+}
+
+// 自监控时间线与持续增长告警的参数：时间线至少有 SelfGrowthMinSamples 个采样时，按时间均分为前中后三段，
+// 三段均值依次递增、且末段均值相对首段的增幅超过对应比例时视为持续增长；goroutine 还须至少多出
+// SelfGoroutineGrowthMin 个，避免基数很小时的正常起伏被误判。
+const (
+	SelfTimelineInterval         = time.Minute
+	SelfGrowthMinSamples         = 6
+	SelfGoroutineGrowthThreshold = 0.5
+	SelfGoroutineGrowthMin       = 10
+	SelfHeapGrowthThreshold      = 0.5
+)
+
+// Growing 返回 goroutine 数或堆内存是否在运行期间持续增长。
+func (s *SelfStats) Growing() bool {
+	return s != nil && (s.GoroutineGrowing || s.HeapGrowing)
+}
+
+// ResidualGoroutineThreshold 运行结束后残留的 goroutine 超过该数量时视为疑似泄漏；
+// TUI、事件订阅等常驻 goroutine 会有少量起伏，留出余量。
+const ResidualGoroutineThreshold = 10
+
+// LeakSuspected 返回运行结束后残留的 goroutine 是否超过 ResidualGoroutineThreshold。
+func (s *SelfStats) LeakSuspected() bool {
+	return s != nil && s.ResidualGoroutines > ResidualGoroutineThreshold
 }
 
 type TaskDefinition struct {
@@ -278,6 +1081,7 @@ type TaskRunSummary struct {
 	Status               string        `json:"status"`
 	Protocol             string        `json:"protocol"`
 	Model                string        `json:"model"`
+	ModelAlias           string        `json:"model_alias,omitempty"`
 	StartedAt            time.Time     `json:"started_at"`
 	FinishedAt           time.Time     `json:"finished_at"`
 	SuccessRate          float64       `json:"success_rate"`
@@ -304,10 +1108,83 @@ type RequestMetrics struct {
 	ConnectTime      time.Duration `json:"connect_time"`
 	TLSTime          time.Duration `json:"tls_time"`
 	TargetIP         string        `json:"target_ip"`
+	ThinkingTime     time.Duration `json:"thinking_time,omitempty"`
 	ErrorMessage     string        `json:"error_message,omitempty"`
+	Prompt           string        `json:"prompt,omitempty"` // 实际发送的用户 prompt（raw 模式为原始请求体）
 	RequestBody      string        `json:"request_body,omitempty"`
 	ResponseBody     string        `json:"response_body,omitempty"`
 	Level            int           `json:"level,omitempty"`
+
+	// 自适应限流模式下该请求因限流重试的次数
+	ThrottleRetries int `json:"throttle_retries,omitempty"`
+
+	// 链路追踪：本地生成的 trace id 与供应商返回的请求 ID，用于向供应商排障
+	ClientRequestID string `json:"client_request_id,omitempty"`
+	ServerRequestID string `json:"request_id,omitempty"`
+
+	// 生成结束原因（stop / length / end_turn / max_tokens 等，原样记录供应商返回值）
+	FinishReason string `json:"finish_reason,omitempty"`
+
+	// 多端点轮询时该请求实际命中的端点
+	Endpoint string `json:"endpoint,omitempty"`
+
+	// 写入 prompt 缓存的输入 token 数（Anthropic cache_creation_input_tokens）
+	CacheCreationTokens int `json:"cache_creation_tokens,omitempty"`
+
+	// 响应中的工具调用次数（仅配置 tools_file 时可能非零）
+	ToolCallCount int `json:"tool_call_count,omitempty"`
+
+	// 开启 verify_request_id 时，响应回传的 X-Request-Id 与发送值不一致
+	RequestIDMismatch bool `json:"request_id_mismatch,omitempty"`
+
+	// 主模型失败后由备用模型重试的请求
+	Fallback bool `json:"fallback,omitempty"`
+
+	// 重放运行时该请求在原运行中的序号，便于与原运行的请求对回
+	OriginIndex *int `json:"origin_index,omitempty"`
+
+	// Anthropic 流式响应中各 SSE event 类型首次出现的时间与各 content block 的起止时间（相对请求开始）
+	EventTimes    map[string]time.Duration `json:"event_times,omitempty"`
+	ContentBlocks []ContentBlockTiming     `json:"content_blocks,omitempty"`
+
+	// 网络错误（未收到响应）的类别：dns / connection_refused / connect_timeout / tls 等
+	NetErrorKind string `json:"net_error_kind,omitempty"`
+	// 连接建立阶段失败后的重试次数（开启 connect_retries 时）
+	ConnectRetries int `json:"connect_retries,omitempty"`
+
+	// 请求成功但正文为空（如内容被安全策略过滤）
+	EmptyContent bool `json:"empty_content,omitempty"`
+
+	// 请求发出的时间；Cold 表示该请求是本次运行第 1 个完成的成功请求（冷请求），其余为热请求
+	StartedAt time.Time `json:"started_at,omitempty"`
+	Cold      bool      `json:"cold,omitempty"`
+
+	// 首字节到达时间（TTFB）：收到响应首字节的时间，与 TTFT 的差值为首个内容 token 之前的预处理开销
+	TTFB time.Duration `json:"ttfb,omitempty"`
+
+	// 本次请求 DNS 解析返回的全部 IP，复用连接时为空
+	ResolvedIPs []string `json:"resolved_ips,omitempty"`
+
+	// 派发给执行 goroutine 到真正开始执行的本地排队时间
+	QueueWaitTime time.Duration `json:"queue_wait_time,omitempty"`
+
+	// 因参数不兼容改写请求体重试时的改动说明，未改写时为空
+	AdaptedParams string `json:"adapted_params,omitempty"`
+}
+
+// ContentBlockTiming 流式响应中一个 content block 的类型与起止时间（相对请求开始）。
+type ContentBlockTiming struct {
+	Index int           `json:"index"`
+	Type  string        `json:"type"`           // thinking / text / tool_use 等
+	Start time.Duration `json:"start"`          // content_block_start 到达时间
+	Stop  time.Duration `json:"stop,omitempty"` // content_block_stop 到达时间，流提前结束时为 0
+}
+
+// TokenChunk 流式响应中一个增量 chunk 的到达时刻（相对请求开始）与内容长度，Tokens 按字符粗略估算。
+type TokenChunk struct {
+	Offset time.Duration
+	Chars  int
+	Tokens int
 }
 
 type TurboConfig struct {
@@ -334,6 +1211,139 @@ type TurboLevelResult struct {
 	StdDevTPS     float64       `json:"stddev_tps"`
 	Stable        bool          `json:"stable"`
 	StopReason    string        `json:"stop_reason,omitempty"`
+
+	ErrorRate    float64       `json:"error_rate"`     // 错误率（0-1），即 1 - SuccessRate
+	P99TotalTime time.Duration `json:"p99_total_time"` // 该档位请求总耗时的 P99
+}
+
+// ConcurrencyKnee 并发扫描中错误率 / 延迟开始显著上升的拐点，以第一个档位为基线判定。
+// 对应拐点未出现时并发为 0。
+type ConcurrencyKnee struct {
+	ErrorRateConcurrency int `json:"error_rate_concurrency,omitempty"` // 错误率比基线上升超过阈值的第一个并发档位
+	LatencyConcurrency   int `json:"latency_concurrency,omitempty"`    // P99 延迟超过基线倍数的第一个并发档位
+	SafeConcurrency      int `json:"safe_concurrency"`                 // 最早拐点的前一个档位，即建议的安全并发上限
+}
+
+// StreamMode 取值，用于 A/B 对比结果的元数据标注。
+const (
+	StreamModeStream    = "stream"
+	StreamModeNonStream = "non-stream"
+)
+
+// HTTPVersionCompareResult HTTP/1.1 与 HTTP/2 A/B 对比结果。
+type HTTPVersionCompareResult struct {
+	HTTP1 *ReportData `json:"http1,omitempty"`
+	HTTP2 *ReportData `json:"http2,omitempty"`
+}
+
+// Reports 按"HTTP/1.1、HTTP/2"顺序返回已完成的各轮结果，供报告生成使用。
+func (r *HTTPVersionCompareResult) Reports() []ReportData {
+	if r == nil {
+		return nil
+	}
+	var out []ReportData
+	if r.HTTP1 != nil {
+		out = append(out, *r.HTTP1)
+	}
+	if r.HTTP2 != nil {
+		out = append(out, *r.HTTP2)
+	}
+	return out
+}
+
+// TotalRequests 返回两轮请求总数。
+func (r *HTTPVersionCompareResult) TotalRequests() int {
+	total := 0
+	for _, report := range r.Reports() {
+		total += report.TotalRequests
+	}
+	return total
+}
+
+// StreamCompareResult 流式与非流式 A/B 对比结果。
+type StreamCompareResult struct {
+	Stream    *ReportData `json:"stream,omitempty"`
+	NonStream *ReportData `json:"non_stream,omitempty"`
+}
+
+// Reports 按"流式、非流式"顺序返回已完成的各轮结果，供报告生成使用。
+func (r *StreamCompareResult) Reports() []ReportData {
+	if r == nil {
+		return nil
+	}
+	var out []ReportData
+	if r.Stream != nil {
+		out = append(out, *r.Stream)
+	}
+	if r.NonStream != nil {
+		out = append(out, *r.NonStream)
+	}
+	return out
+}
+
+// TotalRequests 返回两轮请求总数。
+func (r *StreamCompareResult) TotalRequests() int {
+	total := 0
+	for _, report := range r.Reports() {
+		total += report.TotalRequests
+	}
+	return total
+}
+
+// InputLengthSweepResult 输入长度扫描结果，Points 按配置的长度顺序排列。
+type InputLengthSweepResult struct {
+	Points []*ReportData `json:"points"`
+}
+
+// Reports 按扫描顺序返回已完成的各轮结果，供报告生成使用。
+func (r *InputLengthSweepResult) Reports() []ReportData {
+	if r == nil {
+		return nil
+	}
+	out := make([]ReportData, 0, len(r.Points))
+	for _, point := range r.Points {
+		if point != nil {
+			out = append(out, *point)
+		}
+	}
+	return out
+}
+
+// TotalRequests 返回各轮请求总数。
+func (r *InputLengthSweepResult) TotalRequests() int {
+	total := 0
+	for _, report := range r.Reports() {
+		total += report.TotalRequests
+	}
+	return total
+}
+
+// PromptFileRunsResult 按 prompt 文件分场景运行的结果，Scenarios 按文件名顺序排列，每轮的 Scenario 为场景名。
+type PromptFileRunsResult struct {
+	Scenarios []*ReportData `json:"scenarios"`
+}
+
+// Reports 按场景顺序返回已完成的各轮结果，供报告生成使用。
+func (r *PromptFileRunsResult) Reports() []ReportData {
+	if r == nil {
+		return nil
+	}
+	out := make([]ReportData, 0, len(r.Scenarios))
+	for _, scenario := range r.Scenarios {
+		if scenario != nil {
+			out = append(out, *scenario)
+		}
+	}
+	return out
+}
+
+// TotalRequests 返回各轮请求总数。
+func (r *PromptFileRunsResult) TotalRequests() int {
+	total := 0
+	for _, report := range r.Reports() {
+		total += report.TotalRequests
+	}
+	return total
 }
 
 type TurboResult struct {
@@ -347,6 +1357,8 @@ type TurboResult struct {
 	Protocol             string             `json:"protocol"`
 	EndpointURL          string             `json:"endpoint_url"`
 	Timestamp            string             `json:"timestamp"`
+
+	Knee *ConcurrencyKnee `json:"knee,omitempty"` // 错误率 / 延迟拐点，档位不足两个或未出现拐点时为 nil
 }
 
 type IntegrityConfig struct {
@@ -457,6 +1469,7 @@ func (r *ReportData) MarshalJSON() ([]byte, error) {
 		AvgTotalTime        string `json:"avg_total_time"`
 		MinTotalTime        string `json:"min_total_time"`
 		MaxTotalTime        string `json:"max_total_time"`
+		P99TotalTime        string `json:"p99_total_time"`
 		AvgDNSTime          string `json:"avg_dns_time"`
 		MinDNSTime          string `json:"min_dns_time"`
 		MaxDNSTime          string `json:"max_dns_time"`
@@ -475,12 +1488,16 @@ func (r *ReportData) MarshalJSON() ([]byte, error) {
 		StdDevTotalTime     string `json:"stddev_total_time"`
 		StdDevTTFT          string `json:"stddev_ttft"`
 		StdDevTPOT          string `json:"stddev_tpot"`
+		AvgThinkingTime     string `json:"avg_thinking_time"`
+		MinThinkingTime     string `json:"min_thinking_time"`
+		MaxThinkingTime     string `json:"max_thinking_time"`
 	}{
 		Alias:               (*Alias)(r),
 		TotalTime:           r.TotalTime.String(),
 		AvgTotalTime:        r.AvgTotalTime.String(),
 		MinTotalTime:        r.MinTotalTime.String(),
 		MaxTotalTime:        r.MaxTotalTime.String(),
+		P99TotalTime:        r.P99TotalTime.String(),
 		AvgDNSTime:          r.AvgDNSTime.String(),
 		MinDNSTime:          r.MinDNSTime.String(),
 		MaxDNSTime:          r.MaxDNSTime.String(),
@@ -499,6 +1516,9 @@ func (r *ReportData) MarshalJSON() ([]byte, error) {
 		StdDevTotalTime:     r.StdDevTotalTime.String(),
 		StdDevTTFT:          formatTTFT(r.StdDevTTFT, r.IsStream),
 		StdDevTPOT:          formatTPOT(r.StdDevTPOT, r.IsStream),
+		AvgThinkingTime:     r.AvgThinkingTime.String(),
+		MinThinkingTime:     r.MinThinkingTime.String(),
+		MaxThinkingTime:     r.MaxThinkingTime.String(),
 	})
 }
 
@@ -512,6 +1532,7 @@ func (r *ReportData) UnmarshalJSON(data []byte) error {
 		AvgTotalTime        string `json:"avg_total_time"`
 		MinTotalTime        string `json:"min_total_time"`
 		MaxTotalTime        string `json:"max_total_time"`
+		P99TotalTime        string `json:"p99_total_time"`
 		AvgDNSTime          string `json:"avg_dns_time"`
 		MinDNSTime          string `json:"min_dns_time"`
 		MaxDNSTime          string `json:"max_dns_time"`
@@ -530,6 +1551,9 @@ func (r *ReportData) UnmarshalJSON(data []byte) error {
 		StdDevTotalTime     string `json:"stddev_total_time"`
 		StdDevTTFT          string `json:"stddev_ttft"`
 		StdDevTPOT          string `json:"stddev_tpot"`
+		AvgThinkingTime     string `json:"avg_thinking_time"`
+		MinThinkingTime     string `json:"min_thinking_time"`
+		MaxThinkingTime     string `json:"max_thinking_time"`
 	}{Alias: (*Alias)(r)}
 
 	if err := json.Unmarshal(data, aux); err != nil {
@@ -548,6 +1572,7 @@ func (r *ReportData) UnmarshalJSON(data []byte) error {
 	r.AvgTotalTime = parseDur(aux.AvgTotalTime)
 	r.MinTotalTime = parseDur(aux.MinTotalTime)
 	r.MaxTotalTime = parseDur(aux.MaxTotalTime)
+	r.P99TotalTime = parseDur(aux.P99TotalTime)
 	r.AvgDNSTime = parseDur(aux.AvgDNSTime)
 	r.MinDNSTime = parseDur(aux.MinDNSTime)
 	r.MaxDNSTime = parseDur(aux.MaxDNSTime)
@@ -566,6 +1591,9 @@ func (r *ReportData) UnmarshalJSON(data []byte) error {
 	r.StdDevTotalTime = parseDur(aux.StdDevTotalTime)
 	r.StdDevTTFT = parseDur(aux.StdDevTTFT)
 	r.StdDevTPOT = parseDur(aux.StdDevTPOT)
+	r.AvgThinkingTime = parseDur(aux.AvgThinkingTime)
+	r.MinThinkingTime = parseDur(aux.MinThinkingTime)
+	r.MaxThinkingTime = parseDur(aux.MaxThinkingTime)
 	return nil
 }
 
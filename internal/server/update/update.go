@@ -0,0 +1,102 @@
+// Package update 启动时检查 GitHub 上是否有更新的 ait 版本。
+package update
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yinxulai/ait/internal/server/network"
+)
+
+// DefaultReleaseURL GitHub releases API 中最新正式版本的地址。
+const DefaultReleaseURL = "https://api.github.com/repos/yinxulai/ait/releases/latest"
+
+// CheckTimeout 版本检查的总超时，超时视为没有新版本，不影响启动。
+const CheckTimeout = time.Second
+
+// Latest 请求 releaseURL（GitHub releases API 格式）并返回最新版本的 tag，如 "v1.2.3"。
+func Latest(ctx context.Context, client *http.Client, releaseURL string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, releaseURL, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("releases API 返回 %d", resp.StatusCode)
+	}
+	var release struct {
+		TagName string `json:"tag_name"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
+		return "", err
+	}
+	if release.TagName == "" {
+		return "", fmt.Errorf("releases API 未返回 tag_name")
+	}
+	return release.TagName, nil
+}
+
+// Check 在 CheckTimeout 内检查 releaseURL 上是否有比 current 更新的版本，有则返回新版本的 tag，
+// 否则（包括开发版、网络失败、超时）返回空字符串。出站请求使用 network.TelemetryOptions 的网络配置。
+func Check(ctx context.Context, releaseURL, current string) string {
+	if _, ok := parseVersion(current); !ok {
+		return ""
+	}
+	ctx, cancel := context.WithTimeout(ctx, CheckTimeout)
+	defer cancel()
+	latest, err := Latest(ctx, network.TelemetryOptions().NewClient(CheckTimeout), releaseURL)
+	if err != nil || !Newer(latest, current) {
+		return ""
+	}
+	return latest
+}
+
+// Newer 判断 latest 是否比 current 更新。版本号按 major.minor.patch 比较，可带 "v" 前缀；
+// 任一方无法解析（如开发版 "dev"）时返回 false。
+func Newer(latest, current string) bool {
+	l, ok := parseVersion(latest)
+	if !ok {
+		return false
+	}
+	c, ok := parseVersion(current)
+	if !ok {
+		return false
+	}
+	for i := range l {
+		if l[i] != c[i] {
+			return l[i] > c[i]
+		}
+	}
+	return false
+}
+
+// parseVersion 解析 "v1.2.3" / "1.2" 形式的版本号，忽略 "-rc.1"、"+build" 等后缀。
+func parseVersion(v string) ([3]int, bool) {
+	var out [3]int
+	v = strings.TrimPrefix(strings.TrimSpace(v), "v")
+	if i := strings.IndexAny(v, "-+"); i >= 0 {
+		v = v[:i]
+	}
+	parts := strings.Split(v, ".")
+	if v == "" || len(parts) > 3 {
+		return out, false
+	}
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return out, false
+		}
+		out[i] = n
+	}
+	return out, true
+}
@@ -0,0 +1,72 @@
+package update
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestNewer(t *testing.T) {
+	cases := []struct {
+		latest, current string
+		want            bool
+	}{
+		{"v1.2.3", "v1.2.2", true},
+		{"v1.10.0", "v1.9.9", true},
+		{"v2.0", "1.9.9", true},
+		{"v1.2.3", "v1.2.3", false},
+		{"v1.2.3", "v1.3.0", false},
+		{"v1.2.4-rc.1", "v1.2.3", true},
+		{"v1.2.3", "dev", false},
+		{"latest", "v1.0.0", false},
+	}
+	for _, c := range cases {
+		if got := Newer(c.latest, c.current); got != c.want {
+			t.Errorf("Newer(%q, %q) = %v, want %v", c.latest, c.current, got, c.want)
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"tag_name":"v1.5.0","name":"v1.5.0"}`))
+	}))
+	defer srv.Close()
+
+	if got := Check(context.Background(), srv.URL, "v1.4.2"); got != "v1.5.0" {
+		t.Errorf("Check(older) = %q, want v1.5.0", got)
+	}
+	if got := Check(context.Background(), srv.URL, "v1.5.0"); got != "" {
+		t.Errorf("Check(same) = %q, want empty", got)
+	}
+	if got := Check(context.Background(), srv.URL, "dev"); got != "" {
+		t.Errorf("Check(dev) = %q, want empty", got)
+	}
+}
+
+func TestCheck_FailsSilently(t *testing.T) {
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(3 * time.Second):
+		}
+	}))
+	defer slow.Close()
+	broken := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "rate limited", http.StatusForbidden)
+	}))
+	defer broken.Close()
+
+	start := time.Now()
+	if got := Check(context.Background(), slow.URL, "v1.0.0"); got != "" {
+		t.Errorf("Check(slow) = %q, want empty", got)
+	}
+	if elapsed := time.Since(start); elapsed > 2*CheckTimeout {
+		t.Errorf("Check(slow) took %v, want about %v", elapsed, CheckTimeout)
+	}
+	if got := Check(context.Background(), broken.URL, "v1.0.0"); got != "" {
+		t.Errorf("Check(403) = %q, want empty", got)
+	}
+}
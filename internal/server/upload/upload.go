@@ -5,13 +5,17 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"math"
 	"net/http"
 	"net/url"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/yinxulai/ait/internal/server/client"
+	"github.com/yinxulai/ait/internal/server/clock"
 	"github.com/yinxulai/ait/internal/server/network"
+	"github.com/yinxulai/ait/internal/server/redact"
 	"github.com/yinxulai/ait/internal/server/types"
 )
 
@@ -38,6 +42,8 @@ type ReportUploadItem struct {
 	PerOutputTokenTime       float64 `json:"perOutputTokenTime"`       // 毫秒
 	FirstOutputTokenTime     int64   `json:"firstOutputTokenTime"`     // 毫秒
 	ErrorMessage             string  `json:"errorMessage"`
+	ClientRequestID          string  `json:"clientRequestId,omitempty"` // 本地 trace id（X-Client-Request-Id）
+	RequestID                string  `json:"requestId,omitempty"`       // 供应商返回的请求 ID
 }
 
 // Uploader 上传器结构体
@@ -46,6 +52,9 @@ type Uploader struct {
 	authToken string
 	userAgent string
 	client    *http.Client
+
+	sampleRate float64    // 成功请求的上报比例
+	rng        clock.Rand // 采样使用的随机源
 }
 
 var (
@@ -55,23 +64,65 @@ var (
 	UploadUserAgent = "yinxulai/ait"
 )
 
-// New 创建新的上传器实例
-func New() *Uploader {
+// DefaultSampleRate 默认的上报采样比例：高并发、大 count 时只上报约 10% 的成功请求，
+// 避免上报请求挤占带宽与连接、干扰测量本身。
+const DefaultSampleRate = 0.1
+
+var sampleRateBits atomic.Uint64
+
+func init() {
+	sampleRateBits.Store(math.Float64bits(DefaultSampleRate))
+}
+
+// SetSampleRate 设置本进程的上报采样比例，通常在启动时由 --upload-sample-rate 设置一次；
+// rate 取值 [0, 1]，0 表示不上报，1 表示全部上报。
+func SetSampleRate(rate float64) error {
+	if math.IsNaN(rate) || rate < 0 || rate > 1 {
+		return fmt.Errorf("采样比例必须在 [0, 1] 内，当前为 %g", rate)
+	}
+	sampleRateBits.Store(math.Float64bits(rate))
+	return nil
+}
+
+// SampleRate 返回当前的上报采样比例。
+func SampleRate() float64 {
+	return math.Float64frombits(sampleRateBits.Load())
+}
+
+// New 创建新的上传器实例。opts 为上报请求自己的代理与超时（默认 3 秒），
+// 不继承被测请求的 proxy_url / timeout；采样比例取自 SetSampleRate。
+func New(opts network.HTTPOptions) *Uploader {
 	return &Uploader{
-		baseURL:   UploadBaseURL,
-		authToken: UploadAuthToken,
-		userAgent: UploadUserAgent,
-		client: &http.Client{
-			Timeout: time.Second * 3,
-			Transport: &http.Transport{
-				MaxIdleConns:        10,
-				MaxIdleConnsPerHost: 5,
-				IdleConnTimeout:     30 * time.Second,
-			},
-		},
+		baseURL:    UploadBaseURL,
+		authToken:  UploadAuthToken,
+		userAgent:  UploadUserAgent,
+		client:     opts.NewClient(3 * time.Second),
+		sampleRate: SampleRate(),
+		rng:        clock.DefaultRand(),
 	}
 }
 
+// SetRand 替换采样使用的随机源，测试注入固定种子以得到确定的采样结果。
+func (u *Uploader) SetRand(r clock.Rand) {
+	u.rng = r
+}
+
+// Sampled 按采样比例随机决定本次请求是否上报，调用方在 UploadReport 之前判断。
+func (u *Uploader) Sampled() bool {
+	switch {
+	case u.sampleRate >= 1:
+		return true
+	case u.sampleRate <= 0:
+		return false
+	}
+	return u.rng.Float64() < u.sampleRate
+}
+
+// Enabled 报告本进程是否会上报遥测：构建时注入了上报地址与凭证，且采样比例大于 0。
+func Enabled() bool {
+	return (&Uploader{}).isValidURL(UploadBaseURL) && UploadAuthToken != "null" && SampleRate() > 0
+}
+
 // isValidURL 检查给定的字符串是否是一个有效的URL
 func (u *Uploader) isValidURL(urlStr string) bool {
 	if urlStr == "" || urlStr == "null" {
@@ -102,9 +153,9 @@ func (u *Uploader) convertResponseMetricsToUploadItem(taskID string, metrics *cl
 	var errorMessage string
 	successful := true
 
-	// 检查是否有错误
+	// 检查是否有错误；错误信息可能回显 prompt 内容，开启 --redact 时先脱敏
 	if metrics.ErrorMessage != "" {
-		errorMessage = metrics.ErrorMessage
+		errorMessage = redact.String(metrics.ErrorMessage)
 		successful = false
 	}
 
@@ -125,7 +176,7 @@ func (u *Uploader) convertResponseMetricsToUploadItem(taskID string, metrics *cl
 
 	return ReportUploadItem{
 		TaskID:                   taskID,
-		Thinking:                 input.Thinking,
+		Thinking:                 input.ThinkingEnabled(),
 		ModelKey:                 nil, // 未知模型
 		Reporter:                 u.userAgent,
 		Protocol:                 strings.ToUpper(input.Protocol),
@@ -145,6 +196,8 @@ func (u *Uploader) convertResponseMetricsToUploadItem(taskID string, metrics *cl
 		PerOutputTokenTime:       perOutputTokenTime,
 		FirstOutputTokenTime:     metrics.TimeToFirstToken.Nanoseconds() / 1e6, // 转换为毫秒
 		ErrorMessage:             errorMessage,
+		ClientRequestID:          metrics.ClientRequestID,
+		RequestID:                metrics.ServerRequestID,
 	}
 }
 
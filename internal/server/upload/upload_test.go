@@ -1,17 +1,23 @@
 package upload
 
 import (
+	"context"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/yinxulai/ait/internal/server/client"
+	"github.com/yinxulai/ait/internal/server/clock"
+	"github.com/yinxulai/ait/internal/server/network"
+	"github.com/yinxulai/ait/internal/server/redact"
 	"github.com/yinxulai/ait/internal/server/types"
 )
 
 func TestNew(t *testing.T) {
-	uploader := New()
+	uploader := New(network.HTTPOptions{})
 
 	// 测试基本结构是否正确初始化
 	if uploader == nil {
@@ -204,6 +210,8 @@ func TestUploader_convertResponseMetricsToUploadItem(t *testing.T) {
 				TimeToFirstToken: time.Millisecond * 0,
 				TargetIP:         "",
 				ErrorMessage:     "Connection timeout",
+				ClientRequestID:  "trace-1",
+				ServerRequestID:  "req_1",
 			},
 			input: types.Input{
 				Protocol: "anthropic",
@@ -233,6 +241,8 @@ func TestUploader_convertResponseMetricsToUploadItem(t *testing.T) {
 				PerOutputTokenTime:       0,
 				FirstOutputTokenTime:     0,
 				ErrorMessage:             "Connection timeout",
+				ClientRequestID:          "trace-1",
+				RequestID:                "req_1",
 			},
 		},
 		{
@@ -357,10 +367,31 @@ func TestUploader_convertResponseMetricsToUploadItem(t *testing.T) {
 			if result.ErrorMessage != tt.expected.ErrorMessage {
 				t.Errorf("ErrorMessage: got %q, expected %q", result.ErrorMessage, tt.expected.ErrorMessage)
 			}
+			if result.ClientRequestID != tt.expected.ClientRequestID || result.RequestID != tt.expected.RequestID {
+				t.Errorf("trace ids: got %q/%q, expected %q/%q", result.ClientRequestID, result.RequestID, tt.expected.ClientRequestID, tt.expected.RequestID)
+			}
 		})
 	}
 }
 
+func TestUploader_convertResponseMetricsToUploadItem_Redact(t *testing.T) {
+	r, err := redact.New(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	redact.Enable(r)
+	t.Cleanup(func() { redact.Enable(nil) })
+
+	metrics := &client.ResponseMetrics{ErrorMessage: "HTTP 400: prompt 含有身份证 110101199003071234"}
+	item := (&Uploader{}).convertResponseMetricsToUploadItem("task", metrics, types.Input{Protocol: "openai", Model: "m"})
+	if item.ErrorMessage != "HTTP 400: prompt 含有身份证 [REDACTED]" {
+		t.Errorf("ErrorMessage = %q", item.ErrorMessage)
+	}
+	if metrics.ErrorMessage != "HTTP 400: prompt 含有身份证 110101199003071234" {
+		t.Error("redaction should not modify the original metrics")
+	}
+}
+
 func TestUploader_UploadReport(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -536,3 +567,142 @@ func containsHelper(s, substr string) bool {
 	}
 	return false
 }
+
+// newRecordingProxy 创建一个记录请求目标 host 的 HTTP 代理桩，respond 决定返回内容。
+func newRecordingProxy(t *testing.T, respond func(w http.ResponseWriter, r *http.Request)) (*httptest.Server, func() []string) {
+	t.Helper()
+	var mu sync.Mutex
+	var hosts []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		hosts = append(hosts, r.Host)
+		mu.Unlock()
+		respond(w, r)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), hosts...)
+	}
+}
+
+func TestNew_TelemetryTimeout(t *testing.T) {
+	uploader := New(network.HTTPOptions{Timeout: 7 * time.Second})
+	if uploader.client.Timeout != 7*time.Second {
+		t.Errorf("client timeout = %v, want 7s", uploader.client.Timeout)
+	}
+}
+
+func TestSetSampleRate(t *testing.T) {
+	t.Cleanup(func() { _ = SetSampleRate(DefaultSampleRate) })
+	if got := New(network.HTTPOptions{}).sampleRate; got != DefaultSampleRate {
+		t.Errorf("default sample rate = %v, want %v", got, DefaultSampleRate)
+	}
+	for _, rate := range []float64{-0.1, 1.5} {
+		if err := SetSampleRate(rate); err == nil {
+			t.Errorf("SetSampleRate(%g) should fail", rate)
+		}
+	}
+	if err := SetSampleRate(0.5); err != nil {
+		t.Fatalf("SetSampleRate(0.5): %v", err)
+	}
+	if got := New(network.HTTPOptions{}).sampleRate; got != 0.5 {
+		t.Errorf("sample rate = %v, want 0.5", got)
+	}
+}
+
+func TestEnabled(t *testing.T) {
+	baseURL, token := UploadBaseURL, UploadAuthToken
+	t.Cleanup(func() {
+		UploadBaseURL, UploadAuthToken = baseURL, token
+		_ = SetSampleRate(DefaultSampleRate)
+	})
+
+	UploadBaseURL, UploadAuthToken = "null", "null"
+	if Enabled() {
+		t.Error("Enabled() = true without injected upload URL")
+	}
+	UploadBaseURL, UploadAuthToken = "https://report.example.com", "token"
+	if !Enabled() {
+		t.Error("Enabled() = false with injected upload URL")
+	}
+	if err := SetSampleRate(0); err != nil {
+		t.Fatal(err)
+	}
+	if Enabled() {
+		t.Error("Enabled() = true with sample rate 0")
+	}
+}
+
+func TestUploader_Sampled(t *testing.T) {
+	u := &Uploader{sampleRate: 0.3}
+	u.SetRand(clock.NewRand(5))
+	expected := clock.NewRand(5)
+	for i := 0; i < 20; i++ {
+		if got, want := u.Sampled(), expected.Float64() < 0.3; got != want {
+			t.Fatalf("draw %d: Sampled() = %v, want %v", i, got, want)
+		}
+	}
+
+	if !(&Uploader{sampleRate: 1}).Sampled() {
+		t.Error("rate 1 should always upload")
+	}
+	if (&Uploader{sampleRate: 0}).Sampled() {
+		t.Error("rate 0 should never upload")
+	}
+}
+
+// 被测请求走任务的 proxy_url，上报与公网 IP 查询走遥测代理，两者互不串用。
+func TestUploader_TelemetryProxySeparateFromModelProxy(t *testing.T) {
+	modelProxy, modelHosts := newRecordingProxy(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"choices":[{"index":0,"message":{"role":"assistant","content":"hi"}}],"usage":{"prompt_tokens":3,"completion_tokens":2}}`)
+	})
+	telemetryProxy, telemetryHosts := newRecordingProxy(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/model/perf/report/upload" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		fmt.Fprint(w, "203.0.113.7") // 公网 IP 查询
+	})
+
+	opts := network.HTTPOptions{Proxy: telemetryProxy.URL}
+	network.SetTelemetryOptions(opts)
+	t.Cleanup(func() { network.SetTelemetryOptions(network.HTTPOptions{}) })
+
+	input := types.Input{
+		Protocol:    types.ProtocolOpenAICompletions,
+		EndpointURL: "http://model.test/v1/chat/completions",
+		ProxyURL:    modelProxy.URL,
+		Model:       "test-model",
+	}
+	modelClient, err := client.NewClient(input, nil)
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	metrics, err := modelClient.Request(context.Background(), "", "hello", false)
+	if err != nil || metrics.ErrorMessage != "" {
+		t.Fatalf("model request: err=%v metrics=%+v", err, metrics)
+	}
+
+	uploader := New(opts)
+	uploader.baseURL = "http://upload.test"
+	uploader.authToken = "token"
+	if err := uploader.UploadReport("task", metrics, input); err != nil {
+		t.Fatalf("UploadReport: %v", err)
+	}
+
+	if got := modelHosts(); len(got) != 1 || got[0] != "model.test" {
+		t.Errorf("model proxy hosts = %v, want [model.test]", got)
+	}
+	got := telemetryHosts()
+	if len(got) == 0 || got[len(got)-1] != "upload.test" {
+		t.Errorf("telemetry proxy hosts = %v, want upload.test last", got)
+	}
+	for _, host := range got {
+		if host == "model.test" {
+			t.Errorf("model request leaked to telemetry proxy: %v", got)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/yinxulai/ait/internal/server/network"
+	"github.com/yinxulai/ait/internal/server/redact"
+	"github.com/yinxulai/ait/internal/server/report"
+	"github.com/yinxulai/ait/internal/server/types"
+)
+
+// webhook 推送的单次请求超时与重试间隔
+var (
+	webhookTimeout    = 10 * time.Second
+	webhookRetryDelay = 2 * time.Second
+)
+
+var (
+	// pendingWebhooks 统计已结束但 webhook 尚未推送完的运行，进程退出前经 WaitWebhooks 等待
+	pendingWebhooks sync.WaitGroup
+	// webhookWarnOut 推送失败警告的输出位置
+	webhookWarnMu  sync.Mutex
+	webhookWarnOut io.Writer = os.Stdout
+)
+
+// trackRunWebhook 在运行标记为结束之前登记一次待推送的 webhook，返回实际推送的函数；
+// 这样运行结束事件一发出，进程退出流程就能通过 WaitWebhooks 等到推送（含重试）完成。
+func trackRunWebhook(taskDef types.TaskDefinition, snap *RunState) func() {
+	if taskDef.Input.WebhookURL == "" || snap == nil {
+		return func() {}
+	}
+	pendingWebhooks.Add(1)
+	return func() {
+		defer pendingWebhooks.Done()
+		sendRunWebhook(taskDef, snap)
+	}
+}
+
+// WaitWebhooks 等待所有已登记的 webhook 推送完成，最多等待 timeout；全部完成时返回 true。
+// 进程退出前调用，避免最后一个运行的推送与重试被 os.Exit 打断。
+func WaitWebhooks(timeout time.Duration) bool {
+	done := make(chan struct{})
+	go func() {
+		pendingWebhooks.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+// WebhookWaitTimeout 进程退出时等待 webhook 的上限：覆盖首次发送与每次重试的请求超时及重试间隔；
+// 单次超时取推送实际使用的值，--telemetry-timeout 调整后随之变化。
+func WebhookWaitTimeout() time.Duration {
+	attempts := time.Duration(report.WebhookRetries + 1)
+	timeout := network.TelemetryOptions().EffectiveTimeout(webhookTimeout)
+	return attempts*timeout + (attempts-1)*webhookRetryDelay
+}
+
+// warnWebhook 在 stdout 输出一行推送失败警告
+func warnWebhook(format string, args ...any) {
+	webhookWarnMu.Lock()
+	defer webhookWarnMu.Unlock()
+	fmt.Fprintf(webhookWarnOut, "警告: "+format+"\n", args...)
+}
+
+// sendRunWebhook 把标准运行的核心指标推送到任务配置的 webhook。
+// 推送失败在 stdout 输出警告，不影响运行状态与进程退出码。
+func sendRunWebhook(taskDef types.TaskDefinition, snap *RunState) {
+	input := taskDef.Input
+	if input.WebhookURL == "" || snap == nil {
+		return
+	}
+
+	var reports []types.ReportData
+	switch result := snap.ModeResult.(type) {
+	case *types.ReportData:
+		reports = []types.ReportData{*result}
+	case *types.StreamCompareResult:
+		reports = result.Reports()
+	case *types.InputLengthSweepResult:
+		reports = result.Reports()
+	case *types.HTTPVersionCompareResult:
+		reports = result.Reports()
+	case *types.PromptFileRunsResult:
+		reports = result.Reports()
+	}
+	if len(reports) == 0 {
+		return
+	}
+	var errorMessages []string
+	for _, req := range snap.Requests {
+		if req != nil && !req.Success {
+			errorMessages = append(errorMessages, redact.String(req.ErrorMessage))
+		}
+	}
+
+	summary := report.BuildWebhookSummary(taskDef.Name, string(snap.Status), reports, errorMessages,
+		input.ApiKey, input.ResolvedEndpointURL(), input.BaseUrl)
+	renderer := &report.WebhookRenderer{Format: input.WebhookFormat}
+	body, err := renderer.Render(summary)
+	if err != nil {
+		warnWebhook("任务 %s 的 webhook 消息生成失败: %v", taskDef.Name, err)
+		return
+	}
+	client := network.TelemetryOptions().NewClient(webhookTimeout)
+	if err := report.SendWebhook(context.Background(), client, input.WebhookURL, body, webhookRetryDelay); err != nil {
+		warnWebhook("任务 %s 的 webhook 推送失败: %v", taskDef.Name, err)
+	}
+}
@@ -99,6 +99,27 @@ func (c *Client) StopRunCmd(runID server.RunID) tea.Cmd {
 	}
 }
 
+// AdjustRunConcurrencyCmd 异步把运行的并发上限增减 delta。
+func (c *Client) AdjustRunConcurrencyCmd(runID server.RunID, delta int) tea.Cmd {
+	return func() tea.Msg {
+		n, err := c.srv.AdjustRunConcurrency(runID, delta)
+		if err != nil {
+			return ErrorMsg{Err: fmt.Errorf("调整并发失败: %w", err)}
+		}
+		return RunConcurrencyAdjustedMsg{RunID: runID, Concurrency: n}
+	}
+}
+
+// SetRunPausedCmd 异步暂停或恢复运行。
+func (c *Client) SetRunPausedCmd(runID server.RunID, paused bool) tea.Cmd {
+	return func() tea.Msg {
+		if err := c.srv.SetRunPaused(runID, paused); err != nil {
+			return ErrorMsg{Err: fmt.Errorf("暂停 / 恢复失败: %w", err)}
+		}
+		return RunPausedMsg{RunID: runID, Paused: paused}
+	}
+}
+
 // SubscribeRunEventsCmd 订阅 runID 的事件流，返回用于首次等待的 Cmd 和 CancelFunc。
 // 调用方应将 ch 存储在 dashboardState 中，每次收到 ServerEventMsg 后
 // 再次调用 WaitEventCmd(ch) 继续监听。
@@ -119,6 +140,20 @@ func WaitEventCmd(ch <-chan server.Event) tea.Cmd {
 	}
 }
 
+// waitNoticeCmd 等待启动提示通道的下一条提示；通道为 nil 或已关闭时返回 nil，不再继续监听。
+func waitNoticeCmd(ch <-chan string) tea.Cmd {
+	if ch == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		text, ok := <-ch
+		if !ok {
+			return nil
+		}
+		return NoticeMsg{Text: text}
+	}
+}
+
 // ─── 历史 & 报告 ──────────────────────────────────────────────────────────────
 
 // LoadTaskRunHistoryCmd 异步加载指定任务的运行历史，limit<=0 表示不限条数。
@@ -10,6 +10,11 @@ type TasksLoadedMsg struct {
 	Tasks []types.TaskOverview
 }
 
+// NoticeMsg 启动提示（遥测说明、升级提示等），由 waitNoticeCmd 产生。
+type NoticeMsg struct {
+	Text string
+}
+
 // TaskSavedMsg 新建或更新任务完成。
 type TaskSavedMsg struct {
 	Task      types.TaskDefinition
@@ -38,6 +43,18 @@ type RunStopRequestedMsg struct {
 	RunID server.RunID
 }
 
+// RunConcurrencyAdjustedMsg 运行的并发上限已调整。
+type RunConcurrencyAdjustedMsg struct {
+	RunID       server.RunID
+	Concurrency int
+}
+
+// RunPausedMsg 运行已暂停或恢复派发。
+type RunPausedMsg struct {
+	RunID  server.RunID
+	Paused bool
+}
+
 // ServerEventMsg 封装从 server.SubscribeRunEvents 获取的事件，由 WaitEventCmd 产生。
 type ServerEventMsg struct {
 	Event server.Event
@@ -11,6 +11,7 @@ import (
 	"github.com/yinxulai/ait/internal/server/config"
 	"github.com/yinxulai/ait/internal/server/types"
 	"github.com/yinxulai/ait/internal/tui/pages"
+	"github.com/yinxulai/ait/internal/tui/pages/shared"
 )
 
 // ─── 视图状态 ─────────────────────────────────────────────────────────────────
@@ -73,10 +74,18 @@ func Run(srv server.Server) error {
 // SetVersion 设置 AppHeader 中显示的版本字符串，应在 Run 之前调用。
 func SetVersion(v string) { pages.SetAppVersion(v) }
 
+var startupNotices <-chan string
+
+// SetNotices 设置启动提示（如遥测说明、升级提示）的来源，收到的每条提示显示在顶部提示条中，应在 Run 之前调用。
+func SetNotices(ch <-chan string) { startupNotices = ch }
+
+// SetASCII 切换纯 ASCII 显示（不含 emoji 与 Unicode 框线），应在 Run 之前调用。
+func SetASCII(enabled bool) { shared.SetASCII(enabled) }
+
 // ─── BubbleTea 接口 ───────────────────────────────────────────────────────────
 
 func (m *Model) Init() tea.Cmd {
-	return m.client.LoadTasksCmd()
+	return tea.Batch(m.client.LoadTasksCmd(), waitNoticeCmd(startupNotices))
 }
 
 func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -118,6 +127,15 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 
+	// ── 启动提示 ──
+	case NoticeMsg:
+		if m.status == "" {
+			m.status = msg.Text
+		} else {
+			m.status += "  " + msg.Text
+		}
+		return m, waitNoticeCmd(startupNotices)
+
 	// ── 任务保存完成（新建或更新） ──
 	case TaskSavedMsg:
 		m.status = fmt.Sprintf("任务 %q 已保存", msg.Task.Name)
@@ -173,6 +191,19 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.status = "已发送停止信号，等待当前请求收尾"
 		return m, nil
 
+	// ── 并发已调整 ──
+	case RunConcurrencyAdjustedMsg:
+		m.status = fmt.Sprintf(i18n.T(i18n.KConcurrencyAdjustedFmt), msg.Concurrency)
+		return m, nil
+
+	// ── 暂停 / 恢复 ──
+	case RunPausedMsg:
+		m.status = i18n.T(i18n.KRunResumed)
+		if msg.Paused {
+			m.status = i18n.T(i18n.KRunPaused)
+		}
+		return m, nil
+
 	// ── Server 事件（来自运行中订阅） ──
 	case ServerEventMsg:
 		return m.handleServerEvent(msg)
@@ -241,10 +272,10 @@ func (m *Model) View() string {
 	// 状态/错误提示条占用一行
 	var banner string
 	if m.err != nil {
-		banner = m.styles.ErrStyle.Width(innerW).Render(" ✗ " + m.err.Error())
+		banner = m.styles.ErrStyle.Width(innerW).Render(" " + shared.Sym().Err + " " + m.err.Error())
 		innerH--
 	} else if m.status != "" {
-		banner = m.styles.Ok.Width(innerW).Render(" ✓ " + m.status)
+		banner = m.styles.Ok.Width(innerW).Render(" " + shared.Sym().Ok + " " + m.status)
 		innerH--
 	}
 
@@ -490,7 +521,7 @@ func (m *Model) handleServerEvent(msg ServerEventMsg) (tea.Model, tea.Cmd) {
 
 	switch e.Kind {
 	case server.EventProgressTick, server.EventRequestDone, server.EventLevelDone:
-		if rs, ok := e.Payload.(*server.RunState); ok {
+		if rs, ok := e.Payload.(*server.RunState); ok && !m.staleProgress(isDash, rs) {
 			if isDash {
 				m.dash.RunState = rs
 			} else {
@@ -603,6 +634,18 @@ func (m *Model) findTask(taskID string) *types.TaskDefinition {
 	return nil
 }
 
+// staleProgress 判断进度快照是否比面板当前展示的更旧。快照在锁内生成、锁外投递，定时快照与请求完成
+// 事件可能乱序到达；已完成数只增不减，回退的快照直接丢弃，避免进度条倒退跳动。
+func (m *Model) staleProgress(isDash bool, next *server.RunState) bool {
+	var cur *server.RunState
+	if isDash {
+		cur = m.dash.RunState
+	} else {
+		cur = m.turboDash.RunState
+	}
+	return cur != nil && cur.RunID == next.RunID && next.DoneReqs < cur.DoneReqs
+}
+
 func (m *Model) injectRunState(rs *server.RunState) {
 	if m.taskList == nil || rs == nil {
 		return
@@ -624,7 +667,7 @@ func (m *Model) injectRunState(rs *server.RunState) {
 
 func (m *Model) dashTaskName() string {
 	if m.dash == nil {
-		return "─"
+		return shared.Sym().None
 	}
 	t := m.findTask(m.dash.TaskID)
 	if t != nil {
@@ -635,7 +678,7 @@ func (m *Model) dashTaskName() string {
 
 func (m *Model) turboDashTaskName() string {
 	if m.turboDash == nil {
-		return "─"
+		return shared.Sym().None
 	}
 	t := m.findTask(m.turboDash.TaskID)
 	if t != nil {
@@ -663,7 +706,7 @@ func (m *Model) reqDetailTaskName() string {
 			return t.Name
 		}
 	}
-	return "─"
+	return shared.Sym().None
 }
 
 func (m *Model) currentRunID() server.RunID {
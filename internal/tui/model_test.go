@@ -32,6 +32,8 @@ func (s *stubServer) DuplicateTask(id string) (types.TaskDefinition, error) {
 }
 func (s *stubServer) StartRun(taskID string) (server.RunID, error)            { return "", nil }
 func (s *stubServer) StopRun(runID server.RunID) error                        { return nil }
+func (s *stubServer) AdjustRunConcurrency(server.RunID, int) (int, error)     { return 0, nil }
+func (s *stubServer) SetRunPaused(server.RunID, bool) error                   { return nil }
 func (s *stubServer) GetRunState(runID server.RunID) (*server.RunState, bool) { return nil, false }
 func (s *stubServer) SubscribeRunEvents(runID server.RunID) (<-chan server.Event, server.CancelFunc) {
 	ch := make(chan server.Event)
@@ -54,6 +56,9 @@ func (s *stubServer) GetIntegritySuite(protocol, suiteID string) (types.Integrit
 	return types.IntegritySuite{}, nil
 }
 func (s *stubServer) Context() context.Context { return context.Background() }
+func (s *stubServer) Regressed() bool          { return false }
+func (s *stubServer) SLAFailed() bool          { return false }
+func (s *stubServer) AbortedEarly() bool       { return false }
 
 // ─── NewModel ─────────────────────────────────────────────────────────────────
 
@@ -229,3 +234,56 @@ func TestOpenWizard_EditTask_InferLegacyPromptMode(t *testing.T) {
 		t.Errorf("PromptText = %q, want %q", m.wizard.PromptText, "legacy prompt")
 	}
 }
+
+func TestHandleServerEvent_IgnoresStaleProgress(t *testing.T) {
+	m := NewModel(&stubServer{})
+	m.dash = pages.NewDashboardState("run-1", "task-1")
+
+	// 模拟定时快照在锁外投递时晚于更新的请求完成事件到达：完成数序列 3 → 5 → 4（过期） → 6
+	var shown []int
+	for _, e := range []struct {
+		kind server.EventKind
+		done int
+	}{
+		{server.EventRequestDone, 3},
+		{server.EventRequestDone, 5},
+		{server.EventProgressTick, 4},
+		{server.EventRequestDone, 6},
+	} {
+		m.handleServerEvent(ServerEventMsg{Event: server.Event{
+			RunID:   "run-1",
+			Kind:    e.kind,
+			Payload: &server.RunState{RunID: "run-1", TaskID: "task-1", Status: server.RunStatusRunning, DoneReqs: e.done, TotalReqs: 10},
+		}})
+		shown = append(shown, m.dash.RunState.DoneReqs)
+	}
+
+	want := []int{3, 5, 5, 6}
+	for i := range want {
+		if shown[i] != want[i] {
+			t.Fatalf("DoneReqs sequence = %v, want %v", shown, want)
+		}
+	}
+}
+
+func TestNoticeMsg_ShowsInBanner(t *testing.T) {
+	ch := make(chan string, 2)
+	ch <- "遥测说明"
+	ch <- "发现新版本 v9.9.9"
+	close(ch)
+	SetNotices(ch)
+	t.Cleanup(func() { SetNotices(nil) })
+
+	m := NewModel(&stubServer{})
+	cmd := waitNoticeCmd(startupNotices)
+	for cmd != nil {
+		msg := cmd()
+		if msg == nil {
+			break
+		}
+		_, cmd = m.Update(msg)
+	}
+	if m.status != "遥测说明  发现新版本 v9.9.9" {
+		t.Errorf("status = %q", m.status)
+	}
+}
@@ -1,6 +1,9 @@
 package pages
 
-import "github.com/yinxulai/ait/internal/i18n"
+import (
+	"github.com/yinxulai/ait/internal/i18n"
+	"github.com/yinxulai/ait/internal/tui/pages/shared"
+)
 
 // HotkeyItem 是底部 Hotkeys 区中的一个展示项。
 type HotkeyItem struct {
@@ -10,11 +13,11 @@ type HotkeyItem struct {
 }
 
 func HotkeyAction(key, desc string) HotkeyItem {
-	return HotkeyItem{Key: key, Desc: desc}
+	return HotkeyItem{Key: shared.ASCIIText(key), Desc: desc}
 }
 
 func HotkeyText(text string) HotkeyItem {
-	return HotkeyItem{Text: text}
+	return HotkeyItem{Text: shared.ASCIIText(text)}
 }
 
 func HotkeyTexts(texts ...string) []HotkeyItem {
@@ -120,6 +123,8 @@ func Hotkeys_Wizard_Step3() []HotkeyItem {
 // Hotkeys_Dashboard_Running_NoSel 标准仪表盘运行中，无选中请求时。
 func Hotkeys_Dashboard_Running_NoSel() []HotkeyItem {
 	return []HotkeyItem{
+		HotkeyAction("+/-", i18n.T(i18n.KAdjustConcurrency)),
+		HotkeyAction("p", i18n.T(i18n.KPauseResume)),
 		HotkeyAction("s", i18n.T(i18n.KStop)),
 		HotkeyAction("b/Esc", i18n.T(i18n.KBackToList)),
 	}
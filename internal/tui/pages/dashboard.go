@@ -125,6 +125,21 @@ func HandleDashboardKey(d *DashboardState, msg tea.KeyMsg, client Client) (*Dash
 			return d, client.StopRunCmd(d.RunID), nav
 		}
 
+	case "+", "=":
+		if d.IsRunning() {
+			return d, client.AdjustRunConcurrencyCmd(d.RunID, 1), nav
+		}
+
+	case "-", "_":
+		if d.IsRunning() {
+			return d, client.AdjustRunConcurrencyCmd(d.RunID, -1), nav
+		}
+
+	case "p":
+		if d.IsRunning() {
+			return d, client.SetRunPausedCmd(d.RunID, !d.RunState.Paused), nav
+		}
+
 	case "b", "esc":
 		if d.BackNav.To != NavNone {
 			nav = d.BackNav
@@ -245,10 +260,317 @@ func buildDashParamsPanel(d *DashboardState, rs *server.RunState, st Styles, max
 		lines = append(lines, " "+st.Muted.Render(i18n.T(i18n.KWaitingData)))
 	} else {
 		lbls := []string{i18n.T(i18n.KProgress), i18n.T(i18n.KSuccessCount), i18n.T(i18n.KFailureCount)}
+		if rs.Concurrency > 0 {
+			lbls = append(lbls, i18n.T(i18n.KConcurrency))
+		}
+		if rs.SelfStats != nil {
+			lbls = append(lbls, i18n.T(i18n.KSelfStats))
+		}
+		var baseline *types.BaselineResult
+		if data, ok := rs.ModeResult.(*types.ReportData); ok && data.Baseline != nil {
+			baseline = data.Baseline
+			lbls = append(lbls, i18n.T(i18n.KBaseline))
+		}
+		var finishReasons map[string]int
+		if data, ok := rs.ModeResult.(*types.ReportData); ok && len(data.FinishReasons) > 0 {
+			finishReasons = data.FinishReasons
+			lbls = append(lbls, i18n.T(i18n.KFinishReason))
+		}
+		var suspicious int
+		if data, ok := rs.ModeResult.(*types.ReportData); ok && data.SuspiciousContentCount > 0 {
+			suspicious = data.SuspiciousContentCount
+			lbls = append(lbls, i18n.T(i18n.KSuspiciousContent))
+		}
+		var emptyContent *types.ReportData
+		if data, ok := rs.ModeResult.(*types.ReportData); ok && data.EmptyContentCount > 0 {
+			emptyContent = data
+			lbls = append(lbls, i18n.T(i18n.KEmptyContent))
+		}
+		var maxTokensUsage *types.MaxTokensUsage
+		if data, ok := rs.ModeResult.(*types.ReportData); ok && data.MaxTokensUsage != nil {
+			maxTokensUsage = data.MaxTokensUsage
+			lbls = append(lbls, i18n.T(i18n.KMaxTokensUsage))
+		}
+		var abort *types.AbortStats
+		if data, ok := rs.ModeResult.(*types.ReportData); ok && data.Abort != nil {
+			abort = data.Abort
+			lbls = append(lbls, i18n.T(i18n.KAbortedEarly))
+		}
+		var requestIDCheck *types.RequestIDCheckStats
+		if data, ok := rs.ModeResult.(*types.ReportData); ok && data.RequestIDCheck != nil {
+			requestIDCheck = data.RequestIDCheck
+			lbls = append(lbls, i18n.T(i18n.KRequestIDCheck))
+		}
+		var encodings map[string]*types.EncodingStats
+		if data, ok := rs.ModeResult.(*types.ReportData); ok && len(data.ResponseEncodings) > 0 {
+			encodings = data.ResponseEncodings
+			lbls = append(lbls, i18n.T(i18n.KResponseEncoding))
+		}
+		var schemaCheck *types.SchemaCheckStats
+		if data, ok := rs.ModeResult.(*types.ReportData); ok && data.SchemaCheck != nil {
+			schemaCheck = data.SchemaCheck
+			lbls = append(lbls, i18n.T(i18n.KSchemaCheck))
+		}
+		var slaResults []types.SLAResult
+		if data, ok := rs.ModeResult.(*types.ReportData); ok && len(data.SLAResults) > 0 {
+			slaResults = data.SLAResults
+			lbls = append(lbls, i18n.T(i18n.KSLA))
+		}
+		var bursts []types.BurstStats
+		if data, ok := rs.ModeResult.(*types.ReportData); ok && len(data.Bursts) > 0 {
+			bursts = data.Bursts
+			lbls = append(lbls, i18n.T(i18n.KBurst))
+		}
+		var fallback *types.FallbackStats
+		if data, ok := rs.ModeResult.(*types.ReportData); ok && data.Fallback != nil {
+			fallback = data.Fallback
+			lbls = append(lbls, i18n.T(i18n.KFallback))
+		}
+		var probeSummary *types.NetworkProbeSummary
+		if data, ok := rs.ModeResult.(*types.ReportData); ok && data.NetworkProbeSummary != nil {
+			probeSummary = data.NetworkProbeSummary
+			lbls = append(lbls, i18n.T(i18n.KNetworkProbe))
+			if probeSummary.Verdict != "" {
+				lbls = append(lbls, i18n.T(i18n.KProbeVerdict))
+			}
+		}
+		var sweepPoints []*types.ReportData
+		if sweep, ok := rs.ModeResult.(*types.InputLengthSweepResult); ok && len(sweep.Points) > 0 {
+			sweepPoints = sweep.Points
+			lbls = append(lbls, i18n.T(i18n.KLengthSweep))
+		}
+		var scenarios []*types.ReportData
+		if result, ok := rs.ModeResult.(*types.PromptFileRunsResult); ok && len(result.Scenarios) > 0 {
+			scenarios = result.Scenarios
+			lbls = append(lbls, i18n.T(i18n.KPromptFileScenario))
+		}
+		var compareRows []streamCompareRow
+		if compare, ok := rs.ModeResult.(*types.StreamCompareResult); ok && compare.Stream != nil {
+			compareRows = streamCompareRows(compare)
+		} else if compare, ok := rs.ModeResult.(*types.HTTPVersionCompareResult); ok && compare.HTTP1 != nil {
+			compareRows = httpVersionCompareRows(compare)
+		}
+		for _, row := range compareRows {
+			lbls = append(lbls, row.label)
+		}
+		var replayOf string
+		if data, ok := rs.ModeResult.(*types.ReportData); ok && data.ReplayOf != "" {
+			replayOf = data.ReplayOf
+			lbls = append(lbls, i18n.T(i18n.KReplay))
+		}
+		var phases *types.PhaseStats
+		if data, ok := rs.ModeResult.(*types.ReportData); ok && data.PhaseStats != nil {
+			phases = data.PhaseStats
+			lbls = append(lbls, i18n.T(i18n.KPhase))
+		}
+		var firstRequest *types.FirstRequestMetrics
+		if data, ok := rs.ModeResult.(*types.ReportData); ok && data.FirstRequest != nil {
+			firstRequest = data.FirstRequest
+			lbls = append(lbls, i18n.T(i18n.KFirstRequest))
+		}
+		var blockStarts map[string]time.Duration
+		if data, ok := rs.ModeResult.(*types.ReportData); ok && len(data.AvgBlockStarts) > 0 {
+			blockStarts = data.AvgBlockStarts
+			lbls = append(lbls, i18n.T(i18n.KBlockTiming))
+		}
+		var netErrorKinds map[string]int
+		if data, ok := rs.ModeResult.(*types.ReportData); ok && len(data.NetErrorKinds) > 0 {
+			netErrorKinds = data.NetErrorKinds
+			lbls = append(lbls, i18n.T(i18n.KNetErrors))
+		}
+		var consistency *types.ReportData
+		if data, ok := rs.ModeResult.(*types.ReportData); ok && data.DistinctOutputs > 0 {
+			consistency = data
+			lbls = append(lbls, i18n.T(i18n.KConsistency))
+		}
+		var avgToolCalls float64
+		if data, ok := rs.ModeResult.(*types.ReportData); ok && data.AvgToolCallCount > 0 {
+			avgToolCalls = data.AvgToolCallCount
+			lbls = append(lbls, i18n.T(i18n.KAvgToolCalls))
+		}
+		peakConns := 0
+		if data, ok := rs.ModeResult.(*types.ReportData); ok && data.PeakConcurrentConnections > 0 {
+			peakConns = data.PeakConcurrentConnections
+			lbls = append(lbls, i18n.T(i18n.KPeakConnections))
+		}
+		var connReuse *types.ReportData
+		if data, ok := rs.ModeResult.(*types.ReportData); ok && data.ConnRequests > 0 {
+			connReuse = data
+			lbls = append(lbls, i18n.T(i18n.KConnReuse))
+		}
+		var queueWait *types.ReportData
+		if data, ok := rs.ModeResult.(*types.ReportData); ok && data.MaxQueueWaitTime > 0 {
+			queueWait = data
+			lbls = append(lbls, i18n.T(i18n.KQueueWait))
+		}
+		var targetIPTexts []string
+		if data, ok := rs.ModeResult.(*types.ReportData); ok {
+			if targetIPTexts = targetIPStatsTexts(data.TargetIPStats, data.ResolvedIPs); targetIPTexts != nil {
+				lbls = append(lbls, i18n.T(i18n.KTargetIP))
+			}
+		}
 		lw := shared.MaxLabelWidth(lbls)
 		lines = append(lines, " "+labelValue(st, lbls[0], fmt.Sprintf("%d/%d", rs.DoneReqs, rs.TotalReqs), lw))
 		lines = append(lines, " "+labelValue(st, lbls[1], fmt.Sprintf("%d", rs.SuccessReqs), lw))
 		lines = append(lines, " "+labelValue(st, lbls[2], fmt.Sprintf("%d", rs.FailedReqs), lw))
+		if rs.Concurrency > 0 {
+			lines = append(lines, " "+labelValue(st, i18n.T(i18n.KConcurrency), fmt.Sprintf("%d", rs.Concurrency), lw))
+		}
+		if peakConns > 0 {
+			text := fmt.Sprintf(i18n.T(i18n.KPeakConnectionsFmt), peakConns, rs.Concurrency)
+			if peakConns < rs.Concurrency {
+				// 实际并发连接数低于配置的并发，提示可能被服务端或中间件限制
+				text = st.MetricVal.Render(text)
+			}
+			lines = append(lines, " "+labelValue(st, i18n.T(i18n.KPeakConnections), text, lw))
+		}
+		if connReuse != nil {
+			text := fmt.Sprintf(i18n.T(i18n.KConnReuseFmt), connReuse.ConnReusedRequests, connReuse.ConnRequests, connReuse.ConnReuseRate)
+			lines = append(lines, " "+labelValue(st, i18n.T(i18n.KConnReuse), text, lw))
+		}
+		if queueWait != nil {
+			text := fmt.Sprintf(i18n.T(i18n.KQueueWaitFmt), shared.FmtDuration(queueWait.AvgQueueWaitTime), shared.FmtDuration(queueWait.MaxQueueWaitTime))
+			lines = append(lines, " "+labelValue(st, i18n.T(i18n.KQueueWait), text, lw))
+			if queueWait.QueueWaitHigh() {
+				lines = append(lines, " "+labelValue(st, "", st.MetricVal.Render(shared.Truncate(i18n.T(i18n.KQueueWaitHint), shared.MaxInt(8, width-lw-3))), lw))
+			}
+		}
+		if rs.SelfStats != nil {
+			text := selfStatsText(rs.SelfStats)
+			if rs.SelfStats.LeakSuspected() || rs.SelfStats.Growing() {
+				text = st.MetricVal.Render(text)
+			}
+			lines = append(lines, " "+labelValue(st, i18n.T(i18n.KSelfStats), text, lw))
+		}
+		if baseline != nil {
+			lines = append(lines, " "+labelValue(st, i18n.T(i18n.KBaseline), baselineText(baseline), lw))
+		}
+		if finishReasons != nil {
+			lines = append(lines, " "+labelValue(st, i18n.T(i18n.KFinishReason), shared.Truncate(finishReasonsText(finishReasons), shared.MaxInt(8, width-lw-3)), lw))
+		}
+		if suspicious > 0 {
+			lines = append(lines, " "+labelValue(st, i18n.T(i18n.KSuspiciousContent), st.MetricVal.Render(fmt.Sprintf(i18n.T(i18n.KSuspiciousContentFmt), suspicious)), lw))
+		}
+		if emptyContent != nil {
+			text := fmt.Sprintf(i18n.T(i18n.KEmptyContentFmt), emptyContent.EmptyContentCount, emptyContent.EmptyContentRate)
+			lines = append(lines, " "+labelValue(st, i18n.T(i18n.KEmptyContent), st.ErrStyle.Render(shared.Truncate(text, shared.MaxInt(8, width-lw-3))), lw))
+		}
+		if maxTokensUsage != nil {
+			text := shared.Truncate(maxTokensUsageText(maxTokensUsage), shared.MaxInt(8, width-lw-3))
+			if maxTokensUsage.Exceeded > 0 {
+				text = st.ErrStyle.Render(text)
+			} else if maxTokensUsage.Truncated > 0 {
+				text = st.MetricVal.Render(text)
+			}
+			lines = append(lines, " "+labelValue(st, i18n.T(i18n.KMaxTokensUsage), text, lw))
+		}
+		if abort != nil {
+			text := fmt.Sprintf(i18n.T(i18n.KAbortedEarlyFmt), abort.ErrorRate, abort.Threshold, abort.Failed, abort.Completed)
+			lines = append(lines, " "+labelValue(st, i18n.T(i18n.KAbortedEarly), st.ErrStyle.Render(shared.Truncate(text, shared.MaxInt(8, width-lw-3))), lw))
+		}
+		if requestIDCheck != nil {
+			text := fmt.Sprintf(i18n.T(i18n.KRequestIDCheckFmt), requestIDCheck.Echoed, requestIDCheck.Mismatched)
+			if requestIDCheck.Mismatched > 0 {
+				text = st.MetricVal.Render(text)
+			}
+			lines = append(lines, " "+labelValue(st, i18n.T(i18n.KRequestIDCheck), text, lw))
+		}
+		if encodings != nil {
+			lines = append(lines, " "+labelValue(st, i18n.T(i18n.KResponseEncoding), shared.Truncate(responseEncodingsText(encodings), shared.MaxInt(8, width-lw-3)), lw))
+		}
+		if schemaCheck != nil {
+			text := fmt.Sprintf(i18n.T(i18n.KSchemaCheckFmt), schemaCheck.ComplianceRate, schemaCheck.ValidJSONRate, schemaCheck.Checked)
+			lines = append(lines, " "+labelValue(st, i18n.T(i18n.KSchemaCheck), shared.Truncate(text, shared.MaxInt(8, width-lw-3)), lw))
+		}
+		for _, r := range slaResults {
+			lines = append(lines, " "+labelValue(st, i18n.T(i18n.KSLA), shared.Truncate(slaText(r), shared.MaxInt(8, width-lw-3)), lw))
+		}
+		if bursts != nil {
+			lines = append(lines, " "+labelValue(st, i18n.T(i18n.KBurst), shared.Truncate(burstText(bursts), shared.MaxInt(8, width-lw-3)), lw))
+		}
+		if fallback != nil {
+			text := fmt.Sprintf(i18n.T(i18n.KFallbackFmt), fallback.Triggered, fallback.Succeeded)
+			if fallback.Triggered > 0 {
+				text = st.MetricVal.Render(text)
+			}
+			lines = append(lines, " "+labelValue(st, i18n.T(i18n.KFallback), text, lw))
+		}
+		if probeSummary != nil {
+			lines = append(lines, " "+labelValue(st, i18n.T(i18n.KNetworkProbe), shared.Truncate(networkProbeText(probeSummary), shared.MaxInt(8, width-lw-3)), lw))
+			if verdict := probeVerdictText(probeSummary.Verdict); verdict != "" {
+				lines = append(lines, " "+labelValue(st, i18n.T(i18n.KProbeVerdict), st.MetricVal.Render(shared.Truncate(verdict, shared.MaxInt(8, width-lw-3))), lw))
+			}
+		}
+		for _, point := range sweepPoints {
+			if point != nil {
+				lines = append(lines, " "+labelValue(st, i18n.T(i18n.KLengthSweep), shared.Truncate(lengthSweepText(point), shared.MaxInt(8, width-lw-3)), lw))
+			}
+		}
+		for _, scenario := range scenarios {
+			if scenario != nil {
+				lines = append(lines, " "+labelValue(st, i18n.T(i18n.KPromptFileScenario), shared.Truncate(promptFileScenarioText(scenario), shared.MaxInt(8, width-lw-3)), lw))
+			}
+		}
+		if compareRows != nil {
+			colW := 0
+			for _, row := range compareRows {
+				colW = shared.MaxInt(colW, lipgloss.Width(row.stream))
+			}
+			for i, row := range compareRows {
+				value := shared.PadToDisplayWidth(row.stream, colW) + "  " + row.nonStream
+				if i > 0 {
+					value = st.MetricVal.Render(shared.PadToDisplayWidth(row.stream, colW)) + "  " + st.MetricVal.Render(row.nonStream)
+				}
+				lines = append(lines, " "+labelValue(st, row.label, value, lw))
+			}
+		}
+		if replayOf != "" {
+			lines = append(lines, " "+labelValue(st, i18n.T(i18n.KReplay), shared.Truncate(fmt.Sprintf(i18n.T(i18n.KReplayOfFmt), replayOf), shared.MaxInt(8, width-lw-3)), lw))
+		}
+		if phases != nil {
+			for _, text := range phaseTexts(phases) {
+				lines = append(lines, " "+labelValue(st, i18n.T(i18n.KPhase), shared.Truncate(text, shared.MaxInt(8, width-lw-3)), lw))
+			}
+			if phases.Skewed() {
+				hint := fmt.Sprintf(i18n.T(i18n.KPhaseSkewedHint), phases.SteadyDeviation)
+				lines = append(lines, " "+labelValue(st, "", st.MetricVal.Render(shared.Truncate(hint, shared.MaxInt(8, width-lw-3))), lw))
+			}
+		}
+		if firstRequest != nil {
+			for i, text := range firstRequestTexts(firstRequest) {
+				label := i18n.T(i18n.KFirstRequest)
+				if i > 0 {
+					label = ""
+				}
+				lines = append(lines, " "+labelValue(st, label, shared.Truncate(text, shared.MaxInt(8, width-lw-3)), lw))
+			}
+			if firstRequest.ColdStart() {
+				hint := fmt.Sprintf(i18n.T(i18n.KColdStartHint), firstRequest.Deviation)
+				lines = append(lines, " "+labelValue(st, "", st.MetricVal.Render(shared.Truncate(hint, shared.MaxInt(8, width-lw-3))), lw))
+			}
+		}
+		if blockStarts != nil {
+			lines = append(lines, " "+labelValue(st, i18n.T(i18n.KBlockTiming), shared.Truncate(blockStartsText(blockStarts), shared.MaxInt(8, width-lw-3)), lw))
+		}
+		if consistency != nil {
+			text := fmt.Sprintf(i18n.T(i18n.KConsistencyFmt), consistency.ConsistencyRate, consistency.DistinctOutputs)
+			lines = append(lines, " "+labelValue(st, i18n.T(i18n.KConsistency), st.MetricVal.Render(text), lw))
+			// 一致率低于 100% 时列出各版本与最常见版本的差异摘要
+			if consistency.DistinctOutputs > 1 {
+				for _, text := range outputVariantTexts(consistency.OutputVariants) {
+					lines = append(lines, " "+labelValue(st, "", shared.Truncate(text, shared.MaxInt(8, width-lw-3)), lw))
+				}
+			}
+		}
+		if netErrorKinds != nil {
+			lines = append(lines, " "+labelValue(st, i18n.T(i18n.KNetErrors), st.ErrStyle.Render(shared.Truncate(netErrorKindsText(netErrorKinds), shared.MaxInt(8, width-lw-3))), lw))
+		}
+		if avgToolCalls > 0 {
+			lines = append(lines, " "+labelValue(st, i18n.T(i18n.KAvgToolCalls), fmt.Sprintf("%.2f", avgToolCalls), lw))
+		}
+		for _, text := range targetIPTexts {
+			lines = append(lines, " "+labelValue(st, i18n.T(i18n.KTargetIP), shared.Truncate(text, shared.MaxInt(8, width-lw-3)), lw))
+		}
 	}
 
 	return finishPanelLines(lines, maxH)
@@ -278,7 +600,7 @@ func buildProgressLine(rs *server.RunState, st Styles, width int) string {
 	if total > 0 {
 		ratio = float64(done) / float64(total)
 	}
-	elapsed := "─"
+	elapsed := shared.Sym().None
 	if !rs.StartedAt.IsZero() {
 		if rs.FinishedAt != nil {
 			elapsed = shared.FmtDuration(rs.FinishedAt.Sub(rs.StartedAt))
@@ -287,6 +609,9 @@ func buildProgressLine(rs *server.RunState, st Styles, width int) string {
 		}
 	}
 	suffix := fmt.Sprintf("  %d / %d   %s", done, total, elapsed)
+	if rs.Paused && rs.Status == server.RunStatusRunning {
+		suffix += "   " + st.MetricVal.Render(i18n.T(i18n.KPausedTag))
+	}
 	return renderProgressBar(st, " "+shared.PadToDisplayWidth(i18n.T(i18n.KProgress), 4)+"  ", suffix, ratio, width)
 }
 
@@ -321,9 +646,9 @@ func buildRequestList(d *DashboardState, rs *server.RunState, st Styles, width,
 	for pos := 0; pos < len(reqs); pos++ {
 		i := requestIndexFromDisplayPos(pos, len(reqs))
 		r := reqs[i]
-		statusText := "✓"
+		statusText := shared.Sym().Ok
 		if !r.Success {
-			statusText = "✗"
+			statusText = shared.Sym().Err
 		}
 		totalText := shared.FmtDuration(r.TotalTime)
 		if !r.Success && r.ErrorMessage != "" {
@@ -354,6 +679,7 @@ func buildRequestList(d *DashboardState, rs *server.RunState, st Styles, width,
 		Width(width).
 		Height(tableH).
 		YOffset(d.ReqOff).
+		Border(shared.Sym().TableBorder).
 		BorderTop(false).BorderBottom(false).
 		BorderLeft(false).BorderRight(false).
 		BorderHeader(true).BorderColumn(true).BorderRow(true).
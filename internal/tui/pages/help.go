@@ -108,7 +108,7 @@ type helpItem struct {
 }
 
 func helpContent() []helpSection {
-	return []helpSection{
+	sections := []helpSection{
 		{
 			title: i18n.T(i18n.KHelpSecConcepts),
 			items: []helpItem{
@@ -123,10 +123,12 @@ func helpContent() []helpSection {
 			items: []helpItem{
 				{i18n.T(i18n.KHelpTermTPS), i18n.T(i18n.KHelpDescTPS)},
 				{i18n.T(i18n.KHelpTermAvgTPS), i18n.T(i18n.KHelpDescAvgTPS)},
+				{i18n.T(i18n.KHelpTermSteadyTPS), i18n.T(i18n.KHelpDescSteadyTPS)},
 				{i18n.T(i18n.KHelpTermTTFT), i18n.T(i18n.KHelpDescTTFT)},
 				{i18n.T(i18n.KHelpTermAvgTTFT), i18n.T(i18n.KHelpDescAvgTTFT)},
 				{i18n.T(i18n.KHelpTermSuccessRate), i18n.T(i18n.KHelpDescSuccessRate)},
 				{i18n.T(i18n.KHelpTermCacheHit), i18n.T(i18n.KHelpDescCacheHit)},
+				{i18n.T(i18n.KHelpTermCachedTokenRatio), i18n.T(i18n.KHelpDescCachedTokenRatio)},
 				{i18n.T(i18n.KHelpTermConcurrencyTurbo), i18n.T(i18n.KHelpDescConcurrencyTurbo)},
 			},
 		},
@@ -178,6 +180,8 @@ func helpContent() []helpSection {
 				{i18n.T(i18n.KHelpTermSelectReq), i18n.T(i18n.KHelpDescSelectReq)},
 				{i18n.T(i18n.KHelpTermViewReq), i18n.T(i18n.KHelpDescViewReq)},
 				{i18n.T(i18n.KHelpTermStopDash), i18n.T(i18n.KHelpDescStopDash)},
+				{i18n.T(i18n.KHelpTermAdjustConcurrency), i18n.T(i18n.KHelpDescAdjustConcurrency)},
+				{i18n.T(i18n.KHelpTermPauseResume), i18n.T(i18n.KHelpDescPauseResume)},
 				{i18n.T(i18n.KHelpTermGenerateReport), i18n.T(i18n.KHelpDescGenerateReport)},
 				{i18n.T(i18n.KHelpTermBackDash), i18n.T(i18n.KHelpDescBackDash)},
 			},
@@ -190,6 +194,13 @@ func helpContent() []helpSection {
 			},
 		},
 	}
+	for i := range sections {
+		for j := range sections[i].items {
+			sections[i].items[j].term = shared.ASCIIText(sections[i].items[j].term)
+			sections[i].items[j].desc = shared.ASCIIText(sections[i].items[j].desc)
+		}
+	}
+	return sections
 }
 
 func buildHelpLines(s *HelpState, contentW, _ int) []string {
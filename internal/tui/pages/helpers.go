@@ -2,12 +2,14 @@ package pages
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
 	"charm.land/lipgloss/v2"
 	"github.com/yinxulai/ait/internal/i18n"
 	"github.com/yinxulai/ait/internal/server"
+	"github.com/yinxulai/ait/internal/server/types"
 	"github.com/yinxulai/ait/internal/tui/pages/shared"
 )
 
@@ -35,8 +37,8 @@ func renderProgressBar(st Styles, prefix, suffix string, ratio float64, totalW i
 		suffix = shared.Truncate(suffix, maxSuffixW)
 	}
 	filled := int(ratio * float64(barW))
-	barRendered := st.Ok.Render(strings.Repeat("█", filled)) +
-		st.Muted.Render(strings.Repeat("░", barW-filled))
+	barRendered := st.Ok.Render(strings.Repeat(shared.Sym().BarFull, filled)) +
+		st.Muted.Render(strings.Repeat(shared.Sym().BarEmpty, barW-filled))
 	return lipgloss.JoinHorizontal(lipgloss.Top, prefix, barRendered, suffix)
 }
 
@@ -44,7 +46,7 @@ func dividerLine(st Styles, width int) string {
 	if width <= 0 {
 		return ""
 	}
-	return st.Divider.Render(strings.Repeat("─", width))
+	return st.Divider.Render(strings.Repeat(shared.Sym().HLine, width))
 }
 
 func renderHeader(st Styles, width int, title, subtitle, meta string, infoLeft, infoRight []string) string {
@@ -81,6 +83,14 @@ func renderHeader(st Styles, width int, title, subtitle, meta string, infoLeft,
 		"    ██    ", // 可视宽 10
 	}
 
+	if block := shared.Sym().ArtBlock; block != "█" {
+		for i := range artA {
+			artA[i] = strings.ReplaceAll(artA[i], "█", block)
+			artI[i] = strings.ReplaceAll(artI[i], "█", block)
+			artT[i] = strings.ReplaceAll(artT[i], "█", block)
+		}
+	}
+
 	styleA := lipgloss.NewStyle().Foreground(colorPink).Bold(true)
 	styleI := lipgloss.NewStyle().Foreground(colorGold).Bold(true)
 	styleT := lipgloss.NewStyle().Foreground(colorCyan).Bold(true)
@@ -89,7 +99,7 @@ func renderHeader(st Styles, width int, title, subtitle, meta string, infoLeft,
 	artRow := func(i int) string {
 		return styleA.Render(artA[i]) + "  " + styleI.Render(artI[i]) + "  " + styleT.Render(artT[i])
 	}
-	vsep := styleSep.Render("┃")
+	vsep := styleSep.Render(shared.Sym().VLine)
 
 	wideEnough := w >= 65 // 宽屏才展示 ASCII art
 
@@ -347,14 +357,344 @@ func appendRunMetricLines(lines []string, st Styles, rs *server.RunState) []stri
 	}
 	lw := shared.MaxLabelWidth(lbls)
 	lines = append(lines, " "+labelValue(st, lbls[0], st.MetricVal.Render(fmt.Sprintf("%.1f%%", rs.SuccessRate)), lw))
-	lines = append(lines, " "+labelValue(st, lbls[1], st.MetricVal.Render(fmt.Sprintf("%.1f tok/s", rs.AvgTPS)), lw))
-	lines = append(lines, " "+labelValue(st, lbls[2], st.MetricVal.Render(shared.FmtDuration(rs.AvgTTFT)), lw))
-	lines = append(lines, " "+labelValue(st, lbls[3], st.MetricVal.Render(fmt.Sprintf("%.1f%%", rs.CacheHitRate*100)), lw))
+	// 面板高度有限，实时 TPS 与均值同行展示
+	tpsText := fmt.Sprintf("%.1f tok/s", rs.AvgTPS)
+	if rs.AvgSteadyTPS > 0 {
+		tpsText += fmt.Sprintf(" · %s %.1f", i18n.T(i18n.KSteadyTPS), rs.AvgSteadyTPS)
+	}
+	if data, ok := rs.ModeResult.(*types.ReportData); ok && data.AvgNormalizedTPS > 0 {
+		tpsText += fmt.Sprintf(" · %s %.1f", i18n.T(i18n.KNormalizedTPS), data.AvgNormalizedTPS)
+	}
+	if shared.IsRunStateRunning(rs) {
+		tpsText += fmt.Sprintf(" · %s %.1f", i18n.T(i18n.KInstantTPS), rs.InstantTPS)
+	}
+	lines = append(lines, " "+labelValue(st, lbls[1], st.MetricVal.Render(tpsText), lw))
+	ttftText := shared.FmtDuration(rs.AvgTTFT)
+	if data, ok := rs.ModeResult.(*types.ReportData); ok && data.AvgTTFB > 0 {
+		ttftText += " · TTFB " + shared.FmtDuration(data.AvgTTFB)
+	}
+	if rs.AvgThinkingTime > 0 {
+		ttftText += fmt.Sprintf(" · %s %s", i18n.T(i18n.KThinkingTime), shared.FmtDuration(rs.AvgThinkingTime))
+	}
+	lines = append(lines, " "+labelValue(st, lbls[2], st.MetricVal.Render(ttftText), lw))
+	cacheText := fmt.Sprintf("%.1f%%", rs.CacheHitRate*100)
+	if rs.CachedTokenRatio > 0 {
+		cacheText += fmt.Sprintf(" · %s %.1f%%", i18n.T(i18n.KCachedTokenRatio), rs.CachedTokenRatio*100)
+	}
+	lines = append(lines, " "+labelValue(st, lbls[3], st.MetricVal.Render(cacheText), lw))
 	lines = append(lines, " "+labelValue(st, lbls[4], st.MetricVal.Render(fmt.Sprintf("%.0f req/min", rs.RPM)), lw))
 	lines = append(lines, " "+labelValue(st, lbls[5], st.MetricVal.Render(fmt.Sprintf("%.0f tok/min", rs.TPM)), lw))
 	return lines
 }
 
+// selfStatsText 把自监控结果压缩为一行：goroutine 峰值 · 堆峰值 · GC 次数（累计停顿），
+// 运行结束后有残留 goroutine 时追加残留数，超出阈值时附带疑似泄漏警告；goroutine 或堆内存持续增长时附带增长警告。
+func selfStatsText(s *types.SelfStats) string {
+	text := fmt.Sprintf("goroutine %d · heap %s · GC %d (%s)",
+		s.PeakGoroutines, shared.FmtBytes(s.PeakHeapAlloc), s.NumGC, s.GCPauseTotal.Round(time.Microsecond))
+	if s.ResidualGoroutines > 0 {
+		text += " · " + fmt.Sprintf(i18n.T(i18n.KSelfStatsResidualFmt), s.ResidualGoroutines)
+	}
+	if s.LeakSuspected() {
+		text += " ⚠ " + i18n.T(i18n.KSelfStatsLeak)
+	}
+	if s.Growing() {
+		text += " ⚠ " + i18n.T(i18n.KSelfStatsGrowing)
+	}
+	return text
+}
+
+// baselineText 把基线对比结果压缩为一行：新建基线、无回归或回退的指标数。
+func baselineText(b *types.BaselineResult) string {
+	switch {
+	case b.Error != "":
+		return b.Error
+	case b.Created:
+		return i18n.T(i18n.KBaselineSaved)
+	case b.Regressed:
+		n := 0
+		for _, m := range b.Metrics {
+			if m.Regressed {
+				n++
+			}
+		}
+		return fmt.Sprintf(i18n.T(i18n.KBaselineRegressedFmt), n)
+	default:
+		return i18n.T(i18n.KBaselinePassed)
+	}
+}
+
+// slaText 渲染一条 SLA 的评估结果：✅ ttft<800ms,p=95 96.0%。
+func slaText(r types.SLAResult) string {
+	mark := shared.Sym().Pass
+	if !r.Met {
+		mark = shared.Sym().Fail
+	}
+	return fmt.Sprintf("%s %s %.1f%%", mark, r.Expr, r.Rate)
+}
+
+// burstText 汇总突发模式各批：批数，以及批内 TTFT 均值最高的一批的均值与全部批次中的最大 TTFT。
+func burstText(bursts []types.BurstStats) string {
+	var worstAvg, maxTTFT time.Duration
+	for _, b := range bursts {
+		worstAvg = max(worstAvg, b.AvgTTFT)
+		maxTTFT = max(maxTTFT, b.MaxTTFT)
+	}
+	return fmt.Sprintf(i18n.T(i18n.KBurstFmt), len(bursts), shared.FmtDuration(worstAvg), shared.FmtDuration(maxTTFT))
+}
+
+// responseEncodingsText 按编码名排列各 Content-Encoding 的请求数、压缩率与平均 TTFT，如 "br ×10 · 压缩率 23% · TTFT 320ms"。
+func responseEncodingsText(encodings map[string]*types.EncodingStats) string {
+	names := make([]string, 0, len(encodings))
+	for name := range encodings {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		s := encodings[name]
+		parts = append(parts, fmt.Sprintf(i18n.T(i18n.KResponseEncodingFmt), name, s.Count, s.CompressionRatio*100, shared.FmtDuration(s.AvgTTFT)))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// networkProbeText 汇总基线网络探测：次数、失败数、探测延迟的最小 / 平均 / 最大值与业务请求的建连均值。
+func networkProbeText(s *types.NetworkProbeSummary) string {
+	return fmt.Sprintf(i18n.T(i18n.KNetworkProbeFmt), s.Samples, s.Errors,
+		shared.FmtDuration(s.Min), shared.FmtDuration(s.Avg), shared.FmtDuration(s.Max), shared.FmtDuration(s.RequestAvg))
+}
+
+// probeVerdictText 网络探测结论的提示文案，无结论时为空。
+func probeVerdictText(verdict string) string {
+	switch verdict {
+	case types.ProbeVerdictServer:
+		return i18n.T(i18n.KProbeVerdictServer)
+	case types.ProbeVerdictNetwork:
+		return i18n.T(i18n.KProbeVerdictNetwork)
+	}
+	return ""
+}
+
+// phaseTexts 分段分析的三行：预热段、稳态段、收尾段各自的请求数、平均 TTFT 与 TPS。
+func phaseTexts(p *types.PhaseStats) []string {
+	row := func(key i18n.Key, m types.PhaseMetrics) string {
+		return fmt.Sprintf(i18n.T(i18n.KPhaseFmt), i18n.T(key), m.Requests, shared.FmtDuration(m.AvgTTFT), m.AvgTPS)
+	}
+	return []string{
+		row(i18n.KPhaseWarmup, p.Warmup),
+		row(i18n.KPhaseSteady, p.Steady),
+		row(i18n.KPhaseCooldown, p.Cooldown),
+	}
+}
+
+// firstRequestTexts 返回首请求与其余请求平均值的两行文本。
+func firstRequestTexts(f *types.FirstRequestMetrics) []string {
+	return []string{
+		fmt.Sprintf(i18n.T(i18n.KFirstRequestFmt), f.Index, shared.FmtDuration(f.TTFT), shared.FmtDuration(f.TotalTime)),
+		fmt.Sprintf(i18n.T(i18n.KWarmRequestFmt), f.WarmRequests, shared.FmtDuration(f.WarmAvgTTFT), shared.FmtDuration(f.WarmAvgTotalTime)),
+	}
+}
+
+// streamCompareRow 流式 / 非流式并排对比中的一行。
+type streamCompareRow struct {
+	label, stream, nonStream string
+}
+
+// streamCompareRows 把 A/B 对比结果整理为并排对比行：首行为列头，其后为 TTFT、总耗时、TPS 与成功率。
+// 非流式的 TTFT 没有意义，某一轮未完成（如中途停止）时该列显示占位符。
+func streamCompareRows(r *types.StreamCompareResult) []streamCompareRow {
+	none := shared.Sym().None
+	col := func(d *types.ReportData, format func(*types.ReportData) string) string {
+		if d == nil {
+			return none
+		}
+		return format(d)
+	}
+	row := func(label string, format func(*types.ReportData) string) streamCompareRow {
+		return streamCompareRow{label, col(r.Stream, format), col(r.NonStream, format)}
+	}
+	ttft := row("TTFT", func(d *types.ReportData) string { return shared.FmtDuration(d.AvgTTFT) })
+	ttft.nonStream = none
+	return []streamCompareRow{
+		{i18n.T(i18n.KStreamCompare), i18n.T(i18n.KStream), i18n.T(i18n.KNonStream)},
+		ttft,
+		row(i18n.T(i18n.KTotalTime), func(d *types.ReportData) string { return shared.FmtDuration(d.AvgTotalTime) }),
+		row(i18n.T(i18n.KOutputTPS), func(d *types.ReportData) string { return fmt.Sprintf("%.1f", d.AvgTPS) }),
+		row(i18n.T(i18n.KSuccessRate), func(d *types.ReportData) string { return fmt.Sprintf("%.1f%%", d.SuccessRate) }),
+	}
+}
+
+// httpVersionCompareRows 把 HTTP 版本 A/B 对比结果整理为并排对比行，左列为 HTTP/1.1、右列为 HTTP/2；
+// 首行为列头，其后为 TTFT（仅流式）、总耗时、TPS、成功率与实际协商到的协议版本。
+func httpVersionCompareRows(r *types.HTTPVersionCompareResult) []streamCompareRow {
+	none := shared.Sym().None
+	col := func(d *types.ReportData, format func(*types.ReportData) string) string {
+		if d == nil {
+			return none
+		}
+		return format(d)
+	}
+	row := func(label string, format func(*types.ReportData) string) streamCompareRow {
+		return streamCompareRow{label, col(r.HTTP1, format), col(r.HTTP2, format)}
+	}
+	rows := []streamCompareRow{{i18n.T(i18n.KHTTPVersionCompare), "HTTP/1.1", "HTTP/2"}}
+	if r.HTTP1 != nil && r.HTTP1.IsStream {
+		rows = append(rows, row("TTFT", func(d *types.ReportData) string { return shared.FmtDuration(d.AvgTTFT) }))
+	}
+	return append(rows,
+		row(i18n.T(i18n.KTotalTime), func(d *types.ReportData) string { return shared.FmtDuration(d.AvgTotalTime) }),
+		row(i18n.T(i18n.KOutputTPS), func(d *types.ReportData) string { return fmt.Sprintf("%.1f", d.AvgTPS) }),
+		row(i18n.T(i18n.KSuccessRate), func(d *types.ReportData) string { return fmt.Sprintf("%.1f%%", d.SuccessRate) }),
+		// 协商版本与网络错误同为按请求数计数，沿用同一格式
+		row(i18n.T(i18n.KHTTPProtocols), func(d *types.ReportData) string {
+			if len(d.HTTPProtocols) == 0 {
+				return none
+			}
+			return netErrorKindsText(d.HTTPProtocols)
+		}),
+	)
+}
+
+// blockStartsText 按开始时间先后列出各类型 content block 的平均开始时间，如 "thinking 120ms · text 3.4s"。
+func blockStartsText(starts map[string]time.Duration) string {
+	kinds := make([]string, 0, len(starts))
+	for t := range starts {
+		kinds = append(kinds, t)
+	}
+	sort.Slice(kinds, func(i, j int) bool {
+		if starts[kinds[i]] != starts[kinds[j]] {
+			return starts[kinds[i]] < starts[kinds[j]]
+		}
+		return kinds[i] < kinds[j]
+	})
+	parts := make([]string, len(kinds))
+	for i, t := range kinds {
+		parts[i] = t + " " + shared.FmtDuration(starts[t])
+	}
+	return strings.Join(parts, " · ")
+}
+
+// netErrorKindsText 按请求数从多到少列出各类网络错误，如 "connection_refused 12 · dns 3"。
+func netErrorKindsText(kinds map[string]int) string {
+	names := make([]string, 0, len(kinds))
+	for k := range kinds {
+		names = append(names, k)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if kinds[names[i]] != kinds[names[j]] {
+			return kinds[names[i]] > kinds[names[j]]
+		}
+		return names[i] < names[j]
+	})
+	parts := make([]string, len(names))
+	for i, k := range names {
+		parts[i] = fmt.Sprintf("%s %d", k, kinds[k])
+	}
+	return strings.Join(parts, " · ")
+}
+
+// maxVariantLines 仪表盘最多列出的输出版本数
+const maxVariantLines = 5
+
+// outputVariantTexts 为确定性验证的各输出版本生成一行摘要：最常见版本显示开头片段，
+// 其余版本显示与它第一个不同字符的位置及从该处起的片段，如 "12.5% @48 …而不是"。
+func outputVariantTexts(variants []types.OutputVariant) []string {
+	texts := make([]string, 0, min(len(variants), maxVariantLines))
+	for i, v := range variants {
+		if i == maxVariantLines {
+			break
+		}
+		excerpt := strings.Join(strings.Fields(v.Excerpt), " ")
+		if i == 0 {
+			texts = append(texts, fmt.Sprintf("%.1f%% %s", v.Rate, excerpt))
+			continue
+		}
+		texts = append(texts, fmt.Sprintf("%.1f%% @%d %s", v.Rate, v.DiffOffset, excerpt))
+	}
+	return texts
+}
+
+// contentBlocksText 按到达顺序列出单个请求各 content block 的起止时间，如 "thinking 0.1s-2.3s · text 2.4s-5.0s"。
+func contentBlocksText(blocks []types.ContentBlockTiming) string {
+	parts := make([]string, 0, len(blocks))
+	for _, b := range blocks {
+		stop := shared.Sym().None
+		if b.Stop > 0 {
+			stop = shared.FmtDuration(b.Stop)
+		}
+		parts = append(parts, fmt.Sprintf("%s %s-%s", b.Type, shared.FmtDuration(b.Start), stop))
+	}
+	return strings.Join(parts, " · ")
+}
+
+// lengthSweepText 输入长度扫描中一个长度的结果：目标长度、实际平均输入 token、平均 TTFT 与 prefill TPS。
+func lengthSweepText(d *types.ReportData) string {
+	return fmt.Sprintf(i18n.T(i18n.KLengthSweepFmt), d.InputLength, d.AvgInputTokenCount, shared.FmtDuration(d.AvgTTFT), d.AvgPrefillTPS)
+}
+
+// promptFileScenarioText 按 prompt 文件分场景运行中一个场景的结果：场景名、成功率、平均总耗时与平均 TPS。
+func promptFileScenarioText(d *types.ReportData) string {
+	return fmt.Sprintf(i18n.T(i18n.KPromptFileScenarioFmt), d.Scenario, d.SuccessRate, shared.FmtDuration(d.AvgTotalTime), d.AvgTPS)
+}
+
+// targetIPStatsTexts 按 IP 排序把各目标 IP 的统计格式化为一行一个，DNS 解析到但从未连接的 IP 也各占一行；
+// 总共只有一个 IP 时返回 nil，沿用原有展示。
+func targetIPStatsTexts(stats map[string]types.TargetIPStats, resolved []string) []string {
+	ips := make([]string, 0, len(stats)+len(resolved))
+	for ip := range stats {
+		ips = append(ips, ip)
+	}
+	for _, ip := range resolved {
+		if _, ok := stats[ip]; !ok {
+			ips = append(ips, ip)
+		}
+	}
+	if len(ips) <= 1 {
+		return nil
+	}
+	sort.Strings(ips)
+	texts := make([]string, len(ips))
+	for i, ip := range ips {
+		s, ok := stats[ip]
+		if !ok {
+			texts[i] = fmt.Sprintf(i18n.T(i18n.KTargetIPUnusedFmt), ip)
+			continue
+		}
+		texts[i] = fmt.Sprintf(i18n.T(i18n.KTargetIPStatFmt), ip, s.Count, shared.FmtDuration(s.AvgTotalTime), shared.FmtDuration(s.AvgTTFT))
+	}
+	return texts
+}
+
+// finishReasonsText 把结束原因分布压缩为一行，按请求数从多到少排列：stop 92% · length 8%。
+func finishReasonsText(counts map[string]int) string {
+	reasons := make([]string, 0, len(counts))
+	total := 0
+	for reason, n := range counts {
+		reasons = append(reasons, reason)
+		total += n
+	}
+	sort.Slice(reasons, func(i, j int) bool {
+		if counts[reasons[i]] != counts[reasons[j]] {
+			return counts[reasons[i]] > counts[reasons[j]]
+		}
+		return reasons[i] < reasons[j]
+	})
+	parts := make([]string, len(reasons))
+	for i, reason := range reasons {
+		parts[i] = fmt.Sprintf("%s %.0f%%", reason, float64(counts[reason])/float64(total)*100)
+	}
+	return strings.Join(parts, " · ")
+}
+
+// maxTokensUsageText 把 max_tokens 用量压缩为一行：截断占比 · 平均用量，有请求超出上限时追加警告。
+func maxTokensUsageText(u *types.MaxTokensUsage) string {
+	text := fmt.Sprintf(i18n.T(i18n.KMaxTokensUsageFmt), u.TruncatedRate, u.AvgRatio*100)
+	if u.Exceeded > 0 {
+		text += " · " + fmt.Sprintf(i18n.T(i18n.KMaxTokensExceededFmt), u.Exceeded)
+	}
+	return text
+}
+
 func panelTitleLines(st Styles, title string, width int, compact bool) []string {
 	var lines []string
 	if compact {
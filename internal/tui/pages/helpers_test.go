@@ -0,0 +1,121 @@
+package pages
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yinxulai/ait/internal/server/types"
+	"github.com/yinxulai/ait/internal/tui/pages/shared"
+)
+
+func TestFinishReasonsText(t *testing.T) {
+	got := finishReasonsText(map[string]int{"length": 2, "stop": 7, "content_filter": 1})
+	want := "stop 70% · length 20% · content_filter 10%"
+	if got != want {
+		t.Errorf("finishReasonsText = %q, want %q", got, want)
+	}
+}
+
+func TestMaxTokensUsageText(t *testing.T) {
+	got := maxTokensUsageText(&types.MaxTokensUsage{TruncatedRate: 40, AvgRatio: 0.85})
+	if got != "40% 请求被 max_tokens 截断 · 平均用量 85%" {
+		t.Errorf("maxTokensUsageText = %q", got)
+	}
+	got = maxTokensUsageText(&types.MaxTokensUsage{TruncatedRate: 100, AvgRatio: 1.5, Exceeded: 2})
+	if !strings.HasSuffix(got, " · 2 条超出上限，服务可能忽略了 max_tokens") {
+		t.Errorf("maxTokensUsageText = %q, want exceeded warning", got)
+	}
+}
+
+func TestTargetIPStatsTexts(t *testing.T) {
+	single := map[string]types.TargetIPStats{"10.0.0.1": {Count: 3}}
+	if got := targetIPStatsTexts(single, []string{"10.0.0.1"}); got != nil {
+		t.Errorf("single ip should return nil, got %q", got)
+	}
+	got := targetIPStatsTexts(map[string]types.TargetIPStats{
+		"10.0.0.2": {Count: 3, AvgTotalTime: 2 * time.Second, AvgTTFT: 500 * time.Millisecond},
+		"10.0.0.1": {Count: 7, AvgTotalTime: time.Second, AvgTTFT: 200 * time.Millisecond},
+	}, nil)
+	if len(got) != 2 || !strings.HasPrefix(got[0], "10.0.0.1  7 ") || !strings.HasPrefix(got[1], "10.0.0.2  3 ") {
+		t.Errorf("targetIPStatsTexts = %q, want sorted by ip", got)
+	}
+
+	// 解析到但从未连接的 IP 单独成行
+	got = targetIPStatsTexts(single, []string{"10.0.0.3", "10.0.0.1"})
+	if len(got) != 2 || !strings.HasPrefix(got[0], "10.0.0.1  3 ") || got[1] != "10.0.0.3  解析到但未连接" {
+		t.Errorf("targetIPStatsTexts with unused resolved ip = %q", got)
+	}
+}
+
+func TestASCIISymbols(t *testing.T) {
+	shared.SetASCII(true)
+	defer shared.SetASCII(false)
+
+	if got := slaText(types.SLAResult{Expr: "ttft<800ms", Met: false, Rate: 90}); got != "[FAIL] ttft<800ms 90.0%" {
+		t.Errorf("slaText = %q", got)
+	}
+	bar := stripANSI(renderProgressBar(NewStyles(), "[", "]", 0.5, 12))
+	if bar != "[#####.....]" {
+		t.Errorf("renderProgressBar = %q", bar)
+	}
+	if got := HotkeyAction("←→/Space", "").Key; got != "Left/Right/Space" {
+		t.Errorf("HotkeyAction key = %q", got)
+	}
+	header := stripANSI(renderHeader(NewStyles(), 100, "task", "", "", nil, nil))
+	for _, r := range header {
+		if r > 0x7e {
+			t.Fatalf("header contains non-ASCII rune %q:\n%s", r, header)
+		}
+	}
+}
+
+func TestStreamCompareRows(t *testing.T) {
+	rows := streamCompareRows(&types.StreamCompareResult{
+		Stream:    &types.ReportData{AvgTTFT: 200 * time.Millisecond, AvgTotalTime: time.Second, AvgTPS: 42, SuccessRate: 100},
+		NonStream: &types.ReportData{AvgTTFT: 900 * time.Millisecond, AvgTotalTime: 900 * time.Millisecond, AvgTPS: 50, SuccessRate: 100},
+	})
+	if len(rows) != 5 {
+		t.Fatalf("rows = %+v, want header + 4 metrics", rows)
+	}
+	if rows[1].label != "TTFT" || rows[1].stream == shared.Sym().None || rows[1].nonStream != shared.Sym().None {
+		t.Errorf("TTFT row = %+v, want non-stream placeholder", rows[1])
+	}
+	if rows[3].stream != "42.0" || rows[3].nonStream != "50.0" {
+		t.Errorf("TPS row = %+v", rows[3])
+	}
+
+	// 非流式一轮未完成（中途停止）时整列为占位符
+	rows = streamCompareRows(&types.StreamCompareResult{Stream: &types.ReportData{AvgTPS: 1}})
+	for _, row := range rows[1:] {
+		if row.nonStream != shared.Sym().None {
+			t.Errorf("row %q non-stream = %q, want placeholder", row.label, row.nonStream)
+		}
+	}
+}
+
+func TestHTTPVersionCompareRows(t *testing.T) {
+	rows := httpVersionCompareRows(&types.HTTPVersionCompareResult{
+		HTTP1: &types.ReportData{AvgTPS: 40, SuccessRate: 100, HTTPProtocols: map[string]int{"HTTP/1.1": 10}},
+		HTTP2: &types.ReportData{AvgTPS: 45, SuccessRate: 100, HTTPProtocols: map[string]int{"HTTP/2.0": 10}},
+	})
+	if len(rows) != 5 {
+		t.Fatalf("rows = %+v, want header + 4 metrics for non-stream runs", rows)
+	}
+	if rows[2].stream != "40.0" || rows[2].nonStream != "45.0" {
+		t.Errorf("TPS row = %+v", rows[2])
+	}
+	if last := rows[len(rows)-1]; last.stream != "HTTP/1.1 10" || last.nonStream != "HTTP/2.0 10" {
+		t.Errorf("protocols row = %+v", last)
+	}
+
+	rows = httpVersionCompareRows(&types.HTTPVersionCompareResult{HTTP1: &types.ReportData{IsStream: true}})
+	if rows[1].label != "TTFT" {
+		t.Errorf("stream runs should include TTFT, got %+v", rows[1])
+	}
+	for _, row := range rows[1:] {
+		if row.nonStream != shared.Sym().None {
+			t.Errorf("row %q HTTP/2 = %q, want placeholder", row.label, row.nonStream)
+		}
+	}
+}
@@ -232,5 +232,5 @@ func renderTooSmall(st Styles, width, _ int) string {
 	if width < 4 {
 		return "..."
 	}
-	return st.Muted.Render(shared.Truncate(i18n.T(i18n.KWindowTooSmall), width))
+	return st.Muted.Render(shared.Truncate(shared.ASCIIText(i18n.T(i18n.KWindowTooSmall)), width))
 }
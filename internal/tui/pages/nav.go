@@ -49,6 +49,8 @@ type Client interface {
 	// 运行管理
 	StartRunCmd(taskID string) tea.Cmd
 	StopRunCmd(runID server.RunID) tea.Cmd
+	AdjustRunConcurrencyCmd(runID server.RunID, delta int) tea.Cmd
+	SetRunPausedCmd(runID server.RunID, paused bool) tea.Cmd
 
 	// 历史 & 报告
 	LoadTaskRunHistoryCmd(taskID string, limit int) tea.Cmd
@@ -170,23 +170,38 @@ func buildReqPerfPanel(r *types.RequestMetrics, st Styles, maxH, width int) stri
 		return finishPanelLines(lines, maxH)
 	}
 
-	statusStr := st.Ok.Render("✓ " + i18n.T(i18n.KCompleted))
+	statusStr := st.Ok.Render(shared.Sym().Ok + " " + i18n.T(i18n.KCompleted))
 	if !r.Success {
-		statusStr = st.ErrStyle.Render("✗ " + i18n.T(i18n.KRunFailed))
+		statusStr = st.ErrStyle.Render(shared.Sym().Err + " " + i18n.T(i18n.KRunFailed))
 	}
-	totalTime := "─"
+	if r.OriginIndex != nil {
+		statusStr += st.Muted.Render(" · " + fmt.Sprintf(i18n.T(i18n.KReplayOriginFmt), *r.OriginIndex))
+	}
+	totalTime := shared.Sym().None
 	if r.TotalTime > 0 {
 		totalTime = shared.FmtDuration(r.TotalTime)
 	}
-	ttft := "─"
+	if r.QueueWaitTime > 0 {
+		totalTime += fmt.Sprintf(" · %s %s", i18n.T(i18n.KQueueWait), shared.FmtDuration(r.QueueWaitTime))
+	}
+	ttft := shared.Sym().None
 	if r.TTFT > 0 {
 		ttft = shared.FmtDuration(r.TTFT)
 	}
-	tps := "─"
+	if r.TTFB > 0 {
+		ttft += " · TTFB " + shared.FmtDuration(r.TTFB)
+	}
+	if r.ThinkingTime > 0 {
+		ttft += fmt.Sprintf(" · %s %s", i18n.T(i18n.KThinkingTime), shared.FmtDuration(r.ThinkingTime))
+	}
+	tps := shared.Sym().None
 	if r.TPS > 0 {
 		tps = fmt.Sprintf("%.1f tok/s", r.TPS)
 	}
 	tokenSummary := fmt.Sprintf("%d in / %d out", r.PromptTokens, r.CompletionTokens)
+	if r.FinishReason != "" {
+		tokenSummary += fmt.Sprintf(" · %s %s", i18n.T(i18n.KFinishReason), r.FinishReason)
+	}
 	cacheSummary := fmt.Sprintf("%d tok (%.1f%%)", r.CachedTokens, r.CacheHitRate*100)
 	errorSummary := "—"
 	if !r.Success {
@@ -201,6 +216,9 @@ func buildReqPerfPanel(r *types.RequestMetrics, st Styles, maxH, width int) stri
 		i18n.T(i18n.KStatus), i18n.T(i18n.KTotalTime), "TTFT",
 		i18n.T(i18n.KOutputTPS), i18n.T(i18n.KToken), i18n.T(i18n.KCache),
 	}
+	if len(r.ContentBlocks) > 0 {
+		lbls = append(lbls, i18n.T(i18n.KBlockTiming))
+	}
 	lw := shared.MaxLabelWidth(lbls)
 	lines = append(lines, " "+labelValue(st, lbls[0], statusStr, lw))
 	lines = append(lines, " "+labelValue(st, lbls[1], st.MetricVal.Render(totalTime), lw))
@@ -212,6 +230,9 @@ func buildReqPerfPanel(r *types.RequestMetrics, st Styles, maxH, width int) stri
 	} else {
 		lines = append(lines, " "+st.ErrStyle.Render(i18n.T(i18n.KError)+": "+errorSummary))
 	}
+	if len(r.ContentBlocks) > 0 {
+		lines = append(lines, " "+labelValue(st, i18n.T(i18n.KBlockTiming), shared.Truncate(contentBlocksText(r.ContentBlocks), shared.MaxInt(8, width-lw-3)), lw))
+	}
 
 	return finishPanelLines(lines, maxH)
 }
@@ -227,6 +248,7 @@ func buildReqNetworkPanel(r *types.RequestMetrics, st Styles, maxH, width int) s
 
 	lbls := []string{
 		i18n.T(i18n.KDNS), i18n.T(i18n.KTCPConnect), i18n.T(i18n.KTLSHandshake), i18n.T(i18n.KTargetIP),
+		i18n.T(i18n.KTraceID), i18n.T(i18n.KRequestID),
 	}
 	lw := shared.MaxLabelWidth(lbls)
 	lines = append(lines, " "+labelValue(st, lbls[0], shared.FmtDuration(r.DNSTime), lw))
@@ -235,13 +257,27 @@ func buildReqNetworkPanel(r *types.RequestMetrics, st Styles, maxH, width int) s
 	// 始终显示目标IP行，保持高度一致
 	targetIPValue := "—"
 	if r.TargetIP != "" {
-		targetIPValue = shared.Truncate(r.TargetIP, shared.MaxInt(4, width-12))
+		targetIPValue = r.TargetIP
+		if len(r.ResolvedIPs) > 1 {
+			targetIPValue = fmt.Sprintf(i18n.T(i18n.KResolvedIPsFmt), r.TargetIP, len(r.ResolvedIPs))
+		}
+		targetIPValue = shared.Truncate(targetIPValue, shared.MaxInt(4, width-12))
 	}
 	lines = append(lines, " "+labelValue(st, lbls[3], targetIPValue, lw))
+	lines = append(lines, " "+labelValue(st, lbls[4], traceIDValue(r.ClientRequestID, width), lw))
+	lines = append(lines, " "+labelValue(st, lbls[5], traceIDValue(r.ServerRequestID, width), lw))
 
 	return finishPanelLines(lines, maxH)
 }
 
+// traceIDValue 返回 trace id 的展示值，未记录时显示 "—"。
+func traceIDValue(id string, width int) string {
+	if id == "" {
+		return "—"
+	}
+	return shared.Truncate(id, shared.MaxInt(4, width-14))
+}
+
 // buildInputSection 构建输入 (请求体) 区域。
 func buildInputSection(r *types.RequestMetrics, st Styles, width, maxH int) string {
 	lines := panelTitleLines(st, i18n.T(i18n.KRequestBody), width, true)
@@ -283,7 +319,7 @@ func buildOutputSection(r *types.RequestMetrics, scrollY int, st Styles, width,
 			lines = append(lines, " "+l)
 		}
 		if len(allLines) > maxH-3 {
-			lines = append(lines, " "+st.Muted.Render("("+i18n.T(i18n.KScrollMore)+")"))
+			lines = append(lines, " "+st.Muted.Render("("+shared.ASCIIText(i18n.T(i18n.KScrollMore))+")"))
 		}
 	}
 
@@ -54,3 +54,20 @@ func TestRenderReqDetailKeepsSameHeightForSuccessAndFailure(t *testing.T) {
 		t.Fatalf("expected failure error summary to be normalized into a single visual line")
 	}
 }
+
+func TestRenderReqDetailShowsFinishReason(t *testing.T) {
+	state := &ReqDetailState{
+		RunID: server.RunID("run_finish"),
+		Requests: []*types.RequestMetrics{{
+			Success:          true,
+			TotalTime:        time.Second,
+			PromptTokens:     8,
+			CompletionTokens: 256,
+			FinishReason:     "length",
+		}},
+	}
+	out := stripANSI(RenderReqDetail(state, "示例任务", NewStyles(), 120, 30))
+	if !strings.Contains(out, "256 out · 结束原因 length") {
+		t.Errorf("expected finish reason next to token counts:\n%s", out)
+	}
+}
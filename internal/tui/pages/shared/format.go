@@ -25,6 +25,20 @@ func FmtDuration(d time.Duration) string {
 	return fmt.Sprintf("%ds", s)
 }
 
+// FmtBytes 以 1024 进制格式化字节数（如"12.3MB"）。
+func FmtBytes(b uint64) string {
+	const unit = 1024
+	if b < unit {
+		return fmt.Sprintf("%dB", b)
+	}
+	div, exp := uint64(unit), 0
+	for n := b / unit; n >= unit && exp < 3; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%cB", float64(b)/float64(div), "KMGT"[exp])
+}
+
 // FmtRelativeTime 返回相对时间的友好文本（如"2小时前"、"刚刚"）。
 func FmtRelativeTime(t time.Time) string {
 	if t.IsZero() {
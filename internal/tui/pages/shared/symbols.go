@@ -0,0 +1,104 @@
+package shared
+
+import (
+	"strings"
+
+	"charm.land/lipgloss/v2"
+)
+
+// Symbols 汇聚 TUI 渲染中使用的所有图形符号，便于在 Unicode 与纯 ASCII 之间整体切换。
+type Symbols struct {
+	Ok        string // 成功标记
+	Err       string // 失败标记
+	Pass      string // 达标标记（SLA 等）
+	Fail      string // 未达标标记
+	Bullet    string // 列表圆点
+	Arrow     string // 指向箭头
+	None      string // 空值占位
+	HLine     string // 水平分隔线
+	VLine     string // 竖直分隔线
+	BarFull   string // 进度条已完成
+	BarEmpty  string // 进度条未完成
+	UpDown    string // 上下方向键
+	LeftRight string // 左右方向键
+	ArtBlock  string // 字符画像素块
+
+	Border      lipgloss.Border // 面板 / 输入框边框
+	AppBorder   lipgloss.Border // 最外层边框
+	TableBorder lipgloss.Border // 表格边框
+}
+
+// UnicodeSymbols 为默认符号集。
+var UnicodeSymbols = Symbols{
+	Ok:        "✓",
+	Err:       "✗",
+	Pass:      "✅",
+	Fail:      "❌",
+	Bullet:    "●",
+	Arrow:     "→",
+	None:      "─",
+	HLine:     "─",
+	VLine:     "┃",
+	BarFull:   "█",
+	BarEmpty:  "░",
+	UpDown:    "↑↓",
+	LeftRight: "←→",
+	ArtBlock:  "█",
+
+	Border:      lipgloss.NormalBorder(),
+	AppBorder:   lipgloss.RoundedBorder(),
+	TableBorder: lipgloss.RoundedBorder(),
+}
+
+// ASCIISymbols 为纯 ASCII 符号集，适用于不支持 Unicode 的终端或日志采集。
+var ASCIISymbols = Symbols{
+	Ok:        "[OK]",
+	Err:       "[ERR]",
+	Pass:      "[PASS]",
+	Fail:      "[FAIL]",
+	Bullet:    "*",
+	Arrow:     "->",
+	None:      "-",
+	HLine:     "-",
+	VLine:     "|",
+	BarFull:   "#",
+	BarEmpty:  ".",
+	UpDown:    "Up/Down",
+	LeftRight: "Left/Right",
+	ArtBlock:  "#",
+
+	Border:      lipgloss.ASCIIBorder(),
+	AppBorder:   lipgloss.ASCIIBorder(),
+	TableBorder: lipgloss.ASCIIBorder(),
+}
+
+var sym = UnicodeSymbols
+
+// SetASCII 切换为纯 ASCII 符号集（enabled=false 恢复 Unicode），应在渲染前调用。
+func SetASCII(enabled bool) {
+	if enabled {
+		sym = ASCIISymbols
+	} else {
+		sym = UnicodeSymbols
+	}
+}
+
+// Sym 返回当前生效的符号集。
+func Sym() Symbols {
+	return sym
+}
+
+// ASCIIText 在 ASCII 模式下将文案中的 Unicode 箭头替换为 ASCII 等价，其余情况原样返回。
+func ASCIIText(s string) string {
+	if sym.UpDown == UnicodeSymbols.UpDown {
+		return s
+	}
+	return asciiReplacer.Replace(s)
+}
+
+var asciiReplacer = strings.NewReplacer(
+	"↑↓", ASCIISymbols.UpDown,
+	"←→", ASCIISymbols.LeftRight,
+	"↔", "<->",
+	"→", ASCIISymbols.Arrow,
+)
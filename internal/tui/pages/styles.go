@@ -1,6 +1,9 @@
 package pages
 
-import "charm.land/lipgloss/v2"
+import (
+	"charm.land/lipgloss/v2"
+	"github.com/yinxulai/ait/internal/tui/pages/shared"
+)
 
 // Color palette
 var (
@@ -108,14 +111,14 @@ func NewStyles() Styles {
 		FieldActive: lipgloss.NewStyle().
 			Background(lipgloss.Color("236")).
 			Foreground(colorWhite).
-			Border(lipgloss.NormalBorder()).
+			Border(shared.Sym().Border).
 			BorderForeground(colorPink).
 			Bold(true).
 			Padding(0, 1),
 		FieldIdle: lipgloss.NewStyle().
 			Background(lipgloss.Color("234")).
 			Foreground(colorWhite).
-			Border(lipgloss.NormalBorder()).
+			Border(shared.Sym().Border).
 			BorderForeground(lipgloss.Color("238")).
 			Padding(0, 1),
 		Cursor: lipgloss.NewStyle().
@@ -136,10 +139,10 @@ func NewStyles() Styles {
 		Divider: lipgloss.NewStyle().
 			Foreground(colorDivider),
 		Panel: lipgloss.NewStyle().
-			Border(lipgloss.NormalBorder()).
+			Border(shared.Sym().Border).
 			BorderForeground(colorDivider),
 		AppBorder: lipgloss.NewStyle().
-			Border(lipgloss.RoundedBorder()).
+			Border(shared.Sym().AppBorder).
 			BorderForeground(colorPink).
 			BorderTop(true).BorderBottom(true).BorderLeft(true).BorderRight(true),
 	}
@@ -3,6 +3,7 @@ package pages
 import (
 	"github.com/yinxulai/ait/internal/tui/pages/shared"
 	"fmt"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -11,6 +12,8 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/yinxulai/ait/internal/i18n"
 	"github.com/yinxulai/ait/internal/server"
+	"github.com/yinxulai/ait/internal/server/network"
+	"github.com/yinxulai/ait/internal/server/redact"
 	"github.com/yinxulai/ait/internal/server/types"
 )
 
@@ -234,9 +237,14 @@ func buildTaskDetailContent(s *TaskDetailState, st Styles, t types.TaskDefinitio
 		proxy := shared.Truncate(inp.ProxyURL, leftW-8)
 		leftLines = append(leftLines, shared.PadRight(" "+st.Label.Render(i18n.T(i18n.KProxy))+"  "+st.Value.Render(proxy), leftW))
 	}
+	// --resolve / --dns-server 为进程级配置，对所有任务生效
+	if dns := network.CurrentDNSConfig(); dns.Enabled() {
+		policy := shared.Truncate(dns.String(), leftW-8)
+		leftLines = append(leftLines, shared.PadRight(" "+st.Label.Render(i18n.T(i18n.KDNSPolicy))+"  "+st.Value.Render(policy), leftW))
+	}
 	leftLines = append(leftLines, shared.PadRight("", leftW))
 
-	model := shared.Truncate(inp.Model, leftW-10)
+	model := shared.Truncate(inp.DisplayModel(), leftW-10)
 	leftLines = append(leftLines, shared.PadRight(" "+st.Label.Render(i18n.T(i18n.KModel))+"  "+st.Value.Render(model), leftW))
 	modeStr := i18n.T(i18n.KStandardMode)
 	if inp.Turbo {
@@ -246,19 +254,33 @@ func buildTaskDetailContent(s *TaskDetailState, st Styles, t types.TaskDefinitio
 	if inp.Turbo {
 		tc := inp.TurboConfig
 		leftLines = append(leftLines, shared.PadRight(" "+st.Label.Render(i18n.T(i18n.KConcurrency))+"  "+st.Value.Render(
-			fmt.Sprintf("%d → %d", tc.InitConcurrency, tc.MaxConcurrency)), leftW))
+			fmt.Sprintf("%d %s %d", tc.InitConcurrency, shared.Sym().Arrow, tc.MaxConcurrency)), leftW))
 		leftLines = append(leftLines, shared.PadRight(" "+st.Label.Render(i18n.T(i18n.KStepLabel))+"  "+st.Value.Render(
 			fmt.Sprintf("+%d  %d req", tc.StepSize, tc.LevelRequests)), leftW))
 	} else {
 		leftLines = append(leftLines, shared.PadRight(" "+st.Label.Render(i18n.T(i18n.KConcurrency))+"  "+st.Value.Render(
 			fmt.Sprintf("%d", inp.Concurrency)), leftW))
+		requests := fmt.Sprintf("%d", inp.Count)
+		if shard := server.CurrentShard(); shard.Enabled() {
+			requests += " · " + fmt.Sprintf(i18n.T(i18n.KShardFmt), shard, shard.Count(inp.Count))
+		}
 		leftLines = append(leftLines, shared.PadRight(" "+st.Label.Render(i18n.T(i18n.KRequests))+"  "+st.Value.Render(
-			fmt.Sprintf("%d", inp.Count)), leftW))
+			shared.Truncate(requests, leftW-10)), leftW))
 	}
 	leftLines = append(leftLines, shared.PadRight("", leftW))
 	leftLines = append(leftLines, shared.PadRight(" "+st.Label.Render(i18n.T(i18n.KTimeout))+"  "+st.Value.Render(shared.FmtDuration(inp.Timeout)), leftW))
 	leftLines = append(leftLines, shared.PadRight(" "+st.Label.Render(i18n.T(i18n.KStream))+"  "+st.Value.Render(boolLabel(inp.Stream)), leftW))
 	prompt := promptSummary(inp.PromptMode, inp.PromptText, inp.PromptFile, inp.PromptLength)
+	if inp.PromptLengthDist != "" {
+		prompt = fmt.Sprintf(i18n.T(i18n.KPromptLengthDistFmt), inp.PromptLengthDist)
+	}
+	if inp.ToolsFile != "" {
+		prompt += " · " + fmt.Sprintf(i18n.T(i18n.KToolsFileFmt), filepath.Base(inp.ToolsFile))
+	}
+	// --redact 为进程级配置，对所有任务的日志、上报与报告生效
+	if redact.Enabled() {
+		prompt += " · " + i18n.T(i18n.KRedacted)
+	}
 	leftLines = append(leftLines, shared.PadRight(" "+st.Label.Render(i18n.T(i18n.KPromptLabel))+"  "+st.Value.Render(shared.Truncate(prompt, leftW-12)), leftW))
 	leftContent := finishPanelLines(leftLines, panelContentH)
 
@@ -321,20 +343,20 @@ func buildTaskDetailContent(s *TaskDetailState, st Styles, t types.TaskDefinitio
 	if hasActive {
 		rs := s.ActiveRun
 		modeShort := shared.ModeShortLabel(rs.Mode)
-		rateStr := "─"
+		rateStr := shared.Sym().None
 		if rs.TotalReqs > 0 {
 			rateStr = fmt.Sprintf("%.1f%%", rs.SuccessRate)
 		}
 		rowData[0] = histRow{
 			isRunning: true,
-			time:      "● " + rs.StartedAt.Format("2006-01-02 15:04"),
+			time:      shared.Sym().Bullet + " " + rs.StartedAt.Format("2006-01-02 15:04"),
 			mode:      modeShort,
 			rate:      rateStr,
-			dur:       "─",
-			ttft:      "─",
-			tps:       "─",
-			rpm:       "─",
-			tpm:       "─",
+			dur:       shared.Sym().None,
+			ttft:      shared.Sym().None,
+			tps:       shared.Sym().None,
+			rpm:       shared.Sym().None,
+			tpm:       shared.Sym().None,
 		}
 	}
 	for histIdx := 0; histIdx < len(historyEntries); histIdx++ {
@@ -347,13 +369,13 @@ func buildTaskDetailContent(s *TaskDetailState, st Styles, t types.TaskDefinitio
 		isFailed := run.Status == string(server.RunStatusFailed)
 		isMuted := run.Status == string(server.RunStatusStopped)
 		modeShort := shared.ModeShortLabel(run.Mode)
-		durText := "─"
+		durText := shared.Sym().None
 		if !run.FinishedAt.IsZero() {
 			durText = shared.FmtDuration(run.FinishedAt.Sub(run.StartedAt))
 		}
 		timeText := run.StartedAt.Format("2006-01-02 15:04")
 		if isRunning {
-			timeText = "● " + timeText
+			timeText = shared.Sym().Bullet + " " + timeText
 		}
 		rowData[rowIdx] = histRow{
 			isRunning: isRunning,
@@ -393,6 +415,7 @@ func buildTaskDetailContent(s *TaskDetailState, st Styles, t types.TaskDefinitio
 		Width(rightW).
 		Height(tableH).
 		YOffset(s.HistoryOff).
+		Border(shared.Sym().TableBorder).
 		BorderTop(false).BorderBottom(false).
 		BorderLeft(false).BorderRight(false).
 		BorderHeader(true).BorderColumn(true).BorderRow(true).
@@ -552,7 +575,7 @@ func buildTaskHistoryDetailLines(history []types.TaskRunSummary, histIdx int, st
 	}
 
 	lines := []string{
-		shared.PadRight(st.Divider.Render(strings.Repeat("─", width)), width),
+		shared.PadRight(st.Divider.Render(strings.Repeat(shared.Sym().None, width)), width),
 		shared.PadRight(" "+st.SectionHead.Render(i18n.T(i18n.KRecordDetails)), width),
 	}
 
@@ -577,7 +600,11 @@ func buildTaskHistoryDetailLines(history []types.TaskRunSummary, histIdx int, st
 		"TPM", fmt.Sprintf("%.0f tok/min", sel.TPM), st.MetricVal,
 	)
 	lines = appendSingleField(lines, i18n.T(i18n.KProtocol), shortProtocol(sel.Protocol), st.Value)
-	lines = appendSingleField(lines, i18n.T(i18n.KModel), sel.Model, st.Value)
+	modelText := sel.Model
+	if sel.ModelAlias != "" {
+		modelText = sel.ModelAlias + " (" + sel.Model + ")"
+	}
+	lines = appendSingleField(lines, i18n.T(i18n.KModel), modelText, st.Value)
 	if sel.CacheHitRate > 0 {
 		lines = appendSingleField(lines, i18n.T(i18n.KCache), fmt.Sprintf("%.1f%%", sel.CacheHitRate*100), st.Value)
 	}
@@ -230,31 +230,31 @@ func buildTaskListContent(s *TaskListState, st Styles, width, maxH int) string {
 		}
 
 		isRunning := hasActiveRun || (t.LatestRun != nil && t.LatestRun.Status == string(server.RunStatusRunning))
-		lastRunText := "─"
+		lastRunText := shared.Sym().None
 		if t.LatestRun != nil && !t.LatestRun.FinishedAt.IsZero() {
 			lastRunText = shared.FmtRelativeTime(t.LatestRun.FinishedAt)
 		}
 
 		displayName := t.Name
 		if isRunning {
-			displayName = "● " + displayName
+			displayName = shared.Sym().Bullet + " " + displayName
 		}
 
-		rateText := "─"
+		rateText := shared.Sym().None
 		if hasActiveRun && rs != nil && rs.TotalReqs > 0 {
 			rateText = fmt.Sprintf("%.1f%%", rs.SuccessRate)
 		} else if !hasActiveRun && t.LatestRun != nil {
 			rateText = fmt.Sprintf("%.1f%%", t.LatestRun.SuccessRate)
 		}
 
-		ttftText := "─"
+		ttftText := shared.Sym().None
 		if hasActiveRun && rs != nil && rs.AvgTTFT > 0 {
 			ttftText = shared.FmtDuration(rs.AvgTTFT)
 		} else if !hasActiveRun && t.LatestRun != nil {
 			ttftText = shared.FmtDuration(t.LatestRun.AvgTTFT)
 		}
 
-		tpsText := "─"
+		tpsText := shared.Sym().None
 		if hasActiveRun && rs != nil && rs.AvgTPS > 0 {
 			tpsText = fmt.Sprintf("%.1f", rs.AvgTPS)
 		} else if !hasActiveRun && t.LatestRun != nil {
@@ -265,21 +265,21 @@ func buildTaskListContent(s *TaskListState, st Styles, width, maxH int) string {
 			}
 		}
 
-		cacheText := "─"
+		cacheText := shared.Sym().None
 		if hasActiveRun && rs != nil && rs.CacheHitRate > 0 {
 			cacheText = fmt.Sprintf("%.1f%%", rs.CacheHitRate*100)
 		} else if !hasActiveRun && t.LatestRun != nil && t.LatestRun.CacheHitRate > 0 {
 			cacheText = fmt.Sprintf("%.1f%%", t.LatestRun.CacheHitRate*100)
 		}
 
-		rpmText := "─"
+		rpmText := shared.Sym().None
 		if hasActiveRun && rs != nil && rs.RPM > 0 {
 			rpmText = fmt.Sprintf("%.0f", rs.RPM)
 		} else if !hasActiveRun && t.LatestRun != nil && t.LatestRun.RPM > 0 {
 			rpmText = fmt.Sprintf("%.0f", t.LatestRun.RPM)
 		}
 
-		tpmText := "─"
+		tpmText := shared.Sym().None
 		if hasActiveRun && rs != nil && rs.TPM > 0 {
 			tpmText = fmt.Sprintf("%.0f", rs.TPM)
 		} else if !hasActiveRun && t.LatestRun != nil && t.LatestRun.TPM > 0 {
@@ -330,6 +330,7 @@ func buildTaskListContent(s *TaskListState, st Styles, width, maxH int) string {
 		Width(width).
 		Height(maxH).
 		YOffset(s.Offset).
+		Border(shared.Sym().TableBorder).
 		BorderTop(false).BorderBottom(false).
 		BorderLeft(false).BorderRight(false).
 		BorderHeader(true).BorderColumn(true).BorderRow(true).
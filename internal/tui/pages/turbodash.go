@@ -289,17 +289,31 @@ func buildTurboDashParams(rs *server.RunState, st Styles, maxH, width int) strin
 		if tc == nil {
 			lines = append(lines, " "+st.Muted.Render(i18n.T(i18n.KWaitingData)))
 		} else {
-			lbls := []string{i18n.T(i18n.KRamp), i18n.T(i18n.KPerLevel), i18n.T(i18n.KStopCondLabel)}
+			lbls := []string{i18n.T(i18n.KRamp), i18n.T(i18n.KPerLevel), i18n.T(i18n.KStopCondLabel), i18n.T(i18n.KKneeLabel)}
 			lw := shared.MaxLabelWidth(lbls)
-			lines = append(lines, " "+labelValue(st, lbls[0], fmt.Sprintf("%d→%d  +%d", tc.InitConcurrency, tc.MaxConcurrency, tc.StepSize), lw))
+			lines = append(lines, " "+labelValue(st, lbls[0], fmt.Sprintf("%d%s%d  +%d", tc.InitConcurrency, shared.Sym().Arrow, tc.MaxConcurrency, tc.StepSize), lw))
 			lines = append(lines, " "+labelValue(st, lbls[1], fmt.Sprintf("%d req", tc.LevelRequests), lw))
 			lines = append(lines, " "+labelValue(st, lbls[2], fmt.Sprintf("%.0f%%", tc.MinSuccessRate*100), lw))
+			if result, ok := rs.ModeResult.(*types.TurboResult); ok && result != nil && result.Knee != nil {
+				lines = append(lines, " "+labelValue(st, lbls[3], turboKneeText(result.Knee), lw))
+			}
 		}
 	}
 
 	return finishPanelLines(lines, maxH)
 }
 
+// turboKneeText 格式化并发拐点，未出现的拐点显示为 "-"。
+func turboKneeText(knee *types.ConcurrencyKnee) string {
+	concurrency := func(c int) string {
+		if c <= 0 {
+			return "-"
+		}
+		return fmt.Sprintf("%d", c)
+	}
+	return fmt.Sprintf(i18n.T(i18n.KTurboKneeFmt), knee.SafeConcurrency, concurrency(knee.ErrorRateConcurrency), concurrency(knee.LatencyConcurrency))
+}
+
 // buildTurboDashMetrics 构建 Turbo 仪表盘右侧当前级别实时指标面板。
 func buildTurboDashMetrics(rs *server.RunState, st Styles, maxH, width int) string {
 	var lines []string
@@ -374,9 +388,9 @@ func buildTurboRequestList(d *TurboDashState, rs *server.RunState, st Styles, wi
 	for pos := 0; pos < len(reqs); pos++ {
 		i := requestIndexFromDisplayPos(pos, len(reqs))
 		r := reqs[i]
-		statusText := "✓"
+		statusText := shared.Sym().Ok
 		if !r.Success {
-			statusText = "✗"
+			statusText = shared.Sym().Err
 		}
 		totalText := shared.FmtDuration(r.TotalTime)
 		if !r.Success && r.ErrorMessage != "" {
@@ -407,6 +421,7 @@ func buildTurboRequestList(d *TurboDashState, rs *server.RunState, st Styles, wi
 		Width(width).
 		Height(tableH).
 		YOffset(d.ReqOff).
+		Border(shared.Sym().TableBorder).
 		BorderTop(false).BorderBottom(false).
 		BorderLeft(false).BorderRight(false).
 		BorderHeader(true).BorderColumn(true).BorderRow(true).
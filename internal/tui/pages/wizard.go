@@ -311,6 +311,7 @@ func step1Fields() []fieldDef {
 		types.ProtocolOpenAICompletions,
 		types.ProtocolOpenAIResponses,
 		types.ProtocolAnthropicMessages,
+		types.ProtocolTritonGRPC,
 	}
 	return []fieldDef{
 		{
@@ -956,8 +957,8 @@ func renderStep3Summary(wz *WizardState, st Styles, innerW int) []string {
 		addRow(i18n.T(i18n.KWzFailFast), boolLabel(wz.IntegrityFailFast), st.Value)
 	case wz.Turbo:
 		addRow(i18n.T(i18n.KWzTestMode), i18n.T(i18n.KWzTurboMode), st.Value)
-		addRow(i18n.T(i18n.KWzConcurrencyRamp), fmt.Sprintf("%d → %d · +%d · %d req",
-			wz.InitConcurrency, wz.MaxConcurrency, wz.StepSize, wz.LevelRequests), st.Value)
+		addRow(i18n.T(i18n.KWzConcurrencyRamp), fmt.Sprintf("%d %s %d · +%d · %d req",
+			wz.InitConcurrency, shared.Sym().Arrow, wz.MaxConcurrency, wz.StepSize, wz.LevelRequests), st.Value)
 		addRow(i18n.T(i18n.KWzStopCondition), fmt.Sprintf("< %.0f%%", wz.MinSuccessRate), st.Value)
 	default: // Standard
 		addRow(i18n.T(i18n.KWzTestMode), i18n.T(i18n.KWzStandardMode), st.Value)
@@ -997,7 +998,7 @@ func renderWizardStepStrip(step wizardStep) string {
 	for i, label := range labels {
 		switch {
 		case i < int(step):
-			parts = append(parts, done.Render("✓ "+label))
+			parts = append(parts, done.Render(shared.Sym().Ok+" "+label))
 		case i == int(step):
 			parts = append(parts, active.Render(label))
 		default:
@@ -75,6 +75,7 @@ func step1Fields() []fieldDef {
 		types.ProtocolOpenAICompletions,
 		types.ProtocolOpenAIResponses,
 		types.ProtocolAnthropicMessages,
+		types.ProtocolTritonGRPC,
 	}
 	return []fieldDef{
 		{
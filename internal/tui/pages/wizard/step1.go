@@ -13,6 +13,7 @@ func GetStep1Fields() []fieldDef {
 		types.ProtocolOpenAICompletions,
 		types.ProtocolOpenAIResponses,
 		types.ProtocolAnthropicMessages,
+		types.ProtocolTritonGRPC,
 	}
 	return []fieldDef{
 		{
@@ -293,8 +293,8 @@ func (h *apiHandler) handleRunReport(w http.ResponseWriter, r *http.Request, run
 	if format == "" {
 		format = aitserver.ReportFormatJSON
 	}
-	if format != aitserver.ReportFormatJSON && format != aitserver.ReportFormatCSV {
-		writeError(w, http.StatusBadRequest, "format must be json or csv")
+	if format != aitserver.ReportFormatJSON && format != aitserver.ReportFormatCSV && format != aitserver.ReportFormatMarkdown && format != aitserver.ReportFormatK6 {
+		writeError(w, http.StatusBadRequest, "format must be json, csv, md or k6")
 		return
 	}
 
@@ -458,8 +458,10 @@ func decodeInput(raw json.RawMessage) (types.Input, error) {
 	if err := json.Unmarshal(raw, &obj); err != nil {
 		return types.Input{}, fmt.Errorf("invalid input: %w", err)
 	}
-	if err := normalizeDurationField(obj, "timeout"); err != nil {
-		return types.Input{}, err
+	for _, key := range []string{"timeout", "burst_interval", "probe_interval"} {
+		if err := normalizeDurationField(obj, key); err != nil {
+			return types.Input{}, err
+		}
 	}
 	if turbo, ok := obj["turbo_config"].(map[string]any); ok {
 		if err := normalizeDurationField(turbo, "max_latency"); err != nil {
@@ -581,26 +583,64 @@ func taskConfigDTO(cfg aitserver.TaskConfig) map[string]any {
 
 func inputDTO(input types.Input) map[string]any {
 	return map[string]any{
-		"mode":          input.RunMode(),
-		"protocol":      input.NormalizedProtocol(),
-		"endpoint_url":  input.ResolvedEndpointURL(),
-		"base_url":      input.BaseUrl,
-		"proxy_url":     input.ProxyURL,
-		"model":         input.Model,
-		"concurrency":   input.Concurrency,
-		"count":         input.Count,
-		"stream":        input.Stream,
-		"thinking":      input.Thinking,
-		"turbo":         input.Turbo,
-		"turbo_config":  turboConfigDTO(input.TurboConfig),
-		"integrity":     input.Integrity,
-		"prompt_mode":   input.PromptMode,
-		"prompt_text":   input.PromptText,
-		"prompt_file":   input.PromptFile,
-		"prompt_length": input.PromptLength,
-		"report":        input.Report,
-		"timeout":       durationString(input.Timeout),
-		"log":           input.Log,
+		"mode":                 input.RunMode(),
+		"protocol":             input.NormalizedProtocol(),
+		"endpoint_url":         input.ResolvedEndpointURL(),
+		"base_url":             input.BaseUrl,
+		"proxy_url":            input.ProxyURL,
+		"model":                input.Model,
+		"model_alias":          input.ModelAlias,
+		"concurrency":          input.Concurrency,
+		"count":                input.Count,
+		"stream":               input.Stream,
+		"compare_stream":       input.CompareStream,
+		"compare_stream_split": input.CompareStreamSplit,
+		"grpc_method":          input.GRPCMethod,
+		"thinking":             input.Thinking,
+		"thinking_budget":      input.ThinkingBudget,
+		"max_tokens":           input.MaxTokens,
+		"anthropic_version":    input.AnthropicVersion,
+		"anthropic_beta":       input.AnthropicBeta,
+		"self_stats":           input.SelfStats,
+		"csv_header_comment":   input.CSVHeaderComment,
+		"csv_legacy_format":    input.CSVLegacyFormat,
+		"adaptive":             input.Adaptive,
+		"baseline_dir":         input.BaselineDir,
+		"regression_threshold": input.RegressionThreshold,
+		"token_budget":         input.TokenBudget,
+		"webhook_url":          input.WebhookURL,
+		"webhook_format":       input.WebhookFormat,
+		"endpoints":            input.Endpoints,
+		"endpoint_strategy":    input.EndpointStrategy,
+		"content_check":        input.ContentCheck,
+		"sla":                  input.SLA,
+		"turbo":                input.Turbo,
+		"turbo_config":         turboConfigDTO(input.TurboConfig),
+		"integrity":            input.Integrity,
+		"prompt_mode":          input.PromptMode,
+		"prompt_text":          input.PromptText,
+		"prompt_file":          input.PromptFile,
+		"prompt_file_per_run":  input.PromptFilePerRun,
+		"prompt_length":        input.PromptLength,
+		"prompt_length_dist":   input.PromptLengthDist,
+		"prompt_seed":          input.PromptSeed,
+		"tools_file":           input.ToolsFile,
+		"response_schema":      input.ResponseSchema,
+		"compress_request":     input.CompressRequest,
+		"verify_request_id":    input.VerifyRequestID,
+		"normalize_tokens":     input.NormalizeTokens,
+		"burst_size":           input.BurstSize,
+		"burst_interval":       durationString(input.BurstInterval),
+		"fallback_model":       input.FallbackModel,
+		"probe_interval":       durationString(input.ProbeInterval),
+		"input_length_sweep":   input.InputLengthSweep,
+		"phase_ratio":          input.PhaseRatio,
+		"replay_file":          input.ReplayFile,
+		"connect_retries":      input.ConnectRetries,
+		"consistency_check":    input.ConsistencyCheck,
+		"report":               input.Report,
+		"timeout":              durationString(input.Timeout),
+		"log":                  input.Log,
 	}
 }
 
@@ -631,6 +671,7 @@ func runSummaryDTO(run types.TaskRunSummary) map[string]any {
 		"status":                 run.Status,
 		"protocol":               run.Protocol,
 		"model":                  run.Model,
+		"model_alias":            run.ModelAlias,
 		"started_at":             run.StartedAt,
 		"finished_at":            run.FinishedAt,
 		"success_rate":           run.SuccessRate,
@@ -652,30 +693,34 @@ func runStateDTO(state *aitserver.RunState, requests []map[string]any) map[strin
 		requests = requestDTOs(state.Requests)
 	}
 	return map[string]any{
-		"run_id":         string(state.RunID),
-		"task_id":        state.TaskID,
-		"status":         string(state.Status),
-		"mode":           state.Mode,
-		"started_at":     state.StartedAt,
-		"finished_at":    state.FinishedAt,
-		"total_reqs":     state.TotalReqs,
-		"queued_reqs":    state.QueuedReqs,
-		"running_reqs":   state.RunningReqs,
-		"done_reqs":      state.DoneReqs,
-		"success_reqs":   state.SuccessReqs,
-		"failed_reqs":    state.FailedReqs,
-		"skipped_reqs":   state.SkippedReqs,
-		"avg_tps":        state.AvgTPS,
-		"avg_ttft":       durationString(state.AvgTTFT),
-		"success_rate":   state.SuccessRate,
-		"cache_hit_rate": state.CacheHitRate,
-		"rpm":            state.RPM,
-		"tpm":            state.TPM,
-		"requests":       requests,
-		"request_states": requestStateDTOs(state.RequestStates),
-		"mode_state":     state.ModeState,
-		"mode_result":    state.ModeResult,
-		"error_msg":      state.ErrorMsg,
+		"run_id":            string(state.RunID),
+		"task_id":           state.TaskID,
+		"status":            string(state.Status),
+		"mode":              state.Mode,
+		"started_at":        state.StartedAt,
+		"finished_at":       state.FinishedAt,
+		"total_reqs":        state.TotalReqs,
+		"queued_reqs":       state.QueuedReqs,
+		"running_reqs":      state.RunningReqs,
+		"done_reqs":         state.DoneReqs,
+		"success_reqs":      state.SuccessReqs,
+		"failed_reqs":       state.FailedReqs,
+		"skipped_reqs":      state.SkippedReqs,
+		"avg_tps":           state.AvgTPS,
+		"avg_ttft":          durationString(state.AvgTTFT),
+		"success_rate":      state.SuccessRate,
+		"cache_hit_rate":    state.CacheHitRate,
+		"rpm":               state.RPM,
+		"tpm":               state.TPM,
+		"instant_tps":       state.InstantTPS,
+		"avg_thinking_time": durationString(state.AvgThinkingTime),
+		"avg_steady_tps":    state.AvgSteadyTPS,
+		"self_stats":        state.SelfStats,
+		"requests":          requests,
+		"request_states":    requestStateDTOs(state.RequestStates),
+		"mode_state":        state.ModeState,
+		"mode_result":       state.ModeResult,
+		"error_msg":         state.ErrorMsg,
 	}
 }
 
@@ -726,7 +771,12 @@ func requestDTO(request types.RequestMetrics) map[string]any {
 		"dns_time":          durationString(request.DNSTime),
 		"connect_time":      durationString(request.ConnectTime),
 		"tls_time":          durationString(request.TLSTime),
+		"thinking_time":     durationString(request.ThinkingTime),
+		"throttle_retries":  request.ThrottleRetries,
+		"client_request_id": request.ClientRequestID,
+		"request_id":        request.ServerRequestID,
 		"target_ip":         request.TargetIP,
+		"endpoint":          request.Endpoint,
 		"error_message":     request.ErrorMessage,
 		"request_body":      request.RequestBody,
 		"response_body":     request.ResponseBody,
@@ -211,6 +211,13 @@ func (s *stubServer) DuplicateTask(id string) (types.TaskDefinition, error) {
 }
 func (s *stubServer) StartRun(taskID string) (aitserver.RunID, error) { return "run-started", nil }
 func (s *stubServer) StopRun(runID aitserver.RunID) error             { return nil }
+
+func (s *stubServer) AdjustRunConcurrency(runID aitserver.RunID, delta int) (int, error) {
+	return 0, nil
+}
+
+func (s *stubServer) SetRunPaused(runID aitserver.RunID, paused bool) error { return nil }
+
 func (s *stubServer) GetRunState(runID aitserver.RunID) (*aitserver.RunState, bool) {
 	if s.runState == nil || s.runState.RunID != runID {
 		return nil, false
@@ -238,6 +245,9 @@ func (s *stubServer) GetIntegritySuite(protocol, suiteID string) (types.Integrit
 	return types.IntegritySuite{ID: suiteID, Cases: []types.IntegrityCase{{ID: "basic-response-shape"}}}, nil
 }
 func (s *stubServer) Context() context.Context { return context.Background() }
+func (s *stubServer) Regressed() bool          { return false }
+func (s *stubServer) SLAFailed() bool          { return false }
+func (s *stubServer) AbortedEarly() bool       { return false }
 
 type errNotFound string
 